@@ -0,0 +1,166 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tipe prestasi (achievements.achievementType) sesuai SRS 3.2.1.
+const (
+	AchievementTypeCompetition   = "competition"
+	AchievementTypePublication   = "publication"
+	AchievementTypeOrganization  = "organization"
+	AchievementTypeCertification = "certification"
+)
+
+// CompetitionLevels adalah nilai details.competitionLevel yang diizinkan.
+var CompetitionLevels = []string{"international", "national", "regional", "local"}
+
+// AchievementTypes mengembalikan tipe prestasi bawaan SRS (seed awal katalog achievement_types).
+func AchievementTypes() []string {
+	return []string{
+		AchievementTypeCompetition,
+		AchievementTypePublication,
+		AchievementTypeOrganization,
+		AchievementTypeCertification,
+	}
+}
+
+// FieldError adalah 1 kesalahan validasi pada field input (path JSON, mis. "details.competitionName").
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidateDetails memeriksa details terhadap tipe prestasi bawaan SRS:
+//   - field wajib per tipe (mis. competition: competitionName + competitionLevel)
+//   - field milik tipe lain tidak boleh diisi (field umum & customFields boleh untuk semua tipe)
+//
+// Mengembalikan nil jika valid.
+func ValidateDetails(achievementType string, d AchievementDetails) []FieldError {
+	var errs []FieldError
+	add := func(field, msg string) {
+		errs = append(errs, FieldError{Field: "details." + field, Message: msg})
+	}
+	required := func(field string, v *string) {
+		if v == nil || strings.TrimSpace(*v) == "" {
+			add(field, "wajib diisi")
+		}
+	}
+
+	switch achievementType {
+	case AchievementTypeCompetition:
+		required("competitionName", d.CompetitionName)
+		if d.CompetitionLevel == nil || strings.TrimSpace(*d.CompetitionLevel) == "" {
+			add("competitionLevel", "wajib diisi")
+		} else if !slices.Contains(CompetitionLevels, *d.CompetitionLevel) {
+			add("competitionLevel", "harus salah satu dari: "+strings.Join(CompetitionLevels, ", "))
+		}
+		if d.Rank != nil && *d.Rank < 1 {
+			add("rank", "minimal 1")
+		}
+	case AchievementTypePublication:
+		required("publicationTitle", d.PublicationTitle)
+		required("publicationType", d.PublicationType)
+	case AchievementTypeOrganization:
+		required("organizationName", d.OrganizationName)
+		required("position", d.Position)
+		switch {
+		case d.Period == nil || d.Period.Start == nil:
+			add("period.start", "wajib diisi")
+		case d.Period.End != nil && d.Period.End.Before(*d.Period.Start):
+			add("period.end", "tidak boleh sebelum period.start")
+		}
+	case AchievementTypeCertification:
+		required("certificationName", d.CertificationName)
+		required("issuedBy", d.IssuedBy)
+	default:
+		// Tipe tambahan dari katalog achievement_types tidak punya aturan field khusus
+		// (validitas kodenya diperiksa service terhadap katalog).
+		return nil
+	}
+
+	for _, f := range foreignDetailFields(achievementType, d) {
+		add(f, "bukan field untuk tipe "+achievementType)
+	}
+	return errs
+}
+
+// EventDateClockSkew: toleransi eventDate "di masa depan" (selisih jam/zona waktu klien).
+const EventDateClockSkew = 24 * time.Hour
+
+// ValidateDetailDates memeriksa kewajaran tanggal details (berlaku untuk semua tipe):
+//   - eventDate tidak di masa depan (toleransi EventDateClockSkew) dan tidak lebih tua
+//     dari horizonYears tahun (0 = tanpa batas bawah)
+//   - validUntil (sertifikasi) harus setelah eventDate jika keduanya diisi
+//
+// Urutan period.start/end diperiksa ValidateDetails (period hanya untuk organization).
+func ValidateDetailDates(d AchievementDetails, now time.Time, horizonYears int) []FieldError {
+	var errs []FieldError
+	add := func(field, msg string) {
+		errs = append(errs, FieldError{Field: "details." + field, Message: msg})
+	}
+
+	if d.EventDate != nil {
+		switch {
+		case d.EventDate.After(now.Add(EventDateClockSkew)):
+			add("eventDate", "tidak boleh di masa depan")
+		case horizonYears > 0 && d.EventDate.Before(now.AddDate(-horizonYears, 0, 0)):
+			add("eventDate", fmt.Sprintf("tidak boleh lebih dari %d tahun yang lalu", horizonYears))
+		}
+	}
+	if d.ValidUntil != nil && d.EventDate != nil && !d.ValidUntil.After(*d.EventDate) {
+		add("validUntil", "harus setelah eventDate")
+	}
+	return errs
+}
+
+// foreignDetailFields mengembalikan field details yang terisi tetapi milik tipe lain.
+func foreignDetailFields(achievementType string, d AchievementDetails) []string {
+	groups := []struct {
+		typ    string
+		fields map[string]bool
+	}{
+		{AchievementTypeCompetition, map[string]bool{
+			"competitionName":  d.CompetitionName != nil,
+			"competitionLevel": d.CompetitionLevel != nil,
+			"rank":             d.Rank != nil,
+			"medalType":        d.MedalType != nil,
+		}},
+		{AchievementTypePublication, map[string]bool{
+			"publicationType":  d.PublicationType != nil,
+			"publicationTitle": d.PublicationTitle != nil,
+			"authors":          len(d.Authors) > 0,
+			"publisher":        d.Publisher != nil,
+			"issn":             d.ISSN != nil,
+		}},
+		{AchievementTypeOrganization, map[string]bool{
+			"organizationName": d.OrganizationName != nil,
+			"position":         d.Position != nil,
+			"period":           d.Period != nil,
+		}},
+		{AchievementTypeCertification, map[string]bool{
+			"certificationName":   d.CertificationName != nil,
+			"issuedBy":            d.IssuedBy != nil,
+			"certificationNumber": d.CertificationNumber != nil,
+			"validUntil":          d.ValidUntil != nil,
+		}},
+	}
+
+	var out []string
+	for _, g := range groups {
+		if g.typ == achievementType {
+			continue
+		}
+		for name, set := range g.fields {
+			if set {
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func fields(errs []FieldError) []string {
+	out := make([]string, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, e.Field)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestValidateDetails(t *testing.T) {
+	str := func(s string) *string { return &s }
+	rank := func(n int) *int { return &n }
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	tests := []struct {
+		name       string
+		typ        string
+		details    AchievementDetails
+		wantFields []string // nil = valid
+	}{
+		// competition
+		{name: "competition valid", typ: AchievementTypeCompetition,
+			details: AchievementDetails{CompetitionName: str("GEMASTIK"), CompetitionLevel: str("national"), Rank: rank(1), Location: str("Surabaya")}},
+		{name: "competition tanpa field wajib", typ: AchievementTypeCompetition,
+			wantFields: []string{"details.competitionLevel", "details.competitionName"}},
+		{name: "competition nama kosong & level di luar enum", typ: AchievementTypeCompetition,
+			details:    AchievementDetails{CompetitionName: str("  "), CompetitionLevel: str("provincial")},
+			wantFields: []string{"details.competitionLevel", "details.competitionName"}},
+		{name: "competition rank < 1", typ: AchievementTypeCompetition,
+			details:    AchievementDetails{CompetitionName: str("GEMASTIK"), CompetitionLevel: str("local"), Rank: rank(0)},
+			wantFields: []string{"details.rank"}},
+		{name: "competition berisi field publikasi", typ: AchievementTypeCompetition,
+			details:    AchievementDetails{PublicationTitle: str("Paper"), Authors: []string{"A"}},
+			wantFields: []string{"details.authors", "details.competitionLevel", "details.competitionName", "details.publicationTitle"}},
+
+		// publication
+		{name: "publication valid", typ: AchievementTypePublication,
+			details: AchievementDetails{PublicationTitle: str("Deteksi Hoaks"), PublicationType: str("journal"), Authors: []string{"A", "B"}}},
+		{name: "publication tanpa field wajib", typ: AchievementTypePublication,
+			wantFields: []string{"details.publicationTitle", "details.publicationType"}},
+		{name: "publication berisi field sertifikasi", typ: AchievementTypePublication,
+			details:    AchievementDetails{PublicationTitle: str("Paper"), PublicationType: str("conference"), IssuedBy: str("BNSP")},
+			wantFields: []string{"details.issuedBy"}},
+
+		// organization
+		{name: "organization valid", typ: AchievementTypeOrganization,
+			details: AchievementDetails{OrganizationName: str("BEM"), Position: str("Ketua"), Period: &Period{Start: &start, End: &end}}},
+		{name: "organization periode tanpa end", typ: AchievementTypeOrganization,
+			details: AchievementDetails{OrganizationName: str("BEM"), Position: str("Ketua"), Period: &Period{Start: &start}}},
+		{name: "organization start = end", typ: AchievementTypeOrganization,
+			details: AchievementDetails{OrganizationName: str("BEM"), Position: str("Ketua"), Period: &Period{Start: &start, End: &start}}},
+		{name: "organization tanpa field wajib", typ: AchievementTypeOrganization,
+			wantFields: []string{"details.organizationName", "details.period.start", "details.position"}},
+		{name: "organization end sebelum start", typ: AchievementTypeOrganization,
+			details:    AchievementDetails{OrganizationName: str("BEM"), Position: str("Ketua"), Period: &Period{Start: &end, End: &start}},
+			wantFields: []string{"details.period.end"}},
+		{name: "organization berisi field kompetisi", typ: AchievementTypeOrganization,
+			details:    AchievementDetails{OrganizationName: str("BEM"), Position: str("Ketua"), Period: &Period{Start: &start}, MedalType: str("gold")},
+			wantFields: []string{"details.medalType"}},
+
+		// certification
+		{name: "certification valid", typ: AchievementTypeCertification,
+			details: AchievementDetails{CertificationName: str("AWS SAA"), IssuedBy: str("AWS"), CertificationNumber: str("X-1")}},
+		{name: "certification tanpa field wajib", typ: AchievementTypeCertification,
+			wantFields: []string{"details.certificationName", "details.issuedBy"}},
+		{name: "certification berisi field organisasi", typ: AchievementTypeCertification,
+			details:    AchievementDetails{CertificationName: str("AWS SAA"), IssuedBy: str("AWS"), Position: str("Ketua")},
+			wantFields: []string{"details.position"}},
+
+		// tipe tambahan dari katalog
+		{name: "tipe katalog tanpa aturan khusus", typ: "seminar",
+			details: AchievementDetails{CompetitionName: str("bebas"), Location: str("Malang")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(ValidateDetails(tt.typ, tt.details))
+			if !slices.Equal(got, tt.wantFields) {
+				t.Fatalf("field error %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateDetailDates(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Time) *time.Time { return &d }
+
+	tests := []struct {
+		name       string
+		details    AchievementDetails
+		horizon    int
+		wantFields []string
+	}{
+		{name: "tanpa tanggal"},
+		{name: "eventDate lalu", details: AchievementDetails{EventDate: at(now.AddDate(0, -2, 0))}, horizon: 5},
+		{name: "eventDate dalam toleransi zona waktu", details: AchievementDetails{EventDate: at(now.Add(12 * time.Hour))}},
+		{name: "eventDate di masa depan", details: AchievementDetails{EventDate: at(now.AddDate(0, 0, 3))},
+			wantFields: []string{"details.eventDate"}},
+		{name: "eventDate melewati horizon", details: AchievementDetails{EventDate: at(now.AddDate(-6, 0, 0))}, horizon: 5,
+			wantFields: []string{"details.eventDate"}},
+		{name: "horizon 0 tanpa batas bawah", details: AchievementDetails{EventDate: at(now.AddDate(-30, 0, 0))}},
+		{name: "validUntil sebelum eventDate",
+			details:    AchievementDetails{EventDate: at(now.AddDate(0, -1, 0)), ValidUntil: at(now.AddDate(0, -2, 0))},
+			wantFields: []string{"details.validUntil"}},
+		{name: "validUntil tanpa eventDate", details: AchievementDetails{ValidUntil: at(now.AddDate(-1, 0, 0))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(ValidateDetailDates(tt.details, now, tt.horizon))
+			if !slices.Equal(got, tt.wantFields) {
+				t.Fatalf("field error %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import "strings"
+
+// Status prestasi (achievement_references.status).
+// Satu-satunya sumber daftar status: validasi repository, check constraint DB,
+// dan endpoint meta semuanya diturunkan dari sini. Menambah status cukup di file ini.
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusVerified  = "verified"
+	StatusRejected  = "rejected"
+	StatusExpired   = "expired" // pengajuan melewati batas waktu verifikasi (SubmissionExpiryJob)
+	StatusDeleted   = "deleted"
+)
+
+// AchievementStatusConstraintName adalah nama check constraint status di Postgres
+// (sama dengan nama yang dulu dibuat otomatis oleh GORM dari tag check).
+const AchievementStatusConstraintName = "chk_achievement_references_status"
+
+// achievementStatusLabels: label tampilan (Bahasa Indonesia) per status, urut sesuai alur.
+var achievementStatusLabels = []struct{ Value, Label string }{
+	{StatusDraft, "Draft"},
+	{StatusSubmitted, "Menunggu Verifikasi"},
+	{StatusVerified, "Terverifikasi"},
+	{StatusRejected, "Ditolak"},
+	{StatusExpired, "Kedaluwarsa"},
+	{StatusDeleted, "Dihapus"},
+}
+
+// AchievementStatuses mengembalikan semua status yang valid (urut sesuai alur).
+func AchievementStatuses() []string {
+	out := make([]string, 0, len(achievementStatusLabels))
+	for _, s := range achievementStatusLabels {
+		out = append(out, s.Value)
+	}
+	return out
+}
+
+// AchievementStatusLabel mengembalikan label tampilan status ("" jika tidak dikenal).
+func AchievementStatusLabel(status string) string {
+	for _, s := range achievementStatusLabels {
+		if s.Value == status {
+			return s.Label
+		}
+	}
+	return ""
+}
+
+// IsValidAchievementStatus mengecek apakah status termasuk daftar status resmi.
+func IsValidAchievementStatus(status string) bool {
+	return AchievementStatusLabel(status) != ""
+}
+
+// AchievementStatusCheckSQL menghasilkan ekspresi CHECK untuk kolom status,
+// contoh: status IN ('draft','submitted',...).
+func AchievementStatusCheckSQL() string {
+	quoted := make([]string, 0, len(achievementStatusLabels))
+	for _, s := range AchievementStatuses() {
+		quoted = append(quoted, "'"+s+"'")
+	}
+	return "status IN (" + strings.Join(quoted, ",") + ")"
+}
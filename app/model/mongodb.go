@@ -7,10 +7,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// AchievementSchemaVersion adalah versi bentuk dokumen achievements saat ini.
+// Riwayat (upgrade in-memory ada di repository.DecodeAchievement):
+//   - 0: tanpa schemaVersion; lampiran memakai key lama url/name/type
+//   - 1: lampiran memakai fileUrl/fileName/fileType; studentId bisa berupa string/UUID binary subtype 4
+//   - 2: studentId selalu binary generic (encoding default uuid.UUID)
+const AchievementSchemaVersion = 2
+
 // Achievement merepresentasikan 1 dokumen prestasi di MongoDB (collection: achievements)
 // Struktur mengikuti definisi di SRS bagian 3.2.1 Collection achievements.
 type Achievement struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty"`     // _id dokumen Mongo
+	SchemaVersion   int                `bson:"schemaVersion"`     // versi bentuk dokumen (AchievementSchemaVersion)
 	StudentID       uuid.UUID         `bson:"studentId"`         // ID mahasiswa (sama dengan students.id di Postgres)
 	AchievementType string            `bson:"achievementType"`   // tipe prestasi: competition/publication/organization/certification
 	Title           string            `bson:"title"`             // judul prestasi
@@ -18,9 +26,12 @@ type Achievement struct {
 	Details         AchievementDetails `bson:"details"`          // detail spesifik tergantung tipe
 	Attachments     []Attachment       `bson:"attachments"`      // daftar lampiran bukti
 	Tags            []string           `bson:"tags"`             // tag/tagline pendukung
-	Points          int                `bson:"points"`           // bobot poin prestasi
+	Points          float64            `bson:"points"`           // bobot poin prestasi (boleh pecahan, mis. 2.5)
+	PointsOverride  *PointsOverride    `bson:"pointsOverride,omitempty"` // override poin manual oleh admin
 	CreatedAt       time.Time          `bson:"createdAt"`        // tanggal dibuat
 	UpdatedAt       time.Time          `bson:"updatedAt"`        // tanggal terakhir diupdate
+	Deleted         bool               `bson:"deleted,omitempty" json:"-"`   // true jika prestasi di-soft-delete
+	DeletedAt       *time.Time         `bson:"deletedAt,omitempty" json:"-"` // waktu soft delete
 }
 
 // AchievementDetails menyimpan field dinamis (competition/publication/organization/certification)
@@ -68,10 +79,69 @@ type Period struct {
 }
 
 // Attachment merepresentasikan 1 lampiran (file bukti) prestasi.
-// Nama type ini sengaja disamakan dengan yang dipakai di service ([]model.Attachment).
+// Satu-satunya type lampiran: dipakai di dokumen Mongo, AddAttachment, dan input create/update,
+// sehingga nama key bson & json sama (fileName/fileUrl/fileType/uploadedAt).
 type Attachment struct {
-	FileName   string    `bson:"fileName"`   // fileName
-	FileURL    string    `bson:"fileUrl"`    // fileUrl
-	FileType   string    `bson:"fileType"`   // fileType (pdf/jpg/dll)
-	UploadedAt time.Time `bson:"uploadedAt"` // uploadedAt
+	FileName   string    `bson:"fileName" json:"fileName"`     // fileName
+	FileURL    string    `bson:"fileUrl" json:"fileUrl"`       // fileUrl
+	FileType   string    `bson:"fileType" json:"fileType"`     // fileType (pdf/jpg/dll)
+	UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"` // uploadedAt
+
+	// ID stabil lampiran, dibuat saat upload (dipakai DELETE .../attachments/:attachmentId).
+	// Lampiran lama (sebelum field ini ada) tidak memiliki id.
+	ID string `bson:"id,omitempty" json:"id,omitempty"`
+
+	// Deleted ditandai saat prestasi di-soft-delete: lampiran tidak bisa diunduh,
+	// tetapi file tetap ada sampai purge sehingga restore mengembalikannya utuh.
+	// Tidak dikirim/diterima lewat JSON (hanya diatur repository).
+	Deleted   bool       `bson:"deleted,omitempty" json:"-"`
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"-"`
 }
+
+// PointsOverride menyimpan jejak override poin manual oleh admin.
+// Saat override dibatalkan, poin dikembalikan dari revisi RevisionID (isi prestasi tepat sebelum
+// override ini) dan override sebelumnya (Previous) dipasang kembali; OriginalPoints hanya
+// cadangan jika revisi tersebut sudah terpangkas.
+type PointsOverride struct {
+	OriginalPoints float64            `bson:"originalPoints"`       // poin sebelum override pertama
+	Points         float64            `bson:"points"`               // poin hasil override
+	Reason         string             `bson:"reason"`               // alasan override (wajib)
+	ActorID        uuid.UUID          `bson:"actorId"`              // admin yang melakukan override
+	OverriddenAt   time.Time          `bson:"overriddenAt"`
+	RevisionID     primitive.ObjectID `bson:"revisionId,omitempty"` // revisi isi prestasi sebelum override ini
+	Previous       *PointsOverride    `bson:"previous,omitempty"`   // override yang digantikan (nil = belum pernah)
+}
+
+// AchievementComment adalah 1 komentar diskusi prestasi antara mahasiswa, dosen wali, dan admin
+// (collection achievement_comments). Komentar tidak bisa diubah; penulis hanya boleh
+// menghapus komentarnya sendiri dalam AchievementCommentDeleteWindow.
+type AchievementComment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID string             `bson:"achievementId" json:"achievementId"` // achievement_references.id
+	AuthorUserID  uuid.UUID          `bson:"authorUserId" json:"authorUserId"`
+	Role          string             `bson:"role" json:"role"` // role penulis saat berkomentar
+	Body          string             `bson:"body" json:"body"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// AchievementCommentDeleteWindow: batas waktu penulis boleh menghapus komentarnya.
+const AchievementCommentDeleteWindow = 15 * time.Minute
+
+// AchievementRevision adalah salinan isi prestasi sebelum diubah lewat PUT/PATCH
+// (collection achievement_revisions). Hanya AchievementRevisionLimit revisi terbaru yang disimpan.
+type AchievementRevision struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID primitive.ObjectID `bson:"achievementId" json:"-"` // _id dokumen achievements
+	Title         string             `bson:"title" json:"title"`
+	Description   string             `bson:"description" json:"description"`
+	Details       AchievementDetails `bson:"details" json:"details"`
+	Tags          []string           `bson:"tags" json:"tags"`
+	Points        float64            `bson:"points" json:"points"`
+	Attachments   []Attachment       `bson:"attachments,omitempty" json:"attachments"` // kosong untuk revisi lama
+	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`               // updatedAt versi lama
+	EditedBy      uuid.UUID          `bson:"editedBy" json:"editedBy"`                 // user yang mengganti versi ini
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`               // waktu versi ini digantikan
+}
+
+// AchievementRevisionLimit: jumlah revisi terbaru yang disimpan per prestasi.
+const AchievementRevisionLimit = 20
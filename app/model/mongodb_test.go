@@ -0,0 +1,143 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestAchievementPointsJSONRoundTrip: points 0, bulat, dan pecahan tidak berubah setelah JSON encode/decode.
+func TestAchievementPointsJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		points float64
+	}{
+		{name: "nol", points: 0},
+		{name: "bulat", points: 15},
+		{name: "pecahan", points: 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := Achievement{Title: "Juara 1", Points: tt.points,
+				PointsOverride: &PointsOverride{OriginalPoints: tt.points, Points: tt.points + 0.25}}
+			raw, err := json.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out Achievement
+			if err := json.Unmarshal(raw, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Points != tt.points {
+				t.Fatalf("points %v, want %v", out.Points, tt.points)
+			}
+			if out.PointsOverride == nil || out.PointsOverride.OriginalPoints != tt.points || out.PointsOverride.Points != tt.points+0.25 {
+				t.Fatalf("pointsOverride %+v", out.PointsOverride)
+			}
+		})
+	}
+}
+
+// TestAchievementPointsDecodesLegacyBSON: dokumen lama dengan points int32/int64 tetap terbaca sebagai float64.
+func TestAchievementPointsDecodesLegacyBSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		points any
+		want   float64
+	}{
+		{name: "int32", points: int32(10), want: 10},
+		{name: "int64", points: int64(25), want: 25},
+		{name: "double", points: 2.5, want: 2.5},
+		{name: "nol", points: int32(0), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(bson.M{
+				"title":          "Juara 1",
+				"points":         tt.points,
+				"pointsOverride": bson.M{"originalPoints": tt.points, "points": tt.points},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc Achievement
+			if err := bson.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("decode dokumen points %T: %v", tt.points, err)
+			}
+			if doc.Points != tt.want || doc.PointsOverride.OriginalPoints != tt.want || doc.PointsOverride.Points != tt.want {
+				t.Fatalf("points %v override %+v, want %v", doc.Points, doc.PointsOverride, tt.want)
+			}
+		})
+	}
+}
+
+// TestAttachmentDecodesStoredDocuments: dokumen lama (fileName/fileUrl/fileType/uploadedAt, tanpa id)
+// tetap terbaca, dan key JSON lampiran sama dengan key BSON-nya.
+func TestAttachmentDecodesStoredDocuments(t *testing.T) {
+	uploadedAt := time.Date(2025, 8, 17, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		stored bson.M
+		want   Attachment
+	}{
+		{
+			name: "lampiran lama tanpa id",
+			stored: bson.M{"fileName": "sertifikat.pdf", "fileUrl": "/uploads/a/sertifikat.pdf",
+				"fileType": "pdf", "uploadedAt": uploadedAt},
+			want: Attachment{FileName: "sertifikat.pdf", FileURL: "/uploads/a/sertifikat.pdf", FileType: "pdf", UploadedAt: uploadedAt},
+		},
+		{
+			name: "lampiran dengan id",
+			stored: bson.M{"id": "att-1", "fileName": "foto.jpg", "fileUrl": "/uploads/a/foto.jpg",
+				"fileType": "jpg", "uploadedAt": uploadedAt},
+			want: Attachment{ID: "att-1", FileName: "foto.jpg", FileURL: "/uploads/a/foto.jpg", FileType: "jpg", UploadedAt: uploadedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(bson.M{"title": "Juara 1", "attachments": bson.A{tt.stored}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc Achievement
+			if err := bson.Unmarshal(raw, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if len(doc.Attachments) != 1 {
+				t.Fatalf("%d lampiran, want 1", len(doc.Attachments))
+			}
+			got := doc.Attachments[0]
+			if got.FileName != tt.want.FileName || got.FileURL != tt.want.FileURL || got.FileType != tt.want.FileType ||
+				!got.UploadedAt.Equal(tt.want.UploadedAt) || got.ID != tt.want.ID || got.Deleted {
+				t.Fatalf("lampiran %+v, want %+v", got, tt.want)
+			}
+
+			// JSON memakai key yang sama dengan BSON (tanpa deleted/deletedAt).
+			js, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys map[string]any
+			if err := json.Unmarshal(js, &keys); err != nil {
+				t.Fatal(err)
+			}
+			for k := range tt.stored {
+				if _, ok := keys[k]; !ok {
+					t.Fatalf("JSON %s tidak memiliki key %q", js, k)
+				}
+			}
+			if len(keys) != len(tt.stored) {
+				t.Fatalf("JSON %s, want hanya key %v", js, tt.stored)
+			}
+			var back Attachment
+			if err := json.Unmarshal(js, &back); err != nil {
+				t.Fatal(err)
+			}
+			if back.FileName != got.FileName || back.FileURL != got.FileURL || !back.UploadedAt.Equal(got.UploadedAt) {
+				t.Fatalf("round-trip JSON %+v, want %+v", back, got)
+			}
+		})
+	}
+}
@@ -16,8 +16,26 @@ type User struct {
 	RoleID       uuid.UUID `gorm:"type:uuid;not null"`
 	Role         Role      `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
 	IsActive     bool      `gorm:"default:true"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	// MustChangePassword memaksa user mengganti password sebelum bisa memakai endpoint lain
+	// (dipakai untuk akun seed yang masih memakai password default di production).
+	MustChangePassword bool `gorm:"default:false"`
+	// PendingApproval menandai akun hasil registrasi mandiri yang belum disetujui admin
+	// (IsActive=false sampai disetujui).
+	PendingApproval bool `gorm:"default:false;index"`
+	// TokenVersion dinaikkan saat role, status aktif, atau password berubah; token dengan
+	// versi lama ditolak AuthMiddleware / refresh sehingga user harus login ulang.
+	TokenVersion int `gorm:"not null;default:0"`
+	// LastLoginAt & LastLoginIP diisi saat login berhasil (nil = belum pernah login).
+	LastLoginAt *time.Time
+	LastLoginIP *string `gorm:"type:varchar(45)"`
+	// 2FA (TOTP). Secret disimpan terenkripsi (utils.EncryptSecret); aktif setelah
+	// kode pertama diverifikasi. Kode pemulihan disimpan sebagai hash, sekali pakai.
+	TwoFactorEnabled       bool
+	TwoFactorSecret        string    `gorm:"type:text" json:"-"`
+	TwoFactorRecoveryCodes []string  `gorm:"serializer:json;type:jsonb" json:"-"`
+	TwoFactorLastStep      int64     `json:"-"` // langkah TOTP terakhir yang dipakai (anti replay)
+	CreatedAt              time.Time `gorm:"autoCreateTime"`
+	UpdatedAt              time.Time `gorm:"autoUpdateTime"`
 }
 
 // Role menyimpan peran pengguna (admin, mahasiswa, dosen_wali)
@@ -49,10 +67,16 @@ type Student struct {
 	StudentID    string     `gorm:"type:varchar(20);not null;column:student_id"` // NIM
 	ProgramStudy string     `gorm:"type:varchar(100)"`
 	AcademicYear string     `gorm:"type:varchar(10)"`
+	PhoneNumber  *string    `gorm:"type:varchar(20)"` // kontak mahasiswa, diubah sendiri lewat PUT /auth/profile
 	AdvisorID    *uuid.UUID `gorm:"type:uuid"` // FK ke lecturers.id
 	Advisor      *Lecturer  `gorm:"foreignKey:AdvisorID"`                        // dosen wali
 	CreatedAt    time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+
+	// GraduatedAt diisi admin; dasar perhitungan masa retensi data pribadi.
+	GraduatedAt *time.Time `gorm:"index"`
+	// AnonymizedAt diisi job retensi setelah data pribadi dianonimkan (tidak bisa dibalik).
+	AnonymizedAt *time.Time `gorm:"index"`
 }
 
 
@@ -69,10 +93,11 @@ type Lecturer struct {
 
 // AchievementReference menyimpan referensi prestasi di Postgres yang terhubung ke dokumen di Mongo
 type AchievementReference struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;index:idx_achievement_refs_review_queue,priority:3"`
 
 	// Simpan FK ke mahasiswa (students.id), TANPA bikin relasi otomatis dua arah
-	StudentID uuid.UUID `gorm:"type:uuid;not null"`
+	// Index (student_id, status) menjaga hitungan draft per mahasiswa tetap murah.
+	StudentID uuid.UUID `gorm:"type:uuid;not null;index:idx_achievement_refs_student_status,priority:1"`
 
 	// Kalau kamu pengin tetap punya field Student di struct utk dipakai di kode,
 	// tapi TANPA ikut migrasi/foreign key, pakai gorm:"-"
@@ -80,13 +105,272 @@ type AchievementReference struct {
 
 	MongoAchievementID string `gorm:"not null"` // _id dokumen di MongoDB (hex string)
 
-	// Status mengikuti SRS + revisi: draft, submitted, verified, rejected, deleted
-	Status        string     `gorm:"type:varchar(20);not null;check:status IN ('draft','submitted','verified','rejected','deleted')"`
-	SubmittedAt   *time.Time // waktu mahasiswa submit prestasi
-	VerifiedAt    *time.Time // waktu dosen wali/verifier memverifikasi
-	VerifiedBy    *uuid.UUID `gorm:"type:uuid"` // FK ke users.id (yang memverifikasi)
+	// Status mengikuti SRS + revisi (lihat model.AchievementStatuses).
+	// Check constraint dibuat saat migrasi dari daftar tersebut (database.syncAchievementStatusConstraint).
+	// Index (status, submitted_at, id) dipakai antrean review admin (keyset pagination);
+	// index (verified_by, verified_at) dipakai daftar keputusan dosen (FindByVerifier).
+	Status        string     `gorm:"type:varchar(20);not null;index:idx_achievement_refs_student_status,priority:2;index:idx_achievement_refs_review_queue,priority:1"`
+	SubmittedAt   *time.Time `gorm:"index:idx_achievement_refs_review_queue,priority:2"`       // waktu mahasiswa submit prestasi
+	VerifiedAt    *time.Time `gorm:"index:idx_achievement_refs_verifier,priority:2"`           // waktu dosen wali/verifier memverifikasi
+	VerifiedBy    *uuid.UUID `gorm:"type:uuid;index:idx_achievement_refs_verifier,priority:1"` // FK ke users.id (yang memverifikasi)
 	Verifier      *User      `gorm:"foreignKey:VerifiedBy"`
 	RejectionNote *string    // alasan penolakan jika status rejected
 	CreatedAt     time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+
+	// RejectionCategory: kategori penolakan (model.RejectionCategories); nil untuk penolakan lama.
+	RejectionCategory *string `gorm:"type:varchar(30)"`
+
+	// VerifiedAsDelegateOf terisi jika verifikasi/penolakan dilakukan oleh delegasi
+	// (lecturers.id dosen wali asli yang melimpahkan).
+	VerifiedAsDelegateOf *uuid.UUID `gorm:"type:uuid"`
+
+	// InternalNote: catatan privat verifier (hanya untuk dosen pemutus & admin).
+	// json:"-" agar tidak pernah ikut terserialisasi ke response mahasiswa/export.
+	InternalNote *string `gorm:"type:text" json:"-"`
+
+	// DecisionImported: keputusan verified/rejected berasal dari impor sistem lama
+	// (verified_at = tanggal keputusan historis, bukan waktu impor).
+	DecisionImported bool `gorm:"not null;default:false"`
+
+	// ExternalRef: ID prestasi di sistem lama (diisi admin saat memindahkan data lama),
+	// dipakai impor keputusan untuk mencocokkan baris spreadsheet. Unik jika diisi.
+	ExternalRef *string `gorm:"type:varchar(100);uniqueIndex"`
+}
+
+// AchievementStatusLog mencatat 1 perubahan status prestasi. Ditulis di transaksi yang sama
+// dengan perubahan status, sehingga timeline riwayat tidak tertimpa saat prestasi
+// diajukan ulang / ditolak berkali-kali.
+type AchievementStatusLog struct {
+	ID                     uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	AchievementReferenceID uuid.UUID  `gorm:"type:uuid;not null;index:idx_achievement_status_logs_ref_created,priority:1"`
+	FromStatus             string     `gorm:"type:varchar(20);not null"`
+	ToStatus               string     `gorm:"type:varchar(20);not null"`
+	ActorUserID            *uuid.UUID `gorm:"type:uuid"`        // nil = sistem / tidak diketahui
+	Note                   *string    `gorm:"type:text"`        // catatan penolakan (bukan catatan privat verifier)
+	Category               *string    `gorm:"type:varchar(30)"` // kategori penolakan (nil = bukan penolakan / data lama)
+	CreatedAt              time.Time  `gorm:"not null;index:idx_achievement_status_logs_ref_created,priority:2"`
+}
+
+// AuditLog mencatat aksi penting (override poin, merge akun, dsb) untuk keperluan audit.
+// Payload berisi detail aksi dalam bentuk JSON (nilai lama/baru, alasan, dll).
+type AuditLog struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ActorUserID *uuid.UUID `gorm:"type:uuid"`                        // user yang melakukan aksi (nil = sistem)
+	Action      string     `gorm:"type:varchar(100);not null;index"` // contoh: achievement.points_override
+	TargetType  string     `gorm:"type:varchar(50)"`                 // contoh: achievement, user
+	TargetID    string     `gorm:"type:varchar(100);index"`
+	Payload     string     `gorm:"type:jsonb"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	// ImpersonatedBy: admin yang meng-impersonate actor saat aksi dilakukan (nil = token biasa).
+	ImpersonatedBy *uuid.UUID `gorm:"type:uuid;index"`
+}
+
+// VerificationDelegation adalah pelimpahan sementara hak verifikasi dosen wali
+// (misal saat cuti/riset) ke dosen lain, tanpa memindahkan mahasiswa bimbingan.
+// Delegasi berlaku pada rentang [StartsAt, EndsAt) dan berakhir otomatis setelahnya.
+type VerificationDelegation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	FromLecturerID uuid.UUID  `gorm:"type:uuid;not null;index" json:"fromLecturerId"` // dosen wali yang melimpahkan
+	ToLecturerID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"toLecturerId"`   // dosen penerima delegasi
+	StartsAt       time.Time  `gorm:"not null" json:"startsAt"`
+	EndsAt         time.Time  `gorm:"not null" json:"endsAt"`
+	CreatedBy      uuid.UUID  `gorm:"type:uuid;not null" json:"createdBy"` // users.id pembuat (dosen sendiri / admin)
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// AchievementTarget menyimpan target jumlah prestasi verified per tahun akademik.
+// ProgramStudy kosong ("") = default global; baris dengan ProgramStudy terisi meng-override default.
+type AchievementTarget struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProgramStudy    string    `gorm:"type:varchar(100);not null;default:'';uniqueIndex" json:"programStudy"`
+	VerifiedPerYear int       `gorm:"not null" json:"verifiedPerYear"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// AchievementType adalah 1 entri katalog tipe prestasi (achievementType di dokumen Mongo).
+// Tipe tidak dihapus permanen karena dirujuk dokumen lama; cukup dinonaktifkan (Active=false).
+type AchievementType struct {
+	Code          string    `gorm:"type:varchar(30);primaryKey" json:"code"` // huruf kecil, mis. competition
+	Label         string    `gorm:"type:varchar(100);not null" json:"label"`
+	Active        bool      `gorm:"not null" json:"active"`
+	DefaultPoints float64   `gorm:"type:numeric(8,2);not null;default:0" json:"defaultPoints"` // poin jika tidak ada point_rules yang cocok
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// PointRule adalah 1 baris rubrik poin prestasi. Kolom opsional (nil) berarti "semua nilai";
+// saat menghitung poin dipakai aturan paling spesifik yang cocok dengan details prestasi.
+type PointRule struct {
+	ID               uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AchievementType  string    `gorm:"type:varchar(30);not null;index" json:"achievementType"`
+	CompetitionLevel *string   `gorm:"type:varchar(30)" json:"competitionLevel,omitempty"` // international/national/regional/local
+	Rank             *int      `json:"rank,omitempty"`
+	MedalType        *string   `gorm:"type:varchar(30)" json:"medalType,omitempty"` // gold/silver/bronze
+	Points           float64   `gorm:"type:numeric(8,2);not null" json:"points"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// Holiday adalah 1 tanggal libur (nasional/kampus) yang tidak dihitung sebagai hari kerja.
+type Holiday struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex" json:"date"`
+	Name      string    `gorm:"type:varchar(150);not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// PendingNotification adalah notifikasi yang belum terkirim: limpahan saat antrean in-memory
+// penuh, atau notifikasi yang gagal dikirim dan menunggu retry. Tidak ada notifikasi yang dibuang;
+// setelah melewati batas percobaan, baris ditandai Failed dan tetap tersimpan untuk ditinjau admin.
+type PendingNotification struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RecipientType string     `gorm:"type:varchar(20);not null" json:"recipientType"` // student | lecturer | user
+	RecipientID   uuid.UUID  `gorm:"type:uuid;not null" json:"recipientId"`
+	Type          string     `gorm:"type:varchar(50);not null" json:"type"` // contoh: achievement.verified
+	AchievementID *uuid.UUID `gorm:"type:uuid" json:"achievementId,omitempty"`
+	Message       string     `gorm:"not null" json:"message"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"nextAttemptAt"`
+	LastError     string     `json:"lastError,omitempty"`
+	Failed        bool       `gorm:"not null;default:false;index" json:"failed"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	// IdempotencyKey: kunci dedup untuk notifikasi dari outbox; baris dengan key yang sama
+	// tidak disimpan dua kali, dan Notifier meneruskannya agar penerima bisa men-dedup.
+	IdempotencyKey *string `gorm:"type:varchar(100);uniqueIndex" json:"idempotencyKey,omitempty"`
+}
+
+// OutboxEvent adalah event domain yang ditulis dalam transaksi yang sama dengan perubahan status
+// (transactional outbox). Dispatcher mengirimnya ke lapisan notifikasi/webhook dengan
+// idempotency key = ID event, lalu mengisi DispatchedAt. Crash di antara commit dan kirim
+// tidak menghilangkan event; pengiriman ulang setelah restart bisa di-dedup penerima lewat key.
+type OutboxEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventType     string     `gorm:"type:varchar(50);not null" json:"eventType"` // contoh: achievement.verified
+	AggregateID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"aggregateId"`
+	Payload       string     `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null" json:"nextAttemptAt"`
+	LastError     string     `json:"lastError,omitempty"`
+	Failed        bool       `gorm:"not null;default:false" json:"failed"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	DispatchedAt  *time.Time `gorm:"index" json:"dispatchedAt,omitempty"`
+}
+
+// OutboxDelivery mencatat publisher yang sudah berhasil menerima sebuah event outbox.
+// Saat event di-retry (karena publisher lain gagal), publisher yang sudah tercatat dilewati
+// sehingga notifikasi/webhook yang sudah terkirim tidak diulang.
+type OutboxDelivery struct {
+	EventID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"eventId"`
+	Publisher   string    `gorm:"type:varchar(50);primaryKey" json:"publisher"`
+	DeliveredAt time.Time `gorm:"not null" json:"deliveredAt"`
+}
+
+// RetentionPolicy menyimpan masa retensi (tahun) per kelas data, diatur admin.
+// Kelas "student_personal": data pribadi mahasiswa dianonimkan N tahun setelah lulus.
+type RetentionPolicy struct {
+	DataClass      string     `gorm:"type:varchar(50);primaryKey" json:"dataClass"`
+	RetentionYears int        `gorm:"not null" json:"retentionYears"`
+	UpdatedBy      *uuid.UUID `gorm:"type:uuid" json:"updatedBy,omitempty"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// Permission yang boleh diberikan ke API key (integrasi machine-to-machine, read-only).
+const (
+	PermissionAchievementRead = "achievement:read"
+	PermissionReportRead      = "report:read"
+	PermissionStudentRead     = "student:read"  // daftar mahasiswa (GET /students)
+	PermissionLecturerRead    = "lecturer:read" // daftar dosen (GET /lecturers)
+)
+
+// APIKeyPermissions mengembalikan daftar permission yang valid untuk API key.
+func APIKeyPermissions() []string {
+	return []string{PermissionAchievementRead, PermissionReportRead, PermissionStudentRead, PermissionLecturerRead}
+}
+
+// APIKey adalah kredensial machine-to-machine (mis. portal fakultas) yang dikelola admin.
+// Key mentah hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash SHA-256-nya.
+type APIKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix      string     `gorm:"type:varchar(16);uniqueIndex;not null" json:"prefix"` // bagian awal key, untuk lookup & identifikasi
+	KeyHash     string     `gorm:"type:varchar(64);not null" json:"-"`
+	Permissions []string   `gorm:"serializer:json;type:jsonb;not null" json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP  string     `gorm:"type:varchar(64)" json:"lastUsedIp,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"createdBy"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// HasPermission true jika key memiliki permission tsb.
+func (k *APIKey) HasPermission(perm string) bool {
+	for _, p := range k.Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshToken mencatat setiap refresh token yang diterbitkan (berdasarkan jti), sehingga
+// token bisa dicabut walau JWT stateless. Setiap refresh merotasi token dalam 1 family;
+// pemakaian ulang token yang sudah dirotasi mencabut seluruh family (indikasi kebocoran).
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	JTI       string     `gorm:"column:jti;type:varchar(64);uniqueIndex;not null"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index"` // sama untuk semua hasil rotasi 1 login
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time `gorm:"index"`
+	UserAgent string     `gorm:"type:varchar(512)"` // klien yang login / merotasi token ini
+	IP        string     `gorm:"column:ip;type:varchar(45)"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// RevokedToken adalah denylist access token (berdasarkan jti) yang dicabut sebelum kedaluwarsa,
+// misalnya saat logout. Baris dihapus job cleanup setelah ExpiresAt (token sudah tidak valid).
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Alasan gagal percobaan login (kolom login_events.failure_reason).
+const (
+	LoginFailureUnknownUser     = "unknown_user"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInactiveAccount = "inactive_account"
+	LoginFailureInvalid2FA      = "invalid_2fa_code"
+	LoginFailureSessionLimit    = "session_limit"
+)
+
+// LoginEvent mencatat setiap percobaan login (berhasil maupun gagal) untuk audit keamanan.
+// UserID nil jika username/email yang dicoba tidak dikenal.
+type LoginEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index:idx_login_events_user_created,priority:1" json:"userId"`
+	Username      string     `gorm:"type:varchar(255);not null" json:"username"` // username/email yang dikirim
+	IP            string     `gorm:"column:ip;type:varchar(45)" json:"ip"`
+	UserAgent     string     `gorm:"type:varchar(512)" json:"userAgent"`
+	Success       bool       `gorm:"not null" json:"success"`
+	FailureReason string     `gorm:"type:varchar(32)" json:"failureReason,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime;index:idx_login_events_user_created,priority:2" json:"createdAt"`
+}
+
+// PasswordHistory menyimpan hash password yang pernah dipakai user (ditambah setiap ganti
+// password, dipangkas ke beberapa entri terakhir) agar password lama tidak dipakai ulang.
+type PasswordHistory struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_password_history_user_created,priority:1"`
+	Hash      string    `gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_password_history_user_created,priority:2"`
+}
+
+// TableName: tabel tunggal password_history (bukan password_histories).
+func (PasswordHistory) TableName() string {
+	return "password_history"
 }
@@ -0,0 +1,47 @@
+package model
+
+// Kategori alasan penolakan prestasi (achievement_references.rejection_category).
+// Prestasi yang ditolak sebelum kategori ada tidak memiliki kategori (NULL).
+const (
+	RejectionInsufficientEvidence = "insufficient_evidence"
+	RejectionWrongType            = "wrong_type"
+	RejectionDuplicate            = "duplicate"
+	RejectionInvalidData          = "invalid_data"
+	RejectionOther                = "other"
+)
+
+// RejectionOtherMinNoteLength: panjang minimal catatan penolakan untuk kategori "other".
+const RejectionOtherMinNoteLength = 20
+
+// rejectionCategoryLabels: label tampilan (Bahasa Indonesia) per kategori.
+var rejectionCategoryLabels = []struct{ Value, Label string }{
+	{RejectionInsufficientEvidence, "Bukti tidak memadai"},
+	{RejectionWrongType, "Tipe prestasi tidak sesuai"},
+	{RejectionDuplicate, "Duplikat prestasi lain"},
+	{RejectionInvalidData, "Data tidak valid"},
+	{RejectionOther, "Lainnya"},
+}
+
+// RejectionCategories mengembalikan semua kategori penolakan yang valid.
+func RejectionCategories() []string {
+	out := make([]string, 0, len(rejectionCategoryLabels))
+	for _, c := range rejectionCategoryLabels {
+		out = append(out, c.Value)
+	}
+	return out
+}
+
+// RejectionCategoryLabel mengembalikan label tampilan kategori ("" jika tidak dikenal).
+func RejectionCategoryLabel(category string) string {
+	for _, c := range rejectionCategoryLabels {
+		if c.Value == category {
+			return c.Label
+		}
+	}
+	return ""
+}
+
+// IsValidRejectionCategory mengecek apakah kategori termasuk daftar resmi.
+func IsValidRejectionCategory(category string) bool {
+	return RejectionCategoryLabel(category) != ""
+}
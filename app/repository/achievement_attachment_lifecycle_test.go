@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+)
+
+// TestSoftDeleteRestorePurgeAttachments: soft-delete menandai lampiran deleted dan mengeluarkan
+// draft dari hitungan kuota, restore mengembalikan keduanya, purge menghapus dokumen & reference.
+func TestSoftDeleteRestorePurgeAttachments(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: openTestMongo(t), mongoTx: &mongoTxSupport{}}
+	student := createTestStudent(t, pgDB)
+	ctx := context.Background()
+	ref, _ := newEditableAchievement(t, r, student)
+	newEditableAchievement(t, r, student)
+	id := ref.ID.String()
+	if err := r.AddAttachment(ctx, id, model.Attachment{ID: "att-1", FileName: "bukti.pdf",
+		FileURL: "/uploads/achievements/" + id + "/a1.pdf", FileType: "pdf", UploadedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(step string, wantDrafts int64, wantDeleted bool) {
+		t.Helper()
+		drafts, err := r.CountDraftsByStudent(student.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if drafts != wantDrafts {
+			t.Fatalf("%s: %d draft, want %d", step, drafts, wantDrafts)
+		}
+		doc, err := r.FindDocumentByReference(ctx, id)
+		if err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+		if len(doc.Attachments) != 1 || doc.Attachments[0].Deleted != wantDeleted {
+			t.Fatalf("%s: lampiran %+v, want deleted=%v", step, doc.Attachments, wantDeleted)
+		}
+	}
+
+	check("awal", 2, false)
+	if err := r.UpdateStatus(ctx, id, model.StatusDeleted, UpdateStatusOptions{ExpectedStatus: model.StatusDraft}); err != nil {
+		t.Fatal(err)
+	}
+	check("setelah delete", 1, true)
+	if err := r.Restore(ctx, id, student.UserID); err != nil {
+		t.Fatal(err)
+	}
+	check("setelah restore", 2, false)
+
+	if err := r.Purge(ctx, id, PurgeOptions{}); !errors.Is(err, ErrPurgeNotDeleted) {
+		t.Fatalf("purge draft tanpa hard: %v, want ErrPurgeNotDeleted", err)
+	}
+	if err := r.UpdateStatus(ctx, id, model.StatusDeleted, UpdateStatusOptions{ExpectedStatus: model.StatusDraft}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Purge(ctx, id, PurgeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.FindDocumentByReference(ctx, id); err == nil {
+		t.Fatal("dokumen masih ada setelah purge")
+	}
+	if drafts, _ := r.CountDraftsByStudent(student.ID); drafts != 1 {
+		t.Fatalf("setelah purge: %d draft, want 1", drafts)
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCommentNotFound dikembalikan jika komentar tidak ada pada prestasi tersebut.
+var ErrCommentNotFound = errors.New("comment not found")
+
+// AchievementCommentRepository mengelola komentar prestasi (Mongo, collection achievement_comments).
+type AchievementCommentRepository interface {
+	// Create menyimpan komentar baru (ID diisi).
+	Create(ctx context.Context, comment *model.AchievementComment) error
+	// FindByAchievement: semua komentar 1 prestasi, terlama dulu.
+	FindByAchievement(ctx context.Context, achievementID string) ([]model.AchievementComment, error)
+	// FindByID: 1 komentar milik prestasi achievementID.
+	FindByID(ctx context.Context, achievementID, commentID string) (*model.AchievementComment, error)
+	// Delete menghapus 1 komentar milik prestasi achievementID.
+	Delete(ctx context.Context, achievementID, commentID string) error
+	// Count: jumlah komentar 1 prestasi.
+	Count(ctx context.Context, achievementID string) (int64, error)
+	// FindReceived: komentar pada prestasi-prestasi achievementIDs yang ditulis selain
+	// excludeAuthor (terbaru dulu), dibatasi limit.
+	FindReceived(ctx context.Context, achievementIDs []string, excludeAuthor uuid.UUID, limit int) ([]model.AchievementComment, error)
+}
+
+type achievementCommentRepository struct {
+	col *mongo.Collection
+}
+
+// NewAchievementCommentRepository membuat instance AchievementCommentRepository.
+func NewAchievementCommentRepository(mongoDB *mongo.Database) AchievementCommentRepository {
+	return &achievementCommentRepository{col: mongoDB.Collection("achievement_comments")}
+}
+
+// Create lihat dokumentasi di interface.
+func (r *achievementCommentRepository) Create(ctx context.Context, comment *model.AchievementComment) error {
+	comment.ID = primitive.NewObjectID()
+	_, err := r.col.InsertOne(ctx, comment)
+	return err
+}
+
+// FindByAchievement lihat dokumentasi di interface.
+func (r *achievementCommentRepository) FindByAchievement(ctx context.Context, achievementID string) ([]model.AchievementComment, error) {
+	cur, err := r.col.Find(ctx, bson.M{"achievementId": achievementID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	comments := []model.AchievementComment{}
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// FindByID lihat dokumentasi di interface.
+func (r *achievementCommentRepository) FindByID(ctx context.Context, achievementID, commentID string) (*model.AchievementComment, error) {
+	objID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, ErrCommentNotFound
+	}
+	var comment model.AchievementComment
+	err = r.col.FindOne(ctx, bson.M{"_id": objID, "achievementId": achievementID}).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Delete lihat dokumentasi di interface.
+func (r *achievementCommentRepository) Delete(ctx context.Context, achievementID, commentID string) error {
+	objID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return ErrCommentNotFound
+	}
+	res, err := r.col.DeleteOne(ctx, bson.M{"_id": objID, "achievementId": achievementID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// Count lihat dokumentasi di interface.
+func (r *achievementCommentRepository) Count(ctx context.Context, achievementID string) (int64, error) {
+	return r.col.CountDocuments(ctx, bson.M{"achievementId": achievementID})
+}
+
+// FindReceived lihat dokumentasi di interface.
+func (r *achievementCommentRepository) FindReceived(ctx context.Context, achievementIDs []string, excludeAuthor uuid.UUID, limit int) ([]model.AchievementComment, error) {
+	comments := []model.AchievementComment{}
+	if len(achievementIDs) == 0 {
+		return comments, nil
+	}
+	cur, err := r.col.Find(ctx,
+		bson.M{"achievementId": bson.M{"$in": achievementIDs}, "authorUserId": bson.M{"$ne": excludeAuthor}},
+		options.Find().
+			SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
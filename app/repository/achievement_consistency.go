@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// Jenis ketidakcocokan data prestasi antara PostgreSQL dan MongoDB.
+const (
+	ConsistencyOrphanedReference = "orphaned_reference" // reference tanpa dokumen Mongo
+	ConsistencyOrphanedDocument  = "orphaned_document"  // dokumen Mongo tanpa reference
+	ConsistencyStatusMismatch    = "status_mismatch"    // status deleted berbeda di kedua sisi
+)
+
+// consistencyBatchSize: jumlah baris/dokumen per batch pemindaian.
+const consistencyBatchSize = 500
+
+// orphanedReferenceNote adalah catatan status log saat reference yatim dihapus lewat repair.
+const orphanedReferenceNote = "Dokumen detail prestasi tidak ditemukan (perbaikan konsistensi data)"
+
+// ConsistencyIssue adalah 1 ketidakcocokan yang ditemukan CheckConsistency.
+type ConsistencyIssue struct {
+	Kind          string     `json:"kind"`
+	AchievementID *uuid.UUID `json:"achievementId,omitempty"`
+	MongoID       string     `json:"mongoId,omitempty"`
+	StudentID     string     `json:"studentId,omitempty"`
+	Status        string     `json:"status,omitempty"`       // status di Postgres
+	MongoDeleted  *bool      `json:"mongoDeleted,omitempty"` // flag deleted di Mongo
+}
+
+// ConsistencyReport adalah hasil pemindaian kedua database.
+type ConsistencyReport struct {
+	Issues            []ConsistencyIssue `json:"-"`
+	Counts            map[string]int     `json:"counts"`
+	ScannedReferences int                `json:"scannedReferences"`
+	ScannedDocuments  int                `json:"scannedDocuments"`
+}
+
+// CheckConsistency lihat dokumentasi di interface.
+func (r *achievementRepository) CheckConsistency(ctx context.Context) (*ConsistencyReport, error) {
+	report := &ConsistencyReport{
+		Issues: []ConsistencyIssue{},
+		Counts: map[string]int{
+			ConsistencyOrphanedReference: 0,
+			ConsistencyOrphanedDocument:  0,
+			ConsistencyStatusMismatch:    0,
+		},
+	}
+	add := func(issue ConsistencyIssue) {
+		report.Issues = append(report.Issues, issue)
+		report.Counts[issue.Kind]++
+	}
+
+	if err := r.scanReferences(ctx, report, add); err != nil {
+		return nil, err
+	}
+	if err := r.scanDocuments(ctx, report, add); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// scanReferences memeriksa semua reference (keyset per id) terhadap dokumen Mongo:
+// 1 query Postgres + 1 query Mongo ($in) per batch.
+func (r *achievementRepository) scanReferences(ctx context.Context, report *ConsistencyReport, add func(ConsistencyIssue)) error {
+	var after uuid.UUID
+	for {
+		var refs []model.AchievementReference
+		if err := r.pgDB.WithContext(ctx).
+			Select("id", "student_id", "mongo_achievement_id", "status").
+			Where("id > ?", after).
+			Order("id ASC").
+			Limit(consistencyBatchSize).
+			Find(&refs).Error; err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			return nil
+		}
+		report.ScannedReferences += len(refs)
+		after = refs[len(refs)-1].ID
+
+		oids := make([]primitive.ObjectID, 0, len(refs))
+		for _, ref := range refs {
+			if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		deletedByID, err := r.documentDeletedFlags(ctx, bson.M{"_id": bson.M{"$in": oids}}, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range refs {
+			issue := ConsistencyIssue{
+				AchievementID: &ref.ID,
+				MongoID:       ref.MongoAchievementID,
+				StudentID:     ref.StudentID.String(),
+				Status:        ref.Status,
+			}
+			deleted, found := deletedByID[ref.MongoAchievementID]
+			switch {
+			case !found:
+				issue.Kind = ConsistencyOrphanedReference
+			case deleted != (ref.Status == model.StatusDeleted):
+				issue.Kind = ConsistencyStatusMismatch
+				issue.MongoDeleted = &deleted
+			default:
+				continue
+			}
+			add(issue)
+		}
+	}
+}
+
+// scanDocuments mencari dokumen Mongo tanpa reference (keyset per _id):
+// 1 query Mongo + 1 query Postgres (IN) per batch.
+func (r *achievementRepository) scanDocuments(ctx context.Context, report *ConsistencyReport, add func(ConsistencyIssue)) error {
+	after := primitive.NilObjectID
+	for {
+		studentByID := map[string]string{}
+		deletedByID, err := r.documentDeletedFlags(ctx, bson.M{"_id": bson.M{"$gt": after}}, studentByID)
+		if err != nil {
+			return err
+		}
+		if len(deletedByID) == 0 {
+			return nil
+		}
+		report.ScannedDocuments += len(deletedByID)
+
+		mongoIDs := sortedKeys(deletedByID)
+		if after, err = primitive.ObjectIDFromHex(mongoIDs[len(mongoIDs)-1]); err != nil {
+			return err
+		}
+		var referenced []string
+		if err := r.pgDB.WithContext(ctx).Model(&model.AchievementReference{}).
+			Where("mongo_achievement_id IN ?", mongoIDs).
+			Pluck("mongo_achievement_id", &referenced).Error; err != nil {
+			return err
+		}
+		hasRef := make(map[string]bool, len(referenced))
+		for _, id := range referenced {
+			hasRef[id] = true
+		}
+
+		for _, id := range mongoIDs {
+			if hasRef[id] {
+				continue
+			}
+			deleted := deletedByID[id]
+			add(ConsistencyIssue{
+				Kind:         ConsistencyOrphanedDocument,
+				MongoID:      id,
+				StudentID:    studentByID[id],
+				MongoDeleted: &deleted,
+			})
+		}
+	}
+}
+
+// documentDeletedFlags mengambil maksimal consistencyBatchSize dokumen (urut _id) yang cocok
+// dengan filter: map _id hex → flag deleted. Jika studentByID tidak nil, studentId ikut diisi.
+func (r *achievementRepository) documentDeletedFlags(ctx context.Context, filter bson.M, studentByID map[string]string) (map[string]bool, error) {
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx, filter,
+		options.Find().
+			SetProjection(bson.M{"_id": 1, "deleted": 1, "studentId": 1}).
+			SetSort(bson.M{"_id": 1}).
+			SetLimit(consistencyBatchSize))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := map[string]bool{}
+	for cur.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Deleted   bool               `bson:"deleted"`
+			StudentID any                `bson:"studentId"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		out[doc.ID.Hex()] = doc.Deleted
+		if studentByID != nil {
+			studentByID[doc.ID.Hex()] = studentIDString(doc.StudentID)
+		}
+	}
+	return out, cur.Err()
+}
+
+// studentIDString mengubah studentId dokumen (UUID binary atau string lama) menjadi string UUID.
+func studentIDString(v any) string {
+	switch id := v.(type) {
+	case primitive.Binary:
+		if parsed, err := uuid.FromBytes(id.Data); err == nil {
+			return parsed.String()
+		}
+	case string:
+		return id
+	}
+	return ""
+}
+
+// sortedKeys mengembalikan key map terurut (hasil pemindaian deterministik).
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// MarkReferencesDeleted lihat dokumentasi di interface.
+func (r *achievementRepository) MarkReferencesDeleted(ids []uuid.UUID, actorID uuid.UUID) (int, error) {
+	marked := 0
+	err := r.pgDB.Transaction(func(tx *gorm.DB) error {
+		var refs []model.AchievementReference
+		if err := tx.Where("id IN ? AND status != ?", ids, model.StatusDeleted).Find(&refs).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		note := orphanedReferenceNote
+		for i := range refs {
+			if err := tx.Model(&refs[i]).Updates(map[string]any{
+				"status":     model.StatusDeleted,
+				"updated_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&model.AchievementStatusLog{
+				AchievementReferenceID: refs[i].ID,
+				FromStatus:             refs[i].Status,
+				ToStatus:               model.StatusDeleted,
+				ActorUserID:            &actorID,
+				Note:                   &note,
+				CreatedAt:              now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		marked = len(refs)
+		return nil
+	})
+	return marked, err
+}
+
+// FlagOrphanedDocuments lihat dokumentasi di interface.
+func (r *achievementRepository) FlagOrphanedDocuments(ctx context.Context, mongoIDs []string) (int64, error) {
+	oids := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return 0, nil
+	}
+	res, err := r.mongoDB.Collection("achievements").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": oids}},
+		bson.M{"$set": bson.M{"deleted": true, "deletedAt": time.Now(), "orphaned": true}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
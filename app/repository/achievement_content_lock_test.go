@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
+)
+
+// newEditableAchievement membuat dokumen Mongo + reference berstatus draft untuk test edit isi.
+func newEditableAchievement(t *testing.T, r *achievementRepository, student model.Student) (model.AchievementReference, bson.M) {
+	t.Helper()
+	ctx := context.Background()
+	doc := model.Achievement{StudentID: student.ID, AchievementType: "competition", Title: "Judul awal",
+		SchemaVersion: model.AchievementSchemaVersion, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	res, err := r.mongoDB.Collection("achievements").InsertOne(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := model.AchievementReference{StudentID: student.ID, Status: model.StatusDraft,
+		MongoAchievementID: res.InsertedID.(primitive.ObjectID).Hex()}
+	if err := r.pgDB.Create(&ref).Error; err != nil {
+		t.Fatal(err)
+	}
+	return ref, bson.M{"_id": res.InsertedID}
+}
+
+// contentTitle membaca judul dokumen Mongo saat ini.
+func contentTitle(t *testing.T, r *achievementRepository, filter bson.M) string {
+	t.Helper()
+	var doc model.Achievement
+	if err := r.mongoDB.Collection("achievements").FindOne(context.Background(), filter).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc.Title
+}
+
+// TestUpdateContentWaitsForStatusChange: edit isi yang datang saat pengajuan sedang ditulis
+// (baris reference terkunci) menunggu lock, lalu ditolak ErrContentLocked tanpa menyentuh Mongo.
+func TestUpdateContentWaitsForStatusChange(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: openTestMongo(t), mongoTx: &mongoTxSupport{}}
+	student := createTestStudent(t, pgDB)
+	ref, filter := newEditableAchievement(t, r, student)
+
+	// Transaksi "submit" mengunci baris dan mengubah status, tapi belum commit.
+	locked, release := make(chan struct{}), make(chan struct{})
+	submitDone := make(chan error, 1)
+	go func() {
+		submitDone <- pgDB.Transaction(func(tx *gorm.DB) error {
+			if _, err := lockEditableReference(tx, ref.ID.String()); err != nil {
+				return err
+			}
+			if err := tx.Model(&model.AchievementReference{}).Where("id = ?", ref.ID).
+				Update("status", model.StatusSubmitted).Error; err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	editDone := make(chan error, 1)
+	go func() {
+		editDone <- r.UpdateContent(context.Background(), ref.ID.String(),
+			&model.Achievement{AchievementType: "competition", Title: "Judul baru"})
+	}()
+	select {
+	case err := <-editDone:
+		t.Fatalf("edit isi tidak menunggu lock reference: err = %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-submitDone; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-editDone; !errors.Is(err, ErrContentLocked) {
+		t.Fatalf("edit setelah submit: err = %v, want ErrContentLocked", err)
+	}
+	if got := contentTitle(t, r, filter); got != "Judul awal" {
+		t.Fatalf("judul = %q, isi prestasi submitted ikut berubah", got)
+	}
+}
+
+// TestUpdateContentRacesSubmit: edit isi dan submit berjalan bersamaan berkali-kali; apa pun
+// urutannya, isi hanya berubah jika edit selesai selagi status masih draft.
+func TestUpdateContentRacesSubmit(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: openTestMongo(t), mongoTx: &mongoTxSupport{}}
+	student := createTestStudent(t, pgDB)
+
+	for i := 0; i < 20; i++ {
+		ref, filter := newEditableAchievement(t, r, student)
+
+		start := make(chan struct{})
+		var editErr, submitErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			editErr = r.UpdateContent(context.Background(), ref.ID.String(),
+				&model.Achievement{AchievementType: "competition", Title: "Judul baru"})
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			submitErr = r.UpdateStatus(context.Background(), ref.ID.String(), model.StatusSubmitted,
+				UpdateStatusOptions{ExpectedStatus: model.StatusDraft})
+		}()
+		close(start)
+		wg.Wait()
+
+		if submitErr != nil {
+			t.Fatalf("iterasi %d: submit err = %v", i, submitErr)
+		}
+		title := contentTitle(t, r, filter)
+		switch {
+		case editErr == nil && title != "Judul baru":
+			t.Fatalf("iterasi %d: edit berhasil tapi judul %q", i, title)
+		case errors.Is(editErr, ErrContentLocked) && title != "Judul awal":
+			t.Fatalf("iterasi %d: edit ditolak tapi judul berubah menjadi %q", i, title)
+		case editErr != nil && !errors.Is(editErr, ErrContentLocked):
+			t.Fatalf("iterasi %d: edit err = %v", i, editErr)
+		}
+	}
+}
+
+// TestUpdateContentKeepsAttachments: PUT isi prestasi tidak menimpa lampiran yang diunggah lewat
+// endpoint attachments, walaupun dokumen update membawa daftar lampiran lain.
+func TestUpdateContentKeepsAttachments(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: openTestMongo(t), mongoTx: &mongoTxSupport{}}
+	student := createTestStudent(t, pgDB)
+	ref, filter := newEditableAchievement(t, r, student)
+
+	uploaded := model.Attachment{ID: "att-1", FileName: "sertifikat.pdf", FileURL: "/uploads/x/sertifikat.pdf", FileType: "pdf"}
+	if err := r.AddAttachment(context.Background(), ref.ID.String(), uploaded); err != nil {
+		t.Fatal(err)
+	}
+	injected := []model.Attachment{{FileName: "palsu.pdf", FileURL: "https://contoh.invalid/palsu.pdf"}}
+	if err := r.UpdateContent(context.Background(), ref.ID.String(),
+		&model.Achievement{AchievementType: "competition", Title: "Judul baru", Attachments: injected}); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc model.Achievement
+	if err := r.mongoDB.Collection("achievements").FindOne(context.Background(), filter).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Judul baru" {
+		t.Fatalf("judul %q, want Judul baru", doc.Title)
+	}
+	if len(doc.Attachments) != 1 || doc.Attachments[0].ID != "att-1" {
+		t.Fatalf("lampiran %+v, want hanya att-1", doc.Attachments)
+	}
+}
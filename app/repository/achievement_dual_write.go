@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// mongoTxSupport mendeteksi apakah MongoDB mendukung transaksi: hanya replica set dan
+// sharded cluster (mongos), bukan server standalone. Hanya jawaban pasti yang di-cache;
+// jika deteksi gagal (mis. Mongo belum siap), request berikutnya mencoba lagi.
+type mongoTxSupport struct {
+	mu        sync.Mutex
+	known     bool
+	supported bool
+}
+
+// mongoTxProbeTimeout membatasi perintah hello agar deteksi tidak ikut batal/menggantung
+// bersama context request yang memicunya.
+const mongoTxProbeTimeout = 5 * time.Second
+
+// check menjalankan perintah hello; selama deteksi gagal dianggap tidak mendukung transaksi.
+func (m *mongoTxSupport) check(ctx context.Context, db *mongo.Database) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.known {
+		return m.supported
+	}
+
+	hctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mongoTxProbeTimeout)
+	defer cancel()
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(hctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		log.Printf("⚠️  deteksi transaksi MongoDB gagal, memakai kompensasi manual: %v", err)
+		return false
+	}
+	m.known, m.supported = true, hello.SetName != "" || hello.Msg == "isdbgrid"
+	return m.supported
+}
+
+// dualWrite adalah 1 penulisan yang menyentuh MongoDB dan PostgreSQL.
+type dualWrite struct {
+	lock  func(tx *gorm.DB) error         // opsional: kunci/validasi baris Postgres sebelum Mongo disentuh
+	mongo func(ctx context.Context) error // perubahan di MongoDB
+	pg    func(tx *gorm.DB) error         // perubahan di PostgreSQL (dalam transaksi, belum commit)
+
+	// undo membatalkan perubahan Mongo yang sudah permanen; dicatat sebagai span event undoEvent
+	// dengan id dokumen *oid (dibaca saat kompensasi, jadi boleh diisi oleh langkah mongo;
+	// nil untuk penulisan banyak dokumen).
+	undo      func(ctx context.Context) error
+	undoEvent string
+	oid       *primitive.ObjectID
+}
+
+// runDualWrite menjalankan w dengan commit PostgreSQL paling akhir (w.lock, jika ada, dijalankan
+// lebih dulu dalam transaksi Postgres yang sama sehingga baris terkunci selama penulisan Mongo):
+//   - Mongo mendukung transaksi: perubahan Mongo & Postgres dijalankan dalam transaksi Mongo;
+//     gagal di Postgres membatalkan (abort) transaksi Mongo tanpa kompensasi. Kompensasi hanya
+//     diperlukan jika commit Postgres gagal setelah transaksi Mongo di-commit.
+//   - Mongo standalone: perubahan Mongo langsung permanen, dibatalkan dengan w.undo jika
+//     langkah Postgres (termasuk commit) gagal.
+func (r *achievementRepository) runDualWrite(ctx context.Context, span trace.Span, w dualWrite) error {
+	tx := r.pgDB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	undo := func() {
+		var oid primitive.ObjectID
+		if w.oid != nil {
+			oid = *w.oid
+		}
+		r.compensate(context.WithoutCancel(ctx), span, w.undoEvent, oid, w.undo)
+	}
+	if w.lock != nil {
+		if err := w.lock(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if r.mongoTx.check(ctx, r.mongoDB) {
+		sess, err := r.mongoDB.Client().StartSession()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		defer sess.EndSession(context.WithoutCancel(ctx))
+
+		err = mongo.WithSession(ctx, sess, func(sc mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+			if err := w.mongo(sc); err != nil {
+				_ = sess.AbortTransaction(context.WithoutCancel(sc))
+				return err
+			}
+			if err := w.pg(tx); err != nil {
+				_ = sess.AbortTransaction(context.WithoutCancel(sc))
+				return err
+			}
+			return sess.CommitTransaction(sc)
+		})
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit().Error; err != nil {
+			undo()
+			return err
+		}
+		return nil
+	}
+
+	if err := w.mongo(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := w.pg(tx); err != nil {
+		tx.Rollback()
+		undo()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestMongoTxSupportRetriesAfterFailedProbe(t *testing.T) {
+	// Server tidak terjangkau: deteksi gagal dan tidak boleh di-cache.
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	m := &mongoTxSupport{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // context request yang sudah selesai tidak boleh memengaruhi hasil deteksi
+	if m.check(ctx, client.Database("x")) {
+		t.Fatal("deteksi gagal dianggap mendukung transaksi")
+	}
+	if m.known {
+		t.Fatal("hasil deteksi yang gagal ikut di-cache")
+	}
+
+	if os.Getenv("TEST_MONGO_URI") == "" {
+		return
+	}
+	want := m.check(context.Background(), openTestMongo(t))
+	if !m.known || m.supported != want {
+		t.Fatalf("deteksi berhasil tidak di-cache: known=%v supported=%v", m.known, m.supported)
+	}
+}
+
+// TestDualWritePostgresFailureRevertsMongo mensimulasikan Postgres gagal setelah penulisan
+// Mongo, baik di langkah pg maupun saat commit, pada mode transaksi Mongo & standalone.
+func TestDualWritePostgresFailureRevertsMongo(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	mongoDB := openTestMongo(t)
+	coll := mongoDB.Collection("dual_write_test")
+
+	failStep := func(tx *gorm.DB) error { return errors.New("postgres down") }
+	// Constraint DEFERRABLE baru dicek saat COMMIT, jadi langkah pg berhasil tapi commit gagal.
+	failCommit := func(tx *gorm.DB) error {
+		return tx.Exec(`CREATE TEMP TABLE dual_write_commit_fail (id int UNIQUE DEFERRABLE INITIALLY DEFERRED) ON COMMIT DROP;
+			INSERT INTO dual_write_commit_fail VALUES (1), (1);`).Error
+	}
+
+	tests := []struct {
+		name    string
+		mongoTx bool
+		pg      func(tx *gorm.DB) error
+	}{
+		{name: "standalone, langkah pg gagal", pg: failStep},
+		{name: "standalone, commit gagal", pg: failCommit},
+		{name: "transaksi mongo, langkah pg gagal", mongoTx: true, pg: failStep},
+		{name: "transaksi mongo, commit gagal", mongoTx: true, pg: failCommit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &achievementRepository{pgDB: pgDB, mongoDB: mongoDB, mongoTx: &mongoTxSupport{known: true, supported: tt.mongoTx}}
+			if tt.mongoTx && !(&mongoTxSupport{}).check(context.Background(), mongoDB) {
+				t.Skip("MongoDB standalone: transaksi tidak didukung")
+			}
+
+			oid := primitive.NewObjectID()
+			err := r.runDualWrite(context.Background(), noop.Span{}, dualWrite{
+				mongo: func(c context.Context) error {
+					_, err := coll.InsertOne(c, bson.M{"_id": oid})
+					return err
+				},
+				pg: tt.pg,
+				undo: func(c context.Context) error {
+					_, err := coll.DeleteOne(c, bson.M{"_id": oid})
+					return err
+				},
+				undoEvent: "test_reverted",
+				oid:       &oid,
+			})
+			if err == nil {
+				t.Fatal("runDualWrite tidak mengembalikan error")
+			}
+			n, cerr := coll.CountDocuments(context.Background(), bson.M{"_id": oid})
+			if cerr != nil {
+				t.Fatal(cerr)
+			}
+			if n != 0 {
+				t.Fatal("dokumen Mongo tetap ada setelah Postgres gagal")
+			}
+		})
+	}
+}
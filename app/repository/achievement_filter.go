@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// AchievementContentFilter menyaring prestasi berdasarkan isi dokumen Mongo.
+// Field kosong/nil = tidak difilter.
+type AchievementContentFilter struct {
+	Type string     // achievementType (persis)
+	Tags []string   // cocok jika dokumen memiliki salah satu tag
+	From *time.Time // createdAt >= From
+	To   *time.Time // createdAt < To
+	// IncludeDeleted: dokumen yang di-soft-delete ikut dicocokkan (hanya list admin).
+	IncludeDeleted bool
+}
+
+// IsEmpty: true jika tidak ada filter isi yang aktif.
+func (f AchievementContentFilter) IsEmpty() bool {
+	return f.Type == "" && len(f.Tags) == 0 && f.From == nil && f.To == nil
+}
+
+// Kunci sort daftar prestasi (?sortBy=).
+const (
+	SortCreatedAt   = "createdAt"
+	SortSubmittedAt = "submittedAt"
+	SortStatus      = "status"
+	SortPoints      = "points" // poin ada di Mongo: diurutkan di service, bukan di query
+)
+
+// AchievementSortKeys mengembalikan kunci sort yang diterima.
+func AchievementSortKeys() []string {
+	return []string{SortCreatedAt, SortSubmittedAt, SortStatus, SortPoints}
+}
+
+// AchievementSort adalah urutan daftar prestasi. Nilai nol = created_at DESC (urutan lama).
+type AchievementSort struct {
+	Field string
+	Asc   bool
+}
+
+// AchievementListFilter adalah filter daftar reference prestasi (Postgres).
+type AchievementListFilter struct {
+	StudentID *string // nil = semua mahasiswa
+	Status    *string
+	// IncludeDeleted: status 'deleted' ikut ditampilkan bersama status lain (hanya list admin).
+	// Tanpa ini, prestasi deleted hanya muncul jika Status = 'deleted' dan StudentID nil;
+	// daftar milik 1 mahasiswa tidak pernah memuat prestasi deleted.
+	IncludeDeleted bool
+	// MongoIDs membatasi ke dokumen hasil FindMongoIDsByContent.
+	// nil = tanpa filter isi; slice kosong = tidak ada yang cocok.
+	MongoIDs []string
+	// Search (admin): cocokkan NIM (students.student_id) atau nama mahasiswa (users.full_name),
+	// ATAU dokumen di SearchMongoIDs (hasil SearchDetailIDs atas judul). Hasil gabungan tanpa
+	// duplikat karena disaring dalam 1 query sebelum pagination.
+	Search         string
+	SearchMongoIDs []string
+	Sort           AchievementSort
+	// Cursor (list admin ?cursor=): keyset pagination created_at DESC, id DESC setelah posisi
+	// cursor, tanpa COUNT/OFFSET; Sort, Page & Unpaged diabaikan.
+	Cursor *ListCursor
+	// Unpaged: ambil semua baris (Page/Limit diabaikan), dipakai sort poin di service.
+	Unpaged bool
+	Page    int
+	Limit   int
+}
+
+// ListCursor adalah posisi baris terakhir halaman sebelumnya (CreatedAt nil = halaman pertama).
+type ListCursor struct {
+	CreatedAt *time.Time
+	ID        uuid.UUID
+}
+
+// FindMongoIDsByContent lihat dokumentasi di interface.
+func (r *achievementRepository) FindMongoIDsByContent(ctx context.Context, filter AchievementContentFilter) ([]string, error) {
+	query := bson.M{}
+	if !filter.IncludeDeleted {
+		query["deleted"] = bson.M{"$ne": true}
+	}
+	if filter.Type != "" {
+		query["achievementType"] = filter.Type
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$in": filter.Tags}
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["createdAt"] = createdAt
+	}
+
+	return r.findDetailIDs(ctx, query)
+}
+
+// SearchDetailIDs lihat dokumentasi di interface. Memakai text index achievements_text.
+func (r *achievementRepository) SearchDetailIDs(ctx context.Context, q string, includeDeleted bool) ([]string, error) {
+	query := bson.M{"$text": bson.M{"$search": q}}
+	if !includeDeleted {
+		query["deleted"] = bson.M{"$ne": true}
+	}
+	return r.findDetailIDs(ctx, query)
+}
+
+// findDetailIDs mengembalikan _id (hex) semua dokumen achievements yang cocok dengan query.
+func (r *achievementRepository) findDetailIDs(ctx context.Context, query bson.M) ([]string, error) {
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx, query,
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	ids := []string{}
+	for cur.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+	return ids, cur.Err()
+}
+
+// FindFiltered lihat dokumentasi di interface.
+func (r *achievementRepository) FindFiltered(ctx context.Context, filter AchievementListFilter) ([]model.AchievementReference, int64, error) {
+	page, limit := NormalizePagination(filter.Page, filter.Limit)
+
+	pg := r.pgDB.WithContext(ctx)
+	db := pg.Model(&model.AchievementReference{})
+	if filter.StudentID != nil {
+		db = db.Where("student_id = ? AND status != 'deleted'", *filter.StudentID)
+	} else if !filter.IncludeDeleted && (filter.Status == nil || *filter.Status != model.StatusDeleted) {
+		db = db.Where("status != ?", model.StatusDeleted)
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		db = db.Where("status = ?", *filter.Status)
+	}
+	db = whereMongoIDs(db, filter.MongoIDs)
+	db = whereSearch(pg, db, filter.Search, filter.SearchMongoIDs)
+
+	if filter.Cursor != nil {
+		refs, err := findAchievementsAfter(db, *filter.Cursor, limit)
+		return refs, -1, err
+	}
+	return findAchievementPage(db, filter.Sort, filter.Unpaged, page, limit)
+}
+
+// findAchievementPage menghitung total lalu mengambil 1 halaman reference sesuai sort.
+// Urutan dijamin deterministik: selalu diakhiri created_at & id sebagai tiebreaker.
+func findAchievementPage(db *gorm.DB, sort AchievementSort, unpaged bool, page, limit int) ([]model.AchievementReference, int64, error) {
+	// Hitung total untuk pagination
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	dir := "DESC"
+	if sort.Asc {
+		dir = "ASC"
+	}
+	switch sort.Field {
+	case SortSubmittedAt:
+		db = db.Order("submitted_at " + dir + " NULLS LAST")
+	case SortStatus:
+		db = db.Order("status " + dir)
+	}
+	if sort.Field == "" || sort.Field == SortCreatedAt {
+		db = db.Order("created_at " + dir).Order("id " + dir)
+	} else {
+		db = db.Order("created_at DESC").Order("id DESC")
+	}
+	if !unpaged {
+		db = db.Offset((page - 1) * limit).Limit(limit)
+	}
+
+	var refs []model.AchievementReference
+	err := db.Find(&refs).Error
+	return refs, total, err
+}
+
+// findAchievementsAfter mengambil limit reference setelah cursor (created_at DESC, id DESC).
+// Baris baru yang masuk di antara dua halaman tidak menggeser halaman berikutnya.
+func findAchievementsAfter(db *gorm.DB, cursor ListCursor, limit int) ([]model.AchievementReference, error) {
+	if cursor.CreatedAt != nil {
+		db = db.Where("(created_at, id) < (?, ?)", *cursor.CreatedAt, cursor.ID)
+	}
+	var refs []model.AchievementReference
+	err := db.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&refs).Error
+	return refs, err
+}
+
+// FindPointsByMongoIDs lihat dokumentasi di interface.
+func (r *achievementRepository) FindPointsByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]float64, error) {
+	points := make(map[string]float64, len(mongoIDs))
+	objIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return points, nil
+	}
+
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx,
+		bson.M{"_id": bson.M{"$in": objIDs}},
+		options.Find().SetProjection(bson.M{"_id": 1, "points": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var doc struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Points float64            `bson:"points"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		points[doc.ID.Hex()] = doc.Points
+	}
+	return points, cur.Err()
+}
+
+// whereMongoIDs menerapkan filter isi (hasil FindMongoIDsByContent) ke query reference.
+func whereMongoIDs(db *gorm.DB, mongoIDs []string) *gorm.DB {
+	switch {
+	case mongoIDs == nil:
+		return db
+	case len(mongoIDs) == 0:
+		return db.Where("1 = 0")
+	default:
+		return db.Where("mongo_achievement_id IN ?", mongoIDs)
+	}
+}
+
+// whereSearch membatasi reference ke mahasiswa yang NIM/namanya mengandung search
+// (tanpa membedakan huruf besar/kecil) atau ke dokumen Mongo titleMongoIDs.
+func whereSearch(pgDB, db *gorm.DB, search string, titleMongoIDs []string) *gorm.DB {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return db
+	}
+	pattern := "%" + escapeLike(search) + "%"
+	students := pgDB.Table("students").Select("students.id").
+		Joins("JOIN users ON users.id = students.user_id").
+		Where("students.student_id ILIKE ? OR users.full_name ILIKE ?", pattern, pattern)
+	if len(titleMongoIDs) == 0 {
+		return db.Where("student_id IN (?)", students)
+	}
+	return db.Where("(student_id IN (?) OR mongo_achievement_id IN ?)", students, titleMongoIDs)
+}
+
+// escapeLike meng-escape karakter wildcard LIKE (\, %, _) pada input pengguna.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// AttachIdentities lihat dokumentasi di interface. Relasi Student pada reference sengaja
+// gorm:"-" (tanpa FK), jadi diisi manual: 1 query students (+users) dan 1 query verifier.
+func (r *achievementRepository) AttachIdentities(ctx context.Context, refs []model.AchievementReference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	studentIDs := make([]uuid.UUID, 0, len(refs))
+	verifierIDs := []uuid.UUID{}
+	for _, ref := range refs {
+		studentIDs = append(studentIDs, ref.StudentID)
+		if ref.VerifiedBy != nil {
+			verifierIDs = append(verifierIDs, *ref.VerifiedBy)
+		}
+	}
+
+	pg := r.pgDB.WithContext(ctx)
+	onlyName := func(db *gorm.DB) *gorm.DB { return db.Select("id", "full_name") }
+
+	var students []model.Student
+	if err := pg.Preload("User", onlyName).
+		Where("id IN ?", studentIDs).Find(&students).Error; err != nil {
+		return err
+	}
+	byStudent := make(map[uuid.UUID]model.Student, len(students))
+	for _, st := range students {
+		byStudent[st.ID] = st
+	}
+
+	byUser := map[uuid.UUID]*model.User{}
+	if len(verifierIDs) > 0 {
+		var verifiers []model.User
+		if err := onlyName(pg).Where("id IN ?", verifierIDs).Find(&verifiers).Error; err != nil {
+			return err
+		}
+		for i := range verifiers {
+			byUser[verifiers[i].ID] = &verifiers[i]
+		}
+	}
+
+	for i := range refs {
+		if st, ok := byStudent[refs[i].StudentID]; ok {
+			refs[i].Student = st
+		}
+		if refs[i].VerifiedBy != nil {
+			refs[i].Verifier = byUser[*refs[i].VerifiedBy]
+		}
+	}
+	return nil
+}
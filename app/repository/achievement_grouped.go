@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AchievementGroupItem adalah 1 prestasi (terbaru) di dalam kelompok per tipe.
+type AchievementGroupItem struct {
+	MongoID   primitive.ObjectID `bson:"mongoId" json:"-"`
+	Title     string             `bson:"title" json:"title"`
+	Points    float64            `bson:"points" json:"points"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+
+	// AchievementID & Status (achievement_references) diisi oleh service.
+	AchievementID string `bson:"-" json:"achievementId"`
+	Status        string `bson:"-" json:"status"`
+}
+
+// AchievementTypeGroup adalah ringkasan prestasi 1 achievementType dalam scope pemanggil.
+type AchievementTypeGroup struct {
+	AchievementType string                 `bson:"_id" json:"-"` // key map di response
+	Count           int64                  `bson:"count" json:"count"`
+	TotalPoints     float64                `bson:"totalPoints" json:"totalPoints"`
+	Items           []AchievementGroupItem `bson:"items" json:"items"`
+}
+
+// FindGroupedByType lihat dokumentasi di interface.
+func (r *achievementRepository) FindGroupedByType(ctx context.Context, scope ReportFilter, perGroup int) ([]AchievementTypeGroup, error) {
+	match := bson.M{"deleted": bson.M{"$ne": true}}
+	if len(scope.StudentIDs) > 0 {
+		match["studentId"] = bson.M{"$in": studentIDMatchValues(scope.StudentIDs)}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$achievementType",
+			"count":       bson.M{"$sum": 1},
+			"totalPoints": bson.M{"$sum": "$points"},
+			"items": bson.M{"$push": bson.M{
+				"mongoId":   "$_id",
+				"title":     "$title",
+				"points":    "$points",
+				"createdAt": "$createdAt",
+			}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"count":       1,
+			"totalPoints": 1,
+			"items":       bson.M{"$slice": bson.A{"$items", perGroup}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cur, err := r.mongoDB.Collection("achievements").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	groups := []AchievementTypeGroup{}
+	if err := cur.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].AchievementType == "" {
+			groups[i].AchievementType = "unknown"
+		}
+	}
+	return groups, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// TestFindSubmittedQueueSurvivesVerifications: reviewer memverifikasi setiap halaman sebelum
+// mengambil halaman berikutnya; setiap pengajuan (termasuk yang submitted_at-nya sama atau
+// NULL) harus terlihat tepat 1 kali.
+func TestFindSubmittedQueueSurvivesVerifications(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	ctx := context.Background()
+	student := createTestStudent(t, pgDB)
+	r := &achievementRepository{pgDB: pgDB}
+
+	// Antrean milik test ini saja: data lain di database diabaikan saat menghitung.
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	want := map[uuid.UUID]bool{}
+	for i := 0; i < 11; i++ {
+		ref := model.AchievementReference{StudentID: student.ID, MongoAchievementID: uuid.NewString(), Status: model.StatusSubmitted}
+		switch {
+		case i < 6:
+			at := base.Add(time.Duration(i/2) * time.Minute) // pasangan dengan submitted_at sama
+			ref.SubmittedAt = &at
+		default:
+			ref.SubmittedAt = nil // data lama tanpa submitted_at
+		}
+		if err := pgDB.Create(&ref).Error; err != nil {
+			t.Fatal(err)
+		}
+		want[ref.ID] = true
+	}
+
+	seen := map[uuid.UUID]int{}
+	var cursor *QueueCursor
+	for pages := 0; ; pages++ {
+		if pages > 1000 {
+			t.Fatal("pagination tidak berhenti")
+		}
+		refs, err := r.FindSubmittedQueue(ctx, cursor, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ref := range refs {
+			seen[ref.ID]++
+			if want[ref.ID] {
+				if err := pgDB.Model(&model.AchievementReference{}).Where("id = ?", ref.ID).
+					Update("status", model.StatusVerified).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if len(refs) < 3 {
+			break
+		}
+		last := refs[len(refs)-1]
+		cursor = &QueueCursor{SubmittedAt: last.SubmittedAt, ID: last.ID}
+	}
+
+	for id := range want {
+		if seen[id] != 1 {
+			t.Fatalf("pengajuan %s terlihat %d kali, want 1", id, seen[id])
+		}
+	}
+}
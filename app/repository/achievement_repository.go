@@ -4,15 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AchievementRepository mendefinisikan operasi data prestasi
@@ -21,69 +28,250 @@ type AchievementRepository interface {
 	// Create: simpan prestasi baru ke MongoDB lalu buat reference di PostgreSQL.
 	Create(ctx context.Context, pgData *model.AchievementReference, mongoData *model.Achievement) error
 	// FindByID: ambil 1 reference prestasi berdasarkan ID UUID (Postgres).
-	FindByID(id string) (*model.AchievementReference, error)
+	FindByID(ctx context.Context, id string) (*model.AchievementReference, error)
+	// FindByExternalRef: reference berdasarkan ID di sistem lama (achievement_references.external_ref).
+	FindByExternalRef(ctx context.Context, externalRef string) (*model.AchievementReference, error)
 	// UpdateStatus: update status + field terkait (submitted_at, verified_at, dsb).
-	UpdateStatus(id string, status string, opts UpdateStatusOptions) error
+	UpdateStatus(ctx context.Context, id string, status string, opts UpdateStatusOptions) error
+	// FindStatusLogs: riwayat perubahan status 1 prestasi (terlama dulu).
+	FindStatusLogs(ctx context.Context, achievementID string) ([]model.AchievementStatusLog, error)
+	// FindRecentStatusLogs: riwayat status beberapa prestasi sekaligus (terbaru dulu), dibatasi limit.
+	FindRecentStatusLogs(ctx context.Context, achievementIDs []string, limit int) ([]model.AchievementStatusLog, error)
 	// FindByStudentID: ambil semua reference prestasi milik 1 mahasiswa (kecuali deleted).
-	FindByStudentID(studentID string) ([]model.AchievementReference, error)
+	FindByStudentID(ctx context.Context, studentID string) ([]model.AchievementReference, error)
+	// FindByStudentIDPaged: prestasi milik 1 mahasiswa (kecuali deleted), opsional filter status + pagination.
+	FindByStudentIDPaged(ctx context.Context, studentID string, status *string, page, limit int) ([]model.AchievementReference, int64, error)
 	// FindDetailByMongoID: ambil detail prestasi dari MongoDB berdasarkan ObjectID (hex).
 	FindDetailByMongoID(ctx context.Context, mongoID string) (*model.Achievement, error)
-	// FindAll: FR-010 — ambil semua prestasi (opsional filter status + pagination).
-	FindAll(status *string, page, limit int) ([]model.AchievementReference, int64, error)
+	// FindDetailsByMongoIDs: detail banyak prestasi sekaligus (1 query $in), key _id hex.
+	// ID yang tidak valid / dokumen yang tidak ada (atau terhapus) tidak muncul di map.
+	FindDetailsByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]*model.Achievement, error)
+	// FindDetailsByMongoIDsWithDeleted: sama seperti FindDetailsByMongoIDs, termasuk dokumen
+	// yang di-soft-delete (list admin ?status=deleted / ?includeDeleted=true).
+	FindDetailsByMongoIDsWithDeleted(ctx context.Context, mongoIDs []string) (map[string]*model.Achievement, error)
+	// FindAll: FR-010 — ambil semua prestasi kecuali deleted (opsional filter status, termasuk deleted, + pagination).
+	FindAll(ctx context.Context, status *string, page, limit int) ([]model.AchievementReference, int64, error)
+	// AttachIdentities: isi Student (+User) & Verifier pada refs dengan query batch (bukan per baris).
+	AttachIdentities(ctx context.Context, refs []model.AchievementReference) error
+	// FindFiltered: bentuk umum FindAll/FindByStudentIDPaged (mahasiswa, status, filter isi + pagination).
+	// Pada mode cursor (filter.Cursor) total tidak dihitung (-1).
+	FindFiltered(ctx context.Context, filter AchievementListFilter) ([]model.AchievementReference, int64, error)
+	// FindMongoIDsByContent: _id (hex) dokumen Mongo yang cocok dengan filter isi (tipe, tag, tanggal).
+	FindMongoIDsByContent(ctx context.Context, filter AchievementContentFilter) ([]string, error)
+	// SearchDetailIDs: _id (hex) dokumen Mongo yang cocok dengan pencarian teks q (title, description);
+	// dokumen yang di-soft-delete hanya ikut jika includeDeleted.
+	SearchDetailIDs(ctx context.Context, q string, includeDeleted bool) ([]string, error)
+	// FindDuplicateCandidates: _id (hex) dokumen Mongo aktif milik mahasiswa yang judulnya sama
+	// (abaikan huruf besar/kecil & spasi) dan details.eventDate di tanggal yang sama; excludeMongoID dilewati.
+	FindDuplicateCandidates(ctx context.Context, studentID uuid.UUID, title string, eventDate *time.Time, excludeMongoID string) ([]string, error)
+	// FindPointsByMongoIDs: poin per dokumen Mongo (key _id hex), untuk sort ?sortBy=points.
+	FindPointsByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]float64, error)
+	// FindSubmittedQueue: antrean review (status submitted) dengan keyset pagination
+	// (submitted_at ASC NULLS LAST, id ASC) setelah posisi after (nil = dari awal).
+	// Item yang diverifikasi di antara dua halaman tidak menyebabkan item lain terlewati.
+	FindSubmittedQueue(ctx context.Context, after *QueueCursor, limit int) ([]model.AchievementReference, error)
+	// FindStaleSubmissions: maksimal limit prestasi submitted dengan submitted_at sebelum before
+	// (terlama dulu), kandidat job kedaluwarsa pengajuan.
+	FindStaleSubmissions(before time.Time, limit int) ([]model.AchievementReference, error)
+	// FindTagCounts: tag unik (prefix cocok, tanpa membedakan huruf besar/kecil) beserta jumlah
+	// prestasi aktif yang memakainya dalam scope, terbanyak dulu (maks. limit).
+	FindTagCounts(ctx context.Context, scope ReportFilter, prefix string, limit int) ([]TagCount, error)
+	// FindGroupedByType: prestasi aktif dalam scope dikelompokkan per achievementType (1 agregasi):
+	// jumlah, total poin, dan perGroup prestasi terbaru tiap tipe.
+	FindGroupedByType(ctx context.Context, scope ReportFilter, perGroup int) ([]AchievementTypeGroup, error)
 
 	// UpdateContent: UPDATE isi prestasi di MongoDB (title, description, details, dll) + updated_at di Postgres.
+	// Lampiran tidak ikut diubah (hanya lewat AddAttachment/RemoveAttachment).
+	// ErrContentLocked jika status prestasi (dikunci FOR UPDATE) sudah tidak mengizinkan edit.
 	UpdateContent(ctx context.Context, id string, mongoData *model.Achievement) error
+	// UpdateContentPartial: $set hanya field patch yang terisi (auto-save draft) + updated_at di Postgres.
+	// Pemeriksaan status sama dengan UpdateContent.
+	UpdateContentPartial(ctx context.Context, id string, patch AchievementContentPatch) error
 	// AddAttachment: menambahkan satu attachment ke dokumen achievement di MongoDB.
+	// Mengembalikan ErrDocumentTooLarge jika dokumen/jumlah lampiran sudah mendekati batas.
 	AddAttachment(ctx context.Context, achievementID string, attachment model.Attachment) error
+	// RemoveAttachment: $pull 1 lampiran (berdasarkan id) dari dokumen Mongo dan mengembalikan
+	// lampiran yang dihapus. ErrAttachmentNotFound jika id tidak ada; pemeriksaan status sama
+	// dengan UpdateContent (ErrContentLocked).
+	RemoveAttachment(ctx context.Context, achievementID, attachmentID string) (*model.Attachment, error)
+
+	// SetPointsOverride: admin meng-override poin (field points + pointsOverride di MongoDB).
+	SetPointsOverride(ctx context.Context, achievementID string, override model.PointsOverride) error
+	// ClearPointsOverride: hapus pointsOverride dan kembalikan points ke nilai yang diberikan.
+	ClearPointsOverride(ctx context.Context, achievementID string, points float64) error
+
+
+	// FindDocumentByReference: dokumen Mongo dari achievement_references.id, termasuk yang sudah di-soft-delete.
+	FindDocumentByReference(ctx context.Context, achievementID string) (*model.Achievement, error)
+	// Restore: kembalikan prestasi yang di-soft-delete ke draft (dokumen & lampiran kembali aktif).
+	Restore(ctx context.Context, achievementID string, actorID uuid.UUID) error
+	// Purge: hapus permanen prestasi dari Postgres & MongoDB (default hanya yang sudah di-soft-delete).
+	Purge(ctx context.Context, achievementID string, opts PurgeOptions) error
+
+	// CheckConsistency: pindai Postgres & Mongo per batch (keyset) dan laporkan reference tanpa
+	// dokumen, dokumen tanpa reference, serta status deleted yang berbeda di kedua sisi.
+	CheckConsistency(ctx context.Context) (*ConsistencyReport, error)
+	// MarkReferencesDeleted: ubah reference (yang belum deleted) menjadi deleted + status log,
+	// untuk reference yatim yang dokumennya sudah tidak ada. Mengembalikan jumlah yang diubah.
+	MarkReferencesDeleted(ids []uuid.UUID, actorID uuid.UUID) (int, error)
+	// FlagOrphanedDocuments: tandai dokumen Mongo tanpa reference sebagai deleted + orphaned=true.
+	FlagOrphanedDocuments(ctx context.Context, mongoIDs []string) (int64, error)
+
+	// FindDecidedBetween: prestasi yang sudah diverifikasi/ditolak dengan verified_at di [from, to).
+	FindDecidedBetween(from, to time.Time) ([]model.AchievementReference, error)
+	// FindByVerifier: keputusan (verified/rejected) oleh user verifier userID, opsional filter
+	// decision & rentang verified_at [from, to), terbaru dulu + pagination.
+	FindByVerifier(ctx context.Context, userID uuid.UUID, decision *string, from, to *time.Time, page, limit int) ([]model.AchievementReference, int64, error)
+	// FindReviewTurnaround: median hari submit → keputusan sejak since, untuk mahasiswa
+	// bimbingan advisorID (lecturers.id, nil = tidak ada) dan untuk semua prestasi.
+	FindReviewTurnaround(advisorID *uuid.UUID, since time.Time) (ReviewTurnaround, error)
+
+	// CountDraftsByStudent: jumlah reference berstatus draft milik 1 mahasiswa.
+	CountDraftsByStudent(studentID uuid.UUID) (int64, error)
+	// FindDraftCounts: mahasiswa dengan jumlah draft >= minDrafts (terbanyak dulu).
+	FindDraftCounts(minDrafts int64) ([]StudentDraftCount, error)
+
+	// SchemaVersionCounts: jumlah dokumen Mongo per schemaVersion (tanpa field = 0).
+	SchemaVersionCounts(ctx context.Context) (map[int]int64, error)
+	// UpgradeStoredDocuments: persist upgrade maksimal limit dokumen versi lama; mengembalikan jumlahnya.
+	UpgradeStoredDocuments(ctx context.Context, limit int) (int, error)
+}
+
+// ReviewTurnaround adalah median waktu review (hari kalender) dari keputusan terbaru.
+// Median nil jika tidak ada keputusan pada periode tsb.
+type ReviewTurnaround struct {
+	AdvisorMedianDays *float64
+	AdvisorDecisions  int64
+	GlobalMedianDays  *float64
+	GlobalDecisions   int64
+}
+
+// StudentDraftCount adalah jumlah draft per mahasiswa (laporan maintenance).
+type StudentDraftCount struct {
+	StudentID uuid.UUID `json:"studentId"`
+	NIM       string    `json:"nim"`
+	Drafts    int64     `json:"drafts"`
 }
 
 // UpdateStatusOptions menyimpan opsi tambahan ketika update status prestasi.
 type UpdateStatusOptions struct {
 	VerifierID    *string
 	RejectionNote *string
+	// RejectionCategory: kategori penolakan (model.RejectionCategories), hanya untuk status rejected.
+	RejectionCategory *string
+	// DelegateOf diisi jika verifier bertindak sebagai delegasi dosen wali (lecturers.id).
+	DelegateOf *uuid.UUID
+	// InternalNote: catatan privat verifier untuk keputusan ini (nil = tanpa catatan).
+	InternalNote *string
+	// DecidedAt menimpa verified_at dengan tanggal historis (hanya jalur impor admin);
+	// keputusan lalu ditandai decision_imported.
+	DecidedAt *time.Time
+	// ActorID: user yang mengubah status (dicatat di achievement_status_logs).
+	// nil = pakai VerifierID jika ada.
+	ActorID *uuid.UUID
+	// ExpectedStatus: status yang sudah diperiksa pemanggil; jika diisi, perubahan hanya berlaku
+	// bila status saat ini masih sama (mis. withdraw yang berebut dengan verifikasi, atau 2 dosen
+	// yang memutuskan bersamaan); jika tidak, ErrStatusConflict. Berlaku juga untuk 'deleted'.
+	ExpectedStatus string
+	// ClearDecision (hanya untuk status submitted): kosongkan keputusan sebelumnya
+	// (verifier, catatan, waktu) saat mahasiswa mengajukan ulang prestasi yang ditolak.
+	ClearDecision bool
+	// Expired: perubahan otomatis oleh job kedaluwarsa pengajuan (tanpa aktor);
+	// ditandai di status log & event outbox agar mahasiswa dinotifikasi.
+	Expired bool
+}
+
+// expiredStatusNote adalah catatan status log untuk pengajuan yang kedaluwarsa.
+const expiredStatusNote = "Pengajuan kedaluwarsa: belum diverifikasi sampai batas waktu"
+
+// AchievementContentPatch adalah perubahan sebagian isi prestasi; field nil tidak diubah.
+type AchievementContentPatch struct {
+	Title       *string
+	Description *string
+	Details     *model.AchievementDetails
+	Tags        *[]string
+	Points      *float64
+}
+
+// IsEmpty true jika tidak ada field yang diubah.
+func (p AchievementContentPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.Details == nil && p.Tags == nil && p.Points == nil
+}
+
+// PurgeOptions mengatur prestasi mana yang boleh dihapus permanen oleh Purge.
+type PurgeOptions struct {
+	// Hard: status apa pun boleh dihapus (bukan hanya 'deleted').
+	Hard bool
+	// AllowVerified: bersama Hard, prestasi verified juga boleh dihapus.
+	AllowVerified bool
+}
+
+// Error Purge jika status prestasi tidak diizinkan oleh PurgeOptions.
+var (
+	ErrPurgeNotDeleted = errors.New("only deleted achievements can be purged")
+	ErrPurgeVerified   = errors.New("verified achievements can only be hard-deleted with force")
+)
+
+// ErrStatusConflict dikembalikan UpdateStatus jika status prestasi sudah berubah dari ExpectedStatus.
+var ErrStatusConflict = errors.New("achievement status changed concurrently")
+
+// ErrContentLocked dikembalikan UpdateContent/UpdateContentPartial jika status prestasi saat
+// penulisan bukan draft/rejected/expired (mis. baru saja disubmit atau diverifikasi).
+var ErrContentLocked = errors.New("achievement content is locked by its current status")
+
+// ErrAttachmentNotFound dikembalikan RemoveAttachment jika lampiran dengan id tersebut tidak ada.
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// contentEditableStatuses: status yang mengizinkan perubahan isi prestasi.
+var contentEditableStatuses = []string{model.StatusDraft, model.StatusRejected, model.StatusExpired}
+
+// lockEditableReference mengunci baris reference (SELECT ... FOR UPDATE) di dalam tx dan
+// memastikan statusnya masih mengizinkan edit isi. Perubahan status (submit/verifikasi)
+// menunggu lock ini, sehingga tidak bisa terjadi di tengah penulisan isi.
+func lockEditableReference(tx *gorm.DB, id string) (*model.AchievementReference, error) {
+	var ref model.AchievementReference
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&ref).Error; err != nil {
+		return nil, err
+	}
+	if !slices.Contains(contentEditableStatuses, ref.Status) {
+		return nil, ErrContentLocked
+	}
+	return &ref, nil
 }
 
 // achievementRepository adalah implementasi konkret AchievementRepository.
 type achievementRepository struct {
 	pgDB    *gorm.DB
 	mongoDB *mongo.Database
+	mongoTx *mongoTxSupport // transaksi Mongo untuk dual-write (lihat runDualWrite)
 }
 
 // NewAchievementRepository membuat instance repository baru.
 func NewAchievementRepository(pgDB *gorm.DB, mongoDB *mongo.Database) AchievementRepository {
-	return &achievementRepository{pgDB, mongoDB}
+	return &achievementRepository{pgDB: pgDB, mongoDB: mongoDB, mongoTx: &mongoTxSupport{}}
 }
 
-// validStatuses: daftar status yang diizinkan (sesuai SRS + tambahan 'deleted').
-var validStatuses = map[string]bool{
-	"draft":     true,
-	"submitted": true,
-	"verified":  true,
-	"rejected":  true,
-	"deleted":   true, // tambahan enum baru
-}
+// validStatuses: daftar status yang diizinkan, diturunkan dari model.AchievementStatuses().
+var validStatuses = func() map[string]bool {
+	m := map[string]bool{}
+	for _, st := range model.AchievementStatuses() {
+		m[st] = true
+	}
+	return m
+}()
 
 // Create menyimpan prestasi baru ke MongoDB lalu membuat reference di PostgreSQL.
-func (r *achievementRepository) Create(ctx context.Context, pgData *model.AchievementReference, mongoData *model.Achievement) error {
+// Seluruh langkah tercatat dalam span "achievement.create"; jika insert Postgres gagal,
+// dokumen Mongo dihapus kembali (kompensasi) dan kejadiannya dicatat sebagai span event.
+func (r *achievementRepository) Create(ctx context.Context, pgData *model.AchievementReference, mongoData *model.Achievement) (err error) {
 	if pgData == nil || pgData.StudentID == uuid.Nil {
 		return errors.New("StudentID harus di-set sebelum Create()")
 	}
 
-	tx := r.pgDB.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	// 1. Insert ke MongoDB terlebih dahulu
-	insertRes, err := r.mongoDB.Collection("achievements").InsertOne(ctx, mongoData)
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("mongo insert error: %w", err)
-	}
-
-	// 2. Dapatkan ObjectID lalu simpan ke kolom mongo_achievement_id di Postgres
-	oid := insertRes.InsertedID.(primitive.ObjectID)
-	pgData.MongoAchievementID = oid.Hex()
+	ctx, span := utils.StartSpan(ctx, "achievement.create")
+	defer func() { utils.EndSpan(span, err) }()
 
 	now := time.Now()
 	if pgData.CreatedAt.IsZero() {
@@ -91,25 +279,73 @@ func (r *achievementRepository) Create(ctx context.Context, pgData *model.Achiev
 	}
 	pgData.UpdatedAt = now
 
-	// 3. Insert ke PostgreSQL
-	if err := tx.Create(pgData).Error; err != nil {
-		// Jika gagal, hapus dokumen Mongo yang baru dibuat
-		_, _ = r.mongoDB.Collection("achievements").DeleteOne(ctx, bson.M{"_id": oid})
-		tx.Rollback()
-		return fmt.Errorf("postgres insert error: %w", err)
-	}
+	var oid primitive.ObjectID
+	return r.runDualWrite(ctx, span, dualWrite{
+		// 1. Insert ke MongoDB (selalu dengan bentuk dokumen terbaru)
+		mongo: func(c context.Context) error {
+			mongoData.SchemaVersion = model.AchievementSchemaVersion
+			mctx, mspan := startMongoSpan(c, "insertOne")
+			insertRes, err := r.mongoDB.Collection("achievements").InsertOne(mctx, mongoData)
+			utils.EndSpan(mspan, err)
+			if err != nil {
+				return fmt.Errorf("mongo insert error: %w", err)
+			}
+			// 2. ObjectID disimpan ke kolom mongo_achievement_id di Postgres
+			oid = insertRes.InsertedID.(primitive.ObjectID)
+			pgData.MongoAchievementID = oid.Hex()
+			return nil
+		},
+		// 3. Insert ke PostgreSQL (commit paling akhir)
+		pg: func(tx *gorm.DB) error {
+			if err := tx.Create(pgData).Error; err != nil {
+				return fmt.Errorf("postgres insert error: %w", err)
+			}
+			return nil
+		},
+		// Jika Postgres gagal setelah dokumen Mongo permanen, hapus dokumen tersebut
+		undo: func(c context.Context) error {
+			_, derr := r.mongoDB.Collection("achievements").DeleteOne(c, bson.M{"_id": oid})
+			return derr
+		},
+		undoEvent: "mongo_insert_reverted",
+		oid:       &oid,
+	})
+}
 
-	return tx.Commit().Error
+// startMongoSpan membuat child span untuk 1 operasi koleksi achievements.
+func startMongoSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	return utils.StartSpan(ctx, "mongo.achievements."+op,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.collection.name", "achievements"),
+		attribute.String("db.operation.name", op),
+	)
+}
+
+// compensate menjalankan langkah kompensasi Mongo setelah Postgres gagal dan mencatatnya
+// sebagai span event di span parent, sehingga partial failure terlihat dalam 1 trace.
+func (r *achievementRepository) compensate(ctx context.Context, parent trace.Span, event string, oid primitive.ObjectID, fn func(context.Context) error) {
+	cctx, cspan := startMongoSpan(ctx, "compensate")
+	err := fn(cctx)
+	utils.EndSpan(cspan, err)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("mongo.id", oid.Hex()),
+		attribute.Bool("compensation.succeeded", err == nil),
+	}
+	if err != nil {
+		attrs = append(attrs, attribute.String("compensation.error", err.Error()))
+	}
+	parent.AddEvent(event, trace.WithAttributes(attrs...))
 }
 
 // FindByID mengambil 1 reference prestasi berdasarkan id UUID (Postgres).
-func (r *achievementRepository) FindByID(id string) (*model.AchievementReference, error) {
+func (r *achievementRepository) FindByID(ctx context.Context, id string) (*model.AchievementReference, error) {
 	var ref model.AchievementReference
 
 	// Kita hanya preload Verifier (User yang memverifikasi),
 	// karena relasi Student belum kita definisikan dengan benar di model
 	// dan di seluruh flow kita hanya butuh StudentID, bukan objek Student-nya.
-	err := r.pgDB.
+	err := r.pgDB.WithContext(ctx).
 		Preload("Verifier").
 		Where("id = ?", id).
 		First(&ref).Error
@@ -121,20 +357,38 @@ func (r *achievementRepository) FindByID(id string) (*model.AchievementReference
 	return &ref, nil
 }
 
+// FindByExternalRef mengambil reference prestasi berdasarkan external_ref (ID di sistem lama).
+func (r *achievementRepository) FindByExternalRef(ctx context.Context, externalRef string) (*model.AchievementReference, error) {
+	var ref model.AchievementReference
+	if err := r.pgDB.WithContext(ctx).Where("external_ref = ?", externalRef).First(&ref).Error; err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
 
 // UpdateStatus mengubah status prestasi dan field-field terkait.
-func (r *achievementRepository) UpdateStatus(id string, status string, opts UpdateStatusOptions) error {
+func (r *achievementRepository) UpdateStatus(ctx context.Context, id string, status string, opts UpdateStatusOptions) (err error) {
 	if !validStatuses[status] {
 		return fmt.Errorf("invalid status: %s", status)
 	}
 
+	ctx, span := utils.StartSpan(ctx, "achievement.update_status", attribute.String("achievement.status", status))
+	defer func() { utils.EndSpan(span, err) }()
+
 	// === Perlakuan khusus untuk status 'deleted' ===
-	if status == "deleted" {
+	if status == model.StatusDeleted {
+		// Operasi Mongo tidak ikut dibatalkan bersama request (sama seperti sebelumnya),
+		// tapi tetap membawa span agar tercatat dalam trace request.
+		bg := context.WithoutCancel(ctx)
+
 		// 1. Ambil reference terlebih dahulu
 		var ref model.AchievementReference
-		if err := r.pgDB.Where("id = ?", id).First(&ref).Error; err != nil {
+		if err := r.pgDB.WithContext(ctx).Where("id = ?", id).First(&ref).Error; err != nil {
 			return err
 		}
+		if opts.ExpectedStatus != "" && ref.Status != opts.ExpectedStatus {
+			return ErrStatusConflict
+		}
 
 		// 2. Convert mongoAchievementID (hex) -> ObjectID
 		objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
@@ -142,53 +396,71 @@ func (r *achievementRepository) UpdateStatus(id string, status string, opts Upda
 			return err
 		}
 
-		// 3. Soft delete di Mongo: set field deleted=true, deletedAt=now
 		now := time.Now()
-		res, err := r.mongoDB.Collection("achievements").
-			UpdateOne(
-				context.Background(),
-				bson.M{"_id": objID},
-				bson.M{"$set": bson.M{"deleted": true, "deletedAt": now}},
-			)
-		if err != nil {
-			return fmt.Errorf("mongo soft-delete failed: %w", err)
-		}
-		if res.MatchedCount == 0 {
-			return fmt.Errorf("mongo document not found for deletion")
-		}
-
-		// 4. Update status di Postgres dalam transaksi
-		tx := r.pgDB.Begin()
-		if tx.Error != nil {
-			// rollback perubahan di Mongo
-			_, _ = r.mongoDB.Collection("achievements").
-				UpdateOne(
-					context.Background(),
-					bson.M{"_id": objID},
-					bson.M{"$unset": bson.M{"deleted": "", "deletedAt": ""}},
-				)
-			return tx.Error
-		}
-
-		updates := map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
-		}
-
-		if err := tx.Model(&model.AchievementReference{}).
-			Where("id = ?", id).
-			Updates(updates).Error; err != nil {
-			tx.Rollback()
-			// rollback Mongo
-			_, _ = r.mongoDB.Collection("achievements").
-				UpdateOne(
-					context.Background(),
-					bson.M{"_id": objID},
-					bson.M{"$unset": bson.M{"deleted": "", "deletedAt": ""}},
-				)
-			return err
-		}
-		return tx.Commit().Error
+		return r.runDualWrite(bg, span, dualWrite{
+			// 3. Soft delete di Mongo: set field deleted=true, deletedAt=now
+			mongo: func(c context.Context) error {
+				mctx, mspan := startMongoSpan(c, "softDelete")
+				res, err := r.mongoDB.Collection("achievements").
+					UpdateOne(
+						mctx,
+						bson.M{"_id": objID},
+						bson.M{"$set": bson.M{"deleted": true, "deletedAt": now}},
+					)
+				utils.EndSpan(mspan, err)
+				if err != nil {
+					return fmt.Errorf("mongo soft-delete failed: %w", err)
+				}
+				if res.MatchedCount == 0 {
+					return fmt.Errorf("mongo document not found for deletion")
+				}
+				// Tandai lampiran sebagai deleted (tidak bisa diunduh). Filter "attachments.0"
+				// memastikan array ada, karena operator $[] gagal pada field null/tidak ada.
+				_, _ = r.mongoDB.Collection("achievements").
+					UpdateOne(
+						mctx,
+						bson.M{"_id": objID, "attachments.0": bson.M{"$exists": true}},
+						bson.M{"$set": bson.M{"attachments.$[].deleted": true, "attachments.$[].deletedAt": now}},
+					)
+				return nil
+			},
+			// 4. Update status di Postgres dalam transaksi (commit paling akhir)
+			pg: func(tx *gorm.DB) error {
+				updates := map[string]interface{}{
+					"status":     status,
+					"updated_at": time.Now(),
+				}
+				q := tx.Model(&model.AchievementReference{}).Where("id = ?", id)
+				if opts.ExpectedStatus != "" {
+					q = q.Where("status = ?", opts.ExpectedStatus)
+				}
+				res := q.Updates(updates)
+				if res.Error != nil {
+					return res.Error
+				}
+				// status berubah setelah pemeriksaan awal: perubahan Mongo ikut dibatalkan
+				if opts.ExpectedStatus != "" && res.RowsAffected == 0 {
+					return ErrStatusConflict
+				}
+				if err := writeStatusEvent(tx, &ref, status, opts, now); err != nil {
+					return err
+				}
+				return writeStatusLog(tx, &ref, status, opts, now)
+			},
+			// rollback perubahan di Mongo jika langkah Postgres gagal
+			undo: func(c context.Context) error {
+				_, uerr := r.mongoDB.Collection("achievements").
+					UpdateOne(
+						c,
+						bson.M{"_id": objID},
+						bson.M{"$unset": bson.M{"deleted": "", "deletedAt": ""}},
+					)
+				r.unsetAttachmentsDeleted(c, objID)
+				return uerr
+			},
+			undoEvent: "mongo_soft_delete_reverted",
+			oid:       &objID,
+		})
 	}
 
 	// === Flow umum untuk status selain 'deleted' ===
@@ -198,95 +470,323 @@ func (r *achievementRepository) UpdateStatus(id string, status string, opts Upda
 	}
 	now := time.Now()
 
+	decidedAt := now
+	if opts.DecidedAt != nil {
+		decidedAt = *opts.DecidedAt
+	}
+
 	switch status {
-	case "submitted":
+	case model.StatusDraft:
+		updates["submitted_at"] = nil // ditarik kembali oleh mahasiswa sebelum diputuskan
+	case model.StatusSubmitted:
 		updates["submitted_at"] = now
-	case "verified":
-		updates["verified_at"] = now
+		if opts.ClearDecision {
+			updates["verified_at"] = nil
+			updates["verified_by"] = nil
+			updates["verified_as_delegate_of"] = nil
+			updates["rejection_note"] = nil
+			updates["rejection_category"] = nil
+			updates["internal_note"] = nil
+			updates["decision_imported"] = false
+		}
+	case model.StatusVerified:
+		updates["verified_at"] = decidedAt
+		updates["decision_imported"] = opts.DecidedAt != nil
 		if opts.VerifierID != nil {
 			updates["verified_by"] = *opts.VerifierID
 		}
-	case "rejected":
-		updates["verified_at"] = now
+		updates["verified_as_delegate_of"] = opts.DelegateOf
+		updates["internal_note"] = opts.InternalNote
+	case model.StatusRejected:
+		updates["verified_at"] = decidedAt
+		updates["decision_imported"] = opts.DecidedAt != nil
 		if opts.VerifierID != nil {
 			updates["verified_by"] = *opts.VerifierID
 		}
+		updates["verified_as_delegate_of"] = opts.DelegateOf
+		updates["internal_note"] = opts.InternalNote
 		if opts.RejectionNote != nil {
 			updates["rejection_note"] = *opts.RejectionNote
 		}
+		updates["rejection_category"] = opts.RejectionCategory
 	}
 
-	return r.pgDB.
-		Model(&model.AchievementReference{}).
-		Where("id = ?", id).
-		Updates(updates).Error
+	// Update status + event outbox dalam 1 transaksi: event tidak hilang saat proses crash
+	// setelah commit, dan tidak ada event untuk perubahan yang di-rollback.
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ref model.AchievementReference
+		if err := tx.Where("id = ?", id).First(&ref).Error; err != nil {
+			return err
+		}
+		if opts.ExpectedStatus != "" && ref.Status != opts.ExpectedStatus {
+			return ErrStatusConflict
+		}
+		q := tx.Model(&model.AchievementReference{}).Where("id = ?", id)
+		if opts.ExpectedStatus != "" {
+			q = q.Where("status = ?", opts.ExpectedStatus)
+		}
+		res := q.Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if opts.ExpectedStatus != "" && res.RowsAffected == 0 {
+			return ErrStatusConflict
+		}
+		if err := writeStatusEvent(tx, &ref, status, opts, decidedAt); err != nil {
+			return err
+		}
+		return writeStatusLog(tx, &ref, status, opts, decidedAt)
+	})
+}
+
+// writeStatusLog mencatat perubahan status ref (status lama → status) ke achievement_status_logs
+// di dalam transaksi tx. at = waktu keputusan (historis untuk keputusan hasil impor).
+func writeStatusLog(tx *gorm.DB, ref *model.AchievementReference, status string, opts UpdateStatusOptions, at time.Time) error {
+	actor := opts.ActorID
+	if actor == nil && opts.VerifierID != nil {
+		if id, err := uuid.Parse(*opts.VerifierID); err == nil {
+			actor = &id
+		}
+	}
+	entry := model.AchievementStatusLog{
+		AchievementReferenceID: ref.ID,
+		FromStatus:             ref.Status,
+		ToStatus:               status,
+		ActorUserID:            actor,
+		CreatedAt:              at,
+	}
+	switch {
+	case status == model.StatusRejected:
+		entry.Note = opts.RejectionNote
+		entry.Category = opts.RejectionCategory
+	case opts.Expired:
+		note := expiredStatusNote
+		entry.Note = &note
+	}
+	return tx.Create(&entry).Error
+}
+
+// FindStatusLogs lihat dokumentasi di interface.
+func (r *achievementRepository) FindStatusLogs(ctx context.Context, achievementID string) ([]model.AchievementStatusLog, error) {
+	var logs []model.AchievementStatusLog
+	err := r.pgDB.WithContext(ctx).
+		Where("achievement_reference_id = ?", achievementID).
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&logs).Error
+	return logs, err
+}
+
+// FindRecentStatusLogs lihat dokumentasi di interface.
+func (r *achievementRepository) FindRecentStatusLogs(ctx context.Context, achievementIDs []string, limit int) ([]model.AchievementStatusLog, error) {
+	logs := []model.AchievementStatusLog{}
+	if len(achievementIDs) == 0 {
+		return logs, nil
+	}
+	err := r.pgDB.WithContext(ctx).
+		Where("achievement_reference_id IN ?", achievementIDs).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// writeStatusEvent menulis event outbox "achievement.<status>" di dalam transaksi tx.
+func writeStatusEvent(tx *gorm.DB, ref *model.AchievementReference, status string, opts UpdateStatusOptions, at time.Time) error {
+	var student model.Student
+	if err := tx.Select("advisor_id").Where("id = ?", ref.StudentID).First(&student).Error; err != nil &&
+		!errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return insertOutboxEvent(tx, "achievement."+status, ref.ID, AchievementStatusEvent{
+		AchievementID:     ref.ID,
+		StudentID:         ref.StudentID,
+		AdvisorID:         student.AdvisorID,
+		Status:            status,
+		VerifiedBy:        opts.VerifierID,
+		RejectionNote:     opts.RejectionNote,
+		RejectionCategory: opts.RejectionCategory,
+		Imported:          opts.DecidedAt != nil,
+		Expired:           opts.Expired,
+		At:                at,
+	})
 }
 
 // FindByStudentID mengambil semua prestasi milik seorang mahasiswa (kecuali yang status 'deleted').
-func (r *achievementRepository) FindByStudentID(studentID string) ([]model.AchievementReference, error) {
+// Urutan: created_at DESC, id DESC (tiebreaker deterministik).
+func (r *achievementRepository) FindByStudentID(ctx context.Context, studentID string) ([]model.AchievementReference, error) {
 	var refs []model.AchievementReference
-	err := r.pgDB.
+	err := r.pgDB.WithContext(ctx).
 		Where("student_id = ? AND status != 'deleted'", studentID).
 		Order("created_at DESC").
+		Order("id DESC").
 		Find(&refs).Error
 	return refs, err
 }
 
+// FindByStudentIDPaged sama seperti FindByStudentID, dengan filter status & pagination
+// (semantik sama dengan FindAll).
+func (r *achievementRepository) FindByStudentIDPaged(ctx context.Context, studentID string, status *string, page, limit int) ([]model.AchievementReference, int64, error) {
+	return r.FindFiltered(ctx, AchievementListFilter{StudentID: &studentID, Status: status, Page: page, Limit: limit})
+}
+
 // FindDetailByMongoID mengambil detail prestasi dari MongoDB berdasarkan _id ObjectID hex.
 func (r *achievementRepository) FindDetailByMongoID(ctx context.Context, mongoID string) (*model.Achievement, error) {
 	objID, err := primitive.ObjectIDFromHex(mongoID)
 	if err != nil {
 		return nil, err
 	}
-	var achievement model.Achievement
-	err = r.mongoDB.Collection("achievements").
+	raw, err := r.mongoDB.Collection("achievements").
 		FindOne(ctx, bson.M{"_id": objID, "deleted": bson.M{"$ne": true}}).
-		Decode(&achievement)
-	return &achievement, err
+		Raw()
+	if err != nil {
+		return &model.Achievement{}, err
+	}
+	achievement, _, err := DecodeAchievement(raw)
+	return achievement, err
+}
+
+// FindDetailsByMongoIDs lihat dokumentasi di interface.
+func (r *achievementRepository) FindDetailsByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]*model.Achievement, error) {
+	return r.findDetailsByMongoIDs(ctx, mongoIDs, false)
+}
+
+// FindDetailsByMongoIDsWithDeleted lihat dokumentasi di interface.
+func (r *achievementRepository) FindDetailsByMongoIDsWithDeleted(ctx context.Context, mongoIDs []string) (map[string]*model.Achievement, error) {
+	return r.findDetailsByMongoIDs(ctx, mongoIDs, true)
+}
+
+func (r *achievementRepository) findDetailsByMongoIDs(ctx context.Context, mongoIDs []string, includeDeleted bool) (map[string]*model.Achievement, error) {
+	details := make(map[string]*model.Achievement, len(mongoIDs))
+	objIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return details, nil
+	}
+
+	query := bson.M{"_id": bson.M{"$in": objIDs}}
+	if !includeDeleted {
+		query["deleted"] = bson.M{"$ne": true}
+	}
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		achievement, _, err := DecodeAchievement(cur.Current)
+		if err != nil {
+			continue // dokumen rusak diperlakukan seperti tidak ditemukan (sama seperti FindDetailByMongoID)
+		}
+		details[achievement.ID.Hex()] = achievement
+	}
+	return details, cur.Err()
+}
+
+// Batas pagination OFFSET. maxPage dijaga agar (page-1)*limit tidak overflow
+// walau query param dikirim dengan angka raksasa.
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+	maxPage          = 100000
+)
+
+// NormalizePagination merapikan page & limit dari query param:
+// page < 1 → 1, page > maxPage → maxPage, limit di luar 1..100 → 10.
+// Dipakai repository dan service supaya meta response sama dengan query yang dijalankan.
+func NormalizePagination(page, limit int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if page > maxPage {
+		page = maxPage
+	}
+	if limit <= 0 || limit > maxPageLimit {
+		limit = defaultPageLimit
+	}
+	return page, limit
 }
 
 // FindAll mengembalikan daftar prestasi untuk admin (FR-010).
 // Mendukung:
 //   - filter status (?status=submitted)
 //   - pagination basic (?page=1&limit=10)
-func (r *achievementRepository) FindAll(status *string, page, limit int) ([]model.AchievementReference, int64, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 100 {
-		limit = 10
+//
+// Urutan dijamin deterministik: created_at DESC lalu id DESC sebagai tiebreaker,
+// sehingga baris dengan timestamp sama tidak berpindah/duplikat antar halaman.
+func (r *achievementRepository) FindAll(ctx context.Context, status *string, page, limit int) ([]model.AchievementReference, int64, error) {
+	refs, total, err := r.FindFiltered(ctx, AchievementListFilter{Status: status, Page: page, Limit: limit})
+	if err != nil {
+		return nil, 0, err
 	}
+	return refs, total, r.AttachIdentities(ctx, refs)
+}
 
-	db := r.pgDB.Model(&model.AchievementReference{})
+// QueueCursor adalah posisi baris terakhir halaman antrean review sebelumnya.
+// SubmittedAt nil = baris tanpa submitted_at (data lama), yang diurutkan paling akhir.
+type QueueCursor struct {
+	SubmittedAt *time.Time
+	ID          uuid.UUID
+}
 
-	if status != nil && *status != "" {
-		db = db.Where("status = ?", *status)
-	}
+// FindSubmittedQueue lihat dokumentasi di interface.
+func (r *achievementRepository) FindSubmittedQueue(ctx context.Context, after *QueueCursor, limit int) ([]model.AchievementReference, error) {
+	_, limit = NormalizePagination(1, limit)
 
-	// Hitung total untuk pagination
-	var total int64
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
+	db := r.pgDB.WithContext(ctx).Model(&model.AchievementReference{}).
+		Where("status = ?", model.StatusSubmitted)
+	switch {
+	case after == nil:
+	case after.SubmittedAt == nil:
+		// Sudah di bagian NULL (paling akhir): lanjut berdasarkan id saja
+		db = db.Where("submitted_at IS NULL AND id > ?", after.ID)
+	default:
+		// Perbandingan tuple (submitted_at, id) > (?, ?) bernilai NULL untuk baris tanpa
+		// submitted_at, sehingga bagian NULL di akhir ditambahkan eksplisit.
+		db = db.Where("((submitted_at, id) > (?, ?) OR submitted_at IS NULL)", *after.SubmittedAt, after.ID)
 	}
 
 	var refs []model.AchievementReference
 	err := db.
-		Order("created_at DESC").
-		Offset((page - 1) * limit).
+		Order("submitted_at ASC NULLS LAST").
+		Order("id ASC").
 		Limit(limit).
 		Find(&refs).Error
+	return refs, err
+}
 
-	return refs, total, err
+// FindStaleSubmissions lihat dokumentasi di interface.
+func (r *achievementRepository) FindStaleSubmissions(before time.Time, limit int) ([]model.AchievementReference, error) {
+	var refs []model.AchievementReference
+	err := r.pgDB.
+		Where("status = ? AND submitted_at < ?", model.StatusSubmitted, before).
+		Order("submitted_at ASC").
+		Order("id ASC").
+		Limit(limit).
+		Find(&refs).Error
+	return refs, err
 }
 
 // UpdateContent melakukan UPDATE konten prestasi di MongoDB lalu update updated_at di Postgres.
 func (r *achievementRepository) UpdateContent(ctx context.Context, id string, mongoData *model.Achievement) error {
-	// Ambil reference untuk mendapatkan mongo_achievement_id
-	var ref model.AchievementReference
-	if err := r.pgDB.Where("id = ?", id).First(&ref).Error; err != nil {
-		return err
-	}
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Ambil & kunci reference (mongo_achievement_id + status terbaru)
+		ref, err := lockEditableReference(tx, id)
+		if err != nil {
+			return err
+		}
+		return r.updateContent(ctx, tx, ref, mongoData)
+	})
+}
 
+// updateContent menulis isi prestasi; dipanggil di dalam transaksi yang memegang lock reference.
+func (r *achievementRepository) updateContent(ctx context.Context, tx *gorm.DB, ref *model.AchievementReference, mongoData *model.Achievement) error {
 	objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
 	if err != nil {
 		return err
@@ -300,20 +800,96 @@ func (r *achievementRepository) UpdateContent(ctx context.Context, id string, mo
 		"title":           mongoData.Title,
 		"description":     mongoData.Description,
 		"details":         mongoData.Details,
-		"attachments":     mongoData.Attachments,
 		"tags":            mongoData.Tags,
 		"points":          mongoData.Points,
+		"schemaVersion":   model.AchievementSchemaVersion,
 		"updatedAt":       now,
 	}
 
+	// Perkiraan ukuran setelah update: isi baru tidak boleh melebihi batas dokumen.
+	if raw, err := bson.Marshal(updateDoc); err == nil && len(raw) > maxDocumentBytes() {
+		return ErrDocumentTooLarge
+	}
+
+	// Field yang tidak ikut di-$set (mis. studentId) harus sudah berbentuk terbaru
+	// sebelum schemaVersion terbaru ditulis.
+	if _, err := r.persistSchemaUpgrade(ctx, objID); err != nil {
+		return fmt.Errorf("mongo schema upgrade error: %w", err)
+	}
+
 	if _, err := r.mongoDB.Collection("achievements").
 		UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateDoc}); err != nil {
+		if err := asTooLarge(err); errors.Is(err, ErrDocumentTooLarge) {
+			return err
+		}
 		return fmt.Errorf("mongo update error: %w", err)
 	}
 
 	// Update updated_at di Postgres
-	return r.pgDB.Model(&model.AchievementReference{}).
-		Where("id = ?", id).
+	return tx.Model(&model.AchievementReference{}).
+		Where("id = ?", ref.ID).
+		Update("updated_at", now).Error
+}
+
+// UpdateContentPartial lihat dokumentasi di interface.
+func (r *achievementRepository) UpdateContentPartial(ctx context.Context, id string, patch AchievementContentPatch) error {
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ref, err := lockEditableReference(tx, id)
+		if err != nil {
+			return err
+		}
+		return r.updateContentPartial(ctx, tx, ref, patch)
+	})
+}
+
+// updateContentPartial: lihat updateContent (dipanggil dengan lock reference).
+func (r *achievementRepository) updateContentPartial(ctx context.Context, tx *gorm.DB, ref *model.AchievementReference, patch AchievementContentPatch) error {
+	objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	updateDoc := bson.M{
+		"schemaVersion": model.AchievementSchemaVersion,
+		"updatedAt":     now,
+	}
+	if patch.Title != nil {
+		updateDoc["title"] = *patch.Title
+	}
+	if patch.Description != nil {
+		updateDoc["description"] = *patch.Description
+	}
+	if patch.Details != nil {
+		updateDoc["details"] = *patch.Details
+	}
+	if patch.Tags != nil {
+		updateDoc["tags"] = *patch.Tags
+	}
+	if patch.Points != nil {
+		updateDoc["points"] = *patch.Points
+	}
+
+	// Batas ukuran sama seperti UpdateContent (hanya bagian yang diubah yang bisa diperkirakan di sini).
+	if raw, err := bson.Marshal(updateDoc); err == nil && len(raw) > maxDocumentBytes() {
+		return ErrDocumentTooLarge
+	}
+
+	if _, err := r.persistSchemaUpgrade(ctx, objID); err != nil {
+		return fmt.Errorf("mongo schema upgrade error: %w", err)
+	}
+
+	if _, err := r.mongoDB.Collection("achievements").
+		UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateDoc}); err != nil {
+		if err := asTooLarge(err); errors.Is(err, ErrDocumentTooLarge) {
+			return err
+		}
+		return fmt.Errorf("mongo update error: %w", err)
+	}
+
+	return tx.Model(&model.AchievementReference{}).
+		Where("id = ?", ref.ID).
 		Update("updated_at", now).Error
 }
 
@@ -326,7 +902,7 @@ func (r *achievementRepository) AddAttachment(
 ) error {
 	// 1. Ambil reference di Postgres untuk mendapatkan mongoAchievementID.
 	var ref model.AchievementReference
-	if err := r.pgDB.Where("id = ?", achievementID).First(&ref).Error; err != nil {
+	if err := r.pgDB.WithContext(ctx).Where("id = ?", achievementID).First(&ref).Error; err != nil {
 		return err // achievement tidak ditemukan di Postgres
 	}
 
@@ -336,12 +912,505 @@ func (r *achievementRepository) AddAttachment(
 		return err
 	}
 
-	// 3. Push attachment baru ke array attachments di dokumen Mongo.
-	_, err = r.mongoDB.Collection("achievements").UpdateOne(
+	// 3. Tolak lebih awal jika dokumen akan mendekati batas ukuran Mongo.
+	if err := r.ensureRoomFor(ctx, objID, attachment); err != nil {
+		return err
+	}
+
+	// Lampiran baru ditulis dengan nama field terbaru: upgrade dokumen lama lebih dulu
+	// agar array attachments tidak bercampur bentuk lama & baru.
+	if _, err := r.persistSchemaUpgrade(ctx, objID); err != nil {
+		return fmt.Errorf("mongo schema upgrade error: %w", err)
+	}
+
+	// 4. Push attachment baru ke array attachments di dokumen Mongo.
+	// Filter slot memastikan batas jumlah lampiran tetap berlaku walau ada upload bersamaan.
+	filter := attachmentSlotFilter()
+	filter["_id"] = objID
+	filter["deleted"] = bson.M{"$ne": true}
+	res, err := r.mongoDB.Collection("achievements").UpdateOne(
 		ctx,
-		bson.M{"_id": objID, "deleted": bson.M{"$ne": true}},
+		filter,
 		bson.M{"$push": bson.M{"attachments": attachment}},
 	)
+	if err != nil {
+		return asTooLarge(err)
+	}
+	if res.MatchedCount == 0 {
+		// Dokumen ada (ensureRoomFor berhasil) tapi tidak cocok filter → slot lampiran penuh
+		// (atau prestasi baru saja dihapus; keduanya ditolak).
+		return ErrDocumentTooLarge
+	}
+	return nil
+}
+
+// RemoveAttachment lihat dokumentasi di interface.
+func (r *achievementRepository) RemoveAttachment(ctx context.Context, achievementID, attachmentID string) (*model.Attachment, error) {
+	var removed *model.Attachment
+	err := r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci reference agar submit/verifikasi tidak terjadi di tengah penghapusan.
+		ref, err := lockEditableReference(tx, achievementID)
+		if err != nil {
+			return err
+		}
+		objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		if err != nil {
+			return err
+		}
 
+		// Dokumen sebelum $pull dipakai untuk mengetahui lampiran (path file) yang dihapus.
+		now := time.Now()
+		var before struct {
+			Attachments []model.Attachment `bson:"attachments"`
+		}
+		err = r.mongoDB.Collection("achievements").FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": objID, "deleted": bson.M{"$ne": true}, "attachments.id": attachmentID},
+			bson.M{
+				"$pull": bson.M{"attachments": bson.M{"id": attachmentID}},
+				"$set":  bson.M{"updatedAt": now},
+			},
+			options.FindOneAndUpdate().SetProjection(bson.M{"attachments": 1}),
+		).Decode(&before)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAttachmentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("mongo pull attachment error: %w", err)
+		}
+		for i := range before.Attachments {
+			if before.Attachments[i].ID == attachmentID {
+				removed = &before.Attachments[i]
+				break
+			}
+		}
+
+		return tx.Model(&model.AchievementReference{}).
+			Where("id = ?", ref.ID).
+			Update("updated_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// mongoObjectIDByReference mengambil ObjectID dokumen Mongo dari achievement_references.id.
+func (r *achievementRepository) mongoObjectIDByReference(ctx context.Context, achievementID string) (primitive.ObjectID, error) {
+	var ref model.AchievementReference
+	if err := r.pgDB.WithContext(ctx).Where("id = ?", achievementID).First(&ref).Error; err != nil {
+		return primitive.NilObjectID, err
+	}
+	return primitive.ObjectIDFromHex(ref.MongoAchievementID)
+}
+
+// SetPointsOverride menyimpan override poin: field points diganti dengan nilai override
+// (sehingga agregasi statistik otomatis memakai nilai ini) dan jejaknya disimpan di pointsOverride.
+func (r *achievementRepository) SetPointsOverride(ctx context.Context, achievementID string, override model.PointsOverride) error {
+	objID, err := r.mongoObjectIDByReference(ctx, achievementID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.mongoDB.Collection("achievements").UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "deleted": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{
+			"points":         override.Points,
+			"pointsOverride": override,
+			"updatedAt":      time.Now(),
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("mongo update error: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ClearPointsOverride menghapus pointsOverride dan mengembalikan field points.
+func (r *achievementRepository) ClearPointsOverride(ctx context.Context, achievementID string, points float64) error {
+	objID, err := r.mongoObjectIDByReference(ctx, achievementID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.mongoDB.Collection("achievements").UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "deleted": bson.M{"$ne": true}},
+		bson.M{
+			"$set":   bson.M{"points": points, "updatedAt": time.Now()},
+			"$unset": bson.M{"pointsOverride": ""},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("mongo update error: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetDocumentsStudent mengganti field studentId pada dokumen-dokumen Mongo tertentu
+// (langkah Mongo dari UserAdminRepository.MergeUsers).
+func (r *achievementRepository) SetDocumentsStudent(ctx context.Context, mongoIDs []string, studentID uuid.UUID) error {
+	oids := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return err
+		}
+		oids = append(oids, oid)
+	}
+	if len(oids) == 0 {
+		return nil
+	}
+
+	_, err := r.mongoDB.Collection("achievements").UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": oids}},
+		bson.M{"$set": bson.M{"studentId": studentID, "updatedAt": time.Now()}},
+	)
 	return err
 }
+
+// normalizeTitle menyamakan judul untuk deteksi duplikat: huruf kecil, spasi dirapikan.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// sameEventDate: kedua tanggal kosong, atau jatuh di tanggal kalender (UTC) yang sama.
+func sameEventDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.UTC().Format("2006-01-02") == b.UTC().Format("2006-01-02")
+}
+
+// FindDuplicateCandidates lihat dokumentasi di interface.
+// Normalisasi judul tidak bisa dilakukan index, jadi query memakai prefix studentId
+// dari index (studentId, title) lalu judul dibandingkan di sini (dokumen per mahasiswa sedikit).
+func (r *achievementRepository) FindDuplicateCandidates(ctx context.Context, studentID uuid.UUID, title string, eventDate *time.Time, excludeMongoID string) ([]string, error) {
+	want := normalizeTitle(title)
+	if want == "" {
+		return nil, nil
+	}
+
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx,
+		bson.M{"studentId": studentID, "deleted": bson.M{"$ne": true}},
+		options.Find().SetProjection(bson.M{"title": 1, "details.eventDate": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var ids []string
+	for cur.Next(ctx) {
+		var doc struct {
+			ID      primitive.ObjectID `bson:"_id"`
+			Title   string             `bson:"title"`
+			Details struct {
+				EventDate *time.Time `bson:"eventDate"`
+			} `bson:"details"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			continue
+		}
+		if doc.ID.Hex() == excludeMongoID || normalizeTitle(doc.Title) != want {
+			continue
+		}
+		if sameEventDate(doc.Details.EventDate, eventDate) {
+			ids = append(ids, doc.ID.Hex())
+		}
+	}
+	return ids, cur.Err()
+}
+
+// unsetAttachmentsDeleted menghapus tanda deleted pada semua lampiran dokumen.
+func (r *achievementRepository) unsetAttachmentsDeleted(ctx context.Context, objID primitive.ObjectID) {
+	_, _ = r.mongoDB.Collection("achievements").UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "attachments.0": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"attachments.$[].deleted": "", "attachments.$[].deletedAt": ""}},
+	)
+}
+
+// FindDocumentByReference mengambil dokumen Mongo (termasuk yang deleted) dari achievement_references.id.
+func (r *achievementRepository) FindDocumentByReference(ctx context.Context, achievementID string) (*model.Achievement, error) {
+	objID, err := r.mongoObjectIDByReference(ctx, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := r.mongoDB.Collection("achievements").FindOne(ctx, bson.M{"_id": objID}).Raw()
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := DecodeAchievement(raw)
+	return doc, err
+}
+
+// Restore mengembalikan prestasi berstatus 'deleted' menjadi 'draft'.
+// Lampiran ikut aktif kembali karena file tidak pernah dihapus saat soft delete.
+func (r *achievementRepository) Restore(ctx context.Context, achievementID string, actorID uuid.UUID) error {
+	var ref model.AchievementReference
+	if err := r.pgDB.WithContext(ctx).Where("id = ?", achievementID).First(&ref).Error; err != nil {
+		return err
+	}
+	if ref.Status != model.StatusDeleted {
+		return fmt.Errorf("achievement is not deleted")
+	}
+	objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.mongoDB.Collection("achievements").UpdateOne(
+		ctx,
+		bson.M{"_id": objID},
+		bson.M{"$unset": bson.M{"deleted": "", "deletedAt": ""}},
+	); err != nil {
+		return fmt.Errorf("mongo restore failed: %w", err)
+	}
+	r.unsetAttachmentsDeleted(ctx, objID)
+
+	now := time.Now()
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.AchievementReference{}).
+			Where("id = ? AND status = ?", achievementID, model.StatusDeleted).
+			Updates(map[string]interface{}{
+				"status":     model.StatusDraft,
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("achievement is not deleted")
+		}
+		return writeStatusLog(tx, &ref, model.StatusDraft, UpdateStatusOptions{ActorID: &actorID}, now)
+	})
+}
+
+// Purge menghapus permanen prestasi (status dibatasi opts, lihat PurgeOptions) beserta
+// status log, komentar, dan revisinya lewat runDualWrite. Reference dikunci (FOR UPDATE) dan
+// statusnya dicek sebelum Mongo disentuh; data Mongo dihapus lebih dulu (salinannya disimpan)
+// dan dipulihkan kembali jika penghapusan/commit Postgres gagal.
+func (r *achievementRepository) Purge(ctx context.Context, achievementID string, opts PurgeOptions) (err error) {
+	ctx, span := utils.StartSpan(ctx, "achievement.purge")
+	defer func() { utils.EndSpan(span, err) }()
+
+	var objID primitive.ObjectID
+	achievements := r.mongoDB.Collection("achievements")
+	comments := r.mongoDB.Collection("achievement_comments")
+	revisions := r.mongoDB.Collection("achievement_revisions")
+	commentFilter := bson.M{"achievementId": achievementID}
+	var revisionFilter bson.M
+
+	// Salinan data Mongo yang dihapus, untuk kompensasi.
+	var doc bson.M
+	var savedComments, savedRevisions []interface{}
+
+	return r.runDualWrite(ctx, span, dualWrite{
+		lock: func(tx *gorm.DB) error {
+			var ref model.AchievementReference
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", achievementID).
+				First(&ref).Error; err != nil {
+				return err
+			}
+			if err := checkPurgeAllowed(ref.Status, opts); err != nil {
+				return err
+			}
+			var err error
+			objID, err = primitive.ObjectIDFromHex(ref.MongoAchievementID)
+			revisionFilter = bson.M{"achievementId": objID}
+			return err
+		},
+		mongo: func(c context.Context) error {
+			doc, savedComments, savedRevisions = nil, nil, nil
+			if err := achievements.FindOne(c, bson.M{"_id": objID}).Decode(&doc); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return fmt.Errorf("mongo purge read failed: %w", err)
+			}
+			var err error
+			if savedComments, err = findAllRaw(c, comments, commentFilter); err != nil {
+				return fmt.Errorf("mongo purge read comments failed: %w", err)
+			}
+			if savedRevisions, err = findAllRaw(c, revisions, revisionFilter); err != nil {
+				return fmt.Errorf("mongo purge read revisions failed: %w", err)
+			}
+
+			if _, err := comments.DeleteMany(c, commentFilter); err != nil {
+				return fmt.Errorf("mongo purge comments failed: %w", err)
+			}
+			if _, err := revisions.DeleteMany(c, revisionFilter); err != nil {
+				return fmt.Errorf("mongo purge revisions failed: %w", err)
+			}
+			if _, err := achievements.DeleteOne(c, bson.M{"_id": objID}); err != nil {
+				return fmt.Errorf("mongo purge failed: %w", err)
+			}
+			return nil
+		},
+		// Reference sudah terkunci sejak langkah lock, jadi statusnya tidak bisa berubah di sini.
+		pg: func(tx *gorm.DB) error {
+			if err := tx.Delete(&model.AchievementStatusLog{}, "achievement_reference_id = ?", achievementID).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&model.AchievementReference{}, "id = ?", achievementID).Error
+		},
+		undo: func(c context.Context) error {
+			var errs []error
+			if doc != nil {
+				if _, err := achievements.InsertOne(c, doc); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if len(savedComments) > 0 {
+				if _, err := comments.InsertMany(c, savedComments); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if len(savedRevisions) > 0 {
+				if _, err := revisions.InsertMany(c, savedRevisions); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		},
+		undoEvent: "mongo_purge_restored",
+		oid:       &objID,
+	})
+}
+
+// checkPurgeAllowed memastikan status prestasi boleh dihapus permanen menurut opts.
+func checkPurgeAllowed(status string, opts PurgeOptions) error {
+	switch {
+	case !opts.Hard && status != model.StatusDeleted:
+		return ErrPurgeNotDeleted
+	case status == model.StatusVerified && !opts.AllowVerified:
+		return ErrPurgeVerified
+	}
+	return nil
+}
+
+// findAllRaw mengambil seluruh dokumen yang cocok dengan filter apa adanya (untuk dipulihkan).
+func findAllRaw(ctx context.Context, coll *mongo.Collection, filter bson.M) ([]interface{}, error) {
+	cur, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(docs))
+	for i, d := range docs {
+		out[i] = d
+	}
+	return out, nil
+}
+
+// FindDecidedBetween lihat dokumentasi di interface.
+func (r *achievementRepository) FindDecidedBetween(from, to time.Time) ([]model.AchievementReference, error) {
+	var refs []model.AchievementReference
+	err := r.pgDB.
+		Where("status IN ?", []string{model.StatusVerified, model.StatusRejected}).
+		Where("submitted_at IS NOT NULL AND verified_at >= ? AND verified_at < ?", from, to).
+		Order("verified_at ASC").
+		Order("id ASC").
+		Find(&refs).Error
+	return refs, err
+}
+
+// FindByVerifier lihat dokumentasi di interface.
+func (r *achievementRepository) FindByVerifier(
+	ctx context.Context,
+	userID uuid.UUID,
+	decision *string,
+	from, to *time.Time,
+	page, limit int,
+) ([]model.AchievementReference, int64, error) {
+	page, limit = NormalizePagination(page, limit)
+
+	db := r.pgDB.WithContext(ctx).Model(&model.AchievementReference{}).Where("verified_by = ?", userID)
+	if decision != nil {
+		db = db.Where("status = ?", *decision)
+	} else {
+		db = db.Where("status IN ?", []string{model.StatusVerified, model.StatusRejected})
+	}
+	if from != nil {
+		db = db.Where("verified_at >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where("verified_at < ?", *to)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var refs []model.AchievementReference
+	err := db.Order("verified_at DESC").
+		Order("id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&refs).Error
+	return refs, total, err
+}
+
+// FindReviewTurnaround lihat dokumentasi di interface.
+// Median dosen wali & global dihitung dalam 1 query (percentile_cont + FILTER). Keputusan
+// hasil impor admin diabaikan karena tidak mencerminkan kecepatan review saat ini.
+func (r *achievementRepository) FindReviewTurnaround(advisorID *uuid.UUID, since time.Time) (ReviewTurnaround, error) {
+	advisor := uuid.Nil
+	if advisorID != nil {
+		advisor = *advisorID
+	}
+
+	const days = "EXTRACT(EPOCH FROM (ar.verified_at - ar.submitted_at)) / 86400.0"
+	var out ReviewTurnaround
+	err := r.pgDB.
+		Table("achievement_references AS ar").
+		Select(
+			"percentile_cont(0.5) WITHIN GROUP (ORDER BY "+days+") FILTER (WHERE s.advisor_id = ?) AS advisor_median_days, "+
+				"COUNT(*) FILTER (WHERE s.advisor_id = ?) AS advisor_decisions, "+
+				"percentile_cont(0.5) WITHIN GROUP (ORDER BY "+days+") AS global_median_days, "+
+				"COUNT(*) AS global_decisions",
+			advisor, advisor,
+		).
+		Joins("JOIN students s ON s.id = ar.student_id").
+		Where("ar.status IN ?", []string{model.StatusVerified, model.StatusRejected}).
+		Where("ar.submitted_at IS NOT NULL AND ar.verified_at >= ?", since).
+		Where("ar.decision_imported = ?", false).
+		Scan(&out).Error
+	return out, err
+}
+
+// CountDraftsByStudent lihat dokumentasi di interface.
+func (r *achievementRepository) CountDraftsByStudent(studentID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.pgDB.
+		Model(&model.AchievementReference{}).
+		Where("student_id = ? AND status = ?", studentID, model.StatusDraft).
+		Count(&total).Error
+	return total, err
+}
+
+// FindDraftCounts lihat dokumentasi di interface.
+func (r *achievementRepository) FindDraftCounts(minDrafts int64) ([]StudentDraftCount, error) {
+	var rows []StudentDraftCount
+	err := r.pgDB.
+		Table("achievement_references AS ar").
+		Select("ar.student_id AS student_id, s.student_id AS nim, COUNT(*) AS drafts").
+		Joins("JOIN students s ON s.id = ar.student_id").
+		Where("ar.status = ?", model.StatusDraft).
+		Group("ar.student_id, s.student_id").
+		Having("COUNT(*) >= ?", minDrafts).
+		Order("drafts DESC").
+		Order("ar.student_id ASC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// TestResubmitClearsDecisionKeepsHistory: draft → submitted → rejected → submitted (ClearDecision).
+// Reference terlihat seperti pengajuan baru, sedangkan catatan penolakan tetap ada di riwayat status.
+func TestResubmitClearsDecisionKeepsHistory(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	ctx := context.Background()
+	student := createTestStudent(t, pgDB)
+	r := &achievementRepository{pgDB: pgDB}
+
+	ref := model.AchievementReference{StudentID: student.ID, MongoAchievementID: uuid.NewString(), Status: model.StatusDraft}
+	if err := pgDB.Create(&ref).Error; err != nil {
+		t.Fatal(err)
+	}
+	id := ref.ID.String()
+	verifier := student.UserID.String()
+	note, category, internal := "bukti kurang", "insufficient_evidence", "cek ulang sertifikat"
+
+	steps := []struct {
+		to   string
+		opts UpdateStatusOptions
+	}{
+		{model.StatusSubmitted, UpdateStatusOptions{ActorID: &student.UserID, ExpectedStatus: model.StatusDraft}},
+		{model.StatusRejected, UpdateStatusOptions{VerifierID: &verifier, RejectionNote: &note, RejectionCategory: &category,
+			InternalNote: &internal, ExpectedStatus: model.StatusSubmitted}},
+		{model.StatusSubmitted, UpdateStatusOptions{ActorID: &student.UserID, ClearDecision: true, ExpectedStatus: model.StatusRejected}},
+	}
+	for _, st := range steps {
+		if err := r.UpdateStatus(ctx, id, st.to, st.opts); err != nil {
+			t.Fatalf("-> %s: %v", st.to, err)
+		}
+	}
+
+	got, err := r.FindByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.StatusSubmitted || got.SubmittedAt == nil {
+		t.Fatalf("status %s submitted_at %v, want submitted dengan submitted_at", got.Status, got.SubmittedAt)
+	}
+	if got.VerifiedAt != nil || got.VerifiedBy != nil || got.RejectionNote != nil ||
+		got.RejectionCategory != nil || got.InternalNote != nil || got.DecisionImported {
+		t.Fatalf("keputusan lama masih ada di reference: %+v", got)
+	}
+
+	logs, err := r.FindStatusLogs(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ from, to string }{
+		{model.StatusDraft, model.StatusSubmitted},
+		{model.StatusSubmitted, model.StatusRejected},
+		{model.StatusRejected, model.StatusSubmitted},
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("%d riwayat status, want %d", len(logs), len(want))
+	}
+	for i, w := range want {
+		if logs[i].FromStatus != w.from || logs[i].ToStatus != w.to {
+			t.Fatalf("riwayat %d: %s -> %s, want %s -> %s", i, logs[i].FromStatus, logs[i].ToStatus, w.from, w.to)
+		}
+	}
+	if logs[1].Note == nil || *logs[1].Note != note || logs[1].Category == nil || *logs[1].Category != category {
+		t.Fatalf("catatan penolakan hilang dari riwayat: note %v category %v", logs[1].Note, logs[1].Category)
+	}
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AchievementRevisionRepository mengelola riwayat isi prestasi (Mongo, collection achievement_revisions).
+type AchievementRevisionRepository interface {
+	// Create menyimpan 1 revisi lalu menghapus revisi lama di luar model.AchievementRevisionLimit.
+	Create(ctx context.Context, rev *model.AchievementRevision) error
+	// FindByAchievement: revisi 1 dokumen prestasi (_id achievements), terbaru dulu.
+	FindByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model.AchievementRevision, error)
+	// FindRecentByAchievements: revisi beberapa prestasi sekaligus yang dibuat oleh selain
+	// excludeEditor (terbaru dulu), dibatasi limit.
+	FindRecentByAchievements(ctx context.Context, achievementIDs []primitive.ObjectID, excludeEditor uuid.UUID, limit int) ([]model.AchievementRevision, error)
+	// FindByID: 1 revisi berdasarkan _id (mongo.ErrNoDocuments jika sudah terpangkas).
+	FindByID(ctx context.Context, id primitive.ObjectID) (*model.AchievementRevision, error)
+}
+
+type achievementRevisionRepository struct {
+	col *mongo.Collection
+}
+
+// NewAchievementRevisionRepository membuat instance AchievementRevisionRepository.
+func NewAchievementRevisionRepository(mongoDB *mongo.Database) AchievementRevisionRepository {
+	return &achievementRevisionRepository{col: mongoDB.Collection("achievement_revisions")}
+}
+
+// newestFirst: urutan revisi terbaru dulu (_id sebagai tiebreaker).
+var newestFirst = bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+
+// Create lihat dokumentasi di interface.
+func (r *achievementRevisionRepository) Create(ctx context.Context, rev *model.AchievementRevision) error {
+	rev.ID = primitive.NewObjectID()
+	if _, err := r.col.InsertOne(ctx, rev); err != nil {
+		return err
+	}
+
+	// Pangkas revisi di luar batas (yang lebih lama dari revisi ke-N terbaru)
+	cur, err := r.col.Find(ctx, bson.M{"achievementId": rev.AchievementID},
+		options.Find().
+			SetSort(newestFirst).
+			SetSkip(model.AchievementRevisionLimit).
+			SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var stale []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cur.All(ctx, &stale); err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, 0, len(stale))
+	for _, s := range stale {
+		ids = append(ids, s.ID)
+	}
+	_, err = r.col.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// FindByAchievement lihat dokumentasi di interface.
+func (r *achievementRevisionRepository) FindByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model.AchievementRevision, error) {
+	cur, err := r.col.Find(ctx, bson.M{"achievementId": achievementID},
+		options.Find().SetSort(newestFirst))
+	if err != nil {
+		return nil, err
+	}
+	revisions := []model.AchievementRevision{}
+	if err := cur.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// FindByID lihat dokumentasi di interface.
+func (r *achievementRevisionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.AchievementRevision, error) {
+	var rev model.AchievementRevision
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// FindRecentByAchievements lihat dokumentasi di interface.
+func (r *achievementRevisionRepository) FindRecentByAchievements(ctx context.Context, achievementIDs []primitive.ObjectID, excludeEditor uuid.UUID, limit int) ([]model.AchievementRevision, error) {
+	revisions := []model.AchievementRevision{}
+	if len(achievementIDs) == 0 {
+		return revisions, nil
+	}
+	cur, err := r.col.Find(ctx,
+		bson.M{"achievementId": bson.M{"$in": achievementIDs}, "editedBy": bson.M{"$ne": excludeEditor}},
+		options.Find().SetSort(newestFirst).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// achievementUpgrade meng-upgrade dokumen dari versi i ke i+1 (in-place) dan
+// mengembalikan key top-level yang berubah (dipakai saat upgrade dipersist).
+type achievementUpgrade func(doc bson.M) ([]string, error)
+
+// achievementUpgrades[i] meng-upgrade dokumen versi i → i+1.
+var achievementUpgrades = []achievementUpgrade{
+	upgradeAttachmentFieldNames, // 0 → 1
+	upgradeStudentIDBinary,      // 1 → 2
+}
+
+// Metrik upgrade in-memory per versi asal (dokumen yang di-decode dari bentuk lama).
+var (
+	schemaUpgradeMu     sync.Mutex
+	schemaUpgradeCounts = map[int]int64{}
+)
+
+// SchemaUpgradeCounts mengembalikan jumlah dokumen yang di-upgrade saat dibaca, per versi asal.
+func SchemaUpgradeCounts() map[int]int64 {
+	schemaUpgradeMu.Lock()
+	defer schemaUpgradeMu.Unlock()
+	out := make(map[int]int64, len(schemaUpgradeCounts))
+	for v, n := range schemaUpgradeCounts {
+		out[v] = n
+	}
+	return out
+}
+
+func recordSchemaUpgrade(from int) {
+	schemaUpgradeMu.Lock()
+	schemaUpgradeCounts[from]++
+	first := schemaUpgradeCounts[from] == 1
+	schemaUpgradeMu.Unlock()
+	if first {
+		log.Printf("ℹ️  dokumen achievement schemaVersion %d di-upgrade saat dibaca (jalankan maintenance schema upgrade)", from)
+	}
+}
+
+// DecodeAchievement men-decode dokumen mentah achievements ke model terbaru.
+// Dokumen versi terbaru langsung di-decode ke struct (1 kali decode); hanya dokumen
+// versi lama yang lewat bson.M untuk di-upgrade di memori lebih dulu, sehingga tidak
+// muncul sebagai zero value (atau gagal decode, mis. studentId string).
+// Mengembalikan versi asal dokumen.
+func DecodeAchievement(raw bson.Raw) (*model.Achievement, int, error) {
+	if rawSchemaVersion(raw) == model.AchievementSchemaVersion {
+		var achievement model.Achievement
+		if err := bson.Unmarshal(raw, &achievement); err != nil {
+			return nil, model.AchievementSchemaVersion, err
+		}
+		return &achievement, model.AchievementSchemaVersion, nil
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, 0, err
+	}
+	from, _, err := upgradeAchievementDoc(doc)
+	if err != nil {
+		return nil, from, err
+	}
+	if from < model.AchievementSchemaVersion {
+		recordSchemaUpgrade(from)
+	}
+
+	upgraded, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, from, err
+	}
+	var achievement model.Achievement
+	if err := bson.Unmarshal(upgraded, &achievement); err != nil {
+		return nil, from, err
+	}
+	return &achievement, from, nil
+}
+
+// upgradeAchievementDoc menjalankan semua upgrade yang diperlukan pada doc.
+// Mengembalikan versi asal dan key top-level yang berubah.
+func upgradeAchievementDoc(doc bson.M) (int, []string, error) {
+	from := schemaVersionOf(doc)
+	if from > model.AchievementSchemaVersion {
+		return from, nil, fmt.Errorf("schemaVersion %d lebih baru dari yang dikenal (%d)", from, model.AchievementSchemaVersion)
+	}
+
+	touched := map[string]bool{}
+	for v := from; v < model.AchievementSchemaVersion; v++ {
+		keys, err := achievementUpgrades[v](doc)
+		if err != nil {
+			return from, nil, fmt.Errorf("upgrade schemaVersion %d→%d: %w", v, v+1, err)
+		}
+		for _, k := range keys {
+			touched[k] = true
+		}
+	}
+	if from == model.AchievementSchemaVersion {
+		return from, nil, nil
+	}
+
+	doc["schemaVersion"] = model.AchievementSchemaVersion
+	touched["schemaVersion"] = true
+	keys := make([]string, 0, len(touched))
+	for k := range touched {
+		keys = append(keys, k)
+	}
+	return from, keys, nil
+}
+
+// rawSchemaVersion membaca schemaVersion langsung dari dokumen mentah tanpa decode penuh
+// (field tidak ada = versi 0).
+func rawSchemaVersion(raw bson.Raw) int {
+	v, err := raw.LookupErr("schemaVersion")
+	if err != nil {
+		return 0
+	}
+	n, ok := v.AsInt64OK()
+	if !ok {
+		return 0
+	}
+	return int(n)
+}
+
+// schemaVersionOf membaca schemaVersion (field tidak ada = versi 0).
+func schemaVersionOf(doc bson.M) int {
+	switch v := doc["schemaVersion"].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// asDoc menerima sub-dokumen hasil decode (bson.M atau bson.D) sebagai bson.M.
+func asDoc(v any) (bson.M, bool) {
+	switch d := v.(type) {
+	case bson.M:
+		return d, true
+	case bson.D:
+		m := make(bson.M, len(d))
+		for _, e := range d {
+			m[e.Key] = e.Value
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// upgradeAttachmentFieldNames (0 → 1): lampiran lama memakai url/name/type.
+func upgradeAttachmentFieldNames(doc bson.M) ([]string, error) {
+	list, ok := doc["attachments"].(bson.A)
+	if !ok {
+		return nil, nil
+	}
+	renames := map[string]string{"url": "fileUrl", "name": "fileName", "type": "fileType"}
+	changed := false
+	for i, item := range list {
+		att, ok := asDoc(item)
+		if !ok {
+			continue
+		}
+		for oldKey, newKey := range renames {
+			val, has := att[oldKey]
+			if !has {
+				continue
+			}
+			if _, exists := att[newKey]; !exists {
+				att[newKey] = val
+			}
+			delete(att, oldKey)
+			changed = true
+		}
+		list[i] = att
+	}
+	if !changed {
+		return nil, nil
+	}
+	doc["attachments"] = list
+	return []string{"attachments"}, nil
+}
+
+// upgradeStudentIDBinary (1 → 2): studentId string / binary UUID (subtype 4)
+// dinormalisasi ke binary generic, bentuk yang ditulis encoder uuid.UUID.
+func upgradeStudentIDBinary(doc bson.M) ([]string, error) {
+	switch v := doc["studentId"].(type) {
+	case string:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("studentId tidak valid: %w", err)
+		}
+		doc["studentId"] = primitive.Binary{Subtype: 0x00, Data: id[:]}
+		return []string{"studentId"}, nil
+	case primitive.Binary:
+		if v.Subtype == 0x00 {
+			return nil, nil
+		}
+		if len(v.Data) != 16 {
+			return nil, fmt.Errorf("studentId binary berukuran %d byte", len(v.Data))
+		}
+		doc["studentId"] = primitive.Binary{Subtype: 0x00, Data: v.Data}
+		return []string{"studentId"}, nil
+	}
+	return nil, nil
+}
+
+// persistSchemaUpgrade menyimpan upgrade 1 dokumen (hanya key yang berubah).
+// Dipanggil sebelum menulis ke dokumen lama agar schemaVersion terbaru yang ditulis
+// bersama perubahan tidak menandai bentuk lama sebagai versi terbaru.
+// Filter versi mencegah menimpa dokumen yang sudah di-upgrade proses lain.
+func (r *achievementRepository) persistSchemaUpgrade(ctx context.Context, objID primitive.ObjectID) (bool, error) {
+	coll := r.mongoDB.Collection("achievements")
+	raw, err := coll.FindOne(ctx, bson.M{"_id": objID}).Raw()
+	if err != nil {
+		return false, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return false, err
+	}
+	return r.persistUpgradedDoc(ctx, doc)
+}
+
+func (r *achievementRepository) persistUpgradedDoc(ctx context.Context, doc bson.M) (bool, error) {
+	from, keys, err := upgradeAchievementDoc(doc)
+	if err != nil || len(keys) == 0 {
+		return false, err
+	}
+
+	set := bson.M{}
+	for _, k := range keys {
+		set[k] = doc[k]
+	}
+	filter := bson.M{"_id": doc["_id"]}
+	if from == 0 {
+		filter["schemaVersion"] = bson.M{"$exists": false}
+	} else {
+		filter["schemaVersion"] = from
+	}
+	res, err := r.mongoDB.Collection("achievements").UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// SchemaVersionCounts lihat dokumentasi di interface.
+func (r *achievementRepository) SchemaVersionCounts(ctx context.Context) (map[int]int64, error) {
+	cur, err := r.mongoDB.Collection("achievements").Aggregate(ctx, bson.A{
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$schemaVersion", 0}},
+			"count": bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var rows []struct {
+		Version int   `bson:"_id"`
+		Count   int64 `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	out := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		out[row.Version] = row.Count
+	}
+	return out, nil
+}
+
+// UpgradeStoredDocuments lihat dokumentasi di interface.
+func (r *achievementRepository) UpgradeStoredDocuments(ctx context.Context, limit int) (int, error) {
+	cur, err := r.mongoDB.Collection("achievements").Find(ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"schemaVersion": bson.M{"$exists": false}},
+			bson.M{"schemaVersion": bson.M{"$lt": model.AchievementSchemaVersion}},
+		}},
+		options.Find().SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	upgraded := 0
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return upgraded, err
+		}
+		ok, err := r.persistUpgradedDoc(ctx, doc)
+		if err != nil {
+			return upgraded, fmt.Errorf("upgrade dokumen %v: %w", doc["_id"], err)
+		}
+		if ok {
+			upgraded++
+		}
+	}
+	return upgraded, cur.Err()
+}
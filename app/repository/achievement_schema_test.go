@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDecodeAchievement(t *testing.T) {
+	studentID := uuid.MustParse("1b4e28ba-2fa1-11d2-883f-0016d3cca427")
+	tests := []struct {
+		name         string
+		doc          bson.M
+		wantFrom     int
+		wantUpgraded bool // metrik upgrade in-memory bertambah
+		wantErr      bool
+	}{
+		{name: "v0: lampiran url/name/type, studentId string", wantFrom: 0, wantUpgraded: true, doc: bson.M{
+			"title":       "Juara 1",
+			"studentId":   studentID.String(),
+			"attachments": bson.A{bson.M{"url": "/uploads/a.pdf", "name": "a.pdf", "type": "application/pdf"}},
+		}},
+		{name: "v1: studentId binary subtype 4", wantFrom: 1, wantUpgraded: true, doc: bson.M{
+			"schemaVersion": 1,
+			"title":         "Juara 1",
+			"studentId":     primitive.Binary{Subtype: 0x04, Data: studentID[:]},
+			"attachments":   bson.A{bson.M{"fileUrl": "/uploads/a.pdf", "fileName": "a.pdf", "fileType": "application/pdf"}},
+		}},
+		{name: "versi terbaru di-decode langsung", wantFrom: model.AchievementSchemaVersion, doc: bson.M{
+			"schemaVersion": model.AchievementSchemaVersion,
+			"title":         "Juara 1",
+			"studentId":     studentID,
+			"attachments":   bson.A{bson.M{"fileUrl": "/uploads/a.pdf", "fileName": "a.pdf", "fileType": "application/pdf"}},
+		}},
+		{name: "versi tidak dikenal ditolak", wantFrom: model.AchievementSchemaVersion + 1, wantErr: true, doc: bson.M{
+			"schemaVersion": model.AchievementSchemaVersion + 1,
+			"title":         "Juara 1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := SchemaUpgradeCounts()[tt.wantFrom]
+
+			got, from, err := DecodeAchievement(raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if from != tt.wantFrom {
+				t.Fatalf("versi asal %d, want %d", from, tt.wantFrom)
+			}
+			if upgraded := SchemaUpgradeCounts()[tt.wantFrom] > before; upgraded != tt.wantUpgraded {
+				t.Fatalf("metrik upgrade bertambah = %v, want %v", upgraded, tt.wantUpgraded)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.StudentID != studentID || got.Title != "Juara 1" {
+				t.Fatalf("studentId %s title %q, want %s %q", got.StudentID, got.Title, studentID, "Juara 1")
+			}
+			if len(got.Attachments) != 1 || got.Attachments[0].FileURL != "/uploads/a.pdf" ||
+				got.Attachments[0].FileName != "a.pdf" || got.Attachments[0].FileType != "application/pdf" {
+				t.Fatalf("lampiran tidak ter-upgrade: %+v", got.Attachments)
+			}
+		})
+	}
+}
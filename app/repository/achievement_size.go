@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"student-achievement-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrDocumentTooLarge dikembalikan sebelum dokumen prestasi di Mongo mendekati batas 16MB
+// (atau jumlah lampiran melebihi batas), agar client mendapat error jelas, bukan error Mongo.
+var ErrDocumentTooLarge = errors.New("achievement document too large")
+
+// Kode error Mongo untuk dokumen yang melebihi batas BSON (BSONObjectTooLarge & varian update).
+var mongoTooLargeCodes = map[int]bool{10334: true, 17419: true, 17420: true}
+
+// maxDocumentBytes membaca ACHIEVEMENT_MAX_DOCUMENT_BYTES (default 12MB).
+// Default disisakan ruang dari batas keras Mongo (16MB) supaya update kecil berikutnya
+// (status, override poin) tetap bisa masuk.
+func maxDocumentBytes() int {
+	return utils.Runtime().MaxDocumentBytes
+}
+
+// maxAttachments membaca ACHIEVEMENT_MAX_ATTACHMENTS (default 200).
+func maxAttachments() int {
+	return utils.Runtime().MaxAttachments
+}
+
+// documentSize menghitung ukuran BSON dokumen achievement saat ini di server ($bsonSize).
+func (r *achievementRepository) documentSize(ctx context.Context, objID primitive.ObjectID) (int, error) {
+	cur, err := r.mongoDB.Collection("achievements").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": objID}}},
+		{{Key: "$project", Value: bson.M{"size": bson.M{"$bsonSize": "$$ROOT"}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var out []struct {
+		Size int `bson:"size"`
+	}
+	if err := cur.All(ctx, &out); err != nil {
+		return 0, err
+	}
+	if len(out) == 0 {
+		return 0, mongo.ErrNoDocuments
+	}
+	return out[0].Size, nil
+}
+
+// ensureRoomFor memastikan dokumen masih muat jika ditambah value (perkiraan dari ukuran BSON value).
+func (r *achievementRepository) ensureRoomFor(ctx context.Context, objID primitive.ObjectID, value any) error {
+	current, err := r.documentSize(ctx, objID)
+	if err != nil {
+		return err
+	}
+	extra, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		return err
+	}
+	if current+len(extra) > maxDocumentBytes() {
+		return ErrDocumentTooLarge
+	}
+	return nil
+}
+
+// attachmentSlotFilter membuat filter atomik "jumlah lampiran < batas":
+// elemen ke-(batas-1) belum ada berarti masih ada slot untuk 1 lampiran lagi.
+func attachmentSlotFilter() bson.M {
+	return bson.M{"attachments." + strconv.Itoa(maxAttachments()-1): bson.M{"$exists": false}}
+}
+
+// asTooLarge memetakan error batas ukuran dari Mongo ke ErrDocumentTooLarge.
+func asTooLarge(err error) error {
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		for _, e := range we.WriteErrors {
+			if mongoTooLargeCodes[e.Code] {
+				return ErrDocumentTooLarge
+			}
+		}
+	}
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && mongoTooLargeCodes[int(ce.Code)] {
+		return ErrDocumentTooLarge
+	}
+	return err
+}
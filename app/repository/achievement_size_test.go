@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// setRuntimeEnv mengganti setting runtime selama 1 test; setelah env dikembalikan,
+// setting dimuat ulang agar test lain memakai nilai semula.
+func setRuntimeEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	t.Cleanup(func() { _ = utils.InitRuntimeConfig() }) // jalan setelah t.Setenv mengembalikan env
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	if err := utils.InitRuntimeConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsTooLarge(t *testing.T) {
+	other := errors.New("mongo down")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "BSONObjectTooLarge", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 10334}}}, want: ErrDocumentTooLarge},
+		{name: "update terlalu besar", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 17419}}}, want: ErrDocumentTooLarge},
+		{name: "command error", err: mongo.CommandError{Code: 17420}, want: ErrDocumentTooLarge},
+		{name: "duplicate key tetap apa adanya", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}},
+		{name: "error lain tetap apa adanya", err: other, want: other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := asTooLarge(tt.err)
+			if tt.want == nil {
+				if errors.Is(got, ErrDocumentTooLarge) {
+					t.Fatalf("%v dipetakan ke ErrDocumentTooLarge", tt.err)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("asTooLarge(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentSlotFilter(t *testing.T) {
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_MAX_ATTACHMENTS": "3"})
+	got := attachmentSlotFilter()
+	if len(got) != 1 || got["attachments.2"] == nil {
+		t.Fatalf("filter %v, want attachments.2 belum ada", got)
+	}
+}
+
+// TestDocumentSizeLimits: dokumen yang mendekati batas menolak lampiran & isi baru dengan
+// ErrDocumentTooLarge sebelum Mongo menolak, dan dokumennya tidak berubah.
+func TestDocumentSizeLimits(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: openTestMongo(t), mongoTx: &mongoTxSupport{}}
+	student := createTestStudent(t, pgDB)
+	ctx := context.Background()
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_MAX_DOCUMENT_BYTES": "8192", "ACHIEVEMENT_MAX_ATTACHMENTS": "2"})
+
+	ref, filter := newEditableAchievement(t, r, student)
+	id := ref.ID.String()
+	attachment := func(n string) model.Attachment {
+		return model.Attachment{ID: n, FileName: n + ".pdf", FileURL: "/uploads/achievements/" + id + "/" + n + ".pdf",
+			FileType: "pdf", UploadedAt: time.Now()}
+	}
+
+	// Slot lampiran: batas jumlah berlaku walau ukuran masih cukup.
+	for _, n := range []string{"a1", "a2"} {
+		if err := r.AddAttachment(ctx, id, attachment(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.AddAttachment(ctx, id, attachment("a3")); !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("lampiran ke-3: %v, want ErrDocumentTooLarge", err)
+	}
+
+	// Dokumen mendekati batas ukuran: lampiran berikutnya ditolak lebih awal.
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_MAX_ATTACHMENTS": "200"})
+	objID := filter["_id"].(primitive.ObjectID)
+	size, err := r.documentSize(ctx, objID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Isi description sampai dokumen tersisa ±100 byte dari batas (masih valid, tapi tidak muat 1 lampiran).
+	if _, err := r.mongoDB.Collection("achievements").UpdateOne(ctx, filter,
+		bson.M{"$set": bson.M{"description": strings.Repeat("x", 8192-size-100)}}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := r.documentSize(ctx, objID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before > 8192 {
+		t.Fatalf("dokumen awal %d byte sudah melebihi batas", before)
+	}
+	if err := r.AddAttachment(ctx, id, attachment("a3")); !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("lampiran pada dokumen hampir penuh: %v, want ErrDocumentTooLarge", err)
+	}
+	if after, _ := r.documentSize(ctx, objID); after != before {
+		t.Fatalf("ukuran dokumen berubah %d → %d walau ditolak", before, after)
+	}
+
+	// Isi baru yang melebihi batas ditolak sebelum ditulis.
+	big := &model.Achievement{AchievementType: "competition", Title: "Judul awal", Description: strings.Repeat("y", 9000)}
+	if err := r.UpdateContent(ctx, id, big); !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("update isi terlalu besar: %v, want ErrDocumentTooLarge", err)
+	}
+	if after, _ := r.documentSize(ctx, objID); after != before {
+		t.Fatalf("ukuran dokumen berubah %d → %d walau update ditolak", before, after)
+	}
+}
+
+// TestRevisionsRollOverOutsideDocument: riwayat edit disimpan di collection achievement_revisions
+// (bukan di dokumen prestasi) dan dipangkas ke AchievementRevisionLimit terbaru, sehingga
+// edit berulang tidak membuat dokumen prestasi mendekati batas ukuran.
+func TestRevisionsRollOverOutsideDocument(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	mongoDB := openTestMongo(t)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: mongoDB, mongoTx: &mongoTxSupport{}}
+	revisions := NewAchievementRevisionRepository(mongoDB)
+	student := createTestStudent(t, pgDB)
+	ctx := context.Background()
+
+	_, filter := newEditableAchievement(t, r, student)
+	objID := filter["_id"].(primitive.ObjectID)
+	before, err := r.documentSize(ctx, objID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	total := model.AchievementRevisionLimit + 5
+	for i := 0; i < total; i++ {
+		rev := &model.AchievementRevision{AchievementID: objID, Title: fmt.Sprintf("versi %d", i),
+			Description: strings.Repeat("z", 1024), CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := revisions.Create(ctx, rev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := revisions.FindByAchievement(ctx, objID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != model.AchievementRevisionLimit {
+		t.Fatalf("%d revisi tersimpan, want %d", len(got), model.AchievementRevisionLimit)
+	}
+	if got[0].Title != fmt.Sprintf("versi %d", total-1) || got[len(got)-1].Title != fmt.Sprintf("versi %d", total-model.AchievementRevisionLimit) {
+		t.Fatalf("revisi tersimpan %q..%q, want %d terbaru", got[0].Title, got[len(got)-1].Title, model.AchievementRevisionLimit)
+	}
+	if after, _ := r.documentSize(ctx, objID); after != before {
+		t.Fatalf("ukuran dokumen prestasi berubah %d → %d karena revisi", before, after)
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+)
+
+// seedSameTimestampRefs membuat n reference milik student dengan created_at & submitted_at
+// yang sama persis, lalu mengembalikan id-nya terurut DESC (urutan tiebreaker default).
+func seedSameTimestampRefs(t *testing.T, pgDB *gorm.DB, student model.Student, n int) []uuid.UUID {
+	t.Helper()
+	at := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	var ids []uuid.UUID
+	for i := 0; i < n; i++ {
+		ref := model.AchievementReference{StudentID: student.ID, MongoAchievementID: uuid.NewString(),
+			Status: model.StatusSubmitted, SubmittedAt: &at, CreatedAt: at, UpdatedAt: at}
+		if err := pgDB.Create(&ref).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, ref.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() > ids[j].String() })
+	return ids
+}
+
+// assertStablePages mengambil semua halaman 3x: isi tiap halaman harus sama di setiap
+// pemanggilan, tiap baris muncul tepat 1 kali, dan urutannya mengikuti want.
+func assertStablePages(t *testing.T, want []uuid.UUID, limit int, fetch func(page int) ([]model.AchievementReference, error)) {
+	t.Helper()
+	var first [][]uuid.UUID
+	for run := 0; run < 3; run++ {
+		var pages [][]uuid.UUID
+		for page := 1; (page-1)*limit < len(want); page++ {
+			refs, err := fetch(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uuid.UUID
+			for _, ref := range refs {
+				ids = append(ids, ref.ID)
+			}
+			pages = append(pages, ids)
+		}
+		if run == 0 {
+			first = pages
+			continue
+		}
+		if !reflect.DeepEqual(pages, first) {
+			t.Fatalf("pemanggilan ke-%d: halaman %v, want %v", run+1, pages, first)
+		}
+	}
+
+	var got []uuid.UUID
+	for _, ids := range first {
+		got = append(got, ids...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("gabungan halaman %v, want %v", got, want)
+	}
+}
+
+// TestPaginationTiebreakerSameTimestamp: baris dengan created_at/submitted_at/status identik
+// tetap memiliki batas halaman yang stabil (tiebreaker id) di list mahasiswa & list dosen wali.
+func TestPaginationTiebreakerSameTimestamp(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	ctx := context.Background()
+	student := createTestStudent(t, pgDB)
+	want := seedSameTimestampRefs(t, pgDB, student, 7)
+	studentID := student.ID.String()
+
+	achievements := &achievementRepository{pgDB: pgDB}
+	lecturers := NewLecturerRepository(pgDB)
+
+	sorts := []AchievementSort{
+		{},
+		{Field: SortSubmittedAt},
+		{Field: SortSubmittedAt, Asc: true},
+		{Field: SortStatus, Asc: true},
+	}
+	for _, s := range sorts {
+		t.Run(fmt.Sprintf("achievement %s asc=%v", s.Field, s.Asc), func(t *testing.T) {
+			assertStablePages(t, want, 3, func(page int) ([]model.AchievementReference, error) {
+				refs, _, err := achievements.FindFiltered(ctx, AchievementListFilter{
+					StudentID: &studentID, Sort: s, Page: page, Limit: 3})
+				return refs, err
+			})
+		})
+		t.Run(fmt.Sprintf("lecturer %s asc=%v", s.Field, s.Asc), func(t *testing.T) {
+			assertStablePages(t, want, 3, func(page int) ([]model.AchievementReference, error) {
+				refs, _, err := lecturers.FindAchievementsByStudentIDs(ctx, AdviseeAchievementFilter{
+					StudentIDs: []uuid.UUID{student.ID}, Sort: s, Page: page, Limit: 3})
+				return refs, err
+			})
+		})
+	}
+
+	t.Run("created_at ASC", func(t *testing.T) {
+		asc := append([]uuid.UUID{}, want...)
+		sort.Slice(asc, func(i, j int) bool { return asc[i].String() < asc[j].String() })
+		assertStablePages(t, asc, 3, func(page int) ([]model.AchievementReference, error) {
+			refs, _, err := achievements.FindFiltered(ctx, AchievementListFilter{
+				StudentID: &studentID, Sort: AchievementSort{Field: SortCreatedAt, Asc: true}, Page: page, Limit: 3})
+			return refs, err
+		})
+	})
+}
+
+// TestTopStudentsTiebreaker: mahasiswa dengan total poin & jumlah prestasi sama diurutkan
+// berdasarkan studentId, sehingga batas top 10 tidak berubah antar pemanggilan.
+func TestTopStudentsTiebreaker(t *testing.T) {
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+	r := NewReportRepository(mongoDB)
+
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	var ids []string
+	var docs []interface{}
+	for i := 0; i < 13; i++ {
+		id := uuid.NewString()
+		ids = append(ids, id)
+		docs = append(docs, bson.M{"studentId": id, "achievementType": "competition",
+			"points": 10, "createdAt": createdAt})
+	}
+	if _, err := mongoDB.Collection("achievements").InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ids)
+
+	var first []StudentScore
+	for run := 0; run < 3; run++ {
+		res, err := r.GetStatistics(ctx, ReportFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run == 0 {
+			first = res.TopStudents
+			continue
+		}
+		if !reflect.DeepEqual(res.TopStudents, first) {
+			t.Fatalf("pemanggilan ke-%d: top %v, want %v", run+1, res.TopStudents, first)
+		}
+	}
+	if len(first) != 10 {
+		t.Fatalf("top %d mahasiswa, want 10", len(first))
+	}
+	for i, s := range first {
+		if s.StudentID != ids[i] || s.TotalPoints != 10 || s.TotalAchievements != 1 {
+			t.Fatalf("peringkat %d = %+v, want studentId %s", i+1, s, ids[i])
+		}
+	}
+}
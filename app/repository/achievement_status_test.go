@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// TestUpdateStatusCompetingTransitions: beberapa transisi dari status yang sama berjalan
+// bersamaan (mis. mahasiswa menarik pengajuan saat dosen memverifikasi); tepat 1 yang menang,
+// sisanya ErrStatusConflict, dan hanya 1 baris riwayat status yang tercatat.
+func TestUpdateStatusCompetingTransitions(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	student := createTestStudent(t, pgDB)
+	r := &achievementRepository{pgDB: pgDB}
+
+	verifier := student.UserID.String()
+	tests := []struct {
+		name    string
+		from    string
+		targets []string
+	}{
+		{name: "tarik vs verifikasi", from: model.StatusSubmitted, targets: []string{model.StatusDraft, model.StatusVerified}},
+		{name: "verifikasi vs tolak", from: model.StatusSubmitted, targets: []string{model.StatusVerified, model.StatusRejected}},
+		{name: "8 verifikasi bersamaan", from: model.StatusSubmitted, targets: []string{
+			model.StatusVerified, model.StatusVerified, model.StatusVerified, model.StatusVerified,
+			model.StatusVerified, model.StatusVerified, model.StatusVerified, model.StatusVerified,
+		}},
+		{name: "ajukan ulang bersamaan", from: model.StatusRejected, targets: []string{model.StatusSubmitted, model.StatusSubmitted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := model.AchievementReference{StudentID: student.ID, MongoAchievementID: uuid.NewString(), Status: tt.from}
+			if err := pgDB.Create(&ref).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			start := make(chan struct{})
+			errs := make([]error, len(tt.targets))
+			var wg sync.WaitGroup
+			for i, target := range tt.targets {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					errs[i] = r.UpdateStatus(context.Background(), ref.ID.String(), target, UpdateStatusOptions{
+						VerifierID:     &verifier,
+						ExpectedStatus: tt.from,
+					})
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			won := -1
+			for i, err := range errs {
+				switch {
+				case err == nil && won >= 0:
+					t.Fatalf("transisi %d dan %d sama-sama berhasil", won, i)
+				case err == nil:
+					won = i
+				case !errors.Is(err, ErrStatusConflict):
+					t.Fatalf("transisi %d: err = %v, want ErrStatusConflict", i, err)
+				}
+			}
+			if won < 0 {
+				t.Fatal("tidak ada transisi yang berhasil")
+			}
+
+			var got model.AchievementReference
+			if err := pgDB.First(&got, "id = ?", ref.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.targets[won] {
+				t.Fatalf("status akhir %q, want %q (pemenang)", got.Status, tt.targets[won])
+			}
+			var logs int64
+			if err := pgDB.Model(&model.AchievementStatusLog{}).Where("achievement_reference_id = ?", ref.ID).Count(&logs).Error; err != nil {
+				t.Fatal(err)
+			}
+			if logs != 1 {
+				t.Fatalf("riwayat status %d baris, want 1", logs)
+			}
+		})
+	}
+}
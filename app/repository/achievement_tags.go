@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindTagCounts lihat dokumentasi di interface.
+func (r *achievementRepository) FindTagCounts(ctx context.Context, scope ReportFilter, prefix string, limit int) ([]TagCount, error) {
+	match := bson.M{"deleted": bson.M{"$ne": true}}
+	if len(scope.StudentIDs) > 0 {
+		match["studentId"] = bson.M{"$in": studentIDMatchValues(scope.StudentIDs)}
+	}
+	tagMatch := bson.M{"$exists": true}
+	if prefix != "" {
+		tagMatch = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix), "$options": "i"}
+	}
+	// Filter tag sebelum $unwind (memakai index tags) dan sesudahnya (hanya elemen yang cocok).
+	match["tags"] = tagMatch
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$unwind": "$tags"},
+		{"$match": bson.M{"tags": tagMatch}},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+
+	cur, err := r.mongoDB.Collection("achievements").Aggregate(ctx, pipeline, options.Aggregate())
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	tags := []TagCount{}
+	if err := cur.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// studentIDMatchValues mengubah daftar studentId (string UUID) menjadi nilai $in yang cocok
+// dengan dokumen versi terbaru (UUID binary) maupun dokumen lama (string).
+func studentIDMatchValues(ids []string) []any {
+	values := make([]any, 0, 2*len(ids))
+	for _, id := range ids {
+		if parsed, err := uuid.Parse(id); err == nil {
+			values = append(values, parsed)
+		}
+		values = append(values, id)
+	}
+	return values
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans memasang TracerProvider global dengan SpanRecorder selama 1 test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+// endedSpan mencari span selesai berdasarkan nama.
+func endedSpan(t *testing.T, rec *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range rec.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("span %q tidak tercatat", name)
+	return nil
+}
+
+// eventAttrs mengembalikan atribut event bernama name pada span (ok=false jika tidak ada).
+func eventAttrs(s sdktrace.ReadOnlySpan, name string) (map[attribute.Key]attribute.Value, bool) {
+	for _, e := range s.Events() {
+		if e.Name == name {
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range e.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			return attrs, true
+		}
+	}
+	return nil, false
+}
+
+// TestCompensateRecordsEvent: langkah kompensasi menjadi child span sendiri, dan hasilnya
+// (berhasil, atau gagal karena Mongo error) dicatat sebagai event di span operasi.
+func TestCompensateRecordsEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		undoErr error
+	}{
+		{name: "kompensasi berhasil"},
+		{name: "mongo gagal saat kompensasi", undoErr: errors.New("mongo: connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := recordSpans(t)
+			r := &achievementRepository{}
+			oid := primitive.NewObjectID()
+
+			ctx, parent := utils.StartSpan(context.Background(), "achievement.create")
+			r.compensate(ctx, parent, "mongo_insert_reverted", oid, func(context.Context) error { return tt.undoErr })
+			parent.End()
+
+			op := endedSpan(t, rec, "achievement.create")
+			child := endedSpan(t, rec, "mongo.achievements.compensate")
+			if child.Parent().SpanID() != op.SpanContext().SpanID() {
+				t.Fatal("span kompensasi bukan child span operasi")
+			}
+			if got := child.Status().Code == codes.Error; got != (tt.undoErr != nil) {
+				t.Fatalf("status span kompensasi %v, want error=%v", child.Status(), tt.undoErr != nil)
+			}
+
+			attrs, ok := eventAttrs(op, "mongo_insert_reverted")
+			if !ok {
+				t.Fatalf("event kompensasi tidak tercatat: %+v", op.Events())
+			}
+			if attrs["mongo.id"].AsString() != oid.Hex() {
+				t.Fatalf("mongo.id %q, want %q", attrs["mongo.id"].AsString(), oid.Hex())
+			}
+			if attrs["compensation.succeeded"].AsBool() != (tt.undoErr == nil) {
+				t.Fatalf("compensation.succeeded %v", attrs["compensation.succeeded"].AsBool())
+			}
+			if tt.undoErr != nil && attrs["compensation.error"].AsString() != tt.undoErr.Error() {
+				t.Fatalf("compensation.error %q", attrs["compensation.error"].AsString())
+			}
+		})
+	}
+}
+
+// TestCreateSpanHierarchy: Create menghasilkan span "achievement.create" dengan child insertOne;
+// jika insert Postgres gagal (mode Mongo standalone), dokumen dihapus kembali dan event
+// kompensasi tercatat di span create yang berstatus error.
+func TestCreateSpanHierarchy(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	mongoDB := openTestMongo(t)
+	student := createTestStudent(t, pgDB)
+	r := &achievementRepository{pgDB: pgDB, mongoDB: mongoDB, mongoTx: &mongoTxSupport{known: true}}
+	newDoc := func() *model.Achievement {
+		return &model.Achievement{StudentID: student.ID, AchievementType: "competition", Title: "Lomba",
+			CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+
+	t.Run("berhasil", func(t *testing.T) {
+		rec := recordSpans(t)
+		ref := &model.AchievementReference{StudentID: student.ID, Status: model.StatusDraft}
+		if err := r.Create(context.Background(), ref, newDoc()); err != nil {
+			t.Fatal(err)
+		}
+		op := endedSpan(t, rec, "achievement.create")
+		insert := endedSpan(t, rec, "mongo.achievements.insertOne")
+		if op.Parent().IsValid() || insert.Parent().SpanID() != op.SpanContext().SpanID() {
+			t.Fatal("insertOne bukan child span achievement.create")
+		}
+		if op.Status().Code == codes.Error || len(op.Events()) != 0 {
+			t.Fatalf("create berhasil: status %v event %+v", op.Status(), op.Events())
+		}
+	})
+
+	t.Run("postgres gagal, mongo dikompensasi", func(t *testing.T) {
+		existing, _ := newEditableAchievement(t, r, student)
+		rec := recordSpans(t)
+		// ID yang sudah dipakai membuat insert Postgres gagal setelah dokumen Mongo tersimpan.
+		ref := &model.AchievementReference{ID: existing.ID, StudentID: student.ID, Status: model.StatusDraft}
+		if err := r.Create(context.Background(), ref, newDoc()); err == nil {
+			t.Fatal("Create dengan id duplikat berhasil")
+		}
+
+		op := endedSpan(t, rec, "achievement.create")
+		if op.Status().Code != codes.Error {
+			t.Fatalf("status span create %v, want error", op.Status())
+		}
+		attrs, ok := eventAttrs(op, "mongo_insert_reverted")
+		if !ok || !attrs["compensation.succeeded"].AsBool() {
+			t.Fatalf("event kompensasi %+v", op.Events())
+		}
+		oid, err := primitive.ObjectIDFromHex(attrs["mongo.id"].AsString())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := mongoDB.Collection("achievements").CountDocuments(context.Background(), bson.M{"_id": oid}); n != 0 {
+			t.Fatal("dokumen Mongo tidak dihapus setelah kompensasi")
+		}
+		if endedSpan(t, rec, "mongo.achievements.compensate").Parent().SpanID() != op.SpanContext().SpanID() {
+			t.Fatal("span kompensasi bukan child span achievement.create")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"student-achievement-backend/app/model"
+
+	"gorm.io/gorm"
+)
+
+// AchievementTypeRepository menangani tabel achievement_types (katalog tipe prestasi).
+type AchievementTypeRepository interface {
+	// FindAll mengambil katalog tipe; activeOnly=true → hanya tipe aktif.
+	FindAll(activeOnly bool) ([]model.AchievementType, error)
+	FindByCode(code string) (*model.AchievementType, error)
+	Create(t *model.AchievementType) error
+	Update(t *model.AchievementType) error
+	// Deactivate menonaktifkan tipe (dokumen lama tetap memakai kode tsb).
+	Deactivate(code string) error
+}
+
+type achievementTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewAchievementTypeRepository(db *gorm.DB) AchievementTypeRepository {
+	return &achievementTypeRepository{db}
+}
+
+func (r *achievementTypeRepository) FindAll(activeOnly bool) ([]model.AchievementType, error) {
+	var list []model.AchievementType
+	q := r.db.Order("code ASC")
+	if activeOnly {
+		q = q.Where("active = ?", true)
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+func (r *achievementTypeRepository) FindByCode(code string) (*model.AchievementType, error) {
+	var t model.AchievementType
+	if err := r.db.First(&t, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *achievementTypeRepository) Create(t *model.AchievementType) error {
+	return r.db.Create(t).Error
+}
+
+func (r *achievementTypeRepository) Update(t *model.AchievementType) error {
+	return r.db.Model(&model.AchievementType{}).
+		Where("code = ?", t.Code).
+		Updates(map[string]interface{}{
+			"label":          t.Label,
+			"active":         t.Active,
+			"default_points": t.DefaultPoints,
+		}).Error
+}
+
+func (r *achievementTypeRepository) Deactivate(code string) error {
+	res := r.db.Model(&model.AchievementType{}).
+		Where("code = ?", code).
+		Update("active", false)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
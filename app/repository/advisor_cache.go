@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// AdvisorCache menyimpan hasil IsAdvisorOf (lecturerID, studentID) di memori dengan TTL.
+// Cache ini per-proses; invalidasi eksplisit dilakukan oleh decorator repository yang
+// mengubah relasi dosen wali (UpdateAdvisor, merge akun, delegasi).
+type AdvisorCache struct {
+	mu      sync.RWMutex
+	ttl     func() time.Duration // dibaca setiap kali: TTL bisa berubah saat config di-reload
+	entries map[advisorKey]advisorEntry
+	// gen naik setiap invalidasi; hasil query yang dimulai sebelum invalidasi tidak disimpan,
+	// sehingga nilai lama tidak bisa "menimpa" invalidasi yang terjadi di tengah query.
+	gen uint64
+}
+
+type advisorKey struct {
+	lecturerID uuid.UUID
+	studentID  uuid.UUID
+}
+
+type advisorEntry struct {
+	ok       bool
+	cachedAt time.Time
+}
+
+// NewAdvisorCache membuat cache dengan TTL dari fungsi ttl (ttl() <= 0 → cache nonaktif).
+// Umur entri dibandingkan dengan TTL saat dibaca, sehingga TTL yang diperkecil langsung berlaku.
+func NewAdvisorCache(ttl func() time.Duration) *AdvisorCache {
+	return &AdvisorCache{ttl: ttl, entries: map[advisorKey]advisorEntry{}}
+}
+
+func (c *AdvisorCache) get(k advisorKey) (ok bool, found bool, gen uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, exists := c.entries[k]
+	if exists && time.Since(e.cachedAt) < c.ttl() {
+		return e.ok, true, c.gen
+	}
+	return false, false, c.gen
+}
+
+func (c *AdvisorCache) set(k advisorKey, ok bool, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	c.entries[k] = advisorEntry{ok: ok, cachedAt: time.Now()}
+}
+
+// InvalidateStudent menghapus semua entri untuk 1 mahasiswa.
+func (c *AdvisorCache) InvalidateStudent(studentID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for k := range c.entries {
+		if k.studentID == studentID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// InvalidateLecturer menghapus semua entri untuk 1 dosen.
+func (c *AdvisorCache) InvalidateLecturer(lecturerID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for k := range c.entries {
+		if k.lecturerID == lecturerID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Clear mengosongkan cache (dipakai untuk perubahan massal).
+func (c *AdvisorCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.entries = map[advisorKey]advisorEntry{}
+}
+
+// ===================== Decorators =====================
+
+// cachedLecturerRepository: IsAdvisorOf dibaca lewat cache, method lain diteruskan.
+type cachedLecturerRepository struct {
+	LecturerRepository
+	cache *AdvisorCache
+}
+
+// NewCachedLecturerRepository membungkus LecturerRepository dengan cache IsAdvisorOf.
+func NewCachedLecturerRepository(inner LecturerRepository, cache *AdvisorCache) LecturerRepository {
+	return &cachedLecturerRepository{LecturerRepository: inner, cache: cache}
+}
+
+func (r *cachedLecturerRepository) IsAdvisorOf(lecturerID uuid.UUID, studentID uuid.UUID) (bool, error) {
+	if r.cache.ttl() <= 0 {
+		return r.LecturerRepository.IsAdvisorOf(lecturerID, studentID)
+	}
+	k := advisorKey{lecturerID, studentID}
+	ok, found, gen := r.cache.get(k)
+	if found {
+		return ok, nil
+	}
+	ok, err := r.LecturerRepository.IsAdvisorOf(lecturerID, studentID)
+	if err != nil {
+		return false, err
+	}
+	r.cache.set(k, ok, gen)
+	return ok, nil
+}
+
+// cachedStudentRepository meng-invalidasi cache saat dosen wali mahasiswa diganti.
+type cachedStudentRepository struct {
+	StudentRepository
+	cache *AdvisorCache
+}
+
+// NewCachedStudentRepository membungkus StudentRepository agar UpdateAdvisor meng-invalidasi cache.
+func NewCachedStudentRepository(inner StudentRepository, cache *AdvisorCache) StudentRepository {
+	return &cachedStudentRepository{StudentRepository: inner, cache: cache}
+}
+
+func (r *cachedStudentRepository) UpdateAdvisor(studentID, advisorID uuid.UUID) error {
+	// Invalidasi sebelum & sesudah update: query yang berjalan di antaranya tidak akan tersimpan.
+	r.cache.InvalidateStudent(studentID)
+	err := r.StudentRepository.UpdateAdvisor(studentID, advisorID)
+	r.cache.InvalidateStudent(studentID)
+	return err
+}
+
+// cachedUserAdminRepository meng-invalidasi cache saat merge akun (advisor_id dipindah massal).
+type cachedUserAdminRepository struct {
+	UserAdminRepository
+	cache *AdvisorCache
+}
+
+// NewCachedUserAdminRepository membungkus UserAdminRepository agar MergeUsers mengosongkan cache.
+func NewCachedUserAdminRepository(inner UserAdminRepository, cache *AdvisorCache) UserAdminRepository {
+	return &cachedUserAdminRepository{UserAdminRepository: inner, cache: cache}
+}
+
+func (r *cachedUserAdminRepository) MergeUsers(ctx context.Context, primaryID, secondaryID uuid.UUID) (*MergeSummary, error) {
+	r.cache.Clear()
+	summary, err := r.UserAdminRepository.MergeUsers(ctx, primaryID, secondaryID)
+	r.cache.Clear()
+	return summary, err
+}
+
+// cachedDelegationRepository meng-invalidasi entri dosen yang terlibat saat delegasi berubah.
+type cachedDelegationRepository struct {
+	DelegationRepository
+	cache *AdvisorCache
+}
+
+// NewCachedDelegationRepository membungkus DelegationRepository agar Create/Revoke meng-invalidasi cache.
+func NewCachedDelegationRepository(inner DelegationRepository, cache *AdvisorCache) DelegationRepository {
+	return &cachedDelegationRepository{DelegationRepository: inner, cache: cache}
+}
+
+func (r *cachedDelegationRepository) Create(d *model.VerificationDelegation) error {
+	err := r.DelegationRepository.Create(d)
+	r.cache.InvalidateLecturer(d.FromLecturerID)
+	r.cache.InvalidateLecturer(d.ToLecturerID)
+	return err
+}
+
+func (r *cachedDelegationRepository) Revoke(id uuid.UUID) error {
+	d, findErr := r.DelegationRepository.FindByID(id)
+	err := r.DelegationRepository.Revoke(id)
+	if findErr == nil {
+		r.cache.InvalidateLecturer(d.FromLecturerID)
+		r.cache.InvalidateLecturer(d.ToLecturerID)
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// advisorStore: relasi dosen wali di memori yang dipakai bersama fake repository di bawah.
+type advisorStore struct {
+	mu       sync.Mutex
+	advisors map[uuid.UUID]uuid.UUID // studentID → lecturerID
+	queries  int
+	// duringQuery dijalankan di tengah IsAdvisorOf (setelah nilai dibaca, sebelum kembali).
+	duringQuery func()
+}
+
+type fakeAdvisorLecturerRepo struct {
+	LecturerRepository
+	store *advisorStore
+}
+
+func (r *fakeAdvisorLecturerRepo) IsAdvisorOf(lecturerID, studentID uuid.UUID) (bool, error) {
+	r.store.mu.Lock()
+	r.store.queries++
+	ok := r.store.advisors[studentID] == lecturerID
+	hook := r.store.duringQuery
+	r.store.duringQuery = nil
+	r.store.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	return ok, nil
+}
+
+type fakeAdvisorStudentRepo struct {
+	StudentRepository
+	store *advisorStore
+}
+
+func (r *fakeAdvisorStudentRepo) UpdateAdvisor(studentID, advisorID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.advisors[studentID] = advisorID
+	return nil
+}
+
+type fakeCacheDelegationRepo struct {
+	DelegationRepository
+	delegations map[uuid.UUID]*model.VerificationDelegation
+}
+
+func (r *fakeCacheDelegationRepo) Create(d *model.VerificationDelegation) error {
+	d.ID = uuid.New()
+	r.delegations[d.ID] = d
+	return nil
+}
+
+func (r *fakeCacheDelegationRepo) FindByID(id uuid.UUID) (*model.VerificationDelegation, error) {
+	return r.delegations[id], nil
+}
+
+func (r *fakeCacheDelegationRepo) Revoke(uuid.UUID) error { return nil }
+
+func newCachedAdvisorRepos(t *testing.T) (*advisorStore, *AdvisorCache, LecturerRepository) {
+	t.Helper()
+	store := &advisorStore{advisors: map[uuid.UUID]uuid.UUID{}}
+	cache := NewAdvisorCache(func() time.Duration { return time.Hour })
+	return store, cache, NewCachedLecturerRepository(&fakeAdvisorLecturerRepo{store: store}, cache)
+}
+
+func mustAdvisor(t *testing.T, repo LecturerRepository, lecturerID, studentID uuid.UUID, want bool) {
+	t.Helper()
+	ok, err := repo.IsAdvisorOf(lecturerID, studentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok != want {
+		t.Fatalf("IsAdvisorOf(%s, %s) = %v, want %v", lecturerID, studentID, ok, want)
+	}
+}
+
+// TestAdvisorCacheUpdateAdvisorInvalidates: setelah dosen wali diganti, dosen lama langsung
+// kehilangan akses pada panggilan berikutnya (tanpa menunggu TTL).
+func TestAdvisorCacheUpdateAdvisorInvalidates(t *testing.T) {
+	store, cache, lecturers := newCachedAdvisorRepos(t)
+	students := NewCachedStudentRepository(&fakeAdvisorStudentRepo{store: store}, cache)
+	student, oldAdvisor, newAdvisor := uuid.New(), uuid.New(), uuid.New()
+	store.advisors[student] = oldAdvisor
+
+	mustAdvisor(t, lecturers, oldAdvisor, student, true)
+	mustAdvisor(t, lecturers, oldAdvisor, student, true)
+	if store.queries != 1 {
+		t.Fatalf("%d query IsAdvisorOf, want 1 (panggilan kedua dari cache)", store.queries)
+	}
+
+	if err := students.UpdateAdvisor(student, newAdvisor); err != nil {
+		t.Fatal(err)
+	}
+	mustAdvisor(t, lecturers, oldAdvisor, student, false)
+	mustAdvisor(t, lecturers, newAdvisor, student, true)
+}
+
+// TestAdvisorCacheGenGuard: hasil query yang dimulai sebelum invalidasi tidak disimpan,
+// sehingga nilai lama tidak menimpa invalidasi yang terjadi di tengah query.
+func TestAdvisorCacheGenGuard(t *testing.T) {
+	store, cache, lecturers := newCachedAdvisorRepos(t)
+	students := NewCachedStudentRepository(&fakeAdvisorStudentRepo{store: store}, cache)
+	student, oldAdvisor, newAdvisor := uuid.New(), uuid.New(), uuid.New()
+	store.advisors[student] = oldAdvisor
+
+	// Query pertama membaca "masih dosen wali", lalu dosen wali diganti sebelum query selesai.
+	store.duringQuery = func() {
+		if err := students.UpdateAdvisor(student, newAdvisor); err != nil {
+			t.Error(err)
+		}
+	}
+	mustAdvisor(t, lecturers, oldAdvisor, student, true) // hasil basi, tapi tidak boleh di-cache
+
+	mustAdvisor(t, lecturers, oldAdvisor, student, false)
+	if store.queries != 2 {
+		t.Fatalf("%d query IsAdvisorOf, want 2 (hasil basi tidak tersimpan)", store.queries)
+	}
+}
+
+// TestAdvisorCacheDelegationInvalidates: Create & Revoke delegasi menghapus entri kedua dosen.
+func TestAdvisorCacheDelegationInvalidates(t *testing.T) {
+	store, cache, lecturers := newCachedAdvisorRepos(t)
+	delegations := NewCachedDelegationRepository(&fakeCacheDelegationRepo{delegations: map[uuid.UUID]*model.VerificationDelegation{}}, cache)
+	from, to, other := uuid.New(), uuid.New(), uuid.New()
+	studentA, studentB, studentC := uuid.New(), uuid.New(), uuid.New()
+	store.advisors[studentA], store.advisors[studentB], store.advisors[studentC] = from, to, other
+
+	prime := func() {
+		mustAdvisor(t, lecturers, from, studentA, true)
+		mustAdvisor(t, lecturers, to, studentB, true)
+		mustAdvisor(t, lecturers, other, studentC, true)
+	}
+	prime()
+	queries := store.queries
+
+	d := &model.VerificationDelegation{FromLecturerID: from, ToLecturerID: to}
+	if err := delegations.Create(d); err != nil {
+		t.Fatal(err)
+	}
+	prime()
+	// from & to di-query ulang; dosen lain tetap dari cache.
+	if got := store.queries - queries; got != 2 {
+		t.Fatalf("%d query ulang setelah Create, want 2", got)
+	}
+
+	queries = store.queries
+	if err := delegations.Revoke(d.ID); err != nil {
+		t.Fatal(err)
+	}
+	prime()
+	if got := store.queries - queries; got != 2 {
+		t.Fatalf("%d query ulang setelah Revoke, want 2", got)
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyNotFound dikembalikan jika API key tidak ada atau sudah dicabut.
+var ErrAPIKeyNotFound = errors.New("api key not found or already revoked")
+
+// APIKeyRepository mengelola tabel api_keys.
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	// FindAll mengambil semua key (termasuk yang dicabut/kedaluwarsa), terbaru dulu.
+	FindAll() ([]model.APIKey, error)
+	// FindByPrefix mengambil key berdasarkan prefix (lookup saat autentikasi).
+	FindByPrefix(prefix string) (*model.APIKey, error)
+	// Revoke mengisi revoked_at; ErrAPIKeyNotFound jika tidak ada / sudah dicabut.
+	Revoke(id uuid.UUID, at time.Time) (*model.APIKey, error)
+	// TouchLastUsed mencatat pemakaian terakhir, paling sering 1x per interval minGap.
+	TouchLastUsed(id uuid.UUID, at time.Time, ip string, minGap time.Duration) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository membuat instance APIKeyRepository.
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindAll() ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Order("created_at DESC").Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) Revoke(id uuid.UUID, at time.Time) (*model.APIKey, error) {
+	res := r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	var key model.APIKey
+	if err := r.db.First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchLastUsed lihat dokumentasi di interface. Dibatasi minGap agar setiap request
+// integrasi tidak menjadi 1 UPDATE.
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time, ip string, minGap time.Duration) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-minGap)).
+		Updates(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+}
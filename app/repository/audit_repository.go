@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditRepository menyimpan catatan audit (tabel audit_logs).
+type AuditRepository interface {
+	// Record menyimpan satu entri audit. Payload di-encode menjadi JSON. Jika request
+	// memakai token impersonasi (utils.ImpersonatorID(ctx)), admin-nya ikut dicatat.
+	Record(ctx context.Context, actorID *uuid.UUID, action, targetType, targetID string, payload any) error
+	// FindByTargets mengambil entri audit untuk target tertentu (terbaru dulu), dibatasi limit.
+	FindByTargets(targetType string, targetIDs []string, actions []string, limit int) ([]model.AuditLog, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository membuat instance baru AuditRepository.
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+// Record menyimpan satu entri audit ke tabel audit_logs.
+func (r *auditRepository) Record(ctx context.Context, actorID *uuid.UUID, action, targetType, targetID string, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	entry := model.AuditLog{
+		ActorUserID: actorID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Payload:     string(raw),
+	}
+	if adminID, ok := utils.ImpersonatorID(ctx); ok {
+		entry.ImpersonatedBy = &adminID
+	}
+	return r.db.WithContext(ctx).Create(&entry).Error
+}
+
+// FindByTargets lihat dokumentasi di interface.
+func (r *auditRepository) FindByTargets(targetType string, targetIDs []string, actions []string, limit int) ([]model.AuditLog, error) {
+	var logs []model.AuditLog
+	if len(targetIDs) == 0 {
+		return logs, nil
+	}
+	q := r.db.
+		Where("target_type = ? AND target_id IN ?", targetType, targetIDs).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit)
+	if len(actions) > 0 {
+		q = q.Where("action IN ?", actions)
+	}
+	err := q.Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DelegationRepository menangani tabel verification_delegations
+// (pelimpahan sementara hak verifikasi dosen wali ke dosen lain).
+type DelegationRepository interface {
+	Create(d *model.VerificationDelegation) error
+	FindByID(id uuid.UUID) (*model.VerificationDelegation, error)
+	FindByLecturer(lecturerID uuid.UUID) ([]model.VerificationDelegation, error)
+	Revoke(id uuid.UUID) error
+
+	// CountConflicts menghitung delegasi aktif (belum dicabut) yang bentrok dengan rentang baru:
+	// - from sudah melimpahkan di rentang yang overlap
+	// - from sedang menjadi delegasi orang lain (tidak boleh delegasi berantai)
+	// - to sedang melimpahkan hak verifikasinya sendiri
+	CountConflicts(from, to uuid.UUID, startsAt, endsAt time.Time) (int64, error)
+
+	// FindActiveDelegatorIDs mengembalikan lecturers.id yang sedang melimpahkan ke toLecturerID pada waktu at.
+	FindActiveDelegatorIDs(toLecturerID uuid.UUID, at time.Time) ([]uuid.UUID, error)
+	// FindActiveDelegatorForStudent mengembalikan dosen wali studentID jika ia sedang
+	// melimpahkan ke toLecturerID pada waktu at (nil jika tidak ada).
+	FindActiveDelegatorForStudent(toLecturerID, studentID uuid.UUID, at time.Time) (*uuid.UUID, error)
+}
+
+type delegationRepository struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db}
+}
+
+// Create menyimpan delegasi baru.
+func (r *delegationRepository) Create(d *model.VerificationDelegation) error {
+	return r.db.Create(d).Error
+}
+
+// FindByID mengambil satu delegasi.
+func (r *delegationRepository) FindByID(id uuid.UUID) (*model.VerificationDelegation, error) {
+	var d model.VerificationDelegation
+	if err := r.db.First(&d, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// FindByLecturer mengambil semua delegasi di mana dosen menjadi pemberi atau penerima.
+func (r *delegationRepository) FindByLecturer(lecturerID uuid.UUID) ([]model.VerificationDelegation, error) {
+	var list []model.VerificationDelegation
+	err := r.db.
+		Where("from_lecturer_id = ? OR to_lecturer_id = ?", lecturerID, lecturerID).
+		Order("starts_at DESC").
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// Revoke mencabut delegasi (akses delegasi langsung berakhir).
+func (r *delegationRepository) Revoke(id uuid.UUID) error {
+	res := r.db.Model(&model.VerificationDelegation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("delegation not found or already revoked")
+	}
+	return nil
+}
+
+// CountConflicts lihat dokumentasi di interface.
+func (r *delegationRepository) CountConflicts(from, to uuid.UUID, startsAt, endsAt time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.VerificationDelegation{}).
+		Where("revoked_at IS NULL").
+		Where("starts_at < ? AND ends_at > ?", endsAt, startsAt).
+		Where("from_lecturer_id = ? OR to_lecturer_id = ? OR from_lecturer_id = ?", from, from, to).
+		Count(&count).Error
+	return count, err
+}
+
+// FindActiveDelegatorIDs lihat dokumentasi di interface.
+func (r *delegationRepository) FindActiveDelegatorIDs(toLecturerID uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&model.VerificationDelegation{}).
+		Where("to_lecturer_id = ? AND revoked_at IS NULL", toLecturerID).
+		Where("starts_at <= ? AND ends_at > ?", at, at).
+		Distinct().
+		Pluck("from_lecturer_id", &ids).Error
+	return ids, err
+}
+
+// FindActiveDelegatorForStudent lihat dokumentasi di interface.
+func (r *delegationRepository) FindActiveDelegatorForStudent(toLecturerID, studentID uuid.UUID, at time.Time) (*uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&model.VerificationDelegation{}).
+		Joins("JOIN students s ON s.advisor_id = verification_delegations.from_lecturer_id").
+		Where("s.id = ?", studentID).
+		Where("verification_delegations.to_lecturer_id = ? AND verification_delegations.revoked_at IS NULL", toLecturerID).
+		Where("verification_delegations.starts_at <= ? AND verification_delegations.ends_at > ?", at, at).
+		Limit(1).
+		Pluck("verification_delegations.from_lecturer_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return &ids[0], nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// TestDelegationActiveWindow: delegasi hanya aktif pada [startsAt, endsAt) dan selama belum dicabut,
+// baik untuk daftar pemberi delegasi maupun akses per mahasiswa.
+func TestDelegationActiveWindow(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := NewDelegationRepository(pgDB)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	to := createTestLecturer(t, pgDB)
+	type delegator struct {
+		name        string
+		starts, end time.Time
+		revoked     bool
+		wantActive  bool
+	}
+	cases := []delegator{
+		{name: "aktif", starts: now.Add(-time.Hour), end: now.Add(time.Hour), wantActive: true},
+		{name: "mulai tepat sekarang", starts: now, end: now.Add(time.Hour), wantActive: true},
+		{name: "kedaluwarsa", starts: now.Add(-48 * time.Hour), end: now.Add(-time.Hour)},
+		{name: "berakhir tepat sekarang", starts: now.Add(-time.Hour), end: now},
+		{name: "belum mulai", starts: now.Add(time.Hour), end: now.Add(48 * time.Hour)},
+		{name: "dicabut", starts: now.Add(-time.Hour), end: now.Add(time.Hour), revoked: true},
+	}
+
+	var wantIDs []uuid.UUID
+	for _, c := range cases {
+		from := createTestLecturer(t, pgDB)
+		student := createTestStudent(t, pgDB)
+		if err := pgDB.Model(&student).Update("advisor_id", from.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		d := &model.VerificationDelegation{FromLecturerID: from.ID, ToLecturerID: to.ID,
+			StartsAt: c.starts, EndsAt: c.end, CreatedBy: from.UserID}
+		if err := r.Create(d); err != nil {
+			t.Fatal(err)
+		}
+		if c.revoked {
+			if err := r.Revoke(d.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if c.wantActive {
+			wantIDs = append(wantIDs, from.ID)
+		}
+
+		got, err := r.FindActiveDelegatorForStudent(to.ID, student.ID, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got != nil) != c.wantActive || (got != nil && *got != from.ID) {
+			t.Errorf("%s: delegator mahasiswa %v, want aktif=%v", c.name, got, c.wantActive)
+		}
+	}
+
+	got, err := r.FindActiveDelegatorIDs(to.ID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(got, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	slices.SortFunc(wantIDs, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	if !slices.Equal(got, wantIDs) {
+		t.Fatalf("delegator aktif %v, want %v", got, wantIDs)
+	}
+}
+
+// TestDelegationConflicts: rentang yang overlap, delegasi berantai, dan penerima yang sedang
+// melimpahkan dianggap bentrok; rentang bersebelahan dan delegasi yang dicabut tidak.
+func TestDelegationConflicts(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := NewDelegationRepository(pgDB)
+	start := time.Now().UTC().Truncate(time.Second).Add(24 * time.Hour)
+	end := start.Add(7 * 24 * time.Hour)
+
+	a, b, c, d := createTestLecturer(t, pgDB), createTestLecturer(t, pgDB), createTestLecturer(t, pgDB), createTestLecturer(t, pgDB)
+	// a → b selama [start, end).
+	if err := r.Create(&model.VerificationDelegation{FromLecturerID: a.ID, ToLecturerID: b.ID,
+		StartsAt: start, EndsAt: end, CreatedBy: a.UserID}); err != nil {
+		t.Fatal(err)
+	}
+	revoked := &model.VerificationDelegation{FromLecturerID: c.ID, ToLecturerID: d.ID,
+		StartsAt: start, EndsAt: end, CreatedBy: c.UserID}
+	if err := r.Create(revoked); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	day := 24 * time.Hour
+	tests := []struct {
+		name       string
+		from, to   uuid.UUID
+		starts     time.Time
+		ends       time.Time
+		wantConfls int64
+	}{
+		{name: "overlap sebagian (pemberi sama)", from: a.ID, to: c.ID, starts: start.Add(3 * day), ends: end.Add(3 * day), wantConfls: 1},
+		{name: "rentang di dalam (pemberi sama)", from: a.ID, to: c.ID, starts: start.Add(day), ends: start.Add(2 * day), wantConfls: 1},
+		{name: "bersebelahan setelah berakhir", from: a.ID, to: c.ID, starts: end, ends: end.Add(day)},
+		{name: "bersebelahan sebelum mulai", from: a.ID, to: c.ID, starts: start.Add(-day), ends: start},
+		{name: "berantai: penerima melimpahkan lagi", from: b.ID, to: c.ID, starts: start, ends: end, wantConfls: 1},
+		{name: "penerima sedang melimpahkan", from: c.ID, to: a.ID, starts: start, ends: end, wantConfls: 1},
+		{name: "delegasi dicabut tidak dihitung", from: c.ID, to: d.ID, starts: start, ends: end},
+		{name: "dosen lain tanpa bentrok", from: d.ID, to: c.ID, starts: start, ends: end},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.CountConflicts(tt.from, tt.to, tt.starts, tt.ends)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantConfls {
+				t.Fatalf("%d bentrok, want %d", got, tt.wantConfls)
+			}
+		})
+	}
+}
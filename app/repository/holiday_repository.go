@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HolidayRepository menangani tabel holidays (kalender libur untuk hitungan hari kerja).
+type HolidayRepository interface {
+	// FindAll mengambil daftar libur; year nil → semua tahun.
+	FindAll(year *int) ([]model.Holiday, error)
+	Create(h *model.Holiday) error
+	// Upsert menyimpan banyak tanggal sekaligus (tanggal yang sudah ada diperbarui namanya).
+	Upsert(list []model.Holiday) error
+	Update(h *model.Holiday) error
+	Delete(id uuid.UUID) error
+	FindByID(id uuid.UUID) (*model.Holiday, error)
+	// DateSet mengembalikan semua tanggal libur sebagai set berkunci "YYYY-MM-DD".
+	DateSet() (map[string]bool, error)
+}
+
+type holidayRepository struct {
+	db *gorm.DB
+}
+
+func NewHolidayRepository(db *gorm.DB) HolidayRepository {
+	return &holidayRepository{db}
+}
+
+func (r *holidayRepository) FindAll(year *int) ([]model.Holiday, error) {
+	var list []model.Holiday
+	q := r.db.Order("date ASC")
+	if year != nil {
+		start := time.Date(*year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		q = q.Where("date >= ? AND date < ?", start, start.AddDate(1, 0, 0))
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+func (r *holidayRepository) Create(h *model.Holiday) error {
+	return r.db.Create(h).Error
+}
+
+func (r *holidayRepository) Upsert(list []model.Holiday) error {
+	if len(list) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(&list).Error
+}
+
+func (r *holidayRepository) Update(h *model.Holiday) error {
+	return r.db.Model(&model.Holiday{}).
+		Where("id = ?", h.ID).
+		Updates(map[string]interface{}{"date": h.Date, "name": h.Name}).Error
+}
+
+func (r *holidayRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.Holiday{}, "id = ?", id).Error
+}
+
+func (r *holidayRepository) FindByID(id uuid.UUID) (*model.Holiday, error) {
+	var h model.Holiday
+	if err := r.db.First(&h, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r *holidayRepository) DateSet() (map[string]bool, error) {
+	var dates []time.Time
+	if err := r.db.Model(&model.Holiday{}).Pluck("date", &dates).Error; err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		set[d.UTC().Format(utils.HolidayDateFormat)] = true
+	}
+	return set, nil
+}
+
+// cachedHolidayRepository menyimpan DateSet di memori; setiap penulisan mengosongkan cache.
+type cachedHolidayRepository struct {
+	HolidayRepository
+	mu  sync.RWMutex
+	set map[string]bool
+}
+
+// NewCachedHolidayRepository membungkus HolidayRepository dengan cache in-memory untuk DateSet.
+func NewCachedHolidayRepository(inner HolidayRepository) HolidayRepository {
+	return &cachedHolidayRepository{HolidayRepository: inner}
+}
+
+func (r *cachedHolidayRepository) DateSet() (map[string]bool, error) {
+	r.mu.RLock()
+	set := r.set
+	r.mu.RUnlock()
+	if set != nil {
+		return set, nil
+	}
+
+	set, err := r.HolidayRepository.DateSet()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.set = set
+	r.mu.Unlock()
+	return set, nil
+}
+
+func (r *cachedHolidayRepository) invalidate() {
+	r.mu.Lock()
+	r.set = nil
+	r.mu.Unlock()
+}
+
+func (r *cachedHolidayRepository) Create(h *model.Holiday) error {
+	defer r.invalidate()
+	return r.HolidayRepository.Create(h)
+}
+
+func (r *cachedHolidayRepository) Upsert(list []model.Holiday) error {
+	defer r.invalidate()
+	return r.HolidayRepository.Upsert(list)
+}
+
+func (r *cachedHolidayRepository) Update(h *model.Holiday) error {
+	defer r.invalidate()
+	return r.HolidayRepository.Update(h)
+}
+
+func (r *cachedHolidayRepository) Delete(id uuid.UUID) error {
+	defer r.invalidate()
+	return r.HolidayRepository.Delete(id)
+}
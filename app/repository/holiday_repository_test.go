@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+)
+
+// fakeHolidayStore adalah HolidayRepository di memori yang menghitung query DateSet.
+type fakeHolidayStore struct {
+	HolidayRepository
+	dates   []time.Time
+	queries int
+}
+
+func (s *fakeHolidayStore) Create(h *model.Holiday) error {
+	s.dates = append(s.dates, h.Date)
+	return nil
+}
+
+func (s *fakeHolidayStore) DateSet() (map[string]bool, error) {
+	s.queries++
+	set := map[string]bool{}
+	for _, d := range s.dates {
+		set[d.UTC().Format(utils.HolidayDateFormat)] = true
+	}
+	return set, nil
+}
+
+// TestCachedHolidayDateSet: libur yang ditambahkan admin langsung ikut mengurangi hari kerja
+// (cache DateSet dikosongkan saat penulisan), sedangkan selisih kalender tidak berubah.
+func TestCachedHolidayDateSet(t *testing.T) {
+	store := &fakeHolidayStore{}
+	repo := NewCachedHolidayRepository(store)
+	// Jumat 14 Agustus 2026 → Selasa 18 Agustus 2026.
+	submitted := time.Date(2026, time.August, 14, 9, 0, 0, 0, time.UTC)
+	verified := time.Date(2026, time.August, 18, 9, 0, 0, 0, time.UTC)
+
+	metrics := func() (int, int) {
+		t.Helper()
+		set, err := repo.DateSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return utils.CalendarDaysBetween(submitted, verified), utils.WorkingDaysBetween(submitted, verified, set)
+	}
+
+	if cal, work := metrics(); cal != 4 || work != 2 {
+		t.Fatalf("tanpa libur: kalender %d hari kerja %d, want 4 dan 2", cal, work)
+	}
+	metrics()
+	if store.queries != 1 {
+		t.Fatalf("DateSet di-query %d kali, want 1 (cache)", store.queries)
+	}
+
+	if err := repo.Create(&model.Holiday{Date: time.Date(2026, time.August, 17, 0, 0, 0, 0, time.UTC), Name: "HUT RI"}); err != nil {
+		t.Fatal(err)
+	}
+	if cal, work := metrics(); cal != 4 || work != 1 {
+		t.Fatalf("dengan libur Senin: kalender %d hari kerja %d, want 4 dan 1", cal, work)
+	}
+	if store.queries != 2 {
+		t.Fatalf("DateSet di-query %d kali, want 2 (cache dikosongkan setelah Create)", store.queries)
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestPostgres membuka koneksi ke TEST_POSTGRES_DSN; test di-skip jika env tidak di-set.
+func openTestPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN tidak di-set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("koneksi postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// openTestMongo membuka database Mongo sementara di TEST_MONGO_URI (di-drop setelah test);
+// test di-skip jika env tidak di-set.
+func openTestMongo(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI tidak di-set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("koneksi mongo: %v", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("ping mongo: %v", err)
+	}
+	db := client.Database("sab_test_" + uuid.NewString()[:8])
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	return db
+}
+
+// createTestStudent membuat user mahasiswa + profil students (dihapus beserta prestasinya setelah test).
+func createTestStudent(t *testing.T, db *gorm.DB) model.Student {
+	t.Helper()
+	role := model.Role{Name: "mahasiswa"}
+	if err := db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+		t.Fatal(err)
+	}
+	suffix := uuid.NewString()[:8]
+	user := model.User{Username: "mhs" + suffix, Email: "mhs" + suffix + "@kampus.ac.id", PasswordHash: "x",
+		FullName: "Mahasiswa " + suffix, RoleID: role.ID, IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	student := model.Student{UserID: user.ID, StudentID: "T" + suffix}
+	if err := db.Create(&student).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		refs := db.Model(&model.AchievementReference{}).Select("id").Where("student_id = ?", student.ID)
+		db.Where("achievement_reference_id IN (?)", refs).Delete(&model.AchievementStatusLog{})
+		db.Where("aggregate_id IN (?)", refs).Delete(&model.OutboxEvent{})
+		db.Delete(&model.AchievementReference{}, "student_id = ?", student.ID)
+		db.Delete(&model.Student{}, "id = ?", student.ID)
+		db.Delete(&model.User{}, "id = ?", user.ID)
+	})
+	return student
+}
+
+// createTestLecturer membuat user dosen wali + profil lecturers (dihapus beserta delegasinya setelah test).
+func createTestLecturer(t *testing.T, db *gorm.DB) model.Lecturer {
+	t.Helper()
+	role := model.Role{Name: "dosen_wali"}
+	if err := db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+		t.Fatal(err)
+	}
+	suffix := uuid.NewString()[:8]
+	user := model.User{Username: "dsn" + suffix, Email: "dsn" + suffix + "@kampus.ac.id", PasswordHash: "x",
+		FullName: "Dosen " + suffix, RoleID: role.ID, IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	lecturer := model.Lecturer{UserID: user.ID, LecturerID: "L" + suffix}
+	if err := db.Create(&lecturer).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&model.VerificationDelegation{}, "from_lecturer_id = ? OR to_lecturer_id = ?", lecturer.ID, lecturer.ID)
+		db.Model(&model.Student{}).Where("advisor_id = ?", lecturer.ID).Update("advisor_id", nil)
+		db.Delete(&model.Lecturer{}, "id = ?", lecturer.ID)
+		db.Delete(&model.User{}, "id = ?", user.ID)
+	})
+	return lecturer
+}
@@ -21,9 +21,37 @@ type LecturerRepository interface {
 
 	// Untuk kebutuhan RBAC & achievement
 	FindByUserID(userID uuid.UUID) (*model.Lecturer, error)
-	GetAdviseeStudentIDs(lecturerID uuid.UUID) ([]uuid.UUID, error)
+	// FindByCode mencari dosen berdasarkan kode/NIP (lecturers.lecturer_id).
+	FindByCode(code string) (*model.Lecturer, error)
+	GetAdviseeStudentIDs(ctx context.Context, lecturerID uuid.UUID) ([]uuid.UUID, error)
 	IsAdvisorOf(lecturerID uuid.UUID, studentID uuid.UUID) (bool, error)
-	FindAchievementsByStudentIDs(ctx context.Context, studentIDs []uuid.UUID) ([]model.AchievementReference, error)
+	FindAchievementsByStudentIDs(ctx context.Context, filter AdviseeAchievementFilter) ([]model.AchievementReference, int64, error)
+
+	// FindAdviseeContacts: NIM, nama & email mahasiswa bimbingan (untuk file export).
+	FindAdviseeContacts(lecturerID uuid.UUID) ([]AdviseeContact, error)
+}
+
+// AdviseeAchievementFilter adalah filter daftar prestasi mahasiswa bimbingan.
+// StudentIDs wajib (sudah dibatasi ke mahasiswa yang boleh diakses pemanggil);
+// Page/Limit mengikuti NormalizePagination.
+type AdviseeAchievementFilter struct {
+	StudentIDs []uuid.UUID
+	Status     *string
+	MongoIDs   []string // filter isi (lihat AchievementListFilter.MongoIDs)
+	Sort       AchievementSort
+	Unpaged    bool // lihat AchievementListFilter.Unpaged
+	Page       int
+	Limit      int
+}
+
+// AdviseeContact adalah 1 baris data mahasiswa bimbingan untuk export.
+type AdviseeContact struct {
+	StudentID    uuid.UUID
+	NIM          string
+	FullName     string
+	Email        string
+	ProgramStudy string
+	AcademicYear string
 }
 
 type lecturerRepository struct {
@@ -74,10 +102,20 @@ func (r *lecturerRepository) FindByUserID(userID uuid.UUID) (*model.Lecturer, er
 	return &lect, nil
 }
 
+// FindByCode lihat dokumentasi di interface.
+func (r *lecturerRepository) FindByCode(code string) (*model.Lecturer, error) {
+	var lect model.Lecturer
+	err := r.db.First(&lect, "lecturer_id = ?", code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lect, nil
+}
+
 // GetAdviseeStudentIDs mengembalikan daftar ID mahasiswa bimbingan dosen wali.
-func (r *lecturerRepository) GetAdviseeStudentIDs(lecturerID uuid.UUID) ([]uuid.UUID, error) {
+func (r *lecturerRepository) GetAdviseeStudentIDs(ctx context.Context, lecturerID uuid.UUID) ([]uuid.UUID, error) {
 	var students []model.Student
-	err := r.db.
+	err := r.db.WithContext(ctx).
 		Select("id").
 		Where("advisor_id = ?", lecturerID).
 		Find(&students).Error
@@ -101,23 +139,39 @@ func (r *lecturerRepository) IsAdvisorOf(lecturerID uuid.UUID, studentID uuid.UU
 	return count > 0, err
 }
 
-// FindAchievementsByStudentIDs mengambil semua achievement_references
-// untuk daftar mahasiswa tertentu (digunakan dosen wali untuk lihat prestasi bimbingan).
+// FindAchievementsByStudentIDs mengambil achievement_references untuk daftar mahasiswa
+// tertentu (digunakan dosen wali untuk lihat prestasi bimbingan), dengan filter status
+// dan pagination. Urutan default: created_at DESC, id DESC (lihat findAchievementPage).
 func (r *lecturerRepository) FindAchievementsByStudentIDs(
-	_ context.Context,
-	studentIDs []uuid.UUID,
-) ([]model.AchievementReference, error) {
+	ctx context.Context,
+	filter AdviseeAchievementFilter,
+) ([]model.AchievementReference, int64, error) {
 
-	if len(studentIDs) == 0 {
-		return []model.AchievementReference{}, nil
+	if len(filter.StudentIDs) == 0 {
+		return []model.AchievementReference{}, 0, nil
 	}
+	page, limit := NormalizePagination(filter.Page, filter.Limit)
 
-	var refs []model.AchievementReference
-	err := r.db.
-		Where("student_id IN ?", studentIDs).
-		Where("status != ?", "deleted").
-		Order("created_at DESC").
-		Find(&refs).Error
+	db := r.db.WithContext(ctx).Model(&model.AchievementReference{}).
+		Where("student_id IN ?", filter.StudentIDs).
+		Where("status != ?", "deleted")
+	if filter.Status != nil && *filter.Status != "" {
+		db = db.Where("status = ?", *filter.Status)
+	}
+	db = whereMongoIDs(db, filter.MongoIDs)
+
+	return findAchievementPage(db, filter.Sort, filter.Unpaged, page, limit)
+}
 
-	return refs, err
+// FindAdviseeContacts mengambil data kontak mahasiswa bimbingan (join students + users).
+func (r *lecturerRepository) FindAdviseeContacts(lecturerID uuid.UUID) ([]AdviseeContact, error) {
+	var rows []AdviseeContact
+	err := r.db.
+		Table("students AS s").
+		Select("s.id AS student_id, s.student_id AS nim, u.full_name, u.email, s.program_study, s.academic_year").
+		Joins("JOIN users u ON u.id = s.user_id").
+		Where("s.advisor_id = ?", lecturerID).
+		Order("s.student_id ASC").
+		Scan(&rows).Error
+	return rows, err
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginEventRepository mengelola tabel login_events (riwayat percobaan login).
+type LoginEventRepository interface {
+	Create(event *model.LoginEvent) error
+	// FindByUser mengembalikan riwayat login user (terbaru dulu) beserta total baris.
+	FindByUser(userID uuid.UUID, page, limit int) ([]model.LoginEvent, int64, error)
+}
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginEventRepository membuat instance LoginEventRepository.
+func NewLoginEventRepository(db *gorm.DB) LoginEventRepository {
+	return &loginEventRepository{db}
+}
+
+func (r *loginEventRepository) Create(event *model.LoginEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *loginEventRepository) FindByUser(userID uuid.UUID, page, limit int) ([]model.LoginEvent, int64, error) {
+	q := r.db.Model(&model.LoginEvent{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := []model.LoginEvent{}
+	err := q.Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&events).Error
+	return events, total, err
+}
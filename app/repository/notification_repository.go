@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository mengelola tabel pending_notifications (antrean persisten notifikasi).
+type NotificationRepository interface {
+	// Save membuat atau memperbarui baris pending (ID kosong → baris baru).
+	// Baris baru dengan IdempotencyKey yang sudah ada diabaikan (tidak duplikat).
+	Save(n *model.PendingNotification) error
+	// Delete menghapus baris yang sudah berhasil dikirim.
+	Delete(id uuid.UUID) error
+	// LeaseDue mengambil maksimal limit baris yang jatuh tempo dan menunda next_attempt_at
+	// sebesar lease, sehingga baris tidak diambil dua kali selama sedang diproses.
+	LeaseDue(now time.Time, limit int, lease time.Duration) ([]model.PendingNotification, error)
+	// CountPending menghitung baris yang masih menunggu (belum failed) dan yang failed.
+	CountPending() (pending int64, failed int64, err error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository membuat instance NotificationRepository.
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+// Save lihat dokumentasi di interface.
+func (r *notificationRepository) Save(n *model.PendingNotification) error {
+	if n.ID == uuid.Nil {
+		return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(n).Error
+	}
+	return r.db.Save(n).Error
+}
+
+// Delete lihat dokumentasi di interface.
+func (r *notificationRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.PendingNotification{}, "id = ?", id).Error
+}
+
+// LeaseDue lihat dokumentasi di interface.
+// FOR UPDATE SKIP LOCKED membuat beberapa instance aplikasi aman men-drain tabel yang sama.
+func (r *notificationRepository) LeaseDue(now time.Time, limit int, lease time.Duration) ([]model.PendingNotification, error) {
+	var rows []model.PendingNotification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("failed = ? AND next_attempt_at <= ?", false, now).
+			Order("next_attempt_at ASC").
+			Order("id ASC").
+			Limit(limit).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		return tx.Model(&model.PendingNotification{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return rows, err
+}
+
+// CountPending lihat dokumentasi di interface.
+func (r *notificationRepository) CountPending() (int64, int64, error) {
+	var pending, failed int64
+	if err := r.db.Model(&model.PendingNotification{}).Where("failed = ?", false).Count(&pending).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Model(&model.PendingNotification{}).Where("failed = ?", true).Count(&failed).Error; err != nil {
+		return 0, 0, err
+	}
+	return pending, failed, nil
+}
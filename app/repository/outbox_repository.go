@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOutboxEventNotFound dikembalikan jika event tidak ada atau sudah terkirim.
+var ErrOutboxEventNotFound = errors.New("outbox event not found or already dispatched")
+
+// AchievementStatusEvent adalah payload event outbox perubahan status prestasi.
+// Catatan privat verifier (internalNote) sengaja TIDAK ikut, karena payload dikirim
+// ke notifikasi & webhook.
+type AchievementStatusEvent struct {
+	AchievementID     uuid.UUID  `json:"achievementId"`
+	StudentID         uuid.UUID  `json:"studentId"`
+	AdvisorID         *uuid.UUID `json:"advisorId,omitempty"` // lecturers.id dosen wali mahasiswa
+	Status            string     `json:"status"`
+	VerifiedBy        *string    `json:"verifiedBy,omitempty"`
+	RejectionNote     *string    `json:"rejectionNote,omitempty"`
+	RejectionCategory *string    `json:"rejectionCategory,omitempty"`
+	Imported          bool       `json:"imported,omitempty"` // keputusan historis dari impor admin
+	Expired           bool       `json:"expired,omitempty"`  // dikembalikan otomatis karena melewati batas verifikasi
+	At                time.Time  `json:"at"`
+}
+
+// OutboxRepository mengelola tabel outbox_events.
+type OutboxRepository interface {
+	// LeaseDue mengambil maksimal limit event yang belum terkirim & jatuh tempo, lalu menunda
+	// next_attempt_at sebesar lease agar tidak diambil instance lain selama diproses.
+	LeaseDue(now time.Time, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	// FindDeliveries mengembalikan nama publisher yang sudah berhasil menerima tiap event.
+	FindDeliveries(eventIDs []uuid.UUID) (map[uuid.UUID]map[string]bool, error)
+	// MarkDelivered mencatat bahwa publisher sudah berhasil menerima event (idempoten).
+	MarkDelivered(eventID uuid.UUID, publisher string, at time.Time) error
+	// MarkDispatched mengisi dispatched_at (event tidak akan dikirim lagi).
+	MarkDispatched(id uuid.UUID, at time.Time) error
+	// MarkAttemptFailed mencatat kegagalan kirim & jadwal retry berikutnya (failed=true → berhenti).
+	MarkAttemptFailed(id uuid.UUID, attempts int, lastError string, next time.Time, failed bool) error
+	// FindUndispatched mengambil event yang belum terkirim (onlyFailed → hanya yang berhenti retry).
+	FindUndispatched(onlyFailed bool, limit int) ([]model.OutboxEvent, error)
+	// Retry menjadwalkan ulang event yang belum terkirim untuk segera dikirim (attempts direset).
+	Retry(id uuid.UUID) (*model.OutboxEvent, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository membuat instance OutboxRepository.
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+// insertOutboxEvent menulis event di dalam transaksi tx (dipakai repository lain,
+// mis. achievementRepository.UpdateStatus), sehingga event ikut commit/rollback.
+func insertOutboxEvent(tx *gorm.DB, eventType string, aggregateID uuid.UUID, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.OutboxEvent{
+		EventType:     eventType,
+		AggregateID:   aggregateID,
+		Payload:       string(raw),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// LeaseDue lihat dokumentasi di interface.
+// FOR UPDATE SKIP LOCKED membuat beberapa instance aplikasi aman memproses tabel yang sama.
+func (r *outboxRepository) LeaseDue(now time.Time, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	var rows []model.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL AND failed = ? AND next_attempt_at <= ?", false, now).
+			Order("created_at ASC").
+			Order("id ASC").
+			Limit(limit).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		return tx.Model(&model.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return rows, err
+}
+
+// FindDeliveries lihat dokumentasi di interface.
+func (r *outboxRepository) FindDeliveries(eventIDs []uuid.UUID) (map[uuid.UUID]map[string]bool, error) {
+	out := make(map[uuid.UUID]map[string]bool, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return out, nil
+	}
+	var rows []model.OutboxDelivery
+	if err := r.db.Where("event_id IN ?", eventIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if out[row.EventID] == nil {
+			out[row.EventID] = map[string]bool{}
+		}
+		out[row.EventID][row.Publisher] = true
+	}
+	return out, nil
+}
+
+// MarkDelivered lihat dokumentasi di interface.
+func (r *outboxRepository) MarkDelivered(eventID uuid.UUID, publisher string, at time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.OutboxDelivery{
+		EventID:     eventID,
+		Publisher:   publisher,
+		DeliveredAt: at,
+	}).Error
+}
+
+// MarkDispatched lihat dokumentasi di interface.
+func (r *outboxRepository) MarkDispatched(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.OutboxEvent{}).
+		Where("id = ? AND dispatched_at IS NULL", id).
+		Update("dispatched_at", at).Error
+}
+
+// MarkAttemptFailed lihat dokumentasi di interface.
+func (r *outboxRepository) MarkAttemptFailed(id uuid.UUID, attempts int, lastError string, next time.Time, failed bool) error {
+	return r.db.Model(&model.OutboxEvent{}).
+		Where("id = ? AND dispatched_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": next,
+			"failed":          failed,
+		}).Error
+}
+
+// FindUndispatched lihat dokumentasi di interface.
+func (r *outboxRepository) FindUndispatched(onlyFailed bool, limit int) ([]model.OutboxEvent, error) {
+	var rows []model.OutboxEvent
+	q := r.db.Where("dispatched_at IS NULL")
+	if onlyFailed {
+		q = q.Where("failed = ?", true)
+	}
+	err := q.Order("created_at ASC").Order("id ASC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// Retry lihat dokumentasi di interface.
+func (r *outboxRepository) Retry(id uuid.UUID) (*model.OutboxEvent, error) {
+	res := r.db.Model(&model.OutboxEvent{}).
+		Where("id = ? AND dispatched_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"attempts":        0,
+			"failed":          false,
+			"next_attempt_at": time.Now(),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrOutboxEventNotFound
+	}
+	var ev model.OutboxEvent
+	if err := r.db.First(&ev, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &ev, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PointRuleRepository menangani tabel point_rules (rubrik perhitungan poin prestasi).
+type PointRuleRepository interface {
+	// FindAll mengambil aturan poin; achievementType kosong → semua tipe.
+	FindAll(achievementType string) ([]model.PointRule, error)
+	FindByID(id uuid.UUID) (*model.PointRule, error)
+	Create(rule *model.PointRule) error
+	Update(rule *model.PointRule) error
+	Delete(id uuid.UUID) error
+}
+
+type pointRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewPointRuleRepository(db *gorm.DB) PointRuleRepository {
+	return &pointRuleRepository{db}
+}
+
+func (r *pointRuleRepository) FindAll(achievementType string) ([]model.PointRule, error) {
+	var list []model.PointRule
+	q := r.db.Order("achievement_type ASC").
+		Order("competition_level ASC NULLS LAST").
+		Order("rank ASC NULLS LAST").
+		Order("points DESC")
+	if achievementType != "" {
+		q = q.Where("achievement_type = ?", achievementType)
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+func (r *pointRuleRepository) FindByID(id uuid.UUID) (*model.PointRule, error) {
+	var rule model.PointRule
+	if err := r.db.First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *pointRuleRepository) Create(rule *model.PointRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *pointRuleRepository) Update(rule *model.PointRule) error {
+	// map agar kolom opsional bisa dikosongkan kembali (NULL)
+	return r.db.Model(&model.PointRule{}).
+		Where("id = ?", rule.ID).
+		Updates(map[string]interface{}{
+			"achievement_type":  rule.AchievementType,
+			"competition_level": rule.CompetitionLevel,
+			"rank":              rule.Rank,
+			"medal_type":        rule.MedalType,
+			"points":            rule.Points,
+		}).Error
+}
+
+func (r *pointRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.PointRule{}, "id = ?", id).Error
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+
+	"student-achievement-backend/app/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrRolesInUse dikembalikan jika import akan menghapus role yang masih dipakai user.
+var ErrRolesInUse = errors.New("roles still assigned to users")
+
+// RBACPermission adalah 1 permission di dokumen export/import RBAC.
+type RBACPermission struct {
+	Name        string `json:"name"`
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// RBACRole adalah 1 role beserta nama-nama permission yang dimilikinya.
+type RBACRole struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RBACDocument adalah dokumen lengkap konfigurasi RBAC (hasil export / input import).
+type RBACDocument struct {
+	Version     int              `json:"version"`
+	Permissions []RBACPermission `json:"permissions"`
+	Roles       []RBACRole       `json:"roles"`
+}
+
+// RBACAssignment adalah pasangan role → permission di diff.
+type RBACAssignment struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+}
+
+// RBACDiff merangkum perubahan yang (akan) diterapkan oleh import.
+type RBACDiff struct {
+	PermissionsAdded   []string         `json:"permissionsAdded"`
+	PermissionsUpdated []string         `json:"permissionsUpdated"`
+	PermissionsRemoved []string         `json:"permissionsRemoved"`
+	RolesAdded         []string         `json:"rolesAdded"`
+	RolesUpdated       []string         `json:"rolesUpdated"`
+	RolesRemoved       []string         `json:"rolesRemoved"`
+	RolesKeptInUse     []string         `json:"rolesKeptInUse"` // tidak ada di dokumen tapi masih punya user
+	AssignmentsAdded   []RBACAssignment `json:"assignmentsAdded"`
+	AssignmentsRemoved []RBACAssignment `json:"assignmentsRemoved"`
+}
+
+// Empty true jika import tidak mengubah apa pun.
+func (d *RBACDiff) Empty() bool {
+	return len(d.PermissionsAdded)+len(d.PermissionsUpdated)+len(d.PermissionsRemoved)+
+		len(d.RolesAdded)+len(d.RolesUpdated)+len(d.RolesRemoved)+
+		len(d.AssignmentsAdded)+len(d.AssignmentsRemoved) == 0
+}
+
+// RBACImportOptions mengatur perilaku import.
+type RBACImportOptions struct {
+	DryRun bool // hanya hitung diff, tidak ada perubahan yang di-commit
+	// SkipRolesInUse: role yang tidak ada di dokumen tetapi masih punya user dibiarkan
+	// (dilaporkan di RolesKeptInUse). Tanpa flag ini import ditolak dengan ErrRolesInUse.
+	SkipRolesInUse bool
+}
+
+// RBACRepository menangani export/import matriks role-permission.
+type RBACRepository interface {
+	Export() (*RBACDocument, error)
+	// Import menerapkan dokumen secara transaksional dan mengembalikan diff-nya.
+	// Dokumen diasumsikan sudah divalidasi (lihat service).
+	Import(doc *RBACDocument, opts RBACImportOptions) (*RBACDiff, error)
+}
+
+type rbacRepository struct {
+	db *gorm.DB
+}
+
+func NewRBACRepository(db *gorm.DB) RBACRepository {
+	return &rbacRepository{db}
+}
+
+// errDryRun dipakai untuk membatalkan transaksi pada mode dry-run.
+var errDryRun = errors.New("dry run")
+
+// Export mengambil seluruh role, permission, dan assignment-nya (urut nama agar stabil).
+func (r *rbacRepository) Export() (*RBACDocument, error) {
+	var perms []model.Permission
+	if err := r.db.Order("name ASC").Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	var roles []model.Role
+	if err := r.db.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	doc := &RBACDocument{Version: 1, Permissions: []RBACPermission{}, Roles: []RBACRole{}}
+	for _, p := range perms {
+		doc.Permissions = append(doc.Permissions, RBACPermission{
+			Name:        p.Name,
+			Resource:    p.Resource,
+			Action:      p.Action,
+			Description: p.Description,
+		})
+	}
+	for _, role := range roles {
+		names := make([]string, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		doc.Roles = append(doc.Roles, RBACRole{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: names,
+		})
+	}
+	return doc, nil
+}
+
+// Import lihat dokumentasi di interface.
+func (r *rbacRepository) Import(doc *RBACDocument, opts RBACImportOptions) (*RBACDiff, error) {
+	diff := &RBACDiff{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var curPerms []model.Permission
+		if err := tx.Find(&curPerms).Error; err != nil {
+			return err
+		}
+		var curRoles []model.Role
+		if err := tx.Preload("Permissions").Find(&curRoles).Error; err != nil {
+			return err
+		}
+
+		permByName := map[string]*model.Permission{}
+		for i := range curPerms {
+			permByName[curPerms[i].Name] = &curPerms[i]
+		}
+		roleByName := map[string]*model.Role{}
+		for i := range curRoles {
+			roleByName[curRoles[i].Name] = &curRoles[i]
+		}
+
+		// ---- Permissions: tambah / update ----
+		wantPerm := map[string]bool{}
+		for _, p := range doc.Permissions {
+			wantPerm[p.Name] = true
+			cur, ok := permByName[p.Name]
+			if !ok {
+				diff.PermissionsAdded = append(diff.PermissionsAdded, p.Name)
+				np := model.Permission{Name: p.Name, Resource: p.Resource, Action: p.Action, Description: p.Description}
+				if err := tx.Create(&np).Error; err != nil {
+					return err
+				}
+				permByName[p.Name] = &np
+				continue
+			}
+			if cur.Resource != p.Resource || cur.Action != p.Action || cur.Description != p.Description {
+				diff.PermissionsUpdated = append(diff.PermissionsUpdated, p.Name)
+				if err := tx.Model(cur).Updates(map[string]interface{}{
+					"resource":    p.Resource,
+					"action":      p.Action,
+					"description": p.Description,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// ---- Roles: tambah / update + assignment ----
+		wantRole := map[string]bool{}
+		for _, rr := range doc.Roles {
+			wantRole[rr.Name] = true
+
+			role, ok := roleByName[rr.Name]
+			curAssigned := map[string]bool{}
+			if !ok {
+				diff.RolesAdded = append(diff.RolesAdded, rr.Name)
+				role = &model.Role{Name: rr.Name, Description: rr.Description}
+				if err := tx.Omit("Permissions", "Users").Create(role).Error; err != nil {
+					return err
+				}
+			} else {
+				for _, p := range role.Permissions {
+					curAssigned[p.Name] = true
+				}
+				if role.Description != rr.Description {
+					diff.RolesUpdated = append(diff.RolesUpdated, rr.Name)
+					if err := tx.Model(role).Update("description", rr.Description).Error; err != nil {
+						return err
+					}
+				}
+			}
+
+			wantAssigned := map[string]bool{}
+			perms := make([]model.Permission, 0, len(rr.Permissions))
+			for _, name := range rr.Permissions {
+				wantAssigned[name] = true
+				perms = append(perms, *permByName[name])
+				if !curAssigned[name] {
+					diff.AssignmentsAdded = append(diff.AssignmentsAdded, RBACAssignment{rr.Name, name})
+				}
+			}
+			for name := range curAssigned {
+				if !wantAssigned[name] {
+					diff.AssignmentsRemoved = append(diff.AssignmentsRemoved, RBACAssignment{rr.Name, name})
+				}
+			}
+
+			if err := tx.Model(role).Association("Permissions").Replace(perms); err != nil {
+				return err
+			}
+		}
+
+		// ---- Roles yang tidak ada di dokumen ----
+		var blocked []string
+		for name, role := range roleByName {
+			if wantRole[name] {
+				continue
+			}
+			var users int64
+			if err := tx.Model(&model.User{}).Where("role_id = ?", role.ID).Count(&users).Error; err != nil {
+				return err
+			}
+			if users > 0 {
+				if !opts.SkipRolesInUse {
+					blocked = append(blocked, name)
+				}
+				diff.RolesKeptInUse = append(diff.RolesKeptInUse, name)
+				continue
+			}
+			diff.RolesRemoved = append(diff.RolesRemoved, name)
+			for _, p := range role.Permissions {
+				diff.AssignmentsRemoved = append(diff.AssignmentsRemoved, RBACAssignment{name, p.Name})
+			}
+			if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", role.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&model.Role{}, "id = ?", role.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(blocked) > 0 {
+			sort.Strings(blocked)
+			diff.RolesKeptInUse = blocked
+			return ErrRolesInUse
+		}
+
+		// ---- Permissions yang tidak ada di dokumen ----
+		for name, p := range permByName {
+			if wantPerm[name] {
+				continue
+			}
+			diff.PermissionsRemoved = append(diff.PermissionsRemoved, name)
+			if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", p.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&model.Permission{}, "id = ?", p.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// Permission role berubah → token user role tsb membawa permission lama.
+		changedRoles := map[string]bool{}
+		for _, a := range append(append([]RBACAssignment{}, diff.AssignmentsAdded...), diff.AssignmentsRemoved...) {
+			if role, ok := roleByName[a.Role]; ok && wantRole[a.Role] {
+				changedRoles[role.Name] = true
+			}
+		}
+		for name := range changedRoles {
+			if err := tx.Model(&model.User{}).Where("role_id = ?", roleByName[name].ID).
+				Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+
+	diff.sort()
+	if errors.Is(err, errDryRun) {
+		return diff, nil
+	}
+	if err != nil {
+		return diff, err
+	}
+	return diff, nil
+}
+
+// sort mengurutkan isi diff agar output stabil.
+func (d *RBACDiff) sort() {
+	for _, list := range [][]string{
+		d.PermissionsAdded, d.PermissionsUpdated, d.PermissionsRemoved,
+		d.RolesAdded, d.RolesUpdated, d.RolesRemoved, d.RolesKeptInUse,
+	} {
+		sort.Strings(list)
+	}
+	for _, list := range [][]RBACAssignment{d.AssignmentsAdded, d.AssignmentsRemoved} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Role != list[j].Role {
+				return list[i].Role < list[j].Role
+			}
+			return list[i].Permission < list[j].Permission
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// normalizeRBAC mengurutkan dokumen seperti Export agar bisa dibandingkan langsung.
+func normalizeRBAC(doc *RBACDocument) *RBACDocument {
+	out := &RBACDocument{Version: doc.Version,
+		Permissions: append([]RBACPermission{}, doc.Permissions...)}
+	sort.Slice(out.Permissions, func(i, j int) bool { return out.Permissions[i].Name < out.Permissions[j].Name })
+	for _, r := range doc.Roles {
+		perms := append([]string{}, r.Permissions...)
+		sort.Strings(perms)
+		out.Roles = append(out.Roles, RBACRole{Name: r.Name, Description: r.Description, Permissions: perms})
+	}
+	sort.Slice(out.Roles, func(i, j int) bool { return out.Roles[i].Name < out.Roles[j].Name })
+	return out
+}
+
+// TestRBACImportRoundTrip: export → import dokumen yang sama tidak mengubah apa pun (berulang kali),
+// dan diff dry-run sama persis dengan diff import sungguhan tanpa mengubah database.
+func TestRBACImportRoundTrip(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := NewRBACRepository(pgDB)
+
+	original, err := r.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := r.Import(original, RBACImportOptions{SkipRolesInUse: true}); err != nil {
+			t.Errorf("gagal mengembalikan RBAC semula: %v", err)
+		}
+	})
+
+	// Idempoten: import hasil export (2x) tidak menghasilkan perubahan.
+	for i := 0; i < 2; i++ {
+		diff, err := r.Import(original, RBACImportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.Empty() || len(diff.RolesKeptInUse) != 0 {
+			t.Fatalf("import ke-%d hasil export: diff %+v, want kosong", i+1, diff)
+		}
+	}
+	if again, _ := r.Export(); !reflect.DeepEqual(again, original) {
+		t.Fatalf("export berubah setelah import ulang:\n%+v\nwant\n%+v", again, original)
+	}
+
+	if len(original.Roles) == 0 {
+		t.Skip("database uji belum punya role")
+	}
+
+	// Dokumen berubah: 1 permission & 1 role baru, permission baru juga diberikan ke role pertama.
+	suffix := uuid.NewString()[:8]
+	perm, role := "test.rbac_"+suffix, "test_role_"+suffix
+	modified := normalizeRBAC(original)
+	modified.Permissions = append(modified.Permissions, RBACPermission{Name: perm, Resource: "test", Action: "read"})
+	modified.Roles = append(modified.Roles, RBACRole{Name: role, Description: "Role uji", Permissions: []string{perm}})
+	existing := modified.Roles[0].Name
+	modified.Roles[0].Permissions = append(modified.Roles[0].Permissions, perm)
+	modified = normalizeRBAC(modified)
+
+	want := &RBACDiff{
+		PermissionsAdded: []string{perm},
+		RolesAdded:       []string{role},
+		AssignmentsAdded: []RBACAssignment{{existing, perm}, {role, perm}},
+	}
+	want.sort()
+
+	dry, err := r.Import(modified, RBACImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dry, want) {
+		t.Fatalf("diff dry run %+v, want %+v", dry, want)
+	}
+	if after, _ := r.Export(); !reflect.DeepEqual(after, original) {
+		t.Fatal("dry run mengubah database")
+	}
+
+	applied, err := r.Import(modified, RBACImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(applied, dry) {
+		t.Fatalf("diff import %+v berbeda dengan dry run %+v", applied, dry)
+	}
+	after, err := r.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(after, modified) {
+		t.Fatalf("export setelah import:\n%+v\nwant\n%+v", after, modified)
+	}
+	if diff, err := r.Import(after, RBACImportOptions{}); err != nil || !diff.Empty() {
+		t.Fatalf("import ulang dokumen baru: diff %+v err %v, want kosong", diff, err)
+	}
+}
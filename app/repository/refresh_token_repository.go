@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenReused dikembalikan Rotate jika token lama sudah dicabut/dirotasi
+// (termasuk 2 refresh bersamaan dengan token yang sama).
+var ErrRefreshTokenReused = errors.New("refresh token already rotated or revoked")
+
+// RefreshTokenRepository mengelola tabel refresh_tokens.
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	FindByJTI(jti string) (*model.RefreshToken, error)
+	// Rotate mencabut oldJTI dan menyimpan next (family yang sama) dalam 1 transaksi.
+	// ErrRefreshTokenReused jika oldJTI sudah tidak aktif.
+	Rotate(oldJTI string, next *model.RefreshToken) error
+	// RevokeFamily mencabut semua token aktif dalam 1 family.
+	RevokeFamily(familyID uuid.UUID) error
+	// FindActiveSessions mengembalikan sesi (family dengan token aktif) milik user, terbaru dulu.
+	FindActiveSessions(userID uuid.UUID, now time.Time) ([]RefreshSession, error)
+	// RevokeSession mencabut 1 sesi milik user; false jika tidak ada sesi aktif tsb.
+	RevokeSession(userID, familyID uuid.UUID) (bool, error)
+	// RevokeOtherSessions mencabut semua sesi user kecuali keep; mengembalikan jumlah sesi.
+	RevokeOtherSessions(userID, keep uuid.UUID) (int64, error)
+}
+
+// RefreshSession adalah 1 sesi login (family refresh token) untuk daftar sesi.
+type RefreshSession struct {
+	ID         uuid.UUID `json:"id"`         // family_id
+	CreatedAt  time.Time `json:"createdAt"`  // waktu login
+	LastUsedAt time.Time `json:"lastUsedAt"` // rotasi (refresh) terakhir
+	ExpiresAt  time.Time `json:"expiresAt"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current" gorm:"-"` // diisi service: sesi milik token yang sedang dipakai
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository membuat instance RefreshTokenRepository.
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db}
+}
+
+func (r *refreshTokenRepository) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) FindByJTI(jti string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.db.Where("jti = ?", jti).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Rotate(oldJTI string, next *model.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.RefreshToken{}).
+			Where("jti = ? AND revoked_at IS NULL", oldJTI).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		return tx.Create(next).Error
+	})
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) FindActiveSessions(userID uuid.UUID, now time.Time) ([]RefreshSession, error) {
+	sessions := []RefreshSession{}
+	err := r.db.Raw(`
+		SELECT t.family_id AS id, f.started_at AS created_at, t.created_at AS last_used_at,
+		       t.expires_at, t.user_agent, t.ip
+		FROM refresh_tokens t
+		JOIN (
+			SELECT family_id, MIN(created_at) AS started_at
+			FROM refresh_tokens
+			WHERE user_id = ?
+			GROUP BY family_id
+		) f ON f.family_id = t.family_id
+		WHERE t.user_id = ? AND t.revoked_at IS NULL AND t.expires_at > ?
+		ORDER BY t.created_at DESC`, userID, userID, now).
+		Scan(&sessions).Error
+	return sessions, err
+}
+
+func (r *refreshTokenRepository) RevokeSession(userID, familyID uuid.UUID) (bool, error) {
+	res := r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *refreshTokenRepository) RevokeOtherSessions(userID, keep uuid.UUID) (int64, error) {
+	res := r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keep).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected, res.Error
+}
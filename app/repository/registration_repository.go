@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRegistrationNotFound dikembalikan jika user tidak ada atau tidak sedang menunggu persetujuan.
+var ErrRegistrationNotFound = errors.New("pending registration not found")
+
+// RegistrationConflictError dikembalikan CreatePending jika insert ditolak unique constraint
+// (registrasi bersamaan dengan data yang sama lolos FindConflicts).
+// Field berisi field input yang bentrok ("" = constraint tidak dikenali).
+type RegistrationConflictError struct {
+	Field string
+}
+
+func (e *RegistrationConflictError) Error() string {
+	if e.Field == "" {
+		return "registration conflict"
+	}
+	return "registration conflict: " + e.Field
+}
+
+// registrationConstraintFields memetakan nama unique constraint ke field input registrasi.
+// Nama uni_* dibuat AutoMigrate GORM; *_key adalah nama bawaan Postgres di database lama.
+var registrationConstraintFields = map[string]string{
+	"uni_users_username": "username",
+	"users_username_key": "username",
+	"uni_users_email":    "email",
+	"users_email_key":    "email",
+}
+
+// registrationConflict mengubah unique_violation (23505) menjadi RegistrationConflictError;
+// error lain dikembalikan apa adanya.
+func registrationConflict(err error) error {
+	if err == nil || !strings.Contains(err.Error(), "23505") {
+		return err
+	}
+	for constraint, field := range registrationConstraintFields {
+		if strings.Contains(err.Error(), `"`+constraint+`"`) {
+			return &RegistrationConflictError{Field: field}
+		}
+	}
+	return &RegistrationConflictError{}
+}
+
+// PendingRegistration adalah 1 baris antrean persetujuan registrasi mahasiswa.
+type PendingRegistration struct {
+	UserID       uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	FullName     string    `json:"fullName"`
+	StudentID    string    `json:"studentId"` // NIM
+	ProgramStudy string    `json:"programStudy"`
+	AcademicYear string    `json:"academicYear"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RegistrationRepository mengelola registrasi mandiri mahasiswa (akun pending_approval).
+type RegistrationRepository interface {
+	// FindConflicts mengembalikan field (username/email/studentId) yang sudah dipakai.
+	FindConflicts(username, email, nim string) ([]string, error)
+	// CreatePending membuat user (role mahasiswa, nonaktif, pending) + profil mahasiswa dalam 1 transaksi.
+	// Unique constraint yang ditolak dikembalikan sebagai *RegistrationConflictError.
+	CreatePending(user *model.User, student *model.Student) error
+	// FindPending mengambil antrean registrasi (terlama dulu) beserta total.
+	FindPending(page, limit int) ([]PendingRegistration, int64, error)
+	// Approve mengaktifkan akun pending; ErrRegistrationNotFound jika tidak pending.
+	Approve(userID uuid.UUID) (*PendingRegistration, error)
+	// Reject menghapus akun pending beserta profil mahasiswanya.
+	Reject(userID uuid.UUID) (*PendingRegistration, error)
+}
+
+type registrationRepository struct {
+	db *gorm.DB
+}
+
+// NewRegistrationRepository membuat instance RegistrationRepository.
+func NewRegistrationRepository(db *gorm.DB) RegistrationRepository {
+	return &registrationRepository{db}
+}
+
+func (r *registrationRepository) FindConflicts(username, email, nim string) ([]string, error) {
+	var conflicts []string
+	checks := []struct {
+		field string
+		query *gorm.DB
+	}{
+		{"username", r.db.Model(&model.User{}).Where("LOWER(username) = ?", strings.ToLower(username))},
+		{"email", r.db.Model(&model.User{}).Where("LOWER(email) = ?", strings.ToLower(email))},
+		{"studentId", r.db.Model(&model.Student{}).Where("student_id = ?", nim)},
+	}
+	for _, c := range checks {
+		var n int64
+		if err := c.query.Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			conflicts = append(conflicts, c.field)
+		}
+	}
+	return conflicts, nil
+}
+
+func (r *registrationRepository) CreatePending(user *model.User, student *model.Student) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var role model.Role
+		if err := tx.Where("name = ?", "mahasiswa").First(&role).Error; err != nil {
+			return err
+		}
+		if user.ID == uuid.Nil {
+			user.ID = uuid.New()
+		}
+		user.RoleID = role.ID
+		user.IsActive = false
+		user.PendingApproval = true
+		// IsActive=false harus ditulis eksplisit (default kolom true).
+		if err := tx.Select("*").Omit("Role").Create(user).Error; err != nil {
+			return err
+		}
+		student.UserID = user.ID
+		return tx.Omit("User", "Advisor").Create(student).Error
+	})
+	return registrationConflict(err)
+}
+
+// pendingQuery: user pending + profil mahasiswanya.
+func pendingQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("users u").
+		Select(`u.id AS user_id, u.username, u.email, u.full_name, s.student_id,
+			s.program_study, s.academic_year, u.created_at`).
+		Joins("LEFT JOIN students s ON s.user_id = u.id").
+		Where("u.pending_approval = ?", true)
+}
+
+func (r *registrationRepository) FindPending(page, limit int) ([]PendingRegistration, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.User{}).Where("pending_approval = ?", true).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := []PendingRegistration{}
+	err := pendingQuery(r.db).
+		Order("u.created_at ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// findPendingForUpdate mengunci baris user pending agar approve/reject tidak balapan.
+func (r *registrationRepository) findPendingForUpdate(tx *gorm.DB, userID uuid.UUID) (*PendingRegistration, error) {
+	var reg PendingRegistration
+	res := pendingQuery(tx).
+		Where("u.id = ?", userID).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "u"}}).
+		Scan(&reg)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrRegistrationNotFound
+	}
+	return &reg, nil
+}
+
+func (r *registrationRepository) Approve(userID uuid.UUID) (*PendingRegistration, error) {
+	var reg *PendingRegistration
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reg, err = r.findPendingForUpdate(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"is_active":        true,
+				"pending_approval": false,
+			}).Error
+	})
+	return reg, err
+}
+
+func (r *registrationRepository) Reject(userID uuid.UUID) (*PendingRegistration, error) {
+	var reg *PendingRegistration
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reg, err = r.findPendingForUpdate(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.Student{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND pending_approval = ?", userID, true).Delete(&model.User{}).Error
+	})
+	return reg, err
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistrationConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "tanpa error", err: nil, want: nil},
+		{name: "username", err: errors.New(`ERROR: duplicate key value violates unique constraint "uni_users_username" (SQLSTATE 23505)`),
+			want: &RegistrationConflictError{Field: "username"}},
+		{name: "email", err: errors.New(`ERROR: duplicate key value violates unique constraint "uni_users_email" (SQLSTATE 23505)`),
+			want: &RegistrationConflictError{Field: "email"}},
+		{name: "nama constraint database lama", err: errors.New(`ERROR: duplicate key value violates unique constraint "users_email_key" (SQLSTATE 23505)`),
+			want: &RegistrationConflictError{Field: "email"}},
+		{name: "constraint tidak dikenal", err: errors.New(`ERROR: duplicate key value violates unique constraint "students_pkey" (SQLSTATE 23505)`),
+			want: &RegistrationConflictError{}},
+		{name: "bukan unique violation", err: errors.New(`ERROR: relation "roles" does not exist (SQLSTATE 42P01)`),
+			want: errors.New(`ERROR: relation "roles" does not exist (SQLSTATE 42P01)`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registrationConflict(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("registrationConflict(%v) = %#v, want %#v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"student-achievement-backend/utils"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// statsSharedTimeout membatasi eksekusi agregasi bersama. Eksekusi tidak memakai
+// context pemanggil pertama (agar pembatalan 1 request tidak menggagalkan request lain
+// yang menunggu hasil yang sama), sehingga perlu batas waktu sendiri.
+const statsSharedTimeout = 30 * time.Second
+
+// coalescingReportRepository membungkus ReportRepository sehingga query GetStatistics
+// yang identik dan berjalan bersamaan hanya dieksekusi sekali (singleflight).
+// Setiap pemanggil menerima salinan hasil sendiri karena ReportResult berisi map.
+type coalescingReportRepository struct {
+	ReportRepository
+	group singleflight.Group
+}
+
+// NewCoalescingReportRepository membungkus inner dengan request coalescing untuk GetStatistics.
+func NewCoalescingReportRepository(inner ReportRepository) ReportRepository {
+	return &coalescingReportRepository{ReportRepository: inner}
+}
+
+// statsFilterKey menormalisasi filter: studentIds di-lowercase, diurutkan, dan diduplikasi.
+func statsFilterKey(filter ReportFilter) string {
+	ids := make([]string, 0, len(filter.StudentIDs))
+	seen := map[string]bool{}
+	for _, id := range filter.StudentIDs {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return "stats|" + strings.Join(ids, ",")
+}
+
+// GetStatistics lihat ReportRepository.GetStatistics.
+func (r *coalescingReportRepository) GetStatistics(ctx context.Context, filter ReportFilter) (*ReportResult, error) {
+	ch := r.group.DoChan(statsFilterKey(filter), func() (any, error) {
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsSharedTimeout)
+		defer cancel()
+		return r.ReportRepository.GetStatistics(sharedCtx, filter)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return cloneReportResult(res.Val.(*ReportResult)), nil
+	}
+}
+
+// cachingReportRepository menyimpan hasil GetStatistics per filter di memori dengan TTL.
+// Dipasang di atas coalescingReportRepository: cache miss yang bersamaan tetap hanya
+// menjalankan 1 agregasi.
+type cachingReportRepository struct {
+	ReportRepository
+	ttl     func() time.Duration // dibaca setiap kali: TTL bisa berubah saat config di-reload
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry // key statsFilterKey
+}
+
+type statsCacheEntry struct {
+	result   *ReportResult
+	cachedAt time.Time
+}
+
+// NewCachingReportRepository membungkus inner dengan cache GetStatistics (ttl() <= 0 → cache nonaktif).
+func NewCachingReportRepository(inner ReportRepository, ttl func() time.Duration) ReportRepository {
+	return &cachingReportRepository{
+		ReportRepository: inner,
+		ttl:              ttl,
+		now:              time.Now,
+		entries:          map[string]statsCacheEntry{},
+	}
+}
+
+// GetStatistics lihat ReportRepository.GetStatistics.
+func (r *cachingReportRepository) GetStatistics(ctx context.Context, filter ReportFilter) (*ReportResult, error) {
+	ttl := r.ttl()
+	if ttl <= 0 {
+		return r.ReportRepository.GetStatistics(ctx, filter)
+	}
+
+	key := statsFilterKey(filter)
+	now := r.now()
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	r.mu.Unlock()
+	if ok && now.Sub(entry.cachedAt) < ttl {
+		return cloneReportResult(entry.result), nil
+	}
+
+	result, err := r.ReportRepository.GetStatistics(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	// Buang entri kedaluwarsa agar filter yang jarang dipakai tidak menumpuk.
+	for k, e := range r.entries {
+		if now.Sub(e.cachedAt) >= ttl {
+			delete(r.entries, k)
+		}
+	}
+	r.entries[key] = statsCacheEntry{result: cloneReportResult(result), cachedAt: now}
+	r.mu.Unlock()
+	return result, nil
+}
+
+// cloneReportResult membuat deep copy ReportResult (map & slice tidak dibagi antar pemanggil).
+func cloneReportResult(src *ReportResult) *ReportResult {
+	if src == nil {
+		return nil
+	}
+	dst := *src
+	dst.TotalByType = cloneCountMap(src.TotalByType)
+	dst.TotalByPeriod = cloneCountMap(src.TotalByPeriod)
+	dst.CompetitionLevelDist = cloneCountMap(src.CompetitionLevelDist)
+	if src.TopStudents != nil {
+		dst.TopStudents = append([]StudentScore(nil), src.TopStudents...)
+	}
+	if src.TargetProgress != nil {
+		tp := *src.TargetProgress
+		dst.TargetProgress = &tp
+	}
+	if src.PeriodLabels != nil {
+		dst.PeriodLabels = make(map[string]utils.PeriodLabel, len(src.PeriodLabels))
+		for k, v := range src.PeriodLabels {
+			dst.PeriodLabels[k] = v
+		}
+	}
+	return &dst
+}
+
+func cloneCountMap(src map[string]int64) map[string]int64 {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
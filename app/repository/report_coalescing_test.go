@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingReportRepo menghitung eksekusi GetStatistics; jika release != nil, eksekusi
+// menunggu release ditutup (agar pemanggil lain sempat bergabung).
+type countingReportRepo struct {
+	ReportRepository
+	calls   atomic.Int64
+	release chan struct{}
+	err     error
+}
+
+func (r *countingReportRepo) GetStatistics(ctx context.Context, filter ReportFilter) (*ReportResult, error) {
+	n := r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &ReportResult{
+		TotalAchievements: n,
+		TotalByType:       map[string]int64{"competition": int64(len(filter.StudentIDs))},
+		TotalByPeriod:     map[string]int64{"2026-03": 1},
+		TopStudents:       []StudentScore{{StudentID: "a", TotalPoints: 10}},
+	}, nil
+}
+
+// fireConcurrent menjalankan n GetStatistics bersamaan; filterOf(i) menentukan filter tiap pemanggil.
+// Eksekusi asli baru dilepas setelah semua pemanggil siap.
+func fireConcurrent(t *testing.T, repo ReportRepository, inner *countingReportRepo, n int, filterOf func(i int) ReportFilter) ([]*ReportResult, []error) {
+	t.Helper()
+	results := make([]*ReportResult, n)
+	errs := make([]error, n)
+	var ready, done sync.WaitGroup
+	ready.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer done.Done()
+			ready.Done()
+			results[i], errs[i] = repo.GetStatistics(context.Background(), filterOf(i))
+		}()
+	}
+	ready.Wait()
+	time.Sleep(50 * time.Millisecond) // beri waktu semua pemanggil masuk ke singleflight
+	close(inner.release)
+	done.Wait()
+	return results, errs
+}
+
+func TestCoalescingReportRepositoryConcurrentCalls(t *testing.T) {
+	const n = 40
+	tests := []struct {
+		name      string
+		filterOf  func(i int) ReportFilter
+		wantCalls int64
+	}{
+		{name: "statistik global", wantCalls: 1,
+			filterOf: func(int) ReportFilter { return ReportFilter{} }},
+		{name: "filter sama walau urutan/huruf/duplikat berbeda", wantCalls: 1,
+			filterOf: func(i int) ReportFilter {
+				if i%2 == 0 {
+					return ReportFilter{StudentIDs: []string{"B", "a"}}
+				}
+				return ReportFilter{StudentIDs: []string{"a", "b", " a "}}
+			}},
+		{name: "filter berbeda dieksekusi terpisah", wantCalls: 2,
+			filterOf: func(i int) ReportFilter {
+				return ReportFilter{StudentIDs: []string{fmt.Sprint(i % 2)}}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &countingReportRepo{release: make(chan struct{})}
+			results, errs := fireConcurrent(t, NewCoalescingReportRepository(inner), inner, n, tt.filterOf)
+
+			if got := inner.calls.Load(); got != tt.wantCalls {
+				t.Fatalf("eksekusi asli %d kali, want %d", got, tt.wantCalls)
+			}
+			for i := range results {
+				if errs[i] != nil {
+					t.Fatalf("pemanggil %d: err = %v", i, errs[i])
+				}
+				if results[i] == nil || results[i].TotalByPeriod["2026-03"] != 1 || len(results[i].TopStudents) != 1 {
+					t.Fatalf("pemanggil %d: hasil %+v", i, results[i])
+				}
+			}
+			// Setiap pemanggil memegang salinan sendiri: mengubah 1 hasil tidak memengaruhi yang lain.
+			results[0].TotalByType["competition"] = 999
+			results[0].TopStudents[0].TotalPoints = 999
+			for i := 1; i < n; i++ {
+				if results[i].TotalByType["competition"] == 999 || results[i].TopStudents[0].TotalPoints == 999 {
+					t.Fatalf("pemanggil %d berbagi map/slice dengan pemanggil 0", i)
+				}
+			}
+		})
+	}
+}
+
+// TestCoalescingReportRepositoryCallerCancel: pemanggil yang membatalkan request berhenti
+// menunggu, tapi eksekusi bersama tetap selesai untuk pemanggil lain.
+func TestCoalescingReportRepositoryCallerCancel(t *testing.T) {
+	inner := &countingReportRepo{release: make(chan struct{})}
+	repo := NewCoalescingReportRepository(inner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := repo.GetStatistics(ctx, ReportFilter{})
+		cancelled <- err
+	}()
+	for inner.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	other := make(chan error, 1)
+	go func() {
+		_, err := repo.GetStatistics(context.Background(), ReportFilter{})
+		other <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // beri waktu pemanggil kedua bergabung ke eksekusi yang sama
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("pemanggil yang dibatalkan: err = %v, want context.Canceled", err)
+	}
+	close(inner.release)
+	if err := <-other; err != nil {
+		t.Fatalf("pemanggil lain ikut gagal: %v", err)
+	}
+	if got := inner.calls.Load(); got != 1 {
+		t.Fatalf("eksekusi asli %d kali, want 1", got)
+	}
+}
+
+func TestCachingReportRepositoryTTL(t *testing.T) {
+	inner := &countingReportRepo{}
+	ttl := time.Minute
+	repo := NewCachingReportRepository(inner, func() time.Duration { return ttl }).(*cachingReportRepository)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	global := ReportFilter{}
+
+	steps := []struct {
+		name      string
+		setup     func()
+		filter    ReportFilter
+		wantErr   bool
+		wantCalls int64
+	}{
+		{name: "miss pertama", filter: global, wantCalls: 1},
+		{name: "hit dalam TTL", setup: func() { now = now.Add(ttl - time.Second) }, filter: global, wantCalls: 1},
+		{name: "filter lain tidak berbagi entri", filter: ReportFilter{StudentIDs: []string{"a"}}, wantCalls: 2},
+		{name: "filter sama setelah normalisasi", filter: ReportFilter{StudentIDs: []string{"A", "a"}}, wantCalls: 2},
+		{name: "kedaluwarsa dimuat ulang", setup: func() { now = now.Add(time.Second) }, filter: global, wantCalls: 3},
+		{name: "error tidak di-cache", setup: func() { now = now.Add(ttl); inner.err = errors.New("mongo down") },
+			filter: global, wantErr: true, wantCalls: 4},
+		{name: "dicoba lagi setelah error", setup: func() { inner.err = nil }, filter: global, wantCalls: 5},
+		{name: "TTL 0 menonaktifkan cache", setup: func() { ttl = 0 }, filter: global, wantCalls: 6},
+		{name: "TTL 0 tetap tanpa cache", filter: global, wantCalls: 7},
+	}
+	for _, st := range steps {
+		if st.setup != nil {
+			st.setup()
+		}
+		res, err := repo.GetStatistics(context.Background(), st.filter)
+		if (err != nil) != st.wantErr {
+			t.Fatalf("%s: err = %v, wantErr %v", st.name, err, st.wantErr)
+		}
+		if got := inner.calls.Load(); got != st.wantCalls {
+			t.Fatalf("%s: eksekusi asli %d kali, want %d", st.name, got, st.wantCalls)
+		}
+		if res != nil {
+			// Hasil boleh diubah service (mis. PeriodLabels) tanpa mengotori cache.
+			res.TotalByType["competition"] = 999
+		}
+	}
+
+	ttl = time.Minute
+	first, _ := repo.GetStatistics(context.Background(), global)
+	first.TotalByType["competition"] = 999
+	second, _ := repo.GetStatistics(context.Background(), global)
+	if second.TotalByType["competition"] == 999 {
+		t.Fatal("perubahan hasil oleh pemanggil ikut tersimpan di cache")
+	}
+}
+
+// TestCachingAboveCoalescing: cache miss yang bersamaan (cache dingin) tetap hanya
+// menjalankan 1 agregasi karena cache dipasang di atas singleflight.
+func TestCachingAboveCoalescing(t *testing.T) {
+	const n = 40
+	inner := &countingReportRepo{release: make(chan struct{})}
+	repo := NewCachingReportRepository(NewCoalescingReportRepository(inner), func() time.Duration { return time.Minute })
+
+	results, errs := fireConcurrent(t, repo, inner, n, func(int) ReportFilter { return ReportFilter{} })
+	for i := range results {
+		if errs[i] != nil || results[i] == nil {
+			t.Fatalf("pemanggil %d: hasil %v err %v", i, results[i], errs[i])
+		}
+	}
+	if _, err := repo.GetStatistics(context.Background(), ReportFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := inner.calls.Load(); got != 1 {
+		t.Fatalf("eksekusi asli %d kali, want 1", got)
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestStatisticsSumsFractionalPoints: $sum points di top students menghasilkan float, baik dari
+// campuran dokumen lama (int32/int64) + pecahan maupun dari dokumen yang semuanya bulat.
+func TestStatisticsSumsFractionalPoints(t *testing.T) {
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+	mixed, integers, zero := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	now := time.Now()
+
+	docs := []any{
+		bson.M{"studentId": mixed, "achievementType": "competition", "points": int32(3), "createdAt": now},
+		bson.M{"studentId": mixed, "achievementType": "competition", "points": 2.5, "createdAt": now},
+		bson.M{"studentId": mixed, "achievementType": "publication", "points": 0.25, "createdAt": now},
+		bson.M{"studentId": integers, "achievementType": "competition", "points": int32(4), "createdAt": now},
+		bson.M{"studentId": integers, "achievementType": "competition", "points": int64(4), "createdAt": now},
+		bson.M{"studentId": zero, "achievementType": "organization", "points": 0.0, "createdAt": now},
+	}
+	if _, err := mongoDB.Collection("achievements").InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewReportRepository(mongoDB).GetStatistics(ctx, ReportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StudentScore{
+		{StudentID: integers, TotalPoints: 8, TotalAchievements: 2},
+		{StudentID: mixed, TotalPoints: 5.75, TotalAchievements: 3},
+		{StudentID: zero, TotalPoints: 0, TotalAchievements: 1},
+	}
+	if len(res.TopStudents) != len(want) {
+		t.Fatalf("%d top students, want %d: %+v", len(res.TopStudents), len(want), res.TopStudents)
+	}
+	for i, w := range want {
+		if res.TopStudents[i] != w {
+			t.Fatalf("peringkat %d: %+v, want %+v", i+1, res.TopStudents[i], w)
+		}
+	}
+}
+
+// TestPortfolioSumsFractionalPoints: subtotal & total poin portofolio ikut menjumlahkan pecahan.
+func TestPortfolioSumsFractionalPoints(t *testing.T) {
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+	studentID := uuid.New()
+
+	points := []any{int32(10), 2.5, int64(0)}
+	mongoIDs := make([]string, 0, len(points))
+	for _, p := range points {
+		oid := primitive.NewObjectID()
+		doc := bson.M{"_id": oid, "studentId": studentID, "achievementType": "competition", "title": "Juara", "points": p}
+		if _, err := mongoDB.Collection("achievements").InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		mongoIDs = append(mongoIDs, oid.Hex())
+	}
+
+	res, err := NewReportRepository(mongoDB).GetPortfolio(ctx, studentID, mongoIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalPoints != 12.5 || len(res.Groups) != 1 || res.Groups[0].SubtotalPoints != 12.5 {
+		t.Fatalf("total %v groups %+v, want 12.5", res.TotalPoints, res.Groups)
+	}
+	var sum float64
+	for _, item := range res.Groups[0].Items {
+		sum += item.Points
+	}
+	if sum != 12.5 {
+		t.Fatalf("jumlah points item %v, want 12.5", sum)
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestGetPortfolioGroupsAndSubtotals: prestasi dikelompokkan per tipe (urut nama tipe), item
+// terbaru dulu, subtotal & total dijumlahkan dari poin; dokumen di luar mongoIDs, milik
+// mahasiswa lain, atau yang di-soft-delete tidak ikut dihitung.
+func TestGetPortfolioGroupsAndSubtotals(t *testing.T) {
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+	r := NewReportRepository(mongoDB)
+	studentID := uuid.New()
+
+	date := func(m time.Month) *time.Time {
+		d := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	national := "national"
+	var mongoIDs []string
+	insert := func(doc model.Achievement, included bool) primitive.ObjectID {
+		t.Helper()
+		doc.ID = primitive.NewObjectID()
+		if doc.StudentID == uuid.Nil {
+			doc.StudentID = studentID
+		}
+		doc.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		if _, err := mongoDB.Collection("achievements").InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		if included {
+			mongoIDs = append(mongoIDs, doc.ID.Hex())
+		}
+		return doc.ID
+	}
+
+	older := insert(model.Achievement{AchievementType: "competition", Title: "Juara 1", Points: 10,
+		Details: model.AchievementDetails{CompetitionLevel: &national, EventDate: date(time.March)},
+		Tags:    []string{"coding", "ai"}}, true)
+	newer := insert(model.Achievement{AchievementType: "competition", Title: "Finalis", Points: 2.5,
+		Details: model.AchievementDetails{EventDate: date(time.May)}, Tags: []string{"coding"}}, true)
+	org := insert(model.Achievement{AchievementType: "organization", Title: "Ketua BEM", Points: 5,
+		Tags: []string{"leadership"}}, true)
+	// Tidak boleh ikut: belum verified (di luar mongoIDs), di-soft-delete, milik mahasiswa lain.
+	insert(model.Achievement{AchievementType: "competition", Title: "Draft", Points: 100, Tags: []string{"coding"}}, false)
+	insert(model.Achievement{AchievementType: "competition", Title: "Dihapus", Points: 50, Deleted: true}, true)
+	insert(model.Achievement{StudentID: uuid.New(), AchievementType: "publication", Title: "Orang lain", Points: 30}, true)
+	mongoIDs = append(mongoIDs, "bukan-object-id")
+
+	got, err := r.GetPortfolio(ctx, studentID, mongoIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalAchievements != 3 || got.TotalPoints != 17.5 {
+		t.Fatalf("total %d prestasi / %g poin, want 3 / 17.5", got.TotalAchievements, got.TotalPoints)
+	}
+
+	type group struct {
+		Type     string
+		Count    int64
+		Subtotal float64
+		Items    []primitive.ObjectID
+	}
+	var groups []group
+	for _, g := range got.Groups {
+		gr := group{Type: g.AchievementType, Count: g.Count, Subtotal: g.SubtotalPoints}
+		for _, it := range g.Items {
+			gr.Items = append(gr.Items, it.MongoID)
+		}
+		groups = append(groups, gr)
+	}
+	wantGroups := []group{
+		{Type: "competition", Count: 2, Subtotal: 12.5, Items: []primitive.ObjectID{newer, older}},
+		{Type: "organization", Count: 1, Subtotal: 5, Items: []primitive.ObjectID{org}},
+	}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Fatalf("grup %+v, want %+v", groups, wantGroups)
+	}
+	if it := got.Groups[0].Items[1]; it.Title != "Juara 1" || it.Level == nil || *it.Level != national || it.Points != 10 {
+		t.Fatalf("item %+v, want Juara 1 national 10 poin", it)
+	}
+
+	wantTags := []TagCount{{Tag: "coding", Count: 2}, {Tag: "ai", Count: 1}, {Tag: "leadership", Count: 1}}
+	if !reflect.DeepEqual(got.Tags, wantTags) {
+		t.Fatalf("tag %+v, want %+v", got.Tags, wantTags)
+	}
+
+	// Tanpa prestasi verified: hasil kosong, bukan nil.
+	empty, err := r.GetPortfolio(ctx, studentID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Groups == nil || empty.Tags == nil || empty.TotalAchievements != 0 {
+		t.Fatalf("portofolio kosong %+v", empty)
+	}
+}
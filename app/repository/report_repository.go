@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	// "student-achievement-backend/app/model"
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -19,9 +23,9 @@ type ReportFilter struct {
 // StudentScore menyimpan agregat per mahasiswa (untuk top students).
 // StudentID dikirim sebagai string UUID (sesuai representasi di Mongo & JSON).
 type StudentScore struct {
-	StudentID         string `json:"studentId"`
-	TotalPoints       int64  `json:"totalPoints"`
-	TotalAchievements int64  `json:"totalAchievements"`
+	StudentID         string  `json:"studentId"`
+	TotalPoints       float64 `json:"totalPoints"`
+	TotalAchievements int64   `json:"totalAchievements"`
 }
 
 // ReportResult adalah struktur hasil agregasi statistik prestasi.
@@ -31,12 +35,56 @@ type ReportResult struct {
 	TotalByPeriod        map[string]int64 `json:"totalByPeriod"` // key: "YYYY-MM"
 	CompetitionLevelDist map[string]int64 `json:"competitionLevelDistribution"`
 	TopStudents          []StudentScore   `json:"topStudents"`
+
+	// TargetProgress diisi service untuk statistik 1 mahasiswa (progres target tahunan).
+	TargetProgress *TargetProgress `json:"targetProgress,omitempty"`
+
+	// PeriodLabels diisi service: label lokal (Accept-Language) untuk tiap key TotalByPeriod.
+	PeriodLabels map[string]utils.PeriodLabel `json:"periodLabels,omitempty"`
+}
+
+// PortfolioItem adalah 1 prestasi di dalam portofolio mahasiswa.
+type PortfolioItem struct {
+	MongoID primitive.ObjectID `bson:"mongoId" json:"-"`
+	Title   string             `bson:"title" json:"title"`
+	Level   *string            `bson:"level" json:"level,omitempty"` // details.competitionLevel
+	Date    *time.Time         `bson:"date" json:"date,omitempty"`   // details.eventDate
+	Points  float64            `bson:"points" json:"points"`
+	// Attachments: URL lampiran diubah menjadi URL lengkap oleh service.
+	Attachments []model.Attachment `bson:"attachments" json:"attachments"`
+
+	// AchievementID (achievement_references.id) diisi oleh service.
+	AchievementID string `bson:"-" json:"achievementId,omitempty"`
+}
+
+// PortfolioGroup mengelompokkan prestasi portofolio per achievementType.
+type PortfolioGroup struct {
+	AchievementType string          `bson:"_id" json:"achievementType"`
+	Count           int64           `bson:"count" json:"count"`
+	SubtotalPoints  float64         `bson:"subtotal" json:"subtotalPoints"`
+	Items           []PortfolioItem `bson:"items" json:"items"`
+}
+
+// TagCount menyimpan frekuensi pemakaian 1 tag.
+type TagCount struct {
+	Tag   string `bson:"_id" json:"tag"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// PortfolioResult adalah hasil agregasi portofolio mahasiswa (prestasi verified saja).
+type PortfolioResult struct {
+	Groups            []PortfolioGroup `json:"groups"`
+	TotalAchievements int64            `json:"totalAchievements"`
+	TotalPoints       float64          `json:"totalPoints"`
+	Tags              []TagCount       `json:"tags"`
 }
 
 // ReportRepository menangani query statistik (FR-011) ke MongoDB.
 type ReportRepository interface {
 	// GetStatistics menjalankan agregasi statistik berdasarkan filter studentIds.
 	GetStatistics(ctx context.Context, filter ReportFilter) (*ReportResult, error)
+	// GetPortfolio mengelompokkan prestasi (mongoIDs) milik 1 mahasiswa per tipe + frekuensi tag.
+	GetPortfolio(ctx context.Context, studentID uuid.UUID, mongoIDs []string) (*PortfolioResult, error)
 }
 
 // reportRepository implementasi konkrit ReportRepository.
@@ -186,16 +234,23 @@ func (r *reportRepository) GetStatistics(ctx context.Context, filter ReportFilte
 	// =========================
 	// 5) Top Students (berdasarkan total points & jumlah prestasi)
 	// =========================
+	// Mahasiswa yang sudah dianonimkan tetap dihitung di total, tapi tidak masuk peringkat.
+	topMatch := bson.M{"anonymized": bson.M{"$ne": true}}
+	for k, v := range match {
+		topMatch[k] = v
+	}
 	topPipeline := mongo.Pipeline{
-		{{Key: "$match", Value: match}},
+		{{Key: "$match", Value: topMatch}},
 		{{Key: "$group", Value: bson.M{
 			"_id":              "$studentId",      // string UUID
 			"totalPoints":      bson.M{"$sum": "$points"},
 			"achievementCount": bson.M{"$sum": 1},
 		}}},
-		{{Key: "$sort", Value: bson.M{
-			"totalPoints":      -1,
-			"achievementCount": -1,
+		// bson.D agar urutan kunci sort terjaga; _id sebagai tiebreaker deterministik.
+		{{Key: "$sort", Value: bson.D{
+			{Key: "totalPoints", Value: -1},
+			{Key: "achievementCount", Value: -1},
+			{Key: "_id", Value: 1},
 		}}},
 		{{Key: "$limit", Value: 10}},
 	}
@@ -207,9 +262,9 @@ func (r *reportRepository) GetStatistics(ctx context.Context, filter ReportFilte
 	for cur.Next(ctx) {
 		// _id adalah string (studentId)
 		var row struct {
-			ID               string `bson:"_id"`
-			TotalPoints      int64  `bson:"totalPoints"`
-			AchievementCount int64  `bson:"achievementCount"`
+			ID               string  `bson:"_id"`
+			TotalPoints      float64 `bson:"totalPoints"` // $sum bisa int (semua bulat) atau double
+			AchievementCount int64   `bson:"achievementCount"`
 		}
 		if err := cur.Decode(&row); err != nil {
 			return nil, err
@@ -228,3 +283,91 @@ func (r *reportRepository) GetStatistics(ctx context.Context, filter ReportFilte
 
 	return result, nil
 }
+
+// GetPortfolio menjalankan 1 agregasi ($facet) untuk portofolio mahasiswa:
+// - byType: prestasi dikelompokkan per achievementType + subtotal poin
+// - tags  : frekuensi tag (terbanyak dulu)
+// mongoIDs berisi _id dokumen yang sudah dipastikan berstatus verified di Postgres.
+func (r *reportRepository) GetPortfolio(ctx context.Context, studentID uuid.UUID, mongoIDs []string) (*PortfolioResult, error) {
+	result := &PortfolioResult{
+		Groups: []PortfolioGroup{},
+		Tags:   []TagCount{},
+	}
+
+	oids := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return result, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"_id":       bson.M{"$in": oids},
+			"studentId": studentID, // scope ke pemanggil (double check selain filter _id)
+			"deleted":   bson.M{"$ne": true},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"byType": bson.A{
+				bson.M{"$sort": bson.D{
+					{Key: "details.eventDate", Value: -1},
+					{Key: "createdAt", Value: -1},
+					{Key: "_id", Value: -1},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":      "$achievementType",
+					"count":    bson.M{"$sum": 1},
+					"subtotal": bson.M{"$sum": "$points"},
+					"items": bson.M{"$push": bson.M{
+						"mongoId":     "$_id",
+						"title":       "$title",
+						"level":       "$details.competitionLevel",
+						"date":        "$details.eventDate",
+						"points":      "$points",
+						"attachments": bson.M{"$ifNull": bson.A{"$attachments", bson.A{}}},
+					}},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"tags": bson.A{
+				bson.M{"$unwind": "$tags"},
+				bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+		}}},
+	}
+
+	cur, err := r.mongo.Collection("achievements").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var rows []struct {
+		ByType []PortfolioGroup `bson:"byType"`
+		Tags   []TagCount       `bson:"tags"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	for _, g := range rows[0].ByType {
+		if g.AchievementType == "" {
+			g.AchievementType = "unknown"
+		}
+		result.Groups = append(result.Groups, g)
+		result.TotalAchievements += g.Count
+		result.TotalPoints += g.SubtotalPoints
+	}
+	if rows[0].Tags != nil {
+		result.Tags = rows[0].Tags
+	}
+
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionClassStudentPersonal: data pribadi mahasiswa (nama, email, username, NIM,
+// field personal di detail prestasi), dihitung dari tanggal lulus.
+const RetentionClassStudentPersonal = "student_personal"
+
+// DefaultRetentionYears adalah masa retensi bawaan per kelas data jika admin belum mengaturnya.
+var DefaultRetentionYears = map[string]int{
+	RetentionClassStudentPersonal: 5,
+}
+
+// ErrUnknownDataClass dikembalikan untuk kelas data yang tidak dikenal.
+var ErrUnknownDataClass = errors.New("unknown retention data class")
+
+// RetentionCandidate adalah mahasiswa lulus yang sudah melewati masa retensi.
+type RetentionCandidate struct {
+	StudentID   uuid.UUID `json:"studentId"`
+	UserID      uuid.UUID `json:"userId"`
+	NIM         string    `json:"nim"`
+	FullName    string    `json:"fullName"`
+	GraduatedAt time.Time `json:"graduatedAt"`
+}
+
+// RetentionRepository mengelola kebijakan retensi & anonimisasi data mahasiswa.
+type RetentionRepository interface {
+	// FindPolicies mengembalikan kebijakan semua kelas data (default jika belum diatur).
+	FindPolicies() ([]model.RetentionPolicy, error)
+	// UpsertPolicy menyimpan masa retensi 1 kelas data.
+	UpsertPolicy(policy *model.RetentionPolicy) error
+	// FindDueForAnonymization mengambil mahasiswa yang lulus sebelum/pada cutoff dan belum dianonimkan.
+	FindDueForAnonymization(cutoff time.Time, limit int) ([]RetentionCandidate, error)
+	// AnonymizeStudent menganonimkan data pribadi 1 mahasiswa secara permanen.
+	// Mengembalikan false jika mahasiswa sudah dianonimkan sebelumnya.
+	AnonymizeStudent(ctx context.Context, studentID uuid.UUID) (bool, error)
+}
+
+type retentionRepository struct {
+	pgDB    *gorm.DB
+	mongoDB *mongo.Database
+}
+
+// NewRetentionRepository membuat instance RetentionRepository.
+func NewRetentionRepository(pgDB *gorm.DB, mongoDB *mongo.Database) RetentionRepository {
+	return &retentionRepository{pgDB: pgDB, mongoDB: mongoDB}
+}
+
+// FindPolicies lihat dokumentasi di interface.
+func (r *retentionRepository) FindPolicies() ([]model.RetentionPolicy, error) {
+	var stored []model.RetentionPolicy
+	if err := r.pgDB.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byClass := make(map[string]model.RetentionPolicy, len(stored))
+	for _, p := range stored {
+		byClass[p.DataClass] = p
+	}
+
+	policies := make([]model.RetentionPolicy, 0, len(DefaultRetentionYears))
+	for class, years := range DefaultRetentionYears {
+		if p, ok := byClass[class]; ok {
+			policies = append(policies, p)
+			continue
+		}
+		policies = append(policies, model.RetentionPolicy{DataClass: class, RetentionYears: years})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].DataClass < policies[j].DataClass })
+	return policies, nil
+}
+
+// UpsertPolicy lihat dokumentasi di interface.
+func (r *retentionRepository) UpsertPolicy(policy *model.RetentionPolicy) error {
+	if _, ok := DefaultRetentionYears[policy.DataClass]; !ok {
+		return ErrUnknownDataClass
+	}
+	return r.pgDB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "data_class"}},
+		DoUpdates: clause.AssignmentColumns([]string{"retention_years", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+}
+
+// FindDueForAnonymization lihat dokumentasi di interface.
+func (r *retentionRepository) FindDueForAnonymization(cutoff time.Time, limit int) ([]RetentionCandidate, error) {
+	var rows []RetentionCandidate
+	err := r.pgDB.Table("students AS s").
+		Select("s.id AS student_id, s.user_id, s.student_id AS nim, u.full_name, s.graduated_at").
+		Joins("JOIN users u ON u.id = s.user_id").
+		Where("s.graduated_at IS NOT NULL AND s.graduated_at <= ? AND s.anonymized_at IS NULL", cutoff).
+		Order("s.graduated_at ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// AnonymizeStudent lihat dokumentasi di interface.
+//
+// Urutan: Mongo dulu (idempotent), lalu Postgres dalam 1 transaksi yang mengisi anonymized_at.
+// Jika langkah Postgres gagal, run berikutnya mengulang keduanya tanpa efek ganda.
+// Dokumen & poin prestasi tetap ada, sehingga total statistik historis tidak berubah.
+func (r *retentionRepository) AnonymizeStudent(ctx context.Context, studentID uuid.UUID) (bool, error) {
+	_, err := r.mongoDB.Collection("achievements").UpdateMany(ctx,
+		bson.M{"studentId": studentID},
+		bson.M{
+			"$unset": bson.M{"details.authors": "", "details.certificationNumber": ""},
+			"$set":   bson.M{"anonymized": true},
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("mongo anonymize failed: %w", err)
+	}
+
+	done := false
+	err = r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var st model.Student
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND anonymized_at IS NULL", studentID).
+			First(&st).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // sudah dianonimkan (atau tidak ada)
+		}
+		if err != nil {
+			return err
+		}
+
+		token, err := randomHex(8)
+		if err != nil {
+			return err
+		}
+		hashedNIM, err := irreversibleHash(st.StudentID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		if err := tx.Model(&model.User{}).Where("id = ?", st.UserID).Updates(map[string]any{
+			"username":      "anon_" + token,
+			"email":         "anon_" + token + "@anonymized.invalid",
+			"full_name":     "Anonim " + token,
+			"password_hash": "!", // bukan hash bcrypt valid: login tidak mungkin
+			"is_active":     false,
+			"updated_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Student{}).Where("id = ?", st.ID).Updates(map[string]any{
+			"student_id":    hashedNIM,
+			"phone_number":  nil,
+			"anonymized_at": now,
+			"updated_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// randomHex menghasilkan n byte acak dalam bentuk hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// irreversibleHash meng-hash nilai dengan salt acak yang tidak disimpan, sehingga
+// hasilnya tidak bisa dicocokkan balik (NIM berpola pendek mudah di-brute-force tanpa salt).
+// Dipotong 20 karakter mengikuti panjang kolom students.student_id.
+func irreversibleHash(value string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append(salt, value...))
+	return hex.EncodeToString(sum[:])[:20], nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestIrreversibleHash: NIM yang sama menghasilkan hash berbeda setiap kali (salt acak tidak
+// disimpan) dan tidak sama dengan hash tanpa salt, sehingga tidak bisa dicocokkan balik.
+func TestIrreversibleHash(t *testing.T) {
+	const nim = "2021001234"
+	unsalted := sha256.Sum256([]byte(nim))
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		h, err := irreversibleHash(nim)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(h) != 20 || strings.Contains(h, nim) || h == hex.EncodeToString(unsalted[:])[:20] {
+			t.Fatalf("hash %q bisa dicocokkan dengan NIM", h)
+		}
+		if seen[h] {
+			t.Fatalf("hash %q berulang untuk NIM yang sama", h)
+		}
+		seen[h] = true
+	}
+}
+
+// retentionFixture: mahasiswa + 1 dokumen prestasi berisi field personal.
+type retentionFixture struct {
+	student model.Student
+	user    model.User
+	mongoID primitive.ObjectID
+}
+
+// TestAnonymizeStudent: hanya mahasiswa yang lulus melewati masa retensi yang dianonimkan;
+// data pribadinya tidak bisa dipulihkan, poin & jumlah prestasinya tetap, mahasiswa lain tidak tersentuh.
+func TestAnonymizeStudent(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+	repo := NewRetentionRepository(pgDB, mongoDB)
+	reports := NewReportRepository(mongoDB)
+	achievements := mongoDB.Collection("achievements")
+	now := time.Now()
+
+	setup := func(graduatedAt *time.Time) retentionFixture {
+		st := createTestStudent(t, pgDB)
+		if err := pgDB.Model(&model.Student{}).Where("id = ?", st.ID).Update("graduated_at", graduatedAt).Error; err != nil {
+			t.Fatal(err)
+		}
+		f := retentionFixture{mongoID: primitive.NewObjectID()}
+		if err := pgDB.Preload("User").First(&f.student, "id = ?", st.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		f.user = f.student.User
+		doc := bson.M{"_id": f.mongoID, "studentId": st.ID, "achievementType": "publication", "title": "Paper", "points": 7.5,
+			"details": bson.M{"authors": bson.A{f.user.FullName}, "certificationNumber": "C-1", "publicationTitle": "Paper"}}
+		if _, err := achievements.InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	findRetentionDoc := func(t *testing.T, id primitive.ObjectID) (doc struct {
+		Anonymized bool   `bson:"anonymized"`
+		Details    bson.M `bson:"details"`
+	}) {
+		t.Helper()
+		if err := achievements.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	longAgo, recently := now.AddDate(-6, 0, 0), now.AddDate(-1, 0, 0)
+	due := setup(&longAgo)
+	notDue := setup(&recently)
+	notGraduated := setup(nil)
+
+	portfolio := func(f retentionFixture) *PortfolioResult {
+		t.Helper()
+		res, err := reports.GetPortfolio(ctx, f.student.ID, []string{f.mongoID.Hex()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	before := portfolio(due)
+	totalBefore, err := achievements.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := repo.FindDueForAnonymization(now.AddDate(-5, 0, 0), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[uuid.UUID]bool{}
+	for _, c := range candidates {
+		found[c.StudentID] = true
+	}
+	if !found[due.student.ID] || found[notDue.student.ID] || found[notGraduated.student.ID] {
+		t.Fatalf("kandidat %+v, want hanya %s", candidates, due.student.ID)
+	}
+
+	done, err := repo.AnonymizeStudent(ctx, due.student.ID)
+	if err != nil || !done {
+		t.Fatalf("AnonymizeStudent = %v, %v", done, err)
+	}
+	if done, err := repo.AnonymizeStudent(ctx, due.student.ID); err != nil || done {
+		t.Fatalf("anonimisasi ulang = %v, %v, want false (sudah dianonimkan)", done, err)
+	}
+
+	// Data pribadi diganti permanen: tidak ada nilai lama yang tersisa atau bisa diturunkan.
+	var st model.Student
+	if err := pgDB.Preload("User").First(&st, "id = ?", due.student.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	unsalted := sha256.Sum256([]byte(due.student.StudentID))
+	if st.AnonymizedAt == nil || st.PhoneNumber != nil || st.StudentID == due.student.StudentID ||
+		st.StudentID == hex.EncodeToString(unsalted[:])[:20] {
+		t.Fatalf("mahasiswa tidak dianonimkan: %+v", st)
+	}
+	u := st.User
+	if u.Username == due.user.Username || u.Email == due.user.Email || u.FullName == due.user.FullName ||
+		!strings.HasPrefix(u.Username, "anon_") || u.PasswordHash != "!" || u.IsActive {
+		t.Fatalf("user tidak dianonimkan: %+v", u)
+	}
+	doc := findRetentionDoc(t, due.mongoID)
+	_, hasAuthors := doc.Details["authors"]
+	_, hasCertNumber := doc.Details["certificationNumber"]
+	if hasAuthors || hasCertNumber || !doc.Anonymized || doc.Details["publicationTitle"] != "Paper" {
+		t.Fatalf("dokumen prestasi masih berisi data pribadi: %+v", doc)
+	}
+
+	// Statistik tidak berubah: dokumen & poin tetap ada.
+	after := portfolio(due)
+	if after.TotalAchievements != before.TotalAchievements || after.TotalPoints != before.TotalPoints {
+		t.Fatalf("portofolio %+v setelah anonimisasi, want %+v", after, before)
+	}
+	if total, err := achievements.CountDocuments(ctx, bson.M{}); err != nil || total != totalBefore {
+		t.Fatalf("total dokumen %d (%v), want %d", total, err, totalBefore)
+	}
+
+	// Mahasiswa yang belum jatuh tempo tidak tersentuh.
+	for _, f := range []retentionFixture{notDue, notGraduated} {
+		var other model.Student
+		if err := pgDB.Preload("User").First(&other, "id = ?", f.student.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if other.AnonymizedAt != nil || other.StudentID != f.student.StudentID ||
+			other.User.Username != f.user.Username || other.User.FullName != f.user.FullName || !other.User.IsActive {
+			t.Fatalf("mahasiswa %s ikut berubah: %+v", f.student.ID, other)
+		}
+		if otherDoc := findRetentionDoc(t, f.mongoID); otherDoc.Details["authors"] == nil || otherDoc.Anonymized {
+			t.Fatalf("dokumen mahasiswa %s ikut dianonimkan: %+v", f.student.ID, otherDoc)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository mengelola denylist access token (tabel revoked_tokens).
+type RevokedTokenRepository interface {
+	// Revoke memasukkan jti ke denylist sampai expiresAt (idempoten).
+	Revoke(jti string, userID uuid.UUID, expiresAt time.Time) error
+	// IsRevoked true jika jti ada di denylist.
+	IsRevoked(jti string) (bool, error)
+	// AnyRevoked true jika salah satu kunci (jti atau utils.SessionRevocationKey) ada di denylist.
+	AnyRevoked(keys ...string) (bool, error)
+	// DeleteExpired menghapus baris yang token-nya sudah kedaluwarsa; mengembalikan jumlahnya.
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository membuat instance RevokedTokenRepository.
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{db}
+}
+
+func (r *revokedTokenRepository) Revoke(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (r *revokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var row model.RevokedToken
+	err := r.db.Select("jti").Where("jti = ?", jti).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *revokedTokenRepository) AnyRevoked(keys ...string) (bool, error) {
+	if len(keys) == 0 {
+		return false, nil
+	}
+	var row model.RevokedToken
+	err := r.db.Select("jti").Where("jti IN ?", keys).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *revokedTokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", now).Delete(&model.RevokedToken{})
+	return res.RowsAffected, res.Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"student-achievement-backend/app/model"
 
 	"github.com/google/uuid"
@@ -12,7 +14,11 @@ import (
 type StudentRepository interface {
 	FindAll() ([]model.Student, error)                 // GET /students
 	FindByID(id uuid.UUID) (*model.Student, error)     // GET /students/:id
+	// FindByIDs mengambil banyak mahasiswa (+User) sekaligus dengan 1 query (identitas di list prestasi).
+	FindByIDs(ids []uuid.UUID) ([]model.Student, error)
 	UpdateAdvisor(studentID, advisorID uuid.UUID) error // PUT /students/:id/advisor
+	// SetGraduatedAt mengisi/menghapus tanggal lulus (mahasiswa yang sudah dianonimkan dilewati).
+	SetGraduatedAt(studentIDs []uuid.UUID, graduatedAt *time.Time) (int64, error)
 }
 
 type studentRepository struct {
@@ -23,10 +29,10 @@ func NewStudentRepository(db *gorm.DB) StudentRepository {
 	return &studentRepository{db}
 }
 
-// FindAll mengembalikan semua mahasiswa.
+// FindAll mengembalikan semua mahasiswa (kecuali yang sudah dianonimkan).
 func (r *studentRepository) FindAll() ([]model.Student, error) {
 	var students []model.Student
-	err := r.db.Find(&students).Error
+	err := r.db.Where("anonymized_at IS NULL").Find(&students).Error
 	return students, err
 }
 
@@ -42,9 +48,27 @@ func (r *studentRepository) FindByID(id uuid.UUID) (*model.Student, error) {
 	return &st, nil
 }
 
+// FindByIDs mengembalikan mahasiswa dengan ID pada daftar, beserta data User-nya.
+func (r *studentRepository) FindByIDs(ids []uuid.UUID) ([]model.Student, error) {
+	students := []model.Student{}
+	if len(ids) == 0 {
+		return students, nil
+	}
+	err := r.db.Preload("User").Where("id IN ?", ids).Find(&students).Error
+	return students, err
+}
+
 // UpdateAdvisor mengganti dosen wali mahasiswa.
 func (r *studentRepository) UpdateAdvisor(studentID, advisorID uuid.UUID) error {
 	return r.db.Model(&model.Student{}).
 		Where("id = ?", studentID).
 		Update("advisor_id", advisorID).Error
 }
+
+// SetGraduatedAt mengisi tanggal lulus untuk 1 atau banyak mahasiswa sekaligus.
+func (r *studentRepository) SetGraduatedAt(studentIDs []uuid.UUID, graduatedAt *time.Time) (int64, error) {
+	res := r.db.Model(&model.Student{}).
+		Where("id IN ? AND anonymized_at IS NULL", studentIDs).
+		Updates(map[string]any{"graduated_at": graduatedAt, "updated_at": time.Now()})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestFindByIDs: banyak mahasiswa diambil sekaligus beserta User; ID yang tidak ada dilewati.
+func TestFindByIDs(t *testing.T) {
+	db := openTestPostgres(t)
+	a, b := createTestStudent(t, db), createTestStudent(t, db)
+	repo := NewStudentRepository(db)
+
+	students, err := repo.FindByIDs([]uuid.UUID{a.ID, b.ID, uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(students) != 2 {
+		t.Fatalf("%d mahasiswa, want 2", len(students))
+	}
+	for _, st := range students {
+		if st.User.FullName == "" || st.StudentID == "" {
+			t.Fatalf("mahasiswa %s tanpa identitas: %+v", st.ID, st)
+		}
+	}
+
+	empty, err := repo.FindByIDs(nil)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Fatalf("FindByIDs(nil) = %v, %v, want [] tanpa error", empty, err)
+	}
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TargetProgress adalah progres 1 mahasiswa terhadap target prestasi tahunan.
+type TargetProgress struct {
+	StudentID     uuid.UUID `json:"studentId"`
+	AcademicYear  string    `json:"academicYear"` // contoh: "2024/2025"
+	Target        int       `json:"target"`
+	VerifiedCount int64     `json:"verifiedCount"`
+	BehindBy      int64     `json:"behindBy"` // 0 jika target sudah tercapai
+}
+
+// TargetRepository menangani pengaturan target prestasi (tabel achievement_targets)
+// dan perhitungan jumlah prestasi verified per mahasiswa.
+type TargetRepository interface {
+	FindAll() ([]model.AchievementTarget, error)
+	// Upsert menyimpan target; programStudy "" berarti default global.
+	Upsert(programStudy string, verifiedPerYear int) (*model.AchievementTarget, error)
+	// CountVerifiedBetween menghitung prestasi verified per mahasiswa dengan verified_at di [from, to).
+	// studentIDs kosong → semua mahasiswa.
+	CountVerifiedBetween(studentIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]int64, error)
+}
+
+type targetRepository struct {
+	db *gorm.DB
+}
+
+func NewTargetRepository(db *gorm.DB) TargetRepository {
+	return &targetRepository{db}
+}
+
+// FindAll mengembalikan semua pengaturan target (global + per program studi).
+func (r *targetRepository) FindAll() ([]model.AchievementTarget, error) {
+	var list []model.AchievementTarget
+	err := r.db.Order("program_study ASC").Find(&list).Error
+	return list, err
+}
+
+// Upsert membuat / memperbarui target untuk program studi.
+func (r *targetRepository) Upsert(programStudy string, verifiedPerYear int) (*model.AchievementTarget, error) {
+	t := model.AchievementTarget{
+		ProgramStudy:    programStudy,
+		VerifiedPerYear: verifiedPerYear,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "program_study"}},
+		DoUpdates: clause.AssignmentColumns([]string{"verified_per_year", "updated_at"}),
+	}).Create(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CountVerifiedBetween lihat dokumentasi di interface.
+func (r *targetRepository) CountVerifiedBetween(studentIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		StudentID uuid.UUID
+		Total     int64
+	}
+
+	q := r.db.Model(&model.AchievementReference{}).
+		Select("student_id, COUNT(*) AS total").
+		Where("status = ?", model.StatusVerified).
+		Where("verified_at >= ? AND verified_at < ?", from, to)
+	if len(studentIDs) > 0 {
+		q = q.Where("student_id IN ?", studentIDs)
+	}
+	if err := q.Group("student_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		out[row.StudentID] = row.Total
+	}
+	return out, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// TestCountVerifiedBetweenBoundaries: prestasi dihitung pada [awal, akhir) tahun akademik —
+// verified tepat di awal ikut, tepat di akhir masuk tahun berikutnya; status selain verified diabaikan.
+func TestCountVerifiedBetweenBoundaries(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := NewTargetRepository(pgDB)
+	t.Setenv("ACADEMIC_YEAR_START_MONTH", "8")
+	year := utils.AcademicYearStarting(2024, time.UTC)
+
+	a, b := createTestStudent(t, pgDB), createTestStudent(t, pgDB)
+	seed := func(student model.Student, status string, verifiedAt time.Time) {
+		t.Helper()
+		ref := model.AchievementReference{StudentID: student.ID, MongoAchievementID: uuid.NewString(),
+			Status: status, VerifiedAt: &verifiedAt}
+		if err := pgDB.Create(&ref).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed(a, model.StatusVerified, year.Start)                        // awal tahun akademik: dihitung
+	seed(a, model.StatusVerified, year.End.Add(-time.Microsecond))   // detik terakhir: dihitung
+	seed(a, model.StatusVerified, year.End)                          // awal tahun berikutnya
+	seed(a, model.StatusVerified, year.Start.Add(-time.Microsecond)) // akhir tahun sebelumnya
+	seed(a, model.StatusRejected, year.Start.Add(24*time.Hour))      // bukan verified
+	seed(b, model.StatusVerified, year.Start.Add(30*24*time.Hour))
+
+	tests := []struct {
+		name string
+		ids  []uuid.UUID
+		want map[uuid.UUID]int64
+	}{
+		{name: "1 mahasiswa", ids: []uuid.UUID{a.ID}, want: map[uuid.UUID]int64{a.ID: 2}},
+		{name: "beberapa mahasiswa", ids: []uuid.UUID{a.ID, b.ID}, want: map[uuid.UUID]int64{a.ID: 2, b.ID: 1}},
+		{name: "semua mahasiswa", want: map[uuid.UUID]int64{a.ID: 2, b.ID: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.CountVerifiedBetween(tt.ids, year.Start, year.End)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.ids != nil && len(got) != len(tt.want) {
+				t.Fatalf("hasil %v, want %v", got, tt.want)
+			}
+			for id, n := range tt.want {
+				if got[id] != n {
+					t.Fatalf("mahasiswa %s: %d verified, want %d", id, got[id], n)
+				}
+			}
+		})
+	}
+
+	// Tahun akademik berikutnya dimulai tepat di year.End.
+	next, err := r.CountVerifiedBetween([]uuid.UUID{a.ID}, year.End, year.End.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next[a.ID] != 1 {
+		t.Fatalf("tahun berikutnya: %d verified, want 1", next[a.ID])
+	}
+}
+
+// TestTargetUpsertOverride: menyimpan target prodi yang sama 2x memperbarui baris yang ada.
+func TestTargetUpsertOverride(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	r := NewTargetRepository(pgDB)
+	program := "Prodi Uji " + uuid.NewString()[:8]
+	t.Cleanup(func() { pgDB.Delete(&model.AchievementTarget{}, "program_study = ?", program) })
+
+	for _, n := range []int{3, 5} {
+		if _, err := r.Upsert(program, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := r.FindAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []model.AchievementTarget
+	for _, row := range rows {
+		if row.ProgramStudy == program {
+			found = append(found, row)
+		}
+	}
+	if len(found) != 1 || found[0].VerifiedPerYear != 5 {
+		t.Fatalf("baris target %+v, want 1 baris bernilai 5", found)
+	}
+}
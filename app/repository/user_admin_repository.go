@@ -1,11 +1,16 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
 	"time"
 
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserAdminRepository: khusus untuk fitur admin (FR-009)
@@ -20,15 +25,25 @@ type UserAdminRepository interface {
 	CreateStudentProfile(s *model.Student) error
 	CreateLecturerProfile(l *model.Lecturer) error
 
+	// Deteksi & merge akun ganda
+	FindUserProfiles() ([]UserProfileRow, error)
+	FindStudentByUserID(userID uuid.UUID) (*model.Student, error)
+	MergeUsers(ctx context.Context, primaryID, secondaryID uuid.UUID) (*MergeSummary, error)
+	LinkLecturerProfile(userID uuid.UUID, lecturer *model.Lecturer) error
+
 	// ❌ SetStudentAdvisor dihapus karena sekarang ada di StudentService + StudentRepository
 }
 
 type userAdminRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	docs *achievementRepository // dual-write dokumen prestasi Mongo saat merge akun
 }
 
-func NewUserAdminRepository(db *gorm.DB) UserAdminRepository {
-	return &userAdminRepository{db}
+func NewUserAdminRepository(db *gorm.DB, mongoDB *mongo.Database) UserAdminRepository {
+	return &userAdminRepository{
+		db:   db,
+		docs: &achievementRepository{pgDB: db, mongoDB: mongoDB, mongoTx: &mongoTxSupport{}},
+	}
 }
 
 // CreateUser → FR-009: admin membuat user baru
@@ -62,14 +77,20 @@ func (r *userAdminRepository) FindUserByID(id uuid.UUID) (*model.User, error) {
 func (r *userAdminRepository) SoftDeleteUser(id uuid.UUID) error {
 	return r.db.Model(&model.User{}).
 		Where("id = ?", id).
-		Update("is_active", false).Error
+		Updates(map[string]interface{}{
+			"is_active":     false,
+			"token_version": gorm.Expr("token_version + 1"), // token yang beredar langsung ditolak
+		}).Error
 }
 
 // UpdateUserRole → ganti role user
 func (r *userAdminRepository) UpdateUserRole(id uuid.UUID, roleID uuid.UUID) error {
 	return r.db.Model(&model.User{}).
 		Where("id = ?", id).
-		Update("role_id", roleID).Error
+		Updates(map[string]interface{}{
+			"role_id":       roleID,
+			"token_version": gorm.Expr("token_version + 1"), // token lama membawa role lama
+		}).Error
 }
 
 // CreateStudentProfile → buat profil mahasiswa (NIM, Prodi, dst)
@@ -81,3 +102,253 @@ func (r *userAdminRepository) CreateStudentProfile(s *model.Student) error {
 func (r *userAdminRepository) CreateLecturerProfile(l *model.Lecturer) error {
 	return r.db.Create(l).Error
 }
+
+// UserProfileRow adalah ringkasan user + kode profil (NIM / kode dosen) untuk deteksi akun ganda.
+type UserProfileRow struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	FullName    string    `json:"fullName"`
+	IsActive    bool      `json:"isActive"`
+	StudentNIM  *string   `json:"studentId,omitempty"`
+	LecturerNIP *string   `json:"lecturerId,omitempty"`
+}
+
+// MergeSummary merangkum perubahan yang dilakukan MergeUsers.
+type MergeSummary struct {
+	MovedStudentProfile     bool   `json:"movedStudentProfile"`
+	MovedLecturerProfile    bool   `json:"movedLecturerProfile"`
+	ReassignedAchievements  int64  `json:"reassignedAchievements"`
+	ReassignedAdvisees      int64  `json:"reassignedAdvisees"`
+	ReassignedVerifications int64  `json:"reassignedVerifications"`
+	Role                    string `json:"role"`        // role user primer setelah merge
+	RoleChanged             bool   `json:"roleChanged"` // true jika role primer diganti
+}
+
+// FindUserProfiles mengambil semua user beserta NIM / kode dosen (LEFT JOIN).
+func (r *userAdminRepository) FindUserProfiles() ([]UserProfileRow, error) {
+	var rows []UserProfileRow
+	err := r.db.Table("users u").
+		Select("u.id, u.username, u.email, u.full_name, u.is_active, s.student_id AS student_nim, l.lecturer_id AS lecturer_nip").
+		Joins("LEFT JOIN students s ON s.user_id = u.id").
+		Joins("LEFT JOIN lecturers l ON l.user_id = u.id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindStudentByUserID mengambil profil mahasiswa milik user (nil jika tidak ada).
+func (r *userAdminRepository) FindStudentByUserID(userID uuid.UUID) (*model.Student, error) {
+	var st model.Student
+	err := r.db.Where("user_id = ?", userID).Limit(1).Find(&st).Error
+	if err != nil || st.ID == uuid.Nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// MergeUsers memindahkan seluruh jejak user sekunder ke user primer sebagai 1 dual-write:
+//   - baris users, students & lecturers kedua akun dikunci (FOR UPDATE) lebih dulu
+//   - profil mahasiswa: dipindah ke primer; jika primer sudah punya profil, prestasinya yang dipindah
+//     (termasuk studentId dokumen Mongo, dijalankan setelah baris Postgres terkunci)
+//   - profil dosen: dipindah ke primer; jika primer sudah punya profil, mahasiswa bimbingannya yang dipindah
+//   - verified_by pada achievement_references
+//   - role primer disesuaikan dengan profil hasil merge (admin tidak diubah)
+//   - user sekunder dinonaktifkan; token_version kedua akun dinaikkan jika sesinya harus berakhir
+func (r *userAdminRepository) MergeUsers(ctx context.Context, primaryID, secondaryID uuid.UUID) (summary *MergeSummary, err error) {
+	ctx, span := utils.StartSpan(ctx, "user.merge")
+	defer func() { utils.EndSpan(span, err) }()
+
+	summary = &MergeSummary{}
+	var primStu, secStu model.Student
+	var primLect, secLect model.Lecturer
+	var docIDs []string
+
+	err = r.docs.runDualWrite(ctx, span, dualWrite{
+		lock: func(tx *gorm.DB) error {
+			var users []model.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id IN ?", []uuid.UUID{primaryID, secondaryID}).
+				Order("id").Find(&users).Error; err != nil {
+				return err
+			}
+			if len(users) != 2 {
+				return gorm.ErrRecordNotFound
+			}
+			for _, q := range []struct {
+				dst    any
+				userID uuid.UUID
+			}{{&primStu, primaryID}, {&secStu, secondaryID}, {&primLect, primaryID}, {&secLect, secondaryID}} {
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", q.userID).Limit(1).Find(q.dst).Error; err != nil {
+					return err
+				}
+			}
+			if primStu.ID == uuid.Nil || secStu.ID == uuid.Nil {
+				return nil
+			}
+			return tx.Model(&model.AchievementReference{}).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("student_id = ?", secStu.ID).
+				Pluck("mongo_achievement_id", &docIDs).Error
+		},
+		mongo: func(ctx context.Context) error {
+			return r.docs.SetDocumentsStudent(ctx, docIDs, primStu.ID)
+		},
+		pg: func(tx *gorm.DB) error {
+			return mergeUsersTx(tx, primaryID, secondaryID, &primStu, &secStu, &primLect, &secLect, summary)
+		},
+		undo: func(ctx context.Context) error {
+			return r.docs.SetDocumentsStudent(ctx, docIDs, secStu.ID)
+		},
+		undoEvent: "mongo_merge_reverted",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// mergeUsersTx menjalankan bagian Postgres dari MergeUsers (baris sudah dikunci).
+func mergeUsersTx(tx *gorm.DB, primaryID, secondaryID uuid.UUID, primStu, secStu *model.Student,
+	primLect, secLect *model.Lecturer, summary *MergeSummary) error {
+	// 1. Profil mahasiswa
+	hasStudent := primStu.ID != uuid.Nil
+	if secStu.ID != uuid.Nil {
+		hasStudent = true
+		if primStu.ID == uuid.Nil {
+			if err := tx.Model(&model.Student{}).Where("id = ?", secStu.ID).
+				Update("user_id", primaryID).Error; err != nil {
+				return err
+			}
+			summary.MovedStudentProfile = true
+		} else {
+			res := tx.Model(&model.AchievementReference{}).
+				Where("student_id = ?", secStu.ID).
+				Update("student_id", primStu.ID)
+			if res.Error != nil {
+				return res.Error
+			}
+			summary.ReassignedAchievements = res.RowsAffected
+		}
+	}
+
+	// 2. Profil dosen
+	hasLecturer := primLect.ID != uuid.Nil
+	if secLect.ID != uuid.Nil {
+		hasLecturer = true
+		if primLect.ID == uuid.Nil {
+			if err := tx.Model(&model.Lecturer{}).Where("id = ?", secLect.ID).
+				Update("user_id", primaryID).Error; err != nil {
+				return err
+			}
+			summary.MovedLecturerProfile = true
+		} else {
+			res := tx.Model(&model.Student{}).
+				Where("advisor_id = ?", secLect.ID).
+				Update("advisor_id", primLect.ID)
+			if res.Error != nil {
+				return res.Error
+			}
+			summary.ReassignedAdvisees = res.RowsAffected
+		}
+	}
+
+	// 3. Riwayat verifikasi
+	res := tx.Model(&model.AchievementReference{}).
+		Where("verified_by = ?", secondaryID).
+		Update("verified_by", primaryID)
+	if res.Error != nil {
+		return res.Error
+	}
+	summary.ReassignedVerifications = res.RowsAffected
+
+	// 4. Role primer mengikuti profil hasil merge
+	var primary model.User
+	if err := tx.Preload("Role").First(&primary, "id = ?", primaryID).Error; err != nil {
+		return err
+	}
+	summary.Role = mergedRoleName(primary.Role.Name, hasStudent, hasLecturer)
+	if summary.Role != primary.Role.Name {
+		var role model.Role
+		if err := tx.Where("name = ?", summary.Role).First(&role).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", primaryID).
+			Updates(map[string]any{
+				"role_id":       role.ID,
+				"token_version": gorm.Expr("token_version + 1"),
+				"updated_at":    time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		summary.RoleChanged = true
+	}
+
+	// 5. Nonaktifkan user sekunder (token lamanya langsung tidak berlaku)
+	return tx.Model(&model.User{}).
+		Where("id = ?", secondaryID).
+		Updates(map[string]interface{}{
+			"is_active":     false,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// mergedRoleName menentukan role user primer setelah merge: profil dosen → dosen_wali
+// (sama seperti LinkLecturerProfile), hanya profil mahasiswa → mahasiswa. Admin tetap admin.
+func mergedRoleName(current string, hasStudent, hasLecturer bool) string {
+	switch {
+	case current == "admin":
+		return current
+	case hasLecturer:
+		return "dosen_wali"
+	case hasStudent:
+		return "mahasiswa"
+	default:
+		return current
+	}
+}
+
+// Error untuk penautan akun mahasiswa → dosen.
+var (
+	ErrNoStudentProfile     = errors.New("user has no student profile")
+	ErrLecturerProfileExist = errors.New("user already has a lecturer profile")
+)
+
+// LinkLecturerProfile menautkan profil dosen baru ke user yang sudah punya profil mahasiswa
+// (mantan mahasiswa yang menjadi dosen). Dalam 1 transaksi:
+//   - profil mahasiswa dipertahankan apa adanya (riwayat prestasi tidak berpindah),
+//   - profil dosen dibuat dengan user_id yang sama,
+//   - role user diganti menjadi dosen_wali (profil mahasiswa otomatis read-only).
+func (r *userAdminRepository) LinkLecturerProfile(userID uuid.UUID, lecturer *model.Lecturer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var students int64
+		if err := tx.Model(&model.Student{}).Where("user_id = ?", userID).Count(&students).Error; err != nil {
+			return err
+		}
+		if students == 0 {
+			return ErrNoStudentProfile
+		}
+
+		var lecturers int64
+		if err := tx.Model(&model.Lecturer{}).Where("user_id = ?", userID).Count(&lecturers).Error; err != nil {
+			return err
+		}
+		if lecturers > 0 {
+			return ErrLecturerProfileExist
+		}
+
+		var role model.Role
+		if err := tx.Where("name = ?", "dosen_wali").First(&role).Error; err != nil {
+			return err
+		}
+
+		lecturer.UserID = userID
+		if err := tx.Create(lecturer).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{"role_id": role.ID, "updated_at": time.Now()}).Error
+	})
+}
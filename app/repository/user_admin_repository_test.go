@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
+)
+
+func TestMergedRoleName(t *testing.T) {
+	tests := []struct {
+		name                    string
+		current                 string
+		hasStudent, hasLecturer bool
+		want                    string
+	}{
+		{name: "mahasiswa mendapat profil dosen", current: "mahasiswa", hasStudent: true, hasLecturer: true, want: "dosen_wali"},
+		{name: "tanpa profil mendapat profil mahasiswa", current: "dosen_wali", hasStudent: true, want: "mahasiswa"},
+		{name: "dosen tetap dosen", current: "dosen_wali", hasLecturer: true, want: "dosen_wali"},
+		{name: "admin tidak diubah", current: "admin", hasStudent: true, hasLecturer: true, want: "admin"},
+		{name: "tanpa profil tidak diubah", current: "mahasiswa", want: "mahasiswa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergedRoleName(tt.current, tt.hasStudent, tt.hasLecturer); got != tt.want {
+				t.Fatalf("mergedRoleName(%q, %v, %v) = %q, want %q", tt.current, tt.hasStudent, tt.hasLecturer, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeUsersMovesDocumentsAndRole: prestasi (reference + dokumen Mongo) akun sekunder pindah
+// ke profil mahasiswa primer, profil dosen sekunder pindah sehingga role primer menjadi dosen_wali,
+// dan akun sekunder dinonaktifkan dengan token_version naik.
+func TestMergeUsersMovesDocumentsAndRole(t *testing.T) {
+	pgDB := openTestPostgres(t)
+	mongoDB := openTestMongo(t)
+	ctx := context.Background()
+
+	roles := map[string]model.Role{}
+	for _, name := range []string{"mahasiswa", "dosen_wali"} {
+		role := model.Role{Name: name}
+		if err := pgDB.Where("name = ?", name).FirstOrCreate(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		roles[name] = role
+	}
+
+	suffix := uuid.NewString()[:8]
+	newUser := func(name string) model.User {
+		u := model.User{Username: name + suffix, Email: name + suffix + "@kampus.ac.id", PasswordHash: "x",
+			FullName: name, RoleID: roles["mahasiswa"].ID, IsActive: true}
+		if err := pgDB.Create(&u).Error; err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	primary, secondary := newUser("primer"), newUser("sekunder")
+	primStu := model.Student{UserID: primary.ID, StudentID: "P" + suffix}
+	secStu := model.Student{UserID: secondary.ID, StudentID: "S" + suffix}
+	secLect := model.Lecturer{UserID: secondary.ID, LecturerID: "L" + suffix}
+	for _, row := range []any{&primStu, &secStu, &secLect} {
+		if err := pgDB.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := mongoDB.Collection("achievements").InsertOne(ctx, bson.M{"studentId": secStu.ID, "title": "Juara"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := model.AchievementReference{StudentID: secStu.ID, MongoAchievementID: res.InsertedID.(primitive.ObjectID).Hex(), Status: "draft"}
+	if err := pgDB.Create(&ref).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pgDB.Delete(&model.AchievementReference{}, "id = ?", ref.ID)
+		pgDB.Delete(&model.Lecturer{}, "id = ?", secLect.ID)
+		pgDB.Delete(&model.Student{}, "id IN ?", []uuid.UUID{primStu.ID, secStu.ID})
+		pgDB.Delete(&model.User{}, "id IN ?", []uuid.UUID{primary.ID, secondary.ID})
+	})
+
+	summary, err := NewUserAdminRepository(pgDB, mongoDB).MergeUsers(ctx, primary.ID, secondary.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ReassignedAchievements != 1 || !summary.MovedLecturerProfile || summary.Role != "dosen_wali" || !summary.RoleChanged {
+		t.Fatalf("summary tidak sesuai: %+v", summary)
+	}
+
+	var doc struct {
+		StudentID uuid.UUID `bson:"studentId"`
+	}
+	if err := mongoDB.Collection("achievements").FindOne(ctx, bson.M{"_id": res.InsertedID}).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.StudentID != primStu.ID {
+		t.Fatalf("studentId dokumen = %s, want %s", doc.StudentID, primStu.ID)
+	}
+
+	var users []model.User
+	if err := pgDB.Where("id IN ?", []uuid.UUID{primary.ID, secondary.ID}).Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range users {
+		switch u.ID {
+		case primary.ID:
+			if u.RoleID != roles["dosen_wali"].ID || u.TokenVersion != 1 {
+				t.Fatalf("user primer: role %s tv %d, want dosen_wali & 1", u.RoleID, u.TokenVersion)
+			}
+		case secondary.ID:
+			if u.IsActive || u.TokenVersion != 1 {
+				t.Fatalf("user sekunder: active %v tv %d, want false & 1", u.IsActive, u.TokenVersion)
+			}
+		}
+	}
+
+	// User yang tidak ada: tidak ada yang berubah.
+	if _, err := NewUserAdminRepository(pgDB, mongoDB).MergeUsers(ctx, primary.ID, uuid.New()); err != gorm.ErrRecordNotFound {
+		t.Fatalf("merge dengan user tidak ada: err = %v, want ErrRecordNotFound", err)
+	}
+}
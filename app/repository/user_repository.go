@@ -1,6 +1,11 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
 	"student-achievement-backend/app/model"
 
 	"github.com/google/uuid"
@@ -14,6 +19,49 @@ type UserRepository interface {
 	FindByUsername(username string) (*model.User, error)
 	FindByID(id uuid.UUID) (*model.User, error)
 	FindStudentByUserID(userID uuid.UUID) (*model.Student, error)
+	FindStudentByID(id uuid.UUID) (*model.Student, error)
+	FindLecturerByUserID(userID uuid.UUID) (*model.Lecturer, error)
+	// UpdatePassword mengganti password & mencatatnya di password_history
+	// (dipangkas ke PasswordHistoryDepth entri terakhir).
+	UpdatePassword(userID uuid.UUID, passwordHash string) error
+	// UpgradePasswordHash mengganti hash dengan hash baru untuk password yang SAMA (cost lebih
+	// tinggi): tanpa riwayat password / token_version. Tidak berubah jika hash sudah diganti.
+	UpgradePasswordHash(userID uuid.UUID, oldHash, newHash string) error
+	// FindTokenVersion mengembalikan users.token_version (dibandingkan dengan klaim "tv").
+	FindTokenVersion(userID uuid.UUID) (int, error)
+	// RecentPasswordHashes mengembalikan hash password terakhir user (terbaru dulu).
+	RecentPasswordHashes(userID uuid.UUID, limit int) ([]string, error)
+	UpdateLastLogin(userID uuid.UUID, at time.Time, ip string) error
+	// UpdateProfile mengubah data profil milik user sendiri (field nil tidak diubah).
+	// Mengembalikan ErrEmailTaken jika email sudah dipakai user lain.
+	UpdateProfile(userID uuid.UUID, update ProfileUpdate) error
+	// SaveTwoFactorSetup menyimpan secret (terenkripsi) & hash kode pemulihan baru; 2FA
+	// belum aktif sampai EnableTwoFactor.
+	SaveTwoFactorSetup(userID uuid.UUID, encryptedSecret string, recoveryHashes []string) error
+	EnableTwoFactor(userID uuid.UUID, step int64) error
+	// UseTwoFactorStep mencatat langkah TOTP yang dipakai; false jika langkah tsb
+	// (atau yang lebih baru) sudah pernah dipakai.
+	UseTwoFactorStep(userID uuid.UUID, step int64) (bool, error)
+	// ConsumeRecoveryCode menghapus 1 hash kode pemulihan; false jika tidak ada.
+	ConsumeRecoveryCode(userID uuid.UUID, hash string) (bool, error)
+	// ResetTwoFactor (admin) menonaktifkan 2FA dan menghapus secret & kode pemulihan;
+	// token_version dinaikkan sehingga sesi yang beredar harus login ulang.
+	// false jika 2FA user memang belum aktif.
+	ResetTwoFactor(userID uuid.UUID) (bool, error)
+}
+
+// PasswordHistoryDepth adalah jumlah password terakhir yang tidak boleh dipakai ulang.
+const PasswordHistoryDepth = 5
+
+// ErrEmailTaken dikembalikan UpdateProfile jika email melanggar unique constraint.
+var ErrEmailTaken = errors.New("email already used by another user")
+
+// ProfileUpdate berisi field profil yang boleh diubah pemilik akun.
+// Role, permission, NIM dan dosen wali sengaja tidak ada di sini.
+type ProfileUpdate struct {
+	FullName    *string
+	Email       *string
+	PhoneNumber *string // hanya untuk akun dengan profil mahasiswa; "" = hapus nomor
 }
 
 // userRepository adalah implementasi konkret UserRepository berbasis GORM.
@@ -82,3 +130,181 @@ func (r *userRepository) FindStudentByUserID(userID uuid.UUID) (*model.Student,
 	}
 	return &s, nil
 }
+
+// FindStudentByID mencari profil mahasiswa berdasarkan students.id.
+func (r *userRepository) FindStudentByID(id uuid.UUID) (*model.Student, error) {
+	var s model.Student
+	err := r.db.Where("id = ?", id).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FindLecturerByUserID mencari profil dosen berdasarkan user_id.
+func (r *userRepository) FindLecturerByUserID(userID uuid.UUID) (*model.Lecturer, error) {
+	var l model.Lecturer
+	err := r.db.Where("user_id = ?", userID).First(&l).Error
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// UpdatePassword menyimpan hash password baru dan menghapus flag must_change_password.
+func (r *userRepository) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"password_hash":        passwordHash,
+				"must_change_password": false,
+				"token_version":        gorm.Expr("token_version + 1"), // sesi lain harus login ulang
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&model.PasswordHistory{UserID: userID, Hash: passwordHash}).Error; err != nil {
+			return err
+		}
+
+		// Pangkas riwayat: sisakan PasswordHistoryDepth entri terbaru.
+		return tx.Exec(`
+			DELETE FROM password_history
+			WHERE user_id = ? AND id NOT IN (
+				SELECT id FROM password_history
+				WHERE user_id = ?
+				ORDER BY created_at DESC
+				LIMIT ?
+			)`, userID, userID, PasswordHistoryDepth).Error
+	})
+}
+
+// UpgradePasswordHash mengganti hash lama dengan hash ber-cost lebih tinggi (password sama).
+func (r *userRepository) UpgradePasswordHash(userID uuid.UUID, oldHash, newHash string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND password_hash = ?", userID, oldHash).
+		UpdateColumn("password_hash", newHash).Error
+}
+
+// FindTokenVersion mengambil users.token_version (untuk mencabut token lama).
+func (r *userRepository) FindTokenVersion(userID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Select("token_version").
+		Take(&version).Error
+	return version, err
+}
+
+// RecentPasswordHashes mengambil hash dari password_history, terbaru dulu (maksimal limit).
+func (r *userRepository) RecentPasswordHashes(userID uuid.UUID, limit int) ([]string, error) {
+	hashes := []string{}
+	err := r.db.Model(&model.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("hash", &hashes).Error
+	return hashes, err
+}
+
+// UpdateLastLogin mencatat waktu & IP login terakhir. UpdateColumns dipakai agar
+// updated_at tidak ikut berubah (login bukan perubahan data user).
+func (r *userRepository) UpdateLastLogin(userID uuid.UUID, at time.Time, ip string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		UpdateColumns(map[string]interface{}{
+			"last_login_at": at,
+			"last_login_ip": ip,
+		}).Error
+}
+
+// UpdateProfile mengubah nama/email (users) dan nomor telepon (students) dalam 1 transaksi.
+func (r *userRepository) UpdateProfile(userID uuid.UUID, update ProfileUpdate) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		fields := map[string]interface{}{}
+		if update.FullName != nil {
+			fields["full_name"] = *update.FullName
+		}
+		if update.Email != nil {
+			fields["email"] = *update.Email
+		}
+		if len(fields) > 0 {
+			if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+
+		if update.PhoneNumber != nil {
+			var phone interface{}
+			if *update.PhoneNumber != "" {
+				phone = *update.PhoneNumber
+			}
+			if err := tx.Model(&model.Student{}).Where("user_id = ?", userID).
+				Update("phone_number", phone).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// 23505 = unique_violation (email dipakai user lain di antara cek & update)
+	if err != nil && strings.Contains(err.Error(), "23505") {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// SaveTwoFactorSetup menyimpan secret TOTP terenkripsi & hash kode pemulihan (2FA belum aktif).
+func (r *userRepository) SaveTwoFactorSetup(userID uuid.UUID, encryptedSecret string, recoveryHashes []string) error {
+	codes, err := json.Marshal(recoveryHashes)
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND two_factor_enabled = ?", userID, false).
+		Updates(map[string]interface{}{
+			"two_factor_secret":         encryptedSecret,
+			"two_factor_recovery_codes": string(codes),
+			"two_factor_last_step":      0,
+		}).Error
+}
+
+// EnableTwoFactor mengaktifkan 2FA dan mencatat langkah TOTP pertama yang dipakai.
+func (r *userRepository) EnableTwoFactor(userID uuid.UUID, step int64) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"two_factor_enabled":   true,
+			"two_factor_last_step": step,
+		}).Error
+}
+
+// UseTwoFactorStep menaikkan two_factor_last_step; false jika langkah sudah pernah dipakai.
+func (r *userRepository) UseTwoFactorStep(userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.Model(&model.User{}).
+		Where("id = ? AND two_factor_last_step < ?", userID, step).
+		UpdateColumn("two_factor_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+// ResetTwoFactor menonaktifkan 2FA, menghapus secret & kode pemulihan, dan menaikkan token_version.
+func (r *userRepository) ResetTwoFactor(userID uuid.UUID) (bool, error) {
+	res := r.db.Model(&model.User{}).
+		Where("id = ? AND two_factor_enabled = ?", userID, true).
+		Updates(map[string]interface{}{
+			"two_factor_enabled":        false,
+			"two_factor_secret":         "",
+			"two_factor_recovery_codes": "[]",
+			"two_factor_last_step":      0,
+			"token_version":             gorm.Expr("token_version + 1"),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ConsumeRecoveryCode menghapus 1 hash kode pemulihan secara atomik; false jika tidak ditemukan.
+func (r *userRepository) ConsumeRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	res := r.db.Model(&model.User{}).
+		Where("id = ? AND jsonb_exists(two_factor_recovery_codes, ?)", userID, hash).
+		UpdateColumn("two_factor_recovery_codes", gorm.Expr("two_factor_recovery_codes - ?::text", hash))
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxPointsOverride membaca batas atas override poin dari env MAX_POINTS_OVERRIDE (default 100).
+func maxPointsOverride() int {
+	return utils.Runtime().MaxPointsOverride
+}
+
+// ===============================================================
+//  ADMIN: OverridePoints
+//  Endpoint: PUT /api/v1/admin/achievements/:id/points-override
+//  Body: { "points": 25, "reason": "..." }
+//  - points: 0..MAX_POINTS_OVERRIDE
+//  - reason: wajib diisi
+// ===============================================================
+func (s *achievementService) OverridePoints(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi admin diperlukan", "no_user_id", nil)
+		return
+	}
+
+	var input struct {
+		Points *float64 `json:"points" binding:"required"`
+		Reason string   `json:"reason" binding:"required"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input override poin tidak valid", err.Error(), nil)
+		return
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Alasan override wajib diisi", "reason_required", nil)
+		return
+	}
+
+	limit := maxPointsOverride()
+	if *input.Points < 0 || *input.Points > float64(limit) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			fmt.Sprintf("Poin override harus di antara 0 dan %d", limit),
+			"points_out_of_range",
+			map[string]any{"maxPointsOverride": limit})
+		return
+	}
+
+	id := ctx.Param("id")
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	detail, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Detail prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	// Isi prestasi sebelum override disimpan sebagai revisi: revert mengembalikan poin dari sini.
+	rev := revisionOf(ctx, detail)
+	if err := s.revisionRepo.Create(ctx.Request.Context(), rev); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan revisi sebelum override", err.Error(), nil)
+		return
+	}
+
+	// Nilai asli tetap nilai sebelum override pertama (override berulang tidak menimpa nilai asli).
+	original := detail.Points
+	if detail.PointsOverride != nil {
+		original = detail.PointsOverride.OriginalPoints
+	}
+
+	override := model.PointsOverride{
+		OriginalPoints: original,
+		Points:         *input.Points,
+		Reason:         reason,
+		ActorID:        adminID,
+		OverriddenAt:   time.Now(),
+		RevisionID:     rev.ID,
+		Previous:       detail.PointsOverride,
+	}
+
+	if err := s.repo.SetPointsOverride(ctx, id, override); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan override poin", err.Error(), nil)
+		return
+	}
+
+	s.recordAudit(ctx, adminID, "achievement.points_override", id, map[string]any{
+		"oldPoints":  detail.Points,
+		"newPoints":  override.Points,
+		"reason":     reason,
+		"revisionId": rev.ID.Hex(),
+	})
+
+	utils.RespondOK(ctx,
+		"Poin prestasi berhasil di-override", map[string]any{
+			"id":               ref.ID,
+			"points":           override.Points,
+			"originalPoints":   override.OriginalPoints,
+			"pointsOverridden": true,
+		})
+}
+
+// ===============================================================
+//  ADMIN: RevertPointsOverride
+//  Endpoint: DELETE /api/v1/admin/achievements/:id/points-override
+//  - Mengembalikan poin ke nilai sebelum override.
+// ===============================================================
+func (s *achievementService) RevertPointsOverride(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi admin diperlukan", "no_user_id", nil)
+		return
+	}
+
+	id := ctx.Param("id")
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	detail, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Detail prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	if detail.PointsOverride == nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Prestasi ini tidak memiliki override poin", "no_override", nil)
+		return
+	}
+
+	current := detail.PointsOverride
+	restored, err := s.pointsBeforeOverride(ctx, current)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membaca revisi sebelum override", err.Error(), nil)
+		return
+	}
+
+	// Override sebelumnya (jika ada) dipasang kembali; jika tidak, poin kembali normal.
+	if current.Previous != nil {
+		previous := *current.Previous
+		previous.Points = restored
+		err = s.repo.SetPointsOverride(ctx, id, previous)
+	} else {
+		err = s.repo.ClearPointsOverride(ctx, id, restored)
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membatalkan override poin", err.Error(), nil)
+		return
+	}
+
+	s.recordAudit(ctx, adminID, "achievement.points_override_revert", id, map[string]any{
+		"oldPoints":  detail.Points,
+		"newPoints":  restored,
+		"revisionId": current.RevisionID.Hex(),
+	})
+
+	utils.RespondOK(ctx,
+		"Override poin berhasil dibatalkan", map[string]any{
+			"id":               ref.ID,
+			"points":           restored,
+			"pointsOverridden": current.Previous != nil,
+		})
+}
+
+// pointsBeforeOverride mengambil poin tepat sebelum override o dari revisinya. Override lama
+// (tanpa revisi) atau revisi yang sudah terpangkas memakai nilai yang tersimpan di override.
+func (s *achievementService) pointsBeforeOverride(ctx *gin.Context, o *model.PointsOverride) (float64, error) {
+	fallback := o.OriginalPoints
+	if o.Previous != nil {
+		fallback = o.Previous.Points
+	}
+	if o.RevisionID.IsZero() {
+		return fallback, nil
+	}
+	rev, err := s.revisionRepo.FindByID(ctx.Request.Context(), o.RevisionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fallback, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rev.Points, nil
+}
+
+// recordAudit mencatat audit setelah perubahan tersimpan. Perubahan tidak dibatalkan jika
+// pencatatan gagal, tetapi kegagalannya di-log agar celah jejak audit terlihat.
+func (s *achievementService) recordAudit(ctx *gin.Context, actorID uuid.UUID, action, achievementID string, payload map[string]any) {
+	if err := s.auditRepo.Record(ctx, &actorID, action, "achievement", achievementID, payload); err != nil {
+		log.Printf("⚠️  gagal mencatat audit %s prestasi %s: %v", action, achievementID, err)
+	}
+}
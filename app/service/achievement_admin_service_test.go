@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakePointsRepo menambah dokumen Mongo (1 per reference) ke fakeAchievementRepo untuk
+// alur override poin.
+type fakePointsRepo struct {
+	*fakeAchievementRepo
+	docs map[string]*model.Achievement // mongo id → dokumen
+}
+
+func (r *fakePointsRepo) FindDetailByMongoID(_ context.Context, mongoID string) (*model.Achievement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.docs[mongoID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	cp := *doc
+	return &cp, nil
+}
+
+func (r *fakePointsRepo) doc(achievementID string) *model.Achievement {
+	return r.docs[r.refs[uuid.MustParse(achievementID)].MongoAchievementID]
+}
+
+func (r *fakePointsRepo) SetPointsOverride(_ context.Context, achievementID string, override model.PointsOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc := r.doc(achievementID)
+	doc.Points, doc.PointsOverride = override.Points, &override
+	return nil
+}
+
+func (r *fakePointsRepo) ClearPointsOverride(_ context.Context, achievementID string, points float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc := r.doc(achievementID)
+	doc.Points, doc.PointsOverride = points, nil
+	return nil
+}
+
+// fakeRevisionRepo menyimpan revisi di memori; prune mensimulasikan revisi terpangkas.
+type fakeRevisionRepo struct {
+	repository.AchievementRevisionRepository
+	mu   sync.Mutex
+	revs map[primitive.ObjectID]model.AchievementRevision
+}
+
+func (r *fakeRevisionRepo) Create(_ context.Context, rev *model.AchievementRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rev.ID = primitive.NewObjectID()
+	r.revs[rev.ID] = *rev
+	return nil
+}
+
+func (r *fakeRevisionRepo) FindByID(_ context.Context, id primitive.ObjectID) (*model.AchievementRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rev, ok := r.revs[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &rev, nil
+}
+
+func (r *fakeRevisionRepo) prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.revs)
+}
+
+// failingAuditRepo selalu gagal mencatat audit.
+type failingAuditRepo struct {
+	repository.AuditRepository
+}
+
+func (failingAuditRepo) Record(context.Context, *uuid.UUID, string, string, string, any) error {
+	return errors.New("audit_logs tidak tersedia")
+}
+
+func TestPointsOverrideRevert(t *testing.T) {
+	type step struct {
+		action         string  // "override", "revert", "edit" (poin diubah langsung), "prune"
+		points         float64 // untuk override / edit
+		wantPoints     float64
+		wantOverridden bool
+	}
+	tests := []struct {
+		name  string
+		audit repository.AuditRepository
+		steps []step
+	}{
+		{name: "override lalu revert", steps: []step{
+			{action: "override", points: 30, wantPoints: 30, wantOverridden: true},
+			{action: "revert", wantPoints: 10},
+		}},
+		{name: "override berulang di-revert satu per satu", steps: []step{
+			{action: "override", points: 30, wantPoints: 30, wantOverridden: true},
+			{action: "override", points: 50, wantPoints: 50, wantOverridden: true},
+			{action: "revert", wantPoints: 30, wantOverridden: true},
+			{action: "revert", wantPoints: 10},
+		}},
+		{name: "override baru setelah poin berubah", steps: []step{
+			{action: "override", points: 30, wantPoints: 30, wantOverridden: true},
+			{action: "revert", wantPoints: 10},
+			{action: "edit", points: 15, wantPoints: 15},
+			{action: "override", points: 40, wantPoints: 40, wantOverridden: true},
+			{action: "revert", wantPoints: 15},
+		}},
+		{name: "revisi terpangkas memakai nilai cadangan", steps: []step{
+			{action: "override", points: 30, wantPoints: 30, wantOverridden: true},
+			{action: "override", points: 50, wantPoints: 50, wantOverridden: true},
+			{action: "prune", wantPoints: 50, wantOverridden: true},
+			{action: "revert", wantPoints: 30, wantOverridden: true},
+			{action: "revert", wantPoints: 10},
+		}},
+		{name: "audit gagal tidak membatalkan perubahan", audit: failingAuditRepo{}, steps: []step{
+			{action: "override", points: 30, wantPoints: 30, wantOverridden: true},
+			{action: "revert", wantPoints: 10},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mongoID := primitive.NewObjectID()
+			ref := &model.AchievementReference{ID: uuid.New(), MongoAchievementID: mongoID.Hex(), Status: model.StatusVerified}
+			repo := &fakePointsRepo{
+				fakeAchievementRepo: newFakeAchievementRepo(ref),
+				docs:                map[string]*model.Achievement{mongoID.Hex(): {ID: mongoID, Points: 10}},
+			}
+			revisions := &fakeRevisionRepo{revs: map[primitive.ObjectID]model.AchievementRevision{}}
+			audit := tt.audit
+			if audit == nil {
+				audit = &fakeAuditRepo{}
+			}
+			svc := NewAchievementService(repo, nil, nil, nil, audit, nil, nil, nil, nil, revisions, nil, nil)
+
+			r := gin.New()
+			admin := func(c *gin.Context) {
+				c.Set("role", "admin")
+				c.Set("userID", uuid.New())
+			}
+			r.PUT("/achievements/:id/points-override", admin, svc.OverridePoints)
+			r.DELETE("/achievements/:id/points-override", admin, svc.RevertPointsOverride)
+			path := "/achievements/" + ref.ID.String() + "/points-override"
+
+			for i, st := range tt.steps {
+				switch st.action {
+				case "override":
+					w, _ := doJSON(t, r, http.MethodPut, path, "", map[string]any{"points": st.points, "reason": "koreksi"})
+					if w.Code != http.StatusOK {
+						t.Fatalf("langkah %d override: status %d, body %s", i, w.Code, w.Body)
+					}
+				case "revert":
+					w, _ := doJSON(t, r, http.MethodDelete, path, "", nil)
+					if w.Code != http.StatusOK {
+						t.Fatalf("langkah %d revert: status %d, body %s", i, w.Code, w.Body)
+					}
+				case "edit":
+					repo.docs[mongoID.Hex()].Points = st.points
+				case "prune":
+					revisions.prune()
+				}
+				doc := repo.docs[mongoID.Hex()]
+				if doc.Points != st.wantPoints || (doc.PointsOverride != nil) != st.wantOverridden {
+					t.Fatalf("langkah %d (%s): points %v overridden %v, want %v %v",
+						i, st.action, doc.Points, doc.PointsOverride != nil, st.wantPoints, st.wantOverridden)
+				}
+			}
+		})
+	}
+}
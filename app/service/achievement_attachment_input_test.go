@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeEditRepo mencatat dokumen yang dikirim ke UpdateContent.
+type fakeEditRepo struct {
+	*fakePointsRepo
+	updated *model.Achievement
+}
+
+func (r *fakeEditRepo) UpdateContent(_ context.Context, _ string, doc *model.Achievement) error {
+	r.updated = doc
+	return nil
+}
+
+type fakeNoRuleRepo struct {
+	repository.PointRuleRepository
+}
+
+func (fakeNoRuleRepo) FindAll(string) ([]model.PointRule, error) { return nil, nil }
+
+// TestAchievementInputRejectsAttachmentMetadata: metadata lampiran dari client (fileUrl bebas,
+// tanpa id dari server) tidak pernah tersimpan lewat create maupun update.
+func TestAchievementInputRejectsAttachmentMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	injected := []map[string]any{{"fileName": "palsu.pdf", "fileUrl": "https://contoh.invalid/palsu.pdf", "fileType": "pdf"}}
+	types := &fakeTypeRepo{types: []model.AchievementType{{Code: "seminar", Active: true}}}
+
+	t.Run("create menolak field attachments", func(t *testing.T) {
+		repo := &fakeCreateRepo{}
+		svc := NewAchievementService(repo, fakeStudentRepo{}, nil, nil, nil, nil, nil, fakeNoRuleRepo{}, types, nil, nil, nil)
+		r := gin.New()
+		r.POST("/achievements", func(c *gin.Context) { c.Set("role", "admin") }, svc.CreateAchievement)
+
+		w, _ := doJSON(t, r, http.MethodPost, "/achievements", "", map[string]any{
+			"studentId": uuid.NewString(), "achievementType": "seminar", "title": "Seminar", "attachments": injected,
+		})
+		if w.Code != http.StatusBadRequest || repo.created != nil {
+			t.Fatalf("status %d, body %s, want 400 tanpa menyimpan", w.Code, w.Body)
+		}
+
+		w, _ = doJSON(t, r, http.MethodPost, "/achievements", "", map[string]any{
+			"studentId": uuid.NewString(), "achievementType": "seminar", "title": "Seminar",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status %d, body %s", w.Code, w.Body)
+		}
+		if repo.created.Attachments == nil || len(repo.created.Attachments) != 0 {
+			t.Fatalf("lampiran awal %#v, want [] (bukan null, agar $push upload berhasil)", repo.created.Attachments)
+		}
+	})
+
+	t.Run("update mengabaikan field attachments", func(t *testing.T) {
+		studentID, mongoID := uuid.New(), primitive.NewObjectID()
+		ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusDraft, MongoAchievementID: mongoID.Hex()}
+		stored := &model.Achievement{ID: mongoID, StudentID: studentID, Title: "Seminar",
+			Attachments: []model.Attachment{{ID: "att-1", FileName: "asli.pdf", FileURL: "/uploads/x/asli.pdf"}}}
+		repo := &fakeEditRepo{fakePointsRepo: &fakePointsRepo{
+			fakeAchievementRepo: newFakeAchievementRepo(ref),
+			docs:                map[string]*model.Achievement{mongoID.Hex(): stored},
+		}}
+		revisions := &fakeRevisionRepo{revs: map[primitive.ObjectID]model.AchievementRevision{}}
+		svc := NewAchievementService(repo, nil, nil, nil, nil, nil, nil, fakeNoRuleRepo{}, types, revisions, nil, nil)
+		r := gin.New()
+		r.PUT("/achievements/:id", func(c *gin.Context) {
+			c.Set("role", "mahasiswa")
+			c.Set("studentID", studentID)
+		}, svc.UpdateAchievement)
+
+		w, _ := doJSON(t, r, http.MethodPut, "/achievements/"+ref.ID.String(), "", map[string]any{
+			"achievementType": "seminar", "title": "Seminar nasional", "attachments": injected,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d, body %s", w.Code, w.Body)
+		}
+		if repo.updated == nil || repo.updated.Title != "Seminar nasional" {
+			t.Fatalf("UpdateContent tidak dipanggil dengan isi baru: %+v", repo.updated)
+		}
+		if len(repo.updated.Attachments) != 0 {
+			t.Fatalf("lampiran dari client diteruskan ke repository: %+v", repo.updated.Attachments)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeLifecycleRepo meniru soft-delete, restore, dan purge repository asli di memori:
+// status deleted menandai semua lampiran, restore menghapus tandanya, purge menghapus data.
+type fakeLifecycleRepo struct {
+	*fakePointsRepo
+}
+
+func (r *fakeLifecycleRepo) UpdateStatus(ctx context.Context, id string, status string, opts repository.UpdateStatusOptions) error {
+	if err := r.fakeAchievementRepo.UpdateStatus(ctx, id, status, opts); err != nil {
+		return err
+	}
+	if status == model.StatusDeleted {
+		r.setAttachmentsDeleted(id, true)
+	}
+	return nil
+}
+
+func (r *fakeLifecycleRepo) setAttachmentsDeleted(id string, deleted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc := r.doc(id)
+	for i := range doc.Attachments {
+		doc.Attachments[i].Deleted = deleted
+	}
+}
+
+func (r *fakeLifecycleRepo) FindDocumentByReference(_ context.Context, id string) (*model.Achievement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref := r.refs[uuid.MustParse(id)]
+	if ref == nil || r.docs[ref.MongoAchievementID] == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	cp := *r.docs[ref.MongoAchievementID]
+	cp.Attachments = append([]model.Attachment(nil), cp.Attachments...)
+	return &cp, nil
+}
+
+func (r *fakeLifecycleRepo) Restore(_ context.Context, id string, _ uuid.UUID) error {
+	r.mu.Lock()
+	ref := r.refs[uuid.MustParse(id)]
+	if ref.Status != model.StatusDeleted {
+		r.mu.Unlock()
+		return errors.New("achievement is not deleted")
+	}
+	ref.Status = model.StatusDraft
+	r.mu.Unlock()
+	r.setAttachmentsDeleted(id, false)
+	return nil
+}
+
+func (r *fakeLifecycleRepo) Purge(_ context.Context, id string, opts repository.PurgeOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uid := uuid.MustParse(id)
+	ref := r.refs[uid]
+	if !opts.Hard && ref.Status != model.StatusDeleted {
+		return repository.ErrPurgeNotDeleted
+	}
+	delete(r.docs, ref.MongoAchievementID)
+	delete(r.refs, uid)
+	return nil
+}
+
+func (r *fakeLifecycleRepo) CountDraftsByStudent(studentID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+	for _, ref := range r.refs {
+		if ref.StudentID == studentID && ref.Status == model.StatusDraft {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// TestAttachmentLifecycle: lampiran prestasi yang dihapus mahasiswa tidak bisa diunduh (410) dan
+// draftnya keluar dari kuota, restore admin mengembalikan unduhan utuh, dan purge menghapus file fisik.
+func TestAttachmentLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	studentID, mongoID := uuid.New(), primitive.NewObjectID()
+	ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusDraft, MongoAchievementID: mongoID.Hex()}
+	other := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusDraft, MongoAchievementID: primitive.NewObjectID().Hex()}
+	id := ref.ID.String()
+	repo := &fakeLifecycleRepo{&fakePointsRepo{
+		fakeAchievementRepo: newFakeAchievementRepo(ref, other),
+		docs: map[string]*model.Achievement{mongoID.Hex(): {ID: mongoID, StudentID: studentID, Title: "Lomba",
+			Attachments: []model.Attachment{{ID: "att-1", FileName: "sertifikat.pdf", FileType: "pdf",
+				FileURL: "/uploads/achievements/" + id + "/a1.pdf", UploadedAt: time.Now()}}}},
+	}}
+
+	base := t.TempDir()
+	storage := &utils.LocalStorage{BaseDir: base, TempDir: filepath.Join(base, ".tmp")}
+	dir := filepath.Join(base, "achievements", id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a1.pdf"), []byte("isi sertifikat"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	audit := &fakeAuditRepo{}
+	svc := NewAchievementService(repo, nil, nil, nil, audit, nil, nil, nil, nil, nil, storage, nil)
+	as := func(role string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("role", role)
+			c.Set("userID", uuid.New())
+			c.Set("studentID", studentID)
+		}
+	}
+	r := gin.New()
+	r.GET("/achievements/:id/attachments/:fileName", as("mahasiswa"), svc.DownloadAttachment)
+	r.DELETE("/achievements/:id", as("mahasiswa"), svc.DeleteAchievement)
+	r.POST("/admin/achievements/:id/restore", as("admin"), svc.RestoreAchievement)
+	r.DELETE("/admin/achievements/:id", as("admin"), svc.PurgeAchievement)
+
+	call := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+	download := func() *httptest.ResponseRecorder {
+		return call(http.MethodGet, "/achievements/"+id+"/attachments/a1.pdf")
+	}
+	drafts := func() int64 {
+		n, _ := repo.CountDraftsByStudent(studentID)
+		return n
+	}
+
+	if w := download(); w.Code != http.StatusOK || w.Body.String() != "isi sertifikat" {
+		t.Fatalf("unduh awal: status %d, body %s", w.Code, w.Body)
+	}
+	if got := drafts(); got != 2 {
+		t.Fatalf("draft awal %d, want 2", got)
+	}
+
+	// Soft-delete: unduhan 410, draft keluar dari kuota, file tetap ada untuk restore.
+	if w := call(http.MethodDelete, "/achievements/"+id); w.Code != http.StatusOK {
+		t.Fatalf("delete: status %d, body %s", w.Code, w.Body)
+	}
+	if w := download(); w.Code != http.StatusGone {
+		t.Fatalf("unduh setelah delete: status %d, body %s, want 410", w.Code, w.Body)
+	}
+	if got := drafts(); got != 1 {
+		t.Fatalf("draft setelah delete %d, want 1", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a1.pdf")); err != nil {
+		t.Fatalf("file hilang sebelum purge: %v", err)
+	}
+
+	// Restore: unduhan kembali berisi file yang sama, draft kembali dihitung.
+	if w := call(http.MethodPost, "/admin/achievements/"+id+"/restore"); w.Code != http.StatusOK {
+		t.Fatalf("restore: status %d, body %s", w.Code, w.Body)
+	}
+	if w := download(); w.Code != http.StatusOK {
+		t.Fatalf("unduh setelah restore: status %d, body %s", w.Code, w.Body)
+	} else if body, _ := io.ReadAll(w.Body); string(body) != "isi sertifikat" {
+		t.Fatalf("isi setelah restore %q", body)
+	}
+	if got := drafts(); got != 2 {
+		t.Fatalf("draft setelah restore %d, want 2", got)
+	}
+
+	// Purge: hapus lagi lalu purge; folder lampiran hilang dari disk dan unduhan 404.
+	if w := call(http.MethodDelete, "/achievements/"+id); w.Code != http.StatusOK {
+		t.Fatalf("delete kedua: status %d, body %s", w.Code, w.Body)
+	}
+	if w := call(http.MethodDelete, "/admin/achievements/"+id); w.Code != http.StatusOK {
+		t.Fatalf("purge: status %d, body %s", w.Code, w.Body)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("folder lampiran masih ada setelah purge (err %v)", err)
+	}
+	if w := download(); w.Code != http.StatusNotFound {
+		t.Fatalf("unduh setelah purge: status %d, body %s, want 404", w.Code, w.Body)
+	}
+	if got := drafts(); got != 1 {
+		t.Fatalf("draft setelah purge %d, want 1", got)
+	}
+	last, _ := audit.payloads[len(audit.payloads)-1].(map[string]any)
+	if audit.actions[len(audit.actions)-1] != "achievement.purge" || last["filesRemoved"] != true {
+		t.Fatalf("audit purge %v %v", audit.actions, audit.payloads)
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"path/filepath"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// authorizeAchievementRead menerapkan aturan akses yang sama dengan DetailAchievement:
+// mahasiswa pemilik, dosen wali (atau delegasinya), dan admin. Response error ditulis di sini.
+func (s *achievementService) authorizeAchievementRead(ctx *gin.Context, ref *model.AchievementReference) bool {
+	switch getRoleFromContext(ctx) {
+	case "mahasiswa":
+		studentID, _ := getStudentIDFromContext(ctx)
+		if studentID == uuid.Nil || ref.StudentID != studentID {
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Anda tidak berhak mengakses prestasi ini", "forbidden", nil)
+			return false
+		}
+	case "dosen_wali":
+		if isOwnLinkedRecord(ctx, ref) {
+			break // riwayat prestasi sendiri sebagai mahasiswa (akun tertaut)
+		}
+		userID, _ := getUserIDFromContext(ctx)
+		lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Data dosen wali tidak ditemukan", err.Error(), nil)
+			return false
+		}
+		ok, _, err := s.checkAdvisorAccess(lecturerID, ref.StudentID)
+		if err != nil || !ok {
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil)
+			return false
+		}
+	case "admin":
+		// admin bebas
+	default:
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Role tidak berhak mengakses prestasi", "forbidden", nil)
+		return false
+	}
+	return true
+}
+
+// DownloadAttachment mengunduh 1 lampiran prestasi.
+// Endpoint: GET /api/v1/achievements/:id/attachments/:fileName
+// - 404 jika lampiran tidak ada
+// - 410 jika prestasi/lampiran sudah dihapus (file masih disimpan sampai purge)
+func (s *achievementService) DownloadAttachment(ctx *gin.Context) {
+	id := ctx.Param("id")
+	fileName := filepath.Base(ctx.Param("fileName"))
+
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+	if !s.authorizeAchievementRead(ctx, ref) {
+		return
+	}
+
+	doc, err := s.repo.FindDocumentByReference(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Dokumen prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	var attachment *model.Attachment
+	for i := range doc.Attachments {
+		if path.Base(doc.Attachments[i].FileURL) == fileName {
+			attachment = &doc.Attachments[i]
+			break
+		}
+	}
+	if attachment == nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Lampiran tidak ditemukan", "attachment_not_found", nil)
+		return
+	}
+	if ref.Status == model.StatusDeleted || attachment.Deleted {
+		utils.RespondError(ctx, http.StatusGone,
+			"Lampiran sudah dihapus", "attachment_deleted", nil)
+		return
+	}
+
+	f, err := s.storage.Open(filepath.Join("achievements", id, fileName))
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"File lampiran tidak ditemukan", err.Error(), nil)
+		return
+	}
+	defer f.Close()
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+attachment.FileName+`"`)
+	http.ServeContent(ctx.Writer, ctx.Request, attachment.FileName, attachment.UploadedAt, f)
+}
+
+// DeleteAttachment menghapus 1 lampiran yang salah unggah.
+// Endpoint: DELETE /api/v1/achievements/:id/attachments/:attachmentId
+// - Hanya mahasiswa pemilik, dan hanya saat status draft atau rejected (selain itu 409)
+// - 404 jika id lampiran tidak ada pada prestasi tersebut
+// - Metadata dihapus dari Mongo ($pull) lalu file dihapus dari storage
+func (s *achievementService) DeleteAttachment(ctx *gin.Context) {
+	if getRoleFromContext(ctx) != "mahasiswa" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat menghapus lampiran", "forbidden", nil)
+		return
+	}
+	studentID, err := getStudentIDFromContext(ctx)
+	if err != nil || studentID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
+		return
+	}
+
+	id := ctx.Param("id")
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+	if ref.StudentID != studentID {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak menghapus lampiran prestasi ini", "forbidden", nil)
+		return
+	}
+	if ref.Status != model.StatusDraft && ref.Status != model.StatusRejected {
+		utils.RespondError(ctx, http.StatusConflict,
+			"Lampiran hanya dapat dihapus saat prestasi berstatus draft atau rejected", "invalid_status",
+			map[string]any{"currentStatus": ref.Status})
+		return
+	}
+
+	removed, err := s.repo.RemoveAttachment(ctx.Request.Context(), id, ctx.Param("attachmentId"))
+	switch {
+	case errors.Is(err, repository.ErrAttachmentNotFound):
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Lampiran tidak ditemukan", "attachment_not_found", nil)
+		return
+	case errors.Is(err, repository.ErrContentLocked):
+		s.respondContentLocked(ctx, id)
+		return
+	case err != nil:
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menghapus lampiran", err.Error(), nil)
+		return
+	}
+
+	// Metadata sudah terhapus; kegagalan hapus file hanya dicatat di audit agar bisa dibersihkan manual.
+	userID, _ := getUserIDFromContext(ctx)
+	payload := map[string]any{"attachmentId": removed.ID, "fileName": removed.FileName}
+	if fileErr := s.storage.Remove(filepath.Join("achievements", id, path.Base(removed.FileURL))); fileErr != nil {
+		payload["fileError"] = fileErr.Error()
+	}
+	_ = s.auditRepo.Record(ctx, &userID, "achievement.attachment_delete", "achievement", id, payload)
+
+	utils.RespondOK(ctx,
+		"Lampiran berhasil dihapus", nil)
+}
+
+// RestoreAchievement (admin) mengembalikan prestasi yang di-soft-delete menjadi draft.
+// Endpoint: POST /api/v1/admin/achievements/:id/restore
+func (s *achievementService) RestoreAchievement(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+	adminID, _ := getUserIDFromContext(ctx)
+	if err := s.repo.Restore(ctx.Request.Context(), id, adminID); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Gagal memulihkan prestasi", err.Error(), nil)
+		return
+	}
+
+	_ = s.auditRepo.Record(ctx, &adminID, "achievement.restore", "achievement", id, nil)
+
+	utils.RespondOK(ctx,
+		"Prestasi berhasil dipulihkan", nil)
+}
+
+// PurgeAchievement (admin) menghapus permanen prestasi beserta file lampirannya di storage.
+// Endpoint: DELETE /api/v1/admin/achievements/:id[?hard=true[&force=true]]
+//   - tanpa hard: hanya prestasi yang sudah di-soft-delete
+//   - hard=true: status apa pun; prestasi verified wajib force=true (409 tanpa force)
+func (s *achievementService) PurgeAchievement(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	opts := repository.PurgeOptions{
+		Hard:          ctx.Query("hard") == "true",
+		AllowVerified: ctx.Query("force") == "true",
+	}
+	if err := s.repo.Purge(ctx.Request.Context(), id, opts); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPurgeVerified):
+			utils.RespondError(ctx, http.StatusConflict,
+				"Prestasi verified hanya bisa dihapus permanen dengan force=true", "verified_requires_force", nil)
+		case errors.Is(err, repository.ErrPurgeNotDeleted):
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Hanya prestasi yang sudah dihapus yang bisa dihapus permanen (gunakan hard=true)", "not_deleted", nil)
+		default:
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Gagal menghapus permanen prestasi", err.Error(), nil)
+		}
+		return
+	}
+
+	// Data sudah terhapus; kegagalan hapus file hanya dicatat di audit agar bisa dibersihkan manual.
+	fileErr := s.storage.RemoveDir(filepath.Join("achievements", id))
+
+	adminID, _ := getUserIDFromContext(ctx)
+	payload := map[string]any{
+		"filesRemoved":   fileErr == nil,
+		"hard":           opts.Hard,
+		"previousStatus": ref.Status,
+		"studentId":      ref.StudentID,
+	}
+	if opts.Hard && ref.Status == model.StatusVerified {
+		payload["forced"] = true
+	}
+	if fileErr != nil {
+		payload["fileError"] = fileErr.Error()
+	}
+	_ = s.auditRepo.Record(ctx, &adminID, "achievement.purge", "achievement", id, payload)
+
+	utils.RespondOK(ctx,
+		"Prestasi berhasil dihapus permanen", nil)
+}
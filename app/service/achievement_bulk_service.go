@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxBulkDecisionItems membatasi jumlah prestasi per request bulk.
+const maxBulkDecisionItems = 100
+
+// Hasil per item operasi bulk.
+const (
+	bulkOutcomeSuccess = "success"
+	bulkOutcomeError   = "error"
+)
+
+// bulkItemResult adalah hasil 1 item operasi bulk keputusan prestasi. Bentuknya sama untuk
+// semua endpoint bulk agar frontend bisa memakai 1 tampilan hasil.
+type bulkItemResult struct {
+	ID      string `json:"id"`
+	Outcome string `json:"outcome"`
+	Code    string `json:"code,omitempty"`    // kode error (sama dengan endpoint tunggal)
+	Message string `json:"message,omitempty"` // pesan error
+}
+
+// ===============================================================
+//  POST /api/v1/achievements/bulk-reject
+//  Body: { "ids": ["<uuid>", ...], "rejectionCategory": "insufficient_evidence",
+//          "rejectionNote": "Lampiran tidak terbaca, unggah ulang" }
+//  Dosen wali: setiap prestasi diperiksa seperti RejectAchievement dan diproses sendiri-sendiri;
+//  item yang gagal dilaporkan tanpa membatalkan item lain.
+// ===============================================================
+func (s *achievementService) BulkRejectAchievements(ctx *gin.Context) {
+	if getRoleFromContext(ctx) != "dosen_wali" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya dosen wali yang dapat menolak prestasi", "forbidden", nil)
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil || userID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi dosen wali diperlukan", "no_user_id", nil)
+		return
+	}
+
+	lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Data dosen wali tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	var input struct {
+		IDs               []string `json:"ids" binding:"required,min=1,max=100,dive,required"`
+		RejectionCategory string   `json:"rejectionCategory" binding:"required"`
+		RejectionNote     string   `json:"rejectionNote" binding:"required,min=10"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input tidak valid (ids 1-100, rejectionCategory wajib, rejectionNote minimal 10 karakter)", err.Error(),
+			map[string]any{"maxItems": maxBulkDecisionItems})
+		return
+	}
+	if !validateRejectionInput(ctx, input.RejectionCategory, input.RejectionNote) {
+		return
+	}
+
+	seen := map[string]bool{}
+	results := make([]bulkItemResult, 0, len(input.IDs))
+	summary := map[string]int{}
+	for _, id := range input.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		res := bulkItemResult{ID: id, Outcome: bulkOutcomeSuccess}
+		if delegateOf, ierr := s.checkRejectable(ctx, lecturerID, id); ierr != nil {
+			res = bulkItemResult{ID: id, Outcome: bulkOutcomeError, Code: ierr.code, Message: ierr.message}
+		} else if err := s.applyRejection(ctx, userID, lecturerID, id, delegateOf, input.RejectionCategory, input.RejectionNote, nil); errors.Is(err, repository.ErrStatusConflict) {
+			res = bulkItemResult{ID: id, Outcome: bulkOutcomeError, Code: "status_conflict",
+				Message: "Status prestasi sudah diubah oleh pengguna lain"}
+		} else if err != nil {
+			res = bulkItemResult{ID: id, Outcome: bulkOutcomeError, Code: "update_failed", Message: err.Error()}
+		}
+		summary[res.Outcome]++
+		results = append(results, res)
+	}
+
+	utils.RespondOK(ctx,
+		"Penolakan massal selesai diproses", map[string]any{
+			"total":   len(results),
+			"summary": summary,
+			"items":   results,
+		})
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===============================================================
+//  CERTIFICATE — bukti verifikasi prestasi (PDF)
+//  Endpoint: GET /api/v1/achievements/:id/certificate
+//  - Akses sama dengan DetailAchievement
+//  - Hanya prestasi verified; status lain → 409 + currentStatus
+//  - Kode verifikasi: utils.CertificateCode (id prestasi + waktu verifikasi)
+//  - NIM dimasking (utils.MaskPII) kecuali diunduh mahasiswa pemilik atau admin
+// ===============================================================
+func (s *achievementService) GetAchievementCertificate(ctx *gin.Context) {
+	ref, _, ok := s.findViewableAchievement(ctx)
+	if !ok {
+		return
+	}
+
+	if ref.Status != model.StatusVerified || ref.VerifiedAt == nil {
+		utils.RespondError(ctx, http.StatusConflict,
+			"Sertifikat hanya tersedia untuk prestasi yang sudah diverifikasi", "not_verified",
+			map[string]any{"currentStatus": ref.Status})
+		return
+	}
+
+	detail, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil detail prestasi", err.Error(), nil)
+		return
+	}
+
+	refs := []model.AchievementReference{*ref}
+	if err := s.repo.AttachIdentities(ctx.Request.Context(), refs); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data mahasiswa & verifier", err.Error(), nil)
+		return
+	}
+	owner := refs[0]
+
+	typeLabel := detail.AchievementType
+	if t, err := s.typeRepo.FindByCode(detail.AchievementType); err == nil {
+		typeLabel = t.Label
+	}
+	verifierName := "-"
+	if owner.Verifier != nil {
+		verifierName = owner.Verifier.FullName
+	}
+
+	role := getRoleFromContext(ctx)
+	pii := utils.MaskPII(role != "admin" && role != "mahasiswa", utils.PII{NIM: owner.Student.StudentID})
+
+	lines := []string{
+		"Dengan ini menyatakan bahwa prestasi berikut telah diverifikasi oleh dosen wali.",
+		"",
+		"Nama mahasiswa  : " + owner.Student.User.FullName,
+		"NIM             : " + pii.NIM,
+		"Program studi   : " + owner.Student.ProgramStudy,
+		"",
+		"Judul prestasi  : " + detail.Title,
+		"Tipe prestasi   : " + typeLabel,
+		fmt.Sprintf("Poin            : %g", detail.Points),
+		"",
+		"Tanggal verifikasi : " + ref.VerifiedAt.Format("02-01-2006"),
+		"Diverifikasi oleh  : " + verifierName,
+		"",
+		"Kode verifikasi : " + utils.CertificateCode(ref.ID.String(), *ref.VerifiedAt),
+		"ID prestasi     : " + ref.ID.String(),
+	}
+
+	ctx.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="sertifikat-prestasi-%s.pdf"`, ref.ID))
+	ctx.Data(http.StatusOK, "application/pdf",
+		utils.RenderTextPDF("Sertifikat Verifikasi Prestasi", lines))
+}
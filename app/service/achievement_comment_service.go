@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===============================================================
+//  GET /api/v1/achievements/:id/comments
+//  Diskusi prestasi (terlama dulu). Akses sama seperti DetailAchievement:
+//  mahasiswa pemilik, dosen wali (atau delegasinya), dan admin.
+// ===============================================================
+func (s *achievementService) GetComments(ctx *gin.Context) {
+	ref, ok := s.commentTarget(ctx)
+	if !ok {
+		return
+	}
+
+	comments, err := s.commentRepo.FindByAchievement(ctx.Request.Context(), ref.ID.String())
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil komentar", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil komentar prestasi", map[string]any{"items": comments})
+}
+
+// ===============================================================
+//  POST /api/v1/achievements/:id/comments
+//  Body: { "body": "Mohon lampirkan sertifikat asli" }
+//  Komentar tidak bisa diubah setelah dikirim.
+// ===============================================================
+func (s *achievementService) AddComment(ctx *gin.Context) {
+	ref, ok := s.commentTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Body string `json:"body" binding:"required,max=2000"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input tidak valid", err.Error(), nil)
+		return
+	}
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Komentar tidak boleh kosong", "empty_comment", nil)
+		return
+	}
+
+	userID, _ := getUserIDFromContext(ctx)
+	comment := &model.AchievementComment{
+		AchievementID: ref.ID.String(),
+		AuthorUserID:  userID,
+		Role:          getRoleFromContext(ctx),
+		Body:          body,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.commentRepo.Create(ctx.Request.Context(), comment); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan komentar", err.Error(), nil)
+		return
+	}
+
+	utils.RespondCreated(ctx,
+		"Komentar berhasil dikirim", comment)
+}
+
+// ===============================================================
+//  DELETE /api/v1/achievements/:id/comments/:commentId
+//  Hanya penulis, paling lambat 15 menit setelah komentar dikirim.
+// ===============================================================
+func (s *achievementService) DeleteComment(ctx *gin.Context) {
+	ref, ok := s.commentTarget(ctx)
+	if !ok {
+		return
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx.Request.Context(), ref.ID.String(), ctx.Param("commentId"))
+	if err != nil {
+		if errors.Is(err, repository.ErrCommentNotFound) {
+			utils.RespondError(ctx, http.StatusNotFound,
+				"Komentar tidak ditemukan", "not_found", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil komentar", err.Error(), nil)
+		return
+	}
+
+	userID, _ := getUserIDFromContext(ctx)
+	if comment.AuthorUserID != userID {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya penulis yang dapat menghapus komentar", "forbidden", nil)
+		return
+	}
+	if time.Since(comment.CreatedAt) > model.AchievementCommentDeleteWindow {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Komentar hanya dapat dihapus dalam 15 menit setelah dikirim", "comment_delete_window_expired", nil)
+		return
+	}
+
+	if err := s.commentRepo.Delete(ctx.Request.Context(), ref.ID.String(), comment.ID.Hex()); err != nil {
+		if errors.Is(err, repository.ErrCommentNotFound) {
+			utils.RespondError(ctx, http.StatusNotFound,
+				"Komentar tidak ditemukan", "not_found", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menghapus komentar", err.Error(), nil)
+		return
+	}
+
+	_ = s.auditRepo.Record(ctx, &userID, "achievement.comment_delete", "achievement", ref.ID.String(), map[string]any{
+		"commentId": comment.ID.Hex(),
+	})
+
+	utils.RespondOK(ctx,
+		"Komentar berhasil dihapus", nil)
+}
+
+// commentTarget memuat prestasi :id dan memastikan pemanggil peserta diskusinya.
+// ok=false berarti response error sudah dikirim.
+func (s *achievementService) commentTarget(ctx *gin.Context) (*model.AchievementReference, bool) {
+	id := ctx.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi tidak valid", "invalid_id", nil)
+		return nil, false
+	}
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return nil, false
+	}
+
+	switch getRoleFromContext(ctx) {
+	case "mahasiswa":
+		studentID, _ := getStudentIDFromContext(ctx)
+		if studentID != uuid.Nil && ref.StudentID == studentID {
+			return ref, true
+		}
+	case "dosen_wali":
+		if isOwnLinkedRecord(ctx, ref) {
+			return ref, true // prestasi sendiri sebagai mahasiswa (akun tertaut)
+		}
+		userID, _ := getUserIDFromContext(ctx)
+		if userID == uuid.Nil {
+			break
+		}
+		lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
+		if err != nil {
+			break
+		}
+		if ok, _, err := s.checkAdvisorAccess(lecturerID, ref.StudentID); err == nil && ok {
+			return ref, true
+		}
+	case "admin":
+		return ref, true
+	}
+
+	utils.RespondError(ctx, http.StatusForbidden,
+		"Anda tidak berhak mengakses diskusi prestasi ini", "forbidden", nil)
+	return nil, false
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===============================================================
+//  ADMIN: CONSISTENCY — pemeriksaan selisih data Postgres / Mongo
+//  Endpoint: GET /api/v1/admin/achievements/consistency?kind=&page=1&limit=10
+//  - kind: orphaned_reference | orphaned_document | status_mismatch (kosong = semua)
+//  - summary: jumlah per jenis + jumlah baris/dokumen yang dipindai
+// ===============================================================
+func (s *achievementService) CheckConsistency(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	report, ok := s.consistencyReport(ctx)
+	if !ok {
+		return
+	}
+
+	kind := ctx.Query("kind")
+	issues := report.Issues
+	if kind != "" {
+		issues = filterIssues(issues, kind)
+	}
+	items, meta := pageIssues(ctx, issues)
+
+	utils.RespondOK(ctx,
+		"Berhasil memeriksa konsistensi data prestasi", map[string]any{
+			"items":   items,
+			"meta":    meta,
+			"summary": report,
+		})
+}
+
+// ===============================================================
+//  ADMIN: CONSISTENCY REPAIR
+//  Endpoint: POST /api/v1/admin/achievements/consistency/repair?dryRun=false&page=1&limit=10
+//  - dryRun default true: hanya menampilkan rencana perbaikan
+//  - orphaned_reference → reference di-soft-delete (status deleted + status log)
+//  - orphaned_document  → dokumen ditandai deleted + orphaned
+//  - status_mismatch tidak diperbaiki otomatis (perlu diperiksa manual)
+// ===============================================================
+func (s *achievementService) RepairConsistency(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+	adminID, _ := getUserIDFromContext(ctx)
+	dryRun := ctx.DefaultQuery("dryRun", "true") != "false"
+
+	report, ok := s.consistencyReport(ctx)
+	if !ok {
+		return
+	}
+
+	orphanedRefs := filterIssues(report.Issues, repository.ConsistencyOrphanedReference)
+	orphanedDocs := filterIssues(report.Issues, repository.ConsistencyOrphanedDocument)
+	result := map[string]any{
+		"dryRun":                dryRun,
+		"orphanedReferences":    len(orphanedRefs),
+		"orphanedDocuments":     len(orphanedDocs),
+		"statusMismatchSkipped": report.Counts[repository.ConsistencyStatusMismatch],
+	}
+
+	if !dryRun {
+		refIDs := make([]uuid.UUID, 0, len(orphanedRefs))
+		for _, issue := range orphanedRefs {
+			refIDs = append(refIDs, *issue.AchievementID)
+		}
+		docIDs := make([]string, 0, len(orphanedDocs))
+		for _, issue := range orphanedDocs {
+			docIDs = append(docIDs, issue.MongoID)
+		}
+
+		marked, err := s.repo.MarkReferencesDeleted(refIDs, adminID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal menghapus reference tanpa dokumen", err.Error(), nil)
+			return
+		}
+		flagged, err := s.repo.FlagOrphanedDocuments(ctx.Request.Context(), docIDs)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal menandai dokumen tanpa reference", err.Error(), nil)
+			return
+		}
+		result["referencesDeleted"] = marked
+		result["documentsFlagged"] = flagged
+
+		_ = s.auditRepo.Record(ctx, &adminID, "achievement.consistency_repair", "achievement", "", map[string]any{
+			"referencesDeleted": marked,
+			"documentsFlagged":  flagged,
+		})
+	}
+
+	items, meta := pageIssues(ctx, append(orphanedRefs, orphanedDocs...))
+	result["items"] = items
+	result["meta"] = meta
+
+	message := "Rencana perbaikan konsistensi data prestasi (dry run)"
+	if !dryRun {
+		message = "Perbaikan konsistensi data prestasi selesai"
+	}
+	utils.RespondOK(ctx, message, result)
+}
+
+// consistencyReport menjalankan pemindaian; ok=false berarti response error sudah dikirim.
+func (s *achievementService) consistencyReport(ctx *gin.Context) (*repository.ConsistencyReport, bool) {
+	report, err := s.repo.CheckConsistency(ctx.Request.Context())
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memeriksa konsistensi data prestasi", err.Error(), nil)
+		return nil, false
+	}
+	return report, true
+}
+
+// filterIssues mengembalikan issue dengan jenis kind.
+func filterIssues(issues []repository.ConsistencyIssue, kind string) []repository.ConsistencyIssue {
+	out := []repository.ConsistencyIssue{}
+	for _, issue := range issues {
+		if issue.Kind == kind {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// pageIssues memotong issues sesuai ?page=&limit= (format meta sama dengan list prestasi).
+func pageIssues(ctx *gin.Context, issues []repository.ConsistencyIssue) ([]repository.ConsistencyIssue, map[string]any) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = repository.NormalizePagination(page, limit)
+
+	total := int64(len(issues))
+	start := min((page-1)*limit, len(issues))
+	end := min(start+limit, len(issues))
+
+	return issues[start:end], map[string]any{
+		"page":      page,
+		"limit":     limit,
+		"totalData": total,
+		"totalPage": (total + int64(limit) - 1) / int64(limit),
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxDecisionImportRows membatasi jumlah baris CSV per impor.
+const maxDecisionImportRows = 5000
+
+// Hasil per baris impor keputusan.
+const (
+	importOutcomeApplied     = "applied"
+	importOutcomeOverwritten = "overwritten"
+	importOutcomeConflict    = "conflict_skipped"
+	importOutcomeError       = "error"
+)
+
+// decisionImportRow adalah hasil 1 baris CSV.
+type decisionImportRow struct {
+	Row           int    `json:"row"` // nomor baris di file (header = 1)
+	AchievementID string `json:"achievementId"`
+	ExternalRef   string `json:"externalRef,omitempty"`
+	Outcome       string `json:"outcome"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// decisionImportColumns: nama kolom CSV (case-insensitive) → alias yang diterima.
+var decisionImportColumns = map[string][]string{
+	"achievementId":        {"achievementid"},
+	"externalRef":          {"achievementexternalref", "externalref"},
+	"decision":             {"decision"},
+	"decidedAt":            {"decidedat"},
+	"verifierLecturerCode": {"verifierlecturercode"},
+	"note":                 {"note"},
+}
+
+// ===============================================================
+//  ADMIN: ImportDecisions
+//  Endpoint: POST /api/v1/admin/achievements/import-decisions?overwrite=true
+//  Body: CSV (multipart field "file", atau body text/csv) dengan header:
+//    achievementId,achievementExternalRef,decision,decidedAt,verifierLecturerCode,note
+//  - prestasi dicocokkan lewat achievementId (UUID) atau, jika kosong, achievementExternalRef
+//    (ID di sistem lama, achievement_references.external_ref); minimal salah satu kolom ada
+//  - hanya prestasi berstatus submitted yang diberi keputusan (draft dkk → error)
+//  - decision: verified | rejected (rejected wajib note)
+//  - decidedAt: RFC3339 atau YYYY-MM-DD → disimpan sebagai verified_at historis
+//  - verifier dicocokkan lewat kode dosen (lecturers.lecturer_id)
+//  - prestasi yang sudah punya keputusan dilewati kecuali overwrite=true
+// ===============================================================
+func (s *achievementService) ImportDecisions(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi admin diperlukan", "no_user_id", nil)
+		return
+	}
+
+	overwrite := ctx.Query("overwrite") == "true"
+
+	var src io.Reader = ctx.Request.Body
+	if fh, err := ctx.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"File CSV tidak bisa dibaca", err.Error(), nil)
+			return
+		}
+		defer f.Close()
+		src = f
+	}
+
+	reader := csv.NewReader(src)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Header CSV tidak valid", err.Error(), nil)
+		return
+	}
+	cols, missing := mapDecisionImportHeader(header)
+	if len(missing) > 0 {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Kolom CSV wajib tidak ada", "missing_columns", map[string]any{"missing": missing})
+		return
+	}
+
+	results := []decisionImportRow{}
+	summary := map[string]int{}
+	lecturers := map[string]*model.Lecturer{} // cache kode dosen → dosen (nil = tidak ditemukan)
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if line-1 > maxDecisionImportRows {
+			utils.RespondError(ctx, http.StatusRequestEntityTooLarge,
+				"Terlalu banyak baris CSV", "too_many_rows", map[string]any{"max": maxDecisionImportRows})
+			return
+		}
+
+		var res decisionImportRow
+		if err != nil {
+			res = decisionImportRow{Row: line, Outcome: importOutcomeError, Reason: err.Error()}
+		} else {
+			res = s.importDecisionRow(ctx, cols, record, overwrite, lecturers)
+			res.Row = line
+		}
+		summary[res.Outcome]++
+		results = append(results, res)
+	}
+
+	_ = s.auditRepo.Record(ctx, &adminID, "achievement.decision_import", "achievement", "", map[string]any{
+		"rows":      len(results),
+		"summary":   summary,
+		"overwrite": overwrite,
+	})
+
+	utils.RespondOK(ctx,
+		"Impor keputusan verifikasi selesai", map[string]any{
+			"total":   len(results),
+			"summary": summary,
+			"rows":    results,
+		})
+}
+
+// mapDecisionImportHeader memetakan nama kolom ke indeksnya; note opsional.
+func mapDecisionImportHeader(header []string) (map[string]int, []string) {
+	cols := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		for name, aliases := range decisionImportColumns {
+			for _, alias := range aliases {
+				if h == alias {
+					cols[name] = i
+				}
+			}
+		}
+	}
+	var missing []string
+	_, hasID := cols["achievementId"]
+	_, hasRef := cols["externalRef"]
+	if !hasID && !hasRef {
+		missing = append(missing, "achievementId|achievementExternalRef")
+	}
+	for _, name := range []string{"decision", "decidedAt", "verifierLecturerCode"} {
+		if _, ok := cols[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return cols, missing
+}
+
+// importDecisionRow memvalidasi & menerapkan 1 baris impor.
+func (s *achievementService) importDecisionRow(
+	ctx *gin.Context,
+	cols map[string]int,
+	record []string,
+	overwrite bool,
+	lecturers map[string]*model.Lecturer,
+) decisionImportRow {
+	field := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	id, externalRef := field("achievementId"), field("externalRef")
+	fail := func(id, reason string) decisionImportRow {
+		return decisionImportRow{AchievementID: id, ExternalRef: externalRef, Outcome: importOutcomeError, Reason: reason}
+	}
+
+	switch {
+	case id != "":
+		if _, err := uuid.Parse(id); err != nil {
+			return fail(id, "achievementId harus berupa ID prestasi (UUID)")
+		}
+	case externalRef == "":
+		return fail(id, "achievementId atau achievementExternalRef wajib diisi")
+	}
+
+	decision := strings.ToLower(field("decision"))
+	if decision != model.StatusVerified && decision != model.StatusRejected {
+		return fail(id, "decision harus verified atau rejected")
+	}
+
+	decidedAt, err := parseDecisionDate(field("decidedAt"))
+	if err != nil {
+		return fail(id, "decidedAt harus RFC3339 atau YYYY-MM-DD")
+	}
+	if decidedAt.After(time.Now()) {
+		return fail(id, "decidedAt tidak boleh di masa depan")
+	}
+
+	note := field("note")
+	if decision == model.StatusRejected && note == "" {
+		return fail(id, "note wajib untuk keputusan rejected")
+	}
+
+	code := field("verifierLecturerCode")
+	lecturer, cached := lecturers[code]
+	if !cached {
+		lecturer, err = s.lecturerRepo.FindByCode(code)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fail(id, err.Error())
+		}
+		lecturers[code] = lecturer
+	}
+	if lecturer == nil {
+		return fail(id, "kode dosen verifier tidak ditemukan: "+code)
+	}
+
+	var ref *model.AchievementReference
+	if id != "" {
+		ref, err = s.repo.FindByID(ctx.Request.Context(), id)
+	} else {
+		ref, err = s.repo.FindByExternalRef(ctx.Request.Context(), externalRef)
+	}
+	if err != nil {
+		return fail(id, "prestasi tidak ditemukan")
+	}
+	id = ref.ID.String()
+
+	// Keputusan baru hanya untuk prestasi yang sedang diajukan; keputusan lama hanya
+	// ditimpa jika overwrite. Draft/expired/deleted tidak pernah bisa langsung diputuskan.
+	outcome, expect := importOutcomeApplied, model.StatusSubmitted
+	switch ref.Status {
+	case model.StatusSubmitted:
+	case model.StatusVerified, model.StatusRejected:
+		if !overwrite {
+			return decisionImportRow{
+				AchievementID: id,
+				ExternalRef:   externalRef,
+				Outcome:       importOutcomeConflict,
+				Reason:        "prestasi sudah berstatus " + ref.Status,
+			}
+		}
+		outcome, expect = importOutcomeOverwritten, ref.Status
+	default:
+		return fail(id, "prestasi berstatus "+ref.Status+", hanya prestasi submitted yang bisa diberi keputusan")
+	}
+
+	verifierID := lecturer.UserID.String()
+	opts := repository.UpdateStatusOptions{
+		VerifierID:     &verifierID,
+		DecidedAt:      &decidedAt,
+		ExpectedStatus: expect,
+	}
+	if decision == model.StatusRejected {
+		opts.RejectionNote = &note
+	}
+	err = s.repo.UpdateStatus(ctx.Request.Context(), id, decision, opts)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		return decisionImportRow{
+			AchievementID: id,
+			ExternalRef:   externalRef,
+			Outcome:       importOutcomeConflict,
+			Reason:        "status prestasi berubah saat impor",
+		}
+	}
+	if err != nil {
+		return fail(id, err.Error())
+	}
+
+	return decisionImportRow{AchievementID: id, ExternalRef: externalRef, Outcome: outcome}
+}
+
+// parseDecisionDate menerima RFC3339 atau tanggal saja (YYYY-MM-DD, UTC).
+func parseDecisionDate(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeAchievementRepo menyimpan reference prestasi di memori. UpdateStatus menegakkan
+// ExpectedStatus seperti repository asli (ErrStatusConflict jika status sudah berubah).
+type fakeAchievementRepo struct {
+	repository.AchievementRepository
+	mu      sync.Mutex
+	refs    map[uuid.UUID]*model.AchievementReference
+	updates []fakeStatusUpdate
+}
+
+type fakeStatusUpdate struct {
+	ID     string
+	Status string
+	Opts   repository.UpdateStatusOptions
+}
+
+func newFakeAchievementRepo(refs ...*model.AchievementReference) *fakeAchievementRepo {
+	r := &fakeAchievementRepo{refs: map[uuid.UUID]*model.AchievementReference{}}
+	for _, ref := range refs {
+		r.refs[ref.ID] = ref
+	}
+	return r
+}
+
+func (r *fakeAchievementRepo) FindByID(_ context.Context, id string) (*model.AchievementReference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uid, err := uuid.Parse(id)
+	if err != nil || r.refs[uid] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *r.refs[uid]
+	return &cp, nil
+}
+
+func (r *fakeAchievementRepo) FindByExternalRef(_ context.Context, externalRef string) (*model.AchievementReference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ref := range r.refs {
+		if ref.ExternalRef != nil && *ref.ExternalRef == externalRef {
+			cp := *ref
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAchievementRepo) UpdateStatus(_ context.Context, id string, status string, opts repository.UpdateStatusOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref := r.refs[uuid.MustParse(id)]
+	if opts.ExpectedStatus != "" && ref.Status != opts.ExpectedStatus {
+		return repository.ErrStatusConflict
+	}
+	ref.Status = status
+	if opts.VerifierID != nil {
+		verifier := uuid.MustParse(*opts.VerifierID)
+		ref.VerifiedBy, ref.InternalNote = &verifier, opts.InternalNote
+	}
+	if opts.DecidedAt != nil {
+		ref.VerifiedAt, ref.DecisionImported = opts.DecidedAt, true
+	}
+	if opts.ClearDecision {
+		ref.VerifiedAt, ref.VerifiedBy, ref.VerifiedAsDelegateOf = nil, nil, nil
+		ref.RejectionNote, ref.RejectionCategory, ref.InternalNote = nil, nil, nil
+		ref.DecisionImported = false
+	}
+	r.updates = append(r.updates, fakeStatusUpdate{ID: id, Status: status, Opts: opts})
+	return nil
+}
+
+func TestImportDecisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifierUser := uuid.New()
+	legacyRef := "SIAKAD-0042"
+	submitted := &model.AchievementReference{ID: uuid.New(), Status: model.StatusSubmitted}
+	byExternal := &model.AchievementReference{ID: uuid.New(), Status: model.StatusSubmitted, ExternalRef: &legacyRef}
+	draft := &model.AchievementReference{ID: uuid.New(), Status: model.StatusDraft}
+	decided := &model.AchievementReference{ID: uuid.New(), Status: model.StatusVerified}
+
+	csv := func(rows ...string) string {
+		return "achievementId,achievementExternalRef,decision,decidedAt,verifierLecturerCode,note\n" + strings.Join(rows, "\n")
+	}
+	tests := []struct {
+		name        string
+		body        string
+		overwrite   bool
+		wantOutcome string
+		wantStatus  string // status prestasi setelah impor
+		ref         *model.AchievementReference
+	}{
+		{name: "cocok lewat kode dosen & tanggal historis", ref: submitted,
+			body: csv(submitted.ID.String() + ",,verified,2021-03-04,NIP001,"), wantOutcome: importOutcomeApplied, wantStatus: model.StatusVerified},
+		{name: "cocok lewat external ref", ref: byExternal,
+			body: csv("," + legacyRef + ",rejected,2021-03-04,NIP001,bukti kurang"), wantOutcome: importOutcomeApplied, wantStatus: model.StatusRejected},
+		{name: "draft tidak bisa diputuskan", ref: draft,
+			body: csv(draft.ID.String() + ",,verified,2021-03-04,NIP001,"), wantOutcome: importOutcomeError, wantStatus: model.StatusDraft},
+		{name: "draft tetap ditolak walau overwrite", ref: draft, overwrite: true,
+			body: csv(draft.ID.String() + ",,verified,2021-03-04,NIP001,"), wantOutcome: importOutcomeError, wantStatus: model.StatusDraft},
+		{name: "sudah diputuskan dilewati", ref: decided,
+			body: csv(decided.ID.String() + ",,rejected,2021-03-04,NIP001,ganda"), wantOutcome: importOutcomeConflict, wantStatus: model.StatusVerified},
+		{name: "sudah diputuskan ditimpa", ref: decided, overwrite: true,
+			body: csv(decided.ID.String() + ",,rejected,2021-03-04,NIP001,ganda"), wantOutcome: importOutcomeOverwritten, wantStatus: model.StatusRejected},
+		{name: "kode dosen tidak dikenal", ref: submitted,
+			body: csv(submitted.ID.String() + ",,verified,2021-03-04,NIP999,"), wantOutcome: importOutcomeError, wantStatus: model.StatusSubmitted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := *tt.ref
+			repo := newFakeAchievementRepo(&ref)
+			lecturers := &fakeLecturerRepo{byCode: map[string]*model.Lecturer{"NIP001": {ID: uuid.New(), UserID: verifierUser}}}
+			svc := NewAchievementService(repo, nil, nil, lecturers, &fakeAuditRepo{}, nil, nil, nil, nil, nil, nil, nil)
+
+			r := gin.New()
+			r.POST("/import", func(c *gin.Context) {
+				c.Set("role", "admin")
+				c.Set("userID", uuid.New())
+			}, svc.ImportDecisions)
+			path := "/import"
+			if tt.overwrite {
+				path += "?overwrite=true"
+			}
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/csv")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			checkEnvelope(t, w)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+
+			var resp struct {
+				Data struct {
+					Rows []decisionImportRow `json:"rows"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Data.Rows) != 1 {
+				t.Fatalf("response tidak valid: %v %s", err, w.Body)
+			}
+			if got := resp.Data.Rows[0]; got.Outcome != tt.wantOutcome {
+				t.Fatalf("outcome %q (%s), want %q", got.Outcome, got.Reason, tt.wantOutcome)
+			}
+			if ref.Status != tt.wantStatus {
+				t.Fatalf("status prestasi %q, want %q", ref.Status, tt.wantStatus)
+			}
+			if len(repo.updates) == 1 {
+				u := repo.updates[0]
+				if want := time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC); u.Opts.DecidedAt == nil || !u.Opts.DecidedAt.Equal(want) {
+					t.Fatalf("DecidedAt %v, want %v", u.Opts.DecidedAt, want)
+				}
+				if u.Opts.VerifierID == nil || *u.Opts.VerifierID != verifierUser.String() {
+					t.Fatalf("VerifierID %v, want %s", u.Opts.VerifierID, verifierUser)
+				}
+				if !tt.overwrite && u.Opts.ExpectedStatus != model.StatusSubmitted {
+					t.Fatalf("ExpectedStatus %q, want submitted", u.Opts.ExpectedStatus)
+				}
+			}
+		})
+	}
+}
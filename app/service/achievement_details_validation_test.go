@@ -0,0 +1,119 @@
+package service
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// errorFields mengambil daftar field dari data.errors response invalid_details.
+func errorFields(t *testing.T, data map[string]any) []string {
+	t.Helper()
+	list, _ := data["errors"].([]any)
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		if m, ok := e.(map[string]any); ok {
+			out = append(out, m["field"].(string))
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// TestAchievementDetailsValidation: create & update menolak details yang tidak sesuai tipe
+// dengan 400 invalid_details beserta daftar field, tanpa menyimpan apa pun.
+func TestAchievementDetailsValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	catalog := []model.AchievementType{
+		{Code: model.AchievementTypeCompetition, Active: true},
+		{Code: model.AchievementTypePublication, Active: true},
+		{Code: model.AchievementTypeOrganization, Active: true},
+		{Code: model.AchievementTypeCertification, Active: true},
+	}
+
+	tests := []struct {
+		name       string
+		typ        string
+		details    map[string]any
+		wantFields []string // nil = valid (hanya diuji pada create)
+	}{
+		{name: "competition valid", typ: model.AchievementTypeCompetition,
+			details: map[string]any{"competitionName": "GEMASTIK", "competitionLevel": "national"}},
+		{name: "competition berisi field publikasi", typ: model.AchievementTypeCompetition,
+			details:    map[string]any{"publicationTitle": "Paper"},
+			wantFields: []string{"details.competitionLevel", "details.competitionName", "details.publicationTitle"}},
+		{name: "competition level di luar enum", typ: model.AchievementTypeCompetition,
+			details:    map[string]any{"competitionName": "GEMASTIK", "competitionLevel": "provincial"},
+			wantFields: []string{"details.competitionLevel"}},
+		{name: "publication tanpa field wajib", typ: model.AchievementTypePublication,
+			details:    map[string]any{},
+			wantFields: []string{"details.publicationTitle", "details.publicationType"}},
+		{name: "organization end sebelum start", typ: model.AchievementTypeOrganization,
+			details: map[string]any{"organizationName": "BEM", "position": "Ketua",
+				"period": map[string]any{"start": "2025-06-01T00:00:00Z", "end": "2025-01-01T00:00:00Z"}},
+			wantFields: []string{"details.period.end"}},
+		{name: "certification tanpa issuedBy", typ: model.AchievementTypeCertification,
+			details:    map[string]any{"certificationName": "AWS SAA"},
+			wantFields: []string{"details.issuedBy"}},
+	}
+	for _, tt := range tests {
+		body := map[string]any{"achievementType": tt.typ, "title": "Prestasi", "details": tt.details}
+
+		t.Run("create/"+tt.name, func(t *testing.T) {
+			repo := &fakeCreateRepo{}
+			svc := NewAchievementService(repo, fakeStudentRepo{}, nil, nil, nil, nil, nil, nil, &fakeTypeRepo{types: catalog}, nil, nil, nil)
+			r := gin.New()
+			r.POST("/achievements", func(c *gin.Context) { c.Set("role", "admin") }, svc.CreateAchievement)
+
+			createBody := map[string]any{"studentId": uuid.NewString(), "points": 10}
+			for k, v := range body {
+				createBody[k] = v
+			}
+			w, data := doJSON(t, r, http.MethodPost, "/achievements", "", createBody)
+			if tt.wantFields == nil {
+				if w.Code != http.StatusCreated || repo.created == nil {
+					t.Fatalf("status %d, body %s, want 201", w.Code, w.Body)
+				}
+				return
+			}
+			assertInvalidDetails(t, w.Code, w.Body.String(), data, tt.wantFields)
+			if repo.created != nil {
+				t.Fatal("prestasi tetap disimpan walaupun details tidak valid")
+			}
+		})
+
+		if tt.wantFields == nil {
+			continue
+		}
+		t.Run("update/"+tt.name, func(t *testing.T) {
+			studentID := uuid.New()
+			ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusDraft}
+			repo := newFakeAchievementRepo(ref) // UpdateContent tidak diimplementasikan: panggilan = panic
+			svc := NewAchievementService(repo, nil, nil, nil, nil, nil, nil, nil, &fakeTypeRepo{types: catalog}, nil, nil, nil)
+			r := gin.New()
+			r.PUT("/achievements/:id", func(c *gin.Context) {
+				c.Set("role", "mahasiswa")
+				c.Set("studentID", studentID)
+			}, svc.UpdateAchievement)
+
+			w, data := doJSON(t, r, http.MethodPut, "/achievements/"+ref.ID.String(), "", body)
+			assertInvalidDetails(t, w.Code, w.Body.String(), data, tt.wantFields)
+		})
+	}
+}
+
+func assertInvalidDetails(t *testing.T, code int, body string, data map[string]any, wantFields []string) {
+	t.Helper()
+	if code != http.StatusBadRequest || !strings.Contains(body, `"errors":"invalid_details"`) {
+		t.Fatalf("status %d, body %s, want 400 invalid_details", code, body)
+	}
+	if got := errorFields(t, data); !slices.Equal(got, wantFields) {
+		t.Fatalf("field error %v, want %v (body %s)", got, wantFields, body)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDraftsPerStudent membaca batas draft aktif per mahasiswa (MAX_DRAFTS_PER_STUDENT, default 50).
+func maxDraftsPerStudent() int {
+	return utils.Runtime().MaxDraftsPerStudent
+}
+
+// ===============================================================
+//  GET /api/v1/admin/maintenance/drafts?minDrafts=40
+//  Admin: daftar mahasiswa yang jumlah draft-nya mendekati/mencapai batas.
+//  Default minDrafts = 80% dari MAX_DRAFTS_PER_STUDENT.
+// ===============================================================
+func (s *adminService) GetDraftUsage(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	limit := maxDraftsPerStudent()
+	minDrafts := max(limit*8/10, 1)
+	if v := ctx.Query("minDrafts"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"minDrafts harus bilangan bulat >= 1", "invalid_min_drafts", nil)
+			return
+		}
+		minDrafts = n
+	}
+
+	rows, err := s.achievementRepo.FindDraftCounts(int64(minDrafts))
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil jumlah draft mahasiswa", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil jumlah draft mahasiswa", map[string]any{
+			"maxDrafts": limit,
+			"minDrafts": minDrafts,
+			"students":  rows,
+		})
+}
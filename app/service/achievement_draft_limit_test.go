@@ -0,0 +1,117 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TestDraftLimit: mahasiswa bisa membuat draft selama jumlah draft aktifnya di bawah
+// MAX_DRAFTS_PER_STUDENT; tepat di batas ditolak 409 too_many_drafts. Admin tidak terkena batas.
+func TestDraftLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 3
+	setRuntimeEnv(t, map[string]string{"MAX_DRAFTS_PER_STUDENT": "3"})
+	tests := []struct {
+		name   string
+		role   string
+		drafts int64
+		want   int
+	}{
+		{name: "batas - 1", role: "mahasiswa", drafts: limit - 1, want: http.StatusCreated},
+		{name: "tepat di batas", role: "mahasiswa", drafts: limit, want: http.StatusConflict},
+		{name: "melewati batas", role: "mahasiswa", drafts: limit + 2, want: http.StatusConflict},
+		{name: "admin dikecualikan", role: "admin", drafts: limit + 2, want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDraftCountRepo{fakeCreateRepo: &fakeCreateRepo{}, drafts: tt.drafts}
+			types := &fakeTypeRepo{types: []model.AchievementType{{Code: "seminar", Label: "Seminar", Active: true}}}
+			svc := NewAchievementService(repo, fakeStudentRepo{}, nil, nil, nil, nil, nil, fakeNoRuleRepo{}, types, nil, nil, nil)
+
+			studentID := uuid.New()
+			r := gin.New()
+			r.POST("/achievements", func(c *gin.Context) {
+				c.Set("role", tt.role)
+				c.Set("userID", uuid.New())
+				if tt.role == "mahasiswa" {
+					c.Set("studentID", studentID)
+				}
+			}, svc.CreateAchievement)
+			body := map[string]any{"achievementType": "seminar", "title": "Pemakalah seminar nasional"}
+			if tt.role == "admin" {
+				body["studentId"] = studentID.String()
+			}
+			w, data := doJSON(t, r, http.MethodPost, "/achievements", "", body)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, body %s, want %d", w.Code, w.Body, tt.want)
+			}
+			if tt.want == http.StatusCreated {
+				if repo.created == nil || repo.created.StudentID != studentID {
+					t.Fatalf("draft tidak tersimpan untuk mahasiswa: %+v", repo.created)
+				}
+				return
+			}
+			if !strings.Contains(w.Body.String(), `"errors":"too_many_drafts"`) {
+				t.Fatalf("body %s, want too_many_drafts", w.Body)
+			}
+			if data["current"] != float64(tt.drafts) || data["max"] != float64(limit) {
+				t.Fatalf("detail %v, want current=%d max=%d", data, tt.drafts, limit)
+			}
+			if repo.created != nil {
+				t.Fatal("draft tetap dibuat walau melewati batas")
+			}
+		})
+	}
+}
+
+// fakeDraftUsageRepo mencatat minDrafts yang diminta laporan maintenance.
+type fakeDraftUsageRepo struct {
+	repository.AchievementRepository
+	minDrafts int64
+}
+
+func (r *fakeDraftUsageRepo) FindDraftCounts(minDrafts int64) ([]repository.StudentDraftCount, error) {
+	r.minDrafts = minDrafts
+	return []repository.StudentDraftCount{{StudentID: uuid.New(), NIM: "2024001", Drafts: minDrafts}}, nil
+}
+
+// TestGetDraftUsage: laporan draft default memakai 80% dari batas, minDrafts bisa diatur admin.
+func TestGetDraftUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setRuntimeEnv(t, map[string]string{"MAX_DRAFTS_PER_STUDENT": "10"})
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   int64
+	}{
+		{name: "default 80% batas", status: http.StatusOK, want: 8},
+		{name: "minDrafts eksplisit", query: "?minDrafts=2", status: http.StatusOK, want: 2},
+		{name: "minDrafts tidak valid", query: "?minDrafts=0", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDraftUsageRepo{}
+			svc := NewAdminService(nil, repo, nil, nil, nil, nil, nil, nil, nil, nil)
+			r := gin.New()
+			r.GET("/admin/maintenance/drafts", func(c *gin.Context) { c.Set("role", "admin") }, svc.GetDraftUsage)
+			w, data := doJSON(t, r, http.MethodGet, "/admin/maintenance/drafts"+tt.query, "", nil)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, body %s, want %d", w.Code, w.Body, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if repo.minDrafts != tt.want || data["minDrafts"] != float64(tt.want) || data["maxDrafts"] != float64(10) {
+				t.Fatalf("minDrafts repo=%d response=%v, want %d (max 10)", repo.minDrafts, data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"net/http"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// groupedItemsPerType: jumlah prestasi terbaru yang ditampilkan per tipe di dashboard.
+const groupedItemsPerType = 5
+
+// ===============================================================
+//  GROUPED — prestasi per tipe untuk dashboard
+//  Endpoint: GET /api/v1/achievements/grouped?studentId=
+//  - Scope: mahasiswa miliknya, dosen wali bimbingan, admin semua (opsional ?studentId=)
+//  - Hasil: { "<achievementType>": {count, totalPoints, items: [5 terbaru]} }
+//  - 1 agregasi $group di Mongo + 1 query reference batch di Postgres
+// ===============================================================
+func (s *achievementService) GetGroupedAchievements(ctx *gin.Context) {
+	role := ctx.GetString("role")
+	scope, ok := reportScope(ctx, s.lecturerRepo, role, "ringkasan prestasi per tipe")
+	if !ok {
+		return
+	}
+
+	if sid := ctx.Query("studentId"); sid != "" && role == "admin" {
+		target, err := uuid.Parse(sid)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"studentId tidak valid", err.Error(), nil)
+			return
+		}
+		scope.StudentIDs = []string{target.String()}
+	}
+
+	grouped := map[string]repository.AchievementTypeGroup{}
+	// dosen wali tanpa bimbingan: scope kosong bukan berarti semua mahasiswa
+	if role != "admin" && len(scope.StudentIDs) == 0 {
+		utils.RespondOK(ctx, "Berhasil mengambil prestasi per tipe", grouped)
+		return
+	}
+
+	groups, err := s.repo.FindGroupedByType(ctx.Request.Context(), scope, groupedItemsPerType)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengelompokkan prestasi", err.Error(), nil)
+		return
+	}
+
+	// Lengkapi item dengan id & status reference (1 query untuk semua item).
+	mongoIDs := []string{}
+	for _, g := range groups {
+		for _, item := range g.Items {
+			mongoIDs = append(mongoIDs, item.MongoID.Hex())
+		}
+	}
+	refByMongoID := map[string]int{}
+	refs, _, err := s.repo.FindFiltered(ctx.Request.Context(), repository.AchievementListFilter{MongoIDs: mongoIDs, Unpaged: true})
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data prestasi", err.Error(), nil)
+		return
+	}
+	for i, ref := range refs {
+		refByMongoID[ref.MongoAchievementID] = i
+	}
+
+	for _, g := range groups {
+		items := make([]repository.AchievementGroupItem, 0, len(g.Items))
+		for _, item := range g.Items {
+			i, found := refByMongoID[item.MongoID.Hex()]
+			if !found {
+				continue // dokumen tanpa reference aktif (mis. dihapus) tidak ditampilkan
+			}
+			item.AchievementID = refs[i].ID.String()
+			item.Status = refs[i].Status
+			items = append(items, item)
+		}
+		g.Items = items
+		grouped[g.AchievementType] = g
+	}
+
+	utils.RespondOK(ctx, "Berhasil mengambil prestasi per tipe", grouped)
+}
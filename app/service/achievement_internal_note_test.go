@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeNoteRepo: detail & riwayat 1 prestasi (log draft → submitted → verified).
+type fakeNoteRepo struct {
+	*fakePointsRepo
+}
+
+func (r *fakeNoteRepo) FindStatusLogs(_ context.Context, id string) ([]model.AchievementStatusLog, error) {
+	ref, err := r.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	at := ref.CreatedAt
+	logs := []model.AchievementStatusLog{{FromStatus: model.StatusDraft, ToStatus: model.StatusSubmitted, CreatedAt: at.Add(time.Minute)}}
+	if ref.Status == model.StatusVerified {
+		logs = append(logs, model.AchievementStatusLog{FromStatus: model.StatusSubmitted, ToStatus: model.StatusVerified,
+			ActorUserID: ref.VerifiedBy, CreatedAt: at.Add(time.Hour)})
+	}
+	return logs, nil
+}
+
+// fakeNoteLecturerRepo: semua dosen di test berhak atas mahasiswa (dosen wali / delegasi),
+// sehingga yang diuji hanya siapa yang boleh melihat catatan privat.
+type fakeNoteLecturerRepo struct {
+	*fakeListLecturerRepo
+}
+
+func (fakeNoteLecturerRepo) IsAdvisorOf(uuid.UUID, uuid.UUID) (bool, error) { return true, nil }
+
+type fakeNoteCommentRepo struct {
+	repository.AchievementCommentRepository
+}
+
+func (fakeNoteCommentRepo) Count(context.Context, string) (int64, error) { return 0, nil }
+
+// TestInternalNoteVisibility: catatan privat verifier hanya tampil untuk admin dan dosen yang
+// membuat keputusan (detail & riwayat), tidak pernah di list, dan isinya tidak masuk audit.
+func TestInternalNoteVisibility(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const note = "bukti kurang meyakinkan, cek ulang ke panitia"
+	studentID, deciderUser, otherUser := uuid.New(), uuid.New(), uuid.New()
+	mongoID := primitive.NewObjectID()
+	ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: mongoID.Hex(),
+		Status: model.StatusSubmitted, CreatedAt: time.Now().Add(-24 * time.Hour)}
+	doc := &model.Achievement{ID: mongoID, StudentID: studentID, Title: "Juara 1"}
+
+	repo := &fakeNoteRepo{&fakePointsRepo{fakeAchievementRepo: newFakeAchievementRepo(ref),
+		docs: map[string]*model.Achievement{mongoID.Hex(): doc}}}
+	listRepo := &fakeListRepo{docs: repo.docs}
+	lecturers := fakeNoteLecturerRepo{&fakeListLecturerRepo{students: []uuid.UUID{studentID}, list: listRepo}}
+	audit := &fakeAuditRepo{}
+	svc := NewAchievementService(repo, nil, &fakeListStudentRepo{}, lecturers, audit, fakeListDelegationRepo{},
+		fakeNoteCommentRepo{}, nil, nil, nil, nil, nil)
+	listSvc := NewAchievementService(listRepo, nil, &fakeListStudentRepo{}, lecturers, nil, fakeListDelegationRepo{},
+		nil, nil, nil, nil, nil, nil)
+
+	type audience struct {
+		name    string
+		role    string
+		userID  uuid.UUID
+		student uuid.UUID
+		want    bool // catatan privat terlihat di detail & riwayat
+	}
+	mahasiswa := audience{name: "mahasiswa pemilik", role: "mahasiswa", userID: uuid.New(), student: studentID}
+	otherDosen := audience{name: "dosen lain", role: "dosen_wali", userID: otherUser}
+	decider := audience{name: "dosen pemutus", role: "dosen_wali", userID: deciderUser, want: true}
+	admin := audience{name: "admin", role: "admin", userID: uuid.New(), want: true}
+
+	serve := func(t *testing.T, a audience, method, route, target string, h gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := gin.New()
+		r.Handle(method, route, func(c *gin.Context) {
+			c.Set("role", a.role)
+			c.Set("userID", a.userID)
+			c.Set("studentID", a.student)
+			if a.role == "dosen_wali" {
+				c.Set("lecturerID", uuid.NewSHA1(uuid.Nil, a.userID[:]))
+			}
+		}, h)
+		var req *http.Request
+		if body != "" {
+			req = httptest.NewRequest(method, target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		checkEnvelope(t, w)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s sebagai %s: status %d, body %s", method, target, a.name, w.Code, w.Body)
+		}
+		return w
+	}
+
+	// Keputusan dengan catatan privat: audit hanya mencatat keberadaannya.
+	base := "/achievements/" + ref.ID.String()
+	serve(t, decider, http.MethodPost, "/achievements/:id/verify", base+"/verify", svc.VerifyAchievement, `{"internalNote":"`+note+`"}`)
+	if len(audit.actions) != 1 || audit.actions[0] != "achievement.internal_note" {
+		t.Fatalf("audit %v, want [achievement.internal_note]", audit.actions)
+	}
+	payload, _ := json.Marshal(audit.payloads[0])
+	if strings.Contains(string(payload), note) || !strings.Contains(string(payload), `"hasInternalNote":true`) {
+		t.Fatalf("payload audit %s memuat isi catatan privat", payload)
+	}
+	decided, _ := repo.FindByID(context.Background(), ref.ID.String())
+	if decided.InternalNote == nil || *decided.InternalNote != note {
+		t.Fatalf("catatan privat tidak tersimpan: %+v", decided)
+	}
+	listRepo.refs = []model.AchievementReference{*decided}
+
+	for _, a := range []audience{mahasiswa, otherDosen, decider, admin} {
+		t.Run(a.name, func(t *testing.T) {
+			endpoints := []struct {
+				name    string
+				route   string
+				target  string
+				handler gin.HandlerFunc
+				want    bool
+			}{
+				{name: "detail", route: "/achievements/:id", target: base, handler: svc.DetailAchievement, want: a.want},
+				{name: "riwayat", route: "/achievements/:id/history", target: base + "/history", handler: svc.GetAchievementHistory, want: a.want},
+				{name: "list", route: "/achievements", target: "/achievements?page=1&limit=10", handler: listSvc.GetAchievements},
+			}
+			for _, e := range endpoints {
+				w := serve(t, a, http.MethodGet, e.route, e.target, e.handler, "")
+				body := w.Body.String()
+				if got := strings.Contains(body, note); got != e.want {
+					t.Fatalf("%s: catatan privat terlihat = %v, want %v (body %s)", e.name, got, e.want, body)
+				}
+				if !e.want && strings.Contains(body, "internalNote") {
+					t.Fatalf("%s: key internalNote bocor: %s", e.name, body)
+				}
+			}
+		})
+	}
+}
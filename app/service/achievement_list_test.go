@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeListRepo mengembalikan refs tetap untuk list dan menghitung query detail Mongo.
+type fakeListRepo struct {
+	repository.AchievementRepository
+	refs          []model.AchievementReference
+	docs          map[string]*model.Achievement
+	batchQueries  atomic.Int64 // FindDetailsByMongoIDs / ...WithDeleted
+	singleQueries atomic.Int64 // FindDetailByMongoID (per item)
+	withDeleted   atomic.Bool  // query terakhir lewat ...WithDeleted
+}
+
+func (r *fakeListRepo) FindFiltered(context.Context, repository.AchievementListFilter) ([]model.AchievementReference, int64, error) {
+	return append([]model.AchievementReference(nil), r.refs...), int64(len(r.refs)), nil
+}
+
+func (r *fakeListRepo) AttachIdentities(context.Context, []model.AchievementReference) error {
+	return nil
+}
+
+func (r *fakeListRepo) findDetails(mongoIDs []string, withDeleted bool) map[string]*model.Achievement {
+	r.batchQueries.Add(1)
+	r.withDeleted.Store(withDeleted)
+	out := map[string]*model.Achievement{}
+	for _, id := range mongoIDs {
+		if doc, ok := r.docs[id]; ok {
+			out[id] = doc
+		}
+	}
+	return out
+}
+
+func (r *fakeListRepo) FindDetailsByMongoIDs(_ context.Context, mongoIDs []string) (map[string]*model.Achievement, error) {
+	return r.findDetails(mongoIDs, false), nil
+}
+
+func (r *fakeListRepo) FindDetailsByMongoIDsWithDeleted(_ context.Context, mongoIDs []string) (map[string]*model.Achievement, error) {
+	return r.findDetails(mongoIDs, true), nil
+}
+
+func (r *fakeListRepo) FindDetailByMongoID(context.Context, string) (*model.Achievement, error) {
+	r.singleQueries.Add(1)
+	return nil, fmt.Errorf("FindDetailByMongoID tidak boleh dipanggil per item")
+}
+
+// fakeListLecturerRepo: dosen wali dengan refs bimbingan yang sama dengan fakeListRepo.
+type fakeListLecturerRepo struct {
+	repository.LecturerRepository
+	students []uuid.UUID
+	list     *fakeListRepo
+}
+
+func (r *fakeListLecturerRepo) GetAdviseeStudentIDs(context.Context, uuid.UUID) ([]uuid.UUID, error) {
+	return r.students, nil
+}
+
+func (r *fakeListLecturerRepo) FindAchievementsByStudentIDs(context.Context, repository.AdviseeAchievementFilter) ([]model.AchievementReference, int64, error) {
+	return r.list.FindFiltered(context.Background(), repository.AchievementListFilter{})
+}
+
+// fakeListStudentRepo mencatat panggilan FindByIDs (identitas mahasiswa di list).
+type fakeListStudentRepo struct {
+	repository.StudentRepository
+	calls atomic.Int64
+	ids   []uuid.UUID // argumen panggilan terakhir
+}
+
+func (r *fakeListStudentRepo) FindByIDs(ids []uuid.UUID) ([]model.Student, error) {
+	r.calls.Add(1)
+	r.ids = ids
+	out := make([]model.Student, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, model.Student{ID: id, StudentID: "2024001", ProgramStudy: "Informatika",
+			User: model.User{FullName: "Budi"}})
+	}
+	return out, nil
+}
+
+type fakeListDelegationRepo struct {
+	repository.DelegationRepository
+}
+
+func (fakeListDelegationRepo) FindActiveDelegatorIDs(uuid.UUID, time.Time) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+// TestGetAchievementsSingleMongoQuery: list N prestasi (semua role) hanya menjalankan 1 query
+// detail Mongo; dokumen yang hilang tetap tampil sebagai item tanpa title/points.
+func TestGetAchievementsSingleMongoQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const n = 25
+	studentID := uuid.New()
+	repo := &fakeListRepo{docs: map[string]*model.Achievement{}}
+	var missing uuid.UUID
+	for i := 0; i < n; i++ {
+		oid := primitive.NewObjectID()
+		ref := model.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: oid.Hex(), Status: model.StatusSubmitted}
+		if i == n-1 {
+			missing = ref.ID // dokumen Mongo tidak ada
+		} else {
+			repo.docs[oid.Hex()] = &model.Achievement{ID: oid, Title: fmt.Sprintf("Prestasi %d", i), Points: float64(i)}
+		}
+		repo.refs = append(repo.refs, ref)
+	}
+	lecturers := &fakeListLecturerRepo{students: []uuid.UUID{studentID}, list: repo}
+	students := &fakeListStudentRepo{}
+	svc := NewAchievementService(repo, nil, students, lecturers, nil, fakeListDelegationRepo{}, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name        string
+		role        string
+		target      string
+		wantDeleted bool
+	}{
+		{name: "mahasiswa", role: "mahasiswa", target: "/achievements"},
+		{name: "mahasiswa dengan pagination", role: "mahasiswa", target: "/achievements?page=1&limit=50"},
+		{name: "dosen wali", role: "dosen_wali", target: "/achievements?page=1&limit=50"},
+		{name: "admin", role: "admin", target: "/achievements?page=1&limit=50"},
+		{name: "admin termasuk deleted", role: "admin", target: "/achievements?page=1&limit=50&includeDeleted=true", wantDeleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.batchQueries.Store(0)
+			repo.singleQueries.Store(0)
+			students.calls.Store(0)
+			// Halaman berisi prestasi deleted: detail diambil lewat ...WithDeleted, tetap 1 query.
+			repo.refs[0].Status = model.StatusSubmitted
+			if tt.wantDeleted {
+				repo.refs[0].Status = model.StatusDeleted
+			}
+			r := gin.New()
+			r.GET("/achievements", func(c *gin.Context) {
+				c.Set("role", tt.role)
+				c.Set("userID", uuid.New())
+				c.Set("studentID", studentID)
+				c.Set("lecturerID", uuid.New())
+			}, svc.GetAchievements)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			checkEnvelope(t, w)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			if got := repo.batchQueries.Load(); got != 1 {
+				t.Fatalf("%d query detail Mongo untuk %d prestasi, want 1", got, n)
+			}
+			if got := repo.withDeleted.Load(); got != tt.wantDeleted {
+				t.Fatalf("query detail WithDeleted = %v, want %v", got, tt.wantDeleted)
+			}
+			if got := repo.singleQueries.Load(); got != 0 {
+				t.Fatalf("FindDetailByMongoID dipanggil %d kali", got)
+			}
+			// Identitas mahasiswa: 1 query FindByIDs dengan ID tanpa duplikat.
+			if got := students.calls.Load(); got != 1 || len(students.ids) != 1 || students.ids[0] != studentID {
+				t.Fatalf("FindByIDs dipanggil %d kali dengan %v, want 1 kali dengan [%s]", got, students.ids, studentID)
+			}
+
+			var resp struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var items []map[string]any
+			if err := json.Unmarshal(resp.Data, &items); err != nil {
+				var paged struct {
+					Items []map[string]any `json:"items"`
+				}
+				if err := json.Unmarshal(resp.Data, &paged); err != nil {
+					t.Fatal(err)
+				}
+				items = paged.Items
+			}
+			if len(items) != n {
+				t.Fatalf("%d item, want %d", len(items), n)
+			}
+			for i, item := range items {
+				if item["studentName"] != "Budi" || item["studentNumber"] != "2024001" || item["programStudy"] != "Informatika" {
+					t.Fatalf("item %d tanpa identitas mahasiswa: %v", i, item)
+				}
+				_, hasTitle := item["title"]
+				if item["id"] == missing.String() {
+					if hasTitle || item["points"] != nil {
+						t.Fatalf("item tanpa dokumen Mongo berisi title/points: %v", item)
+					}
+					continue
+				}
+				if item["title"] != fmt.Sprintf("Prestasi %d", i) {
+					t.Fatalf("item %d: title %v, want %q", i, item["title"], fmt.Sprintf("Prestasi %d", i))
+				}
+			}
+		})
+	}
+}
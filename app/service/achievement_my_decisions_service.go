@@ -0,0 +1,128 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===============================================================
+//  MY DECISIONS — riwayat keputusan dosen wali yang login
+//  Endpoint: GET /api/v1/achievements/my-decisions?decision=verified|rejected&from=&to=&page=1&limit=10
+//  - Hanya prestasi dengan verified_by = user yang login (termasuk keputusan sebagai delegasi)
+//  - from/to: tanggal keputusan YYYY-MM-DD (to inklusif)
+//  - Terbaru dulu (verified_at DESC); judul diambil dari Mongo sekaligus (1 query $in)
+// ===============================================================
+func (s *achievementService) GetMyDecisions(ctx *gin.Context) {
+	if getRoleFromContext(ctx) != "dosen_wali" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya dosen wali yang dapat melihat riwayat keputusan", "forbidden", nil)
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil || userID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi dosen wali diperlukan", "no_user_id", nil)
+		return
+	}
+
+	var decision *string
+	switch v := ctx.Query("decision"); v {
+	case "":
+	case model.StatusVerified, model.StatusRejected:
+		decision = &v
+	default:
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"decision tidak dikenali", "invalid_decision",
+			map[string]any{"allowed": []string{model.StatusVerified, model.StatusRejected}})
+		return
+	}
+
+	var from, to *time.Time
+	for _, p := range []struct {
+		param string
+		dst   **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := ctx.Query(p.param)
+		if v == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Tanggal "+p.param+" harus berformat YYYY-MM-DD", "invalid_date", map[string]any{"param": p.param})
+			return
+		}
+		*p.dst = &t
+	}
+	if to != nil {
+		end := to.AddDate(0, 0, 1) // to inklusif: sampai akhir hari tsb
+		to = &end
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Tanggal from tidak boleh setelah to", "invalid_date_range", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	refs, total, err := s.repo.FindByVerifier(ctx.Request.Context(), userID, decision, from, to, page, limit)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil riwayat keputusan", err.Error(), nil)
+		return
+	}
+	if err := s.repo.AttachIdentities(ctx.Request.Context(), refs); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data mahasiswa", err.Error(), nil)
+		return
+	}
+
+	mongoIDs := make([]string, 0, len(refs))
+	for _, r := range refs {
+		mongoIDs = append(mongoIDs, r.MongoAchievementID)
+	}
+	// Gagal ambil detail tidak menggagalkan list: item tampil tanpa title.
+	details, _ := s.repo.FindDetailsByMongoIDs(ctx, mongoIDs)
+
+	items := make([]map[string]any, 0, len(refs))
+	for _, r := range refs {
+		item := map[string]any{
+			"id":                r.ID,
+			"studentId":         r.StudentID,
+			"decision":          r.Status,
+			"decidedAt":         r.VerifiedAt,
+			"submittedAt":       r.SubmittedAt,
+			"rejectionNote":     r.RejectionNote,
+			"rejectionCategory": r.RejectionCategory,
+			"asDelegateOf":      r.VerifiedAsDelegateOf,
+		}
+		addStudentIdentity(item, r)
+		if md := details[r.MongoAchievementID]; md != nil {
+			item["title"] = md.Title
+			item["type"] = md.AchievementType
+		}
+		items = append(items, item)
+	}
+
+	page, limit = repository.NormalizePagination(page, limit)
+	utils.RespondOK(ctx,
+		"Berhasil mengambil riwayat keputusan", map[string]any{
+			"items": items,
+			"meta": map[string]any{
+				"page":      page,
+				"limit":     limit,
+				"totalData": total,
+				"totalPage": (total + int64(limit) - 1) / int64(limit),
+			},
+		})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"strings"
+
+	"student-achievement-backend/app/model"
+)
+
+// noPointRuleWarning dikirim di response create/update jika tidak ada aturan poin yang cocok.
+const noPointRuleWarning = "Tidak ada aturan poin yang cocok dengan detail prestasi, poin diisi 0"
+
+// calculatePoints menghitung poin dari rubrik point_rules untuk tipe & details prestasi.
+// matched=false jika tidak ada aturan yang cocok (poin 0).
+func (s *achievementService) calculatePoints(achievementType string, details model.AchievementDetails) (points float64, matched bool, err error) {
+	rules, err := s.pointRuleRepo.FindAll(strings.ToLower(strings.TrimSpace(achievementType)))
+	if err != nil {
+		return 0, false, err
+	}
+	rule := matchPointRule(rules, details)
+	if rule == nil {
+		return 0, false, nil
+	}
+	return rule.Points, true, nil
+}
+
+// matchPointRule memilih aturan paling spesifik (kolom opsional terisi terbanyak) yang cocok
+// dengan details; jika sama spesifiknya, poin terbesar yang dipakai. nil = tidak ada yang cocok.
+func matchPointRule(rules []model.PointRule, d model.AchievementDetails) *model.PointRule {
+	var best *model.PointRule
+	bestScore := -1
+	for i := range rules {
+		rule := &rules[i]
+		score, ok := pointRuleScore(rule, d)
+		if !ok {
+			continue
+		}
+		if score > bestScore || (score == bestScore && rule.Points > best.Points) {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// pointRuleScore: ok=false jika ada kolom aturan yang tidak cocok; score = jumlah kolom opsional yang terisi.
+func pointRuleScore(rule *model.PointRule, d model.AchievementDetails) (score int, ok bool) {
+	if rule.CompetitionLevel != nil {
+		if d.CompetitionLevel == nil || !strings.EqualFold(strings.TrimSpace(*d.CompetitionLevel), *rule.CompetitionLevel) {
+			return 0, false
+		}
+		score++
+	}
+	if rule.Rank != nil {
+		if d.Rank == nil || *d.Rank != *rule.Rank {
+			return 0, false
+		}
+		score++
+	}
+	if rule.MedalType != nil {
+		if d.MedalType == nil || !strings.EqualFold(strings.TrimSpace(*d.MedalType), *rule.MedalType) {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeCreateRepo menyimpan dokumen Mongo terakhir yang dibuat CreateAchievement.
+type fakeCreateRepo struct {
+	repository.AchievementRepository
+	created *model.Achievement
+}
+
+func (r *fakeCreateRepo) Create(_ context.Context, pg *model.AchievementReference, doc *model.Achievement) error {
+	doc.ID = primitive.NewObjectID()
+	pg.ID, pg.MongoAchievementID = uuid.New(), doc.ID.Hex()
+	r.created = doc
+	return nil
+}
+
+func (r *fakeCreateRepo) FindDuplicateCandidates(context.Context, uuid.UUID, string, *time.Time, string) ([]string, error) {
+	return nil, nil
+}
+
+// fakeStudentRepo: setiap students.id dianggap ada.
+type fakeStudentRepo struct {
+	repository.UserRepository
+}
+
+func (fakeStudentRepo) FindStudentByID(id uuid.UUID) (*model.Student, error) {
+	return &model.Student{ID: id}, nil
+}
+
+// TestCreateAchievementFractionalPoints: "points" 0, bulat, dan pecahan (mis. 2.5) dari admin lolos
+// binding dan tersimpan apa adanya di dokumen Mongo serta response.
+func TestCreateAchievementFractionalPoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		points any
+		want   float64
+	}{
+		{name: "nol", points: 0, want: 0},
+		{name: "bulat", points: 15, want: 15},
+		{name: "pecahan", points: 2.5, want: 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeCreateRepo{}
+			types := &fakeTypeRepo{types: []model.AchievementType{{Code: "seminar", Label: "Seminar", Active: true}}}
+			svc := NewAchievementService(repo, fakeStudentRepo{}, nil, nil, nil, nil, nil, nil, types, nil, nil, nil)
+
+			r := gin.New()
+			r.POST("/achievements", func(c *gin.Context) { c.Set("role", "admin") }, svc.CreateAchievement)
+			w, data := doJSON(t, r, http.MethodPost, "/achievements", "", map[string]any{
+				"studentId":       uuid.NewString(),
+				"achievementType": "seminar",
+				"title":           "Pemakalah seminar nasional",
+				"points":          tt.points,
+			})
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			if repo.created == nil || repo.created.Points != tt.want {
+				t.Fatalf("dokumen tersimpan %+v, want points %v", repo.created, tt.want)
+			}
+			if got := data["points"]; got != tt.want {
+				t.Fatalf("response points %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
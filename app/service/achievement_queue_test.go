@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+func TestQueueCursor(t *testing.T) {
+	id := uuid.MustParse("7f1c6f1e-3b7a-4d53-9a57-0c1d2e3f4a5b")
+	at := time.Date(2026, 3, 1, 8, 30, 0, 123000000, time.UTC)
+	tests := []struct {
+		name    string
+		ref     model.AchievementReference
+		cursor  string
+		wantErr bool
+	}{
+		{name: "submitted_at terisi", ref: model.AchievementReference{ID: id, SubmittedAt: &at},
+			cursor: "2026-03-01T08:30:00.123Z," + id.String()},
+		{name: "submitted_at NULL", ref: model.AchievementReference{ID: id}, cursor: "null," + id.String()},
+		{name: "tanpa id", cursor: "2026-03-01T08:30:00Z", wantErr: true},
+		{name: "id tidak valid", cursor: "2026-03-01T08:30:00Z,abc", wantErr: true},
+		{name: "waktu tidak valid", cursor: "kemarin," + id.String(), wantErr: true},
+		{name: "cursor opaque lama", cursor: "MjAyNi0wMy0wMVQwODozMDowMFp8YWJj", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQueueCursor(tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseQueueCursor(%q) err = %v, wantErr %v", tt.cursor, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if formatted := formatQueueCursor(tt.ref); formatted != tt.cursor {
+				t.Fatalf("formatQueueCursor = %q, want %q", formatted, tt.cursor)
+			}
+			if got.ID != tt.ref.ID || (got.SubmittedAt == nil) != (tt.ref.SubmittedAt == nil) ||
+				(got.SubmittedAt != nil && !got.SubmittedAt.Equal(*tt.ref.SubmittedAt)) {
+				t.Fatalf("parseQueueCursor(%q) = %+v, want %+v", tt.cursor, got, tt.ref)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeNoStudentRepo: profil mahasiswa tidak ditemukan (perkiraan waktu review dilewati).
+type fakeNoStudentRepo struct {
+	repository.UserRepository
+}
+
+func (fakeNoStudentRepo) FindStudentByID(uuid.UUID) (*model.Student, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+// TestSubmitResubmitTransitions: /submit hanya dari draft/expired, /resubmit hanya dari rejected;
+// pengajuan ulang mengosongkan keputusan lama di reference dan menyimpannya di audit.
+func TestSubmitResubmitTransitions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	studentID, verifierID := uuid.New(), uuid.New()
+	decidedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	note, category := "bukti kurang", "insufficient_evidence"
+
+	tests := []struct {
+		endpoint   string
+		from       string
+		wantCode   int
+		wantStatus string
+		wantClear  bool // keputusan lama dikosongkan
+	}{
+		{endpoint: "submit", from: model.StatusDraft, wantCode: http.StatusOK, wantStatus: model.StatusSubmitted},
+		{endpoint: "submit", from: model.StatusExpired, wantCode: http.StatusOK, wantStatus: model.StatusSubmitted},
+		{endpoint: "submit", from: model.StatusRejected, wantCode: http.StatusBadRequest, wantStatus: model.StatusRejected},
+		{endpoint: "submit", from: model.StatusSubmitted, wantCode: http.StatusBadRequest, wantStatus: model.StatusSubmitted},
+		{endpoint: "submit", from: model.StatusVerified, wantCode: http.StatusBadRequest, wantStatus: model.StatusVerified},
+		{endpoint: "resubmit", from: model.StatusRejected, wantCode: http.StatusOK, wantStatus: model.StatusSubmitted, wantClear: true},
+		{endpoint: "resubmit", from: model.StatusDraft, wantCode: http.StatusBadRequest, wantStatus: model.StatusDraft},
+		{endpoint: "resubmit", from: model.StatusSubmitted, wantCode: http.StatusBadRequest, wantStatus: model.StatusSubmitted},
+		{endpoint: "resubmit", from: model.StatusVerified, wantCode: http.StatusBadRequest, wantStatus: model.StatusVerified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint+" dari "+tt.from, func(t *testing.T) {
+			ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: tt.from}
+			if tt.from == model.StatusRejected || tt.from == model.StatusVerified {
+				ref.VerifiedAt, ref.VerifiedBy = &decidedAt, &verifierID
+			}
+			if tt.from == model.StatusRejected {
+				ref.RejectionNote, ref.RejectionCategory = &note, &category
+			}
+			repo := newFakeAchievementRepo(ref)
+			audit := &fakeAuditRepo{}
+			svc := NewAchievementService(repo, fakeNoStudentRepo{}, nil, nil, audit, nil, nil, nil, nil, nil, nil, nil)
+
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("role", "mahasiswa")
+				c.Set("studentID", studentID)
+				c.Set("userID", uuid.New())
+			})
+			r.POST("/achievements/:id/submit", svc.SubmitForVerification)
+			r.POST("/achievements/:id/resubmit", svc.ResubmitAchievement)
+
+			w, _ := doJSON(t, r, http.MethodPost, "/achievements/"+ref.ID.String()+"/"+tt.endpoint, "", map[string]any{"force": true})
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.wantCode, w.Body)
+			}
+			got := repo.refs[ref.ID]
+			if got.Status != tt.wantStatus {
+				t.Fatalf("status prestasi %s, want %s", got.Status, tt.wantStatus)
+			}
+			if tt.wantCode != http.StatusOK {
+				if len(repo.updates) != 0 {
+					t.Fatalf("transisi ditolak tetapi UpdateStatus dipanggil: %+v", repo.updates)
+				}
+				return
+			}
+
+			opts := repo.updates[0].Opts
+			if opts.ExpectedStatus != tt.from || opts.ClearDecision != tt.wantClear {
+				t.Fatalf("opsi UpdateStatus %+v, want ExpectedStatus %s ClearDecision %v", opts, tt.from, tt.wantClear)
+			}
+			resubmitAudited := slices.Contains(audit.actions, "achievement.resubmit")
+			if resubmitAudited != tt.wantClear {
+				t.Fatalf("audit achievement.resubmit tercatat = %v, want %v", resubmitAudited, tt.wantClear)
+			}
+			if tt.wantClear && (got.VerifiedAt != nil || got.VerifiedBy != nil || got.RejectionNote != nil || got.RejectionCategory != nil) {
+				t.Fatalf("keputusan lama masih ada di reference: %+v", got)
+			}
+		})
+	}
+}
+
+// TestEditableStatuses: isi prestasi boleh diubah saat draft, rejected (sebelum diajukan ulang),
+// dan expired; status lain ditolak.
+func TestEditableStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	studentID := uuid.New()
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{model.StatusDraft, http.StatusOK},
+		{model.StatusRejected, http.StatusOK},
+		{model.StatusExpired, http.StatusOK},
+		{model.StatusSubmitted, http.StatusBadRequest},
+		{model.StatusVerified, http.StatusBadRequest},
+		{model.StatusDeleted, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: tt.status}
+			svc := NewAchievementService(newFakeAchievementRepo(ref), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).(*achievementService)
+			r := gin.New()
+			r.PUT("/achievements/:id", func(c *gin.Context) {
+				c.Set("role", "mahasiswa")
+				c.Set("studentID", studentID)
+				if _, ok := svc.findEditableAchievement(c); ok {
+					utils.RespondOK(c, "ok", nil)
+				}
+			})
+			w, _ := doJSON(t, r, http.MethodPut, "/achievements/"+ref.ID.String(), "", map[string]any{})
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// recordRevision menyimpan isi prestasi sebelum diubah (previous) sebagai revisi.
+// Dipanggil setelah PUT/PATCH berhasil; gagal menyimpan revisi tidak membatalkan perubahan.
+func (s *achievementService) recordRevision(ctx *gin.Context, previous *model.Achievement) {
+	_ = s.revisionRepo.Create(ctx.Request.Context(), revisionOf(ctx, previous))
+}
+
+// revisionOf menyalin isi prestasi sebelum diubah menjadi revisi (belum disimpan).
+func revisionOf(ctx *gin.Context, previous *model.Achievement) *model.AchievementRevision {
+	editedBy, _ := getUserIDFromContext(ctx)
+	return &model.AchievementRevision{
+		AchievementID: previous.ID,
+		Title:         previous.Title,
+		Description:   previous.Description,
+		Details:       previous.Details,
+		Tags:          previous.Tags,
+		Points:        previous.Points,
+		Attachments:   previous.Attachments,
+		UpdatedAt:     previous.UpdatedAt,
+		EditedBy:      editedBy,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// fieldChange adalah 1 perbedaan field antara revisi dan versi saat ini.
+type fieldChange struct {
+	Field string `json:"field"` // mis. "title", "details.competitionLevel"
+	From  any    `json:"from"`  // nilai di revisi
+	To    any    `json:"to"`    // nilai saat ini
+}
+
+// diffRevision membandingkan revisi dengan dokumen saat ini per field;
+// details dibandingkan per key (nama key sesuai dokumen Mongo).
+func diffRevision(rev model.AchievementRevision, current *model.Achievement) []fieldChange {
+	changes := []fieldChange{}
+	add := func(field string, from, to any) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, fieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("title", rev.Title, current.Title)
+	add("description", rev.Description, current.Description)
+	add("tags", normalizeTags(rev.Tags), normalizeTags(current.Tags))
+	add("points", rev.Points, current.Points)
+
+	from, to := detailsAsMap(rev.Details), detailsAsMap(current.Details)
+	keys := map[string]bool{}
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		add("details."+k, from[k], to[k])
+	}
+	return changes
+}
+
+// normalizeTags: nil dan slice kosong dianggap sama.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return []string{}
+	}
+	return tags
+}
+
+// detailsAsMap mengubah details menjadi map berkunci nama field Mongo (field kosong tidak ada).
+func detailsAsMap(d model.AchievementDetails) map[string]any {
+	out := map[string]any{}
+	raw, err := bson.Marshal(d)
+	if err != nil {
+		return out
+	}
+	var m bson.M
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return out
+	}
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ===============================================================
+//  REVISIONS — riwayat isi prestasi
+//  Endpoint: GET /api/v1/achievements/:id/revisions?diff=true
+//  - Akses sama dengan DetailAchievement
+//  - Revisi terbaru dulu (maks. model.AchievementRevisionLimit)
+//  - diff=true: tiap revisi disertai daftar field yang berbeda dari versi saat ini
+// ===============================================================
+func (s *achievementService) GetAchievementRevisions(ctx *gin.Context) {
+	ref, _, ok := s.findViewableAchievement(ctx)
+	if !ok {
+		return
+	}
+
+	current, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil detail prestasi", err.Error(), nil)
+		return
+	}
+
+	revisions, err := s.revisionRepo.FindByAchievement(ctx.Request.Context(), current.ID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil riwayat revisi prestasi", err.Error(), nil)
+		return
+	}
+
+	withDiff := ctx.Query("diff") == "true"
+	items := make([]map[string]any, 0, len(revisions))
+	for _, rev := range revisions {
+		item := map[string]any{
+			"id":          rev.ID,
+			"title":       rev.Title,
+			"description": rev.Description,
+			"details":     rev.Details,
+			"tags":        rev.Tags,
+			"points":      rev.Points,
+			"attachments": absoluteAttachments(rev.Attachments),
+			"updatedAt":   rev.UpdatedAt,
+			"editedBy":    rev.EditedBy,
+			"replacedAt":  rev.CreatedAt,
+		}
+		if withDiff {
+			item["changes"] = diffRevision(rev, current)
+		}
+		items = append(items, item)
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil riwayat revisi prestasi", map[string]any{
+			"achievementId": ref.ID,
+			"revisions":     items,
+		})
+}
@@ -1,64 +1,147 @@
 package service
 
 import (
-	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
-	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"unicode/utf8"
 
 	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
 	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // AchievementService mendefinisikan handler untuk fitur prestasi FR-003 s/d FR-010.
 type AchievementService interface {
-	// FR-003: CreateAchievement — mahasiswa membuat prestasi (status draft).
+	// FR-003: CreateAchievement — mahasiswa (atau admin atas nama mahasiswa) membuat prestasi (status draft).
 	CreateAchievement(ctx *gin.Context)
 	// FR-004: SubmitForVerification — mahasiswa submit draft untuk diverifikasi.
 	SubmitForVerification(ctx *gin.Context)
+	// ResubmitAchievement — mahasiswa mengajukan ulang prestasi yang ditolak (rejected → submitted).
+	ResubmitAchievement(ctx *gin.Context)
+	// WithdrawAchievement — mahasiswa menarik prestasi submitted yang belum diputuskan (submitted → draft).
+	WithdrawAchievement(ctx *gin.Context)
 	// FR-005: DeleteAchievement — mahasiswa menghapus prestasi draft (soft delete).
 	DeleteAchievement(ctx *gin.Context)
 	// FR-006, FR-007, FR-008, FR-010: GetAchievements — list prestasi tergantung role.
 	GetAchievements(ctx *gin.Context)
+	// GetPendingAchievements — dosen wali: antrean prestasi submitted bimbingan (terlama dulu).
+	GetPendingAchievements(ctx *gin.Context) // GET /api/v1/achievements/pending
+	// GetMyDecisions — dosen wali: riwayat keputusan verified/rejected miliknya (terbaru dulu).
+	GetMyDecisions(ctx *gin.Context) // GET /api/v1/achievements/my-decisions
+	// GetTagSuggestions — saran tag autocomplete (scope sesuai role, terbanyak dulu).
+	GetTagSuggestions(ctx *gin.Context) // GET /api/v1/achievements/tags?q=
+	// GetGroupedAchievements — ringkasan prestasi per tipe (jumlah, total poin, 5 terbaru).
+	GetGroupedAchievements(ctx *gin.Context) // GET /api/v1/achievements/grouped
 	// FR-007: VerifyAchievement — dosen wali memverifikasi prestasi.
 	VerifyAchievement(ctx *gin.Context)
 	// FR-008: RejectAchievement — dosen wali menolak prestasi dengan catatan.
 	RejectAchievement(ctx *gin.Context)
+	// BulkRejectAchievements — dosen wali menolak banyak prestasi dengan 1 catatan bersama.
+	BulkRejectAchievements(ctx *gin.Context) // POST /api/v1/achievements/bulk-reject
 
 	// --- Tambahan sesuai SRS 5.4 ---
 	// DetailAchievement — GET /api/v1/achievements/:id (detail gabungan Postgres + Mongo).
 	DetailAchievement(ctx *gin.Context)
 	// UpdateAchievement — PUT /api/v1/achievements/:id (update konten, mahasiswa pemilik).
 	UpdateAchievement(ctx *gin.Context)
+	// PatchAchievement — PATCH /api/v1/achievements/:id (auto-save sebagian field draft).
+	PatchAchievement(ctx *gin.Context)
+	// GetAchievementRevisions — GET /api/v1/achievements/:id/revisions (riwayat isi, ?diff=true).
+	GetAchievementRevisions(ctx *gin.Context)
 	// GetAchievementHistory — GET /api/v1/achievements/:id/history (status history).
 	GetAchievementHistory(ctx *gin.Context)
+	// GetAchievementCertificate — GET /api/v1/achievements/:id/certificate (PDF, hanya verified).
+	GetAchievementCertificate(ctx *gin.Context)
 	// UploadAttachment — Mahasiswa mengunggah bukti prestasi (file).
 	UploadAttachment(ctx *gin.Context) // POST /api/v1/achievements/:id/attachments
+	// DownloadAttachment — GET /api/v1/achievements/:id/attachments/:fileName
+	DownloadAttachment(ctx *gin.Context)
+	// DeleteAttachment — DELETE /api/v1/achievements/:id/attachments/:attachmentId (draft/rejected)
+	DeleteAttachment(ctx *gin.Context)
+	// GetComments / AddComment / DeleteComment — diskusi prestasi (mahasiswa, dosen wali, admin).
+	GetComments(ctx *gin.Context)   // GET    /api/v1/achievements/:id/comments
+	AddComment(ctx *gin.Context)    // POST   /api/v1/achievements/:id/comments
+	DeleteComment(ctx *gin.Context) // DELETE /api/v1/achievements/:id/comments/:commentId
+
+	// --- Admin ---
+	// OverridePoints — PUT /api/v1/admin/achievements/:id/points-override
+	OverridePoints(ctx *gin.Context)
+	// RevertPointsOverride — DELETE /api/v1/admin/achievements/:id/points-override
+	RevertPointsOverride(ctx *gin.Context)
+	// RestoreAchievement — POST /api/v1/admin/achievements/:id/restore
+	RestoreAchievement(ctx *gin.Context)
+	// PurgeAchievement — DELETE /api/v1/admin/achievements/:id[?hard=true&force=true]
+	PurgeAchievement(ctx *gin.Context)
+	// ImportDecisions — POST /api/v1/admin/achievements/import-decisions (CSV keputusan lama)
+	ImportDecisions(ctx *gin.Context)
+	// CheckConsistency — GET /api/v1/admin/achievements/consistency (selisih Postgres/Mongo)
+	CheckConsistency(ctx *gin.Context)
+	// RepairConsistency — POST /api/v1/admin/achievements/consistency/repair?dryRun=
+	RepairConsistency(ctx *gin.Context)
 }
 
 // achievementService adalah implementasi konkret AchievementService.
 type achievementService struct {
-	repo         repository.AchievementRepository
-	userRepo     repository.UserRepository
-	lecturerRepo repository.LecturerRepository // dipakai untuk FR-006/007/008 (advisor)
+	repo          repository.AchievementRepository
+	userRepo      repository.UserRepository
+	studentRepo   repository.StudentRepository // identitas mahasiswa di list & detail
+	lecturerRepo  repository.LecturerRepository // dipakai untuk FR-006/007/008 (advisor)
+	auditRepo     repository.AuditRepository
+	delegRepo     repository.DelegationRepository          // delegasi verifikasi dosen wali
+	commentRepo   repository.AchievementCommentRepository  // diskusi prestasi
+	pointRuleRepo repository.PointRuleRepository           // rubrik perhitungan poin
+	typeRepo      repository.AchievementTypeRepository     // katalog tipe prestasi
+	revisionRepo  repository.AchievementRevisionRepository // riwayat isi prestasi (PUT/PATCH)
+	storage       utils.FileStorage                        // penyimpanan file lampiran
+	scanner       utils.Scanner                            // pemindai malware lampiran
+	estimator     *reviewEstimator                         // perkiraan waktu review untuk mahasiswa
 }
 
 // NewAchievementService membuat instance baru AchievementService.
 func NewAchievementService(
 	repo repository.AchievementRepository,
 	userRepo repository.UserRepository,
+	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	auditRepo repository.AuditRepository,
+	delegRepo repository.DelegationRepository,
+	commentRepo repository.AchievementCommentRepository,
+	pointRuleRepo repository.PointRuleRepository,
+	typeRepo repository.AchievementTypeRepository,
+	revisionRepo repository.AchievementRevisionRepository,
+	storage utils.FileStorage,
+	scanner utils.Scanner,
 ) AchievementService {
 	return &achievementService{
-		repo:         repo,
-		userRepo:     userRepo,
-		lecturerRepo: lecturerRepo,
+		repo:          repo,
+		userRepo:      userRepo,
+		studentRepo:   studentRepo,
+		lecturerRepo:  lecturerRepo,
+		auditRepo:     auditRepo,
+		delegRepo:     delegRepo,
+		commentRepo:   commentRepo,
+		pointRuleRepo: pointRuleRepo,
+		typeRepo:      typeRepo,
+		revisionRepo:  revisionRepo,
+		storage:       storage,
+		scanner:       scanner,
+		estimator:     newReviewEstimator(repo),
 	}
 }
 
@@ -79,6 +162,14 @@ func getStudentIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
 	return uuid.Nil, ErrNoStudentIDInContext
 }
 
+// isOwnLinkedRecord: true jika prestasi milik profil mahasiswa pemanggil (studentID di JWT).
+// Dipakai agar akun tertaut (mantan mahasiswa yang kini dosen wali) tetap bisa membaca
+// riwayat prestasinya sendiri walau role-nya bukan mahasiswa lagi.
+func isOwnLinkedRecord(ctx *gin.Context, ref *model.AchievementReference) bool {
+	studentID, _ := getStudentIDFromContext(ctx)
+	return studentID != uuid.Nil && ref.StudentID == studentID
+}
+
 // getUserIDFromContext mengambil userID dari JWT.
 func getUserIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
 	if v, ok := ctx.Get("userID"); ok {
@@ -89,6 +180,20 @@ func getUserIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
 	return uuid.Nil, &customError{msg: "userID not found in context"}
 }
 
+// lecturerIDFromContext mengembalikan lecturers.id user dosen dari klaim JWT
+// ("lecturerID" di context, diisi saat login). Token lama tanpa klaim tsb
+// jatuh ke query lecturers berdasarkan userID.
+func lecturerIDFromContext(ctx *gin.Context, lecturerRepo repository.LecturerRepository, userID uuid.UUID) (uuid.UUID, error) {
+	if id, ok := getUUIDFromContext(ctx, "lecturerID"); ok && id != uuid.Nil {
+		return id, nil
+	}
+	lecturer, err := lecturerRepo.FindByUserID(userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return lecturer.ID, nil
+}
+
 // getRoleFromContext membaca role dari JWT.
 func getRoleFromContext(ctx *gin.Context) string {
 	if v, ok := ctx.Get("role"); ok {
@@ -99,38 +204,134 @@ func getRoleFromContext(ctx *gin.Context) string {
 	return ""
 }
 
+// checkAdvisorAccess mengecek apakah dosen boleh bertindak atas prestasi studentID:
+// - dosen wali langsung → ok, delegateOf nil
+// - delegasi aktif dari dosen wali mahasiswa → ok, delegateOf = lecturers.id dosen wali asli
+func (s *achievementService) checkAdvisorAccess(lecturerID, studentID uuid.UUID) (ok bool, delegateOf *uuid.UUID, err error) {
+	ok, err = s.lecturerRepo.IsAdvisorOf(lecturerID, studentID)
+	if err != nil || ok {
+		return ok, nil, err
+	}
+	delegateOf, err = s.delegRepo.FindActiveDelegatorForStudent(lecturerID, studentID, time.Now())
+	if err != nil || delegateOf == nil {
+		return false, nil, err
+	}
+	return true, delegateOf, nil
+}
+
 // ===============================================================
-//  FR-003: CreateAchievement (Mahasiswa)
+//  FR-003: CreateAchievement (Mahasiswa / Admin atas nama mahasiswa)
 //  Endpoint: POST /api/v1/achievements
+//  Body: JSON, atau multipart/form-data dengan bagian "data" (JSON yang sama) dan
+//  lampiran di bagian "files" (boleh lebih dari 1).
+//  Mahasiswa dibatasi MAX_DRAFTS_PER_STUDENT draft aktif (409 too_many_drafts).
 // ===============================================================
 func (s *achievementService) CreateAchievement(ctx *gin.Context) {
 	role := getRoleFromContext(ctx)
-	if role != "mahasiswa" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya mahasiswa yang dapat membuat prestasi", "forbidden", nil))
-		return
-	}
-
-	studentID, err := getStudentIDFromContext(ctx)
-	if err != nil || studentID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+	if role != "mahasiswa" && role != "admin" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa atau admin yang dapat membuat prestasi", "forbidden", nil)
 		return
 	}
 
 	var input struct {
+		// StudentID hanya dipakai admin yang membuat prestasi atas nama mahasiswa (students.id).
+		StudentID       string                   `json:"studentId"`
 		AchievementType string                   `json:"achievementType" binding:"required"`
 		Title           string                   `json:"title" binding:"required"`
 		Description     string                   `json:"description"`
 		Details         model.AchievementDetails `json:"details"`
 		Tags            []string                 `json:"tags"`
-		Points          int                      `json:"points"`
-		Attachments     []model.Attachment       `json:"attachments"`
+		// Points hanya dipakai jika pemanggil admin; selain itu dihitung dari point_rules.
+		// Lampiran tidak diterima di sini: diunggah lewat bagian multipart "files" atau
+		// POST /:id/attachments agar setiap lampiran punya id & file yang dikelola server.
+		Points *float64 `json:"points"`
+		// ExternalRef hanya untuk admin: ID prestasi di sistem lama (dipakai impor keputusan).
+		ExternalRef string `json:"externalRef"`
+	}
+
+	withFiles := ctx.ContentType() == binding.MIMEMultipartPOSTForm
+	bind := utils.BindStrictJSON
+	if withFiles {
+		bind = utils.BindStrictMultipartJSON
+	}
+	if err := bind(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input tidak valid", err.Error(), nil)
+		return
+	}
+	achievementType, ok := s.resolveAchievementType(ctx, input.AchievementType)
+	if !ok || !validateAchievementDetails(ctx, achievementType.Code, input.Details) {
+		return
+	}
+
+	externalRef := strings.TrimSpace(input.ExternalRef)
+	if externalRef != "" && role != "admin" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya admin yang dapat mengisi externalRef", "forbidden", nil)
+		return
+	}
+	if len(externalRef) > 100 {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"externalRef maksimal 100 karakter", "invalid_external_ref", nil)
+		return
+	}
+	if externalRef != "" {
+		if existing, err := s.repo.FindByExternalRef(ctx.Request.Context(), externalRef); err == nil {
+			utils.RespondError(ctx, http.StatusConflict,
+				"externalRef sudah dipakai prestasi lain", "external_ref_taken",
+				map[string]any{"achievementId": existing.ID})
+			return
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal memeriksa externalRef", err.Error(), nil)
+			return
+		}
 	}
 
-	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+	var studentID uuid.UUID
+	if role == "admin" {
+		// Admin membuat atas nama mahasiswa → tidak terkena batas draft
+		sid, err := uuid.Parse(input.StudentID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"studentId wajib diisi dan harus UUID valid", "invalid_student_id", nil)
+			return
+		}
+		if _, err := s.userRepo.FindStudentByID(sid); err != nil {
+			utils.RespondError(ctx, http.StatusNotFound,
+				"Mahasiswa tidak ditemukan", err.Error(), nil)
+			return
+		}
+		studentID = sid
+	} else {
+		sid, err := getStudentIDFromContext(ctx)
+		if err != nil || sid == uuid.Nil {
+			utils.RespondError(ctx, http.StatusUnauthorized,
+				"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
+			return
+		}
+		studentID = sid
+
+		// Batasi jumlah draft agar client yang retry terus tidak menumpuk draft terbengkalai
+		drafts, err := s.repo.CountDraftsByStudent(studentID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal memeriksa jumlah draft", err.Error(), nil)
+			return
+		}
+		if limit := maxDraftsPerStudent(); drafts >= int64(limit) {
+			utils.RespondError(ctx, http.StatusConflict,
+				"Jumlah draft sudah mencapai batas, hapus atau submit draft lama terlebih dahulu", "too_many_drafts", map[string]any{
+					"current": drafts,
+					"max":     limit,
+				})
+			return
+		}
+	}
+
+	points, pointsWarning, ok := s.resolvePoints(ctx, role, achievementType, input.Details, input.Points)
+	if !ok {
 		return
 	}
 
@@ -143,87 +344,365 @@ func (s *achievementService) CreateAchievement(ctx *gin.Context) {
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
+	if externalRef != "" {
+		pg.ExternalRef = &externalRef
+	}
 
 	mongo := model.Achievement{
 		StudentID:       studentID,
-		AchievementType: input.AchievementType,
+		AchievementType: achievementType.Code,
 		Title:           input.Title,
 		Description:     input.Description,
 		Details:         input.Details,
-		Attachments:     input.Attachments,
+		Attachments:     []model.Attachment{},
 		Tags:            input.Tags,
-		Points:          input.Points,
+		Points:          points,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 
-	if err := s.repo.Create(context.Background(), &pg, &mongo); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menyimpan prestasi", err.Error(), nil))
+	if err := s.repo.Create(ctx.Request.Context(), &pg, &mongo); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan prestasi", err.Error(), nil)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated,
-		utils.BuildResponseSuccess("Prestasi berhasil disimpan sebagai draft", map[string]any{
-			"id":                 pg.ID,
-			"mongoAchievementId": pg.MongoAchievementID,
-			"status":             pg.Status,
-		}))
+	// Lampiran multipart disimpan ke draft yang baru dibuat (alur sama dengan UploadAttachment).
+	attachments := []model.Attachment{}
+	if withFiles {
+		form, _ := ctx.MultipartForm()
+		for _, fh := range form.File["files"] {
+			attachment, aerr := s.storeAttachment(ctx, pg.ID.String(), fh, "")
+			if aerr != nil {
+				utils.RespondError(ctx, aerr.status,
+					"Prestasi tersimpan sebagai draft, tetapi lampiran gagal disimpan: "+aerr.message, aerr.code,
+					map[string]any{"achievementId": pg.ID, "fileName": fh.Filename})
+				return
+			}
+			attachment.FileURL = utils.AbsoluteURL(attachment.FileURL)
+			attachments = append(attachments, *attachment)
+		}
+	}
+
+	// Peringatan duplikat tidak memblokir pembuatan draft; gagal cek = daftar kosong
+	duplicates, err := s.findDuplicates(ctx, studentID, &mongo, pg.MongoAchievementID)
+	if err != nil {
+		duplicates = []map[string]any{}
+	}
+
+	data := map[string]any{
+		"id":                 pg.ID,
+		"mongoAchievementId": pg.MongoAchievementID,
+		"status":             pg.Status,
+		"points":             points,
+		"possibleDuplicates": duplicates,
+	}
+	if pointsWarning != "" {
+		data["pointsWarning"] = pointsWarning
+	}
+	if withFiles {
+		data["attachments"] = attachments
+	}
+	utils.RespondCreated(ctx,
+		"Prestasi berhasil disimpan sebagai draft", data)
+}
+
+// resolveAchievementType mencari input achievementType (huruf kecil) di katalog tipe aktif.
+// Jika tidak ada, response 400 invalid_achievement_type (berisi kode yang valid) dikirim dan ok=false.
+func (s *achievementService) resolveAchievementType(ctx *gin.Context, code string) (*model.AchievementType, bool) {
+	types, err := s.typeRepo.FindAll(true)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil katalog tipe prestasi", err.Error(), nil)
+		return nil, false
+	}
+	code = strings.ToLower(strings.TrimSpace(code))
+	allowed := make([]string, 0, len(types))
+	for i := range types {
+		if types[i].Code == code {
+			return &types[i], true
+		}
+		allowed = append(allowed, types[i].Code)
+	}
+	utils.RespondError(ctx, http.StatusBadRequest,
+		"achievementType tidak dikenal atau tidak aktif", "invalid_achievement_type", map[string]any{"allowed": allowed})
+	return nil, false
+}
+
+// validateAchievementDetails menjalankan model.ValidateDetails dan model.ValidateDetailDates;
+// jika ada kesalahan, response 400 invalid_details (daftar per field) dikirim dan mengembalikan false.
+func validateAchievementDetails(ctx *gin.Context, achievementType string, details model.AchievementDetails) bool {
+	errs := model.ValidateDetails(achievementType, details)
+	errs = append(errs, model.ValidateDetailDates(details, time.Now(), utils.Runtime().EventHorizonYears)...)
+	if len(errs) == 0 {
+		return true
+	}
+	utils.RespondError(ctx, http.StatusBadRequest,
+		"Detail prestasi tidak sesuai dengan tipe prestasi", "invalid_details", map[string]any{"errors": errs})
+	return false
+}
+
+// resolvePoints menentukan poin prestasi: nilai kiriman admin dipakai apa adanya,
+// selain itu dihitung dari point_rules; jika tidak ada aturan yang cocok dipakai defaultPoints
+// tipe prestasi (+ warning jika defaultPoints juga 0).
+// ok=false berarti response error sudah dikirim.
+func (s *achievementService) resolvePoints(ctx *gin.Context, role string, achievementType *model.AchievementType, details model.AchievementDetails, requested *float64) (points float64, warning string, ok bool) {
+	if role == "admin" && requested != nil {
+		return *requested, "", true
+	}
+	points, matched, err := s.calculatePoints(achievementType.Code, details)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menghitung poin prestasi", err.Error(), nil)
+		return 0, "", false
+	}
+	if !matched {
+		points = achievementType.DefaultPoints
+		if points == 0 {
+			warning = noPointRuleWarning
+		}
+	}
+	return points, warning, true
+}
+
+// findDuplicates mengembalikan prestasi lain (belum dihapus) milik mahasiswa dengan judul
+// ternormalisasi & tanggal kegiatan yang sama dengan doc, beserta statusnya.
+func (s *achievementService) findDuplicates(ctx *gin.Context, studentID uuid.UUID, doc *model.Achievement, excludeMongoID string) ([]map[string]any, error) {
+	duplicates := []map[string]any{}
+
+	mongoIDs, err := s.repo.FindDuplicateCandidates(ctx.Request.Context(), studentID, doc.Title, doc.Details.EventDate, excludeMongoID)
+	if err != nil || len(mongoIDs) == 0 {
+		return duplicates, err
+	}
+
+	sid := studentID.String()
+	refs, _, err := s.repo.FindFiltered(ctx.Request.Context(), repository.AchievementListFilter{
+		StudentID: &sid,
+		MongoIDs:  mongoIDs,
+		Sort:      repository.AchievementSort{Field: repository.SortCreatedAt, Asc: true},
+		Unpaged:   true,
+	})
+	if err != nil {
+		return duplicates, err
+	}
+	for _, ref := range refs {
+		duplicates = append(duplicates, map[string]any{
+			"id":        ref.ID,
+			"status":    ref.Status,
+			"createdAt": ref.CreatedAt,
+		})
+	}
+	return duplicates, nil
 }
 
 // ===============================================================
 //  FR-004: SubmitForVerification (Mahasiswa)
 //  Endpoint: POST /api/v1/achievements/:id/submit
+//  Prestasi draft, atau expired (pengajuan lama kedaluwarsa) yang diajukan lagi.
 // ===============================================================
 func (s *achievementService) SubmitForVerification(ctx *gin.Context) {
+	s.submitAchievement(ctx, model.StatusDraft, model.StatusExpired)
+}
+
+// ===============================================================
+//  ResubmitAchievement (Mahasiswa)
+//  Endpoint: POST /api/v1/achievements/:id/resubmit
+//  Prestasi rejected (biasanya sudah diperbaiki lewat PUT) diajukan ulang.
+//  Keputusan sebelumnya disimpan di audit log dan tetap tampil di riwayat,
+//  tetapi dikosongkan di reference agar dosen wali melihat pengajuan baru.
+// ===============================================================
+func (s *achievementService) ResubmitAchievement(ctx *gin.Context) {
+	s.submitAchievement(ctx, model.StatusRejected)
+}
+
+// ===============================================================
+//  WithdrawAchievement (Mahasiswa)
+//  Endpoint: POST /api/v1/achievements/:id/withdraw
+//  Prestasi submitted yang belum diputuskan ditarik kembali menjadi draft
+//  (submitted_at dikosongkan) agar bisa diperbaiki. Sudah verified/rejected → 409.
+// ===============================================================
+func (s *achievementService) WithdrawAchievement(ctx *gin.Context) {
+	if getRoleFromContext(ctx) != "mahasiswa" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat menarik prestasi", "forbidden", nil)
+		return
+	}
+
+	studentID, err := getStudentIDFromContext(ctx)
+	if err != nil || studentID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
+		return
+	}
+
+	id := ctx.Param("id")
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+	if ref.StudentID != studentID {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak menarik prestasi ini", "forbidden", nil)
+		return
+	}
+
+	respondDecided := func(status string) {
+		utils.RespondError(ctx, http.StatusConflict,
+			"Prestasi sudah diputuskan dosen wali dan tidak dapat ditarik", "already_decided",
+			map[string]any{"currentStatus": status})
+	}
+	switch ref.Status {
+	case model.StatusSubmitted:
+	case model.StatusVerified, model.StatusRejected:
+		respondDecided(ref.Status)
+		return
+	default:
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Hanya prestasi berstatus 'submitted' yang dapat ditarik", "invalid_status", nil)
+		return
+	}
+
+	userID, _ := getUserIDFromContext(ctx)
+	err = s.repo.UpdateStatus(ctx.Request.Context(), id, model.StatusDraft, repository.UpdateStatusOptions{
+		ActorID:        &userID,
+		ExpectedStatus: model.StatusSubmitted,
+	})
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// diputuskan dosen wali di antara pemeriksaan dan update
+		current := ""
+		if latest, ferr := s.repo.FindByID(ctx.Request.Context(), id); ferr == nil {
+			current = latest.Status
+		}
+		respondDecided(current)
+		return
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menarik prestasi", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Prestasi ditarik kembali menjadi draft", map[string]any{"id": ref.ID, "status": model.StatusDraft})
+}
+
+// submitAchievement memindahkan prestasi milik mahasiswa dari salah satu status allowed ke submitted.
+func (s *achievementService) submitAchievement(ctx *gin.Context, allowed ...string) {
 	role := getRoleFromContext(ctx)
 	if role != "mahasiswa" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya mahasiswa yang dapat submit prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat submit prestasi", "forbidden", nil)
 		return
 	}
 
 	studentID, err := getStudentIDFromContext(ctx)
 	if err != nil || studentID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
 		return
 	}
 
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
 		return
 	}
 
 	if ref.StudentID != studentID {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Anda tidak berhak submit prestasi ini", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak submit prestasi ini", "forbidden", nil)
 		return
 	}
 
-	if ref.Status != "draft" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Prestasi hanya bisa disubmit jika status draft", "invalid_status", nil))
+	if !slices.Contains(allowed, ref.Status) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Prestasi hanya bisa disubmit jika status "+strings.Join(allowed, "/"), "invalid_status", nil)
 		return
 	}
+	from := ref.Status
+
+	// force=true: mahasiswa sudah melihat peringatan duplikat dan tetap ingin submit
+	var input struct {
+		Force bool `json:"force"`
+	}
+	if ctx.Request.ContentLength != 0 {
+		if err := utils.BindStrictJSON(ctx, &input); err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Input tidak valid", err.Error(), nil)
+			return
+		}
+	}
+
+	if !input.Force {
+		doc, err := s.repo.FindDetailByMongoID(ctx.Request.Context(), ref.MongoAchievementID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil detail prestasi", err.Error(), nil)
+			return
+		}
+		duplicates, err := s.findDuplicates(ctx, studentID, doc, ref.MongoAchievementID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal memeriksa duplikat prestasi", err.Error(), nil)
+			return
+		}
+		if len(duplicates) > 0 {
+			utils.RespondError(ctx, http.StatusConflict,
+				"Prestasi dengan judul dan tanggal kegiatan yang sama sudah ada, kirim force=true untuk tetap submit",
+				"possible_duplicate", map[string]any{"duplicates": duplicates})
+			return
+		}
+	}
 
-	if err := s.repo.UpdateStatus(id, "submitted", repository.UpdateStatusOptions{}); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal submit prestasi", err.Error(), nil))
+	userID, _ := getUserIDFromContext(ctx)
+	opts := repository.UpdateStatusOptions{
+		ActorID:        &userID,
+		ClearDecision:  from == model.StatusRejected,
+		ExpectedStatus: from,
+	}
+	err = s.repo.UpdateStatus(ctx.Request.Context(), id, model.StatusSubmitted, opts)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		s.respondStatusConflict(ctx, id)
 		return
 	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal submit prestasi", err.Error(), nil)
+		return
+	}
+
+	if from == model.StatusRejected {
+		_ = s.auditRepo.Record(ctx, &userID, "achievement.resubmit", "achievement", id, previousDecision{
+			SubmittedAt:       ref.SubmittedAt,
+			VerifiedAt:        ref.VerifiedAt,
+			VerifiedBy:        ref.VerifiedBy,
+			AsDelegateOf:      ref.VerifiedAsDelegateOf,
+			RejectionNote:     ref.RejectionNote,
+			RejectionCategory: ref.RejectionCategory,
+			Imported:          ref.DecisionImported,
+			// isi catatan privat tidak disalin ke audit (lihat recordInternalNote)
+			HadInternalNote: ref.InternalNote != nil,
+		})
+	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Prestasi berhasil disubmit", nil))
+	ref.Status = model.StatusSubmitted
+	data := map[string]any{"id": ref.ID, "status": ref.Status}
+	if est, ok := s.reviewEstimateFor(ref); ok {
+		data["reviewEstimate"] = est
+	}
+
+	utils.RespondOK(ctx,
+		"Prestasi berhasil disubmit", data)
 }
 
 // ===============================================================
@@ -233,59 +712,131 @@ func (s *achievementService) SubmitForVerification(ctx *gin.Context) {
 func (s *achievementService) DeleteAchievement(ctx *gin.Context) {
 	role := getRoleFromContext(ctx)
 	if role != "mahasiswa" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya mahasiswa yang dapat menghapus prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat menghapus prestasi", "forbidden", nil)
 		return
 	}
 
 	studentID, err := getStudentIDFromContext(ctx)
 	if err != nil || studentID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
 		return
 	}
 
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
 		return
 	}
 
 	if ref.StudentID != studentID {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Anda tidak berhak menghapus prestasi ini", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak menghapus prestasi ini", "forbidden", nil)
 		return
 	}
 
 	if ref.Status != "draft" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Hanya prestasi draft yang dapat dihapus", "invalid_status", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Hanya prestasi draft yang dapat dihapus", "invalid_status", nil)
 		return
 	}
 
-	if err := s.repo.UpdateStatus(id, "deleted", repository.UpdateStatusOptions{}); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menghapus prestasi", err.Error(), nil))
+	userID, _ := getUserIDFromContext(ctx)
+	err = s.repo.UpdateStatus(ctx.Request.Context(), id, "deleted", repository.UpdateStatusOptions{
+		ActorID:        &userID,
+		ExpectedStatus: model.StatusDraft,
+	})
+	if errors.Is(err, repository.ErrStatusConflict) {
+		s.respondStatusConflict(ctx, id)
+		return
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menghapus prestasi", err.Error(), nil)
 		return
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Prestasi berhasil dihapus", nil))
+	utils.RespondOK(ctx,
+		"Prestasi berhasil dihapus", nil)
 }
 
 // ===============================================================
-//  Helper: buildAchievementListItem
-//  Membantu membentuk 1 item response list prestasi (reference + detail).
+//  Helper: buildAchievementList / buildAchievementListItem
+//  Membentuk item response list prestasi (reference + detail).
+//  Detail Mongo diambil sekaligus (1 query $in) untuk seluruh halaman.
 // ===============================================================
-func (s *achievementService) buildAchievementListItem(ctx *gin.Context, ref model.AchievementReference) map[string]any {
+func (s *achievementService) buildAchievementList(ctx *gin.Context, refs []model.AchievementReference) []map[string]any {
+	mongoIDs := make([]string, 0, len(refs))
+	for _, r := range refs {
+		mongoIDs = append(mongoIDs, r.MongoAchievementID)
+	}
+	// Prestasi deleted hanya ada di list admin; detailnya sudah ditandai deleted di Mongo.
+	fetch := s.repo.FindDetailsByMongoIDs
+	if slices.ContainsFunc(refs, func(r model.AchievementReference) bool { return r.Status == model.StatusDeleted }) {
+		fetch = s.repo.FindDetailsByMongoIDsWithDeleted
+	}
+	// Gagal ambil detail tidak menggagalkan list: item tampil tanpa title/points.
+	details, _ := fetch(ctx, mongoIDs)
+	// Nama & NIM mahasiswa: 1 query batch, kecuali refs sudah diisi AttachIdentities oleh pemanggil.
+	_ = s.attachStudents(refs)
+
+	list := make([]map[string]any, 0, len(refs))
+	for _, r := range refs {
+		list = append(list, buildAchievementListItem(r, details[r.MongoAchievementID]))
+	}
+	return list
+}
+
+// attachStudents mengisi Student (+User) pada refs yang belum berisi identitas: ID mahasiswa
+// dikumpulkan tanpa duplikat lalu diambil dengan 1 query StudentRepository.FindByIDs.
+func (s *achievementService) attachStudents(refs []model.AchievementReference) error {
+	ids := []uuid.UUID{}
+	for _, r := range refs {
+		if r.Student.ID == uuid.Nil && !slices.Contains(ids, r.StudentID) {
+			ids = append(ids, r.StudentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	students, err := s.studentRepo.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]model.Student, len(students))
+	for _, st := range students {
+		byID[st.ID] = st
+	}
+	for i := range refs {
+		if st, ok := byID[refs[i].StudentID]; ok && refs[i].Student.ID == uuid.Nil {
+			refs[i].Student = st
+		}
+	}
+	return nil
+}
+
+// absoluteAttachments menyalin daftar lampiran dengan FileURL berupa URL lengkap
+// (APP_BASE_URL) untuk response; dokumen Mongo tetap menyimpan path relatif.
+func absoluteAttachments(attachments []model.Attachment) []model.Attachment {
+	out := make([]model.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		attachment.FileURL = utils.AbsoluteURL(attachment.FileURL)
+		out[i] = attachment
+	}
+	return out
+}
+
+// buildAchievementListItem membentuk 1 item; md nil = detail Mongo tidak ditemukan.
+func buildAchievementListItem(ref model.AchievementReference, md *model.Achievement) map[string]any {
 	item := map[string]any{
 		"id":          ref.ID,
 		"studentId":   ref.StudentID,
@@ -301,122 +852,276 @@ func (s *achievementService) buildAchievementListItem(ctx *gin.Context, ref mode
 	if ref.RejectionNote != nil {
 		item["rejectionNote"] = ref.RejectionNote
 	}
+	if ref.RejectionCategory != nil {
+		item["rejectionCategory"] = ref.RejectionCategory
+	}
+	addStudentIdentity(item, ref)
+	if ref.Verifier != nil {
+		item["verifierName"] = ref.Verifier.FullName
+	}
 
-	// Ambil detail dari MongoDB
-	if md, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID); err == nil && md != nil {
+	if md != nil {
 		item["title"] = md.Title
 		item["type"] = md.AchievementType
 		item["points"] = md.Points
 		item["tags"] = md.Tags
+		item["pointsOverridden"] = md.PointsOverride != nil
+		item["attachments"] = absoluteAttachments(md.Attachments)
+		if ref.Status == model.StatusDeleted {
+			item["deletedAt"] = md.DeletedAt
+		}
 	}
 
 	return item
 }
 
+// addStudentIdentity menambahkan nama, NIM, dan program studi mahasiswa pemilik prestasi;
+// tersedia jika ref diisi lewat AttachIdentities.
+func addStudentIdentity(item map[string]any, ref model.AchievementReference) {
+	if ref.Student.ID == uuid.Nil {
+		return
+	}
+	item["studentName"] = ref.Student.User.FullName
+	item["studentNumber"] = ref.Student.StudentID
+	item["programStudy"] = ref.Student.ProgramStudy
+}
+
+// studentListDefaultCap membatasi daftar prestasi mahasiswa tanpa page/limit
+// (format lama tanpa meta) agar tidak memuat seluruh riwayat sekaligus.
+const studentListDefaultCap = 50
+
 // ===============================================================
 //  FR-006 / FR-007 / FR-008 / FR-010: GetAchievements
 //  Endpoint: GET /api/v1/achievements
 //
 //  Perilaku per role:
-//    - Mahasiswa: daftar prestasi miliknya (FR-006 dari sisi mahasiswa)
-//    - Dosen Wali: daftar prestasi mahasiswa bimbingan (FR-006)
-//    - Admin: lihat semua prestasi (FR-010, dengan filter & pagination)
+//    - Mahasiswa: daftar prestasi miliknya (FR-006 dari sisi mahasiswa), pagination opsional
+//    - Dosen Wali: daftar prestasi mahasiswa bimbingan (FR-006, filter status/studentId + pagination)
+//    - Admin: lihat semua prestasi (FR-010, dengan filter & pagination); yang deleted
+//      hanya dengan ?status=deleted atau ?includeDeleted=true
+//      ?search= mencari NIM / nama mahasiswa / judul prestasi
+//      ?cursor= (kosong = halaman pertama) untuk keyset pagination, meta.nextCursor
+//    - ?scope=own: akun tertaut (dosen dengan profil mahasiswa lama) melihat prestasinya sendiri
+//    - API key (achievement:read): hanya prestasi verified, dengan pagination
+//    - Semua role: filter isi ?type=&tags=&from=&to= dan pencarian ?q= (lihat contentFilterMongoIDs)
+//    - Semua role: ?sortBy=createdAt|submittedAt|status|points&sortDir=asc|desc
 // ===============================================================
 func (s *achievementService) GetAchievements(ctx *gin.Context) {
 	role := getRoleFromContext(ctx)
 
+	sortSpec, ok := parseAchievementSort(ctx)
+	if !ok {
+		return
+	}
+
+	if apiKeyCan(ctx, model.PermissionAchievementRead) {
+		s.getVerifiedAchievements(ctx, sortSpec)
+		return
+	}
+
+	// ?scope=own: akun tertaut (role dosen_wali + profil mahasiswa) melihat riwayat prestasinya sendiri
+	if ctx.Query("scope") == "own" && role != "mahasiswa" {
+		if studentID, _ := getStudentIDFromContext(ctx); studentID != uuid.Nil {
+			role = "mahasiswa"
+		}
+	}
+
 	switch role {
 
 	// ================= Mahasiswa =================
 	case "mahasiswa":
 		studentID, err := getStudentIDFromContext(ctx)
 		if err != nil || studentID == uuid.Nil {
-			ctx.JSON(http.StatusUnauthorized,
-				utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+			utils.RespondError(ctx, http.StatusUnauthorized,
+				"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
 			return
 		}
 
-		refs, err := s.repo.FindByStudentID(studentID.String())
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError,
-				utils.BuildResponseFailed("Gagal mengambil prestasi", err.Error(), nil))
+		// Query params: ?status=verified&type=&tags=&from=&to=&page=1&limit=10 (sama seperti admin).
+		// Tanpa page/limit: format lama (array), dibatasi studentListDefaultCap terbaru.
+		statusParam := ctx.Query("status")
+		var status *string
+		if statusParam != "" {
+			status = &statusParam
+		}
+
+		mongoIDs, ok := s.contentFilterMongoIDs(ctx, false)
+		if !ok {
+			return
+		}
+		sid := studentID.String()
+		filter := repository.AchievementListFilter{StudentID: &sid, Status: status, MongoIDs: mongoIDs, Sort: sortSpec}
+		fetch := func(unpaged bool) ([]model.AchievementReference, int64, error) {
+			filter.Unpaged = unpaged
+			return s.repo.FindFiltered(ctx.Request.Context(), filter)
+		}
+
+		_, hasPage := ctx.GetQuery("page")
+		_, hasLimit := ctx.GetQuery("limit")
+		if !hasPage && !hasLimit {
+			filter.Page, filter.Limit = 1, studentListDefaultCap
+			refs, _, err := s.findSorted(ctx, sortSpec, filter.Page, filter.Limit, fetch)
+			if err != nil {
+				utils.RespondError(ctx, http.StatusInternalServerError,
+					"Gagal mengambil prestasi", err.Error(), nil)
+				return
+			}
+
+			list := s.buildAchievementList(ctx, refs) // [] jika kosong
+
+			utils.RespondOK(ctx,
+				"Berhasil mengambil daftar prestasi mahasiswa", list)
 			return
 		}
 
-		var list []map[string]any
-		for _, r := range refs {
-			list = append(list, s.buildAchievementListItem(ctx, r))
+		page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+		page, limit = repository.NormalizePagination(page, limit)
+		filter.Page, filter.Limit = page, limit
+
+		refs, total, err := s.findSorted(ctx, sortSpec, page, limit, fetch)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil prestasi", err.Error(), nil)
+			return
 		}
 
-		ctx.JSON(http.StatusOK,
-			utils.BuildResponseSuccess("Berhasil mengambil daftar prestasi mahasiswa", list))
+		list := s.buildAchievementList(ctx, refs)
+
+		utils.RespondOK(ctx,
+			"Berhasil mengambil daftar prestasi mahasiswa", map[string]any{
+				"items": list,
+				"meta": map[string]any{
+					"page":      page,
+					"limit":     limit,
+					"totalData": total,
+					"totalPage": (total + int64(limit) - 1) / int64(limit),
+				},
+			})
 		return
 
 	// ================= Dosen Wali =================
 	case "dosen_wali":
-		userID, err := getUserIDFromContext(ctx)
-		if err != nil || userID == uuid.Nil {
-			ctx.JSON(http.StatusUnauthorized,
-				utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
+		studentIDs, ok := s.reviewableStudentIDs(ctx)
+		if !ok {
 			return
 		}
 
-		// Ambil lecturer berdasarkan userID
-		lecturer, err := s.lecturerRepo.FindByUserID(userID)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
-			return
+		// Query params: ?status=submitted&studentId=<uuid>&type=&tags=&from=&to=&page=1&limit=10
+		filter := repository.AdviseeAchievementFilter{StudentIDs: studentIDs, Sort: sortSpec}
+		if statusParam := ctx.Query("status"); statusParam != "" {
+			filter.Status = &statusParam
 		}
-
-		// Ambil semua studentID bimbingan dosen wali ini
-		studentIDs, err := s.lecturerRepo.GetAdviseeStudentIDs(lecturer.ID)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError,
-				utils.BuildResponseFailed("Gagal mengambil daftar mahasiswa bimbingan", err.Error(), nil))
+		if sid := ctx.Query("studentId"); sid != "" {
+			target, err := uuid.Parse(sid)
+			if err != nil {
+				utils.RespondError(ctx, http.StatusBadRequest,
+					"studentId tidak valid", err.Error(), nil)
+				return
+			}
+			if !slices.Contains(studentIDs, target) {
+				utils.RespondError(ctx, http.StatusForbidden,
+					"Mahasiswa bukan bimbingan Anda", "forbidden", nil)
+				return
+			}
+			filter.StudentIDs = []uuid.UUID{target}
+		}
+		page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+		filter.Page, filter.Limit = repository.NormalizePagination(page, limit)
+		if filter.MongoIDs, ok = s.contentFilterMongoIDs(ctx, false); !ok {
 			return
 		}
 
-		// Ambil semua achievement_references untuk daftar studentID tersebut
-		refs, err := s.lecturerRepo.FindAchievementsByStudentIDs(ctx, studentIDs)
+		refs, total, err := s.findSorted(ctx, sortSpec, filter.Page, filter.Limit,
+			func(unpaged bool) ([]model.AchievementReference, int64, error) {
+				filter.Unpaged = unpaged
+				return s.lecturerRepo.FindAchievementsByStudentIDs(ctx.Request.Context(), filter)
+			})
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError,
-				utils.BuildResponseFailed("Gagal mengambil prestasi mahasiswa bimbingan", err.Error(), nil))
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil prestasi mahasiswa bimbingan", err.Error(), nil)
 			return
 		}
 
-		var list []map[string]any
-		for _, r := range refs {
-			list = append(list, s.buildAchievementListItem(ctx, r))
-		}
+		list := s.buildAchievementList(ctx, refs)
+		// Lama menunggu verifikasi, agar dosen wali melihat pengajuan yang hampir kedaluwarsa
+		addSubmissionAge(list, refs, time.Now())
 
-		ctx.JSON(http.StatusOK,
-			utils.BuildResponseSuccess("Berhasil mengambil daftar prestasi mahasiswa bimbingan", list))
+		utils.RespondOK(ctx,
+			"Berhasil mengambil daftar prestasi mahasiswa bimbingan", map[string]any{
+				"items": list,
+				"meta": map[string]any{
+					"page":      filter.Page,
+					"limit":     filter.Limit,
+					"totalData": total,
+					"totalPage": (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+				},
+			})
 		return
 
 	// ================= Admin (FR-010) =================
 	case "admin":
-		// Query params: ?status=submitted&page=1&limit=10
+		// Query params: ?status=submitted&type=&tags=&from=&to=&search=&page=1&limit=10 (atau &cursor=)
+		// Prestasi deleted hanya tampil lewat ?status=deleted atau ?includeDeleted=true.
+		// ?search= mencocokkan NIM / nama mahasiswa (Postgres) ATAU judul prestasi (text index Mongo).
 		statusParam := ctx.Query("status")
 		var status *string
 		if statusParam != "" {
 			status = &statusParam
 		}
+		includeDeleted := ctx.Query("includeDeleted") == "true" || statusParam == model.StatusDeleted
+
+		// Antrean review: ?status=submitted&after=<submittedAt>,<id> (after kosong = halaman pertama)
+		if after, ok := ctx.GetQuery("after"); ok && statusParam == model.StatusSubmitted {
+			s.getSubmittedQueue(ctx, after)
+			return
+		}
 
 		page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 		limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+		page, limit = repository.NormalizePagination(page, limit)
 
-		refs, total, err := s.repo.FindAll(status, page, limit)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError,
-				utils.BuildResponseFailed("Gagal mengambil daftar semua prestasi", err.Error(), nil))
+		mongoIDs, ok := s.contentFilterMongoIDs(ctx, includeDeleted)
+		if !ok {
 			return
 		}
 
-		var list []map[string]any
-		for _, r := range refs {
-			list = append(list, s.buildAchievementListItem(ctx, r))
+		filter := repository.AchievementListFilter{
+			Status: status, IncludeDeleted: includeDeleted, MongoIDs: mongoIDs, Sort: sortSpec, Page: page, Limit: limit,
 		}
+		if search := strings.TrimSpace(ctx.Query("search")); search != "" {
+			titleIDs, err := s.repo.SearchDetailIDs(ctx.Request.Context(), search, includeDeleted)
+			if err != nil {
+				utils.RespondError(ctx, http.StatusInternalServerError,
+					"Gagal mencari prestasi", err.Error(), nil)
+				return
+			}
+			filter.Search, filter.SearchMongoIDs = search, titleIDs
+		}
+
+		// Mode cursor: ?cursor=<opaque> (kosong = halaman pertama), urutan tetap createdAt desc.
+		if cursor, ok := ctx.GetQuery("cursor"); ok {
+			s.getAchievementsByCursor(ctx, filter, sortSpec, cursor)
+			return
+		}
+		refs, total, err := s.findSorted(ctx, sortSpec, page, limit,
+			func(unpaged bool) ([]model.AchievementReference, int64, error) {
+				filter.Unpaged = unpaged
+				return s.repo.FindFiltered(ctx.Request.Context(), filter)
+			})
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil daftar semua prestasi", err.Error(), nil)
+			return
+		}
+		if err := s.repo.AttachIdentities(ctx.Request.Context(), refs); err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil data mahasiswa & verifier", err.Error(), nil)
+			return
+		}
+
+		list := s.buildAchievementList(ctx, refs) // [] jika kosong
 
 		meta := map[string]any{
 			"page":      page,
@@ -425,231 +1130,787 @@ func (s *achievementService) GetAchievements(ctx *gin.Context) {
 			"totalPage": (total + int64(limit) - 1) / int64(limit),
 		}
 
-		ctx.JSON(http.StatusOK,
-			utils.BuildResponseSuccess("Berhasil mengambil semua prestasi (admin)", map[string]any{
+		utils.RespondOK(ctx,
+			"Berhasil mengambil semua prestasi (admin)", map[string]any{
 				"items": list,
 				"meta":  meta,
-			}))
+			})
 		return
 
 	default:
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Role tidak dikenali untuk akses daftar prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Role tidak dikenali untuk akses daftar prestasi", "forbidden", nil)
 		return
 	}
 }
 
-// ===============================================================
-//  FR-007: VerifyAchievement (Dosen Wali)
-//  Endpoint: POST /api/v1/achievements/:id/verify
-// ===============================================================
-func (s *achievementService) VerifyAchievement(ctx *gin.Context) {
-	role := getRoleFromContext(ctx)
-	if role != "dosen_wali" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya dosen wali yang dapat memverifikasi prestasi", "forbidden", nil))
-		return
-	}
-
+// reviewableStudentIDs mengembalikan mahasiswa yang prestasinya boleh diperiksa dosen wali
+// yang login: bimbingannya sendiri + bimbingan dosen yang sedang melimpahkan verifikasi.
+// ok=false berarti response error sudah dikirim.
+func (s *achievementService) reviewableStudentIDs(ctx *gin.Context) ([]uuid.UUID, bool) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil || userID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
-		return
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi dosen wali diperlukan", "no_user_id", nil)
+		return nil, false
 	}
 
-	lecturer, err := s.lecturerRepo.FindByUserID(userID)
+	// lecturers.id dari JWT (fallback query untuk token lama)
+	lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
-		return
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Data dosen wali tidak ditemukan", err.Error(), nil)
+		return nil, false
 	}
 
-	id := ctx.Param("id")
-	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
-		return
+	// Ambil semua studentID bimbingan dosen wali ini
+	studentIDs, err := s.lecturerRepo.GetAdviseeStudentIDs(ctx.Request.Context(), lecturerID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil daftar mahasiswa bimbingan", err.Error(), nil)
+		return nil, false
 	}
 
-	ref, err := s.repo.FindByID(id)
+	// Tambahkan mahasiswa bimbingan dosen yang sedang melimpahkan verifikasi ke dosen ini
+	delegatorIDs, err := s.delegRepo.FindActiveDelegatorIDs(lecturerID, time.Now())
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
-		return
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data delegasi verifikasi", err.Error(), nil)
+		return nil, false
 	}
+	for _, delegatorID := range delegatorIDs {
+		ids, err := s.lecturerRepo.GetAdviseeStudentIDs(ctx.Request.Context(), delegatorID)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil daftar mahasiswa bimbingan", err.Error(), nil)
+			return nil, false
+		}
+		studentIDs = append(studentIDs, ids...)
+	}
+	return studentIDs, true
+}
 
-	// Cek apakah mahasiswa ini benar advisee doswal tersebut
-	ok, err := s.lecturerRepo.IsAdvisorOf(lecturer.ID, ref.StudentID)
-	if err != nil || !ok {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil))
-		return
+// addSubmissionAge menambahkan daysPending (dan daysUntilExpiry jika pengajuan bisa kedaluwarsa)
+// pada item submitted; list harus sejajar dengan refs (hasil buildAchievementList).
+func addSubmissionAge(list []map[string]any, refs []model.AchievementReference, now time.Time) {
+	for i, ref := range refs {
+		if ref.Status != model.StatusSubmitted || ref.SubmittedAt == nil {
+			continue
+		}
+		daysPending, daysUntilExpiry, ok := submissionAge(*ref.SubmittedAt, now)
+		list[i]["daysPending"] = daysPending
+		if ok {
+			list[i]["daysUntilExpiry"] = daysUntilExpiry
+		}
 	}
+}
 
-	if ref.Status != "submitted" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Hanya prestasi berstatus 'submitted' yang dapat diverifikasi", "invalid_status", nil))
+// ===============================================================
+//  GetPendingAchievements (Dosen Wali) — antrean verifikasi
+//  Endpoint: GET /api/v1/achievements/pending?page=1&limit=10
+//  - Hanya prestasi submitted mahasiswa bimbingan (termasuk delegasi)
+//  - Terlama diajukan dulu (submitted_at ASC)
+//  - Item berisi nama & NIM mahasiswa serta daysPending
+//  - meta.totalPending: jumlah seluruh antrean (untuk badge menu)
+// ===============================================================
+func (s *achievementService) GetPendingAchievements(ctx *gin.Context) {
+	if getRoleFromContext(ctx) != "dosen_wali" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya dosen wali yang dapat melihat antrean verifikasi", "forbidden", nil)
 		return
 	}
 
-	verifierID := userID.String()
-	if err := s.repo.UpdateStatus(id, "verified", repository.UpdateStatusOptions{
-		VerifierID: &verifierID,
-	}); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal memverifikasi prestasi", err.Error(), nil))
+	studentIDs, ok := s.reviewableStudentIDs(ctx)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Prestasi berhasil diverifikasi", nil))
-}
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	status := model.StatusSubmitted
+	filter := repository.AdviseeAchievementFilter{
+		StudentIDs: studentIDs,
+		Status:     &status,
+		Sort:       repository.AchievementSort{Field: repository.SortSubmittedAt, Asc: true},
+	}
+	filter.Page, filter.Limit = repository.NormalizePagination(page, limit)
 
-// ===============================================================
-//  FR-008: RejectAchievement (Dosen Wali)
-//  Endpoint: POST /api/v1/achievements/:id/reject
-// ===============================================================
-func (s *achievementService) RejectAchievement(ctx *gin.Context) {
+	refs, total, err := s.lecturerRepo.FindAchievementsByStudentIDs(ctx.Request.Context(), filter)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil antrean verifikasi", err.Error(), nil)
+		return
+	}
+	if err := s.repo.AttachIdentities(ctx.Request.Context(), refs); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data mahasiswa", err.Error(), nil)
+		return
+	}
+
+	list := s.buildAchievementList(ctx, refs)
+	addSubmissionAge(list, refs, time.Now())
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil antrean verifikasi", map[string]any{
+			"items": list,
+			"meta": map[string]any{
+				"page":         filter.Page,
+				"limit":        filter.Limit,
+				"totalData":    total,
+				"totalPage":    (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+				"totalPending": total,
+			},
+		})
+}
+
+// parseAchievementSort membaca ?sortBy=createdAt|submittedAt|status|points&sortDir=asc|desc
+// (default createdAt desc). ok=false berarti response 400 sudah dikirim.
+func parseAchievementSort(ctx *gin.Context) (repository.AchievementSort, bool) {
+	sortSpec := repository.AchievementSort{Field: ctx.DefaultQuery("sortBy", repository.SortCreatedAt)}
+	if !slices.Contains(repository.AchievementSortKeys(), sortSpec.Field) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"sortBy tidak dikenali", "invalid_sort", map[string]any{"allowed": repository.AchievementSortKeys()})
+		return sortSpec, false
+	}
+	switch strings.ToLower(ctx.DefaultQuery("sortDir", "desc")) {
+	case "asc":
+		sortSpec.Asc = true
+	case "desc":
+	default:
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"sortDir tidak dikenali", "invalid_sort", map[string]any{"allowed": []string{"asc", "desc"}})
+		return sortSpec, false
+	}
+	return sortSpec, true
+}
+
+// findSorted menjalankan fetch sesuai sort. Sort points tidak bisa dilakukan di Postgres:
+// semua baris diambil (fetch unpaged), poin dibaca dari Mongo, lalu diurutkan & dipotong
+// ke halaman page/limit di sini. Urutan dari query (created_at DESC) menjadi tiebreaker.
+func (s *achievementService) findSorted(
+	ctx *gin.Context,
+	sortSpec repository.AchievementSort,
+	page, limit int,
+	fetch func(unpaged bool) ([]model.AchievementReference, int64, error),
+) ([]model.AchievementReference, int64, error) {
+	if sortSpec.Field != repository.SortPoints {
+		return fetch(false)
+	}
+
+	refs, total, err := fetch(true)
+	if err != nil {
+		return nil, 0, err
+	}
+	mongoIDs := make([]string, 0, len(refs))
+	for _, r := range refs {
+		mongoIDs = append(mongoIDs, r.MongoAchievementID)
+	}
+	points, err := s.repo.FindPointsByMongoIDs(ctx.Request.Context(), mongoIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		pi, pj := points[refs[i].MongoAchievementID], points[refs[j].MongoAchievementID]
+		if sortSpec.Asc {
+			return pi < pj
+		}
+		return pi > pj
+	})
+
+	start := min((page-1)*limit, len(refs))
+	end := min(start+limit, len(refs))
+	return refs[start:end], total, nil
+}
+
+// contentFilterMongoIDs membaca filter isi ?type=competition&tags=robotics,ai&from=2024-01-01&to=2024-06-30
+// (tanggal dibuat, to inklusif) dan pencarian teks ?q=robotika, lalu mencari dokumen Mongo yang cocok.
+// mongoIDs nil = tidak ada filter isi. ok=false berarti response error sudah dikirim.
+// includeDeleted: dokumen yang di-soft-delete ikut dicari (hanya list admin).
+func (s *achievementService) contentFilterMongoIDs(ctx *gin.Context, includeDeleted bool) (mongoIDs []string, ok bool) {
+	filter := repository.AchievementContentFilter{Type: strings.TrimSpace(ctx.Query("type")), IncludeDeleted: includeDeleted}
+	for _, tag := range strings.Split(ctx.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	for _, p := range []struct {
+		param string
+		dst   **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		v := ctx.Query(p.param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Tanggal "+p.param+" harus berformat YYYY-MM-DD", "invalid_date", map[string]any{"param": p.param})
+			return nil, false
+		}
+		*p.dst = &t
+	}
+	if filter.To != nil {
+		end := filter.To.AddDate(0, 0, 1) // to inklusif: sampai akhir hari tsb
+		filter.To = &end
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Tanggal from tidak boleh setelah to", "invalid_date_range", nil)
+		return nil, false
+	}
+	if !filter.IsEmpty() {
+		ids, err := s.repo.FindMongoIDsByContent(ctx.Request.Context(), filter)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal memfilter prestasi", err.Error(), nil)
+			return nil, false
+		}
+		mongoIDs = ids
+	}
+
+	// ?q=: pencarian teks judul/deskripsi, digabung (irisan) dengan filter isi lain
+	if q := strings.TrimSpace(ctx.Query("q")); q != "" {
+		ids, err := s.repo.SearchDetailIDs(ctx.Request.Context(), q, includeDeleted)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mencari prestasi", err.Error(), nil)
+			return nil, false
+		}
+		if mongoIDs != nil {
+			keep := make(map[string]bool, len(mongoIDs))
+			for _, id := range mongoIDs {
+				keep[id] = true
+			}
+			ids = slices.DeleteFunc(ids, func(id string) bool { return !keep[id] })
+		}
+		mongoIDs = ids
+	}
+	return mongoIDs, true
+}
+
+// getVerifiedAchievements melayani integrasi (API key): prestasi verified saja, tanpa data internal.
+func (s *achievementService) getVerifiedAchievements(ctx *gin.Context, sortSpec repository.AchievementSort) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = repository.NormalizePagination(page, limit)
+
+	mongoIDs, ok := s.contentFilterMongoIDs(ctx, false)
+	if !ok {
+		return
+	}
+
+	status := model.StatusVerified
+	filter := repository.AchievementListFilter{
+		Status: &status, MongoIDs: mongoIDs, Sort: sortSpec, Page: page, Limit: limit,
+	}
+	refs, total, err := s.findSorted(ctx, sortSpec, page, limit,
+		func(unpaged bool) ([]model.AchievementReference, int64, error) {
+			filter.Unpaged = unpaged
+			return s.repo.FindFiltered(ctx.Request.Context(), filter)
+		})
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil daftar prestasi", err.Error(), nil)
+		return
+	}
+
+	list := s.buildAchievementList(ctx, refs)
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil prestasi terverifikasi", map[string]any{
+			"items": list,
+			"meta": map[string]any{
+				"page":      page,
+				"limit":     limit,
+				"totalData": total,
+				"totalPage": (total + int64(limit) - 1) / int64(limit),
+			},
+		})
+}
+
+// queueCursorNull menandai posisi di bagian baris tanpa submitted_at (diurutkan paling akhir).
+const queueCursorNull = "null"
+
+// parseQueueCursor membaca cursor antrean review "<submittedAt RFC3339>,<id>".
+func parseQueueCursor(after string) (*repository.QueueCursor, error) {
+	at, id, ok := strings.Cut(after, ",")
+	if !ok {
+		return nil, utils.ErrInvalidCursor
+	}
+	cursor := &repository.QueueCursor{}
+	var err error
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	if at != queueCursorNull {
+		t, err := time.Parse(time.RFC3339Nano, at)
+		if err != nil {
+			return nil, utils.ErrInvalidCursor
+		}
+		cursor.SubmittedAt = &t
+	}
+	return cursor, nil
+}
+
+// formatQueueCursor kebalikan parseQueueCursor untuk baris terakhir di halaman.
+func formatQueueCursor(ref model.AchievementReference) string {
+	at := queueCursorNull
+	if ref.SubmittedAt != nil {
+		at = ref.SubmittedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return at + "," + ref.ID.String()
+}
+
+// getSubmittedQueue melayani antrean review admin dengan keyset pagination
+// (submitted_at ASC NULLS LAST, id ASC). Tidak ada total: item terus berpindah status selama review.
+func (s *achievementService) getSubmittedQueue(ctx *gin.Context, after string) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	_, limit = repository.NormalizePagination(1, limit)
+
+	var cursor *repository.QueueCursor
+	if after != "" {
+		var err error
+		if cursor, err = parseQueueCursor(after); err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Cursor tidak valid", "invalid_cursor", nil)
+			return
+		}
+	}
+
+	refs, err := s.repo.FindSubmittedQueue(ctx.Request.Context(), cursor, limit)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil antrean review", err.Error(), nil)
+		return
+	}
+
+	list := s.buildAchievementList(ctx, refs)
+
+	// nextCursor kosong berarti halaman terakhir
+	var nextCursor string
+	if len(refs) == limit {
+		nextCursor = formatQueueCursor(refs[len(refs)-1])
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil antrean review (admin)", map[string]any{
+			"items": list,
+			"meta": map[string]any{
+				"limit":      limit,
+				"nextCursor": nextCursor,
+			},
+		})
+}
+
+// getAchievementsByCursor: list admin dengan keyset pagination (created_at, id) DESC.
+// Berbeda dari page/limit, baris baru tidak membuat item terlewat/terduplikasi antar halaman.
+// nextCursor kosong berarti halaman terakhir.
+func (s *achievementService) getAchievementsByCursor(ctx *gin.Context, filter repository.AchievementListFilter, sortSpec repository.AchievementSort, cursor string) {
+	if sortSpec.Field != repository.SortCreatedAt || sortSpec.Asc {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Mode cursor hanya mendukung urutan createdAt desc", "invalid_sort", nil)
+		return
+	}
+
+	listCursor := repository.ListCursor{}
+	if cursor != "" {
+		at, id, err := utils.DecodeCursor(cursor)
+		if err == nil {
+			listCursor.ID, err = uuid.Parse(id)
+		}
+		if err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Cursor tidak valid", "invalid_cursor", nil)
+			return
+		}
+		listCursor.CreatedAt = &at
+	}
+	filter.Cursor = &listCursor
+
+	refs, _, err := s.repo.FindFiltered(ctx.Request.Context(), filter)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil daftar semua prestasi", err.Error(), nil)
+		return
+	}
+	if err := s.repo.AttachIdentities(ctx.Request.Context(), refs); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil data mahasiswa & verifier", err.Error(), nil)
+		return
+	}
+
+	list := s.buildAchievementList(ctx, refs)
+
+	var nextCursor string
+	if len(refs) == filter.Limit {
+		last := refs[len(refs)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID.String())
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil semua prestasi (admin)", map[string]any{
+			"items": list,
+			"meta": map[string]any{
+				"limit":      filter.Limit,
+				"nextCursor": nextCursor,
+			},
+		})
+}
+
+// ===============================================================
+//  FR-007: VerifyAchievement (Dosen Wali)
+//  Endpoint: POST /api/v1/achievements/:id/verify
+// ===============================================================
+func (s *achievementService) VerifyAchievement(ctx *gin.Context) {
 	role := getRoleFromContext(ctx)
 	if role != "dosen_wali" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya dosen wali yang dapat menolak prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya dosen wali yang dapat memverifikasi prestasi", "forbidden", nil)
 		return
 	}
 
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil || userID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi dosen wali diperlukan", "no_user_id", nil)
 		return
 	}
 
-	lecturer, err := s.lecturerRepo.FindByUserID(userID)
+	lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Data dosen wali tidak ditemukan", err.Error(), nil)
 		return
 	}
 
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	// Akun tertaut tidak boleh memverifikasi prestasinya sendiri (konflik kepentingan)
+	if isOwnLinkedRecord(ctx, ref) {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak dapat memproses prestasi milik Anda sendiri", "self_verification", nil)
 		return
 	}
 
-	ok, err := s.lecturerRepo.IsAdvisorOf(lecturer.ID, ref.StudentID)
+	// Cek apakah mahasiswa ini benar advisee doswal tersebut (atau dosen ini delegasinya)
+	ok, delegateOf, err := s.checkAdvisorAccess(lecturerID, ref.StudentID)
 	if err != nil || !ok {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil)
 		return
 	}
 
 	if ref.Status != "submitted" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Hanya prestasi berstatus 'submitted' yang dapat ditolak", "invalid_status", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Hanya prestasi berstatus 'submitted' yang dapat diverifikasi", "invalid_status", nil)
 		return
 	}
 
+	// Body opsional: { "internalNote": "..." } (catatan privat, tidak terlihat mahasiswa)
 	var input struct {
-		RejectionNote string `json:"rejectionNote" binding:"required"`
+		InternalNote string `json:"internalNote"`
 	}
-
-	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Catatan penolakan wajib diisi", err.Error(), nil))
-		return
+	if ctx.Request.ContentLength != 0 {
+		if err := utils.BindStrictJSON(ctx, &input); err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Input tidak valid", err.Error(), nil)
+			return
+		}
 	}
+	internalNote := optionalNote(input.InternalNote)
 
 	verifierID := userID.String()
-	note := input.RejectionNote
-
-	if err := s.repo.UpdateStatus(id, "rejected", repository.UpdateStatusOptions{
-		VerifierID:    &verifierID,
-		RejectionNote: &note,
-	}); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menolak prestasi", err.Error(), nil))
+	err = s.repo.UpdateStatus(ctx.Request.Context(), id, "verified", repository.UpdateStatusOptions{
+		VerifierID:     &verifierID,
+		DelegateOf:     delegateOf,
+		InternalNote:   internalNote,
+		ExpectedStatus: model.StatusSubmitted,
+	})
+	if errors.Is(err, repository.ErrStatusConflict) {
+		s.respondStatusConflict(ctx, id)
+		return
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memverifikasi prestasi", err.Error(), nil)
 		return
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Prestasi berhasil ditolak", nil))
+	if delegateOf != nil {
+		_ = s.auditRepo.Record(ctx, &userID, "achievement.verify_as_delegate", "achievement", id, map[string]any{
+			"verifierLecturerId": lecturerID,
+			"delegateOf":         delegateOf,
+		})
+	}
+
+	s.recordInternalNote(ctx, userID, id, model.StatusVerified, internalNote)
+
+	utils.RespondOK(ctx,
+		"Prestasi berhasil diverifikasi", nil)
 }
 
 // ===============================================================
-//  DETAIL — SRS 5.4
-//  Endpoint: GET /api/v1/achievements/:id
-//  - Mahasiswa: hanya boleh lihat miliknya
-//  - Dosen wali: hanya prestasi mahasiswa bimbingan
-//  - Admin: boleh semua
+//  FR-008: RejectAchievement (Dosen Wali)
+//  Endpoint: POST /api/v1/achievements/:id/reject
+//  Body: { "rejectionCategory": "insufficient_evidence", "rejectionNote": "...", "internalNote": "..." }
+//  - rejectionCategory salah satu dari model.RejectionCategories (GET /api/v1/meta/rejection-categories)
+//  - Kategori "other": rejectionNote minimal model.RejectionOtherMinNoteLength karakter
 // ===============================================================
-func (s *achievementService) DetailAchievement(ctx *gin.Context) {
+func (s *achievementService) RejectAchievement(ctx *gin.Context) {
+	role := getRoleFromContext(ctx)
+	if role != "dosen_wali" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya dosen wali yang dapat menolak prestasi", "forbidden", nil)
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil || userID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi dosen wali diperlukan", "no_user_id", nil)
+		return
+	}
+
+	lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Data dosen wali tidak ditemukan", err.Error(), nil)
+		return
+	}
+
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
-	role := getRoleFromContext(ctx)
-	ref, err := s.repo.FindByID(id)
+	delegateOf, ierr := s.checkRejectable(ctx, lecturerID, id)
+	if ierr != nil {
+		utils.RespondError(ctx, ierr.status, ierr.message, ierr.code, nil)
+		return
+	}
+
+	var input struct {
+		RejectionCategory string `json:"rejectionCategory" binding:"required"` // model.RejectionCategories
+		RejectionNote     string `json:"rejectionNote" binding:"required"`     // pesan untuk mahasiswa
+		InternalNote      string `json:"internalNote"`                         // catatan privat verifier (opsional)
+	}
+
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Kategori dan catatan penolakan wajib diisi", err.Error(), nil)
+		return
+	}
+	if !validateRejectionInput(ctx, input.RejectionCategory, input.RejectionNote) {
+		return
+	}
+
+	err = s.applyRejection(ctx, userID, lecturerID, id, delegateOf, input.RejectionCategory, input.RejectionNote, optionalNote(input.InternalNote))
+	if errors.Is(err, repository.ErrStatusConflict) {
+		s.respondStatusConflict(ctx, id)
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menolak prestasi", err.Error(), nil)
 		return
 	}
 
+	utils.RespondOK(ctx,
+		"Prestasi berhasil ditolak", nil)
+}
+
+// validateRejectionInput memeriksa kategori penolakan (model.RejectionCategories) dan panjang
+// catatan untuk kategori "other". false berarti response 400 sudah dikirim.
+func validateRejectionInput(ctx *gin.Context, category, note string) bool {
+	if !model.IsValidRejectionCategory(category) {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Kategori penolakan tidak dikenali", "invalid_rejection_category",
+			map[string]any{"allowed": model.RejectionCategories()})
+		return false
+	}
+	if category == model.RejectionOther &&
+		utf8.RuneCountInString(strings.TrimSpace(note)) < model.RejectionOtherMinNoteLength {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			fmt.Sprintf("Catatan penolakan kategori 'other' minimal %d karakter", model.RejectionOtherMinNoteLength),
+			"rejection_note_too_short", map[string]any{"minLength": model.RejectionOtherMinNoteLength})
+		return false
+	}
+	return true
+}
+
+// itemError adalah kegagalan pemeriksaan 1 prestasi (status HTTP, pesan, kode error);
+// dipakai endpoint tunggal (RespondError) maupun bulk (bulkItemResult).
+type itemError struct {
+	status  int
+	message string
+	code    string
+}
+
+// checkRejectable menjalankan pemeriksaan RejectAchievement untuk 1 prestasi: ada, bukan milik
+// sendiri (akun tertaut), mahasiswa bimbingan (atau delegasi), dan berstatus submitted.
+func (s *achievementService) checkRejectable(ctx *gin.Context, lecturerID uuid.UUID, id string) (*uuid.UUID, *itemError) {
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		return nil, &itemError{http.StatusNotFound, "Prestasi tidak ditemukan", err.Error()}
+	}
+
+	if isOwnLinkedRecord(ctx, ref) {
+		return nil, &itemError{http.StatusForbidden,
+			"Anda tidak dapat memproses prestasi milik Anda sendiri", "self_verification"}
+	}
+
+	ok, delegateOf, err := s.checkAdvisorAccess(lecturerID, ref.StudentID)
+	if err != nil || !ok {
+		return nil, &itemError{http.StatusForbidden,
+			"Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden"}
+	}
+
+	if ref.Status != "submitted" {
+		return nil, &itemError{http.StatusBadRequest,
+			"Hanya prestasi berstatus 'submitted' yang dapat ditolak", "invalid_status"}
+	}
+	return delegateOf, nil
+}
+
+// applyRejection menyimpan penolakan 1 prestasi beserta audit delegasi & catatan privat.
+func (s *achievementService) applyRejection(
+	ctx *gin.Context,
+	userID, lecturerID uuid.UUID,
+	id string,
+	delegateOf *uuid.UUID,
+	category, note string,
+	internalNote *string,
+) error {
+	verifierID := userID.String()
+	if err := s.repo.UpdateStatus(ctx.Request.Context(), id, "rejected", repository.UpdateStatusOptions{
+		VerifierID:        &verifierID,
+		RejectionNote:     &note,
+		RejectionCategory: &category,
+		DelegateOf:        delegateOf,
+		InternalNote:      internalNote,
+		ExpectedStatus:    model.StatusSubmitted,
+	}); err != nil {
+		return err
+	}
+
+	if delegateOf != nil {
+		_ = s.auditRepo.Record(ctx, &userID, "achievement.reject_as_delegate", "achievement", id, map[string]any{
+			"verifierLecturerId": lecturerID,
+			"delegateOf":         delegateOf,
+		})
+	}
+
+	s.recordInternalNote(ctx, userID, id, model.StatusRejected, internalNote)
+	return nil
+}
+
+// findViewableAchievement menerapkan aturan akses DetailAchievement (juga dipakai riwayat revisi):
+// mahasiswa pemilik, dosen wali/delegasi, admin, atau API key (hanya verified).
+// role yang dikembalikan = middleware.APIKeyRole untuk API key. ok=false berarti response error sudah dikirim.
+func (s *achievementService) findViewableAchievement(ctx *gin.Context) (*model.AchievementReference, string, bool) {
+	id := ctx.Param("id")
+	if id == "" {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
+		return nil, "", false
+	}
+
+	role := getRoleFromContext(ctx)
+	if apiKeyCan(ctx, model.PermissionAchievementRead) {
+		role = middleware.APIKeyRole
+	}
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return nil, "", false
+	}
+
 	switch role {
+	case middleware.APIKeyRole:
+		// integrasi hanya melihat prestasi verified; selain itu dianggap tidak ada
+		if ref.Status != model.StatusVerified {
+			utils.RespondError(ctx, http.StatusNotFound,
+				"Prestasi tidak ditemukan", "not_found", nil)
+			return nil, "", false
+		}
 	case "mahasiswa":
 		studentID, _ := getStudentIDFromContext(ctx)
 		if studentID == uuid.Nil || ref.StudentID != studentID {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Anda tidak berhak melihat prestasi ini", "forbidden", nil))
-			return
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Anda tidak berhak melihat prestasi ini", "forbidden", nil)
+			return nil, "", false
 		}
 	case "dosen_wali":
+		if isOwnLinkedRecord(ctx, ref) {
+			break // riwayat prestasi sendiri sebagai mahasiswa (akun tertaut)
+		}
 		userID, _ := getUserIDFromContext(ctx)
 		if userID == uuid.Nil {
-			ctx.JSON(http.StatusUnauthorized,
-				utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
-			return
+			utils.RespondError(ctx, http.StatusUnauthorized,
+				"Autentikasi dosen wali diperlukan", "no_user_id", nil)
+			return nil, "", false
 		}
-		lecturer, err := s.lecturerRepo.FindByUserID(userID)
+		lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
 		if err != nil {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
-			return
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Data dosen wali tidak ditemukan", err.Error(), nil)
+			return nil, "", false
 		}
-		ok, err := s.lecturerRepo.IsAdvisorOf(lecturer.ID, ref.StudentID)
+		ok, _, err := s.checkAdvisorAccess(lecturerID, ref.StudentID)
 		if err != nil || !ok {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil))
-			return
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil)
+			return nil, "", false
 		}
 	case "admin":
 		// admin bebas
 	default:
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Role tidak berhak mengakses detail prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Role tidak berhak mengakses detail prestasi", "forbidden", nil)
+		return nil, "", false
+	}
+	return ref, role, true
+}
+
+// ===============================================================
+//  DETAIL — SRS 5.4
+//  Endpoint: GET /api/v1/achievements/:id
+//  - Mahasiswa: hanya boleh lihat miliknya
+//  - Dosen wali: hanya prestasi mahasiswa bimbingan
+//  - Admin: boleh semua
+// ===============================================================
+func (s *achievementService) DetailAchievement(ctx *gin.Context) {
+	ref, role, ok := s.findViewableAchievement(ctx)
+	if !ok {
 		return
 	}
 
 	detail, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal mengambil detail prestasi", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil detail prestasi", err.Error(), nil)
 		return
 	}
 
+	// URL lampiran dikirim sebagai URL lengkap (APP_BASE_URL) jika dikonfigurasi.
+	detail.Attachments = absoluteAttachments(detail.Attachments)
+
 	data := map[string]any{
 		"id":            ref.ID,
 		"studentId":     ref.StudentID,
@@ -661,10 +1922,62 @@ func (s *achievementService) DetailAchievement(ctx *gin.Context) {
 		"createdAt":     ref.CreatedAt,
 		"updatedAt":     ref.UpdatedAt,
 		"detail":        detail,
+
+		"rejectionCategory": ref.RejectionCategory, // null untuk penolakan lama / belum ditolak
+
+		"pointsOverridden": detail.PointsOverride != nil,
+	}
+	if canSeeInternalNote(ctx, ref) && ref.InternalNote != nil {
+		data["internalNote"] = ref.InternalNote
+	}
+	// Verifier sudah di-preload FindByID (verifiedBy mentah tetap dikirim untuk klien lama).
+	if ref.Verifier != nil {
+		data["verifierName"] = ref.Verifier.FullName
+		data["verifier"] = map[string]any{
+			"id":       ref.Verifier.ID,
+			"fullName": ref.Verifier.FullName,
+			"username": ref.Verifier.Username,
+		}
+	}
+	identity := []model.AchievementReference{*ref}
+	if err := s.attachStudents(identity); err == nil {
+		addStudentIdentity(data, identity[0])
+		if advisor := s.advisorSummary(identity[0].Student.AdvisorID); advisor != nil {
+			data["advisor"] = advisor
+		}
+	}
+	if est, ok := s.reviewEstimateFor(ref); ok {
+		data["reviewEstimate"] = est
+	}
+	// Diskusi internal tidak ditampilkan ke integrasi (API key).
+	if role != middleware.APIKeyRole {
+		if n, err := s.commentRepo.Count(ctx.Request.Context(), ref.ID.String()); err == nil {
+			data["commentCount"] = n
+		}
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Berhasil mengambil detail prestasi", data))
+	utils.RespondOK(ctx,
+		"Berhasil mengambil detail prestasi", data)
+}
+
+// advisorSummary mengembalikan dosen wali mahasiswa saat ini (id, nama, departemen) untuk
+// ditampilkan di detail; nil jika mahasiswa belum punya dosen wali atau datanya tidak ditemukan.
+func (s *achievementService) advisorSummary(advisorID *uuid.UUID) map[string]any {
+	if advisorID == nil {
+		return nil
+	}
+	lecturer, err := s.lecturerRepo.FindByID(*advisorID)
+	if err != nil {
+		return nil
+	}
+	advisor := map[string]any{
+		"id":         lecturer.ID,
+		"department": lecturer.Department,
+	}
+	if user, err := s.userRepo.FindByID(lecturer.UserID); err == nil {
+		advisor["fullName"] = user.FullName
+	}
+	return advisor
 }
 
 // ===============================================================
@@ -675,102 +1988,275 @@ func (s *achievementService) DetailAchievement(ctx *gin.Context) {
 // ===============================================================
 func (s *achievementService) UpdateAchievement(ctx *gin.Context) {
 	role := getRoleFromContext(ctx)
-	if role != "mahasiswa" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya mahasiswa yang dapat mengubah prestasi", "forbidden", nil))
+	ref, ok := s.findEditableAchievement(ctx)
+	if !ok {
+		return
+	}
+	id := ref.ID.String()
+
+	var input struct {
+		AchievementType string                   `json:"achievementType" binding:"required"`
+		Title           string                   `json:"title" binding:"required"`
+		Description     string                   `json:"description"`
+		Details         model.AchievementDetails `json:"details"`
+		Tags            []string                 `json:"tags"`
+		// Points & Attachments diterima demi kompatibilitas client lama tetapi diabaikan: poin
+		// dihitung dari point_rules, lampiran hanya berubah lewat endpoint attachments.
+		Points      *float64           `json:"points"`
+		Attachments []model.Attachment `json:"attachments"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input tidak valid", err.Error(), nil)
+		return
+	}
+	achievementType, ok := s.resolveAchievementType(ctx, input.AchievementType)
+	if !ok || !validateAchievementDetails(ctx, achievementType.Code, input.Details) {
 		return
 	}
 
+	points, pointsWarning, ok := s.resolvePoints(ctx, role, achievementType, input.Details, nil)
+	if !ok {
+		return
+	}
+	current, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil detail prestasi", err.Error(), nil)
+		return
+	}
+	// Jika poin sudah di-override admin, hasil perhitungan rubrik tidak dipakai.
+	if current.PointsOverride != nil {
+		points, pointsWarning = current.PointsOverride.Points, ""
+	}
+
+	now := time.Now()
+	mongoUpdate := model.Achievement{
+		StudentID:       ref.StudentID,
+		AchievementType: achievementType.Code,
+		Title:           input.Title,
+		Description:     input.Description,
+		Details:         input.Details,
+		Tags:            input.Tags,
+		Points:          points,
+		UpdatedAt:       now,
+	}
+
+	if err := s.repo.UpdateContent(ctx, id, &mongoUpdate); err != nil {
+		if errors.Is(err, repository.ErrContentLocked) {
+			s.respondContentLocked(ctx, id)
+			return
+		}
+		if errors.Is(err, repository.ErrDocumentTooLarge) {
+			utils.RespondError(ctx, http.StatusRequestEntityTooLarge,
+				"Isi prestasi terlalu besar untuk disimpan", "document_too_large", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memperbarui prestasi", err.Error(), nil)
+		return
+	}
+	s.recordRevision(ctx, current)
+
+	data := map[string]any{"points": points}
+	if pointsWarning != "" {
+		data["pointsWarning"] = pointsWarning
+	}
+	utils.RespondOK(ctx,
+		"Prestasi berhasil diperbarui", data)
+}
+
+// respondStatusConflict mengirim 409 beserta status terbaru jika UpdateStatus gagal karena
+// status prestasi berubah (repository.ErrStatusConflict) setelah diperiksa handler.
+func (s *achievementService) respondStatusConflict(ctx *gin.Context, id string) {
+	current := ""
+	if latest, err := s.repo.FindByID(ctx.Request.Context(), id); err == nil {
+		current = latest.Status
+	}
+	utils.RespondError(ctx, http.StatusConflict,
+		"Status prestasi sudah diubah oleh pengguna lain, muat ulang data", "status_conflict",
+		map[string]any{"currentStatus": current})
+}
+
+// respondContentLocked mengirim 409 beserta status terbaru jika status prestasi berubah
+// (mis. disubmit/diverifikasi) di antara pemeriksaan dan penulisan isi.
+func (s *achievementService) respondContentLocked(ctx *gin.Context, id string) {
+	current := ""
+	if latest, err := s.repo.FindByID(ctx.Request.Context(), id); err == nil {
+		current = latest.Status
+	}
+	utils.RespondError(ctx, http.StatusConflict,
+		"Status prestasi sudah berubah sehingga isinya tidak dapat diubah, muat ulang data", "content_locked",
+		map[string]any{"currentStatus": current})
+}
+
+// findEditableAchievement memeriksa aturan edit yang sama untuk PUT & PATCH:
+// hanya mahasiswa pemilik, dan hanya saat status draft, rejected, atau expired.
+// ok=false berarti response error sudah dikirim.
+func (s *achievementService) findEditableAchievement(ctx *gin.Context) (*model.AchievementReference, bool) {
+	if getRoleFromContext(ctx) != "mahasiswa" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat mengubah prestasi", "forbidden", nil)
+		return nil, false
+	}
+
 	studentID, err := getStudentIDFromContext(ctx)
 	if err != nil || studentID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
-		return
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
+		return nil, false
 	}
 
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
-		return
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
+		return nil, false
 	}
 
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
-		return
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
+		return nil, false
 	}
 
 	if ref.StudentID != studentID {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Anda tidak berhak mengubah prestasi ini", "forbidden", nil))
-		return
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak mengubah prestasi ini", "forbidden", nil)
+		return nil, false
 	}
 
-	// Di sini kita batasi hanya bisa edit saat status draft (mengikuti pola delete).
-	if ref.Status != "draft" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Prestasi hanya dapat diubah saat status 'draft'", "invalid_status", nil))
+	// Edit hanya saat draft, rejected (diperbaiki sebelum diajukan ulang lewat /resubmit),
+	// atau expired (diperbaiki sebelum diajukan lagi lewat /submit).
+	switch ref.Status {
+	case model.StatusDraft, model.StatusRejected, model.StatusExpired:
+	default:
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Prestasi hanya dapat diubah saat status 'draft', 'rejected', atau 'expired'", "invalid_status", nil)
+		return nil, false
+	}
+	return ref, true
+}
+
+// ===============================================================
+//  PATCH — auto-save draft
+//  Endpoint: PATCH /api/v1/achievements/:id
+//  - Aturan sama dengan PUT (mahasiswa pemilik, status draft/rejected)
+//  - Hanya field yang dikirim yang diubah (title/description/details/tags);
+//    field wajib per tipe tidak diperiksa karena form boleh belum lengkap
+//  - points diterima tetapi diabaikan; dihitung ulang dari point_rules jika details berubah
+// ===============================================================
+func (s *achievementService) PatchAchievement(ctx *gin.Context) {
+	ref, ok := s.findEditableAchievement(ctx)
+	if !ok {
 		return
 	}
+	id := ref.ID.String()
 
 	var input struct {
-		AchievementType string                   `json:"achievementType" binding:"required"`
-		Title           string                   `json:"title" binding:"required"`
-		Description     string                   `json:"description"`
-		Details         model.AchievementDetails `json:"details"`
-		Tags            []string                 `json:"tags"`
-		Points          int                      `json:"points"`
-		Attachments     []model.Attachment       `json:"attachments"`
+		Title       *string                   `json:"title"`
+		Description *string                   `json:"description"`
+		Details     *model.AchievementDetails `json:"details"`
+		Tags        *[]string                 `json:"tags"`
+		Points      *float64                  `json:"points"`
 	}
-	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input tidak valid", err.Error(), nil)
 		return
 	}
 
-	now := time.Now()
-	mongoUpdate := model.Achievement{
-		StudentID:       ref.StudentID,
-		AchievementType: input.AchievementType,
-		Title:           input.Title,
-		Description:     input.Description,
-		Details:         input.Details,
-		Attachments:     input.Attachments,
-		Tags:            input.Tags,
-		Points:          input.Points,
-		UpdatedAt:       now,
+	patch := repository.AchievementContentPatch{
+		Title:       input.Title,
+		Description: input.Description,
+		Details:     input.Details,
+		Tags:        input.Tags,
+	}
+	if patch.IsEmpty() && input.Points == nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Tidak ada field yang diubah", "no_fields", nil)
+		return
 	}
 
-	if err := s.repo.UpdateContent(ctx, id, &mongoUpdate); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal memperbarui prestasi", err.Error(), nil))
+	current, err := s.repo.FindDetailByMongoID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil detail prestasi", err.Error(), nil)
+		return
+	}
+
+	data := map[string]any{"id": ref.ID}
+	if input.Details != nil {
+		// Draft boleh belum lengkap, tetapi tanggal yang diisi tetap harus wajar.
+		if errs := model.ValidateDetailDates(*input.Details, time.Now(), utils.Runtime().EventHorizonYears); len(errs) > 0 {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Tanggal pada detail prestasi tidak valid", "invalid_details", map[string]any{"errors": errs})
+			return
+		}
+		// Poin yang di-override admin tidak dihitung ulang
+		if current.PointsOverride == nil {
+			achievementType, err := s.typeRepo.FindByCode(current.AchievementType)
+			if err != nil {
+				achievementType = &model.AchievementType{Code: current.AchievementType}
+			}
+			points, warning, ok := s.resolvePoints(ctx, "mahasiswa", achievementType, *input.Details, nil)
+			if !ok {
+				return
+			}
+			patch.Points = &points
+			data["points"] = points
+			if warning != "" {
+				data["pointsWarning"] = warning
+			}
+		}
+	}
+
+	if patch.IsEmpty() {
+		// hanya points yang dikirim (diabaikan): tidak ada yang perlu disimpan
+		utils.RespondOK(ctx, "Tidak ada perubahan yang disimpan", data)
 		return
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Prestasi berhasil diperbarui", nil))
+	if err := s.repo.UpdateContentPartial(ctx, id, patch); err != nil {
+		if errors.Is(err, repository.ErrContentLocked) {
+			s.respondContentLocked(ctx, id)
+			return
+		}
+		if errors.Is(err, repository.ErrDocumentTooLarge) {
+			utils.RespondError(ctx, http.StatusRequestEntityTooLarge,
+				"Isi prestasi terlalu besar untuk disimpan", "document_too_large", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan perubahan prestasi", err.Error(), nil)
+		return
+	}
+	s.recordRevision(ctx, current)
+
+	utils.RespondOK(ctx,
+		"Perubahan prestasi berhasil disimpan", data)
 }
 
 // ===============================================================
 //  HISTORY — SRS 5.4
 //  Endpoint: GET /api/v1/achievements/:id/history
-//  - Mengembalikan timeline status berdasarkan kolom created/submitted/verified/dll.
+//  - Mengembalikan timeline status dari achievement_status_logs (dengan actor);
+//    prestasi lama tanpa log direkonstruksi dari kolom created/submitted/verified/dll.
 // ===============================================================
 func (s *achievementService) GetAchievementHistory(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
 	role := getRoleFromContext(ctx)
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
 		return
 	}
 
@@ -779,79 +2265,274 @@ func (s *achievementService) GetAchievementHistory(ctx *gin.Context) {
 	case "mahasiswa":
 		studentID, _ := getStudentIDFromContext(ctx)
 		if studentID == uuid.Nil || ref.StudentID != studentID {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Anda tidak berhak melihat riwayat prestasi ini", "forbidden", nil))
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Anda tidak berhak melihat riwayat prestasi ini", "forbidden", nil)
 			return
 		}
 	case "dosen_wali":
+		if isOwnLinkedRecord(ctx, ref) {
+			break // riwayat prestasi sendiri sebagai mahasiswa (akun tertaut)
+		}
 		userID, _ := getUserIDFromContext(ctx)
 		if userID == uuid.Nil {
-			ctx.JSON(http.StatusUnauthorized,
-				utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
+			utils.RespondError(ctx, http.StatusUnauthorized,
+				"Autentikasi dosen wali diperlukan", "no_user_id", nil)
 			return
 		}
-		lecturer, err := s.lecturerRepo.FindByUserID(userID)
+		lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
 		if err != nil {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Data dosen wali tidak ditemukan", err.Error(), nil)
 			return
 		}
-		ok, err := s.lecturerRepo.IsAdvisorOf(lecturer.ID, ref.StudentID)
+		ok, _, err := s.checkAdvisorAccess(lecturerID, ref.StudentID)
 		if err != nil || !ok {
-			ctx.JSON(http.StatusForbidden,
-				utils.BuildResponseFailed("Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil))
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Prestasi bukan milik mahasiswa bimbingan Anda", "forbidden", nil)
 			return
 		}
 	case "admin":
 		// no restriction
 	default:
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Role tidak berhak mengakses riwayat prestasi", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Role tidak berhak mengakses riwayat prestasi", "forbidden", nil)
 		return
 	}
 
+	logs, err := s.repo.FindStatusLogs(ctx.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil riwayat prestasi", err.Error(), nil)
+		return
+	}
+	showInternal := canSeeInternalNote(ctx, ref)
+
 	events := []map[string]any{
 		{
 			"status": "created",
 			"at":     ref.CreatedAt,
+			"actor":  nil,
 		},
 	}
 
+	// Prestasi yang dibuat sebelum achievement_status_logs ada: bagian awal timeline
+	// direkonstruksi dari timestamp reference (hanya kejadian sebelum log pertama).
+	if len(logs) == 0 || logs[0].FromStatus != model.StatusDraft {
+		var cutoff *time.Time
+		if len(logs) > 0 {
+			cutoff = &logs[0].CreatedAt
+		}
+		legacy, err := s.legacyHistoryEvents(ref, showInternal, cutoff)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mengambil riwayat prestasi", err.Error(), nil)
+			return
+		}
+		events = append(events, legacy...)
+	}
+
+	for i, l := range logs {
+		event := map[string]any{
+			"status": l.ToStatus,
+			"from":   l.FromStatus,
+			"at":     l.CreatedAt,
+			"actor":  l.ActorUserID,
+		}
+		if l.Note != nil {
+			event["note"] = l.Note
+		}
+		if l.Category != nil {
+			event["category"] = l.Category
+		}
+		// Detail keputusan (delegasi, impor, catatan privat) hanya tersimpan untuk keputusan terakhir.
+		if i == len(logs)-1 && l.ToStatus == ref.Status &&
+			(ref.Status == model.StatusVerified || ref.Status == model.StatusRejected) {
+			event = withInternalNote(withVerifier(event, ref), ref, showInternal)
+		}
+		events = append(events, event)
+	}
+
+	data := map[string]any{
+		"id":            ref.ID,
+		"studentId":     ref.StudentID,
+		"currentStatus": ref.Status,
+		"events":        events,
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil riwayat status prestasi", data)
+}
+
+// legacyHistoryEvents merekonstruksi timeline dari timestamp reference (dan audit
+// achievement.resubmit) untuk prestasi tanpa achievement_status_logs. Jika cutoff diisi,
+// hanya kejadian sebelum cutoff yang dikembalikan.
+func (s *achievementService) legacyHistoryEvents(
+	ref *model.AchievementReference,
+	showInternal bool,
+	cutoff *time.Time,
+) ([]map[string]any, error) {
+	events := []map[string]any{}
+	add := func(at time.Time, event map[string]any) {
+		if cutoff == nil || at.Before(*cutoff) {
+			events = append(events, event)
+		}
+	}
+
+	// Putaran sebelumnya (submitted → rejected) yang sudah diajukan ulang, terlama dulu.
+	rounds, err := s.previousDecisions(ref.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	for _, prev := range rounds {
+		if prev.SubmittedAt != nil {
+			add(*prev.SubmittedAt, map[string]any{
+				"status": "submitted",
+				"at":     prev.SubmittedAt,
+				"actor":  nil,
+			})
+		}
+		if prev.VerifiedAt != nil {
+			add(*prev.VerifiedAt, withVerifier(map[string]any{
+				"status":   "rejected",
+				"at":       prev.VerifiedAt,
+				"note":     prev.RejectionNote,
+				"category": prev.RejectionCategory,
+				"actor":    prev.VerifiedBy,
+			}, &model.AchievementReference{
+				VerifiedBy:           prev.VerifiedBy,
+				VerifiedAsDelegateOf: prev.AsDelegateOf,
+				DecisionImported:     prev.Imported,
+			}))
+		}
+	}
+
 	if ref.SubmittedAt != nil {
-		events = append(events, map[string]any{
+		event := map[string]any{
 			"status": "submitted",
 			"at":     ref.SubmittedAt,
-		})
+			"actor":  nil,
+		}
+		if len(rounds) > 0 {
+			event["resubmission"] = true
+		}
+		add(*ref.SubmittedAt, event)
 	}
 	if ref.VerifiedAt != nil && ref.Status == "verified" {
-		events = append(events, map[string]any{
+		add(*ref.VerifiedAt, withInternalNote(withVerifier(map[string]any{
 			"status": "verified",
 			"at":     ref.VerifiedAt,
-		})
+			"actor":  ref.VerifiedBy,
+		}, ref), ref, showInternal))
 	}
 	if ref.VerifiedAt != nil && ref.Status == "rejected" {
-		events = append(events, map[string]any{
-			"status": "rejected",
-			"at":     ref.VerifiedAt,
-			"note":   ref.RejectionNote,
-		})
+		add(*ref.VerifiedAt, withInternalNote(withVerifier(map[string]any{
+			"status":   "rejected",
+			"at":       ref.VerifiedAt,
+			"note":     ref.RejectionNote,
+			"category": ref.RejectionCategory,
+			"actor":    ref.VerifiedBy,
+		}, ref), ref, showInternal))
 	}
 	if ref.Status == "deleted" {
-		events = append(events, map[string]any{
+		add(ref.UpdatedAt, map[string]any{
 			"status": "deleted",
 			"at":     ref.UpdatedAt, // kita pakai updatedAt sebagai indikasi delete
+			"actor":  nil,
 		})
 	}
+	return events, nil
+}
 
-	data := map[string]any{
-		"id":            ref.ID,
-		"studentId":     ref.StudentID,
-		"currentStatus": ref.Status,
-		"events":        events,
+// previousDecision adalah payload audit achievement.resubmit: keputusan penolakan
+// yang dikosongkan dari reference saat prestasi diajukan ulang.
+type previousDecision struct {
+	SubmittedAt       *time.Time `json:"submittedAt"`
+	VerifiedAt        *time.Time `json:"verifiedAt"`
+	VerifiedBy        *uuid.UUID `json:"verifiedBy"`
+	AsDelegateOf      *uuid.UUID `json:"asDelegateOf,omitempty"`
+	RejectionNote     *string    `json:"rejectionNote"`
+	RejectionCategory *string    `json:"rejectionCategory,omitempty"`
+	Imported          bool       `json:"imported,omitempty"`
+	HadInternalNote   bool       `json:"hadInternalNote,omitempty"`
+}
+
+// maxResubmitRounds membatasi putaran pengajuan ulang yang ditampilkan di riwayat.
+const maxResubmitRounds = 50
+
+// previousDecisions membaca keputusan putaran sebelumnya dari audit achievement.resubmit
+// (terlama dulu). Payload yang rusak dilewati.
+func (s *achievementService) previousDecisions(achievementID string) ([]previousDecision, error) {
+	logs, err := s.auditRepo.FindByTargets("achievement", []string{achievementID},
+		[]string{"achievement.resubmit"}, maxResubmitRounds)
+	if err != nil {
+		return nil, err
+	}
+	rounds := make([]previousDecision, 0, len(logs))
+	for i := len(logs) - 1; i >= 0; i-- {
+		var prev previousDecision
+		if json.Unmarshal([]byte(logs[i].Payload), &prev) != nil {
+			continue
+		}
+		rounds = append(rounds, prev)
 	}
+	return rounds, nil
+}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Berhasil mengambil riwayat status prestasi", data))
+// withInternalNote menambahkan catatan privat verifier ke event jika pemanggil berhak melihatnya.
+func withInternalNote(event map[string]any, ref *model.AchievementReference, show bool) map[string]any {
+	if show && ref.InternalNote != nil {
+		event["internalNote"] = ref.InternalNote
+	}
+	return event
+}
+
+// canSeeInternalNote: catatan privat hanya untuk admin dan dosen yang membuat keputusan tsb.
+// Mahasiswa (termasuk akun tertaut yang melihat prestasinya sendiri) tidak pernah melihatnya.
+func canSeeInternalNote(ctx *gin.Context, ref *model.AchievementReference) bool {
+	switch getRoleFromContext(ctx) {
+	case "admin":
+		return true
+	case "dosen_wali":
+		userID, _ := getUserIDFromContext(ctx)
+		return ref.VerifiedBy != nil && *ref.VerifiedBy == userID && !isOwnLinkedRecord(ctx, ref)
+	}
+	return false
+}
+
+// optionalNote mengubah string kosong/spasi menjadi nil.
+func optionalNote(note string) *string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil
+	}
+	return &note
+}
+
+// recordInternalNote mencatat di audit bahwa keputusan memiliki catatan privat,
+// tanpa menyalin isi catatannya.
+func (s *achievementService) recordInternalNote(ctx *gin.Context, actorID uuid.UUID, achievementID, decision string, note *string) {
+	if note == nil {
+		return
+	}
+	_ = s.auditRepo.Record(ctx, &actorID, "achievement.internal_note", "achievement", achievementID, map[string]any{
+		"decision":        decision,
+		"hasInternalNote": true,
+	})
+}
+
+// withVerifier menambahkan info verifier ke event riwayat,
+// termasuk "asDelegateOf" jika diverifikasi oleh delegasi dosen wali.
+func withVerifier(event map[string]any, ref *model.AchievementReference) map[string]any {
+	if ref.VerifiedBy != nil {
+		event["verifiedBy"] = ref.VerifiedBy
+	}
+	if ref.VerifiedAsDelegateOf != nil {
+		event["asDelegateOf"] = ref.VerifiedAsDelegateOf
+	}
+	if ref.DecisionImported {
+		event["imported"] = true // keputusan historis hasil impor sistem lama
+	}
+	return event
 }
 
 // UploadAttachment menangani upload bukti prestasi (file) oleh mahasiswa.
@@ -863,55 +2544,78 @@ func (s *achievementService) UploadAttachment(ctx *gin.Context) {
 	// Pastikan role adalah mahasiswa.
 	role := getRoleFromContext(ctx)
 	if role != "mahasiswa" {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Hanya mahasiswa yang dapat mengunggah lampiran", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Hanya mahasiswa yang dapat mengunggah lampiran", "forbidden", nil)
 		return
 	}
 
 	// Ambil studentID dari token.
 	studentID, err := getStudentIDFromContext(ctx)
 	if err != nil || studentID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Autentikasi mahasiswa diperlukan", "no_student_id", nil)
 		return
 	}
 
 	// Ambil ID achievement dari path param.
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("ID prestasi diperlukan", "missing_id", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID prestasi diperlukan", "missing_id", nil)
 		return
 	}
 
 	// Pastikan achievement ada dan memang milik mahasiswa ini.
-	ref, err := s.repo.FindByID(id)
+	ref, err := s.repo.FindByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("Prestasi tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Prestasi tidak ditemukan", err.Error(), nil)
 		return
 	}
 	if ref.StudentID != studentID {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Anda tidak berhak menambahkan lampiran ke prestasi ini", "forbidden", nil))
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Anda tidak berhak menambahkan lampiran ke prestasi ini", "forbidden", nil)
 		return
 	}
 	if ref.Status == "deleted" {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Prestasi yang sudah dihapus tidak dapat diberi lampiran", "invalid_status", nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Prestasi yang sudah dihapus tidak dapat diberi lampiran", "invalid_status", nil)
 		return
 	}
 
 	// Ambil file dari form-data (key: "file").
 	fileHeader, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("File lampiran wajib diunggah (field 'file')", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"File lampiran wajib diunggah (field 'file')", err.Error(), nil)
+		return
+	}
+
+	attachment, aerr := s.storeAttachment(ctx, id, fileHeader, ctx.PostForm("fileType"))
+	if aerr != nil {
+		utils.RespondError(ctx, aerr.status, aerr.message, aerr.code, nil)
 		return
 	}
 
+	// Response sukses berisi data attachment yang baru dibuat.
+	// Di Mongo tetap disimpan path relatif, URL lengkap hanya untuk response.
+	attachment.FileURL = utils.AbsoluteURL(attachment.FileURL)
+	utils.RespondCreated(ctx,
+		"Lampiran berhasil diunggah", attachment)
+}
+
+// attachmentError adalah kegagalan menyimpan 1 lampiran beserta response yang sesuai.
+type attachmentError struct {
+	status  int
+	message string
+	code    string
+}
+
+// storeAttachment memindai file upload, menyimpannya ke storage, lalu menambahkan metadatanya
+// ke dokumen prestasi id (dipakai UploadAttachment & CreateAchievement multipart).
+// fileType kosong = ekstensi file.
+func (s *achievementService) storeAttachment(ctx *gin.Context, id string, fileHeader *multipart.FileHeader, fileType string) (*model.Attachment, *attachmentError) {
 	// Optional: tipe file (misalnya "certificate", "photo", dll).
-	fileType := ctx.PostForm("fileType")
 	if fileType == "" {
 		// default: pakai ekstensi sebagai tipe kasar.
 		fileType = filepath.Ext(fileHeader.Filename)
@@ -920,48 +2624,63 @@ func (s *achievementService) UploadAttachment(ctx *gin.Context) {
 		}
 	}
 
-	// Tentukan direktori penyimpanan lokal.
-	// Contoh: ./uploads/achievements/<achievementID>/
-	baseDir := "uploads"
-	destDir := filepath.Join(baseDir, "achievements", id)
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, &attachmentError{http.StatusBadRequest, "Gagal membaca file upload", err.Error()}
+	}
+	defer src.Close()
 
-	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menyiapkan direktori upload", err.Error(), nil))
-		return
+	// Pindai malware sebelum file disimpan (scanner & batas waktunya diatur per deployment).
+	clean, signature, err := s.scanner.Scan(ctx.Request.Context(), src)
+	if err != nil {
+		return nil, &attachmentError{http.StatusServiceUnavailable, "Pemindaian file tidak tersedia, coba lagi nanti", "scan_unavailable"}
+	}
+	if !clean {
+		userID, _ := getUserIDFromContext(ctx)
+		_ = s.auditRepo.Record(ctx, &userID, "achievement.attachment_malware", "achievement", id, map[string]any{
+			"fileName":  fileHeader.Filename,
+			"signature": signature,
+		})
+		return nil, &attachmentError{http.StatusUnprocessableEntity, "File terdeteksi mengandung malware", "malware_detected"}
+	}
+	// Kembalikan posisi baca ke awal untuk disimpan.
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, &attachmentError{http.StatusInternalServerError, "Gagal membaca file upload", err.Error()}
 	}
 
 	// Buat nama file unik agar tidak bentrok.
+	// Lokasi final: <UPLOAD_DIR>/achievements/<achievementID>/<filename>
 	now := time.Now()
-	filename := strconv.FormatInt(now.UnixNano(), 10) + "_" + fileHeader.Filename
-	fullPath := filepath.Join(destDir, filename)
-
-	// Simpan file ke disk.
-	if err := ctx.SaveUploadedFile(fileHeader, fullPath); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menyimpan file upload", err.Error(), nil))
-		return
-	}
+	filename := strconv.FormatInt(now.UnixNano(), 10) + "_" + filepath.Base(fileHeader.Filename)
+	relPath := filepath.Join("achievements", id, filename)
 
-	// URL/relative path yang disimpan di Mongo (nanti bisa diserve via static files kalau mau).
-	fileURL := "/" + filepath.ToSlash(filepath.Join(baseDir, "achievements", id, filename))
+	// URL/relative path yang disimpan di Mongo (mengikuti direktori upload yang dikonfigurasi).
+	fileURL := s.storage.URL(relPath)
 
 	// Bentuk objek attachment sesuai SRS.
 	attachment := model.Attachment{
+		ID:         uuid.NewString(),
 		FileName:   fileHeader.Filename,
 		FileURL:    fileURL,
 		FileType:   fileType,
 		UploadedAt: now,
 	}
 
-	// Simpan ke MongoDB (append ke array attachments).
-	if err := s.repo.AddAttachment(context.Background(), id, attachment); err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menyimpan lampiran ke database", err.Error(), nil))
-		return
+	// File ditulis ke direktori sementara dulu dan dipindah ke lokasi final sebelum metadata
+	// disimpan ke MongoDB (append ke array attachments); jika penyimpanan gagal, file dihapus lagi.
+	var commitErr error
+	err = s.storage.SaveAtomic(src, relPath, func() error {
+		commitErr = s.repo.AddAttachment(ctx.Request.Context(), id, attachment)
+		return commitErr
+	})
+	if errors.Is(commitErr, repository.ErrDocumentTooLarge) {
+		return nil, &attachmentError{http.StatusRequestEntityTooLarge, "Prestasi sudah mencapai batas jumlah/ukuran lampiran", "document_too_large"}
 	}
-
-	// Response sukses berisi data attachment yang baru dibuat.
-	ctx.JSON(http.StatusCreated,
-		utils.BuildResponseSuccess("Lampiran berhasil diunggah", attachment))
+	if commitErr != nil {
+		return nil, &attachmentError{http.StatusInternalServerError, "Gagal menyimpan lampiran ke database", commitErr.Error()}
+	}
+	if err != nil {
+		return nil, &attachmentError{http.StatusInternalServerError, "Gagal menyimpan file upload", err.Error()}
+	}
+	return &attachment, nil
 }
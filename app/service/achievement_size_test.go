@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeNearLimitRepo menyimpan 1 dokumen prestasi di memori dan menolak lampiran/isi baru
+// dengan ErrDocumentTooLarge jika ukuran BSON-nya akan melewati MaxDocumentBytes,
+// sama seperti perkiraan repository asli.
+type fakeNearLimitRepo struct {
+	*fakeEditRepo
+}
+
+func (r *fakeNearLimitRepo) AddAttachment(_ context.Context, id string, attachment model.Attachment) error {
+	doc := r.doc(id)
+	current, _ := bson.Marshal(doc)
+	extra, _ := bson.Marshal(bson.M{"v": attachment})
+	if len(current)+len(extra) > utils.Runtime().MaxDocumentBytes {
+		return repository.ErrDocumentTooLarge
+	}
+	doc.Attachments = append(doc.Attachments, attachment)
+	return nil
+}
+
+func (r *fakeNearLimitRepo) UpdateContent(ctx context.Context, id string, doc *model.Achievement) error {
+	if raw, _ := bson.Marshal(doc); len(raw) > utils.Runtime().MaxDocumentBytes {
+		return repository.ErrDocumentTooLarge
+	}
+	return r.fakeEditRepo.UpdateContent(ctx, id, doc)
+}
+
+// setRuntimeEnv mengganti setting runtime selama 1 test lalu memuat ulang nilai semula.
+func setRuntimeEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	t.Cleanup(func() { _ = utils.InitRuntimeConfig() }) // jalan setelah t.Setenv mengembalikan env
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	if err := utils.InitRuntimeConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNearLimitDocumentRejectedEarly: pada dokumen yang hampir mencapai batas ukuran, upload
+// lampiran dan edit isi ditolak 413 document_too_large tanpa meninggalkan file maupun revisi.
+func TestNearLimitDocumentRejectedEarly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 8192
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_MAX_DOCUMENT_BYTES": "8192"})
+
+	studentID, mongoID := uuid.New(), primitive.NewObjectID()
+	ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusDraft, MongoAchievementID: mongoID.Hex()}
+	doc := &model.Achievement{ID: mongoID, StudentID: studentID, AchievementType: "seminar", Title: "Seminar"}
+	// Isi description sampai dokumen tersisa ±100 byte dari batas.
+	raw, _ := bson.Marshal(doc)
+	doc.Description = strings.Repeat("x", limit-len(raw)-100)
+
+	repo := &fakeNearLimitRepo{&fakeEditRepo{fakePointsRepo: &fakePointsRepo{
+		fakeAchievementRepo: newFakeAchievementRepo(ref),
+		docs:                map[string]*model.Achievement{mongoID.Hex(): doc},
+	}}}
+	revisions := &fakeRevisionRepo{revs: map[primitive.ObjectID]model.AchievementRevision{}}
+	base := t.TempDir()
+	storage := &utils.LocalStorage{BaseDir: base, TempDir: filepath.Join(base, ".tmp")}
+	types := &fakeTypeRepo{types: []model.AchievementType{{Code: "seminar", Active: true}}}
+	svc := NewAchievementService(repo, nil, nil, nil, &fakeAuditRepo{}, nil, nil, fakeNoRuleRepo{}, types, revisions, storage, utils.NoopScanner{})
+
+	r := gin.New()
+	asStudent := func(c *gin.Context) {
+		c.Set("role", "mahasiswa")
+		c.Set("userID", uuid.New())
+		c.Set("studentID", studentID)
+	}
+	r.POST("/achievements/:id/attachments", asStudent, svc.UploadAttachment)
+	r.PUT("/achievements/:id", asStudent, svc.UpdateAchievement)
+	id := ref.ID.String()
+
+	t.Run("upload lampiran", func(t *testing.T) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("file", "sertifikat.pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte("%PDF-1.4"))
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/achievements/"+id+"/attachments", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"errors":"document_too_large"`) {
+			t.Fatalf("status %d, body %s, want 413 document_too_large", w.Code, w.Body)
+		}
+		if len(doc.Attachments) != 0 {
+			t.Fatalf("lampiran tetap tersimpan: %+v", doc.Attachments)
+		}
+		files, _ := os.ReadDir(filepath.Join(base, "achievements", id))
+		if len(files) != 0 {
+			t.Fatalf("file upload tertinggal di storage: %v", files)
+		}
+	})
+
+	t.Run("edit isi melewati batas", func(t *testing.T) {
+		w, _ := doJSON(t, r, http.MethodPut, "/achievements/"+id, "", map[string]any{
+			"achievementType": "seminar", "title": "Seminar", "description": strings.Repeat("y", limit),
+		})
+		if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"errors":"document_too_large"`) {
+			t.Fatalf("status %d, body %s, want 413 document_too_large", w.Code, w.Body)
+		}
+		if repo.updated != nil || len(revisions.revs) != 0 {
+			t.Fatalf("isi/revisi tetap ditulis: updated=%v, %d revisi", repo.updated != nil, len(revisions.revs))
+		}
+	})
+
+	t.Run("edit kecil tetap diterima", func(t *testing.T) {
+		w, _ := doJSON(t, r, http.MethodPut, "/achievements/"+id, "", map[string]any{
+			"achievementType": "seminar", "title": "Seminar nasional", "description": "ringkas",
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d, body %s", w.Code, w.Body)
+		}
+		if len(revisions.revs) != 1 {
+			t.Fatalf("%d revisi, want 1 (versi sebelum edit)", len(revisions.revs))
+		}
+	})
+}
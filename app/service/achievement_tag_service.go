@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batas jumlah saran tag per request autocomplete.
+const (
+	defaultTagSuggestionLimit = 10
+	maxTagSuggestionLimit     = 50
+)
+
+// ===============================================================
+//  TAGS — saran tag untuk autocomplete form prestasi
+//  Endpoint: GET /api/v1/achievements/tags?q=rob&limit=10
+//  - Scope sama dengan statistik: admin semua, dosen wali bimbingan, mahasiswa miliknya
+//  - q dicocokkan sebagai awalan tag (tanpa membedakan huruf besar/kecil)
+//  - Hasil: [{tag, count}] terbanyak dulu
+// ===============================================================
+func (s *achievementService) GetTagSuggestions(ctx *gin.Context) {
+	scope, ok := reportScope(ctx, s.lecturerRepo, ctx.GetString("role"), "daftar tag")
+	if !ok {
+		return
+	}
+
+	tags := []repository.TagCount{}
+	// dosen wali tanpa bimbingan: scope kosong bukan berarti semua mahasiswa
+	if ctx.GetString("role") != "admin" && len(scope.StudentIDs) == 0 {
+		utils.RespondOK(ctx, "Berhasil mengambil daftar tag", tags)
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultTagSuggestionLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultTagSuggestionLimit
+	}
+	limit = min(limit, maxTagSuggestionLimit)
+
+	tags, err = s.repo.FindTagCounts(ctx.Request.Context(), scope, strings.TrimSpace(ctx.Query("q")), limit)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil daftar tag", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx, "Berhasil mengambil daftar tag", tags)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *fakeRevisionRepo) FindByAchievement(_ context.Context, achievementID primitive.ObjectID) ([]model.AchievementRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.AchievementRevision
+	for _, rev := range r.revs {
+		if rev.AchievementID == achievementID {
+			out = append(out, rev)
+		}
+	}
+	return out, nil
+}
+
+// fakeReportRepo mengembalikan portofolio tetap.
+type fakeReportRepo struct {
+	repository.ReportRepository
+	portfolio repository.PortfolioResult
+}
+
+func (r *fakeReportRepo) GetPortfolio(context.Context, uuid.UUID, []string) (*repository.PortfolioResult, error) {
+	// Salin sampai ke lampiran: hasil agregasi asli selalu baru per pemanggilan.
+	cp := r.portfolio
+	cp.Groups = make([]repository.PortfolioGroup, len(r.portfolio.Groups))
+	for gi, g := range r.portfolio.Groups {
+		g.Items = make([]repository.PortfolioItem, len(g.Items))
+		for ii, it := range r.portfolio.Groups[gi].Items {
+			it.Attachments = append([]model.Attachment(nil), it.Attachments...)
+			g.Items[ii] = it
+		}
+		cp.Groups[gi] = g
+	}
+	return &cp, nil
+}
+
+// TestAttachmentURLsAcrossSurfaces: URL lampiran di list, riwayat revisi, dan ekspor portofolio
+// mengikuti APP_BASE_URL (relatif jika tidak di-set); dokumen sumbernya tetap relatif.
+func TestAttachmentURLsAcrossSurfaces(t *testing.T) {
+	const path = "/uploads/achievements/sertifikat.pdf"
+	attachments := func() []model.Attachment {
+		return []model.Attachment{{FileName: "sertifikat.pdf", FileURL: path, FileType: "application/pdf"}}
+	}
+
+	studentID := uuid.New()
+	mongoID := primitive.NewObjectID()
+	ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: model.StatusVerified}
+	doc := &model.Achievement{ID: mongoID, StudentID: studentID, Title: "Juara 1", Attachments: attachments()}
+
+	achievements := &fakePointsRepo{
+		fakeAchievementRepo: newFakeAchievementRepo(ref),
+		docs:                map[string]*model.Achievement{mongoID.Hex(): doc},
+	}
+	revisions := &fakeRevisionRepo{revs: map[primitive.ObjectID]model.AchievementRevision{}}
+	revID := primitive.NewObjectID()
+	revisions.revs[revID] = model.AchievementRevision{ID: revID, AchievementID: mongoID, Title: "Juara 2", Attachments: attachments()}
+	reports := &fakeReportRepo{portfolio: repository.PortfolioResult{Groups: []repository.PortfolioGroup{{
+		AchievementType: "competition", Count: 1,
+		Items: []repository.PortfolioItem{{MongoID: mongoID, Title: "Juara 1", Attachments: attachments()}},
+	}}}}
+	feedRefs := &fakeFeedAchievementRepo{refs: []model.AchievementReference{*ref}}
+
+	gin.SetMode(gin.TestMode)
+	achievementSvc := NewAchievementService(achievements, nil, nil, nil, nil, nil, nil, nil, nil, revisions, nil, nil)
+	studentSvc := NewStudentService(nil, feedRefs, reports, nil, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("role", c.GetHeader("X-Test-Role"))
+		c.Set("userID", uuid.New())
+		c.Set("studentID", studentID)
+	})
+	r.GET("/achievements/:id/revisions", achievementSvc.GetAchievementRevisions)
+	r.GET("/students/me/portfolio", studentSvc.GetMyPortfolio)
+
+	get := func(t *testing.T, role, target string, out any) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-Test-Role", role)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		checkEnvelope(t, w)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d, body %s", target, w.Code, w.Body)
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	surfaces := []struct {
+		name    string
+		fileURL func(t *testing.T) string
+	}{
+		{name: "list", fileURL: func(t *testing.T) string {
+			item := buildAchievementListItem(*ref, doc)
+			return item["attachments"].([]model.Attachment)[0].FileURL
+		}},
+		{name: "riwayat revisi", fileURL: func(t *testing.T) string {
+			var resp struct {
+				Data struct {
+					Revisions []struct {
+						Attachments []model.Attachment `json:"attachments"`
+					} `json:"revisions"`
+				} `json:"data"`
+			}
+			get(t, "admin", "/achievements/"+ref.ID.String()+"/revisions", &resp)
+			return resp.Data.Revisions[0].Attachments[0].FileURL
+		}},
+		{name: "ekspor portofolio", fileURL: func(t *testing.T) string {
+			var resp struct {
+				Data repository.PortfolioResult `json:"data"`
+			}
+			get(t, "mahasiswa", "/students/me/portfolio", &resp)
+			return resp.Data.Groups[0].Items[0].Attachments[0].FileURL
+		}},
+	}
+	modes := []struct {
+		name string
+		base string
+		want string
+	}{
+		{name: "APP_BASE_URL kosong", want: path},
+		{name: "APP_BASE_URL di-set", base: "https://api.kampus.ac.id/api/", want: "https://api.kampus.ac.id/api" + path},
+	}
+	for _, mode := range modes {
+		for _, surface := range surfaces {
+			t.Run(mode.name+"/"+surface.name, func(t *testing.T) {
+				t.Setenv("APP_BASE_URL", mode.base)
+				if got := surface.fileURL(t); got != mode.want {
+					t.Fatalf("fileUrl = %q, want %q", got, mode.want)
+				}
+				if doc.Attachments[0].FileURL != path || reports.portfolio.Groups[0].Items[0].Attachments[0].FileURL != path {
+					t.Fatal("URL lengkap ikut tersimpan ke data sumber")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===============================================================
+//  POST /api/v1/admin/users/:id/link-lecturer
+//  Admin: menautkan profil dosen ke akun mahasiswa (mahasiswa yang kini menjadi dosen).
+//  Body: { "lecturerId": "NIP", "department": "..." }
+//
+//  Akun tetap 1 user: profil mahasiswa (dan seluruh prestasinya) tidak dipindah, role
+//  diganti menjadi dosen_wali. Setelah login ulang, JWT membawa studentId + lecturerId:
+//    - endpoint prestasi tetap mengenali user sebagai pemilik riwayat lamanya (read-only),
+//    - endpoint verifikasi mengenali user sebagai dosen wali (tidak bisa memverifikasi prestasi sendiri).
+// ===============================================================
+func (s *adminService) LinkLecturerProfile(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+
+	var input struct {
+		LecturerID string `json:"lecturerId" binding:"required"`
+		Department string `json:"department"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	lecturer := model.Lecturer{
+		LecturerID: strings.TrimSpace(input.LecturerID),
+		Department: strings.TrimSpace(input.Department),
+	}
+	err = s.repo.LinkLecturerProfile(userID, &lecturer)
+	switch {
+	case errors.Is(err, repository.ErrNoStudentProfile):
+		ctx.JSON(http.StatusUnprocessableEntity,
+			utils.BuildResponseFailed("User tidak memiliki profil mahasiswa untuk ditautkan", "no_student_profile", nil))
+		return
+	case errors.Is(err, repository.ErrLecturerProfileExist):
+		ctx.JSON(http.StatusConflict,
+			utils.BuildResponseFailed("User sudah memiliki profil dosen", "lecturer_profile_exists", nil))
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menautkan profil dosen", err.Error(), nil))
+		return
+	}
+
+	adminID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &adminID, "user.link_lecturer", "user", userID.String(), map[string]any{
+		"lecturerProfileId": lecturer.ID,
+		"lecturerId":        lecturer.LecturerID,
+	})
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("Profil dosen berhasil ditautkan, user perlu login ulang", map[string]any{
+			"userId":            userID,
+			"lecturerProfileId": lecturer.ID,
+			"role":              "dosen_wali",
+		}))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
+)
+
+// fakeLinkUserRepo menambah profil mahasiswa/dosen per user ke fakeUserRepo,
+// sehingga login mengisi studentId & lecturerId di JWT seperti repository asli.
+type fakeLinkUserRepo struct {
+	*fakeUserRepo
+	students  map[uuid.UUID]*model.Student
+	lecturers map[uuid.UUID]*model.Lecturer
+}
+
+func (r *fakeLinkUserRepo) FindStudentByUserID(userID uuid.UUID) (*model.Student, error) {
+	if s, ok := r.students[userID]; ok {
+		return s, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLinkUserRepo) FindLecturerByUserID(userID uuid.UUID) (*model.Lecturer, error) {
+	if l, ok := r.lecturers[userID]; ok {
+		return l, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeLinkAdminRepo: LinkLecturerProfile dengan aturan yang sama seperti repository asli
+// (wajib punya profil mahasiswa, belum punya profil dosen; role diganti dosen_wali).
+type fakeLinkAdminRepo struct {
+	repository.UserAdminRepository
+	users *fakeLinkUserRepo
+}
+
+func (r *fakeLinkAdminRepo) LinkLecturerProfile(userID uuid.UUID, lecturer *model.Lecturer) error {
+	if r.users.students[userID] == nil {
+		return repository.ErrNoStudentProfile
+	}
+	if r.users.lecturers[userID] != nil {
+		return repository.ErrLecturerProfileExist
+	}
+	lecturer.ID, lecturer.UserID = uuid.New(), userID
+	r.users.lecturers[userID] = lecturer
+	r.users.update(userID, func(u *model.User) { u.Role = model.Role{Name: "dosen_wali"} })
+	return nil
+}
+
+// TestLinkedAccountStudentAndAdvisorFlows: setelah profil dosen ditautkan, token yang sama
+// dipakai untuk membaca riwayat prestasi sendiri (alur mahasiswa) dan memverifikasi prestasi
+// bimbingan (alur dosen wali); prestasi lama tetap milik profil mahasiswa yang sama.
+func TestLinkedAccountStudentAndAdvisorFlows(t *testing.T) {
+	user := newTestUser(t, "budi", "rahasia123", "mahasiswa")
+	ownStudent := &model.Student{ID: uuid.New(), UserID: user.ID, StudentID: "2019001"}
+	users := &fakeLinkUserRepo{
+		students:  map[uuid.UUID]*model.Student{user.ID: ownStudent},
+		lecturers: map[uuid.UUID]*model.Lecturer{},
+	}
+	f := newAuthFixture(t, user)
+	users.fakeUserRepo = f.users
+	f.svc = NewAuthService(users, f.refresh, f.revoked, fakeLoginEvents{}, nil)
+
+	adviseeID := uuid.New()
+	ownMongo := primitive.NewObjectID()
+	own := &model.AchievementReference{ID: uuid.New(), StudentID: ownStudent.ID, MongoAchievementID: ownMongo.Hex(),
+		Status: model.StatusVerified, CreatedAt: time.Now().Add(-365 * 24 * time.Hour)}
+	ownPending := &model.AchievementReference{ID: uuid.New(), StudentID: ownStudent.ID, Status: model.StatusSubmitted}
+	advisee := &model.AchievementReference{ID: uuid.New(), StudentID: adviseeID, Status: model.StatusSubmitted}
+	repo := &fakeNoteRepo{&fakePointsRepo{fakeAchievementRepo: newFakeAchievementRepo(own, ownPending, advisee),
+		docs: map[string]*model.Achievement{ownMongo.Hex(): {ID: ownMongo, StudentID: ownStudent.ID, Title: "Juara 1 Hackathon"}}}}
+	delegations := &fakeDelegationRepo{advisors: map[uuid.UUID]uuid.UUID{}}
+	achievements := NewAchievementService(repo, nil, &fakeListStudentRepo{}, fakeDelegationLecturerRepo{delegations: delegations},
+		&fakeAuditRepo{}, delegations, fakeNoteCommentRepo{}, nil, nil, nil, nil, nil)
+
+	r := gin.New()
+	g := r.Group("/achievements", middleware.AuthMiddleware())
+	g.GET("/:id", achievements.DetailAchievement)
+	g.GET("/:id/history", achievements.GetAchievementHistory)
+	g.POST("/:id/verify", achievements.VerifyAchievement)
+	path := func(ref *model.AchievementReference, suffix string) string {
+		return "/achievements/" + ref.ID.String() + suffix
+	}
+
+	// Sebelum ditautkan: token mahasiswa tidak bisa memverifikasi.
+	before, _ := f.login(t, "budi", "rahasia123")
+	if w, _ := doJSON(t, r, http.MethodPost, path(advisee, "/verify"), before, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("verifikasi sebelum ditautkan: status %d, want 403", w.Code)
+	}
+
+	// Admin menautkan profil dosen; tautan kedua ditolak.
+	admin := r.Group("/admin", func(c *gin.Context) { c.Set("role", "admin"); c.Set("userID", uuid.New()) })
+	audit := &fakeAuditRepo{}
+	adminSvc := NewAdminService(&fakeLinkAdminRepo{users: users}, nil, audit, nil, nil, nil, nil, nil, nil, nil)
+	admin.POST("/users/:id/link-lecturer", adminSvc.LinkLecturerProfile)
+	link := "/admin/users/" + user.ID.String() + "/link-lecturer"
+	w, data := doJSON(t, r, http.MethodPost, link, "", map[string]any{"lecturerId": "198001", "department": "Informatika"})
+	if w.Code != http.StatusCreated || data["role"] != "dosen_wali" {
+		t.Fatalf("link: status %d, body %s", w.Code, w.Body)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != "user.link_lecturer" {
+		t.Fatalf("audit %v, want [user.link_lecturer]", audit.actions)
+	}
+	if w, _ := doJSON(t, r, http.MethodPost, link, "", map[string]any{"lecturerId": "198002"}); w.Code != http.StatusConflict {
+		t.Fatalf("link kedua: status %d, want 409", w.Code)
+	}
+	lecturer := users.lecturers[user.ID]
+	delegations.advisors[adviseeID] = lecturer.ID
+
+	// Login ulang: 1 token membawa kedua profil.
+	token, _ := f.login(t, "budi", "rahasia123")
+
+	t.Run("alur mahasiswa: riwayat prestasi sendiri", func(t *testing.T) {
+		w, data := doJSON(t, r, http.MethodGet, path(own, ""), token, nil)
+		if w.Code != http.StatusOK || data["studentId"] != ownStudent.ID.String() {
+			t.Fatalf("detail: status %d, body %s", w.Code, w.Body)
+		}
+		if w, _ := doJSON(t, r, http.MethodGet, path(own, "/history"), token, nil); w.Code != http.StatusOK {
+			t.Fatalf("riwayat: status %d, body %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("alur dosen wali: verifikasi mahasiswa bimbingan", func(t *testing.T) {
+		w, _ := doJSON(t, r, http.MethodPost, path(advisee, "/verify"), token, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("verifikasi: status %d, body %s", w.Code, w.Body)
+		}
+		got, _ := repo.FindByID(context.Background(), advisee.ID.String())
+		if got.Status != model.StatusVerified || got.VerifiedBy == nil || *got.VerifiedBy != user.ID {
+			t.Fatalf("prestasi bimbingan %+v, want verified oleh %s", got, user.ID)
+		}
+	})
+
+	t.Run("prestasi sendiri tidak bisa diverifikasi", func(t *testing.T) {
+		w, _ := doJSON(t, r, http.MethodPost, path(ownPending, "/verify"), token, nil)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"errors":"self_verification"`) {
+			t.Fatalf("status %d, body %s, want 403 self_verification", w.Code, w.Body)
+		}
+	})
+
+	// Riwayat lama tidak yatim: tetap milik profil mahasiswa yang sama & statusnya tidak berubah.
+	for id, want := range map[uuid.UUID]string{own.ID: model.StatusVerified, ownPending.ID: model.StatusSubmitted} {
+		got, _ := repo.FindByID(context.Background(), id.String())
+		if got.StudentID != ownStudent.ID || got.Status != want {
+			t.Fatalf("prestasi %s: student %s status %s, want %s %s", id, got.StudentID, got.Status, ownStudent.ID, want)
+		}
+	}
+	if users.students[user.ID] != ownStudent {
+		t.Fatal("profil mahasiswa hilang setelah ditautkan")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// achievementTypeCodePattern: kode tipe huruf kecil/angka/underscore (disimpan di dokumen Mongo).
+var achievementTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,29}$`)
+
+// achievementTypeInput adalah body create/update 1 tipe prestasi (code hanya dipakai saat create).
+type achievementTypeInput struct {
+	Code          string   `json:"code"`
+	Label         string   `json:"label" binding:"required"`
+	Active        *bool    `json:"active"` // default true
+	DefaultPoints *float64 `json:"defaultPoints"`
+}
+
+// toModel memvalidasi input; pesan error kosong = valid.
+func (in achievementTypeInput) toModel(code string) (*model.AchievementType, string) {
+	t := &model.AchievementType{
+		Code:   code,
+		Label:  strings.TrimSpace(in.Label),
+		Active: in.Active == nil || *in.Active,
+	}
+	if in.DefaultPoints != nil {
+		if *in.DefaultPoints < 0 {
+			return nil, "defaultPoints tidak boleh negatif"
+		}
+		t.DefaultPoints = *in.DefaultPoints
+	}
+	if t.Label == "" {
+		return nil, "label wajib diisi"
+	}
+	return t, ""
+}
+
+// ===============================================================
+//  GET /api/v1/admin/achievement-types
+//  Termasuk tipe nonaktif (GET /api/v1/achievement-types hanya yang aktif).
+// ===============================================================
+func (s *adminService) GetAchievementTypes(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	list, err := s.typeRepo.FindAll(false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil katalog tipe prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil katalog tipe prestasi", list))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/achievement-types
+//  Body: { "code": "hki", "label": "Hak Kekayaan Intelektual", "defaultPoints": 20 }
+// ===============================================================
+func (s *adminService) CreateAchievementType(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input achievementTypeInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	code := strings.ToLower(strings.TrimSpace(input.Code))
+	if !achievementTypeCodePattern.MatchString(code) {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("code harus 2-30 karakter huruf kecil, angka, atau underscore", "invalid_code", nil))
+		return
+	}
+	t, msg := input.toModel(code)
+	if t == nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed(msg, "invalid_achievement_type", nil))
+		return
+	}
+
+	if _, err := s.typeRepo.FindByCode(code); err == nil {
+		ctx.JSON(http.StatusConflict,
+			utils.BuildResponseFailed("Kode tipe prestasi sudah ada", "duplicate_code", nil))
+		return
+	}
+
+	if err := s.typeRepo.Create(t); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyimpan tipe prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("Tipe prestasi berhasil ditambahkan", t))
+}
+
+// ===============================================================
+//  PUT /api/v1/admin/achievement-types/:code
+//  Kode tidak bisa diubah (sudah tersimpan di dokumen prestasi).
+// ===============================================================
+func (s *adminService) UpdateAchievementType(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	existing, err := s.typeRepo.FindByCode(ctx.Param("code"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Tipe prestasi tidak ditemukan", err.Error(), nil))
+		return
+	}
+
+	var input achievementTypeInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	if input.Code != "" && input.Code != existing.Code {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Kode tipe prestasi tidak bisa diubah", "code_immutable", nil))
+		return
+	}
+	t, msg := input.toModel(existing.Code)
+	if t == nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed(msg, "invalid_achievement_type", nil))
+		return
+	}
+	if input.DefaultPoints == nil {
+		t.DefaultPoints = existing.DefaultPoints
+	}
+	if input.Active == nil {
+		t.Active = existing.Active
+	}
+	t.CreatedAt = existing.CreatedAt
+
+	if err := s.typeRepo.Update(t); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memperbarui tipe prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Tipe prestasi berhasil diperbarui", t))
+}
+
+// ===============================================================
+//  DELETE /api/v1/admin/achievement-types/:code
+//  Menonaktifkan tipe: tidak bisa dipilih lagi, prestasi lama tetap memakai kode tsb.
+// ===============================================================
+func (s *adminService) DeleteAchievementType(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	if err := s.typeRepo.Deactivate(ctx.Param("code")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("Tipe prestasi tidak ditemukan", err.Error(), nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menonaktifkan tipe prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Tipe prestasi berhasil dinonaktifkan", nil))
+}
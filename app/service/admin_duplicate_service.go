@@ -0,0 +1,269 @@
+package service
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxDuplicateBlockSize membatasi jumlah user per blok yang dibandingkan berpasangan,
+// supaya deteksi tetap ringan walau ada blok besar (misal nama belakang yang sangat umum).
+const maxDuplicateBlockSize = 300
+
+// nameTitleRe menghapus gelar akademik umum dari nama (dr., prof., s.kom, m.t., dll).
+var nameTitleRe = regexp.MustCompile(`\b(dr|drs|prof|ir|s\.?kom|s\.?t|s\.?si|m\.?kom|m\.?t|m\.?sc|ph\.?d)\b\.?`)
+
+// nonLetterRe menghapus karakter selain huruf dan spasi.
+var nonLetterRe = regexp.MustCompile(`[^a-z ]+`)
+
+// DuplicateCandidate adalah 1 pasangan user yang dicurigai orang yang sama.
+type DuplicateCandidate struct {
+	UserA   repository.UserProfileRow `json:"userA"`
+	UserB   repository.UserProfileRow `json:"userB"`
+	Reasons []string                  `json:"reasons"`
+}
+
+// normalizeFullName: huruf kecil, tanpa gelar & tanda baca, spasi dirapikan.
+func normalizeFullName(name string) string {
+	n := strings.ToLower(name)
+	n = nameTitleRe.ReplaceAllString(n, " ")
+	n = nonLetterRe.ReplaceAllString(n, " ")
+	return strings.Join(strings.Fields(n), " ")
+}
+
+// levenshtein menghitung edit distance 2 string (rune-aware).
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// similarNames: nama dianggap mirip jika edit distance <= 15% dari panjang nama terpanjang.
+func similarNames(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	longest := max(len([]rune(a)), len([]rune(b)))
+	return levenshtein(a, b)*100 <= longest*15
+}
+
+// emailParts memisahkan local-part (tanpa titik) dan domain email.
+func emailParts(email string) (string, string) {
+	e := strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(e, "@")
+	if at < 0 {
+		return e, ""
+	}
+	return strings.ReplaceAll(e[:at], ".", ""), e[at+1:]
+}
+
+// findDuplicateCandidates membandingkan user per blok (bukan O(n²) seluruh user):
+// - blok nama belakang ternormalisasi → kemiripan nama lengkap
+// - blok domain + 2 huruf awal email → email hampir sama (edit distance <= 2)
+// - NIM / kode dosen yang sama di profil berbeda
+func findDuplicateCandidates(rows []repository.UserProfileRow) []DuplicateCandidate {
+	type pairKey struct{ a, b uuid.UUID }
+	found := map[pairKey]*DuplicateCandidate{}
+	byID := make(map[uuid.UUID]repository.UserProfileRow, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+
+	addReason := func(a, b uuid.UUID, reason string) {
+		if a == b {
+			return
+		}
+		if b.String() < a.String() {
+			a, b = b, a
+		}
+		k := pairKey{a, b}
+		c, ok := found[k]
+		if !ok {
+			c = &DuplicateCandidate{UserA: byID[a], UserB: byID[b]}
+			found[k] = c
+		}
+		for _, r := range c.Reasons {
+			if r == reason {
+				return
+			}
+		}
+		c.Reasons = append(c.Reasons, reason)
+	}
+
+	compareBlocks := func(blocks map[string][]int, cmp func(i, j int) (bool, string)) {
+		for key, members := range blocks {
+			if len(members) > maxDuplicateBlockSize {
+				log.Printf("[DUPLICATE] Blok '%s' terlalu besar (%d user), dilewati", key, len(members))
+				continue
+			}
+			for x := 0; x < len(members); x++ {
+				for y := x + 1; y < len(members); y++ {
+					if ok, reason := cmp(members[x], members[y]); ok {
+						addReason(rows[members[x]].ID, rows[members[y]].ID, reason)
+					}
+				}
+			}
+		}
+	}
+
+	names := make([]string, len(rows))
+	nameBlocks := map[string][]int{}
+	emailBlocks := map[string][]int{}
+	codeOwners := map[string][]int{}
+
+	for i, r := range rows {
+		names[i] = normalizeFullName(r.FullName)
+		if fields := strings.Fields(names[i]); len(fields) > 0 {
+			last := fields[len(fields)-1]
+			nameBlocks[last] = append(nameBlocks[last], i)
+		}
+
+		local, domain := emailParts(r.Email)
+		if len(local) >= 2 {
+			key := domain + "|" + local[:2]
+			emailBlocks[key] = append(emailBlocks[key], i)
+		}
+
+		for _, code := range []*string{r.StudentNIM, r.LecturerNIP} {
+			if code != nil && strings.TrimSpace(*code) != "" {
+				c := strings.ToLower(strings.TrimSpace(*code))
+				codeOwners[c] = append(codeOwners[c], i)
+			}
+		}
+	}
+
+	compareBlocks(nameBlocks, func(i, j int) (bool, string) {
+		return similarNames(names[i], names[j]), "similar_full_name"
+	})
+	compareBlocks(emailBlocks, func(i, j int) (bool, string) {
+		li, _ := emailParts(rows[i].Email)
+		lj, _ := emailParts(rows[j].Email)
+		return levenshtein(li, lj) <= 2, "similar_email"
+	})
+	compareBlocks(codeOwners, func(i, j int) (bool, string) {
+		return true, "same_profile_code"
+	})
+
+	result := make([]DuplicateCandidate, 0, len(found))
+	for _, c := range found {
+		sort.Strings(c.Reasons)
+		result = append(result, *c)
+	}
+	// Pasangan dengan alasan terbanyak di atas.
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Reasons) != len(result[j].Reasons) {
+			return len(result[i].Reasons) > len(result[j].Reasons)
+		}
+		return result[i].UserA.FullName < result[j].UserA.FullName
+	})
+	return result
+}
+
+// ===============================================================
+//  GET /api/v1/admin/users/possible-duplicates
+//  Admin: daftar pasangan akun yang kemungkinan milik orang yang sama.
+// ===============================================================
+func (s *adminService) GetPossibleDuplicates(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	rows, err := s.repo.FindUserProfiles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil data user", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mendeteksi kemungkinan akun ganda", findDuplicateCandidates(rows)))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/users/merge
+//  Body: { "primaryUserId": "...", "secondaryUserId": "..." }
+//  Admin: gabungkan akun sekunder ke akun primer (akun sekunder dinonaktifkan).
+// ===============================================================
+func (s *adminService) MergeUsers(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input struct {
+		PrimaryUserID   string `json:"primaryUserId" binding:"required"`
+		SecondaryUserID string `json:"secondaryUserId" binding:"required"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	primaryID, err1 := uuid.Parse(input.PrimaryUserID)
+	secondaryID, err2 := uuid.Parse(input.SecondaryUserID)
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", "invalid_user_id", nil))
+		return
+	}
+	if primaryID == secondaryID {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("User primer dan sekunder tidak boleh sama", "same_user", nil))
+		return
+	}
+
+	for _, id := range []uuid.UUID{primaryID, secondaryID} {
+		if _, err := s.repo.FindUserByID(id); err != nil {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("User tidak ditemukan", err.Error(), map[string]any{"userId": id}))
+			return
+		}
+	}
+
+	// Dokumen prestasi Mongo dipindah di dalam dual-write repository (setelah baris Postgres dikunci).
+	summary, err := s.repo.MergeUsers(ctx.Request.Context(), primaryID, secondaryID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menggabungkan akun", err.Error(), nil))
+		return
+	}
+	// Akun sekunder nonaktif & role primer mungkin berubah: token lama langsung ditolak.
+	middleware.ForgetTokenVersion(primaryID)
+	middleware.ForgetTokenVersion(secondaryID)
+
+	adminID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &adminID, "user.merge", "user", primaryID.String(), map[string]any{
+		"primaryUserId":   primaryID,
+		"secondaryUserId": secondaryID,
+		"summary":         summary,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Akun berhasil digabungkan", summary))
+}
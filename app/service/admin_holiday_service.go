@@ -0,0 +1,220 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// holidayInput adalah body create/update 1 tanggal libur.
+type holidayInput struct {
+	Date string `json:"date" binding:"required"` // format YYYY-MM-DD
+	Name string `json:"name" binding:"required"`
+}
+
+// toModel memvalidasi & mengubah input menjadi model.Holiday.
+func (in holidayInput) toModel() (*model.Holiday, error) {
+	d, err := time.Parse(utils.HolidayDateFormat, strings.TrimSpace(in.Date))
+	if err != nil {
+		return nil, err
+	}
+	return &model.Holiday{Date: d, Name: strings.TrimSpace(in.Name)}, nil
+}
+
+// ===============================================================
+//  GET /api/v1/admin/holidays?year=2025
+// ===============================================================
+func (s *adminService) GetHolidays(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var year *int
+	if y := ctx.Query("year"); y != "" {
+		v, err := strconv.Atoi(y)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Parameter year tidak valid", err.Error(), nil))
+			return
+		}
+		year = &v
+	}
+
+	list, err := s.holidayRepo.FindAll(year)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil daftar hari libur", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil daftar hari libur", list))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/holidays
+//  Body: { "date": "2025-03-31", "name": "Idul Fitri" }
+// ===============================================================
+func (s *adminService) CreateHoliday(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input holidayInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	h, err := input.toModel()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Format tanggal harus YYYY-MM-DD", err.Error(), nil))
+		return
+	}
+
+	if err := s.holidayRepo.Create(h); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Gagal menyimpan hari libur (tanggal mungkin sudah ada)", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("Hari libur berhasil ditambahkan", h))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/holidays/import
+//  Body: { "year": 2025, "holidays": [ { "date": "...", "name": "..." }, ... ] }
+//  Tanggal yang sudah ada diperbarui namanya; semua tanggal harus di tahun tsb.
+// ===============================================================
+func (s *adminService) ImportHolidays(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input struct {
+		Year     int            `json:"year" binding:"required"`
+		Holidays []holidayInput `json:"holidays" binding:"required,dive"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	list := make([]model.Holiday, 0, len(input.Holidays))
+	seen := map[string]bool{}
+	for _, in := range input.Holidays {
+		h, err := in.toModel()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Format tanggal harus YYYY-MM-DD", err.Error(), map[string]any{"date": in.Date}))
+			return
+		}
+		if h.Date.Year() != input.Year {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Tanggal di luar tahun yang diimpor", "year_mismatch", map[string]any{"date": in.Date}))
+			return
+		}
+		key := h.Date.Format(utils.HolidayDateFormat)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, *h)
+	}
+
+	if err := s.holidayRepo.Upsert(list); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengimpor hari libur", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Hari libur berhasil diimpor", map[string]any{
+			"year":     input.Year,
+			"imported": len(list),
+		}))
+}
+
+// ===============================================================
+//  PUT /api/v1/admin/holidays/:id
+// ===============================================================
+func (s *adminService) UpdateHoliday(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID hari libur tidak valid", err.Error(), nil))
+		return
+	}
+	if _, err := s.holidayRepo.FindByID(id); err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Hari libur tidak ditemukan", err.Error(), nil))
+		return
+	}
+
+	var input holidayInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	h, err := input.toModel()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Format tanggal harus YYYY-MM-DD", err.Error(), nil))
+		return
+	}
+	h.ID = id
+
+	if err := s.holidayRepo.Update(h); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Gagal memperbarui hari libur", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Hari libur berhasil diperbarui", h))
+}
+
+// ===============================================================
+//  DELETE /api/v1/admin/holidays/:id
+// ===============================================================
+func (s *adminService) DeleteHoliday(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID hari libur tidak valid", err.Error(), nil))
+		return
+	}
+
+	if err := s.holidayRepo.Delete(id); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghapus hari libur", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Hari libur berhasil dihapus", nil))
+}
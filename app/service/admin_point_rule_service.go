@@ -0,0 +1,181 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// pointRuleInput adalah body create/update 1 aturan poin.
+// Kolom opsional yang tidak diisi berarti aturan berlaku untuk semua nilai kolom tsb.
+type pointRuleInput struct {
+	AchievementType  string   `json:"achievementType" binding:"required"`
+	CompetitionLevel *string  `json:"competitionLevel"`
+	Rank             *int     `json:"rank"`
+	MedalType        *string  `json:"medalType"`
+	Points           *float64 `json:"points" binding:"required"`
+}
+
+// toModel merapikan input (huruf kecil, string kosong = tidak diisi) menjadi model.PointRule.
+func (in pointRuleInput) toModel() (*model.PointRule, string) {
+	if *in.Points < 0 {
+		return nil, "points tidak boleh negatif"
+	}
+	if in.Rank != nil && *in.Rank < 1 {
+		return nil, "rank minimal 1"
+	}
+	rule := &model.PointRule{
+		AchievementType:  strings.ToLower(strings.TrimSpace(in.AchievementType)),
+		CompetitionLevel: normalizeRuleField(in.CompetitionLevel),
+		Rank:             in.Rank,
+		MedalType:        normalizeRuleField(in.MedalType),
+		Points:           *in.Points,
+	}
+	if rule.AchievementType == "" {
+		return nil, "achievementType wajib diisi"
+	}
+	return rule, ""
+}
+
+func normalizeRuleField(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	s := strings.ToLower(strings.TrimSpace(*v))
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ===============================================================
+//  GET /api/v1/admin/point-rules?achievementType=competition
+// ===============================================================
+func (s *adminService) GetPointRules(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	list, err := s.pointRuleRepo.FindAll(strings.ToLower(strings.TrimSpace(ctx.Query("achievementType"))))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil aturan poin", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil aturan poin", list))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/point-rules
+//  Body: { "achievementType": "competition", "competitionLevel": "national", "rank": 1, "points": 35 }
+// ===============================================================
+func (s *adminService) CreatePointRule(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input pointRuleInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	rule, msg := input.toModel()
+	if rule == nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed(msg, "invalid_point_rule", nil))
+		return
+	}
+
+	if err := s.pointRuleRepo.Create(rule); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyimpan aturan poin", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("Aturan poin berhasil ditambahkan", rule))
+}
+
+// ===============================================================
+//  PUT /api/v1/admin/point-rules/:id
+//  Poin prestasi yang sudah tersimpan tidak dihitung ulang.
+// ===============================================================
+func (s *adminService) UpdatePointRule(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID aturan poin tidak valid", err.Error(), nil))
+		return
+	}
+	existing, err := s.pointRuleRepo.FindByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Aturan poin tidak ditemukan", err.Error(), nil))
+		return
+	}
+
+	var input pointRuleInput
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	rule, msg := input.toModel()
+	if rule == nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed(msg, "invalid_point_rule", nil))
+		return
+	}
+	rule.ID = id
+	rule.CreatedAt = existing.CreatedAt
+
+	if err := s.pointRuleRepo.Update(rule); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memperbarui aturan poin", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Aturan poin berhasil diperbarui", rule))
+}
+
+// ===============================================================
+//  DELETE /api/v1/admin/point-rules/:id
+// ===============================================================
+func (s *adminService) DeletePointRule(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID aturan poin tidak valid", err.Error(), nil))
+		return
+	}
+
+	if err := s.pointRuleRepo.Delete(id); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghapus aturan poin", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Aturan poin berhasil dihapus", nil))
+}
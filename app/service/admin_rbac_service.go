@@ -0,0 +1,151 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validateRBACDocument memeriksa dokumen import sebelum diterapkan:
+// - versi dokumen dikenal
+// - nama permission/role tidak kosong dan tidak bentrok (case-insensitive)
+// - role hanya merujuk permission yang didefinisikan di dokumen
+func validateRBACDocument(doc *repository.RBACDocument) []string {
+	var problems []string
+
+	if doc.Version != 1 {
+		problems = append(problems, fmt.Sprintf("versi dokumen tidak didukung: %d", doc.Version))
+	}
+
+	perms := map[string]bool{}
+	permLower := map[string]string{}
+	for _, p := range doc.Permissions {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			problems = append(problems, "permission tanpa nama")
+			continue
+		}
+		if prev, ok := permLower[strings.ToLower(name)]; ok {
+			problems = append(problems, fmt.Sprintf("nama permission bentrok: '%s' dan '%s'", prev, name))
+			continue
+		}
+		permLower[strings.ToLower(name)] = name
+		perms[name] = true
+	}
+
+	roleLower := map[string]string{}
+	for _, r := range doc.Roles {
+		name := strings.TrimSpace(r.Name)
+		if name == "" {
+			problems = append(problems, "role tanpa nama")
+			continue
+		}
+		if prev, ok := roleLower[strings.ToLower(name)]; ok {
+			problems = append(problems, fmt.Sprintf("nama role bentrok: '%s' dan '%s'", prev, name))
+			continue
+		}
+		roleLower[strings.ToLower(name)] = name
+
+		seen := map[string]bool{}
+		for _, pn := range r.Permissions {
+			if !perms[pn] {
+				problems = append(problems, fmt.Sprintf("role '%s' memakai permission tidak dikenal: '%s'", name, pn))
+			}
+			if seen[pn] {
+				problems = append(problems, fmt.Sprintf("role '%s' mencantumkan permission '%s' lebih dari sekali", name, pn))
+			}
+			seen[pn] = true
+		}
+	}
+
+	return problems
+}
+
+// ===============================================================
+//  GET /api/v1/admin/rbac/export
+//  Admin: dokumen JSON berisi roles, permissions, dan assignment-nya.
+// ===============================================================
+func (s *adminService) ExportRBAC(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	doc, err := s.rbacRepo.Export()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengekspor konfigurasi RBAC", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengekspor konfigurasi RBAC", doc))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/rbac/import?dryRun=true&skipRolesInUse=true
+//  Body: dokumen hasil export.
+//  - dryRun=true        : hanya tampilkan diff (tambah/hapus), tidak ada perubahan
+//  - skipRolesInUse=true: role yang tidak ada di dokumen tapi masih punya user dibiarkan;
+//                         tanpa flag ini import ditolak (role dengan user tidak pernah dihapus)
+// ===============================================================
+func (s *adminService) ImportRBAC(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var doc repository.RBACDocument
+	if err := utils.BindStrictJSON(ctx, &doc); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Dokumen RBAC tidak valid", err.Error(), nil))
+		return
+	}
+
+	if problems := validateRBACDocument(&doc); len(problems) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity,
+			utils.BuildResponseFailed("Dokumen RBAC tidak lolos validasi", "invalid_rbac_document", problems))
+		return
+	}
+
+	opts := repository.RBACImportOptions{
+		DryRun:         ctx.Query("dryRun") == "true",
+		SkipRolesInUse: ctx.Query("skipRolesInUse") == "true",
+	}
+
+	diff, err := s.rbacRepo.Import(&doc, opts)
+	if errors.Is(err, repository.ErrRolesInUse) {
+		ctx.JSON(http.StatusConflict,
+			utils.BuildResponseFailed("Import akan menghapus role yang masih dipakai user", "role_in_use", diff))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengimpor konfigurasi RBAC", err.Error(), nil))
+		return
+	}
+
+	if opts.DryRun {
+		ctx.JSON(http.StatusOK,
+			utils.BuildResponseSuccess("Dry run: perubahan belum diterapkan", map[string]any{
+				"dryRun": true,
+				"diff":   diff,
+			}))
+		return
+	}
+
+	adminID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &adminID, "rbac.import", "rbac", "", diff)
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Konfigurasi RBAC berhasil diimpor", map[string]any{
+			"dryRun": false,
+			"diff":   diff,
+		}))
+}
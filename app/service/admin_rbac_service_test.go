@@ -0,0 +1,175 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeRBACRepo mengembalikan dokumen export tetap dan mencatat import terakhir.
+type fakeRBACRepo struct {
+	doc      *repository.RBACDocument
+	diff     *repository.RBACDiff
+	err      error
+	imported *repository.RBACDocument
+	opts     repository.RBACImportOptions
+}
+
+func (r *fakeRBACRepo) Export() (*repository.RBACDocument, error) { return r.doc, nil }
+
+func (r *fakeRBACRepo) Import(doc *repository.RBACDocument, opts repository.RBACImportOptions) (*repository.RBACDiff, error) {
+	r.imported, r.opts = doc, opts
+	return r.diff, r.err
+}
+
+func testRBACDocument() *repository.RBACDocument {
+	return &repository.RBACDocument{
+		Version: 1,
+		Permissions: []repository.RBACPermission{
+			{Name: "achievements:read", Resource: "achievements", Action: "read", Description: "Lihat prestasi"},
+			{Name: "achievements:verify", Resource: "achievements", Action: "verify"},
+		},
+		Roles: []repository.RBACRole{
+			{Name: "dosen_wali", Permissions: []string{"achievements:read", "achievements:verify"}},
+			{Name: "mahasiswa", Description: "Mahasiswa", Permissions: []string{"achievements:read"}},
+		},
+	}
+}
+
+func TestValidateRBACDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*repository.RBACDocument)
+		want   int
+	}{
+		{name: "dokumen export valid", mutate: func(*repository.RBACDocument) {}},
+		{name: "versi tidak dikenal", mutate: func(d *repository.RBACDocument) { d.Version = 2 }, want: 1},
+		{name: "permission bentrok beda huruf", mutate: func(d *repository.RBACDocument) {
+			d.Permissions = append(d.Permissions, repository.RBACPermission{Name: "Achievements:Read"})
+		}, want: 1},
+		{name: "role bentrok beda huruf", mutate: func(d *repository.RBACDocument) {
+			d.Roles = append(d.Roles, repository.RBACRole{Name: "Mahasiswa"})
+		}, want: 1},
+		{name: "permission tidak dikenal & ganda", mutate: func(d *repository.RBACDocument) {
+			d.Roles[1].Permissions = []string{"achievements:read", "achievements:read", "users:delete"}
+		}, want: 2},
+		{name: "nama kosong", mutate: func(d *repository.RBACDocument) {
+			d.Permissions = append(d.Permissions, repository.RBACPermission{Name: " "})
+			d.Roles = append(d.Roles, repository.RBACRole{Name: ""})
+		}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := testRBACDocument()
+			tt.mutate(doc)
+			if got := validateRBACDocument(doc); len(got) != tt.want {
+				t.Fatalf("masalah %q, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRBACExportImportRoundTrip: hasil export bisa langsung diimpor (lolos binding strict &
+// validasi) tanpa kehilangan isi; dry-run meneruskan opsinya, mengembalikan diff dari repository
+// apa adanya dan tidak diaudit, sedangkan import sungguhan diaudit.
+func TestRBACExportImportRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := testRBACDocument()
+	diff := &repository.RBACDiff{
+		PermissionsAdded: []string{"reports:read"},
+		AssignmentsAdded: []repository.RBACAssignment{{Role: "dosen_wali", Permission: "reports:read"}},
+	}
+	rbac := &fakeRBACRepo{doc: doc, diff: diff}
+	audit := &fakeAuditRepo{}
+	svc := NewAdminService(nil, nil, audit, rbac, nil, nil, nil, nil, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("role", "admin"); c.Set("userID", uuid.New()) })
+	r.GET("/admin/rbac/export", svc.ExportRBAC)
+	r.POST("/admin/rbac/import", svc.ImportRBAC)
+
+	w, _ := doJSON(t, r, http.MethodGet, "/admin/rbac/export", "", nil)
+	var exported struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("export: status %d, body %s", w.Code, w.Body)
+	}
+
+	importDoc := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/admin/rbac/import"+query, strings.NewReader(string(exported.Data)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		checkEnvelope(t, w)
+		return w
+	}
+
+	// Dry run: tidak ada audit, diff dari repository dikembalikan utuh.
+	w = importDoc("?dryRun=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry run: status %d, body %s", w.Code, w.Body)
+	}
+	if !rbac.opts.DryRun || rbac.opts.SkipRolesInUse {
+		t.Fatalf("opsi import %+v, want DryRun saja", rbac.opts)
+	}
+	if !reflect.DeepEqual(rbac.imported, doc) {
+		t.Fatalf("dokumen yang diimpor %+v, want sama dengan export %+v", rbac.imported, doc)
+	}
+	var resp struct {
+		Data struct {
+			DryRun bool                `json:"dryRun"`
+			Diff   repository.RBACDiff `json:"diff"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.Data.DryRun || !reflect.DeepEqual(resp.Data.Diff, *diff) {
+		t.Fatalf("dry run response %+v, want diff %+v", resp.Data, *diff)
+	}
+	if len(audit.actions) != 0 {
+		t.Fatalf("dry run diaudit: %v", audit.actions)
+	}
+
+	// Import sungguhan: diaudit dengan diff yang sama.
+	w = importDoc("?skipRolesInUse=true")
+	if w.Code != http.StatusOK || rbac.opts.DryRun || !rbac.opts.SkipRolesInUse {
+		t.Fatalf("import: status %d, opsi %+v", w.Code, rbac.opts)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != "rbac.import" || audit.payloads[0] != diff {
+		t.Fatalf("audit %v %v, want rbac.import dengan diff", audit.actions, audit.payloads)
+	}
+
+	// Role yang masih dipakai user: 409 beserta diff-nya.
+	rbac.err = repository.ErrRolesInUse
+	rbac.diff = &repository.RBACDiff{RolesKeptInUse: []string{"admin"}}
+	w = importDoc("")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"rolesKeptInUse":["admin"]`) {
+		t.Fatalf("role dipakai: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestImportRBACRejectsInvalidDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rbac := &fakeRBACRepo{}
+	svc := NewAdminService(nil, nil, &fakeAuditRepo{}, rbac, nil, nil, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/admin/rbac/import", func(c *gin.Context) { c.Set("role", "admin") }, svc.ImportRBAC)
+
+	doc := testRBACDocument()
+	doc.Roles[0].Permissions = append(doc.Roles[0].Permissions, "users:delete")
+	w, _ := doJSON(t, r, http.MethodPost, "/admin/rbac/import?dryRun=true", "", doc)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "users:delete") {
+		t.Fatalf("status %d, body %s, want 422 dengan permission tidak dikenal", w.Code, w.Body)
+	}
+	if rbac.imported != nil {
+		t.Fatal("dokumen tidak valid tetap diteruskan ke repository")
+	}
+}
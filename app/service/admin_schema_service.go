@@ -0,0 +1,76 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===============================================================
+//  GET /api/v1/admin/maintenance/schema
+//  Admin: jumlah dokumen prestasi per schemaVersion + jumlah upgrade in-memory saat dibaca.
+// ===============================================================
+func (s *adminService) GetSchemaStatus(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	counts, err := s.achievementRepo.SchemaVersionCounts(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghitung versi dokumen prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil status schema dokumen prestasi", map[string]any{
+			"currentVersion":     model.AchievementSchemaVersion,
+			"documentsByVersion": counts,
+			"upgradedOnRead":     repository.SchemaUpgradeCounts(),
+		}))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/maintenance/schema/upgrade?limit=500
+//  Admin: persist upgrade dokumen prestasi versi lama secara bulk (per batch).
+// ===============================================================
+func (s *adminService) UpgradeDocumentSchema(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "500"))
+	if err != nil || limit < 1 || limit > 10000 {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("limit harus 1..10000", "invalid_limit", nil))
+		return
+	}
+
+	upgraded, err := s.achievementRepo.UpgradeStoredDocuments(ctx, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal meng-upgrade dokumen prestasi", err.Error(), map[string]any{
+				"upgraded": upgraded,
+			}))
+		return
+	}
+
+	actorID, _ := getUserIDFromContext(ctx)
+	_ = s.auditRepo.Record(ctx, &actorID, "maintenance.schema_upgrade", "achievement", "", map[string]any{
+		"upgraded": upgraded,
+		"limit":    limit,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Upgrade dokumen prestasi selesai", map[string]any{
+			"upgraded":       upgraded,
+			"currentVersion": model.AchievementSchemaVersion,
+		}))
+}
@@ -6,11 +6,11 @@ import (
 
 	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
 	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AdminService interface {
@@ -20,15 +20,72 @@ type AdminService interface {
 	GetAllUsers(ctx *gin.Context)
 	GetUserDetail(ctx *gin.Context)
 	UpdateUserRole(ctx *gin.Context)
+	GetPossibleDuplicates(ctx *gin.Context)
+	MergeUsers(ctx *gin.Context)
+	LinkLecturerProfile(ctx *gin.Context)
+	GetUserLoginHistory(ctx *gin.Context)
+	GetUserSessions(ctx *gin.Context)
+	RevokeUserSession(ctx *gin.Context)
+	ImpersonateUser(ctx *gin.Context)
+	ResetUserTwoFactor(ctx *gin.Context)
+	ExportRBAC(ctx *gin.Context)
+	ImportRBAC(ctx *gin.Context)
+	GetHolidays(ctx *gin.Context)
+	CreateHoliday(ctx *gin.Context)
+	ImportHolidays(ctx *gin.Context)
+	UpdateHoliday(ctx *gin.Context)
+	DeleteHoliday(ctx *gin.Context)
+	GetPointRules(ctx *gin.Context)
+	CreatePointRule(ctx *gin.Context)
+	UpdatePointRule(ctx *gin.Context)
+	DeletePointRule(ctx *gin.Context)
+	GetAchievementTypes(ctx *gin.Context)
+	CreateAchievementType(ctx *gin.Context)
+	UpdateAchievementType(ctx *gin.Context)
+	DeleteAchievementType(ctx *gin.Context)
+	GetDraftUsage(ctx *gin.Context)
+	GetSchemaStatus(ctx *gin.Context)
+	UpgradeDocumentSchema(ctx *gin.Context)
 	// ❌ SetStudentAdvisor dihapus — sekarang dihandle oleh StudentService (PUT /api/v1/students/:id/advisor)
 }
 
 type adminService struct {
-	repo repository.UserAdminRepository
+	repo            repository.UserAdminRepository
+	achievementRepo repository.AchievementRepository
+	auditRepo       repository.AuditRepository
+	rbacRepo        repository.RBACRepository
+	holidayRepo     repository.HolidayRepository
+	loginEventRepo  repository.LoginEventRepository
+	refreshRepo     repository.RefreshTokenRepository
+	userRepo        repository.UserRepository // data login user target (impersonasi)
+	pointRuleRepo   repository.PointRuleRepository
+	typeRepo        repository.AchievementTypeRepository // katalog tipe prestasi
 }
 
-func NewAdminService(repo repository.UserAdminRepository) AdminService {
-	return &adminService{repo}
+func NewAdminService(
+	repo repository.UserAdminRepository,
+	achievementRepo repository.AchievementRepository,
+	auditRepo repository.AuditRepository,
+	rbacRepo repository.RBACRepository,
+	holidayRepo repository.HolidayRepository,
+	loginEventRepo repository.LoginEventRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	userRepo repository.UserRepository,
+	pointRuleRepo repository.PointRuleRepository,
+	typeRepo repository.AchievementTypeRepository,
+) AdminService {
+	return &adminService{
+		repo:            repo,
+		achievementRepo: achievementRepo,
+		auditRepo:       auditRepo,
+		rbacRepo:        rbacRepo,
+		holidayRepo:     holidayRepo,
+		loginEventRepo:  loginEventRepo,
+		refreshRepo:     refreshRepo,
+		userRepo:        userRepo,
+		pointRuleRepo:   pointRuleRepo,
+		typeRepo:        typeRepo,
+	}
 }
 
 // helper: cek admin
@@ -66,20 +123,32 @@ func (s *adminService) CreateUser(ctx *gin.Context) {
 		} `json:"lecturerProfile"`
 	}
 
-	if err := ctx.ShouldBindJSON(&input); err != nil {
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
 		ctx.JSON(http.StatusBadRequest,
 			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
 		return
 	}
 
-	hash, _ := bcrypt.GenerateFromPassword([]byte(input.Password), 10)
+	if failed := utils.ValidatePassword(input.Password, input.Username, input.Email); len(failed) > 0 {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Password tidak memenuhi kebijakan", "weak_password",
+				utils.PasswordPolicyDetails(failed)))
+		return
+	}
+
+	hash, err := utils.HashPassword(input.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memproses password", err.Error(), nil))
+		return
+	}
 
 	user := model.User{
 		ID:           uuid.New(),
 		Username:     input.Username,
 		Email:        input.Email,
 		FullName:     input.FullName,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		RoleID:       uuid.MustParse(input.RoleID),
 		IsActive:     true,
 		CreatedAt:    time.Now(),
@@ -140,7 +209,7 @@ func (s *adminService) UpdateUser(ctx *gin.Context) {
 		Email    string `json:"email"`
 	}
 
-	if err := ctx.ShouldBindJSON(&input); err != nil {
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
 		ctx.JSON(http.StatusBadRequest,
 			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
 		return
@@ -174,6 +243,7 @@ func (s *adminService) DeleteUser(ctx *gin.Context) {
 			utils.BuildResponseFailed("Gagal menghapus user", err.Error(), nil))
 		return
 	}
+	middleware.ForgetTokenVersion(uid)
 
 	ctx.JSON(http.StatusOK,
 		utils.BuildResponseSuccess("User berhasil di-nonaktifkan", nil))
@@ -232,7 +302,7 @@ func (s *adminService) UpdateUserRole(ctx *gin.Context) {
 		RoleID string `json:"roleId" binding:"required"`
 	}
 
-	if err := ctx.ShouldBindJSON(&input); err != nil {
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
 		ctx.JSON(http.StatusBadRequest,
 			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
 		return
@@ -245,6 +315,7 @@ func (s *adminService) UpdateUserRole(ctx *gin.Context) {
 			utils.BuildResponseFailed("Gagal update role", err.Error(), nil))
 		return
 	}
+	middleware.ForgetTokenVersion(uid)
 
 	ctx.JSON(http.StatusOK,
 		utils.BuildResponseSuccess("Role user berhasil diperbarui", nil))
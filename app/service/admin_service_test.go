@@ -0,0 +1,74 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeUserAdminRepo mencatat user yang dibuat lewat CreateUser.
+type fakeUserAdminRepo struct {
+	repository.UserAdminRepository
+	created []model.User
+}
+
+func (r *fakeUserAdminRepo) CreateUser(user *model.User) error {
+	r.created = append(r.created, *user)
+	return nil
+}
+
+func TestCreateUserPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus int
+		wantRules  []string
+	}{
+		{name: "password valid", password: "rahasia123", wantStatus: http.StatusCreated},
+		{name: "password lemah ditolak", password: "123123", wantStatus: http.StatusBadRequest,
+			wantRules: []string{"min_length", "letter"}},
+		{name: "sama dengan username", password: "budi2024", wantStatus: http.StatusBadRequest,
+			wantRules: []string{"not_identity"}},
+		// bcrypt menolak password > 72 byte: user tidak boleh dibuat dengan hash kosong.
+		{name: "hash gagal", password: strings.Repeat("a1", 40), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			t.Setenv("PASSWORD_MIN_LENGTH", "")
+			repo := &fakeUserAdminRepo{}
+			svc := NewAdminService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/users", func(c *gin.Context) { c.Set("role", "admin") }, svc.CreateUser)
+
+			w, _ := doJSON(t, r, http.MethodPost, "/users", "", map[string]any{
+				"username": "budi2024", "email": "budi@kampus.ac.id", "password": tt.password,
+				"fullName": "Budi", "roleId": uuid.NewString(),
+			})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d; body %s", w.Code, tt.wantStatus, w.Body)
+			}
+			for _, rule := range tt.wantRules {
+				if !strings.Contains(w.Body.String(), `"`+rule+`"`) {
+					t.Fatalf("aturan %q tidak ada di response: %s", rule, w.Body)
+				}
+			}
+			wantCreated := 0
+			if tt.wantStatus == http.StatusCreated {
+				wantCreated = 1
+			}
+			if len(repo.created) != wantCreated {
+				t.Fatalf("user dibuat %d kali, want %d", len(repo.created), wantCreated)
+			}
+			if wantCreated == 1 && repo.created[0].PasswordHash == "" {
+				t.Fatal("user dibuat tanpa hash password")
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// apiKeyTouchInterval membatasi update last_used_at (paling sering 1x per menit per key).
+const apiKeyTouchInterval = time.Minute
+
+// ErrAPIKeyInvalid dikembalikan Resolve untuk key yang tidak dikenal, dicabut, atau kedaluwarsa.
+var ErrAPIKeyInvalid = errors.New("api key invalid, revoked, or expired")
+
+// APIKeyService meng-handle endpoint admin pengelolaan API key integrasi.
+type APIKeyService interface {
+	CreateAPIKey(ctx *gin.Context) // POST   /api/v1/admin/api-keys
+	ListAPIKeys(ctx *gin.Context)  // GET    /api/v1/admin/api-keys
+	RevokeAPIKey(ctx *gin.Context) // DELETE /api/v1/admin/api-keys/:id
+}
+
+// APIKeyManager mengelola API key dan menyediakan resolver untuk middleware.APIKeyAuth.
+type APIKeyManager struct {
+	repo      repository.APIKeyRepository
+	auditRepo repository.AuditRepository
+	now       func() time.Time
+}
+
+// NewAPIKeyManager membuat instance APIKeyManager.
+func NewAPIKeyManager(repo repository.APIKeyRepository, auditRepo repository.AuditRepository) *APIKeyManager {
+	return &APIKeyManager{repo: repo, auditRepo: auditRepo, now: time.Now}
+}
+
+// Resolve memenuhi middleware.APIKeyResolver: cocokkan hash, tolak key yang dicabut /
+// kedaluwarsa, lalu catat pemakaian terakhir.
+func (m *APIKeyManager) Resolve(rawKey, clientIP string) (uuid.UUID, []string, error) {
+	prefix, err := utils.ParseAPIKeyPrefix(rawKey)
+	if err != nil {
+		return uuid.Nil, nil, ErrAPIKeyInvalid
+	}
+	key, err := m.repo.FindByPrefix(prefix)
+	if err != nil {
+		return uuid.Nil, nil, ErrAPIKeyInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashAPIKey(rawKey))) != 1 {
+		return uuid.Nil, nil, ErrAPIKeyInvalid
+	}
+
+	now := m.now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return uuid.Nil, nil, ErrAPIKeyInvalid
+	}
+
+	if err := m.repo.TouchLastUsed(key.ID, now, clientIP, apiKeyTouchInterval); err != nil {
+		log.Printf("⚠️  gagal mencatat pemakaian API key %s: %v", key.ID, err)
+	}
+	return key.ID, key.Permissions, nil
+}
+
+// apiKeyCan: true jika request berasal dari API key dengan permission perm.
+// Dipakai handler read-only yang membuka akses untuk integrasi (selain role user).
+func apiKeyCan(ctx *gin.Context, perm string) bool {
+	return middleware.IsAPIKeyPrincipal(ctx) && middleware.HasPermission(ctx, perm)
+}
+
+// ===============================================================
+//  POST /api/v1/admin/api-keys
+//  Body: { "name": "Portal Fakultas", "permissions": ["achievement:read"], "expiresAt": "2026-12-31T00:00:00Z" }
+//  Key mentah hanya dikembalikan di response ini; yang disimpan hanya hash-nya.
+// ===============================================================
+func (m *APIKeyManager) CreateAPIKey(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input struct {
+		Name        string     `json:"name" binding:"required,max=100"`
+		Permissions []string   `json:"permissions" binding:"required,min=1,dive,required"`
+		ExpiresAt   *time.Time `json:"expiresAt"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	allowed := map[string]bool{}
+	for _, p := range model.APIKeyPermissions() {
+		allowed[p] = true
+	}
+	seen := map[string]bool{}
+	perms := make([]string, 0, len(input.Permissions))
+	for _, p := range input.Permissions {
+		if !allowed[p] {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Permission tidak dapat diberikan ke API key", "invalid_permission",
+					map[string]any{"permission": p, "allowed": model.APIKeyPermissions()}))
+			return
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(m.now()) {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("expiresAt harus di masa depan", "invalid_expiry", nil))
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized,
+			utils.BuildResponseFailed("Autentikasi admin diperlukan", "no_user_id", nil))
+		return
+	}
+
+	raw, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal membuat API key", err.Error(), nil))
+		return
+	}
+
+	key := &model.APIKey{
+		Name:        input.Name,
+		Prefix:      prefix,
+		KeyHash:     hash,
+		Permissions: perms,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedBy:   adminID,
+	}
+	if err := m.repo.Create(key); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyimpan API key", err.Error(), nil))
+		return
+	}
+
+	_ = m.auditRepo.Record(ctx, &adminID, "api_key.create", "api_key", key.ID.String(), map[string]any{
+		"name":        key.Name,
+		"prefix":      key.Prefix,
+		"permissions": key.Permissions,
+		"expiresAt":   key.ExpiresAt,
+	})
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("API key dibuat. Simpan key ini, tidak akan ditampilkan lagi", gin.H{
+			"apiKey": key,
+			"key":    raw,
+		}))
+}
+
+// ===============================================================
+//  GET /api/v1/admin/api-keys
+//  Admin: semua API key (tanpa key/hash) beserta pemakaian terakhir & status.
+// ===============================================================
+func (m *APIKeyManager) ListAPIKeys(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	keys, err := m.repo.FindAll()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil API key", err.Error(), nil))
+		return
+	}
+
+	now := m.now()
+	out := make([]gin.H, 0, len(keys))
+	for _, k := range keys {
+		status := "active"
+		switch {
+		case k.RevokedAt != nil:
+			status = "revoked"
+		case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+			status = "expired"
+		}
+		out = append(out, gin.H{"apiKey": k, "status": status})
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil API key", out))
+}
+
+// ===============================================================
+//  DELETE /api/v1/admin/api-keys/:id
+//  Admin: cabut API key (berlaku segera untuk request berikutnya).
+// ===============================================================
+func (m *APIKeyManager) RevokeAPIKey(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID API key tidak valid", err.Error(), nil))
+		return
+	}
+
+	key, err := m.repo.Revoke(id, m.now())
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("API key tidak ditemukan atau sudah dicabut", "not_found", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mencabut API key", err.Error(), nil))
+		return
+	}
+
+	actorID, _ := getUserIDFromContext(ctx)
+	_ = m.auditRepo.Record(ctx, &actorID, "api_key.revoke", "api_key", id.String(), map[string]any{
+		"name":   key.Name,
+		"prefix": key.Prefix,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("API key dicabut", key))
+}
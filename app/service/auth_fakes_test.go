@@ -0,0 +1,334 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo adalah UserRepository di memori untuk test alur autentikasi.
+// Method yang tidak dipakai test jatuh ke interface embedded (panic jika dipanggil).
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uuid.UUID]*model.User
+}
+
+func newFakeUserRepo(users ...*model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uuid.UUID]*model.User{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+// newTestUser membuat user aktif dengan password & role tertentu.
+func newTestUser(t *testing.T, username, password, role string) *model.User {
+	t.Helper()
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &model.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        username + "@kampus.ac.id",
+		FullName:     username,
+		PasswordHash: string(hash),
+		IsActive:     true,
+		Role:         model.Role{Name: role},
+	}
+}
+
+func (r *fakeUserRepo) find(match func(*model.User) bool) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) FindByID(id uuid.UUID) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) FindByUsername(username string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) FindStudentByUserID(uuid.UUID) (*model.Student, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) FindLecturerByUserID(uuid.UUID) (*model.Lecturer, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	u.PasswordHash, u.MustChangePassword = passwordHash, false
+	u.TokenVersion++
+	return nil
+}
+
+func (r *fakeUserRepo) UpgradePasswordHash(userID uuid.UUID, oldHash, newHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u := r.users[userID]; u != nil && u.PasswordHash == oldHash {
+		u.PasswordHash = newHash
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) FindTokenVersion(userID uuid.UUID) (int, error) {
+	u, err := r.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	return u.TokenVersion, nil
+}
+
+func (r *fakeUserRepo) RecentPasswordHashes(uuid.UUID, int) ([]string, error) { return nil, nil }
+
+func (r *fakeUserRepo) UpdateLastLogin(uuid.UUID, time.Time, string) error { return nil }
+
+func (r *fakeUserRepo) ConsumeRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	for i, h := range u.TwoFactorRecoveryCodes {
+		if h == hash {
+			u.TwoFactorRecoveryCodes = append(u.TwoFactorRecoveryCodes[:i:i], u.TwoFactorRecoveryCodes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepo) ResetTwoFactor(userID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || !u.TwoFactorEnabled {
+		return false, nil
+	}
+	u.TwoFactorEnabled, u.TwoFactorSecret, u.TwoFactorRecoveryCodes = false, "", nil
+	u.TokenVersion++
+	return true, nil
+}
+
+// update mengubah user tersimpan (mis. admin mengganti role) di dalam lock.
+func (r *fakeUserRepo) update(id uuid.UUID, fn func(*model.User)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.users[id])
+}
+
+// fakeRefreshRepo adalah RefreshTokenRepository di memori (rotasi & pencabutan family).
+type fakeRefreshRepo struct {
+	repository.RefreshTokenRepository
+	mu     sync.Mutex
+	tokens map[string]*model.RefreshToken
+}
+
+func newFakeRefreshRepo() *fakeRefreshRepo {
+	return &fakeRefreshRepo{tokens: map[string]*model.RefreshToken{}}
+}
+
+func (r *fakeRefreshRepo) Create(tok *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tok.CreatedAt.IsZero() {
+		tok.CreatedAt = time.Now()
+	}
+	cp := *tok
+	r.tokens[tok.JTI] = &cp
+	return nil
+}
+
+func (r *fakeRefreshRepo) FindByJTI(jti string) (*model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tok, ok := r.tokens[jti]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *tok
+	return &cp, nil
+}
+
+func (r *fakeRefreshRepo) Rotate(oldJTI string, next *model.RefreshToken) error {
+	r.mu.Lock()
+	old, ok := r.tokens[oldJTI]
+	if !ok || old.RevokedAt != nil {
+		r.mu.Unlock()
+		return repository.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	r.mu.Unlock()
+	return r.Create(next)
+}
+
+func (r *fakeRefreshRepo) revokeWhere(match func(*model.RefreshToken) bool) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	families := map[uuid.UUID]bool{}
+	for _, tok := range r.tokens {
+		if tok.RevokedAt == nil && match(tok) {
+			tok.RevokedAt = &now
+			families[tok.FamilyID] = true
+		}
+	}
+	return int64(len(families))
+}
+
+func (r *fakeRefreshRepo) RevokeFamily(familyID uuid.UUID) error {
+	r.revokeWhere(func(tok *model.RefreshToken) bool { return tok.FamilyID == familyID })
+	return nil
+}
+
+func (r *fakeRefreshRepo) RevokeSession(userID, familyID uuid.UUID) (bool, error) {
+	n := r.revokeWhere(func(tok *model.RefreshToken) bool { return tok.UserID == userID && tok.FamilyID == familyID })
+	return n > 0, nil
+}
+
+func (r *fakeRefreshRepo) RevokeOtherSessions(userID, keep uuid.UUID) (int64, error) {
+	return r.revokeWhere(func(tok *model.RefreshToken) bool { return tok.UserID == userID && tok.FamilyID != keep }), nil
+}
+
+func (r *fakeRefreshRepo) FindActiveSessions(userID uuid.UUID, now time.Time) ([]repository.RefreshSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byFamily := map[uuid.UUID]repository.RefreshSession{}
+	for _, tok := range r.tokens {
+		if tok.UserID != userID || tok.RevokedAt != nil || !tok.ExpiresAt.After(now) {
+			continue
+		}
+		byFamily[tok.FamilyID] = repository.RefreshSession{ID: tok.FamilyID, CreatedAt: tok.CreatedAt, ExpiresAt: tok.ExpiresAt}
+	}
+	out := make([]repository.RefreshSession, 0, len(byFamily))
+	for _, sess := range byFamily {
+		out = append(out, sess)
+	}
+	return out, nil
+}
+
+// fakeRevokedRepo adalah denylist access token di memori.
+type fakeRevokedRepo struct {
+	mu   sync.Mutex
+	jtis map[string]time.Time
+}
+
+func newFakeRevokedRepo() *fakeRevokedRepo { return &fakeRevokedRepo{jtis: map[string]time.Time{}} }
+
+func (r *fakeRevokedRepo) Revoke(jti string, _ uuid.UUID, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jtis[jti] = expiresAt
+	return nil
+}
+
+func (r *fakeRevokedRepo) IsRevoked(jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.jtis[jti]
+	return ok, nil
+}
+
+func (r *fakeRevokedRepo) AnyRevoked(keys ...string) (bool, error) {
+	for _, k := range keys {
+		if ok, _ := r.IsRevoked(k); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRevokedRepo) DeleteExpired(time.Time) (int64, error) { return 0, nil }
+
+// fakeLoginEvents membuang riwayat login.
+type fakeLoginEvents struct {
+	repository.LoginEventRepository
+}
+
+func (fakeLoginEvents) Create(*model.LoginEvent) error { return nil }
+
+// authFixture merangkai authService dengan repository palsu.
+type authFixture struct {
+	users   *fakeUserRepo
+	refresh *fakeRefreshRepo
+	revoked *fakeRevokedRepo
+	svc     AuthService
+}
+
+func newAuthFixture(t *testing.T, users ...*model.User) *authFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	f := &authFixture{
+		users:   newFakeUserRepo(users...),
+		refresh: newFakeRefreshRepo(),
+		revoked: newFakeRevokedRepo(),
+	}
+	f.svc = NewAuthService(f.users, f.refresh, f.revoked, fakeLoginEvents{}, nil)
+	return f
+}
+
+// doJSON menjalankan handler pada route dengan body JSON & bearer token opsional,
+// lalu mengembalikan recorder dan field "data" dari response.
+func doJSON(t *testing.T, r *gin.Engine, method, path, token string, body any) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, strings.NewReader(string(raw)))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	checkEnvelope(t, w)
+
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp.Data
+}
+
+// login memanggil POST /login dan mengembalikan access & refresh token.
+func (f *authFixture) login(t *testing.T, username, password string) (string, string) {
+	t.Helper()
+	r := gin.New()
+	r.POST("/login", f.svc.Login)
+	w, data := doJSON(t, r, http.MethodPost, "/login", "", map[string]any{"username": username, "password": password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", w.Code, w.Body)
+	}
+	access, _ := data["token"].(string)
+	refresh, _ := data["refreshToken"].(string)
+	return access, refresh
+}
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
 	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -19,16 +23,42 @@ type AuthService interface {
 	RefreshToken(ctx *gin.Context)  // POST /api/v1/auth/refresh
 	Logout(ctx *gin.Context)        // POST /api/v1/auth/logout
 	GetProfile(ctx *gin.Context)    // GET  /api/v1/auth/profile
+	UpdateProfile(ctx *gin.Context) // PUT  /api/v1/auth/profile
+	ChangePassword(ctx *gin.Context) // POST /api/v1/auth/change-password
+	GetLoginHistory(ctx *gin.Context) // GET  /api/v1/auth/login-history
+	SetupTwoFactor(ctx *gin.Context)     // POST /api/v1/auth/2fa/setup
+	VerifyTwoFactor(ctx *gin.Context)    // POST /api/v1/auth/2fa/verify
+	TwoFactorChallenge(ctx *gin.Context) // POST /api/v1/auth/2fa/challenge
+	GetSessions(ctx *gin.Context)         // GET    /api/v1/auth/sessions
+	RevokeSession(ctx *gin.Context)       // DELETE /api/v1/auth/sessions/:id
+	RevokeOtherSessions(ctx *gin.Context) // DELETE /api/v1/auth/sessions
 }
 
 // authService adalah implementasi konkret AuthService.
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	refreshRepo    repository.RefreshTokenRepository // jti refresh token (rotasi & pencabutan)
+	revokedRepo    repository.RevokedTokenRepository // denylist access token (logout)
+	loginEventRepo repository.LoginEventRepository   // riwayat percobaan login
+	lecturerRepo   repository.LecturerRepository     // lecturerProfile dosen wali (GetProfile)
 }
 
-// NewAuthService membuat instance baru authService dengan dependency UserRepository.
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authService{userRepo}
+// NewAuthService membuat instance baru authService dengan dependency UserRepository,
+// RefreshTokenRepository, RevokedTokenRepository, LoginEventRepository dan LecturerRepository.
+func NewAuthService(
+	userRepo repository.UserRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	revokedRepo repository.RevokedTokenRepository,
+	loginEventRepo repository.LoginEventRepository,
+	lecturerRepo repository.LecturerRepository,
+) AuthService {
+	return &authService{
+		userRepo:       userRepo,
+		refreshRepo:    refreshRepo,
+		revokedRepo:    revokedRepo,
+		loginEventRepo: loginEventRepo,
+		lecturerRepo:   lecturerRepo,
+	}
 }
 
 // ===============================================================
@@ -45,12 +75,14 @@ func (s *authService) Login(ctx *gin.Context) {
 	var input struct {
 		Username string `json:"username" binding:"required"` // bisa username atau email
 		Password string `json:"password" binding:"required"`
+		// RememberMe memakai refresh token berumur lebih panjang (JWT_REMEMBER_ME_REFRESH_TTL).
+		RememberMe bool `json:"rememberMe"`
 	}
 
 	// Bind dan validasi body request.
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Input login tidak valid", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input login tidak valid", err.Error(), nil)
 		return
 	}
 
@@ -68,57 +100,130 @@ func (s *authService) Login(ctx *gin.Context) {
 	}
 
 	if err != nil {
+		s.recordLoginEvent(ctx, nil, input.Username, false, model.LoginFailureUnknownUser)
 		// Untuk keamanan, pesan tetap generic (tidak membocorkan mana yang salah).
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Username atau password salah", "invalid credentials", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Username atau password salah", "invalid credentials", nil)
 		return
 	}
 
 	// Cocokkan password plaintext dengan hash di database.
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)) != nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Username atau password salah", "invalid credentials", nil))
+		s.recordLoginEvent(ctx, &user.ID, input.Username, false, model.LoginFailureInvalidPassword)
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Username atau password salah", "invalid credentials", nil)
 		return
 	}
 
+	// Hash lama dengan cost < BCRYPT_COST di-upgrade selagi password plaintext tersedia.
+	s.upgradePasswordHash(user, input.Password)
+
 	// Cek status aktif user (FR-001 step 3).
 	if !user.IsActive {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Akun dinonaktifkan", "inactive account", nil))
+		s.recordLoginEvent(ctx, &user.ID, input.Username, false, model.LoginFailureInactiveAccount)
+		if user.PendingApproval {
+			utils.RespondError(ctx, http.StatusForbidden,
+				"Akun menunggu persetujuan admin", "pending_approval", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Akun dinonaktifkan", "inactive account", nil)
+		return
+	}
+
+	// 2FA aktif: token baru diberikan setelah kode diverifikasi di POST /auth/2fa/challenge.
+	if user.TwoFactorEnabled {
+		mfaToken, ttl, err := utils.GenerateMFAToken(user.ID, input.RememberMe)
+		if err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal membuat token", err.Error(), nil)
+			return
+		}
+		utils.RespondOK(ctx,
+			"Masukkan kode autentikasi 2 langkah", map[string]any{
+				"mfaRequired": true,
+				"mfaToken":    mfaToken,
+				"expiresIn":   int(ttl.Seconds()),
+			})
 		return
 	}
 
+	s.completeLogin(ctx, user, input.Username, input.RememberMe)
+}
+
+// completeLogin menerbitkan access & refresh token untuk user yang sudah lolos
+// verifikasi (password, dan kode 2FA jika aktif), lalu mencatat login.
+func (s *authService) completeLogin(ctx *gin.Context, user *model.User, attemptedUsername string, rememberMe bool) {
 	// Kumpulkan permission names dari role user (FR-001 step 4).
 	var perms []string
 	for _, p := range user.Role.Permissions {
 		perms = append(perms, p.Name)
 	}
 
-	// Ambil StudentID jika role adalah mahasiswa, untuk disimpan di JWT.
-	// Jika bukan mahasiswa, StudentID akan tetap uuid.Nil.
-	var studentID uuid.UUID
-	if user.Role.Name == "mahasiswa" {
-		if stu, err := s.userRepo.FindStudentByUserID(user.ID); err == nil && stu != nil {
-			studentID = stu.ID
-		}
+	// Ambil StudentID & LecturerID (profil yang dimiliki user) untuk disimpan di JWT.
+	// Akun tertaut (mantan mahasiswa yang kini dosen) membawa keduanya.
+	studentID, lecturerID := profileIDs(s.userRepo, user)
+
+	// Batas sesi bersamaan (SESSION_LIMIT): sesi terlama dicabut, atau 409 jika strict.
+	activeSessions, ok := s.enforceSessionLimit(ctx, user.ID, attemptedUsername)
+	if !ok {
+		return
 	}
 
-	// Generate JWT access token (isi: userID, studentID, roleName, permissions).
+	// Setiap login membuka sesi baru = family refresh token baru.
+	sessionID := uuid.New()
+
+	// Generate JWT access token (isi: userID, studentID, lecturerID, roleName, permissions).
 	token, err := utils.GenerateToken(
 		user.ID,       // userID
-		studentID,     // studentID (uuid.Nil jika bukan mahasiswa)
+		studentID,     // studentID (uuid.Nil jika tidak punya profil mahasiswa)
+		lecturerID,    // lecturerID (uuid.Nil jika tidak punya profil dosen)
 		user.Role.Name, // roleName
 		perms,         // permissions
+		user.MustChangePassword,
+		sessionID,
+		user.TokenVersion,
+	)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat token", err.Error(), nil)
+		return
+	}
+
+	// Refresh token terpisah (tokenType "refresh", masa berlaku JWT_REFRESH_TTL);
+	// hanya diterima POST /api/v1/auth/refresh. jti-nya disimpan sebagai awal family baru.
+	refreshToken, info, err := utils.GenerateRefreshToken(
+		user.ID,
+		studentID,
+		lecturerID,
+		user.Role.Name,
+		perms,
+		user.MustChangePassword,
+		rememberMe,
+		sessionID,
+		user.TokenVersion,
 	)
+	if err == nil {
+		err = s.refreshRepo.Create(&model.RefreshToken{
+			UserID:    user.ID,
+			JTI:       info.JTI,
+			FamilyID:  sessionID,
+			ExpiresAt: info.ExpiresAt,
+			UserAgent: truncate(ctx.Request.UserAgent(), maxLoginEventUserAgent),
+			IP:        ctx.ClientIP(),
+		})
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal membuat token", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat refresh token", err.Error(), nil)
 		return
 	}
 
-	// Untuk sementara, refreshToken disamakan dengan token.
-	// TODO: jika nanti ada mekanisme refresh token terpisah, ubah ke generator khusus.
-	refreshToken := token
+	// Catat login terakhir & riwayat login; kegagalan tidak boleh menggagalkan login.
+	if err := s.userRepo.UpdateLastLogin(user.ID, time.Now(), ctx.ClientIP()); err != nil {
+		log.Printf("⚠️  gagal mencatat login terakhir user %s: %v", user.ID, err)
+	}
+	s.recordLoginEvent(ctx, &user.ID, attemptedUsername, true, "")
 
 	// Bentuk response sesuai contoh di SRS (token, refreshToken, user + permissions).
 	data := map[string]any{
@@ -131,10 +236,16 @@ func (s *authService) Login(ctx *gin.Context) {
 			"role":        user.Role.Name,
 			"permissions": perms,
 		},
+		"mustChangePassword": user.MustChangePassword,
+	}
+	if limit, _ := sessionLimit(); limit > 0 {
+		// client bisa memperingatkan user jika sesi hampir / sudah mencapai batas
+		data["activeSessions"] = activeSessions
+		data["sessionLimit"] = limit
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Login berhasil", data))
+	utils.RespondOK(ctx,
+		"Login berhasil", data)
 }
 // RefreshToken memvalidasi refreshToken dan membuat access token baru.
 func (s *authService) RefreshToken(ctx *gin.Context) {
@@ -143,95 +254,535 @@ func (s *authService) RefreshToken(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Input refresh token tidak valid", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input refresh token tidak valid", err.Error(), nil)
 		return
 	}
 
-	claims, err := utils.ValidateToken(input.RefreshToken)
+	// Hanya refresh token; access token yang dikirim ke sini ditolak (401).
+	claims, err := utils.ValidateRefreshToken(input.RefreshToken)
+	if errors.Is(err, utils.ErrWrongTokenType) {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Token yang dikirim bukan refresh token", "invalid_token_type", nil)
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Refresh token tidak valid atau kedaluwarsa", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Refresh token tidak valid atau kedaluwarsa", err.Error(), nil)
 		return
 	}
 
+	// jti harus tercatat & belum dicabut. Token yang sudah dirotasi dipakai lagi berarti
+	// kemungkinan bocor: seluruh family dicabut sehingga pemegang token curian & pemilik
+	// asli sama-sama harus login ulang.
+	stored, err := s.refreshRepo.FindByJTI(claims.ID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Refresh token tidak valid atau kedaluwarsa", "unknown_refresh_token", nil)
+		return
+	}
+	if stored.RevokedAt != nil {
+		s.revokeRefreshFamily(stored.FamilyID)
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Refresh token sudah pernah dipakai, silakan login ulang", "refresh_token_reused", nil)
+		return
+	}
+
+	// Klaim token baru dibaca ulang dari database (bukan disalin dari refresh token lama),
+	// sehingga perubahan role/permission/profil langsung berlaku di token berikutnya.
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.IsActive {
+		s.revokeRefreshFamily(stored.FamilyID)
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Akun tidak ditemukan atau dinonaktifkan, silakan login ulang", "account_inactive", nil)
+		return
+	}
+	// Role / status / password berubah sejak token dibuat → sesi ini harus login ulang.
+	// Family tidak dicabut: setelah ganti password, family yang sama sudah berisi token baru.
+	if user.TokenVersion != claims.TokenVersion {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Data akun berubah, silakan login ulang", "token_version_mismatch", nil)
+		return
+	}
+
+	var perms []string
+	for _, p := range user.Role.Permissions {
+		perms = append(perms, p.Name)
+	}
+	studentID, lecturerID := profileIDs(s.userRepo, user)
+
 	newAccessToken, err := utils.GenerateToken(
-		claims.UserID,
-		claims.StudentID,
-		claims.Role,
-		claims.Permissions,
+		user.ID,
+		studentID,
+		lecturerID,
+		user.Role.Name,
+		perms,
+		user.MustChangePassword,
+		stored.FamilyID,
+		user.TokenVersion,
+	)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat token baru", err.Error(), nil)
+		return
+	}
+
+	// Rotasi: token lama dicabut, token baru masuk family yang sama.
+	newRefreshToken, info, err := utils.GenerateRefreshToken(
+		user.ID,
+		studentID,
+		lecturerID,
+		user.Role.Name,
+		perms,
+		user.MustChangePassword,
+		claims.RememberMe,
+		stored.FamilyID,
+		user.TokenVersion,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal membuat token baru", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat refresh token baru", err.Error(), nil)
+		return
+	}
+	err = s.refreshRepo.Rotate(claims.ID, &model.RefreshToken{
+		UserID:    stored.UserID,
+		JTI:       info.JTI,
+		FamilyID:  stored.FamilyID,
+		ExpiresAt: info.ExpiresAt,
+		UserAgent: truncate(ctx.Request.UserAgent(), maxLoginEventUserAgent),
+		IP:        ctx.ClientIP(),
+	})
+	if errors.Is(err, repository.ErrRefreshTokenReused) {
+		// refresh bersamaan dengan token yang sama: diperlakukan sebagai pemakaian ulang
+		s.revokeRefreshFamily(stored.FamilyID)
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Refresh token sudah pernah dipakai, silakan login ulang", "refresh_token_reused", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan refresh token baru", err.Error(), nil)
 		return
 	}
 
 	data := map[string]any{
 		"token":        newAccessToken,
-		"refreshToken": input.RefreshToken,
+		"refreshToken": newRefreshToken,
 	}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Token berhasil diperbarui", data))
+	utils.RespondOK(ctx,
+		"Token berhasil diperbarui", data)
+}
+
+// revokeRefreshFamily mencabut seluruh family refresh token (deteksi pemakaian ulang).
+func (s *authService) revokeRefreshFamily(familyID uuid.UUID) {
+	if err := s.refreshRepo.RevokeFamily(familyID); err != nil {
+		log.Printf("⚠️  gagal mencabut family refresh token %s: %v", familyID, err)
+	}
 }
 
-// Logout mengembalikan respon sukses (JWT tetap stateless).
+// Logout mencabut access token yang sedang dipakai (jti masuk denylist sampai token
+// kedaluwarsa). Body opsional { "refreshToken": "..." } ikut mencabut family refresh token-nya.
 func (s *authService) Logout(ctx *gin.Context) {
-	// Implementasi stateless: client cukup menghapus token dari storage.
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Logout berhasil, silakan hapus token di sisi client", nil))
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	var input struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&input); err != nil {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Input logout tidak valid", err.Error(), nil)
+			return
+		}
+	}
+
+	// Token lama tanpa jti tidak bisa dicabut; tetap kedaluwarsa sesuai exp.
+	if jti := ctx.GetString("tokenID"); jti != "" {
+		expiresAt := ctx.GetTime("tokenExpiresAt")
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(utils.AccessTokenTTL())
+		}
+		if err := s.revokedRepo.Revoke(jti, userID, expiresAt); err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mencabut token", err.Error(), nil)
+			return
+		}
+	}
+
+	if input.RefreshToken != "" {
+		if claims, err := utils.ValidateRefreshToken(input.RefreshToken); err == nil && claims.UserID == userID {
+			if stored, err := s.refreshRepo.FindByJTI(claims.ID); err == nil {
+				s.revokeRefreshFamily(stored.FamilyID)
+			}
+		}
+	}
+
+	utils.RespondOK(ctx,
+		"Logout berhasil", nil)
 }
 
 // GetProfile mengembalikan profil user berdasarkan klaim JWT.
 func (s *authService) GetProfile(ctx *gin.Context) {
 	v, ok := ctx.Get("userID")
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("User belum terautentikasi", "no_user_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
 		return
 	}
 	userID, ok := v.(uuid.UUID)
 	if !ok || userID == uuid.Nil {
-		ctx.JSON(http.StatusUnauthorized,
-			utils.BuildResponseFailed("Klaim token tidak valid", "invalid_user_id", nil))
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Klaim token tidak valid", "invalid_user_id", nil)
 		return
 	}
 
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound,
-			utils.BuildResponseFailed("User tidak ditemukan", err.Error(), nil))
+		utils.RespondError(ctx, http.StatusNotFound,
+			"User tidak ditemukan", err.Error(), nil)
 		return
 	}
 
+	utils.RespondOK(ctx,
+		"Berhasil mengambil profil", s.profileData(ctx, user))
+}
+
+// profileData menyusun response profil (GetProfile & UpdateProfile).
+func (s *authService) profileData(ctx *gin.Context, user *model.User) map[string]any {
+	// Profil mahasiswa tetap ditampilkan untuk akun tertaut (role dosen_wali, read-only).
 	var studentProfile any
-	if user.Role.Name == "mahasiswa" {
-		if sp, err := s.userRepo.FindStudentByUserID(user.ID); err == nil && sp != nil {
-			studentProfile = map[string]any{
-				"id":           sp.ID,
-				"studentId":    sp.StudentID,
-				"programStudy": sp.ProgramStudy,
-				"academicYear": sp.AcademicYear,
+	if sp, err := s.userRepo.FindStudentByUserID(user.ID); err == nil && sp != nil {
+		studentProfile = map[string]any{
+			"id":           sp.ID,
+			"studentId":    sp.StudentID,
+			"programStudy": sp.ProgramStudy,
+			"academicYear": sp.AcademicYear,
+			"phoneNumber":  sp.PhoneNumber,
+			"readOnly":     user.Role.Name != "mahasiswa",
+		}
+	}
+
+	var lecturerProfile any
+	switch user.Role.Name {
+	case "dosen_wali":
+		// Dosen wali: data dosen + jumlah mahasiswa bimbingan, agar frontend tidak perlu request kedua.
+		if lp, err := s.lecturerRepo.FindByUserID(user.ID); err == nil && lp != nil {
+			profile := map[string]any{
+				"id":         lp.ID,
+				"lecturerId": lp.LecturerID,
+				"department": lp.Department,
+			}
+			if ids, err := s.lecturerRepo.GetAdviseeStudentIDs(ctx.Request.Context(), lp.ID); err == nil {
+				profile["adviseeCount"] = len(ids)
+			}
+			lecturerProfile = profile
+		}
+	case "mahasiswa":
+		// mahasiswa tidak punya profil dosen
+	default:
+		if lp, err := s.userRepo.FindLecturerByUserID(user.ID); err == nil && lp != nil {
+			lecturerProfile = map[string]any{
+				"id":         lp.ID,
+				"lecturerId": lp.LecturerID,
+				"department": lp.Department,
+			}
+		}
+	}
+
+	var perms []string
+	for _, p := range user.Role.Permissions {
+		perms = append(perms, p.Name)
+	}
+
+	data := map[string]any{
+		"id":              user.ID,
+		"username":        user.Username,
+		"email":           user.Email,
+		"fullName":        user.FullName,
+		"role":            user.Role.Name,
+		"permissions":     perms,
+		"studentProfile":  studentProfile,
+		"lecturerProfile": lecturerProfile,
+		"impersonation":   nil,
+	}
+
+	// Token impersonasi: UI menampilkan banner "sedang login sebagai ...".
+	if adminID, ok := utils.ImpersonatorID(ctx); ok {
+		data["impersonation"] = map[string]any{
+			"active":         true,
+			"impersonatedBy": adminID,
+		}
+	}
+	return data
+}
+
+// UpdateProfile mengubah profil user yang sedang login.
+// Body (semua opsional): { "fullName", "email", "phoneNumber" (khusus mahasiswa, "" = hapus) }.
+// Role, permission, NIM dan dosen wali tidak bisa diubah lewat endpoint ini (field
+// tidak dikenal ditolak saat STRICT_JSON aktif). Response sama dengan GetProfile.
+func (s *authService) UpdateProfile(ctx *gin.Context) {
+	v, _ := ctx.Get("userID")
+	userID, ok := v.(uuid.UUID)
+	if !ok || userID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	var input struct {
+		FullName    *string `json:"fullName" binding:"omitempty,min=1,max=255"`
+		Email       *string `json:"email" binding:"omitempty,email,max=255"`
+		PhoneNumber *string `json:"phoneNumber" binding:"omitempty,max=20"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input profil tidak valid", err.Error(), nil)
+		return
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"User tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	update := repository.ProfileUpdate{}
+	if input.FullName != nil {
+		name := strings.TrimSpace(*input.FullName)
+		if name == "" {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Nama lengkap tidak boleh kosong", "invalid_full_name", nil)
+			return
+		}
+		update.FullName = &name
+	}
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		if !strings.EqualFold(email, user.Email) {
+			if other, err := s.userRepo.FindByEmail(email); err == nil && other.ID != user.ID {
+				utils.RespondError(ctx, http.StatusConflict,
+					"Email sudah dipakai akun lain", "duplicate",
+					map[string]string{"email": "Email sudah terdaftar"})
+				return
 			}
 		}
+		update.Email = &email
+	}
+	if input.PhoneNumber != nil {
+		if user.Role.Name != "mahasiswa" {
+			utils.RespondError(ctx, http.StatusBadRequest,
+				"Nomor telepon hanya untuk profil mahasiswa", "phone_not_supported", nil)
+			return
+		}
+		phone := strings.TrimSpace(*input.PhoneNumber)
+		update.PhoneNumber = &phone
+	}
+
+	if err := s.userRepo.UpdateProfile(user.ID, update); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			utils.RespondError(ctx, http.StatusConflict,
+				"Email sudah dipakai akun lain", "duplicate",
+				map[string]string{"email": "Email sudah terdaftar"})
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memperbarui profil", err.Error(), nil)
+		return
+	}
+
+	user, err = s.userRepo.FindByID(userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil profil", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Profil berhasil diperbarui", s.profileData(ctx, user))
+}
+
+// ChangePassword mengganti password user yang sedang login.
+// Dipakai juga untuk akun yang ditandai must_change_password: setelah berhasil,
+// flag dihapus dan token baru (tanpa flag) dikembalikan.
+func (s *authService) ChangePassword(ctx *gin.Context) {
+	v, _ := ctx.Get("userID")
+	userID, ok := v.(uuid.UUID)
+	if !ok || userID == uuid.Nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	var input struct {
+		OldPassword string `json:"oldPassword" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input ganti password tidak valid", err.Error(), nil)
+		return
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"User tidak ditemukan", err.Error(), nil)
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.OldPassword)) != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Password lama salah", "invalid_old_password", nil)
+		return
+	}
+
+	if input.NewPassword == input.OldPassword {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Password baru harus berbeda dari password lama", "same_password", nil)
+		return
+	}
+
+	if failed := utils.ValidatePassword(input.NewPassword, user.Username, user.Email); len(failed) > 0 {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Password baru tidak memenuhi kebijakan", "weak_password",
+			utils.PasswordPolicyDetails(failed))
+		return
 	}
 
+	reused, err := s.passwordRecentlyUsed(user, input.NewPassword)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memeriksa riwayat password", err.Error(), nil)
+		return
+	}
+	if reused {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Password baru pernah dipakai sebelumnya", "password_recently_used",
+			map[string]any{"historyDepth": repository.PasswordHistoryDepth})
+		return
+	}
+
+	hash, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memproses password baru", err.Error(), nil)
+		return
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, hash); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan password baru", err.Error(), nil)
+		return
+	}
+	middleware.ForgetTokenVersion(user.ID)
+
+	// UpdatePassword menaikkan token_version: semua token lama (termasuk milik sesi ini)
+	// ditolak, sehingga sesi ini diberi access & refresh token baru dengan versi baru.
 	var perms []string
 	for _, p := range user.Role.Permissions {
 		perms = append(perms, p.Name)
 	}
 
+	studentID, lecturerID := profileIDs(s.userRepo, user)
+	tokenVersion := user.TokenVersion + 1
+
+	sessionID, _ := getUUIDFromContext(ctx, "sessionID")
+	if sessionID == uuid.Nil {
+		sessionID = uuid.New() // token lama tanpa sesi: buka sesi baru
+	}
+	token, err := utils.GenerateToken(user.ID, studentID, lecturerID, user.Role.Name, perms, false, sessionID, tokenVersion)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat token", err.Error(), nil)
+		return
+	}
+
+	refreshToken, info, err := utils.GenerateRefreshToken(
+		user.ID, studentID, lecturerID, user.Role.Name, perms, false, false, sessionID, tokenVersion)
+	if err == nil {
+		err = s.refreshRepo.Create(&model.RefreshToken{
+			UserID:    user.ID,
+			JTI:       info.JTI,
+			FamilyID:  sessionID,
+			ExpiresAt: info.ExpiresAt,
+			UserAgent: truncate(ctx.Request.UserAgent(), maxLoginEventUserAgent),
+			IP:        ctx.ClientIP(),
+		})
+	}
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat refresh token", err.Error(), nil)
+		return
+	}
+
+	// Sesi lain sudah tidak bisa refresh (versi token lama); dicabut agar tidak tampil sebagai aktif.
+	if _, err := s.refreshRepo.RevokeOtherSessions(user.ID, sessionID); err != nil {
+		log.Printf("⚠️  gagal mencabut sesi lain user %s: %v", user.ID, err)
+	}
+
 	data := map[string]any{
-		"id":             user.ID,
-		"username":       user.Username,
-		"email":          user.Email,
-		"fullName":       user.FullName,
-		"role":           user.Role.Name,
-		"permissions":    perms,
-		"studentProfile": studentProfile,
+		"token":        token,
+		"refreshToken": refreshToken,
+	}
+
+	utils.RespondOK(ctx,
+		"Password berhasil diganti", data)
+}
+
+// profileIDs mengembalikan students.id & lecturers.id milik user (uuid.Nil jika tidak ada).
+// Profil mahasiswa tetap dibawa walau role user sudah berganti menjadi dosen_wali,
+// agar riwayat prestasinya sebagai mahasiswa tetap bisa diakses (read-only).
+func profileIDs(userRepo repository.UserRepository, user *model.User) (uuid.UUID, uuid.UUID) {
+	var studentID, lecturerID uuid.UUID
+	if stu, err := userRepo.FindStudentByUserID(user.ID); err == nil && stu != nil {
+		studentID = stu.ID
+	}
+	if user.Role.Name != "mahasiswa" {
+		if lect, err := userRepo.FindLecturerByUserID(user.ID); err == nil && lect != nil {
+			lecturerID = lect.ID
+		}
+	}
+	return studentID, lecturerID
+}
+
+// passwordRecentlyUsed: true jika candidate cocok dengan password saat ini atau salah satu
+// dari PasswordHistoryDepth password terakhir user.
+func (s *authService) passwordRecentlyUsed(user *model.User, candidate string) (bool, error) {
+	hashes, err := s.userRepo.RecentPasswordHashes(user.ID, repository.PasswordHistoryDepth)
+	if err != nil {
+		return false, err
 	}
+	// Akun lama belum punya riwayat: password saat ini tetap dihitung.
+	hashes = append(hashes, user.PasswordHash)
+	for _, h := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(h), []byte(candidate)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Berhasil mengambil profil", data))
+// upgradePasswordHash meng-hash ulang password dengan BCRYPT_COST saat ini jika hash
+// tersimpan memakai cost lebih rendah. Kegagalan hanya dicatat; login tetap berjalan.
+func (s *authService) upgradePasswordHash(user *model.User, password string) {
+	if !utils.PasswordNeedsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := utils.HashPassword(password)
+	if err == nil {
+		err = s.userRepo.UpgradePasswordHash(user.ID, user.PasswordHash, hash)
+	}
+	if err != nil {
+		log.Printf("⚠️  gagal meng-upgrade hash password user %s: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hash
 }
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authRouter merangkai endpoint auth yang dites dengan AuthMiddleware asli, plus 1 endpoint
+// terproteksi biasa untuk mengecek gate must-change-password.
+func (f *authFixture) authRouter() *gin.Engine {
+	r := gin.New()
+	g := r.Group("/api/v1/auth")
+	g.POST("/login", f.svc.Login)
+	g.POST("/refresh", f.svc.RefreshToken)
+	g.POST("/change-password", middleware.AuthMiddleware(), f.svc.ChangePassword)
+	r.GET("/api/v1/achievements", middleware.AuthMiddleware(), func(c *gin.Context) {
+		utils.RespondOK(c, "ok", nil)
+	})
+	return r
+}
+
+// assertRefreshToken memastikan token adalah refresh token asli (bukan access token).
+func assertRefreshToken(t *testing.T, access, refresh string) {
+	t.Helper()
+	if refresh == "" || refresh == access {
+		t.Fatalf("refreshToken sama dengan access token / kosong")
+	}
+	if _, err := utils.ValidateRefreshToken(refresh); err != nil {
+		t.Fatalf("refreshToken ditolak ValidateRefreshToken: %v", err)
+	}
+	if _, err := utils.ValidateToken(refresh); err == nil {
+		t.Fatal("refreshToken diterima sebagai access token")
+	}
+}
+
+func TestDefaultCredentialLoginMustChangePassword(t *testing.T) {
+	user := newTestUser(t, "mahasiswa1", "123123", "mahasiswa")
+	user.MustChangePassword = true // FORCE_DEFAULT_PASSWORD_CHANGE pada akun seed
+	f := newAuthFixture(t, user)
+	r := f.authRouter()
+
+	access, _ := f.login(t, "mahasiswa1", "123123")
+
+	w, _ := doJSON(t, r, http.MethodGet, "/api/v1/achievements", access, nil)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("endpoint lain sebelum ganti password: status %d, want 403", w.Code)
+	}
+
+	w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/change-password", access,
+		map[string]any{"oldPassword": "123123", "newPassword": "Kuat#Sekali2026"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("change-password: status %d, body %s", w.Code, w.Body)
+	}
+	newAccess, _ := data["token"].(string)
+	newRefresh, _ := data["refreshToken"].(string)
+	assertRefreshToken(t, newAccess, newRefresh)
+
+	claims, err := utils.ValidateRefreshToken(newRefresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.MustChangePassword || claims.TokenVersion != 1 {
+		t.Fatalf("klaim refresh token baru: mustChange=%v tv=%d, want false & 1", claims.MustChangePassword, claims.TokenVersion)
+	}
+	if _, err := f.refresh.FindByJTI(claims.ID); err != nil {
+		t.Fatalf("jti refresh token baru tidak tersimpan: %v", err)
+	}
+
+	w, _ = doJSON(t, r, http.MethodGet, "/api/v1/achievements", newAccess, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("endpoint lain setelah ganti password: status %d, want 200", w.Code)
+	}
+
+	// Refresh token hasil ganti password bisa dipakai untuk refresh.
+	w, _ = doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": newRefresh})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh dengan token hasil ganti password: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestRefreshTokenIsDistinctFromAccessToken(t *testing.T) {
+	user := newTestUser(t, "doswal", "Rahasia#2026", "dosen_wali")
+	f := newAuthFixture(t, user)
+	r := f.authRouter()
+
+	access, refresh := f.login(t, "doswal", "Rahasia#2026")
+	assertRefreshToken(t, access, refresh)
+
+	w, _ := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": access})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("access token di /refresh: status %d, want 401", w.Code)
+	}
+
+	w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d, body %s", w.Code, w.Body)
+	}
+	newAccess, _ := data["token"].(string)
+	rotated, _ := data["refreshToken"].(string)
+	assertRefreshToken(t, newAccess, rotated)
+	if rotated == refresh {
+		t.Fatal("refresh token tidak dirotasi")
+	}
+
+	// Token lama yang sudah dirotasi dipakai lagi → family dicabut, token hasil rotasi ikut mati.
+	w, _ = doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("pemakaian ulang: status %d, want 401", w.Code)
+	}
+	w, _ = doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": rotated})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("token hasil rotasi setelah family dicabut: status %d, want 401", w.Code)
+	}
+}
+
+func TestRefreshReloadsUserFromDatabase(t *testing.T) {
+	tests := []struct {
+		name       string
+		change     func(u *model.User)
+		wantStatus int
+		wantCode   string
+		wantPerms  []string
+	}{
+		{name: "permission role berubah ikut ke token baru", wantStatus: http.StatusOK, wantPerms: []string{"achievements:read", "reports:read"},
+			change: func(u *model.User) {
+				u.Role.Permissions = []model.Permission{{Name: "achievements:read"}, {Name: "reports:read"}}
+			}},
+		{name: "akun dinonaktifkan", wantStatus: http.StatusUnauthorized, wantCode: "account_inactive",
+			change: func(u *model.User) { u.IsActive = false }},
+		{name: "token version naik", wantStatus: http.StatusUnauthorized, wantCode: "token_version_mismatch",
+			change: func(u *model.User) { u.TokenVersion++ }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t, "dosen", "Rahasia#2026", "dosen_wali")
+			user.Role.Permissions = []model.Permission{{Name: "achievements:read"}}
+			f := newAuthFixture(t, user)
+			r := f.authRouter()
+			_, refresh := f.login(t, "dosen", "Rahasia#2026")
+
+			f.users.update(user.ID, tt.change)
+
+			w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" && !strings.Contains(w.Body.String(), tt.wantCode) {
+				t.Fatalf("body %s tidak memuat %q", w.Body, tt.wantCode)
+			}
+			if tt.wantPerms == nil {
+				return
+			}
+			access, _ := data["token"].(string)
+			claims, err := utils.ValidateToken(access)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(claims.Permissions, tt.wantPerms) {
+				t.Fatalf("permissions %v, want %v", claims.Permissions, tt.wantPerms)
+			}
+		})
+	}
+}
+
+// TestLoginRememberMeRefreshTTL: rememberMe di body login memilih JWT_REMEMBER_ME_REFRESH_TTL
+// untuk refresh token, dan pilihan itu tetap terbawa setelah rotasi di /refresh.
+func TestLoginRememberMeRefreshTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		rememberMe bool
+		wantTTL    time.Duration
+	}{
+		{name: "login biasa", rememberMe: false, wantTTL: 8 * time.Hour},
+		{name: "ingat saya", rememberMe: true, wantTTL: 720 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t, "mhs", "Rahasia#2026", "mahasiswa")
+			f := newAuthFixture(t, user)
+			t.Setenv("JWT_ACCESS_TTL", "15m")
+			t.Setenv("JWT_REFRESH_TTL", "8h")
+			t.Setenv("JWT_REMEMBER_ME_REFRESH_TTL", "720h")
+			r := f.authRouter()
+
+			checkRefresh := func(step, token string) {
+				t.Helper()
+				claims, err := utils.ValidateRefreshToken(token)
+				if err != nil {
+					t.Fatalf("%s: %v", step, err)
+				}
+				if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != tt.wantTTL {
+					t.Fatalf("%s: refresh token berlaku %s, want %s", step, got, tt.wantTTL)
+				}
+				stored, err := f.refresh.FindByJTI(claims.ID)
+				if err != nil {
+					t.Fatalf("%s: refresh token tidak tersimpan: %v", step, err)
+				}
+				if got := time.Until(stored.ExpiresAt); got < tt.wantTTL-time.Minute || got > tt.wantTTL {
+					t.Fatalf("%s: expires_at tersimpan %s lagi, want ~%s", step, got, tt.wantTTL)
+				}
+			}
+
+			w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/login", "",
+				map[string]any{"username": "mhs", "password": "Rahasia#2026", "rememberMe": tt.rememberMe})
+			if w.Code != http.StatusOK {
+				t.Fatalf("login: status %d, body %s", w.Code, w.Body)
+			}
+			access, _ := data["token"].(string)
+			claims, err := utils.ValidateToken(access)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != 15*time.Minute {
+				t.Fatalf("access token berlaku %s, want 15m (tidak terpengaruh rememberMe)", got)
+			}
+			refresh, _ := data["refreshToken"].(string)
+			checkRefresh("login", refresh)
+
+			w, data = doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+			if w.Code != http.StatusOK {
+				t.Fatalf("refresh: status %d, body %s", w.Code, w.Body)
+			}
+			rotated, _ := data["refreshToken"].(string)
+			checkRefresh("rotasi", rotated)
+		})
+	}
+}
+
+// TestRefreshRejectsNonRefreshTokens: /refresh hanya menerima tokenType "refresh"; access token,
+// token lama tanpa tokenType, dan token 2FA ditolak 401 invalid_token_type tanpa menerbitkan token.
+func TestRefreshRejectsNonRefreshTokens(t *testing.T) {
+	user := newTestUser(t, "mhs", "Rahasia#2026", "mahasiswa")
+	f := newAuthFixture(t, user)
+	r := f.authRouter()
+
+	access, _ := f.login(t, "mhs", "Rahasia#2026")
+	mfa, _, err := utils.GenerateMFAToken(user.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.JWTCustomClaims{
+		UserID: user.ID,
+		Role:   "mahasiswa",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    utils.JWTIssuer(),
+			Audience:  jwt.ClaimStrings{utils.JWTAudience()},
+			ID:        uuid.NewString(),
+		},
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "access token", token: access},
+		{name: "token lama tanpa tokenType", token: legacy},
+		{name: "token 2FA", token: mfa},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": tt.token})
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status %d, want 401, body %s", w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), `"errors":"invalid_token_type"`) {
+				t.Fatalf("body %s, want errors invalid_token_type", w.Body)
+			}
+			if data["token"] != nil || data["refreshToken"] != nil {
+				t.Fatal("token baru diterbitkan untuk token yang bukan refresh token")
+			}
+		})
+	}
+}
+
+// TestLoginUpgradesWeakPasswordHash: hash cost 10 di-upgrade ke BCRYPT_COST saat login pertama,
+// login berikutnya tetap berhasil, dan hash yang sudah kuat tidak di-hash ulang.
+func TestLoginUpgradesWeakPasswordHash(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "10")
+	user := newTestUser(t, "doswal", "Rahasia#2026", "dosen_wali") // hash cost 10 (seed lama)
+	f := newAuthFixture(t, user)
+	t.Setenv("BCRYPT_COST", "11")
+
+	storedHash := func() string {
+		u, err := f.users.FindByID(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.PasswordHash
+	}
+	cost := func(hash string) int {
+		c, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if got := cost(storedHash()); got != 10 {
+		t.Fatalf("cost awal %d, want 10", got)
+	}
+	f.login(t, "doswal", "Rahasia#2026")
+	upgraded := storedHash()
+	if got := cost(upgraded); got != 11 {
+		t.Fatalf("cost setelah login pertama %d, want 11", got)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(upgraded), []byte("Rahasia#2026")); err != nil {
+		t.Fatalf("hash baru tidak cocok dengan password: %v", err)
+	}
+	if u, _ := f.users.FindByID(user.ID); u.TokenVersion != user.TokenVersion {
+		t.Fatal("upgrade hash menaikkan token_version (sesi lain ikut logout)")
+	}
+
+	f.login(t, "doswal", "Rahasia#2026")
+	if storedHash() != upgraded {
+		t.Fatal("hash yang sudah memakai cost terbaru di-hash ulang")
+	}
+
+	r := f.authRouter()
+	w, _ := doJSON(t, r, http.MethodPost, "/api/v1/auth/login", "",
+		map[string]any{"username": "doswal", "password": "salah-password1"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("password salah setelah upgrade: status %d, want 401", w.Code)
+	}
+}
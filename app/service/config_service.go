@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ConfigService meng-handle endpoint admin untuk setting runtime (non-rahasia).
+type ConfigService interface {
+	GetRuntimeConfig(ctx *gin.Context)    // GET  /api/v1/admin/config
+	ReloadRuntimeConfig(ctx *gin.Context) // POST /api/v1/admin/config/reload
+}
+
+// ConfigReloader me-reload setting runtime dari .env/env dan mencatat perubahannya
+// ke audit log. Dipakai endpoint admin dan handler SIGHUP di main.
+type ConfigReloader struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewConfigReloader membuat instance ConfigReloader.
+func NewConfigReloader(auditRepo repository.AuditRepository) *ConfigReloader {
+	return &ConfigReloader{auditRepo: auditRepo}
+}
+
+// Reload menerapkan konfigurasi baru; actorID nil berarti dipicu sinyal (SIGHUP).
+// Jika validasi gagal, konfigurasi lama tetap berlaku dan error dikembalikan.
+func (c *ConfigReloader) Reload(ctx context.Context, actorID *uuid.UUID, source string) (map[string]utils.ConfigChange, error) {
+	diff, err := utils.ReloadRuntimeConfig()
+	if err != nil {
+		return nil, err
+	}
+	_ = c.auditRepo.Record(ctx, actorID, "config.reload", "config", "runtime", map[string]any{
+		"source":  source,
+		"changes": diff,
+	})
+	if len(diff) > 0 {
+		log.Printf("🔧 config runtime di-reload (%s): %d setting berubah", source, len(diff))
+	}
+	return diff, nil
+}
+
+// ===============================================================
+//  GET /api/v1/admin/config
+//  Admin: setting runtime yang sedang berlaku + daftar env yang bisa di-reload.
+// ===============================================================
+func (c *ConfigReloader) GetRuntimeConfig(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil konfigurasi runtime", gin.H{
+			"config":        utils.Runtime(),
+			"reloadableEnv": utils.RuntimeConfigKeys(),
+		}))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/config/reload
+//  Admin: baca ulang .env/env, validasi, lalu terapkan tanpa restart.
+// ===============================================================
+func (c *ConfigReloader) ReloadRuntimeConfig(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	actorID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized,
+			utils.BuildResponseFailed("Autentikasi admin diperlukan", "no_user_id", nil))
+		return
+	}
+
+	diff, err := c.Reload(ctx, &actorID, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity,
+			utils.BuildResponseFailed("Konfigurasi baru tidak valid, konfigurasi lama tetap berlaku", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Konfigurasi runtime di-reload", gin.H{
+			"changes": diff,
+			"config":  utils.Runtime(),
+		}))
+}
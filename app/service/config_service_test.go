@@ -0,0 +1,100 @@
+package service
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeDraftCountRepo: pembuatan draft dengan jumlah draft mahasiswa yang bisa diatur test.
+type fakeDraftCountRepo struct {
+	*fakeCreateRepo
+	drafts int64
+}
+
+func (r *fakeDraftCountRepo) CountDraftsByStudent(uuid.UUID) (int64, error) { return r.drafts, nil }
+
+// TestReloadRuntimeConfigAppliesWithoutRestart: MAX_DRAFTS_PER_STUDENT yang diubah di .env
+// lalu di-reload lewat endpoint admin langsung berlaku untuk service yang sudah berjalan;
+// reload dengan nilai tidak valid ditolak dan batas sebelumnya tetap berlaku.
+func TestReloadRuntimeConfigAppliesWithoutRestart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setRuntimeEnv(t, map[string]string{"MAX_DRAFTS_PER_STUDENT": "5", "ADVISOR_CACHE_TTL": "30s"})
+	t.Chdir(t.TempDir()) // ReloadRuntimeConfig membaca .env di working directory
+	writeEnv := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(".env", []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := &fakeDraftCountRepo{fakeCreateRepo: &fakeCreateRepo{}, drafts: 3}
+	types := &fakeTypeRepo{types: []model.AchievementType{{Code: "seminar", Label: "Seminar", Active: true}}}
+	achievements := NewAchievementService(repo, nil, nil, nil, nil, nil, nil, fakeNoRuleRepo{}, types, nil, nil, nil)
+	audit := &fakeAuditRepo{}
+	config := NewConfigReloader(audit)
+
+	r := gin.New()
+	r.POST("/achievements", func(c *gin.Context) {
+		c.Set("role", "mahasiswa")
+		c.Set("userID", uuid.New())
+		c.Set("studentID", uuid.New())
+	}, achievements.CreateAchievement)
+	admin := r.Group("/admin", func(c *gin.Context) { c.Set("role", "admin"); c.Set("userID", uuid.New()) })
+	admin.GET("/config", config.GetRuntimeConfig)
+	admin.POST("/config/reload", config.ReloadRuntimeConfig)
+
+	createDraft := func() int {
+		w, _ := doJSON(t, r, http.MethodPost, "/achievements", "", map[string]any{
+			"achievementType": "seminar", "title": "Pemakalah seminar nasional",
+		})
+		if w.Code == http.StatusConflict && !strings.Contains(w.Body.String(), `"errors":"too_many_drafts"`) {
+			t.Fatalf("409 bukan too_many_drafts: %s", w.Body)
+		}
+		return w.Code
+	}
+
+	if code := createDraft(); code != http.StatusCreated {
+		t.Fatalf("3 draft dengan batas 5: status %d, want 201", code)
+	}
+
+	// Batas diturunkan di .env lalu di-reload tanpa restart.
+	writeEnv("MAX_DRAFTS_PER_STUDENT=3\n")
+	w, data := doJSON(t, r, http.MethodPost, "/admin/config/reload", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reload: status %d, body %s", w.Code, w.Body)
+	}
+	changes, _ := data["changes"].(map[string]any)
+	change, _ := changes["maxDraftsPerStudent"].(map[string]any)
+	if len(changes) != 1 || change["old"] != float64(5) || change["new"] != float64(3) {
+		t.Fatalf("changes %v, want maxDraftsPerStudent 5 → 3", changes)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != "config.reload" {
+		t.Fatalf("audit %v, want [config.reload]", audit.actions)
+	}
+	if code := createDraft(); code != http.StatusConflict {
+		t.Fatalf("3 draft dengan batas 3 setelah reload: status %d, want 409", code)
+	}
+	_, data = doJSON(t, r, http.MethodGet, "/admin/config", "", nil)
+	if current, _ := data["config"].(map[string]any); current["maxDraftsPerStudent"] != float64(3) {
+		t.Fatalf("config berlaku %v, want maxDraftsPerStudent 3", data["config"])
+	}
+
+	// Nilai tidak valid: reload ditolak, batas hasil reload sebelumnya tetap berlaku.
+	writeEnv("MAX_DRAFTS_PER_STUDENT=10\nADVISOR_CACHE_TTL=-1s\n")
+	if w, _ := doJSON(t, r, http.MethodPost, "/admin/config/reload", "", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reload tidak valid: status %d, want 422", w.Code)
+	}
+	if code := createDraft(); code != http.StatusConflict {
+		t.Fatalf("batas berubah walau reload ditolak: status %d, want 409", code)
+	}
+	if len(audit.actions) != 1 {
+		t.Fatalf("reload gagal tetap diaudit: %v", audit.actions)
+	}
+}
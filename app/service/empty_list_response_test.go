@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeNoAchievementRepo: mahasiswa belum punya prestasi (FindByStudentID mengembalikan nil).
+type fakeNoAchievementRepo struct {
+	*fakeListRepo
+}
+
+func (fakeNoAchievementRepo) FindByStudentID(context.Context, string) ([]model.AchievementReference, error) {
+	return nil, nil
+}
+
+// TestEmptyListsRespondWithArray: list kosong dikirim sebagai [] (bukan null) di semua cabang
+// GET /achievements dan GET /students/:id/achievements.
+func TestEmptyListsRespondWithArray(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeListRepo{docs: map[string]*model.Achievement{}}
+	lecturers := &fakeListLecturerRepo{students: []uuid.UUID{uuid.New()}, list: repo}
+	achievements := NewAchievementService(repo, nil, nil, lecturers, nil, fakeListDelegationRepo{}, nil, nil, nil, nil, nil, nil)
+	students := NewStudentService(nil, fakeNoAchievementRepo{repo}, nil, nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		role    string
+		target  string
+		handler gin.HandlerFunc
+		want    string
+	}{
+		{name: "mahasiswa", role: "mahasiswa", target: "/achievements",
+			handler: achievements.GetAchievements, want: `"data":[]`},
+		{name: "mahasiswa dengan pagination", role: "mahasiswa", target: "/achievements?page=1&limit=10",
+			handler: achievements.GetAchievements, want: `"items":[]`},
+		{name: "dosen wali", role: "dosen_wali", target: "/achievements",
+			handler: achievements.GetAchievements, want: `"items":[]`},
+		{name: "admin", role: "admin", target: "/achievements",
+			handler: achievements.GetAchievements, want: `"items":[]`},
+		{name: "prestasi mahasiswa (admin)", role: "admin", target: "/students/" + uuid.NewString() + "/achievements",
+			handler: students.GetStudentAchievements, want: `"data":[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			setIdentity := func(c *gin.Context) {
+				c.Set("role", tt.role)
+				c.Set("userID", uuid.New())
+				c.Set("studentID", uuid.New())
+				c.Set("lecturerID", uuid.New())
+			}
+			r.GET("/achievements", setIdentity, tt.handler)
+			r.GET("/students/:id/achievements", setIdentity, tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			checkEnvelope(t, w)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, tt.want) || strings.Contains(body, "null") {
+				t.Fatalf("body %s, want mengandung %s tanpa null", body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"student-achievement-backend/utils"
+)
+
+// checkEnvelope menggagalkan test jika respons JSON handler memakai pasangan
+// HTTP status & field status yang tidak cocok (mis. 200 dengan body gagal).
+func checkEnvelope(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if err := utils.CheckResponseEnvelope(w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===============================================================
+//  POST /api/v1/admin/users/:id/impersonate
+//  Admin: access token atas nama user target (klaim impersonatedBy = admin),
+//  berlaku IMPERSONATION_TOKEN_TTL (default 15 menit) dan tidak bisa di-refresh.
+//  Dipakai support untuk melihat persis apa yang dilihat user: token hanya boleh
+//  GET/HEAD (view-only, ditegakkan AuthMiddleware) kecuali logout. Setiap penerbitan
+//  dicatat di audit log ("user.impersonate"); entri audit lain yang ditulis dengan
+//  token ini menyimpan impersonated_by = admin.
+// ===============================================================
+func (s *adminService) ImpersonateUser(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized,
+			utils.BuildResponseFailed("Autentikasi admin diperlukan", "no_user_id", nil))
+		return
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+	if targetID == adminID {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Tidak bisa meng-impersonate diri sendiri", "self_impersonation", nil))
+		return
+	}
+
+	user, err := s.userRepo.FindByID(targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("User tidak ditemukan", "not_found", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil user", err.Error(), nil))
+		return
+	}
+	// Sesama admin tidak boleh: impersonasi hanya untuk melihat sudut pandang mahasiswa/dosen.
+	if user.Role.Name == "admin" {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Akun admin tidak bisa di-impersonate", "cannot_impersonate_admin", nil))
+		return
+	}
+
+	var perms []string
+	for _, p := range user.Role.Permissions {
+		perms = append(perms, p.Name)
+	}
+	studentID, lecturerID := profileIDs(s.userRepo, user)
+
+	token, jti, expiresAt, err := utils.GenerateImpersonationToken(
+		user.ID, studentID, lecturerID, user.Role.Name, perms, user.TokenVersion, adminID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal membuat token impersonasi", err.Error(), nil))
+		return
+	}
+
+	_ = s.auditRepo.Record(ctx, &adminID, "user.impersonate", "user", user.ID.String(), map[string]any{
+		"username":  user.Username,
+		"role":      user.Role.Name,
+		"tokenId":   jti,
+		"expiresAt": expiresAt,
+		"ip":        ctx.ClientIP(),
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Token impersonasi dibuat", gin.H{
+			"token":          token,
+			"expiresAt":      expiresAt,
+			"expiresIn":      int(time.Until(expiresAt).Seconds()),
+			"impersonatedBy": adminID,
+			"user": gin.H{
+				"id":       user.ID,
+				"username": user.Username,
+				"fullName": user.FullName,
+				"role":     user.Role.Name,
+			},
+		}))
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentLecturer mengambil data dosen milik user yang login (role dosen_wali).
+func (s *lecturerService) currentLecturer(ctx *gin.Context) (*model.Lecturer, bool) {
+	if role := ctx.GetString("role"); role != "dosen_wali" {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Hanya dosen wali yang dapat mengelola delegasi", "forbidden", nil))
+		return nil, false
+	}
+	userID, _ := getUUIDFromContext(ctx, "userID")
+	lect, err := s.lecturerRepo.FindByUserID(userID)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
+		return nil, false
+	}
+	return lect, true
+}
+
+// lecturerFromParam mengambil dosen dari path :id (override admin).
+func (s *lecturerService) lecturerFromParam(ctx *gin.Context) (*model.Lecturer, bool) {
+	if !ensureAdmin(ctx) {
+		return nil, false
+	}
+	lectID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID dosen tidak valid", err.Error(), nil))
+		return nil, false
+	}
+	lect, err := s.lecturerRepo.FindByID(lectID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Dosen tidak ditemukan", err.Error(), nil))
+		return nil, false
+	}
+	return lect, true
+}
+
+// listDelegations menampilkan delegasi yang diberikan / diterima dosen.
+func (s *lecturerService) listDelegations(ctx *gin.Context, lect *model.Lecturer) {
+	list, err := s.delegRepo.FindByLecturer(lect.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil data delegasi", err.Error(), nil))
+		return
+	}
+
+	now := time.Now()
+	out := make([]map[string]any, 0, len(list))
+	for _, d := range list {
+		out = append(out, map[string]any{
+			"id":             d.ID,
+			"fromLecturerId": d.FromLecturerID,
+			"toLecturerId":   d.ToLecturerID,
+			"startsAt":       d.StartsAt,
+			"endsAt":         d.EndsAt,
+			"createdBy":      d.CreatedBy,
+			"revokedAt":      d.RevokedAt,
+			"active":         d.RevokedAt == nil && !now.Before(d.StartsAt) && now.Before(d.EndsAt),
+		})
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil data delegasi", out))
+}
+
+// createDelegation memvalidasi & menyimpan delegasi dari lect ke dosen lain.
+// Aturan:
+// - tidak boleh ke diri sendiri
+// - endsAt > startsAt dan endsAt belum lewat
+// - tidak boleh berantai / overlap (lihat DelegationRepository.CountConflicts)
+func (s *lecturerService) createDelegation(ctx *gin.Context, from *model.Lecturer) {
+	var input struct {
+		ToLecturerID string    `json:"toLecturerId" binding:"required"`
+		StartsAt     time.Time `json:"startsAt" binding:"required"`
+		EndsAt       time.Time `json:"endsAt" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	toID, err := uuid.Parse(input.ToLecturerID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID dosen tujuan tidak valid", err.Error(), nil))
+		return
+	}
+	if toID == from.ID {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Delegasi tidak boleh ke diri sendiri", "self_delegation", nil))
+		return
+	}
+	if !input.EndsAt.After(input.StartsAt) || !input.EndsAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Rentang waktu delegasi tidak valid", "invalid_window", nil))
+		return
+	}
+	if _, err := s.lecturerRepo.FindByID(toID); err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Dosen tujuan tidak ditemukan", err.Error(), nil))
+		return
+	}
+
+	conflicts, err := s.delegRepo.CountConflicts(from.ID, toID, input.StartsAt, input.EndsAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memeriksa delegasi", err.Error(), nil))
+		return
+	}
+	if conflicts > 0 {
+		ctx.JSON(http.StatusConflict,
+			utils.BuildResponseFailed("Delegasi bentrok dengan delegasi lain (overlap atau berantai)", "delegation_conflict", nil))
+		return
+	}
+
+	userID, _ := getUUIDFromContext(ctx, "userID")
+	d := &model.VerificationDelegation{
+		FromLecturerID: from.ID,
+		ToLecturerID:   toID,
+		StartsAt:       input.StartsAt,
+		EndsAt:         input.EndsAt,
+		CreatedBy:      userID,
+	}
+	if err := s.delegRepo.Create(d); err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal membuat delegasi", err.Error(), nil))
+		return
+	}
+
+	_ = s.auditRepo.Record(ctx, &userID, "lecturer.delegation_create", "verification_delegation", d.ID.String(), d)
+
+	ctx.JSON(http.StatusCreated,
+		utils.BuildResponseSuccess("Delegasi verifikasi berhasil dibuat", d))
+}
+
+// revokeDelegation mencabut delegasi milik lect (sebagai pemberi).
+func (s *lecturerService) revokeDelegation(ctx *gin.Context, from *model.Lecturer) {
+	delegID, err := uuid.Parse(ctx.Param("delegationId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID delegasi tidak valid", err.Error(), nil))
+		return
+	}
+
+	d, err := s.delegRepo.FindByID(delegID)
+	if err != nil || d.FromLecturerID != from.ID {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Delegasi tidak ditemukan", "not_found", nil))
+		return
+	}
+
+	if err := s.delegRepo.Revoke(delegID); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Gagal mencabut delegasi", err.Error(), nil))
+		return
+	}
+
+	userID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &userID, "lecturer.delegation_revoke", "verification_delegation", delegID.String(), nil)
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Delegasi verifikasi berhasil dicabut", nil))
+}
+
+// ===============================================================
+//  GET/POST /api/v1/lecturers/me/delegations
+//  DELETE   /api/v1/lecturers/me/delegations/:delegationId
+//  Dosen wali mengelola delegasi verifikasinya sendiri.
+// ===============================================================
+func (s *lecturerService) GetMyDelegations(ctx *gin.Context) {
+	if lect, ok := s.currentLecturer(ctx); ok {
+		s.listDelegations(ctx, lect)
+	}
+}
+
+func (s *lecturerService) CreateMyDelegation(ctx *gin.Context) {
+	if lect, ok := s.currentLecturer(ctx); ok {
+		s.createDelegation(ctx, lect)
+	}
+}
+
+func (s *lecturerService) RevokeMyDelegation(ctx *gin.Context) {
+	if lect, ok := s.currentLecturer(ctx); ok {
+		s.revokeDelegation(ctx, lect)
+	}
+}
+
+// ===============================================================
+//  GET/POST /api/v1/lecturers/:id/delegations
+//  DELETE   /api/v1/lecturers/:id/delegations/:delegationId
+//  Override admin atas delegasi dosen tertentu.
+// ===============================================================
+func (s *lecturerService) GetLecturerDelegations(ctx *gin.Context) {
+	if lect, ok := s.lecturerFromParam(ctx); ok {
+		s.listDelegations(ctx, lect)
+	}
+}
+
+func (s *lecturerService) CreateLecturerDelegation(ctx *gin.Context) {
+	if lect, ok := s.lecturerFromParam(ctx); ok {
+		s.createDelegation(ctx, lect)
+	}
+}
+
+func (s *lecturerService) RevokeLecturerDelegation(ctx *gin.Context) {
+	if lect, ok := s.lecturerFromParam(ctx); ok {
+		s.revokeDelegation(ctx, lect)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeDelegationRepo menyimpan delegasi di memori dengan aturan aktif/bentrok yang sama
+// seperti query repository asli; advisors memetakan mahasiswa → dosen wali.
+type fakeDelegationRepo struct {
+	repository.DelegationRepository
+	list     []*model.VerificationDelegation
+	advisors map[uuid.UUID]uuid.UUID
+}
+
+func (r *fakeDelegationRepo) Create(d *model.VerificationDelegation) error {
+	d.ID = uuid.New()
+	r.list = append(r.list, d)
+	return nil
+}
+
+func (r *fakeDelegationRepo) FindByID(id uuid.UUID) (*model.VerificationDelegation, error) {
+	for _, d := range r.list {
+		if d.ID == id {
+			cp := *d
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeDelegationRepo) FindByLecturer(lecturerID uuid.UUID) ([]model.VerificationDelegation, error) {
+	out := []model.VerificationDelegation{}
+	for _, d := range r.list {
+		if d.FromLecturerID == lecturerID || d.ToLecturerID == lecturerID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDelegationRepo) Revoke(id uuid.UUID) error {
+	for _, d := range r.list {
+		if d.ID == id && d.RevokedAt == nil {
+			now := time.Now()
+			d.RevokedAt = &now
+			return nil
+		}
+	}
+	return errors.New("delegation not found or already revoked")
+}
+
+func (r *fakeDelegationRepo) CountConflicts(from, to uuid.UUID, startsAt, endsAt time.Time) (int64, error) {
+	var n int64
+	for _, d := range r.list {
+		overlap := d.StartsAt.Before(endsAt) && d.EndsAt.After(startsAt)
+		related := d.FromLecturerID == from || d.ToLecturerID == from || d.FromLecturerID == to
+		if d.RevokedAt == nil && overlap && related {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeDelegationRepo) active(d *model.VerificationDelegation, at time.Time) bool {
+	return d.RevokedAt == nil && !at.Before(d.StartsAt) && at.Before(d.EndsAt)
+}
+
+func (r *fakeDelegationRepo) FindActiveDelegatorIDs(to uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, d := range r.list {
+		if d.ToLecturerID == to && r.active(d, at) && !slices.Contains(ids, d.FromLecturerID) {
+			ids = append(ids, d.FromLecturerID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeDelegationRepo) FindActiveDelegatorForStudent(to, studentID uuid.UUID, at time.Time) (*uuid.UUID, error) {
+	for _, d := range r.list {
+		if d.ToLecturerID == to && r.advisors[studentID] == d.FromLecturerID && r.active(d, at) {
+			from := d.FromLecturerID
+			return &from, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeDelegationLecturerRepo: semua dosen ada; dosen wali langsung dibaca dari fakeDelegationRepo.advisors.
+type fakeDelegationLecturerRepo struct {
+	repository.LecturerRepository
+	delegations *fakeDelegationRepo
+}
+
+func (r fakeDelegationLecturerRepo) FindByID(id uuid.UUID) (*model.Lecturer, error) {
+	return &model.Lecturer{ID: id}, nil
+}
+
+func (r fakeDelegationLecturerRepo) IsAdvisorOf(lecturerID, studentID uuid.UUID) (bool, error) {
+	return r.delegations.advisors[studentID] == lecturerID, nil
+}
+
+// TestLecturerDelegations: delegasi baru ditolak jika overlap/berantai atau rentangnya sudah
+// lewat, dan daftar delegasi menandai hanya yang sedang berjalan sebagai aktif.
+func TestLecturerDelegations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	advisor, delegate, other := uuid.New(), uuid.New(), uuid.New()
+	delegations := &fakeDelegationRepo{}
+	svc := NewLecturerService(fakeDelegationLecturerRepo{delegations: delegations}, delegations, &fakeAuditRepo{}, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("role", "admin"); c.Set("userID", uuid.New()) })
+	r.GET("/lecturers/:id/delegations", svc.GetLecturerDelegations)
+	r.POST("/lecturers/:id/delegations", svc.CreateLecturerDelegation)
+	r.DELETE("/lecturers/:id/delegations/:delegationId", svc.RevokeLecturerDelegation)
+
+	create := func(from, to uuid.UUID, starts, ends time.Time) int {
+		w, _ := doJSON(t, r, http.MethodPost, "/lecturers/"+from.String()+"/delegations", "", map[string]any{
+			"toLecturerId": to.String(), "startsAt": starts, "endsAt": ends,
+		})
+		return w.Code
+	}
+
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name     string
+		from, to uuid.UUID
+		starts   time.Time
+		ends     time.Time
+		want     int
+	}{
+		{name: "aktif sekarang", from: advisor, to: delegate, starts: now.Add(-time.Hour), ends: now.Add(week), want: http.StatusCreated},
+		{name: "overlap dengan delegasi aktif", from: advisor, to: other, starts: now.Add(24 * time.Hour), ends: now.Add(2 * week), want: http.StatusConflict},
+		{name: "berantai dari penerima", from: delegate, to: other, starts: now, ends: now.Add(week), want: http.StatusConflict},
+		{name: "rentang sudah lewat", from: advisor, to: other, starts: now.Add(-2 * week), ends: now.Add(-week), want: http.StatusBadRequest},
+		{name: "setelah delegasi aktif berakhir", from: advisor, to: other, starts: now.Add(week), ends: now.Add(2 * week), want: http.StatusCreated},
+		{name: "ke diri sendiri", from: advisor, to: advisor, starts: now, ends: now.Add(week), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		before := len(delegations.list)
+		if code := create(tt.from, tt.to, tt.starts, tt.ends); code != tt.want {
+			t.Fatalf("%s: status %d, want %d", tt.name, code, tt.want)
+		}
+		if created := len(delegations.list) - before; (created == 1) != (tt.want == http.StatusCreated) {
+			t.Fatalf("%s: %d delegasi tersimpan", tt.name, created)
+		}
+	}
+
+	// Delegasi kedaluwarsa & dicabut tetap tampil di daftar, tapi tidak aktif.
+	expired := &model.VerificationDelegation{FromLecturerID: advisor, ToLecturerID: other,
+		StartsAt: now.Add(-2 * week), EndsAt: now.Add(-week)}
+	_ = delegations.Create(expired)
+	w, _ := doJSON(t, r, http.MethodDelete, "/lecturers/"+advisor.String()+"/delegations/"+delegations.list[1].ID.String(), "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke: status %d, body %s", w.Code, w.Body)
+	}
+	if w, _ := doJSON(t, r, http.MethodDelete, "/lecturers/"+other.String()+"/delegations/"+delegations.list[0].ID.String(), "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("revoke oleh bukan pemberi: status %d, want 404", w.Code)
+	}
+
+	w, _ = doJSON(t, r, http.MethodGet, "/lecturers/"+advisor.String()+"/delegations", "", nil)
+	var resp struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	items := resp.Data
+	wantActive := map[string]bool{
+		delegations.list[0].ID.String(): true,  // sedang berjalan
+		delegations.list[1].ID.String(): false, // belum mulai lalu dicabut
+		expired.ID.String():             false, // kedaluwarsa
+	}
+	if len(items) != len(wantActive) {
+		t.Fatalf("%d delegasi, want %d: %s", len(items), len(wantActive), w.Body)
+	}
+	for _, item := range items {
+		if want := wantActive[item["id"].(string)]; item["active"] != want {
+			t.Fatalf("delegasi %v active=%v, want %v", item["id"], item["active"], want)
+		}
+	}
+}
+
+// TestDelegateVerifyAccess: dosen penerima delegasi hanya bisa memverifikasi prestasi bimbingan
+// pemberi selama delegasinya aktif; verifikasi dicatat atas nama dosen wali asli.
+func TestDelegateVerifyAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	tests := []struct {
+		name       string
+		starts     time.Time
+		ends       time.Time
+		revoked    bool
+		wantStatus int
+	}{
+		{name: "delegasi aktif", starts: now.Add(-time.Hour), ends: now.Add(time.Hour), wantStatus: http.StatusOK},
+		{name: "delegasi kedaluwarsa", starts: now.Add(-48 * time.Hour), ends: now.Add(-time.Hour), wantStatus: http.StatusForbidden},
+		{name: "delegasi belum mulai", starts: now.Add(time.Hour), ends: now.Add(48 * time.Hour), wantStatus: http.StatusForbidden},
+		{name: "delegasi dicabut", starts: now.Add(-time.Hour), ends: now.Add(time.Hour), revoked: true, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advisor, delegate, studentID := uuid.New(), uuid.New(), uuid.New()
+			delegations := &fakeDelegationRepo{advisors: map[uuid.UUID]uuid.UUID{studentID: advisor}}
+			d := &model.VerificationDelegation{FromLecturerID: advisor, ToLecturerID: delegate, StartsAt: tt.starts, EndsAt: tt.ends}
+			_ = delegations.Create(d)
+			if tt.revoked {
+				_ = delegations.Revoke(d.ID)
+			}
+			ref := &model.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: model.StatusSubmitted}
+			repo := newFakeAchievementRepo(ref)
+			audit := &fakeAuditRepo{}
+			svc := NewAchievementService(repo, nil, nil, fakeDelegationLecturerRepo{delegations: delegations}, audit, delegations, nil, nil, nil, nil, nil, nil)
+
+			r := gin.New()
+			r.POST("/achievements/:id/verify", func(c *gin.Context) {
+				c.Set("role", "dosen_wali")
+				c.Set("userID", uuid.New())
+				c.Set("lecturerID", delegate)
+			}, svc.VerifyAchievement)
+			w, _ := doJSON(t, r, http.MethodPost, "/achievements/"+ref.ID.String()+"/verify", "", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, body %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(repo.updates) != 0 {
+					t.Fatalf("status tetap diubah: %+v", repo.updates)
+				}
+				return
+			}
+			if len(repo.updates) != 1 || repo.updates[0].Opts.DelegateOf == nil || *repo.updates[0].Opts.DelegateOf != advisor {
+				t.Fatalf("update %+v, want DelegateOf dosen wali asli", repo.updates)
+			}
+			if !slices.Contains(audit.actions, "achievement.verify_as_delegate") {
+				t.Fatalf("audit %v tanpa achievement.verify_as_delegate", audit.actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ================================================================
+// GET /api/v1/lecturers/:id/advisees/export?maskPII=true|false
+// Admin / dosen wali: export CSV mahasiswa bimbingan.
+// Dosen wali hanya boleh mengekspor mahasiswa bimbingannya sendiri (:id = lecturerID token).
+// Email & NIM selalu dimasking (utils.MaskPII) kecuali admin secara eksplisit
+// mengirim maskPII=false — aksi tersebut dicatat di audit log.
+// ================================================================
+func (s *lecturerService) ExportLecturerAdvisees(ctx *gin.Context) {
+
+	role := ctx.GetString("role")
+	if role != "admin" && role != "dosen_wali" {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Hanya admin atau dosen wali yang dapat mengekspor mahasiswa bimbingan", "forbidden", nil))
+		return
+	}
+
+	lectID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID dosen tidak valid", err.Error(), nil))
+		return
+	}
+
+	if role == "dosen_wali" {
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized,
+				utils.BuildResponseFailed("Autentikasi dosen wali diperlukan", "no_user_id", nil))
+			return
+		}
+		ownID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
+		if err != nil || ownID != lectID {
+			ctx.JSON(http.StatusForbidden,
+				utils.BuildResponseFailed("Dosen wali hanya dapat mengekspor mahasiswa bimbingannya sendiri", "forbidden", nil))
+			return
+		}
+	}
+
+	mask := true
+	if v := ctx.Query("maskPII"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("maskPII harus true/false", err.Error(), nil))
+			return
+		}
+		mask = b
+	}
+	if !mask && role != "admin" {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Hanya admin yang dapat mengekspor data pribadi tanpa masking", "pii_unmask_forbidden", nil))
+		return
+	}
+
+	if _, err := s.lecturerRepo.FindByID(lectID); err != nil {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Dosen tidak ditemukan", err.Error(), nil))
+		return
+	}
+
+	rows, err := s.lecturerRepo.FindAdviseeContacts(lectID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil mahasiswa bimbingan", err.Error(), nil))
+		return
+	}
+
+	if !mask {
+		// File berisi data pribadi lengkap → wajib tercatat siapa yang mengekspor
+		actorID, _ := getUUIDFromContext(ctx, "userID")
+		if err := s.auditRepo.Record(ctx, &actorID, "export.pii_unmasked", "lecturer", lectID.String(), map[string]any{
+			"export": "lecturer_advisees",
+			"rows":   len(rows),
+		}); err != nil {
+			ctx.JSON(http.StatusInternalServerError,
+				utils.BuildResponseFailed("Gagal mencatat audit export", err.Error(), nil))
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="mahasiswa-bimbingan-%s.csv"`, lectID))
+	ctx.Header("X-PII-Masked", strconv.FormatBool(mask))
+	ctx.Status(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Writer)
+	_ = w.Write([]string{"nim", "nama", "email", "program_studi", "angkatan"})
+	for _, r := range rows {
+		pii := utils.MaskPII(mask, utils.PII{Email: r.Email, NIM: r.NIM})
+		_ = w.Write([]string{pii.NIM, r.FullName, pii.Email, r.ProgramStudy, r.AcademicYear})
+	}
+	w.Flush()
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeLecturerRepo hanya mengimplementasikan method yang dipakai export; method lain panic.
+type fakeLecturerRepo struct {
+	repository.LecturerRepository
+	byUser   map[uuid.UUID]uuid.UUID // userID → lecturers.id
+	byCode   map[string]*model.Lecturer
+	contacts []repository.AdviseeContact
+}
+
+func (r *fakeLecturerRepo) FindByID(id uuid.UUID) (*model.Lecturer, error) {
+	return &model.Lecturer{ID: id}, nil
+}
+
+func (r *fakeLecturerRepo) FindByUserID(userID uuid.UUID) (*model.Lecturer, error) {
+	id, ok := r.byUser[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.Lecturer{ID: id, UserID: userID}, nil
+}
+
+func (r *fakeLecturerRepo) FindByCode(code string) (*model.Lecturer, error) {
+	if l, ok := r.byCode[code]; ok {
+		return l, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLecturerRepo) FindAdviseeContacts(uuid.UUID) ([]repository.AdviseeContact, error) {
+	return r.contacts, nil
+}
+
+// fakeAuditRepo mencatat action yang direkam.
+type fakeAuditRepo struct {
+	repository.AuditRepository
+	actions  []string
+	payloads []any // payload per action (indeks sama dengan actions)
+}
+
+func (r *fakeAuditRepo) Record(_ context.Context, _ *uuid.UUID, action, _, _ string, payload any) error {
+	r.actions = append(r.actions, action)
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func TestExportLecturerAdvisees(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ownLecturer, otherLecturer := uuid.New(), uuid.New()
+	dosenUser, adminUser := uuid.New(), uuid.New()
+	contacts := []repository.AdviseeContact{{NIM: "2109106001", FullName: "Budi", Email: "budi@kampus.ac.id"}}
+
+	tests := []struct {
+		name       string
+		role       string
+		userID     uuid.UUID
+		lecturerID uuid.UUID // klaim lecturerID di token (Nil → fallback FindByUserID)
+		target     uuid.UUID
+		query      string
+		wantStatus int
+		wantBody   string
+		wantAudit  bool
+	}{
+		{name: "dosen wali sendiri dimasking", role: "dosen_wali", userID: dosenUser, lecturerID: ownLecturer, target: ownLecturer,
+			wantStatus: http.StatusOK, wantBody: "2109******,Budi,b***@kampus.ac.id"},
+		{name: "dosen wali tanpa klaim lecturerID", role: "dosen_wali", userID: dosenUser, target: ownLecturer,
+			wantStatus: http.StatusOK, wantBody: "b***@kampus.ac.id"},
+		{name: "dosen wali dosen lain ditolak", role: "dosen_wali", userID: dosenUser, lecturerID: ownLecturer, target: otherLecturer,
+			wantStatus: http.StatusForbidden},
+		{name: "dosen wali minta unmask ditolak", role: "dosen_wali", userID: dosenUser, lecturerID: ownLecturer, target: ownLecturer,
+			query: "?maskPII=false", wantStatus: http.StatusForbidden},
+		{name: "admin default dimasking", role: "admin", userID: adminUser, target: otherLecturer,
+			wantStatus: http.StatusOK, wantBody: "2109******,Budi,b***@kampus.ac.id"},
+		{name: "admin unmask diaudit", role: "admin", userID: adminUser, target: otherLecturer,
+			query: "?maskPII=false", wantStatus: http.StatusOK, wantBody: "2109106001,Budi,budi@kampus.ac.id", wantAudit: true},
+		{name: "mahasiswa ditolak", role: "mahasiswa", userID: uuid.New(), target: ownLecturer,
+			wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lecturers := &fakeLecturerRepo{byUser: map[uuid.UUID]uuid.UUID{dosenUser: ownLecturer}, contacts: contacts}
+			audit := &fakeAuditRepo{}
+			svc := NewLecturerService(lecturers, nil, audit, nil)
+
+			r := gin.New()
+			r.GET("/lecturers/:id/advisees/export", func(c *gin.Context) {
+				c.Set("role", tt.role)
+				c.Set("userID", tt.userID)
+				if tt.lecturerID != uuid.Nil {
+					c.Set("lecturerID", tt.lecturerID)
+				}
+			}, svc.ExportLecturerAdvisees)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/lecturers/"+tt.target.String()+"/advisees/export"+tt.query, nil))
+
+			checkEnvelope(t, w)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantBody != "" && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Fatalf("body %q tidak memuat %q", w.Body, tt.wantBody)
+			}
+			if got := len(audit.actions) == 1 && audit.actions[0] == "export.pii_unmasked"; got != tt.wantAudit {
+				t.Fatalf("audit %v, want export.pii_unmasked = %v", audit.actions, tt.wantAudit)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"net/http"
+	"time"
 
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
 	"student-achievement-backend/utils"
 
@@ -13,17 +15,39 @@ import (
 // LecturerService meng-handle endpoint SRS 5.5 untuk Lecturers:
 // GET /lecturers
 // GET /lecturers/:id/advisees
+// + delegasi verifikasi (lecturers/me/delegations & override admin lecturers/:id/delegations)
 type LecturerService interface {
 	GetLecturers(ctx *gin.Context)
 	GetLecturerAdvisees(ctx *gin.Context)
+	ExportLecturerAdvisees(ctx *gin.Context)
+
+	GetMyDelegations(ctx *gin.Context)
+	CreateMyDelegation(ctx *gin.Context)
+	RevokeMyDelegation(ctx *gin.Context)
+	GetLecturerDelegations(ctx *gin.Context)
+	CreateLecturerDelegation(ctx *gin.Context)
+	RevokeLecturerDelegation(ctx *gin.Context)
 }
 
 type lecturerService struct {
 	lecturerRepo repository.LecturerRepository
+	delegRepo    repository.DelegationRepository
+	auditRepo    repository.AuditRepository
+	targetRepo   repository.TargetRepository
 }
 
-func NewLecturerService(lecturerRepo repository.LecturerRepository) LecturerService {
-	return &lecturerService{lecturerRepo}
+func NewLecturerService(
+	lecturerRepo repository.LecturerRepository,
+	delegRepo repository.DelegationRepository,
+	auditRepo repository.AuditRepository,
+	targetRepo repository.TargetRepository,
+) LecturerService {
+	return &lecturerService{
+		lecturerRepo: lecturerRepo,
+		delegRepo:    delegRepo,
+		auditRepo:    auditRepo,
+		targetRepo:   targetRepo,
+	}
 }
 
 // =======================
@@ -31,9 +55,9 @@ func NewLecturerService(lecturerRepo repository.LecturerRepository) LecturerServ
 // =======================
 func (s *lecturerService) GetLecturers(ctx *gin.Context) {
 
-	// Misal: hanya admin yang boleh melihat semua dosen.
+	// Misal: hanya admin (atau API key lecturer:read) yang boleh melihat semua dosen.
 	roleI, _ := ctx.Get("role")
-	if role, _ := roleI.(string); role != "admin" {
+	if role, _ := roleI.(string); role != "admin" && !apiKeyCan(ctx, model.PermissionLecturerRead) {
 		ctx.JSON(http.StatusForbidden,
 			utils.BuildResponseFailed("Hanya admin yang dapat melihat daftar dosen", "forbidden", nil))
 		return
@@ -85,6 +109,23 @@ func (s *lecturerService) GetLecturerAdvisees(ctx *gin.Context) {
 		return
 	}
 
+	// Sertakan progres target tahunan (tahun akademik berjalan) per mahasiswa.
+	progress, err := loadTargetProgress(s.targetRepo, students, utils.AcademicYearOf(time.Now()))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghitung progres target", err.Error(), nil))
+		return
+	}
+
+	type adviseeWithProgress struct {
+		model.Student
+		TargetProgress repository.TargetProgress `json:"targetProgress"`
+	}
+	out := make([]adviseeWithProgress, 0, len(students))
+	for _, st := range students {
+		out = append(out, adviseeWithProgress{Student: st, TargetProgress: progress[st.ID]})
+	}
+
 	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Berhasil mengambil daftar mahasiswa bimbingan", students))
+		utils.BuildResponseSuccess("Berhasil mengambil daftar mahasiswa bimbingan", out))
 }
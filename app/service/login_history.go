@@ -0,0 +1,113 @@
+package service
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxLoginEventUserAgent mengikuti ukuran kolom user_agent (login_events & refresh_tokens).
+const maxLoginEventUserAgent = 512
+
+// truncate memotong s menjadi maksimal n byte (sesuai ukuran kolom varchar).
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// recordLoginEvent menyimpan 1 percobaan login. Kegagalan hanya di-log agar tidak
+// mengubah hasil login.
+func (s *authService) recordLoginEvent(ctx *gin.Context, userID *uuid.UUID, username string, success bool, reason string) {
+	err := s.loginEventRepo.Create(&model.LoginEvent{
+		UserID:        userID,
+		Username:      truncate(username, 255),
+		IP:            ctx.ClientIP(),
+		UserAgent:     truncate(ctx.Request.UserAgent(), maxLoginEventUserAgent),
+		Success:       success,
+		FailureReason: reason,
+	})
+	if err != nil {
+		log.Printf("⚠️  gagal mencatat riwayat login %q: %v", username, err)
+	}
+}
+
+// loginHistoryPage mengambil 1 halaman riwayat login userID (?page=&limit=).
+func loginHistoryPage(ctx *gin.Context, repo repository.LoginEventRepository, userID uuid.UUID) (map[string]any, error) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = repository.NormalizePagination(page, limit)
+
+	events, total, err := repo.FindByUser(userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"items": events,
+		"meta": map[string]any{
+			"page":      page,
+			"limit":     limit,
+			"totalData": total,
+			"totalPage": (total + int64(limit) - 1) / int64(limit),
+		},
+	}, nil
+}
+
+// ===============================================================
+//  GET /api/v1/auth/login-history?page=1&limit=10
+//  Riwayat login (berhasil & gagal) milik user yang sedang login.
+// ===============================================================
+func (s *authService) GetLoginHistory(ctx *gin.Context) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	data, err := loginHistoryPage(ctx, s.loginEventRepo, userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil riwayat login", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil riwayat login", data)
+}
+
+// ===============================================================
+//  GET /api/v1/admin/users/:id/login-history?page=1&limit=10
+//  Admin: riwayat login user tertentu (audit keamanan kampus).
+// ===============================================================
+func (s *adminService) GetUserLoginHistory(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+
+	data, err := loginHistoryPage(ctx, s.loginEventRepo, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil riwayat login", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil riwayat login user", data))
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetaService menyediakan data referensi (enum, label) untuk frontend,
+// supaya frontend tidak menyimpan salinan daftar sendiri.
+type MetaService interface {
+	// GET /api/v1/meta/achievement-statuses
+	GetAchievementStatuses(ctx *gin.Context)
+	// GET /api/v1/meta/rejection-categories
+	GetRejectionCategories(ctx *gin.Context)
+	// GET /api/v1/achievement-types
+	GetAchievementTypes(ctx *gin.Context)
+}
+
+// metaCacheTTL: lama daftar referensi disimpan di memori. Perubahan katalog tipe oleh
+// admin terlihat di endpoint meta paling lambat setelah TTL ini.
+const metaCacheTTL = 5 * time.Minute
+
+// Key cache per daftar referensi.
+const (
+	metaKeyStatuses            = "achievement-statuses"
+	metaKeyRejectionCategories = "rejection-categories"
+	metaKeyAchievementTypes    = "achievement-types"
+)
+
+type metaCacheEntry struct {
+	data     []map[string]string
+	cachedAt time.Time
+}
+
+type metaService struct {
+	typeRepo repository.AchievementTypeRepository
+	ttl      time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	entries  map[string]metaCacheEntry
+}
+
+func NewMetaService(typeRepo repository.AchievementTypeRepository) MetaService {
+	return &metaService{
+		typeRepo: typeRepo,
+		ttl:      metaCacheTTL,
+		now:      time.Now,
+		entries:  map[string]metaCacheEntry{},
+	}
+}
+
+// cached mengembalikan daftar key dari cache jika umurnya < TTL, selain itu memanggil load.
+// Hasil load yang gagal tidak disimpan.
+func (s *metaService) cached(key string, load func() ([]map[string]string, error)) ([]map[string]string, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && now.Sub(entry.cachedAt) < s.ttl {
+		return entry.data, nil
+	}
+
+	data, err := load()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.entries[key] = metaCacheEntry{data: data, cachedAt: now}
+	s.mu.Unlock()
+	return data, nil
+}
+
+// GetAchievementStatuses mengembalikan daftar status prestasi beserta labelnya.
+func (s *metaService) GetAchievementStatuses(ctx *gin.Context) {
+	out, _ := s.cached(metaKeyStatuses, func() ([]map[string]string, error) {
+		statuses := model.AchievementStatuses()
+		out := make([]map[string]string, 0, len(statuses))
+		for _, st := range statuses {
+			out = append(out, map[string]string{
+				"value": st,
+				"label": model.AchievementStatusLabel(st),
+			})
+		}
+		return out, nil
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil daftar status prestasi", out))
+}
+
+// GetRejectionCategories mengembalikan daftar kategori penolakan prestasi beserta labelnya.
+func (s *metaService) GetRejectionCategories(ctx *gin.Context) {
+	out, _ := s.cached(metaKeyRejectionCategories, func() ([]map[string]string, error) {
+		categories := model.RejectionCategories()
+		out := make([]map[string]string, 0, len(categories))
+		for _, c := range categories {
+			out = append(out, map[string]string{
+				"value": c,
+				"label": model.RejectionCategoryLabel(c),
+			})
+		}
+		return out, nil
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil daftar kategori penolakan", out))
+}
+
+// GetAchievementTypes mengembalikan katalog tipe prestasi yang aktif (code + label).
+func (s *metaService) GetAchievementTypes(ctx *gin.Context) {
+	out, err := s.cached(metaKeyAchievementTypes, func() ([]map[string]string, error) {
+		types, err := s.typeRepo.FindAll(true)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]map[string]string, 0, len(types))
+		for _, t := range types {
+			out = append(out, map[string]string{
+				"value": t.Code,
+				"label": t.Label,
+			})
+		}
+		return out, nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil daftar tipe prestasi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil daftar tipe prestasi", out))
+}
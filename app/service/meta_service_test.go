@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTypeRepo menghitung pemanggilan FindAll; err != nil → FindAll gagal.
+type fakeTypeRepo struct {
+	repository.AchievementTypeRepository
+	types []model.AchievementType
+	err   error
+	calls int
+}
+
+func (r *fakeTypeRepo) FindAll(bool) ([]model.AchievementType, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return append([]model.AchievementType(nil), r.types...), nil
+}
+
+// TestMetaAchievementTypesTTLCache: katalog tipe dibaca dari cache selama TTL, dimuat ulang
+// setelah kedaluwarsa, dan kegagalan repository tidak ikut tersimpan.
+func TestMetaAchievementTypesTTLCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeTypeRepo{types: []model.AchievementType{{Code: "competition", Label: "Kompetisi", Active: true}}}
+	svc := NewMetaService(repo).(*metaService)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.GET("/achievement-types", svc.GetAchievementTypes)
+	get := func() (int, []map[string]string) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/achievement-types", nil))
+		checkEnvelope(t, w)
+		var resp struct {
+			Data []map[string]string `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Data
+	}
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		setup     func()
+		wantCode  int
+		wantLabel string
+		wantCalls int
+	}{
+		{name: "pertama kali dimuat dari repository", wantCode: http.StatusOK, wantLabel: "Kompetisi", wantCalls: 1},
+		{name: "dalam TTL dari cache", advance: metaCacheTTL - time.Second, wantCode: http.StatusOK,
+			setup: func() { repo.types[0].Label = "Lomba" }, wantLabel: "Kompetisi", wantCalls: 1},
+		{name: "setelah TTL dimuat ulang", advance: time.Second, wantCode: http.StatusOK, wantLabel: "Lomba", wantCalls: 2},
+		{name: "gagal dimuat tidak disimpan", advance: metaCacheTTL, wantCode: http.StatusInternalServerError,
+			setup: func() { repo.err = errors.New("db down") }, wantCalls: 3},
+		{name: "request berikutnya mencoba lagi", wantCode: http.StatusOK,
+			setup: func() { repo.err = nil }, wantLabel: "Lomba", wantCalls: 4},
+	}
+	for _, st := range steps {
+		now = now.Add(st.advance)
+		if st.setup != nil {
+			st.setup()
+		}
+		code, data := get()
+		if code != st.wantCode {
+			t.Fatalf("%s: status %d, want %d", st.name, code, st.wantCode)
+		}
+		if st.wantLabel != "" && (len(data) != 1 || data[0]["label"] != st.wantLabel) {
+			t.Fatalf("%s: data %v, want label %q", st.name, data, st.wantLabel)
+		}
+		if repo.calls != st.wantCalls {
+			t.Fatalf("%s: FindAll dipanggil %d kali, want %d", st.name, repo.calls, st.wantCalls)
+		}
+	}
+}
+
+// TestMetaAchievementStatusesFromModel: endpoint status mengembalikan daftar model yang sama
+// (urutan & label), juga saat dibaca dari cache.
+func TestMetaAchievementStatusesFromModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := NewMetaService(nil)
+	r := gin.New()
+	r.GET("/achievement-statuses", svc.GetAchievementStatuses)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/achievement-statuses", nil))
+		checkEnvelope(t, w)
+		var resp struct {
+			Data []map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		want := model.AchievementStatuses()
+		if len(resp.Data) != len(want) {
+			t.Fatalf("request %d: %d status, want %d", i, len(resp.Data), len(want))
+		}
+		for j, s := range want {
+			if resp.Data[j]["value"] != s || resp.Data[j]["label"] != model.AchievementStatusLabel(s) {
+				t.Fatalf("request %d: item %d = %v, want %s", i, j, resp.Data[j], s)
+			}
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Notifier mengirim 1 notifikasi ke penerimanya (email, push, dll).
+type Notifier interface {
+	Notify(ctx context.Context, n model.PendingNotification) error
+}
+
+// LogNotifier adalah Notifier default: hanya menulis notifikasi ke log.
+type LogNotifier struct{}
+
+// Notify memenuhi interface Notifier.
+func (LogNotifier) Notify(_ context.Context, n model.PendingNotification) error {
+	key := ""
+	if n.IdempotencyKey != nil {
+		key = " key=" + *n.IdempotencyKey
+	}
+	log.Printf("🔔 notify %s:%s [%s]%s %s", n.RecipientType, n.RecipientID, n.Type, key, n.Message)
+	return nil
+}
+
+// NotificationQueue adalah sisi "enqueue" dispatcher yang dipakai service lain.
+// Enqueue tidak pernah blocking; nil berarti notifikasi sudah tersimpan durable.
+type NotificationQueue interface {
+	Enqueue(n model.PendingNotification) error
+}
+
+// NotificationService meng-handle endpoint monitoring antrean notifikasi.
+type NotificationService interface {
+	GetQueueStats(ctx *gin.Context) // GET /api/v1/admin/notifications/stats
+}
+
+// NotificationStats adalah metrik antrean notifikasi.
+type NotificationStats struct {
+	Workers       int   `json:"workers"`
+	QueueCapacity int   `json:"queueCapacity"`
+	QueueDepth    int   `json:"queueDepth"`
+	ActiveWorkers int64 `json:"activeWorkers"`
+	Enqueued      int64 `json:"enqueued"`
+	Overflowed    int64 `json:"overflowed"` // antrean penuh → disimpan ke pending_notifications
+	Delivered     int64 `json:"delivered"`
+	Retried       int64 `json:"retried"`
+	Failed        int64 `json:"failed"` // melewati batas percobaan (tetap tersimpan)
+	PersistErrors int64 `json:"persistErrors"`
+	PendingRows   int64 `json:"pendingRows"`
+	FailedRows    int64 `json:"failedRows"`
+}
+
+// NotificationDispatcher adalah worker pool terbatas yang mengonsumsi buffered channel.
+// Jumlah worker mengikuti utils.Runtime().NotifyWorkers dan disesuaikan di setiap tick drainer
+// (setelah config di-reload). Jika channel penuh, notifikasi disimpan ke tabel
+// pending_notifications (persist-and-retry) dan di-drain kembali oleh goroutine drainer saat antrean longgar.
+type NotificationDispatcher struct {
+	repo       repository.NotificationRepository
+	notifier   Notifier
+	queue      chan model.PendingNotification
+	drainEvery time.Duration
+	lease      time.Duration
+
+	startOnce sync.Once
+	poolMu    sync.Mutex
+	stops     []chan struct{}    // 1 channel stop per worker yang berjalan
+	closing   atomic.Bool        // Shutdown dimulai: notifikasi baru hanya disimpan, tidak diantrekan
+	inflight  atomic.Int64       // notifikasi di antrean + yang sedang dikirim worker
+	abort     context.CancelFunc // membatalkan pengiriman yang masih berjalan saat Shutdown kehabisan waktu
+
+	active, enqueued, overflowed, delivered, retried, failed, persistErrors atomic.Int64
+}
+
+// NewNotificationDispatcher membuat dispatcher dari env:
+// NOTIFY_QUEUE_SIZE (256), NOTIFY_DRAIN_INTERVAL (5s). NOTIFY_WORKERS (4), NOTIFY_MAX_ATTEMPTS (5)
+// dan NOTIFY_RETRY_DELAY (30s, dikali jumlah percobaan) dibaca dari utils.Runtime() (bisa di-reload).
+func NewNotificationDispatcher(repo repository.NotificationRepository, notifier Notifier) *NotificationDispatcher {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &NotificationDispatcher{
+		repo:       repo,
+		notifier:   notifier,
+		queue:      make(chan model.PendingNotification, max(utils.GetEnvInt("NOTIFY_QUEUE_SIZE", 256), 1)),
+		drainEvery: utils.GetEnvDuration("NOTIFY_DRAIN_INTERVAL", 5*time.Second),
+		lease:      5 * time.Minute,
+	}
+}
+
+// Start menjalankan worker & drainer sampai ctx dibatalkan. Aman dipanggil lebih dari sekali.
+func (d *NotificationDispatcher) Start(ctx context.Context) {
+	d.startOnce.Do(func() {
+		ctx, d.abort = context.WithCancel(ctx)
+		d.resize(ctx)
+		go d.drain(ctx)
+	})
+}
+
+// resize menyamakan jumlah worker dengan NotifyWorkers saat ini. Worker yang dihentikan
+// menyelesaikan notifikasi yang sedang dikirim lebih dulu.
+func (d *NotificationDispatcher) resize(ctx context.Context) {
+	want := utils.Runtime().NotifyWorkers
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+	for len(d.stops) < want {
+		stop := make(chan struct{})
+		d.stops = append(d.stops, stop)
+		go d.work(ctx, stop)
+	}
+	for len(d.stops) > want {
+		last := len(d.stops) - 1
+		close(d.stops[last])
+		d.stops = d.stops[:last]
+	}
+}
+
+func (d *NotificationDispatcher) workerCount() int {
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+	return len(d.stops)
+}
+
+// Enqueue menyimpan notifikasi ke pending_notifications lebih dulu, baru memasukkannya
+// ke antrean tanpa blocking. Baris yang masuk antrean di-lease (next_attempt_at ditunda)
+// agar tidak diambil drainer; jika proses mati sebelum terkirim, baris diambil ulang setelah
+// lease habis. Jika antrean penuh, baris langsung jatuh tempo dan di-drain kemudian.
+// Error dikembalikan jika notifikasi gagal disimpan (pemanggil wajib me-retry).
+func (d *NotificationDispatcher) Enqueue(n model.PendingNotification) error {
+	now := time.Now()
+	if n.NextAttemptAt.IsZero() {
+		n.NextAttemptAt = now
+	}
+	due := n.NextAttemptAt
+	closing := d.closing.Load()
+	if !closing && !due.After(now) && len(d.queue) < cap(d.queue) {
+		n.NextAttemptAt = now.Add(d.lease)
+	}
+	if err := d.repo.Save(&n); err != nil {
+		d.persistErrors.Add(1)
+		return err
+	}
+	if n.ID == uuid.Nil {
+		return nil // idempotency key sudah ada: notifikasi yang sama sudah diantrekan
+	}
+	if due.After(now) || closing {
+		return nil // dijadwalkan untuk nanti / sedang shutdown: diambil drainer saat jatuh tempo
+	}
+	d.inflight.Add(1)
+	select {
+	case d.queue <- n:
+		d.enqueued.Add(1)
+	default:
+		d.inflight.Add(-1)
+		d.overflowed.Add(1)
+		if n.NextAttemptAt.After(now) {
+			n.NextAttemptAt = now
+			d.persist(&n)
+		}
+	}
+	return nil
+}
+
+// persist menyimpan notifikasi ke pending_notifications; kegagalan dicatat di log & metrik.
+func (d *NotificationDispatcher) persist(n *model.PendingNotification) {
+	if err := d.repo.Save(n); err != nil {
+		d.persistErrors.Add(1)
+		log.Printf("❌ gagal menyimpan notifikasi %s untuk %s:%s: %v", n.Type, n.RecipientType, n.RecipientID, err)
+	}
+}
+
+// work adalah 1 worker: kirim notifikasi, lalu hapus baris pending atau jadwalkan retry.
+func (d *NotificationDispatcher) work(ctx context.Context, stop <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case n := <-d.queue:
+			d.active.Add(1)
+			d.deliver(ctx, n)
+			d.active.Add(-1)
+			d.inflight.Add(-1)
+		}
+	}
+}
+
+func (d *NotificationDispatcher) deliver(ctx context.Context, n model.PendingNotification) {
+	err := d.notifier.Notify(ctx, n)
+	if err != nil && ctx.Err() != nil {
+		// Dibatalkan karena proses berhenti: bukan percobaan gagal, langsung jatuh tempo lagi.
+		n.NextAttemptAt = time.Now()
+		d.persist(&n)
+		return
+	}
+	if err == nil {
+		d.delivered.Add(1)
+		if n.ID != uuid.Nil {
+			if err := d.repo.Delete(n.ID); err != nil {
+				log.Printf("⚠️  notifikasi %s terkirim tetapi baris pending gagal dihapus: %v", n.ID, err)
+			}
+		}
+		return
+	}
+
+	cfg := utils.Runtime()
+	n.Attempts++
+	n.LastError = err.Error()
+	if n.Attempts >= cfg.NotifyMaxAttempts {
+		n.Failed = true
+		d.failed.Add(1)
+	} else {
+		n.NextAttemptAt = time.Now().Add(cfg.NotifyRetryDelay * time.Duration(n.Attempts))
+		d.retried.Add(1)
+	}
+	d.persist(&n)
+}
+
+// drain memindahkan baris pending yang jatuh tempo ke antrean, sebanyak slot yang kosong.
+func (d *NotificationDispatcher) drain(ctx context.Context) {
+	ticker := time.NewTicker(d.drainEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if d.closing.Load() {
+			return
+		}
+		d.resize(ctx)
+
+		free := cap(d.queue) - len(d.queue)
+		if free <= 0 {
+			continue
+		}
+		rows, err := d.repo.LeaseDue(time.Now(), free, d.lease)
+		if err != nil {
+			log.Printf("⚠️  gagal mengambil notifikasi pending: %v", err)
+			continue
+		}
+		for _, row := range rows {
+			d.inflight.Add(1)
+			select {
+			case d.queue <- row:
+			default:
+				d.inflight.Add(-1)
+				// antrean terisi lagi: baris tetap di tabel dan diambil ulang setelah lease habis
+			}
+		}
+	}
+}
+
+// shutdownPoll: interval pengecekan antrean kosong selama Shutdown.
+const shutdownPoll = 20 * time.Millisecond
+
+// Shutdown berhenti menerima notifikasi ke antrean (Enqueue hanya menyimpan baris) dan menunggu
+// worker mengirim semua isi antrean sampai ctx habis. Notifikasi yang belum sempat dikirim
+// dilepas lease-nya (jatuh tempo sekarang) agar langsung diambil instance lain / saat start
+// berikutnya, bukan menunggu lease habis. Dipanggil sebelum ctx worker dibatalkan.
+func (d *NotificationDispatcher) Shutdown(ctx context.Context) error {
+	d.closing.Store(true)
+
+	ticker := time.NewTicker(shutdownPoll)
+	defer ticker.Stop()
+	for d.inflight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			released := d.release()
+			d.abortInFlight()
+			return fmt.Errorf("antrean notifikasi belum habis (%d dilepas ke pending_notifications): %w", released, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// abortGrace: batas tunggu worker menyimpan kembali pengiriman yang dibatalkan.
+const abortGrace = time.Second
+
+// abortInFlight membatalkan pengiriman yang sedang berjalan (barisnya dilepas oleh deliver)
+// dan menunggu sebentar sampai worker selesai menyimpannya.
+func (d *NotificationDispatcher) abortInFlight() {
+	if d.abort == nil {
+		return
+	}
+	d.abort()
+	deadline := time.Now().Add(abortGrace)
+	for d.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(shutdownPoll)
+	}
+}
+
+// release mengosongkan antrean dan menjadikan barisnya jatuh tempo sekarang.
+func (d *NotificationDispatcher) release() int {
+	released := 0
+	for {
+		select {
+		case n := <-d.queue:
+			n.NextAttemptAt = time.Now()
+			d.persist(&n)
+			d.inflight.Add(-1)
+			released++
+		default:
+			return released
+		}
+	}
+}
+
+// Stats mengembalikan snapshot metrik antrean.
+func (d *NotificationDispatcher) Stats() NotificationStats {
+	st := NotificationStats{
+		Workers:       d.workerCount(),
+		QueueCapacity: cap(d.queue),
+		QueueDepth:    len(d.queue),
+		ActiveWorkers: d.active.Load(),
+		Enqueued:      d.enqueued.Load(),
+		Overflowed:    d.overflowed.Load(),
+		Delivered:     d.delivered.Load(),
+		Retried:       d.retried.Load(),
+		Failed:        d.failed.Load(),
+		PersistErrors: d.persistErrors.Load(),
+	}
+	if pending, failed, err := d.repo.CountPending(); err == nil {
+		st.PendingRows, st.FailedRows = pending, failed
+	}
+	return st
+}
+
+// ===============================================================
+//  GET /api/v1/admin/notifications/stats
+//  Admin: kedalaman antrean, jumlah worker aktif, limpahan & kegagalan notifikasi.
+// ===============================================================
+func (d *NotificationDispatcher) GetQueueStats(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil statistik antrean notifikasi", d.Stats()))
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+
+	"github.com/google/uuid"
+)
+
+// gatedNotifier menahan setiap pengiriman sampai gate ditutup (nil = langsung terkirim).
+type gatedNotifier struct {
+	gate chan struct{}
+	sent atomic.Int64
+}
+
+func (n *gatedNotifier) Notify(ctx context.Context, _ model.PendingNotification) error {
+	if n.gate != nil {
+		select {
+		case <-n.gate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	n.sent.Add(1)
+	return nil
+}
+
+func TestNotificationShutdownDrainsQueue(t *testing.T) {
+	const burst = 8
+	tests := []struct {
+		name        string
+		blocked     bool // notifier tidak pernah selesai sebelum batas waktu shutdown
+		wantErr     bool
+		wantSent    int64
+		wantPending int // baris yang tersisa di pending_notifications
+	}{
+		{name: "antrean terkirim habis sebelum berhenti", wantSent: burst},
+		{name: "batas waktu habis: sisa antrean dilepas", blocked: true, wantErr: true, wantPending: burst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NOTIFY_QUEUE_SIZE", "16")
+			repo := newFakeNotificationRepo()
+			notifier := &gatedNotifier{}
+			if tt.blocked {
+				notifier.gate = make(chan struct{})
+			}
+			d := NewNotificationDispatcher(repo, notifier)
+			for i := 0; i < burst; i++ {
+				n := model.PendingNotification{RecipientType: "student", RecipientID: uuid.New(), Type: "achievement.verified"}
+				if err := d.Enqueue(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			workerCtx, stopWorkers := context.WithCancel(context.Background())
+			defer stopWorkers()
+			d.Start(workerCtx)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			err := d.Shutdown(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Shutdown err = %v, wantErr %v", err, tt.wantErr)
+			}
+			stopWorkers()
+
+			if got := notifier.sent.Load(); got != tt.wantSent {
+				t.Fatalf("terkirim %d, want %d", got, tt.wantSent)
+			}
+			repo.mu.Lock()
+			defer repo.mu.Unlock()
+			if len(repo.rows) != tt.wantPending {
+				t.Fatalf("baris pending %d, want %d", len(repo.rows), tt.wantPending)
+			}
+			// Baris yang dilepas / sedang dikirim saat berhenti tidak boleh menunggu lease 5 menit.
+			for _, row := range repo.rows {
+				if row.NextAttemptAt.After(time.Now().Add(time.Minute)) {
+					t.Fatalf("baris %s masih ter-lease sampai %s", row.ID, row.NextAttemptAt)
+				}
+			}
+		})
+	}
+}
+
+func TestNotificationEnqueueAfterShutdownOnlyPersists(t *testing.T) {
+	repo := newFakeNotificationRepo()
+	d := NewNotificationDispatcher(repo, &gatedNotifier{})
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	n := model.PendingNotification{RecipientType: "student", RecipientID: uuid.New(), Type: "achievement.verified"}
+	if err := d.Enqueue(n); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.queue) != 0 || len(repo.rows) != 1 {
+		t.Fatalf("antrean = %d, baris = %d, want 0 dan 1", len(d.queue), len(repo.rows))
+	}
+	for _, row := range repo.rows {
+		if row.NextAttemptAt.After(time.Now()) {
+			t.Fatalf("baris ter-lease sampai %s, want langsung jatuh tempo", row.NextAttemptAt)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OutboxPublisher mengirim 1 event outbox ke tujuan (notifikasi, webhook, dll).
+// Keberhasilan dicatat per publisher (Name), jadi retry hanya mengulang publisher yang
+// gagal. Crash di antara Publish dan pencatatan tetap bisa mengirim ulang; tujuan
+// wajib men-dedup memakai event.ID sebagai idempotency key.
+// Publish hanya boleh mengembalikan nil setelah event tersimpan secara durable di tujuan.
+type OutboxPublisher interface {
+	Name() string
+	Publish(ctx context.Context, ev model.OutboxEvent) error
+}
+
+// OutboxService meng-handle endpoint admin untuk memantau & me-retry event outbox.
+type OutboxService interface {
+	ListOutboxEvents(ctx *gin.Context) // GET  /api/v1/admin/outbox
+	RetryOutboxEvent(ctx *gin.Context) // POST /api/v1/admin/outbox/:id/retry
+}
+
+// outboxIdempotencyKey adalah key yang dikirim ke tujuan untuk event outbox.
+func outboxIdempotencyKey(ev model.OutboxEvent) string {
+	return "outbox:" + ev.ID.String()
+}
+
+// NotificationPublisher menerjemahkan event status prestasi menjadi notifikasi
+// dan memasukkannya ke antrean notifikasi.
+type NotificationPublisher struct {
+	Queue NotificationQueue
+}
+
+// Name memenuhi interface OutboxPublisher.
+func (NotificationPublisher) Name() string { return "notification" }
+
+// Publish memenuhi interface OutboxPublisher.
+func (p NotificationPublisher) Publish(_ context.Context, ev model.OutboxEvent) error {
+	var payload repository.AchievementStatusEvent
+	if err := json.Unmarshal([]byte(ev.Payload), &payload); err != nil {
+		return fmt.Errorf("payload outbox tidak valid: %w", err)
+	}
+
+	if payload.Imported {
+		return nil // keputusan historis hasil impor tidak dinotifikasikan ulang
+	}
+
+	var recipientType, message string
+	var recipientID uuid.UUID
+	switch ev.EventType {
+	case "achievement.submitted":
+		if payload.AdvisorID == nil {
+			return nil // mahasiswa belum punya dosen wali: tidak ada penerima
+		}
+		recipientType, recipientID = "lecturer", *payload.AdvisorID
+		message = "Prestasi baru menunggu verifikasi Anda"
+	case "achievement.verified":
+		recipientType, recipientID = "student", payload.StudentID
+		message = "Prestasi Anda telah diverifikasi"
+	case "achievement.rejected":
+		recipientType, recipientID = "student", payload.StudentID
+		message = "Prestasi Anda ditolak"
+		if payload.RejectionCategory != nil {
+			if label := model.RejectionCategoryLabel(*payload.RejectionCategory); label != "" {
+				message += " (" + label + ")"
+			}
+		}
+		if payload.RejectionNote != nil {
+			message += ": " + *payload.RejectionNote
+		}
+	case "achievement.expired", "achievement.draft":
+		if !payload.Expired {
+			return nil // draft karena ditarik sendiri oleh mahasiswa
+		}
+		recipientType, recipientID = "student", payload.StudentID
+		message = "Pengajuan prestasi Anda kedaluwarsa karena belum diverifikasi, silakan ajukan kembali"
+	default:
+		return nil // event lain tidak menghasilkan notifikasi
+	}
+
+	// Enqueue menyimpan notifikasi ke pending_notifications lebih dulu; jika gagal, event
+	// outbox tidak di-ack dan di-retry, sehingga crash tidak menghilangkan notifikasi.
+	key := outboxIdempotencyKey(ev)
+	return p.Queue.Enqueue(model.PendingNotification{
+		RecipientType:  recipientType,
+		RecipientID:    recipientID,
+		Type:           ev.EventType,
+		AchievementID:  &payload.AchievementID,
+		Message:        message,
+		IdempotencyKey: &key,
+	})
+}
+
+// WebhookPublisher mengirim event sebagai POST JSON ke URL webhook (mis. portal fakultas)
+// dengan header Idempotency-Key. Respons non-2xx dianggap gagal dan di-retry.
+type WebhookPublisher struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name memenuhi interface OutboxPublisher.
+func (WebhookPublisher) Name() string { return "webhook" }
+
+// Publish memenuhi interface OutboxPublisher.
+func (p WebhookPublisher) Publish(ctx context.Context, ev model.OutboxEvent) error {
+	body, err := json.Marshal(map[string]any{
+		"id":        ev.ID,
+		"type":      ev.EventType,
+		"payload":   json.RawMessage(ev.Payload),
+		"createdAt": ev.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", outboxIdempotencyKey(ev))
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook merespons %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OutboxDispatcher mem-poll outbox_events yang belum terkirim, mengirimnya ke semua
+// publisher, lalu mengisi dispatched_at. Event yang gagal di-retry dengan jeda bertambah
+// sampai batas percobaan, lalu ditandai failed dan menunggu retry manual oleh admin.
+type OutboxDispatcher struct {
+	repo       repository.OutboxRepository
+	auditRepo  repository.AuditRepository
+	publishers []OutboxPublisher
+	pollEvery  time.Duration
+	batchSize  int
+	lease      time.Duration
+}
+
+// NewOutboxDispatcher membuat dispatcher dari env:
+// OUTBOX_POLL_INTERVAL (2s), OUTBOX_BATCH_SIZE (50). OUTBOX_MAX_ATTEMPTS (10) dan
+// OUTBOX_RETRY_DELAY (30s, dikali jumlah percobaan) dibaca dari utils.Runtime() (bisa di-reload).
+func NewOutboxDispatcher(repo repository.OutboxRepository, auditRepo repository.AuditRepository, publishers ...OutboxPublisher) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		repo:       repo,
+		auditRepo:  auditRepo,
+		publishers: publishers,
+		pollEvery:  utils.GetEnvDuration("OUTBOX_POLL_INTERVAL", 2*time.Second),
+		batchSize:  max(utils.GetEnvInt("OUTBOX_BATCH_SIZE", 50), 1),
+		lease:      5 * time.Minute,
+	}
+}
+
+// Start menjalankan loop polling sampai ctx dibatalkan.
+func (d *OutboxDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.pollEvery)
+		defer ticker.Stop()
+		for {
+			if _, err := d.DispatchBatch(ctx); err != nil {
+				log.Printf("⚠️  gagal memproses outbox: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DispatchBatch memproses 1 batch event yang jatuh tempo, berurutan sesuai created_at.
+// Event ditandai dispatched hanya setelah semua publisher berhasil; jika proses mati
+// di tengah batch, event yang belum ditandai diambil ulang setelah lease habis.
+// Publisher yang sudah tercatat berhasil untuk sebuah event tidak dipanggil lagi.
+func (d *OutboxDispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	events, err := d.repo.LeaseDue(time.Now(), d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+	deliveries, err := d.repo.FindDeliveries(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := 0
+	for _, ev := range events {
+		if ctx.Err() != nil {
+			return dispatched, ctx.Err()
+		}
+		if err := d.publish(ctx, ev, deliveries[ev.ID]); err != nil {
+			d.markFailed(ev, err)
+			continue
+		}
+		if err := d.repo.MarkDispatched(ev.ID, time.Now()); err != nil {
+			// Event akan dikirim ulang setelah lease; tujuan men-dedup via idempotency key.
+			log.Printf("⚠️  event outbox %s terkirim tetapi gagal ditandai: %v", ev.ID, err)
+			continue
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// publish mengirim ev ke publisher yang belum tercatat di delivered, lalu mencatat
+// setiap yang berhasil. Gagal mencatat dianggap gagal agar event di-retry (tujuan men-dedup).
+func (d *OutboxDispatcher) publish(ctx context.Context, ev model.OutboxEvent, delivered map[string]bool) error {
+	var errs []error
+	for _, p := range d.publishers {
+		if delivered[p.Name()] {
+			continue
+		}
+		if err := p.Publish(ctx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		if err := d.repo.MarkDelivered(ev.ID, p.Name(), time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: gagal mencatat pengiriman: %w", p.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *OutboxDispatcher) markFailed(ev model.OutboxEvent, cause error) {
+	cfg := utils.Runtime()
+	attempts := ev.Attempts + 1
+	failed := attempts >= cfg.OutboxMaxAttempts
+	next := time.Now().Add(cfg.OutboxRetryDelay * time.Duration(attempts))
+	if err := d.repo.MarkAttemptFailed(ev.ID, attempts, cause.Error(), next, failed); err != nil {
+		log.Printf("⚠️  gagal mencatat kegagalan event outbox %s: %v", ev.ID, err)
+	}
+}
+
+// ===============================================================
+//  GET /api/v1/admin/outbox?failed=true&limit=100
+//  Admin: daftar event outbox yang belum terkirim (atau hanya yang failed).
+// ===============================================================
+func (d *OutboxDispatcher) ListOutboxEvents(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	onlyFailed := ctx.Query("failed") == "true"
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	events, err := d.repo.FindUndispatched(onlyFailed, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil event outbox", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil event outbox", events))
+}
+
+// ===============================================================
+//  POST /api/v1/admin/outbox/:id/retry
+//  Admin: jadwalkan ulang event yang belum terkirim agar segera dikirim.
+// ===============================================================
+func (d *OutboxDispatcher) RetryOutboxEvent(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID event tidak valid", err.Error(), nil))
+		return
+	}
+
+	ev, err := d.repo.Retry(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrOutboxEventNotFound) {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("Event tidak ditemukan atau sudah terkirim", "not_found", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menjadwalkan ulang event", err.Error(), nil))
+		return
+	}
+
+	if actorID, err := getUserIDFromContext(ctx); err == nil {
+		_ = d.auditRepo.Record(ctx, &actorID, "outbox.retry", "outbox_event", id.String(), map[string]any{
+			"eventType": ev.EventType,
+		})
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Event dijadwalkan ulang", ev))
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeOutboxRepo adalah OutboxRepository di memori: LeaseDue selalu mengembalikan
+// event yang belum terkirim (lease & jadwal retry diabaikan agar test deterministik).
+type fakeOutboxRepo struct {
+	mu         sync.Mutex
+	events     []model.OutboxEvent
+	deliveries map[uuid.UUID]map[string]bool
+}
+
+func newFakeOutboxRepo(events ...model.OutboxEvent) *fakeOutboxRepo {
+	return &fakeOutboxRepo{events: events, deliveries: map[uuid.UUID]map[string]bool{}}
+}
+
+func (r *fakeOutboxRepo) LeaseDue(time.Time, int, time.Duration) ([]model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.OutboxEvent
+	for _, ev := range r.events {
+		if ev.DispatchedAt == nil && !ev.Failed {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeOutboxRepo) FindDeliveries(ids []uuid.UUID) (map[uuid.UUID]map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := map[uuid.UUID]map[string]bool{}
+	for _, id := range ids {
+		for name := range r.deliveries[id] {
+			if out[id] == nil {
+				out[id] = map[string]bool{}
+			}
+			out[id][name] = true
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeOutboxRepo) MarkDelivered(id uuid.UUID, publisher string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deliveries[id] == nil {
+		r.deliveries[id] = map[string]bool{}
+	}
+	r.deliveries[id][publisher] = true
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkDispatched(id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.events {
+		if r.events[i].ID == id {
+			r.events[i].DispatchedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkAttemptFailed(id uuid.UUID, attempts int, lastError string, _ time.Time, failed bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.events {
+		if r.events[i].ID == id {
+			r.events[i].Attempts, r.events[i].LastError, r.events[i].Failed = attempts, lastError, failed
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) FindUndispatched(bool, int) ([]model.OutboxEvent, error) { return nil, nil }
+
+func (r *fakeOutboxRepo) Retry(uuid.UUID) (*model.OutboxEvent, error) {
+	return nil, repository.ErrOutboxEventNotFound
+}
+
+// countingPublisher gagal sebanyak failures kali pertama, lalu berhasil.
+type countingPublisher struct {
+	name     string
+	failures int
+	calls    int
+}
+
+func (p *countingPublisher) Name() string { return p.name }
+
+func (p *countingPublisher) Publish(context.Context, model.OutboxEvent) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("tujuan tidak tersedia")
+	}
+	return nil
+}
+
+func TestOutboxRetryOnlyRepeatsFailedPublisher(t *testing.T) {
+	ev := model.OutboxEvent{ID: uuid.New(), EventType: "achievement.verified", Payload: "{}"}
+	repo := newFakeOutboxRepo(ev)
+	notif := &countingPublisher{name: "notification"}
+	hook := &countingPublisher{name: "webhook", failures: 1}
+	d := NewOutboxDispatcher(repo, nil, notif, hook)
+
+	if n, err := d.DispatchBatch(context.Background()); err != nil || n != 0 {
+		t.Fatalf("batch 1: got (%d, %v), want (0, nil)", n, err)
+	}
+	if n, err := d.DispatchBatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("batch 2: got (%d, %v), want (1, nil)", n, err)
+	}
+
+	if notif.calls != 1 {
+		t.Fatalf("publisher notifikasi dipanggil %d kali, want 1 (tidak boleh duplikat)", notif.calls)
+	}
+	if hook.calls != 2 {
+		t.Fatalf("publisher webhook dipanggil %d kali, want 2", hook.calls)
+	}
+	if repo.events[0].DispatchedAt == nil {
+		t.Fatal("event belum ditandai dispatched setelah semua publisher berhasil")
+	}
+}
+
+// fakeNotificationRepo adalah NotificationRepository di memori dengan dedup idempotency key.
+type fakeNotificationRepo struct {
+	mu      sync.Mutex
+	rows    map[uuid.UUID]model.PendingNotification
+	keys    map[string]bool
+	saveErr error
+}
+
+func newFakeNotificationRepo() *fakeNotificationRepo {
+	return &fakeNotificationRepo{rows: map[uuid.UUID]model.PendingNotification{}, keys: map[string]bool{}}
+}
+
+func (r *fakeNotificationRepo) Save(n *model.PendingNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	if n.ID == uuid.Nil {
+		if n.IdempotencyKey != nil {
+			if r.keys[*n.IdempotencyKey] {
+				return nil // ON CONFLICT DO NOTHING: ID tetap kosong
+			}
+			r.keys[*n.IdempotencyKey] = true
+		}
+		n.ID = uuid.New()
+	}
+	r.rows[n.ID] = *n
+	return nil
+}
+
+func (r *fakeNotificationRepo) Delete(id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *fakeNotificationRepo) LeaseDue(now time.Time, limit int, lease time.Duration) ([]model.PendingNotification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.PendingNotification
+	for id, row := range r.rows {
+		if len(out) < limit && !row.Failed && !row.NextAttemptAt.After(now) {
+			row.NextAttemptAt = now.Add(lease)
+			r.rows[id] = row
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeNotificationRepo) CountPending() (int64, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.rows)), 0, nil
+}
+
+func TestNotificationPublisherPersistsBeforeAck(t *testing.T) {
+	studentID := uuid.New()
+	ev := model.OutboxEvent{
+		ID:        uuid.New(),
+		EventType: "achievement.verified",
+		Payload:   `{"achievementId":"` + uuid.NewString() + `","studentId":"` + studentID.String() + `"}`,
+	}
+
+	tests := []struct {
+		name     string
+		saveErr  error
+		wantErr  bool
+		wantRows int
+	}{
+		{name: "tersimpan lalu di-ack", wantRows: 1},
+		{name: "gagal simpan tidak di-ack", saveErr: errors.New("db down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeNotificationRepo()
+			repo.saveErr = tt.saveErr
+			d := NewNotificationDispatcher(repo, nil) // worker tidak dijalankan: simulasi crash sebelum kirim
+
+			err := NotificationPublisher{Queue: d}.Publish(context.Background(), ev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(repo.rows) != tt.wantRows {
+				t.Fatalf("baris pending = %d, want %d", len(repo.rows), tt.wantRows)
+			}
+			for _, row := range repo.rows {
+				if row.RecipientID != studentID || !row.NextAttemptAt.After(time.Now()) {
+					t.Fatalf("baris pending tidak sesuai: %+v", row)
+				}
+			}
+		})
+	}
+}
+
+func TestNotificationEnqueueDeduplicatesRetriedEvent(t *testing.T) {
+	repo := newFakeNotificationRepo()
+	d := NewNotificationDispatcher(repo, nil)
+	key := "outbox:" + uuid.NewString()
+	n := model.PendingNotification{RecipientType: "student", RecipientID: uuid.New(), Type: "achievement.verified", IdempotencyKey: &key}
+
+	for i := 0; i < 2; i++ {
+		if err := d.Enqueue(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(d.queue) != 1 || len(repo.rows) != 1 {
+		t.Fatalf("antrean = %d, baris = %d, want 1 dan 1", len(d.queue), len(repo.rows))
+	}
+}
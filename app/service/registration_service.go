@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegistrationService meng-handle registrasi mandiri mahasiswa dan antrean persetujuannya.
+type RegistrationService interface {
+	Register(ctx *gin.Context)            // POST /api/v1/auth/register
+	GetRegistrations(ctx *gin.Context)    // GET  /api/v1/admin/registrations
+	ApproveRegistration(ctx *gin.Context) // POST /api/v1/admin/registrations/:id/approve
+	RejectRegistration(ctx *gin.Context)  // POST /api/v1/admin/registrations/:id/reject
+}
+
+type registrationService struct {
+	repo      repository.RegistrationRepository
+	auditRepo repository.AuditRepository
+}
+
+// NewRegistrationService membuat instance RegistrationService.
+func NewRegistrationService(repo repository.RegistrationRepository, auditRepo repository.AuditRepository) RegistrationService {
+	return &registrationService{repo: repo, auditRepo: auditRepo}
+}
+
+// registrationConflictMessages adalah pesan per field untuk registrasi duplikat (409).
+var registrationConflictMessages = map[string]string{
+	"username":  "Username sudah dipakai",
+	"email":     "Email sudah terdaftar",
+	"studentId": "NIM sudah terdaftar",
+}
+
+// ===============================================================
+//  POST /api/v1/auth/register (tanpa JWT)
+//  Body: { "username", "email", "password", "fullName",
+//          "studentId" (NIM), "programStudy", "academicYear" }
+//  Membuat akun mahasiswa nonaktif (pending_approval) + profil mahasiswa.
+//  Akun baru bisa login setelah disetujui admin.
+// ===============================================================
+func (s *registrationService) Register(ctx *gin.Context) {
+	var input struct {
+		Username     string `json:"username" binding:"required,min=3,max=50"`
+		Email        string `json:"email" binding:"required,email,max=255"`
+		Password     string `json:"password" binding:"required,max=72"`
+		FullName     string `json:"fullName" binding:"required,max=255"`
+		StudentID    string `json:"studentId" binding:"required,max=20"`
+		ProgramStudy string `json:"programStudy" binding:"required,max=100"`
+		AcademicYear string `json:"academicYear" binding:"required,max=10"`
+	}
+	if err := utils.BindStrictJSON(ctx, &input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input registrasi tidak valid", err.Error(), nil)
+		return
+	}
+	input.Username = strings.TrimSpace(input.Username)
+	input.Email = strings.TrimSpace(input.Email)
+	input.StudentID = strings.TrimSpace(input.StudentID)
+	if strings.Contains(input.Username, "@") {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Username tidak boleh mengandung @", "invalid_username",
+			map[string]string{"username": "Username tidak boleh mengandung @"})
+		return
+	}
+
+	if failed := utils.ValidatePassword(input.Password, input.Username, input.Email); len(failed) > 0 {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Password tidak memenuhi kebijakan", "weak_password",
+			utils.PasswordPolicyDetails(failed))
+		return
+	}
+
+	conflicts, err := s.repo.FindConflicts(input.Username, input.Email, input.StudentID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memeriksa data registrasi", err.Error(), nil)
+		return
+	}
+	if len(conflicts) > 0 {
+		fields := map[string]string{}
+		for _, f := range conflicts {
+			fields[f] = registrationConflictMessages[f]
+		}
+		utils.RespondError(ctx, http.StatusConflict,
+			"Data registrasi sudah terdaftar", "duplicate", fields)
+		return
+	}
+
+	hash, err := utils.HashPassword(input.Password)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memproses password", err.Error(), nil)
+		return
+	}
+
+	user := &model.User{
+		Username:     input.Username,
+		Email:        input.Email,
+		PasswordHash: string(hash),
+		FullName:     input.FullName,
+	}
+	student := &model.Student{
+		StudentID:    input.StudentID,
+		ProgramStudy: input.ProgramStudy,
+		AcademicYear: input.AcademicYear,
+	}
+	if err := s.repo.CreatePending(user, student); err != nil {
+		// registrasi bersamaan dengan username/email yang sama lolos FindConflicts
+		// tetapi ditolak unique constraint: payload sama dengan hasil FindConflicts
+		var conflict *repository.RegistrationConflictError
+		if errors.As(err, &conflict) {
+			var fields map[string]string
+			if conflict.Field != "" {
+				fields = map[string]string{conflict.Field: registrationConflictMessages[conflict.Field]}
+			}
+			utils.RespondError(ctx, http.StatusConflict,
+				"Data registrasi sudah terdaftar", "duplicate", fields)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan registrasi", err.Error(), nil)
+		return
+	}
+
+	utils.RespondCreated(ctx,
+		"Registrasi berhasil, akun menunggu persetujuan admin", map[string]any{
+			"id":              user.ID,
+			"username":        user.Username,
+			"pendingApproval": true,
+		})
+}
+
+// ===============================================================
+//  GET /api/v1/admin/registrations?page=1&limit=10
+//  Admin: antrean registrasi mahasiswa yang menunggu persetujuan.
+// ===============================================================
+func (s *registrationService) GetRegistrations(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = repository.NormalizePagination(page, limit)
+
+	rows, total, err := s.repo.FindPending(page, limit)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil antrean registrasi", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil antrean registrasi", map[string]any{
+			"items": rows,
+			"meta": map[string]any{
+				"page":      page,
+				"limit":     limit,
+				"totalData": total,
+				"totalPage": (total + int64(limit) - 1) / int64(limit),
+			},
+		})
+}
+
+// ===============================================================
+//  POST /api/v1/admin/registrations/:id/approve
+//  Admin: aktifkan akun registrasi (user bisa login).
+// ===============================================================
+func (s *registrationService) ApproveRegistration(ctx *gin.Context) {
+	s.decideRegistration(ctx, "approve")
+}
+
+// ===============================================================
+//  POST /api/v1/admin/registrations/:id/reject
+//  Admin: tolak registrasi; akun & profil mahasiswanya dihapus.
+// ===============================================================
+func (s *registrationService) RejectRegistration(ctx *gin.Context) {
+	s.decideRegistration(ctx, "reject")
+}
+
+func (s *registrationService) decideRegistration(ctx *gin.Context, decision string) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID registrasi tidak valid", err.Error(), nil)
+		return
+	}
+
+	var reg *repository.PendingRegistration
+	if decision == "approve" {
+		reg, err = s.repo.Approve(userID)
+	} else {
+		reg, err = s.repo.Reject(userID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRegistrationNotFound) {
+			utils.RespondError(ctx, http.StatusNotFound,
+				"Registrasi tidak ditemukan atau sudah diputuskan", "not_found", nil)
+			return
+		}
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memproses registrasi", err.Error(), nil)
+		return
+	}
+
+	actorID, _ := getUserIDFromContext(ctx)
+	_ = s.auditRepo.Record(ctx, &actorID, "registration."+decision, "user", userID.String(), reg)
+
+	message := "Registrasi disetujui, akun sudah aktif"
+	if decision == "reject" {
+		message = "Registrasi ditolak, akun dihapus"
+	}
+	utils.RespondOK(ctx, message, reg)
+}
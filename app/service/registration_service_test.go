@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeRegistrationRepo: FindConflicts mengembalikan conflicts, CreatePending mengembalikan createErr.
+type fakeRegistrationRepo struct {
+	repository.RegistrationRepository
+	conflicts []string
+	createErr error
+}
+
+func (r *fakeRegistrationRepo) FindConflicts(string, string, string) ([]string, error) {
+	return r.conflicts, nil
+}
+
+func (r *fakeRegistrationRepo) CreatePending(*model.User, *model.Student) error {
+	return r.createErr
+}
+
+func TestRegisterConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		conflicts  []string
+		createErr  error
+		wantStatus int
+		wantFields map[string]any // nil = data kosong
+	}{
+		{name: "berhasil", wantStatus: http.StatusCreated},
+		{name: "terdeteksi sebelum insert", conflicts: []string{"email"}, wantStatus: http.StatusConflict,
+			wantFields: map[string]any{"email": "Email sudah terdaftar"}},
+		{name: "insert bersamaan: username", createErr: &repository.RegistrationConflictError{Field: "username"},
+			wantStatus: http.StatusConflict, wantFields: map[string]any{"username": "Username sudah dipakai"}},
+		{name: "insert bersamaan: email", createErr: &repository.RegistrationConflictError{Field: "email"},
+			wantStatus: http.StatusConflict, wantFields: map[string]any{"email": "Email sudah terdaftar"}},
+		{name: "insert bersamaan: constraint tidak dikenal", createErr: &repository.RegistrationConflictError{},
+			wantStatus: http.StatusConflict},
+		{name: "error lain", createErr: errors.New("koneksi terputus"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			svc := NewRegistrationService(&fakeRegistrationRepo{conflicts: tt.conflicts, createErr: tt.createErr}, nil)
+			r := gin.New()
+			r.POST("/register", svc.Register)
+
+			w, data := doJSON(t, r, http.MethodPost, "/register", "", map[string]any{
+				"username": "budi", "email": "budi@kampus.ac.id", "password": "rahasia123", "fullName": "Budi",
+				"studentId": "2024001", "programStudy": "Informatika", "academicYear": "2024",
+			})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d; body %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusConflict && !reflect.DeepEqual(data, tt.wantFields) {
+				t.Fatalf("field error %v, want %v", data, tt.wantFields)
+			}
+		})
+	}
+}
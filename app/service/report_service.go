@@ -1,10 +1,14 @@
 package service
 
 import (
-	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
+	"student-achievement-backend/middleware"
 	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -24,19 +28,44 @@ type ReportService interface {
 	// - Dosen Wali: hanya student bimbingan
 	// - Mahasiswa: hanya dirinya sendiri (id harus = claim.studentId)
 	GetStudentStatistics(ctx *gin.Context)
+
+	// GetTargetProgress (admin): progres target prestasi tahunan per mahasiswa,
+	// filter ?programStudy=...&year=2024 (tahun awal tahun akademik).
+	GetTargetProgress(ctx *gin.Context)
+	// GetTargets / UpdateTarget (admin): pengaturan target global & per program studi.
+	GetTargets(ctx *gin.Context)
+	UpdateTarget(ctx *gin.Context)
+
+	// GetTurnaround (admin): waktu submit → keputusan dosen wali dalam hari kalender & hari kerja.
+	GetTurnaround(ctx *gin.Context)
 }
 
 // reportService implementasi konkrit ReportService.
 type reportService struct {
 	reportRepo   repository.ReportRepository
 	lecturerRepo repository.LecturerRepository
+	studentRepo  repository.StudentRepository
+	targetRepo   repository.TargetRepository
+	achRepo      repository.AchievementRepository
+	holidayRepo  repository.HolidayRepository
 }
 
 // NewReportService membuat instance baru reportService.
-func NewReportService(reportRepo repository.ReportRepository, lecturerRepo repository.LecturerRepository) ReportService {
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	lecturerRepo repository.LecturerRepository,
+	studentRepo repository.StudentRepository,
+	targetRepo repository.TargetRepository,
+	achRepo repository.AchievementRepository,
+	holidayRepo repository.HolidayRepository,
+) ReportService {
 	return &reportService{
 		reportRepo:   reportRepo,
 		lecturerRepo: lecturerRepo,
+		studentRepo:  studentRepo,
+		targetRepo:   targetRepo,
+		achRepo:      achRepo,
+		holidayRepo:  holidayRepo,
 	}
 }
 
@@ -50,40 +79,79 @@ func getUUIDFromContext(ctx *gin.Context, key string) (uuid.UUID, bool) {
 	return uuid.Nil, false
 }
 
+// withPeriodLabels mengisi label periode (bulan & semester) sesuai Accept-Language,
+// sehingga semua konsumen memakai label yang sama dari server.
+func withPeriodLabels(ctx *gin.Context, stats *repository.ReportResult) {
+	if stats == nil {
+		return
+	}
+	keys := make([]string, 0, len(stats.TotalByPeriod))
+	for k := range stats.TotalByPeriod {
+		keys = append(keys, k)
+	}
+	stats.PeriodLabels = utils.PeriodLabels(keys, utils.LocaleFromAcceptLanguage(ctx.GetHeader("Accept-Language")))
+}
+
 // GetGlobalStatistics mengembalikan statistik prestasi sesuai role pemanggil.
 // - Admin      → semua mahasiswa
 // - Dosen Wali → hanya mahasiswa bimbingan
 // - Mahasiswa  → hanya prestasi dirinya
 func (s *reportService) GetGlobalStatistics(ctx *gin.Context) {
 	role := ctx.GetString("role")
+	if apiKeyCan(ctx, model.PermissionReportRead) {
+		role = middleware.APIKeyRole
+	}
+
+	filter, ok := reportScope(ctx, s.lecturerRepo, role, "statistik global")
+	if !ok {
+		return
+	}
+
+	stats, err := s.reportRepo.GetStatistics(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghitung statistik prestasi", err.Error(), nil))
+		return
+	}
+
+	withPeriodLabels(ctx, stats)
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil statistik prestasi", stats))
+}
 
+// reportScope menentukan scope data (ReportFilter) sesuai role pemanggil; what dipakai di pesan 403.
+// - admin / API key → filter kosong (semua mahasiswa)
+// - dosen_wali      → mahasiswa bimbingan
+// - mahasiswa       → dirinya sendiri
+// ok=false berarti response error sudah dikirim.
+func reportScope(ctx *gin.Context, lecturerRepo repository.LecturerRepository, role, what string) (repository.ReportFilter, bool) {
 	filter := repository.ReportFilter{}
 
 	switch role {
-	case "admin":
-		// admin: filter kosong → semua data (tidak perlu isi StudentIDs)
+	case "admin", middleware.APIKeyRole:
+		// admin & integrasi (API key report:read): filter kosong → semua data (tidak perlu isi StudentIDs)
 
 	case "dosen_wali":
-		// dosen wali: statistik hanya untuk mahasiswa bimbingan
+		// dosen wali: hanya mahasiswa bimbingan
 		userID, ok := getUUIDFromContext(ctx, "userID")
 		if !ok || userID == uuid.Nil {
 			ctx.JSON(http.StatusUnauthorized,
 				utils.BuildResponseFailed("Autentikasi dosen wali tidak valid", "no_user_id", nil))
-			return
+			return filter, false
 		}
 
-		lecturer, err := s.lecturerRepo.FindByUserID(userID)
+		lecturerID, err := lecturerIDFromContext(ctx, lecturerRepo, userID)
 		if err != nil {
 			ctx.JSON(http.StatusForbidden,
 				utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
-			return
+			return filter, false
 		}
 
-		adviseeIDs, err := s.lecturerRepo.GetAdviseeStudentIDs(lecturer.ID)
+		adviseeIDs, err := lecturerRepo.GetAdviseeStudentIDs(ctx.Request.Context(), lecturerID)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError,
 				utils.BuildResponseFailed("Gagal mengambil daftar mahasiswa bimbingan", err.Error(), nil))
-			return
+			return filter, false
 		}
 
 		// Konversi []uuid.UUID → []string (UUID string)
@@ -94,30 +162,22 @@ func (s *reportService) GetGlobalStatistics(ctx *gin.Context) {
 		filter.StudentIDs = ids
 
 	case "mahasiswa":
-		// mahasiswa: statistik hanya miliknya sendiri
+		// mahasiswa: hanya miliknya sendiri
 		studentID, ok := getUUIDFromContext(ctx, "studentID")
 		if !ok || studentID == uuid.Nil {
 			ctx.JSON(http.StatusUnauthorized,
 				utils.BuildResponseFailed("Autentikasi mahasiswa tidak valid", "no_student_id", nil))
-			return
+			return filter, false
 		}
 		filter.StudentIDs = []string{studentID.String()}
 
 	default:
 		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("Role tidak diizinkan mengakses statistik global", "forbidden_role", nil))
-		return
+			utils.BuildResponseFailed("Role tidak diizinkan mengakses "+what, "forbidden_role", nil))
+		return filter, false
 	}
 
-	stats, err := s.reportRepo.GetStatistics(context.Background(), filter)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError,
-			utils.BuildResponseFailed("Gagal menghitung statistik prestasi", err.Error(), nil))
-		return
-	}
-
-	ctx.JSON(http.StatusOK,
-		utils.BuildResponseSuccess("Berhasil mengambil statistik prestasi", stats))
+	return filter, true
 }
 
 // GetStudentStatistics mengembalikan statistik untuk 1 mahasiswa tertentu.
@@ -135,10 +195,14 @@ func (s *reportService) GetStudentStatistics(ctx *gin.Context) {
 		return
 	}
 
+	if apiKeyCan(ctx, model.PermissionReportRead) {
+		role = middleware.APIKeyRole
+	}
+
 	// Role-based access control
 	switch role {
-	case "admin":
-		// admin boleh lihat siapa saja
+	case "admin", middleware.APIKeyRole:
+		// admin & integrasi (API key report:read) boleh lihat siapa saja
 
 	case "dosen_wali":
 		// pastikan student ini adalah advisee dosen wali tsb
@@ -148,14 +212,14 @@ func (s *reportService) GetStudentStatistics(ctx *gin.Context) {
 				utils.BuildResponseFailed("Autentikasi dosen wali tidak valid", "no_user_id", nil))
 			return
 		}
-		lecturer, err := s.lecturerRepo.FindByUserID(userID)
+		lecturerID, err := lecturerIDFromContext(ctx, s.lecturerRepo, userID)
 		if err != nil {
 			ctx.JSON(http.StatusForbidden,
 				utils.BuildResponseFailed("Data dosen wali tidak ditemukan", err.Error(), nil))
 			return
 		}
 
-		isAdvisor, err := s.lecturerRepo.IsAdvisorOf(lecturer.ID, studentID)
+		isAdvisor, err := s.lecturerRepo.IsAdvisorOf(lecturerID, studentID)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError,
 				utils.BuildResponseFailed("Gagal memeriksa relasi dosen wali", err.Error(), nil))
@@ -191,13 +255,204 @@ func (s *reportService) GetStudentStatistics(ctx *gin.Context) {
 	filter := repository.ReportFilter{
 		StudentIDs: []string{studentID.String()},
 	}
-	stats, err := s.reportRepo.GetStatistics(context.Background(), filter)
+	stats, err := s.reportRepo.GetStatistics(ctx.Request.Context(), filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError,
 			utils.BuildResponseFailed("Gagal menghitung statistik prestasi mahasiswa", err.Error(), nil))
 		return
 	}
 
+	// Progres target tahunan (tahun akademik berjalan)
+	if st, err := s.studentRepo.FindByID(studentID); err == nil {
+		progress, err := loadTargetProgress(s.targetRepo, []model.Student{*st}, utils.AcademicYearOf(time.Now()))
+		if err == nil {
+			p := progress[studentID]
+			stats.TargetProgress = &p
+		}
+	}
+
+	withPeriodLabels(ctx, stats)
 	ctx.JSON(http.StatusOK,
 		utils.BuildResponseSuccess("Berhasil mengambil statistik prestasi mahasiswa", stats))
 }
+
+// GetTargetProgress (admin) — GET /api/v1/reports/target-progress
+// Query: ?programStudy=Teknik%20Informatika&year=2024&behindOnly=true
+func (s *reportService) GetTargetProgress(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	year := utils.AcademicYearOf(time.Now())
+	if y := ctx.Query("year"); y != "" {
+		startYear, err := strconv.Atoi(y)
+		if err != nil || startYear < 1900 || startYear > 9999 {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Parameter year tidak valid", "invalid_year", nil))
+			return
+		}
+		year = utils.AcademicYearStarting(startYear, time.Local)
+	}
+
+	students, err := s.studentRepo.FindAll()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil daftar mahasiswa", err.Error(), nil))
+		return
+	}
+
+	if program := strings.TrimSpace(ctx.Query("programStudy")); program != "" {
+		filtered := students[:0]
+		for _, st := range students {
+			if strings.EqualFold(st.ProgramStudy, program) {
+				filtered = append(filtered, st)
+			}
+		}
+		students = filtered
+	}
+
+	progress, err := loadTargetProgress(s.targetRepo, students, year)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menghitung progres target", err.Error(), nil))
+		return
+	}
+
+	behindOnly := ctx.Query("behindOnly") == "true"
+	items := make([]map[string]any, 0, len(students))
+	for _, st := range students {
+		p := progress[st.ID]
+		if behindOnly && p.BehindBy == 0 {
+			continue
+		}
+		items = append(items, map[string]any{
+			"studentId":     st.ID,
+			"nim":           st.StudentID,
+			"programStudy":  st.ProgramStudy,
+			"target":        p.Target,
+			"verifiedCount": p.VerifiedCount,
+			"behindBy":      p.BehindBy,
+		})
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil progres target prestasi", map[string]any{
+			"academicYear": year,
+			"items":        items,
+		}))
+}
+
+// GetTargets (admin) — GET /api/v1/reports/targets
+func (s *reportService) GetTargets(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	rows, err := s.targetRepo.FindAll()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil pengaturan target", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil pengaturan target", map[string]any{
+			"defaultVerifiedPerYear": newTargetResolver(rows).global,
+			"targets":                rows,
+		}))
+}
+
+// UpdateTarget (admin) — PUT /api/v1/reports/targets
+// Body: { "programStudy": "" (global) | "<nama prodi>", "verifiedPerYear": 2 }
+func (s *reportService) UpdateTarget(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input struct {
+		ProgramStudy    string `json:"programStudy"`
+		VerifiedPerYear *int   `json:"verifiedPerYear" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+	if *input.VerifiedPerYear < 0 {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Target tidak boleh negatif", "invalid_target", nil))
+		return
+	}
+
+	t, err := s.targetRepo.Upsert(strings.TrimSpace(input.ProgramStudy), *input.VerifiedPerYear)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyimpan target", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Target prestasi berhasil disimpan", t))
+}
+
+// GetTurnaround (admin) — GET /api/v1/reports/turnaround?from=2025-01-01&to=2025-07-01
+// Default: tahun akademik berjalan. Hari kerja mengabaikan Sabtu/Minggu & tabel holidays.
+func (s *reportService) GetTurnaround(ctx *gin.Context) {
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	year := utils.AcademicYearOf(time.Now())
+	from, to := year.Start, year.End
+	for param, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := ctx.Query(param); v != "" {
+			t, err := time.ParseInLocation(utils.HolidayDateFormat, v, time.Local)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest,
+					utils.BuildResponseFailed("Parameter "+param+" harus YYYY-MM-DD", err.Error(), nil))
+				return
+			}
+			*dst = t
+		}
+	}
+
+	refs, err := s.achRepo.FindDecidedBetween(from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil data verifikasi", err.Error(), nil))
+		return
+	}
+	holidays, err := s.holidayRepo.DateSet()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil kalender libur", err.Error(), nil))
+		return
+	}
+
+	var totalCal, totalWork, maxCal, maxWork int
+	for _, ref := range refs {
+		cal := utils.CalendarDaysBetween(*ref.SubmittedAt, *ref.VerifiedAt)
+		work := utils.WorkingDaysBetween(*ref.SubmittedAt, *ref.VerifiedAt, holidays)
+		totalCal += cal
+		totalWork += work
+		maxCal = max(maxCal, cal)
+		maxWork = max(maxWork, work)
+	}
+
+	var avgCal, avgWork float64
+	if n := len(refs); n > 0 {
+		avgCal = float64(totalCal) / float64(n)
+		avgWork = float64(totalWork) / float64(n)
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil menghitung turnaround verifikasi", map[string]any{
+			"from":            from,
+			"to":              to,
+			"decidedCount":    len(refs),
+			"avgCalendarDays": avgCal,
+			"avgWorkingDays":  avgWork,
+			"maxCalendarDays": maxCal,
+			"maxWorkingDays":  maxWork,
+		}))
+}
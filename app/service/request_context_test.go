@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// slowHistoryRepo menahan FindStatusLogs sampai context query dibatalkan.
+type slowHistoryRepo struct {
+	*fakeAchievementRepo
+	queryErr chan error
+}
+
+func (r *slowHistoryRepo) FindStatusLogs(ctx context.Context, _ string) ([]model.AchievementStatusLog, error) {
+	select {
+	case <-ctx.Done():
+		r.queryErr <- ctx.Err()
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		r.queryErr <- nil
+		return nil, nil
+	}
+}
+
+func TestRequestTimeoutCancelsRepositoryQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ref := &model.AchievementReference{ID: uuid.New(), Status: model.StatusSubmitted}
+	repo := &slowHistoryRepo{fakeAchievementRepo: newFakeAchievementRepo(ref), queryErr: make(chan error, 1)}
+	svc := NewAchievementService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	r := gin.New()
+	r.GET("/achievements/:id/history", middleware.Timeout(50*time.Millisecond), func(c *gin.Context) {
+		c.Set("role", "admin")
+		c.Set("userID", uuid.New())
+	}, svc.GetAchievementHistory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/achievements/"+ref.ID.String()+"/history", nil))
+
+	checkEnvelope(t, w)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status %d, want 504 (body %s)", w.Code, w.Body)
+	}
+	if err := <-repo.queryErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("context query = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionService meng-handle endpoint admin kebijakan retensi data.
+type RetentionService interface {
+	GetRetentionPolicies(ctx *gin.Context)  // GET /api/v1/admin/retention/policies
+	UpdateRetentionPolicy(ctx *gin.Context) // PUT /api/v1/admin/retention/policies/:class
+	PreviewAnonymization(ctx *gin.Context)  // GET /api/v1/admin/retention/preview
+}
+
+// RetentionJob menjalankan anonimisasi terjadwal mahasiswa yang sudah melewati masa
+// retensi setelah lulus (kebijakan "student_personal"), sekaligus meng-handle endpoint admin.
+type RetentionJob struct {
+	repo      repository.RetentionRepository
+	auditRepo repository.AuditRepository
+	every     time.Duration
+	batchSize int
+}
+
+// NewRetentionJob membuat job dari env: RETENTION_RUN_INTERVAL (24h), RETENTION_BATCH_SIZE (100).
+func NewRetentionJob(repo repository.RetentionRepository, auditRepo repository.AuditRepository) *RetentionJob {
+	return &RetentionJob{
+		repo:      repo,
+		auditRepo: auditRepo,
+		every:     utils.GetEnvDuration("RETENTION_RUN_INTERVAL", 24*time.Hour),
+		batchSize: max(utils.GetEnvInt("RETENTION_BATCH_SIZE", 100), 1),
+	}
+}
+
+// Start menjalankan RunOnce setiap interval sampai ctx dibatalkan.
+func (j *RetentionJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Printf("⚠️  job retensi gagal: %v", err)
+			}
+			if n > 0 {
+				log.Printf("🔒 job retensi: %d mahasiswa dianonimkan", n)
+			}
+		}
+	}()
+}
+
+// cutoff menghitung batas tanggal lulus untuk kelas data student_personal.
+func (j *RetentionJob) cutoff(now time.Time) (time.Time, int, error) {
+	policies, err := j.repo.FindPolicies()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	years := repository.DefaultRetentionYears[repository.RetentionClassStudentPersonal]
+	for _, p := range policies {
+		if p.DataClass == repository.RetentionClassStudentPersonal {
+			years = p.RetentionYears
+		}
+	}
+	return now.AddDate(-years, 0, 0), years, nil
+}
+
+// RunOnce menganonimkan semua mahasiswa yang jatuh tempo; mengembalikan jumlah yang diproses.
+func (j *RetentionJob) RunOnce(ctx context.Context) (int, error) {
+	cutoff, years, err := j.cutoff(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		candidates, err := j.repo.FindDueForAnonymization(cutoff, j.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(candidates) == 0 {
+			return total, nil
+		}
+
+		progressed := 0
+		for _, c := range candidates {
+			if ctx.Err() != nil {
+				return total, ctx.Err()
+			}
+			done, err := j.repo.AnonymizeStudent(ctx, c.StudentID)
+			if err != nil {
+				log.Printf("⚠️  gagal menganonimkan mahasiswa %s: %v", c.StudentID, err)
+				continue
+			}
+			progressed++
+			if done {
+				total++
+				// tanpa data pribadi di payload: audit hanya mencatat bahwa anonimisasi terjadi
+				_ = j.auditRepo.Record(ctx, nil, "student.anonymized", "student", c.StudentID.String(), map[string]any{
+					"retentionYears": years,
+				})
+			}
+		}
+		if progressed == 0 {
+			return total, errors.New("tidak ada mahasiswa yang berhasil dianonimkan pada batch ini")
+		}
+	}
+}
+
+// ===============================================================
+//  GET /api/v1/admin/retention/policies
+//  Admin: masa retensi per kelas data.
+// ===============================================================
+func (j *RetentionJob) GetRetentionPolicies(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	policies, err := j.repo.FindPolicies()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil kebijakan retensi", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil kebijakan retensi", policies))
+}
+
+// ===============================================================
+//  PUT /api/v1/admin/retention/policies/:class
+//  Body: { "retentionYears": 5 }
+// ===============================================================
+func (j *RetentionJob) UpdateRetentionPolicy(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var input struct {
+		RetentionYears int `json:"retentionYears" binding:"required,min=1,max=100"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	actorID, _ := getUserIDFromContext(ctx)
+	policy := &model.RetentionPolicy{
+		DataClass:      ctx.Param("class"),
+		RetentionYears: input.RetentionYears,
+		UpdatedBy:      &actorID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := j.repo.UpsertPolicy(policy); err != nil {
+		if errors.Is(err, repository.ErrUnknownDataClass) {
+			ctx.JSON(http.StatusNotFound,
+				utils.BuildResponseFailed("Kelas data tidak dikenal", "unknown_data_class", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyimpan kebijakan retensi", err.Error(), nil))
+		return
+	}
+
+	_ = j.auditRepo.Record(ctx, &actorID, "retention.policy_update", "retention_policy", policy.DataClass, map[string]any{
+		"retentionYears": policy.RetentionYears,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Kebijakan retensi diperbarui", policy))
+}
+
+// ===============================================================
+//  GET /api/v1/admin/retention/preview
+//  Admin (dry-run): mahasiswa yang akan dianonimkan pada run berikutnya.
+// ===============================================================
+func (j *RetentionJob) PreviewAnonymization(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	cutoff, years, err := j.cutoff(time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil kebijakan retensi", err.Error(), nil))
+		return
+	}
+
+	candidates, err := j.repo.FindDueForAnonymization(cutoff, 1000)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil daftar mahasiswa", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Daftar mahasiswa yang akan dianonimkan", gin.H{
+			"retentionYears":  years,
+			"graduatedBefore": cutoff,
+			"nextRunAffects":  candidates,
+		}))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeRetentionRepo: kandidat dihitung dari tanggal lulus; AnonymizeStudent menandai mahasiswa.
+type fakeRetentionRepo struct {
+	repository.RetentionRepository
+	graduated  map[uuid.UUID]time.Time
+	names      map[uuid.UUID]string
+	anonymized map[uuid.UUID]bool
+}
+
+func (r *fakeRetentionRepo) FindPolicies() ([]model.RetentionPolicy, error) {
+	return []model.RetentionPolicy{{DataClass: repository.RetentionClassStudentPersonal, RetentionYears: 5}}, nil
+}
+
+func (r *fakeRetentionRepo) FindDueForAnonymization(cutoff time.Time, limit int) ([]repository.RetentionCandidate, error) {
+	var out []repository.RetentionCandidate
+	for id, at := range r.graduated {
+		if !at.After(cutoff) && !r.anonymized[id] && len(out) < limit {
+			out = append(out, repository.RetentionCandidate{StudentID: id, FullName: r.names[id], GraduatedAt: at})
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRetentionRepo) AnonymizeStudent(_ context.Context, id uuid.UUID) (bool, error) {
+	if r.anonymized[id] {
+		return false, nil
+	}
+	r.anonymized[id] = true
+	return true, nil
+}
+
+// TestRetentionRunOnceAnonymizesOnlyDueStudents: hanya mahasiswa yang lulus melewati masa
+// retensi yang dianonimkan (beberapa batch), run ulang tidak memproses apa pun, dan audit
+// tidak memuat data pribadi.
+func TestRetentionRunOnceAnonymizesOnlyDueStudents(t *testing.T) {
+	now := time.Now()
+	repo := &fakeRetentionRepo{graduated: map[uuid.UUID]time.Time{}, names: map[uuid.UUID]string{}, anonymized: map[uuid.UUID]bool{}}
+	var due []uuid.UUID
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		repo.graduated[id] = now.AddDate(-6, -i, 0)
+		repo.names[id] = "Budi Santoso"
+		due = append(due, id)
+	}
+	recent := uuid.New()
+	repo.graduated[recent] = now.AddDate(-4, 0, 0)
+	audit := &fakeAuditRepo{}
+	job := &RetentionJob{repo: repo, auditRepo: audit, batchSize: 2}
+
+	n, err := job.RunOnce(context.Background())
+	if err != nil || n != len(due) {
+		t.Fatalf("RunOnce = %d, %v, want %d", n, err, len(due))
+	}
+	for _, id := range due {
+		if !repo.anonymized[id] {
+			t.Fatalf("mahasiswa %s (jatuh tempo) tidak dianonimkan", id)
+		}
+	}
+	if repo.anonymized[recent] {
+		t.Fatal("mahasiswa yang belum jatuh tempo ikut dianonimkan")
+	}
+
+	if n, err := job.RunOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("run ulang = %d, %v, want 0", n, err)
+	}
+
+	if len(audit.actions) != len(due) {
+		t.Fatalf("audit %v, want %d student.anonymized", audit.actions, len(due))
+	}
+	for i, action := range audit.actions {
+		raw, _ := json.Marshal(audit.payloads[i])
+		if action != "student.anonymized" || string(raw) != `{"retentionYears":5}` {
+			t.Fatalf("audit %s %s, want student.anonymized tanpa data pribadi", action, raw)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// Parameter perkiraan waktu review untuk mahasiswa.
+const (
+	reviewEstimateWindow     = 90 * 24 * time.Hour // keputusan yang dihitung: 90 hari terakhir
+	reviewEstimateMinSamples = 5                   // minimal keputusan agar median dipakai
+	reviewEstimateCacheTTL   = time.Hour
+)
+
+// Dasar perhitungan perkiraan (field "basis").
+const (
+	reviewBasisAdvisor = "advisor_history" // median keputusan dosen wali mahasiswa
+	reviewBasisGlobal  = "global_history"  // median semua keputusan
+	reviewBasisSLA     = "sla"             // REVIEW_SLA_DAYS (data historis belum cukup)
+)
+
+// ReviewEstimate adalah perkiraan lama review yang ditampilkan ke mahasiswa.
+// Selalu ditandai isEstimate=true: angka ini bukan tenggat atau janji.
+type ReviewEstimate struct {
+	EstimatedReviewDays float64 `json:"estimatedReviewDays"`
+	IsEstimate          bool    `json:"isEstimate"`
+	Basis               string  `json:"basis"`
+	SampleSize          int64   `json:"sampleSize"` // jumlah keputusan yang menjadi dasar (0 untuk sla)
+}
+
+type reviewTurnaroundEntry struct {
+	data     repository.ReviewTurnaround
+	cachedAt time.Time
+}
+
+// reviewEstimator menghitung ReviewEstimate dengan cache hasil query per dosen wali
+// (1 jam). Yang di-cache hasil query, bukan estimasinya, sehingga perubahan
+// REVIEW_SLA_DAYS lewat reload config langsung berlaku.
+type reviewEstimator struct {
+	repo    repository.AchievementRepository
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[uuid.UUID]reviewTurnaroundEntry // key lecturers.id (uuid.Nil = tanpa dosen wali)
+}
+
+func newReviewEstimator(repo repository.AchievementRepository) *reviewEstimator {
+	return &reviewEstimator{
+		repo:    repo,
+		ttl:     reviewEstimateCacheTTL,
+		now:     time.Now,
+		entries: map[uuid.UUID]reviewTurnaroundEntry{},
+	}
+}
+
+// Estimate mengembalikan perkiraan untuk mahasiswa dengan dosen wali advisorID (boleh nil).
+// Urutan: median dosen wali (>= 5 keputusan) → median global (>= 5 keputusan) → SLA.
+func (e *reviewEstimator) Estimate(advisorID *uuid.UUID) (ReviewEstimate, error) {
+	t, err := e.turnaround(advisorID)
+	if err != nil {
+		return ReviewEstimate{}, err
+	}
+
+	switch {
+	case advisorID != nil && t.AdvisorDecisions >= reviewEstimateMinSamples && t.AdvisorMedianDays != nil:
+		return newReviewEstimate(*t.AdvisorMedianDays, reviewBasisAdvisor, t.AdvisorDecisions), nil
+	case t.GlobalDecisions >= reviewEstimateMinSamples && t.GlobalMedianDays != nil:
+		return newReviewEstimate(*t.GlobalMedianDays, reviewBasisGlobal, t.GlobalDecisions), nil
+	default:
+		return newReviewEstimate(float64(utils.Runtime().ReviewSLADays), reviewBasisSLA, 0), nil
+	}
+}
+
+func (e *reviewEstimator) turnaround(advisorID *uuid.UUID) (repository.ReviewTurnaround, error) {
+	key := uuid.Nil
+	if advisorID != nil {
+		key = *advisorID
+	}
+
+	now := e.now()
+	e.mu.Lock()
+	entry, ok := e.entries[key]
+	e.mu.Unlock()
+	if ok && now.Sub(entry.cachedAt) < e.ttl {
+		return entry.data, nil
+	}
+
+	data, err := e.repo.FindReviewTurnaround(advisorID, now.Add(-reviewEstimateWindow))
+	if err != nil {
+		return repository.ReviewTurnaround{}, err
+	}
+	e.mu.Lock()
+	e.entries[key] = reviewTurnaroundEntry{data: data, cachedAt: now}
+	e.mu.Unlock()
+	return data, nil
+}
+
+// newReviewEstimate membulatkan ke 0,5 hari terdekat (minimal 0,5) agar tidak terkesan presisi.
+func newReviewEstimate(days float64, basis string, samples int64) ReviewEstimate {
+	return ReviewEstimate{
+		EstimatedReviewDays: math.Max(0.5, math.Round(days*2)/2),
+		IsEstimate:          true,
+		Basis:               basis,
+		SampleSize:          samples,
+	}
+}
+
+// reviewEstimateFor menghitung perkiraan untuk prestasi berstatus submitted milik studentID.
+// Kegagalan tidak menggagalkan request utama: ok=false dan field tidak ditampilkan.
+func (s *achievementService) reviewEstimateFor(ref *model.AchievementReference) (ReviewEstimate, bool) {
+	if ref.Status != model.StatusSubmitted {
+		return ReviewEstimate{}, false
+	}
+	student, err := s.userRepo.FindStudentByID(ref.StudentID)
+	if err != nil {
+		return ReviewEstimate{}, false
+	}
+	est, err := s.estimator.Estimate(student.AdvisorID)
+	if err != nil {
+		return ReviewEstimate{}, false
+	}
+	return est, true
+}
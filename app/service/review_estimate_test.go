@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeTurnaroundRepo mengembalikan statistik review tetap dan menghitung jumlah query.
+type fakeTurnaroundRepo struct {
+	repository.AchievementRepository
+	data  repository.ReviewTurnaround
+	err   error
+	calls int
+	since time.Time
+}
+
+func (r *fakeTurnaroundRepo) FindReviewTurnaround(_ *uuid.UUID, since time.Time) (repository.ReviewTurnaround, error) {
+	r.calls++
+	r.since = since
+	return r.data, r.err
+}
+
+func medianDays(d float64) *float64 { return &d }
+
+func TestReviewEstimateTiers(t *testing.T) {
+	setRuntimeEnv(t, map[string]string{"REVIEW_SLA_DAYS": "4"})
+	advisor := uuid.New()
+	tests := []struct {
+		name        string
+		advisorID   *uuid.UUID
+		data        repository.ReviewTurnaround
+		wantDays    float64
+		wantBasis   string
+		wantSamples int64
+	}{
+		{
+			name:      "median dosen wali",
+			advisorID: &advisor,
+			data:      repository.ReviewTurnaround{AdvisorMedianDays: medianDays(2.3), AdvisorDecisions: 5, GlobalMedianDays: medianDays(6), GlobalDecisions: 40},
+			wantDays:  2.5, wantBasis: reviewBasisAdvisor, wantSamples: 5,
+		},
+		{
+			name:      "keputusan dosen wali kurang → global",
+			advisorID: &advisor,
+			data:      repository.ReviewTurnaround{AdvisorMedianDays: medianDays(1), AdvisorDecisions: 4, GlobalMedianDays: medianDays(6.2), GlobalDecisions: 40},
+			wantDays:  6, wantBasis: reviewBasisGlobal, wantSamples: 40,
+		},
+		{
+			name:     "tanpa dosen wali → global",
+			data:     repository.ReviewTurnaround{AdvisorMedianDays: medianDays(1), AdvisorDecisions: 10, GlobalMedianDays: medianDays(3.8), GlobalDecisions: 5},
+			wantDays: 4, wantBasis: reviewBasisGlobal, wantSamples: 5,
+		},
+		{
+			name:      "data global kurang → SLA",
+			advisorID: &advisor,
+			data:      repository.ReviewTurnaround{AdvisorMedianDays: medianDays(1), AdvisorDecisions: 2, GlobalMedianDays: medianDays(2), GlobalDecisions: 4},
+			wantDays:  4, wantBasis: reviewBasisSLA,
+		},
+		{
+			name:      "median kosong → SLA",
+			advisorID: &advisor,
+			data:      repository.ReviewTurnaround{AdvisorDecisions: 8, GlobalDecisions: 8},
+			wantDays:  4, wantBasis: reviewBasisSLA,
+		},
+		{
+			name:      "median sangat cepat dibulatkan minimal 0,5 hari",
+			advisorID: &advisor,
+			data:      repository.ReviewTurnaround{AdvisorMedianDays: medianDays(0.1), AdvisorDecisions: 12},
+			wantDays:  0.5, wantBasis: reviewBasisAdvisor, wantSamples: 12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newReviewEstimator(&fakeTurnaroundRepo{data: tt.data})
+			got, err := e.Estimate(tt.advisorID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := ReviewEstimate{EstimatedReviewDays: tt.wantDays, IsEstimate: true, Basis: tt.wantBasis, SampleSize: tt.wantSamples}
+			if got != want {
+				t.Fatalf("estimate %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestReviewEstimateCache: hasil query di-cache per dosen wali selama TTL; setelah TTL lewat
+// query diulang, error tidak di-cache, dan perubahan REVIEW_SLA_DAYS berlaku walau cache masih hidup.
+func TestReviewEstimateCache(t *testing.T) {
+	setRuntimeEnv(t, map[string]string{"REVIEW_SLA_DAYS": "7"})
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	repo := &fakeTurnaroundRepo{}
+	e := newReviewEstimator(repo)
+	e.now = func() time.Time { return now }
+	advisor, other := uuid.New(), uuid.New()
+
+	estimate := func(advisorID *uuid.UUID) ReviewEstimate {
+		t.Helper()
+		got, err := e.Estimate(advisorID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	estimate(&advisor)
+	if repo.calls != 1 || !repo.since.Equal(now.Add(-reviewEstimateWindow)) {
+		t.Fatalf("query %d kali sejak %v, want 1 kali sejak %v", repo.calls, repo.since, now.Add(-reviewEstimateWindow))
+	}
+
+	// Masih dalam TTL: pakai cache walau data di repository berubah.
+	now = now.Add(reviewEstimateCacheTTL - time.Minute)
+	repo.data = repository.ReviewTurnaround{AdvisorMedianDays: medianDays(2), AdvisorDecisions: 9}
+	if got := estimate(&advisor); repo.calls != 1 || got.Basis != reviewBasisSLA {
+		t.Fatalf("dalam TTL: %d query, estimate %+v, want cache (sla)", repo.calls, got)
+	}
+
+	// Cache per dosen wali: dosen lain & tanpa dosen wali punya entri sendiri.
+	estimate(&other)
+	estimate(nil)
+	if repo.calls != 3 {
+		t.Fatalf("%d query, want 3 (1 per dosen wali)", repo.calls)
+	}
+
+	// REVIEW_SLA_DAYS diubah: yang di-cache hasil query, jadi SLA baru langsung berlaku.
+	setRuntimeEnv(t, map[string]string{"REVIEW_SLA_DAYS": "3"})
+	if got := estimate(&advisor); repo.calls != 3 || got.EstimatedReviewDays != 3 {
+		t.Fatalf("setelah ubah SLA: %d query, estimate %+v, want 3 hari dari cache", repo.calls, got)
+	}
+
+	// TTL lewat: query ulang dan data terbaru dipakai.
+	now = now.Add(time.Minute)
+	if got := estimate(&advisor); repo.calls != 4 || got.Basis != reviewBasisAdvisor || got.EstimatedReviewDays != 2 {
+		t.Fatalf("setelah TTL: %d query, estimate %+v, want advisor_history 2 hari", repo.calls, got)
+	}
+
+	// Error repository tidak di-cache.
+	now = now.Add(reviewEstimateCacheTTL)
+	repo.err = errors.New("postgres down")
+	if _, err := e.Estimate(&advisor); err == nil {
+		t.Fatal("error repository tidak diteruskan")
+	}
+	repo.err = nil
+	estimate(&advisor)
+	if repo.calls != 6 {
+		t.Fatalf("%d query, want 6 (error tidak di-cache)", repo.calls)
+	}
+}
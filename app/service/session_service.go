@@ -0,0 +1,275 @@
+package service
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// activeSessions mengambil sesi aktif userID dan menandai sesi token yang sedang dipakai.
+func activeSessions(ctx *gin.Context, repo repository.RefreshTokenRepository, userID uuid.UUID) ([]repository.RefreshSession, error) {
+	sessions, err := repo.FindActiveSessions(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	current, _ := getUUIDFromContext(ctx, "sessionID")
+	for i := range sessions {
+		sessions[i].Current = current != uuid.Nil && sessions[i].ID == current
+	}
+	return sessions, nil
+}
+
+// sessionLimit membaca SESSION_LIMIT (default 3, 0 = tanpa batas) dan SESSION_LIMIT_STRICT
+// (default false: sesi terlama dicabut; true: login baru ditolak 409).
+func sessionLimit() (limit int, strict bool) {
+	return utils.GetEnvInt("SESSION_LIMIT", 3), utils.GetEnvBool("SESSION_LIMIT_STRICT", false)
+}
+
+// sessionsToEvict memilih sesi yang harus dicabut agar login baru tidak melebihi limit:
+// sesi dengan waktu login paling lama lebih dulu.
+func sessionsToEvict(sessions []repository.RefreshSession, limit int) []uuid.UUID {
+	excess := len(sessions) - limit + 1
+	if limit <= 0 || excess <= 0 {
+		return nil
+	}
+	oldest := append([]repository.RefreshSession(nil), sessions...)
+	sort.SliceStable(oldest, func(i, j int) bool {
+		return oldest[i].CreatedAt.Before(oldest[j].CreatedAt)
+	})
+	ids := make([]uuid.UUID, 0, excess)
+	for _, sess := range oldest[:excess] {
+		ids = append(ids, sess.ID)
+	}
+	return ids
+}
+
+// enforceSessionLimit dipanggil sebelum login membuka sesi baru. Mengembalikan jumlah sesi
+// aktif setelah login (termasuk sesi baru); ok=false jika response error sudah dikirim.
+func (s *authService) enforceSessionLimit(ctx *gin.Context, userID uuid.UUID, attemptedUsername string) (active int, ok bool) {
+	limit, strict := sessionLimit()
+	if limit <= 0 {
+		return 0, true
+	}
+
+	sessions, err := s.refreshRepo.FindActiveSessions(userID, time.Now())
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memeriksa sesi login", err.Error(), nil)
+		return 0, false
+	}
+
+	evict := sessionsToEvict(sessions, limit)
+	if len(evict) == 0 {
+		return len(sessions) + 1, true
+	}
+	if strict {
+		s.recordLoginEvent(ctx, &userID, attemptedUsername, false, model.LoginFailureSessionLimit)
+		utils.RespondError(ctx, http.StatusConflict,
+			"Jumlah sesi login sudah maksimal, logout dari perangkat lain terlebih dahulu",
+			"session_limit_reached", map[string]any{
+				"sessionLimit":   limit,
+				"activeSessions": len(sessions),
+			})
+		return 0, false
+	}
+
+	for _, id := range evict {
+		if _, err := s.refreshRepo.RevokeSession(userID, id); err != nil {
+			log.Printf("⚠️  gagal mencabut sesi lama %s user %s: %v", id, userID, err)
+			continue
+		}
+		if err := s.revokeSessionAccess(userID, id); err != nil {
+			log.Printf("⚠️  gagal mencabut access token sesi lama %s user %s: %v", id, userID, err)
+		}
+	}
+	return len(sessions) - len(evict) + 1, true
+}
+
+// revokeSessionAccess memasukkan sesi ke denylist selama umur access token, sehingga access
+// token sesi itu yang masih berlaku ikut ditolak (bukan hanya refresh token-nya).
+func (s *authService) revokeSessionAccess(userID, sessionID uuid.UUID) error {
+	return s.revokedRepo.Revoke(utils.SessionRevocationKey(sessionID), userID, time.Now().Add(utils.AccessTokenTTL()))
+}
+
+// ===============================================================
+//  GET /api/v1/auth/sessions
+//  Sesi login aktif (refresh token yang belum dicabut) milik user.
+// ===============================================================
+func (s *authService) GetSessions(ctx *gin.Context) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	sessions, err := activeSessions(ctx, s.refreshRepo, userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengambil sesi login", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Berhasil mengambil sesi login", sessions)
+}
+
+// ===============================================================
+//  DELETE /api/v1/auth/sessions/:id
+//  Cabut 1 sesi: refresh token-nya ditolak di /auth/refresh berikutnya.
+// ===============================================================
+func (s *authService) RevokeSession(ctx *gin.Context) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"ID sesi tidak valid", err.Error(), nil)
+		return
+	}
+
+	revoked, err := s.refreshRepo.RevokeSession(userID, sessionID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mencabut sesi", err.Error(), nil)
+		return
+	}
+	if !revoked {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"Sesi tidak ditemukan atau sudah berakhir", "not_found", nil)
+		return
+	}
+	if err := s.revokeSessionAccess(userID, sessionID); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mencabut sesi", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"Sesi berhasil dicabut", nil)
+}
+
+// ===============================================================
+//  DELETE /api/v1/auth/sessions
+//  Cabut semua sesi lain, kecuali sesi token yang sedang dipakai.
+// ===============================================================
+func (s *authService) RevokeOtherSessions(ctx *gin.Context) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return
+	}
+
+	// Token lama tanpa sid: sesi saat ini tidak dikenal → semua sesi dicabut.
+	current, _ := getUUIDFromContext(ctx, "sessionID")
+	sessions, err := s.refreshRepo.FindActiveSessions(userID, time.Now())
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mencabut sesi", err.Error(), nil)
+		return
+	}
+	count, err := s.refreshRepo.RevokeOtherSessions(userID, current)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mencabut sesi", err.Error(), nil)
+		return
+	}
+	for _, sess := range sessions {
+		if sess.ID == current {
+			continue
+		}
+		if err := s.revokeSessionAccess(userID, sess.ID); err != nil {
+			utils.RespondError(ctx, http.StatusInternalServerError,
+				"Gagal mencabut sesi", err.Error(), nil)
+			return
+		}
+	}
+
+	utils.RespondOK(ctx,
+		"Sesi lain berhasil dicabut", map[string]any{"revoked": count})
+}
+
+// ===============================================================
+//  GET /api/v1/admin/users/:id/sessions
+//  Admin: sesi login aktif user tertentu.
+// ===============================================================
+func (s *adminService) GetUserSessions(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+
+	sessions, err := s.refreshRepo.FindActiveSessions(userID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil sesi login", err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil sesi login user", sessions))
+}
+
+// ===============================================================
+//  DELETE /api/v1/admin/users/:id/sessions/:sessionId
+//  Admin: cabut 1 sesi user (mis. perangkat hilang).
+// ===============================================================
+func (s *adminService) RevokeUserSession(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+	sessionID, err := uuid.Parse(ctx.Param("sessionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID sesi tidak valid", err.Error(), nil))
+		return
+	}
+
+	revoked, err := s.refreshRepo.RevokeSession(userID, sessionID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mencabut sesi", err.Error(), nil))
+		return
+	}
+	if !revoked {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Sesi tidak ditemukan atau sudah berakhir", "not_found", nil))
+		return
+	}
+
+	actorID, _ := getUserIDFromContext(ctx)
+	_ = s.auditRepo.Record(ctx, &actorID, "user.session_revoke", "user", userID.String(), map[string]any{
+		"sessionId": sessionID,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Sesi user berhasil dicabut", nil))
+}
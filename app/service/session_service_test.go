@@ -0,0 +1,158 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// sessionOf mengambil sessionID dari access token.
+func sessionOf(t *testing.T, access string) uuid.UUID {
+	t.Helper()
+	claims, err := utils.ValidateToken(access)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims.SessionID
+}
+
+// activeSessionIDs mengembalikan sesi aktif user di repository refresh token.
+func activeSessionIDs(t *testing.T, f *authFixture, userID uuid.UUID) map[uuid.UUID]bool {
+	t.Helper()
+	sessions, err := f.refresh.FindActiveSessions(userID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[uuid.UUID]bool{}
+	for _, sess := range sessions {
+		ids[sess.ID] = true
+	}
+	return ids
+}
+
+func TestSessionLimitEvictionRevokesAccessToken(t *testing.T) {
+	user := newTestUser(t, "mhs", "Rahasia#2026", "mahasiswa")
+	f := newAuthFixture(t, user)
+	t.Setenv("SESSION_LIMIT", "1")
+	middleware.SetTokenRevocationChecker(f.revoked)
+	t.Cleanup(func() { middleware.SetTokenRevocationChecker(nil) })
+	r := f.authRouter()
+
+	oldAccess, oldRefresh := f.login(t, "mhs", "Rahasia#2026")
+	newAccess, _ := f.login(t, "mhs", "Rahasia#2026") // sesi pertama dicabut (SESSION_LIMIT=1)
+
+	claims, err := utils.ValidateToken(oldAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := f.revoked.IsRevoked(utils.SessionRevocationKey(claims.SessionID)); !ok {
+		t.Fatal("sesi yang dicabut tidak masuk denylist")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "access token sesi yang dicabut", token: oldAccess, want: http.StatusUnauthorized},
+		{name: "access token sesi baru", token: newAccess, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, _ := doJSON(t, r, http.MethodGet, "/api/v1/achievements", tt.token, nil); w.Code != tt.want {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	if w, _ := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": oldRefresh}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token sesi yang dicabut: status %d, want 401", w.Code)
+	}
+}
+
+// TestSessionLimitEvictsOnlyOldest: SESSION_LIMIT=3, login ke-4 hanya mencabut sesi pertama;
+// 3 sesi terbaru tetap aktif dan access token-nya tetap diterima.
+func TestSessionLimitEvictsOnlyOldest(t *testing.T) {
+	user := newTestUser(t, "mhs", "Rahasia#2026", "mahasiswa")
+	f := newAuthFixture(t, user)
+	t.Setenv("SESSION_LIMIT", "3")
+	t.Setenv("SESSION_LIMIT_STRICT", "false")
+	middleware.SetTokenRevocationChecker(f.revoked)
+	t.Cleanup(func() { middleware.SetTokenRevocationChecker(nil) })
+	r := f.authRouter()
+
+	var access, refresh []string
+	for i := 0; i < 4; i++ {
+		a, rt := f.login(t, "mhs", "Rahasia#2026")
+		access, refresh = append(access, a), append(refresh, rt)
+		time.Sleep(time.Millisecond) // waktu login tiap sesi berbeda
+	}
+
+	active := activeSessionIDs(t, f, user.ID)
+	if len(active) != 3 || active[sessionOf(t, access[0])] {
+		t.Fatalf("sesi aktif %v, want 3 sesi tanpa sesi pertama", active)
+	}
+	for i, a := range access {
+		sid := sessionOf(t, a)
+		revoked, _ := f.revoked.IsRevoked(utils.SessionRevocationKey(sid))
+		want := http.StatusOK
+		if i == 0 {
+			want = http.StatusUnauthorized
+		}
+		if revoked != (i == 0) || (i > 0 && !active[sid]) {
+			t.Fatalf("sesi ke-%d: denylist=%v aktif=%v", i+1, revoked, active[sid])
+		}
+		if w, _ := doJSON(t, r, http.MethodGet, "/api/v1/achievements", a, nil); w.Code != want {
+			t.Fatalf("access token sesi ke-%d: status %d, want %d", i+1, w.Code, want)
+		}
+	}
+
+	if w, _ := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh[0]}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token sesi pertama: status %d, want 401", w.Code)
+	}
+	if w, _ := doJSON(t, r, http.MethodPost, "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh[1]}); w.Code != http.StatusOK {
+		t.Fatalf("refresh token sesi kedua: status %d, want 200", w.Code)
+	}
+}
+
+// TestSessionLimitStrictRejectsWithoutEviction: SESSION_LIMIT_STRICT=true menolak login
+// yang melebihi limit dengan 409 session_limit_reached tanpa mencabut sesi mana pun.
+func TestSessionLimitStrictRejectsWithoutEviction(t *testing.T) {
+	user := newTestUser(t, "mhs", "Rahasia#2026", "mahasiswa")
+	f := newAuthFixture(t, user)
+	t.Setenv("SESSION_LIMIT", "3")
+	t.Setenv("SESSION_LIMIT_STRICT", "true")
+	r := f.authRouter()
+
+	var access []string
+	for i := 0; i < 3; i++ {
+		a, _ := f.login(t, "mhs", "Rahasia#2026")
+		access = append(access, a)
+	}
+	before := activeSessionIDs(t, f, user.ID)
+
+	w, data := doJSON(t, r, http.MethodPost, "/api/v1/auth/login", "", map[string]any{"username": "mhs", "password": "Rahasia#2026"})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"errors":"session_limit_reached"`) {
+		t.Fatalf("login ke-4: status %d, body %s, want 409 session_limit_reached", w.Code, w.Body)
+	}
+	if data["sessionLimit"] != float64(3) || data["activeSessions"] != float64(3) {
+		t.Fatalf("detail %v, want sessionLimit=3 activeSessions=3", data)
+	}
+
+	after := activeSessionIDs(t, f, user.ID)
+	if len(after) != 3 || len(before) != 3 {
+		t.Fatalf("sesi aktif sebelum %v sesudah %v, want tetap 3", before, after)
+	}
+	for _, a := range access {
+		sid := sessionOf(t, a)
+		if revoked, _ := f.revoked.IsRevoked(utils.SessionRevocationKey(sid)); revoked || !after[sid] {
+			t.Fatalf("sesi %s dicabut pada mode strict", sid)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feedSourceCap membatasi jumlah entri per sumber sebelum digabung ke feed.
+const feedSourceCap = 500
+
+// FeedItem adalah 1 kejadian di feed aktivitas mahasiswa.
+type FeedItem struct {
+	Type          string     `json:"type"`
+	At            time.Time  `json:"at"`
+	AchievementID *uuid.UUID `json:"achievementId,omitempty"`
+	Message       string     `json:"message"`
+
+	// key unik per item, dipakai sebagai tie-break urutan & bagian dari cursor
+	key string
+}
+
+// feedStatusMessages: pesan untuk perubahan status prestasi (per status tujuan).
+var feedStatusMessages = map[string]string{
+	model.StatusSubmitted: "Prestasi diajukan untuk verifikasi",
+	model.StatusVerified:  "Prestasi diverifikasi oleh dosen wali",
+	model.StatusRejected:  "Prestasi ditolak oleh dosen wali",
+	model.StatusExpired:   "Pengajuan kedaluwarsa karena belum diverifikasi",
+	model.StatusDeleted:   "Prestasi dihapus",
+}
+
+// feedStatusMessage merender 1 baris riwayat status; ok=false jika transisinya tidak ditampilkan.
+func feedStatusMessage(l model.AchievementStatusLog) (string, bool) {
+	switch {
+	case l.FromStatus == model.StatusSubmitted && l.ToStatus == model.StatusDraft:
+		return "Pengajuan prestasi ditarik kembali menjadi draft", true
+	case l.FromStatus == model.StatusRejected && l.ToStatus == model.StatusSubmitted:
+		return "Prestasi yang ditolak diajukan ulang", true
+	case l.FromStatus == model.StatusDeleted:
+		return "Prestasi dipulihkan", true
+	case l.ToStatus == model.StatusRejected && l.Note != nil && *l.Note != "":
+		return feedStatusMessages[model.StatusRejected] + ": " + *l.Note, true
+	}
+	msg, ok := feedStatusMessages[l.ToStatus]
+	return msg, ok
+}
+
+// feedCommentRoles: nama penulis komentar di pesan feed (per role saat berkomentar).
+var feedCommentRoles = map[string]string{
+	"dosen_wali": "Dosen wali",
+	"admin":      "Admin",
+}
+
+// feedAuditMessages: aksi audit yang boleh tampil di feed mahasiswa beserta pesannya.
+// Aksi di luar daftar ini (merge akun, RBAC, dll) tidak pernah ditampilkan. Perubahan status
+// (termasuk resubmit & restore) diambil dari riwayat status, bukan dari audit.
+var feedAuditMessages = map[string]string{
+	"achievement.points_override":        "Poin prestasi Anda disesuaikan oleh admin",
+	"achievement.points_override_revert": "Penyesuaian poin prestasi Anda dibatalkan",
+	"achievement.attachment_malware":     "Lampiran ditolak karena terdeteksi malware",
+	"student.advisor_change":             "Dosen wali Anda telah diganti",
+}
+
+// feedBefore: true jika a tampil lebih dulu daripada (at, key) di feed (terbaru dulu).
+func feedBefore(a FeedItem, at time.Time, key string) bool {
+	if !a.At.Equal(at) {
+		return a.At.After(at)
+	}
+	return a.key > key
+}
+
+// ================================
+// GET /api/v1/students/me/feed?limit=20&cursor=...
+// Mahasiswa: feed aktivitas gabungan (terbaru dulu) untuk prestasi & profilnya sendiri:
+// riwayat status prestasi, komentar & perubahan isi oleh orang lain, serta kejadian audit
+// yang menyangkut dirinya. Setiap sumber dibatasi feedSourceCap entri terbaru.
+// ================================
+func (s *studentService) GetMyFeed(ctx *gin.Context) {
+
+	// Mahasiswa, atau akun tertaut (dosen dengan profil mahasiswa lama)
+	studentID, _ := getUUIDFromContext(ctx, "studentID")
+	if ctx.GetString("role") != "mahasiswa" && studentID == uuid.Nil {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("Hanya mahasiswa yang dapat melihat feed aktivitas", "forbidden", nil))
+		return
+	}
+	if studentID == uuid.Nil {
+		ctx.JSON(http.StatusUnauthorized,
+			utils.BuildResponseFailed("Autentikasi mahasiswa tidak valid", "no_student_id", nil))
+		return
+	}
+	userID, _ := getUUIDFromContext(ctx, "userID")
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var cursorAt time.Time
+	var cursorKey string
+	hasCursor := false
+	if c := ctx.Query("cursor"); c != "" {
+		at, key, err := utils.DecodeCursor(c)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest,
+				utils.BuildResponseFailed("Cursor tidak valid", err.Error(), nil))
+			return
+		}
+		cursorAt, cursorKey, hasCursor = at, key, true
+	}
+
+	// Semua sumber di bawah difilter ke prestasi milik mahasiswa ini saja
+	rctx := ctx.Request.Context()
+	refs, err := s.achievementRepo.FindByStudentID(rctx, studentID.String())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil prestasi", err.Error(), nil))
+		return
+	}
+
+	var items []FeedItem
+	refIDs := make([]string, 0, len(refs))
+	byMongoID := make(map[primitive.ObjectID]uuid.UUID, len(refs))
+	for i := range refs {
+		ref := &refs[i]
+		refIDs = append(refIDs, ref.ID.String())
+		if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			byMongoID[oid] = ref.ID
+		}
+		// Pembuatan draft tidak tercatat di riwayat status; created_at tidak pernah berubah.
+		items = append(items, FeedItem{
+			Type: "created", At: ref.CreatedAt, AchievementID: &ref.ID,
+			Message: "Prestasi dibuat sebagai draft", key: "created:" + ref.ID.String(),
+		})
+	}
+
+	// 1) Riwayat status (achievement_status_logs)
+	logs, err := s.achievementRepo.FindRecentStatusLogs(rctx, refIDs, feedSourceCap)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil riwayat status", err.Error(), nil))
+		return
+	}
+	for _, l := range logs {
+		msg, ok := feedStatusMessage(l)
+		if !ok {
+			continue
+		}
+		id := l.AchievementReferenceID
+		items = append(items, FeedItem{
+			Type: "status_change", At: l.CreatedAt, AchievementID: &id,
+			Message: msg, key: "status:" + l.ID.String(),
+		})
+	}
+
+	// 2) Komentar dari dosen wali / admin (achievement_comments)
+	comments, err := s.commentRepo.FindReceived(rctx, refIDs, userID, feedSourceCap)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil komentar", err.Error(), nil))
+		return
+	}
+	for _, c := range comments {
+		id, err := uuid.Parse(c.AchievementID)
+		if err != nil {
+			continue
+		}
+		author, ok := feedCommentRoles[c.Role]
+		if !ok {
+			author = "Pengguna lain"
+		}
+		items = append(items, FeedItem{
+			Type: "comment", At: c.CreatedAt, AchievementID: &id,
+			Message: author + " mengomentari prestasi Anda", key: "comment:" + c.ID.Hex(),
+		})
+	}
+
+	// 3) Perubahan isi prestasi oleh orang lain (achievement_revisions)
+	mongoIDs := make([]primitive.ObjectID, 0, len(byMongoID))
+	for oid := range byMongoID {
+		mongoIDs = append(mongoIDs, oid)
+	}
+	revisions, err := s.revisionRepo.FindRecentByAchievements(rctx, mongoIDs, userID, feedSourceCap)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil riwayat perubahan", err.Error(), nil))
+		return
+	}
+	for _, rev := range revisions {
+		id, ok := byMongoID[rev.AchievementID]
+		if !ok {
+			continue
+		}
+		items = append(items, FeedItem{
+			Type: "revision", At: rev.CreatedAt, AchievementID: &id,
+			Message: "Isi prestasi Anda diubah oleh pengguna lain", key: "revision:" + rev.ID.Hex(),
+		})
+	}
+
+	// 4) Kejadian audit — target harus prestasi milik mahasiswa ini atau profilnya sendiri
+	actions := make([]string, 0, len(feedAuditMessages))
+	for action := range feedAuditMessages {
+		actions = append(actions, action)
+	}
+	achievementLogs, err := s.auditRepo.FindByTargets("achievement", refIDs, actions, feedSourceCap)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil aktivitas", err.Error(), nil))
+		return
+	}
+	studentLogs, err := s.auditRepo.FindByTargets("student", []string{studentID.String()}, actions, feedSourceCap)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil aktivitas", err.Error(), nil))
+		return
+	}
+	for _, l := range append(achievementLogs, studentLogs...) {
+		item := FeedItem{
+			Type:    l.Action,
+			At:      l.CreatedAt,
+			Message: feedAuditMessages[l.Action],
+			key:     "audit:" + l.ID.String(),
+		}
+		if l.TargetType == "achievement" {
+			if id, err := uuid.Parse(l.TargetID); err == nil {
+				item.AchievementID = &id
+			}
+		}
+		items = append(items, item)
+	}
+
+	// 5) Gabung & urutkan terbaru dulu, lalu potong setelah cursor
+	sort.Slice(items, func(i, j int) bool {
+		return feedBefore(items[i], items[j].At, items[j].key)
+	})
+
+	start := 0
+	if hasCursor {
+		start = sort.Search(len(items), func(i int) bool {
+			return !feedBefore(items[i], cursorAt, cursorKey) &&
+				!(items[i].At.Equal(cursorAt) && items[i].key == cursorKey)
+		})
+	}
+	end := min(start+limit, len(items))
+	page := items[start:end]
+
+	var nextCursor *string
+	if end < len(items) && len(page) > 0 {
+		last := page[len(page)-1]
+		c := utils.EncodeCursor(last.At, last.key)
+		nextCursor = &c
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil feed aktivitas", map[string]any{
+			"items":      page,
+			"nextCursor": nextCursor,
+		}))
+}
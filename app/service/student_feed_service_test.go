@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeFeedAchievementRepo: reference & riwayat status semua mahasiswa; difilter seperti query asli.
+type fakeFeedAchievementRepo struct {
+	repository.AchievementRepository
+	refs []model.AchievementReference
+	logs []model.AchievementStatusLog
+}
+
+func (r *fakeFeedAchievementRepo) FindByStudentID(_ context.Context, studentID string) ([]model.AchievementReference, error) {
+	var out []model.AchievementReference
+	for _, ref := range r.refs {
+		if ref.StudentID.String() == studentID {
+			out = append(out, ref)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeFeedAchievementRepo) FindRecentStatusLogs(_ context.Context, achievementIDs []string, _ int) ([]model.AchievementStatusLog, error) {
+	var out []model.AchievementStatusLog
+	for _, l := range r.logs {
+		if slices.Contains(achievementIDs, l.AchievementReferenceID.String()) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+type fakeFeedCommentRepo struct {
+	repository.AchievementCommentRepository
+	comments []model.AchievementComment
+}
+
+func (r *fakeFeedCommentRepo) FindReceived(_ context.Context, achievementIDs []string, excludeAuthor uuid.UUID, _ int) ([]model.AchievementComment, error) {
+	var out []model.AchievementComment
+	for _, c := range r.comments {
+		if slices.Contains(achievementIDs, c.AchievementID) && c.AuthorUserID != excludeAuthor {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type fakeFeedRevisionRepo struct {
+	repository.AchievementRevisionRepository
+	revs []model.AchievementRevision
+}
+
+func (r *fakeFeedRevisionRepo) FindRecentByAchievements(_ context.Context, achievementIDs []primitive.ObjectID, excludeEditor uuid.UUID, _ int) ([]model.AchievementRevision, error) {
+	var out []model.AchievementRevision
+	for _, rev := range r.revs {
+		if slices.Contains(achievementIDs, rev.AchievementID) && rev.EditedBy != excludeEditor {
+			out = append(out, rev)
+		}
+	}
+	return out, nil
+}
+
+type fakeFeedAuditRepo struct {
+	repository.AuditRepository
+	logs []model.AuditLog
+}
+
+func (r *fakeFeedAuditRepo) FindByTargets(targetType string, targetIDs, actions []string, _ int) ([]model.AuditLog, error) {
+	var out []model.AuditLog
+	for _, l := range r.logs {
+		if l.TargetType == targetType && slices.Contains(targetIDs, l.TargetID) && slices.Contains(actions, l.Action) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func TestGetMyFeedMergesHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	base := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	studentID, userID := uuid.New(), uuid.New()
+	otherStudent, lecturerID := uuid.New(), uuid.New()
+	mine := model.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: primitive.NewObjectID().Hex(),
+		Status: model.StatusVerified, CreatedAt: at(0)}
+	theirs := model.AchievementReference{ID: uuid.New(), StudentID: otherStudent, MongoAchievementID: primitive.NewObjectID().Hex(),
+		Status: model.StatusSubmitted, CreatedAt: at(1)}
+	mineOID, _ := primitive.ObjectIDFromHex(mine.MongoAchievementID)
+	theirsOID, _ := primitive.ObjectIDFromHex(theirs.MongoAchievementID)
+	note := "bukti kurang"
+
+	achievements := &fakeFeedAchievementRepo{
+		refs: []model.AchievementReference{mine, theirs},
+		logs: []model.AchievementStatusLog{
+			{ID: uuid.New(), AchievementReferenceID: mine.ID, FromStatus: model.StatusDraft, ToStatus: model.StatusSubmitted, CreatedAt: at(2)},
+			{ID: uuid.New(), AchievementReferenceID: mine.ID, FromStatus: model.StatusSubmitted, ToStatus: model.StatusRejected, Note: &note, CreatedAt: at(4)},
+			{ID: uuid.New(), AchievementReferenceID: mine.ID, FromStatus: model.StatusRejected, ToStatus: model.StatusSubmitted, CreatedAt: at(6)},
+			{ID: uuid.New(), AchievementReferenceID: mine.ID, FromStatus: model.StatusSubmitted, ToStatus: model.StatusVerified, CreatedAt: at(8)},
+			{ID: uuid.New(), AchievementReferenceID: theirs.ID, FromStatus: model.StatusDraft, ToStatus: model.StatusSubmitted, CreatedAt: at(9)},
+		},
+	}
+	comments := &fakeFeedCommentRepo{comments: []model.AchievementComment{
+		{ID: primitive.NewObjectID(), AchievementID: mine.ID.String(), AuthorUserID: lecturerID, Role: "dosen_wali", CreatedAt: at(5)},
+		{ID: primitive.NewObjectID(), AchievementID: mine.ID.String(), AuthorUserID: userID, Role: "mahasiswa", CreatedAt: at(5)},
+		{ID: primitive.NewObjectID(), AchievementID: theirs.ID.String(), AuthorUserID: lecturerID, Role: "dosen_wali", CreatedAt: at(10)},
+	}}
+	revisions := &fakeFeedRevisionRepo{revs: []model.AchievementRevision{
+		{ID: primitive.NewObjectID(), AchievementID: mineOID, EditedBy: userID, CreatedAt: at(3)},
+		{ID: primitive.NewObjectID(), AchievementID: mineOID, EditedBy: uuid.New(), CreatedAt: at(7)},
+		{ID: primitive.NewObjectID(), AchievementID: theirsOID, EditedBy: uuid.New(), CreatedAt: at(11)},
+	}}
+	audit := &fakeFeedAuditRepo{logs: []model.AuditLog{
+		{ID: uuid.New(), Action: "achievement.points_override", TargetType: "achievement", TargetID: mine.ID.String(), CreatedAt: at(9)},
+		{ID: uuid.New(), Action: "student.advisor_change", TargetType: "student", TargetID: studentID.String(), CreatedAt: at(10)},
+		{ID: uuid.New(), Action: "achievement.points_override", TargetType: "achievement", TargetID: theirs.ID.String(), CreatedAt: at(12)},
+		{ID: uuid.New(), Action: "user.merge", TargetType: "student", TargetID: studentID.String(), CreatedAt: at(13)},
+	}}
+
+	svc := NewStudentService(nil, achievements, nil, audit, comments, revisions)
+	r := gin.New()
+	r.GET("/feed", func(c *gin.Context) {
+		c.Set("role", "mahasiswa")
+		c.Set("studentID", studentID)
+		c.Set("userID", userID)
+	}, svc.GetMyFeed)
+
+	type page struct {
+		Items      []FeedItem `json:"items"`
+		NextCursor *string    `json:"nextCursor"`
+	}
+	fetch := func(cursor string) page {
+		t.Helper()
+		q := url.Values{"limit": {"3"}}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed?"+q.Encode(), nil))
+		checkEnvelope(t, w)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d, body %s", w.Code, w.Body)
+		}
+		var resp struct {
+			Data page `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Data
+	}
+
+	var got []FeedItem
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination tidak berhenti")
+		}
+		p := fetch(cursor)
+		got = append(got, p.Items...)
+		if p.NextCursor == nil {
+			break
+		}
+		cursor = *p.NextCursor
+	}
+
+	want := []struct {
+		typ     string
+		at      time.Time
+		message string
+	}{
+		{"student.advisor_change", at(10), "Dosen wali Anda telah diganti"},
+		{"achievement.points_override", at(9), "Poin prestasi Anda disesuaikan oleh admin"},
+		{"status_change", at(8), "Prestasi diverifikasi oleh dosen wali"},
+		{"revision", at(7), "Isi prestasi Anda diubah oleh pengguna lain"},
+		{"status_change", at(6), "Prestasi yang ditolak diajukan ulang"},
+		{"comment", at(5), "Dosen wali mengomentari prestasi Anda"},
+		{"status_change", at(4), "Prestasi ditolak oleh dosen wali: bukti kurang"},
+		{"status_change", at(2), "Prestasi diajukan untuk verifikasi"},
+		{"created", at(0), "Prestasi dibuat sebagai draft"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("feed berisi %d item, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Type != w.typ || !g.At.Equal(w.at) || g.Message != w.message {
+			t.Fatalf("item %d = {%s %s %q}, want {%s %s %q}", i, g.Type, g.At, g.Message, w.typ, w.at, w.message)
+		}
+		if g.AchievementID != nil && *g.AchievementID != mine.ID {
+			t.Fatalf("item %d milik prestasi %s, bukan milik mahasiswa ini", i, g.AchievementID)
+		}
+	}
+}
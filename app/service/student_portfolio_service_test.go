@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePortfolioReportRepo mencatat argumen GetPortfolio dan mengembalikan portofolio tetap.
+type fakePortfolioReportRepo struct {
+	repository.ReportRepository
+	portfolio repository.PortfolioResult
+	calls     int
+	studentID uuid.UUID
+	mongoIDs  []string
+}
+
+func (r *fakePortfolioReportRepo) GetPortfolio(_ context.Context, studentID uuid.UUID, mongoIDs []string) (*repository.PortfolioResult, error) {
+	r.calls++
+	r.studentID, r.mongoIDs = studentID, mongoIDs
+	cp := r.portfolio
+	cp.Groups = make([]repository.PortfolioGroup, len(r.portfolio.Groups))
+	for i, g := range r.portfolio.Groups {
+		g.Items = append([]repository.PortfolioItem(nil), g.Items...)
+		cp.Groups[i] = g
+	}
+	return &cp, nil
+}
+
+// TestGetMyPortfolioVerifiedOnly: hanya reference verified milik pemanggil yang diteruskan ke
+// agregasi Mongo, dan tiap item portofolio diberi achievementId reference-nya.
+func TestGetMyPortfolioVerifiedOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	studentID, otherID := uuid.New(), uuid.New()
+
+	newRef := func(owner uuid.UUID, status string) model.AchievementReference {
+		return model.AchievementReference{ID: uuid.New(), StudentID: owner,
+			MongoAchievementID: primitive.NewObjectID().Hex(), Status: status}
+	}
+	verifiedA := newRef(studentID, model.StatusVerified)
+	verifiedB := newRef(studentID, model.StatusVerified)
+	refs := []model.AchievementReference{
+		newRef(studentID, model.StatusDraft),
+		verifiedA,
+		newRef(studentID, model.StatusSubmitted),
+		newRef(studentID, model.StatusRejected),
+		verifiedB,
+		newRef(otherID, model.StatusVerified), // milik mahasiswa lain
+	}
+	oidA, _ := primitive.ObjectIDFromHex(verifiedA.MongoAchievementID)
+	oidB, _ := primitive.ObjectIDFromHex(verifiedB.MongoAchievementID)
+
+	reports := &fakePortfolioReportRepo{portfolio: repository.PortfolioResult{
+		Groups: []repository.PortfolioGroup{
+			{AchievementType: "competition", Count: 1, SubtotalPoints: 12.5,
+				Items: []repository.PortfolioItem{{MongoID: oidA, Title: "Juara 1", Points: 12.5}}},
+			{AchievementType: "organization", Count: 1, SubtotalPoints: 5,
+				Items: []repository.PortfolioItem{{MongoID: oidB, Title: "Ketua BEM", Points: 5}}},
+		},
+		TotalAchievements: 2,
+		TotalPoints:       17.5,
+		Tags:              []repository.TagCount{{Tag: "coding", Count: 2}},
+	}}
+	svc := NewStudentService(nil, &fakeFeedAchievementRepo{refs: refs}, reports, nil, nil, nil)
+	r := gin.New()
+	r.GET("/students/me/portfolio", func(c *gin.Context) {
+		c.Set("role", "mahasiswa")
+		c.Set("studentID", studentID)
+	}, svc.GetMyPortfolio)
+
+	w, _ := doJSON(t, r, http.MethodGet, "/students/me/portfolio", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	if reports.studentID != studentID {
+		t.Fatalf("agregasi untuk mahasiswa %s, want %s", reports.studentID, studentID)
+	}
+	want := []string{verifiedA.MongoAchievementID, verifiedB.MongoAchievementID}
+	if !reflect.DeepEqual(reports.mongoIDs, want) {
+		t.Fatalf("mongoIDs %v, want hanya yang verified %v", reports.mongoIDs, want)
+	}
+
+	var resp struct {
+		Data repository.PortfolioResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TotalAchievements != 2 || resp.Data.TotalPoints != 17.5 || len(resp.Data.Groups) != 2 {
+		t.Fatalf("portofolio %+v", resp.Data)
+	}
+	if got := resp.Data.Groups[0].Items[0].AchievementID; got != verifiedA.ID.String() {
+		t.Fatalf("achievementId %q, want %s", got, verifiedA.ID)
+	}
+	if got := resp.Data.Groups[1].Items[0].AchievementID; got != verifiedB.ID.String() {
+		t.Fatalf("achievementId %q, want %s", got, verifiedB.ID)
+	}
+
+	// PDF memakai data yang sama.
+	req := httptest.NewRequest(http.MethodGet, "/students/me/portfolio?format=pdf", nil)
+	pdf := httptest.NewRecorder()
+	r.ServeHTTP(pdf, req)
+	if pdf.Code != http.StatusOK || pdf.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("pdf: status %d, content-type %q", pdf.Code, pdf.Header().Get("Content-Type"))
+	}
+	if body := pdf.Body.String(); !strings.HasPrefix(body, "%PDF-") || !strings.Contains(body, "Total poin: 17.5") {
+		t.Fatalf("pdf tidak memuat total poin: %q", body)
+	}
+}
+
+func TestGetMyPortfolioAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		role       string
+		studentID  uuid.UUID
+		query      string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "admin", role: "admin", wantStatus: http.StatusForbidden, wantCode: "forbidden"},
+		{name: "dosen wali", role: "dosen_wali", wantStatus: http.StatusForbidden, wantCode: "forbidden"},
+		{name: "dosen dengan profil mahasiswa tertaut", role: "dosen_wali", studentID: uuid.New(), wantStatus: http.StatusOK},
+		{name: "mahasiswa tanpa studentID", role: "mahasiswa", wantStatus: http.StatusUnauthorized, wantCode: "no_student_id"},
+		{name: "format tidak dikenal", role: "mahasiswa", studentID: uuid.New(), query: "?format=xml",
+			wantStatus: http.StatusBadRequest, wantCode: "invalid_format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakePortfolioReportRepo{}
+			svc := NewStudentService(nil, &fakeFeedAchievementRepo{}, reports, nil, nil, nil)
+			r := gin.New()
+			r.GET("/students/me/portfolio", func(c *gin.Context) {
+				c.Set("role", tt.role)
+				if tt.studentID != uuid.Nil {
+					c.Set("studentID", tt.studentID)
+				}
+			}, svc.GetMyPortfolio)
+
+			w, _ := doJSON(t, r, http.MethodGet, "/students/me/portfolio"+tt.query, "", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if !strings.Contains(w.Body.String(), `"errors":"`+tt.wantCode+`"`) {
+					t.Fatalf("body %s, want kode %s", w.Body, tt.wantCode)
+				}
+				if reports.calls != 0 {
+					t.Fatal("portofolio tetap diagregasi untuk pemanggil yang ditolak")
+				}
+			}
+		})
+	}
+}
+
+func TestPortfolioLines(t *testing.T) {
+	level := "national"
+	p := &repository.PortfolioResult{
+		Groups: []repository.PortfolioGroup{
+			{AchievementType: "competition", Count: 2, SubtotalPoints: 12.5, Items: []repository.PortfolioItem{
+				{Title: "Juara 1", Level: &level, Points: 10},
+				{Title: "Finalis", Points: 2.5},
+			}},
+			{AchievementType: "organization", Count: 1, SubtotalPoints: 5,
+				Items: []repository.PortfolioItem{{Title: "Ketua BEM", Points: 5}}},
+		},
+		TotalAchievements: 3,
+		TotalPoints:       17.5,
+		Tags:              []repository.TagCount{{Tag: "coding", Count: 2}, {Tag: "ai", Count: 1}},
+	}
+	want := []string{
+		"Total prestasi terverifikasi: 3",
+		"Total poin: 17.5",
+		"",
+		"COMPETITION (2 prestasi, 12.5 poin)",
+		"  - Juara 1 [national] : 10 poin",
+		"  - Finalis : 2.5 poin",
+		"",
+		"ORGANIZATION (1 prestasi, 5 poin)",
+		"  - Ketua BEM : 5 poin",
+		"",
+		"Tag: coding (2), ai (1)",
+	}
+	if got := portfolioLines(p); !reflect.DeepEqual(got, want) {
+		t.Fatalf("baris PDF:\n%q\nwant\n%q", got, want)
+	}
+}
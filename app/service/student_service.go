@@ -1,8 +1,12 @@
 package service
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/repository"
 	"student-achievement-backend/utils"
 
@@ -15,27 +19,47 @@ import (
 // - GET /api/v1/students/:id
 // - GET /api/v1/students/:id/achievements
 // - PUT /api/v1/students/:id/advisor
+// - PUT /api/v1/students/:id/graduation
+// - POST /api/v1/students/graduation/bulk
+// - GET /api/v1/students/me/portfolio
+// - GET /api/v1/students/me/feed
 type StudentService interface {
 	GetStudents(ctx *gin.Context)
 	GetStudentDetail(ctx *gin.Context)
 	GetStudentAchievements(ctx *gin.Context)
 	UpdateAdvisor(ctx *gin.Context)
+	SetGraduation(ctx *gin.Context)
+	BulkSetGraduation(ctx *gin.Context)
+	GetMyPortfolio(ctx *gin.Context)
+	GetMyFeed(ctx *gin.Context)
 }
 
 // studentService menyimpan dependency ke repository yang dibutuhkan.
 type studentService struct {
 	studentRepo     repository.StudentRepository
 	achievementRepo repository.AchievementRepository
+	reportRepo      repository.ReportRepository
+	auditRepo       repository.AuditRepository
+	commentRepo     repository.AchievementCommentRepository  // feed: komentar yang diterima
+	revisionRepo    repository.AchievementRevisionRepository // feed: perubahan isi oleh orang lain
 }
 
 // NewStudentService membuat instance StudentService baru.
 func NewStudentService(
 	studentRepo repository.StudentRepository,
 	achievementRepo repository.AchievementRepository,
+	reportRepo repository.ReportRepository,
+	auditRepo repository.AuditRepository,
+	commentRepo repository.AchievementCommentRepository,
+	revisionRepo repository.AchievementRevisionRepository,
 ) StudentService {
 	return &studentService{
 		studentRepo:     studentRepo,
 		achievementRepo: achievementRepo,
+		reportRepo:      reportRepo,
+		auditRepo:       auditRepo,
+		commentRepo:     commentRepo,
+		revisionRepo:    revisionRepo,
 	}
 }
 
@@ -45,8 +69,9 @@ func NewStudentService(
 // =====================
 func (s *studentService) GetStudents(ctx *gin.Context) {
 
-	// gunakan helper ensureAdmin yang sudah ada di admin_service.go
-	if !ensureAdmin(ctx) {
+	// gunakan helper ensureAdmin yang sudah ada di admin_service.go;
+	// integrasi dengan API key student:read juga boleh (read-only)
+	if !apiKeyCan(ctx, model.PermissionStudentRead) && !ensureAdmin(ctx) {
 		return
 	}
 
@@ -113,7 +138,7 @@ func (s *studentService) GetStudentAchievements(ctx *gin.Context) {
 		return
 	}
 
-	refs, err := s.achievementRepo.FindByStudentID(studentID.String())
+	refs, err := s.achievementRepo.FindByStudentID(ctx.Request.Context(), studentID.String())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError,
 			utils.BuildResponseFailed("Gagal mengambil prestasi mahasiswa", err.Error(), nil))
@@ -165,6 +190,214 @@ func (s *studentService) UpdateAdvisor(ctx *gin.Context) {
 		return
 	}
 
+	// dicatat agar pergantian dosen wali muncul di feed aktivitas mahasiswa
+	actorID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &actorID, "student.advisor_change", "student", studentID.String(), map[string]any{
+		"advisorId": advisorUUID.String(),
+	})
+
 	ctx.JSON(http.StatusOK,
 		utils.BuildResponseSuccess("Dosen wali berhasil diperbarui", nil))
 }
+
+// =====================
+// PUT /api/v1/students/:id/graduation
+// Admin: set tanggal lulus (null = hapus). Body: { "graduatedAt": "2020-08-31T00:00:00Z" }
+// =====================
+func (s *studentService) SetGraduation(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	studentID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID mahasiswa tidak valid", err.Error(), nil))
+		return
+	}
+
+	var body struct {
+		GraduatedAt *time.Time `json:"graduatedAt"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	n, err := s.studentRepo.SetGraduatedAt([]uuid.UUID{studentID}, body.GraduatedAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memperbarui tanggal lulus", err.Error(), nil))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("Mahasiswa tidak ditemukan atau sudah dianonimkan", "not_found", nil))
+		return
+	}
+
+	actorID, _ := getUUIDFromContext(ctx, "userID")
+	_ = s.auditRepo.Record(ctx, &actorID, "student.graduation_set", "student", studentID.String(), map[string]any{
+		"graduatedAt": body.GraduatedAt,
+	})
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Tanggal lulus berhasil diperbarui", nil))
+}
+
+// =====================
+// POST /api/v1/students/graduation/bulk
+// Admin: set tanggal lulus banyak mahasiswa. Body: { "studentIds": [...], "graduatedAt": "..." }
+// =====================
+func (s *studentService) BulkSetGraduation(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	var body struct {
+		StudentIDs  []uuid.UUID `json:"studentIds" binding:"required,min=1,max=1000"`
+		GraduatedAt *time.Time  `json:"graduatedAt"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Input tidak valid", err.Error(), nil))
+		return
+	}
+
+	n, err := s.studentRepo.SetGraduatedAt(body.StudentIDs, body.GraduatedAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal memperbarui tanggal lulus", err.Error(), nil))
+		return
+	}
+
+	actorID, _ := getUUIDFromContext(ctx, "userID")
+	for _, id := range body.StudentIDs {
+		_ = s.auditRepo.Record(ctx, &actorID, "student.graduation_set", "student", id.String(), map[string]any{
+			"graduatedAt": body.GraduatedAt,
+			"bulk":        true,
+		})
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Tanggal lulus berhasil diperbarui", gin.H{
+			"requested": len(body.StudentIDs),
+			"updated":   n, // mahasiswa yang tidak ada / sudah dianonimkan tidak dihitung
+		}))
+}
+
+// ================================
+// GET /api/v1/students/me/portfolio?format=json|pdf
+// Mahasiswa: portofolio prestasi verified miliknya,
+// dikelompokkan per tipe + subtotal poin + frekuensi tag.
+// ================================
+func (s *studentService) GetMyPortfolio(ctx *gin.Context) {
+
+	// Mahasiswa, atau akun tertaut (dosen dengan profil mahasiswa lama), yang punya studentID di token
+	roleI, _ := ctx.Get("role")
+	if role, _ := roleI.(string); role != "mahasiswa" {
+		if sid, _ := getUUIDFromContext(ctx, "studentID"); sid == uuid.Nil {
+			ctx.JSON(http.StatusForbidden,
+				utils.BuildResponseFailed("Hanya mahasiswa yang dapat melihat portofolio", "forbidden", nil))
+			return
+		}
+	}
+
+	studentID, ok := getUUIDFromContext(ctx, "studentID")
+	if !ok || studentID == uuid.Nil {
+		ctx.JSON(http.StatusUnauthorized,
+			utils.BuildResponseFailed("Autentikasi mahasiswa diperlukan", "no_student_id", nil))
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "pdf" {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("Format tidak didukung (json|pdf)", "invalid_format", nil))
+		return
+	}
+
+	// Hanya reference berstatus verified (sumber kebenaran status ada di Postgres).
+	refs, err := s.achievementRepo.FindByStudentID(ctx.Request.Context(), studentID.String())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mengambil prestasi mahasiswa", err.Error(), nil))
+		return
+	}
+
+	mongoIDs := make([]string, 0, len(refs))
+	refByMongoID := make(map[string]string, len(refs))
+	for _, r := range refs {
+		if r.Status != "verified" {
+			continue
+		}
+		mongoIDs = append(mongoIDs, r.MongoAchievementID)
+		refByMongoID[r.MongoAchievementID] = r.ID.String()
+	}
+
+	portfolio, err := s.reportRepo.GetPortfolio(ctx.Request.Context(), studentID, mongoIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal menyusun portofolio", err.Error(), nil))
+		return
+	}
+
+	for gi := range portfolio.Groups {
+		for ii := range portfolio.Groups[gi].Items {
+			item := &portfolio.Groups[gi].Items[ii]
+			item.AchievementID = refByMongoID[item.MongoID.Hex()]
+			item.Attachments = absoluteAttachments(item.Attachments)
+		}
+	}
+
+	if format == "pdf" {
+		ctx.Header("Content-Disposition", `attachment; filename="portofolio-prestasi.pdf"`)
+		ctx.Data(http.StatusOK, "application/pdf",
+			utils.RenderTextPDF("Portofolio Prestasi Mahasiswa", portfolioLines(portfolio)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("Berhasil mengambil portofolio prestasi", portfolio))
+}
+
+// portfolioLines menyusun baris teks portofolio untuk dokumen PDF.
+func portfolioLines(p *repository.PortfolioResult) []string {
+	lines := []string{
+		fmt.Sprintf("Total prestasi terverifikasi: %d", p.TotalAchievements),
+		fmt.Sprintf("Total poin: %g", p.TotalPoints),
+		"",
+	}
+
+	for _, g := range p.Groups {
+		lines = append(lines, fmt.Sprintf("%s (%d prestasi, %g poin)", strings.ToUpper(g.AchievementType), g.Count, g.SubtotalPoints))
+		for _, it := range g.Items {
+			line := "  - " + it.Title
+			if it.Level != nil && *it.Level != "" {
+				line += " [" + *it.Level + "]"
+			}
+			if it.Date != nil {
+				line += " " + it.Date.Format("02-01-2006")
+			}
+			line += fmt.Sprintf(" : %g poin", it.Points)
+			lines = append(lines, line)
+			for _, att := range it.Attachments {
+				lines = append(lines, "      Lampiran: "+att.FileURL)
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	if len(p.Tags) > 0 {
+		tags := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			tags = append(tags, fmt.Sprintf("%s (%d)", t.Tag, t.Count))
+		}
+		lines = append(lines, "Tag: "+strings.Join(tags, ", "))
+	}
+
+	return lines
+}
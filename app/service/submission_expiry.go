@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+)
+
+// SubmissionExpiryJob mengembalikan pengajuan yang tidak diverifikasi dalam
+// SUBMISSION_EXPIRY_DAYS hari (runtime config, 0 = nonaktif) ke status expired atau draft.
+// Perubahan status tercatat di status log dan event outbox (mahasiswa dinotifikasi).
+type SubmissionExpiryJob struct {
+	repo      repository.AchievementRepository
+	every     time.Duration
+	target    string
+	batchSize int
+}
+
+// NewSubmissionExpiryJob membuat job dari env: SUBMISSION_EXPIRY_INTERVAL (1h),
+// SUBMISSION_EXPIRY_TARGET (expired|draft, default expired).
+func NewSubmissionExpiryJob(repo repository.AchievementRepository) *SubmissionExpiryJob {
+	target := os.Getenv("SUBMISSION_EXPIRY_TARGET")
+	switch target {
+	case model.StatusExpired, model.StatusDraft:
+	case "":
+		target = model.StatusExpired
+	default:
+		log.Printf("⚠️  SUBMISSION_EXPIRY_TARGET %q tidak dikenal, memakai %q", target, model.StatusExpired)
+		target = model.StatusExpired
+	}
+	return &SubmissionExpiryJob{
+		repo:      repo,
+		every:     utils.GetEnvDuration("SUBMISSION_EXPIRY_INTERVAL", time.Hour),
+		target:    target,
+		batchSize: 100,
+	}
+}
+
+// Start menjalankan RunOnce setiap interval sampai ctx dibatalkan.
+func (j *SubmissionExpiryJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Printf("⚠️  job kedaluwarsa pengajuan gagal: %v", err)
+			}
+			if n > 0 {
+				log.Printf("⏰ job kedaluwarsa pengajuan: %d prestasi menjadi %s", n, j.target)
+			}
+		}
+	}()
+}
+
+// RunOnce memproses semua pengajuan yang melewati batas; mengembalikan jumlah yang diubah.
+func (j *SubmissionExpiryJob) RunOnce(ctx context.Context) (int, error) {
+	days := utils.Runtime().SubmissionExpiryDays
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	total := 0
+	for {
+		refs, err := j.repo.FindStaleSubmissions(cutoff, j.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(refs) == 0 {
+			return total, nil
+		}
+
+		progressed := 0
+		for _, ref := range refs {
+			if ctx.Err() != nil {
+				return total, ctx.Err()
+			}
+			err := j.repo.UpdateStatus(ctx, ref.ID.String(), j.target, repository.UpdateStatusOptions{
+				ExpectedStatus: model.StatusSubmitted,
+				Expired:        true,
+			})
+			if errors.Is(err, repository.ErrStatusConflict) {
+				progressed++ // sudah diputuskan / ditarik di antara query dan update
+				continue
+			}
+			if err != nil {
+				log.Printf("⚠️  gagal mengubah pengajuan kedaluwarsa %s: %v", ref.ID, err)
+				continue
+			}
+			progressed++
+			total++
+		}
+		if progressed == 0 {
+			return total, errors.New("tidak ada pengajuan kedaluwarsa yang berhasil diproses pada batch ini")
+		}
+	}
+}
+
+// submissionAge menghitung lama (hari penuh) prestasi menunggu verifikasi, beserta sisa hari
+// sebelum kedaluwarsa (ok=false jika SUBMISSION_EXPIRY_DAYS nonaktif).
+func submissionAge(submittedAt, now time.Time) (daysPending, daysUntilExpiry int, ok bool) {
+	daysPending = int(now.Sub(submittedAt).Hours() / 24)
+	days := utils.Runtime().SubmissionExpiryDays
+	if days <= 0 {
+		return daysPending, 0, false
+	}
+	return daysPending, max(days-daysPending, 0), true
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"strings"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// defaultTargetPerYear: target global jika belum ada baris default di achievement_targets
+// (ACHIEVEMENT_TARGET_PER_YEAR, default 2 prestasi verified per tahun akademik).
+func defaultTargetPerYear() int {
+	return utils.Runtime().TargetPerYear
+}
+
+// targetResolver menentukan target per program studi.
+// Urutan prioritas: override program studi > baris global ("") > env default.
+type targetResolver struct {
+	global    int
+	byProgram map[string]int
+}
+
+func newTargetResolver(rows []model.AchievementTarget) targetResolver {
+	r := targetResolver{global: defaultTargetPerYear(), byProgram: map[string]int{}}
+	for _, t := range rows {
+		key := strings.ToLower(strings.TrimSpace(t.ProgramStudy))
+		if key == "" {
+			r.global = t.VerifiedPerYear
+			continue
+		}
+		r.byProgram[key] = t.VerifiedPerYear
+	}
+	return r
+}
+
+// For mengembalikan target untuk program studi tertentu.
+func (r targetResolver) For(programStudy string) int {
+	if v, ok := r.byProgram[strings.ToLower(strings.TrimSpace(programStudy))]; ok {
+		return v
+	}
+	return r.global
+}
+
+// loadTargetProgress menghitung progres target untuk daftar mahasiswa pada 1 tahun akademik.
+func loadTargetProgress(
+	targetRepo repository.TargetRepository,
+	students []model.Student,
+	year utils.AcademicYear,
+) (map[uuid.UUID]repository.TargetProgress, error) {
+
+	out := make(map[uuid.UUID]repository.TargetProgress, len(students))
+	if len(students) == 0 {
+		return out, nil
+	}
+
+	rows, err := targetRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	resolver := newTargetResolver(rows)
+
+	ids := make([]uuid.UUID, 0, len(students))
+	for _, st := range students {
+		ids = append(ids, st.ID)
+	}
+	counts, err := targetRepo.CountVerifiedBetween(ids, year.Start, year.End)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, st := range students {
+		target := resolver.For(st.ProgramStudy)
+		verified := counts[st.ID]
+		behind := int64(target) - verified
+		if behind < 0 {
+			behind = 0
+		}
+		out[st.ID] = repository.TargetProgress{
+			StudentID:     st.ID,
+			AcademicYear:  year.Label,
+			Target:        target,
+			VerifiedCount: verified,
+			BehindBy:      behind,
+		}
+	}
+	return out, nil
+}
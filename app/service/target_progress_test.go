@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeTargetRepo mengembalikan pengaturan target & jumlah verified tetap, dan mencatat rentang query.
+type fakeTargetRepo struct {
+	repository.TargetRepository
+	rows     []model.AchievementTarget
+	counts   map[uuid.UUID]int64
+	from, to time.Time
+}
+
+func (r *fakeTargetRepo) FindAll() ([]model.AchievementTarget, error) { return r.rows, nil }
+
+func (r *fakeTargetRepo) CountVerifiedBetween(_ []uuid.UUID, from, to time.Time) (map[uuid.UUID]int64, error) {
+	r.from, r.to = from, to
+	return r.counts, nil
+}
+
+// fakeTargetStudentRepo: daftar mahasiswa tetap untuk laporan target.
+type fakeTargetStudentRepo struct {
+	repository.StudentRepository
+	students []model.Student
+}
+
+func (r *fakeTargetStudentRepo) FindAll() ([]model.Student, error) {
+	return append([]model.Student{}, r.students...), nil
+}
+
+func TestTargetResolverPrecedence(t *testing.T) {
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_TARGET_PER_YEAR": "2"})
+	tests := []struct {
+		name    string
+		rows    []model.AchievementTarget
+		program string
+		want    int
+	}{
+		{name: "tanpa pengaturan → env", program: "Informatika", want: 2},
+		{name: "baris global menimpa env", rows: []model.AchievementTarget{{VerifiedPerYear: 3}}, program: "Informatika", want: 3},
+		{name: "override prodi menimpa global", program: "Informatika", want: 5,
+			rows: []model.AchievementTarget{{VerifiedPerYear: 3}, {ProgramStudy: "Informatika", VerifiedPerYear: 5}}},
+		{name: "prodi lain tetap global", program: "Sistem Informasi", want: 3,
+			rows: []model.AchievementTarget{{VerifiedPerYear: 3}, {ProgramStudy: "Informatika", VerifiedPerYear: 5}}},
+		{name: "override tanpa baris global, prodi lain → env", program: "Sistem Informasi", want: 2,
+			rows: []model.AchievementTarget{{ProgramStudy: "Informatika", VerifiedPerYear: 5}}},
+		{name: "nama prodi tidak peka huruf & spasi", program: "  informatika ", want: 5,
+			rows: []model.AchievementTarget{{ProgramStudy: "Informatika", VerifiedPerYear: 5}}},
+		{name: "override 0 tetap berlaku", program: "Informatika", want: 0,
+			rows: []model.AchievementTarget{{VerifiedPerYear: 3}, {ProgramStudy: "Informatika", VerifiedPerYear: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTargetResolver(tt.rows).For(tt.program); got != tt.want {
+				t.Fatalf("target %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetTargetProgress: ?year memilih rentang tahun akademik [1 Agustus, 1 Agustus tahun berikutnya),
+// target tiap mahasiswa mengikuti override prodinya, dan behindBy tidak pernah negatif.
+func TestGetTargetProgress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setRuntimeEnv(t, map[string]string{"ACHIEVEMENT_TARGET_PER_YEAR": "2"})
+	t.Setenv("ACADEMIC_YEAR_START_MONTH", "8")
+
+	onTarget, behind, ahead := uuid.New(), uuid.New(), uuid.New()
+	students := &fakeTargetStudentRepo{students: []model.Student{
+		{ID: onTarget, StudentID: "2024001", ProgramStudy: "Informatika"},
+		{ID: behind, StudentID: "2024002", ProgramStudy: "Informatika"},
+		{ID: ahead, StudentID: "2024003", ProgramStudy: "Sistem Informasi"},
+	}}
+	targets := &fakeTargetRepo{
+		rows:   []model.AchievementTarget{{ProgramStudy: "informatika", VerifiedPerYear: 4}},
+		counts: map[uuid.UUID]int64{onTarget: 4, behind: 1, ahead: 7},
+	}
+	svc := NewReportService(nil, nil, students, targets, nil, nil)
+	r := gin.New()
+	r.GET("/reports/target-progress", func(c *gin.Context) { c.Set("role", "admin") }, svc.GetTargetProgress)
+
+	type item struct {
+		StudentID     uuid.UUID `json:"studentId"`
+		Target        int       `json:"target"`
+		VerifiedCount int64     `json:"verifiedCount"`
+		BehindBy      int64     `json:"behindBy"`
+	}
+	get := func(query string) (int, []item) {
+		t.Helper()
+		w, _ := doJSON(t, r, http.MethodGet, "/reports/target-progress"+query, "", nil)
+		var resp struct {
+			Data struct {
+				Items []item `json:"items"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Data.Items
+	}
+
+	code, items := get("?year=2024")
+	if code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	wantFrom := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.Local)
+	if !targets.from.Equal(wantFrom) || !targets.to.Equal(wantFrom.AddDate(1, 0, 0)) {
+		t.Fatalf("rentang [%v, %v), want [%v, %v)", targets.from, targets.to, wantFrom, wantFrom.AddDate(1, 0, 0))
+	}
+	want := []item{
+		{StudentID: onTarget, Target: 4, VerifiedCount: 4},
+		{StudentID: behind, Target: 4, VerifiedCount: 1, BehindBy: 3},
+		{StudentID: ahead, Target: 2, VerifiedCount: 7},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items %+v, want %+v", items, want)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Fatalf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+
+	if _, items := get("?year=2024&behindOnly=true"); len(items) != 1 || items[0].StudentID != behind {
+		t.Fatalf("behindOnly: %+v, want hanya %s", items, behind)
+	}
+	if _, items := get("?programStudy=sistem%20informasi"); len(items) != 1 || items[0].StudentID != ahead {
+		t.Fatalf("filter prodi: %+v, want hanya %s", items, ahead)
+	}
+	for _, q := range []string{"?year=abc", "?year=1899"} {
+		if code, _ := get(q); code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d, want 400", q, code)
+		}
+	}
+}
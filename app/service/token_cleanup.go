@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/utils"
+)
+
+// TokenCleanupJob menghapus baris denylist (revoked_tokens) yang token-nya sudah
+// kedaluwarsa; token tsb sudah ditolak ValidateToken sehingga tidak perlu dicek lagi.
+type TokenCleanupJob struct {
+	revokedRepo repository.RevokedTokenRepository
+	every       time.Duration
+}
+
+// NewTokenCleanupJob membuat job dari env TOKEN_CLEANUP_INTERVAL (default 1h).
+func NewTokenCleanupJob(revokedRepo repository.RevokedTokenRepository) *TokenCleanupJob {
+	return &TokenCleanupJob{
+		revokedRepo: revokedRepo,
+		every:       utils.GetEnvDuration("TOKEN_CLEANUP_INTERVAL", time.Hour),
+	}
+}
+
+// Start menjalankan RunOnce setiap interval sampai ctx dibatalkan.
+func (j *TokenCleanupJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			j.RunOnce()
+		}
+	}()
+}
+
+// RunOnce menghapus baris denylist yang sudah kedaluwarsa.
+func (j *TokenCleanupJob) RunOnce() {
+	n, err := j.revokedRepo.DeleteExpired(time.Now())
+	if err != nil {
+		log.Printf("⚠️  gagal membersihkan denylist token: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("🧹 denylist token: %d baris kedaluwarsa dihapus", n)
+	}
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// twoFactorRecoveryCodeCount adalah jumlah kode pemulihan yang dibuat saat setup.
+const twoFactorRecoveryCodeCount = 10
+
+// ===============================================================
+//  POST /api/v1/auth/2fa/setup
+//  Admin: buat secret TOTP + kode pemulihan baru. 2FA belum aktif sampai
+//  kode pertama dikirim ke /auth/2fa/verify. Secret & kode pemulihan hanya
+//  ditampilkan di response ini.
+// ===============================================================
+func (s *authService) SetupTwoFactor(ctx *gin.Context) {
+	user, ok := s.twoFactorUser(ctx)
+	if !ok {
+		return
+	}
+	if user.TwoFactorEnabled {
+		utils.RespondError(ctx, http.StatusConflict,
+			"2FA sudah aktif", "2fa_already_enabled", nil)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat secret 2FA", err.Error(), nil)
+		return
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, utils.ErrSecretKeyMissing) {
+			status = http.StatusServiceUnavailable
+		}
+		utils.RespondError(ctx, status,
+			"2FA belum dapat digunakan di server ini", err.Error(), nil)
+		return
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(twoFactorRecoveryCodeCount)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membuat kode pemulihan", err.Error(), nil)
+		return
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = utils.HashRecoveryCode(c)
+	}
+
+	if err := s.userRepo.SaveTwoFactorSetup(user.ID, encrypted, hashes); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal menyimpan setup 2FA", err.Error(), nil)
+		return
+	}
+
+	issuer := os.Getenv("TWO_FACTOR_ISSUER")
+	if issuer == "" {
+		issuer = "Student Achievement"
+	}
+	utils.RespondOK(ctx,
+		"Pindai QR / masukkan secret di aplikasi authenticator, lalu verifikasi kodenya", map[string]any{
+			"secret":        secret,
+			"otpauthUrl":    utils.TOTPAuthURL(issuer, user.Username, secret),
+			"recoveryCodes": codes,
+		})
+}
+
+// ===============================================================
+//  POST /api/v1/auth/2fa/verify
+//  Body: { "code": "123456" }
+//  Admin: aktifkan 2FA dengan kode pertama dari aplikasi authenticator.
+// ===============================================================
+func (s *authService) VerifyTwoFactor(ctx *gin.Context) {
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input verifikasi 2FA tidak valid", err.Error(), nil)
+		return
+	}
+
+	user, ok := s.twoFactorUser(ctx)
+	if !ok {
+		return
+	}
+	if user.TwoFactorEnabled {
+		utils.RespondError(ctx, http.StatusConflict,
+			"2FA sudah aktif", "2fa_already_enabled", nil)
+		return
+	}
+	if user.TwoFactorSecret == "" {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Jalankan setup 2FA terlebih dahulu", "2fa_not_setup", nil)
+		return
+	}
+
+	secret, err := utils.DecryptSecret(user.TwoFactorSecret)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal membaca secret 2FA", err.Error(), nil)
+		return
+	}
+	step, valid := utils.ValidateTOTP(secret, input.Code, time.Now())
+	if !valid {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Kode 2FA salah", "invalid_2fa_code", nil)
+		return
+	}
+
+	if err := s.userRepo.EnableTwoFactor(user.ID, step); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal mengaktifkan 2FA", err.Error(), nil)
+		return
+	}
+
+	utils.RespondOK(ctx,
+		"2FA berhasil diaktifkan", map[string]any{"enabled": true})
+}
+
+// ===============================================================
+//  POST /api/v1/auth/2fa/challenge
+//  Body: { "mfaToken": "...", "code": "123456" }
+//  Tahap kedua login untuk user dengan 2FA aktif: tukar mfaToken + kode TOTP
+//  (atau kode pemulihan) dengan access & refresh token.
+// ===============================================================
+func (s *authService) TwoFactorChallenge(ctx *gin.Context) {
+	var input struct {
+		MFAToken string `json:"mfaToken" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(ctx, http.StatusBadRequest,
+			"Input 2FA tidak valid", err.Error(), nil)
+		return
+	}
+
+	claims, err := utils.ValidateMFAToken(input.MFAToken)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Sesi 2FA tidak valid atau kedaluwarsa, silakan login ulang", "invalid_mfa_token", nil)
+		return
+	}
+	// mfaToken hanya sekali pakai: jti-nya masuk denylist setelah login berhasil.
+	if used, err := s.revokedRepo.IsRevoked(claims.ID); err != nil || used {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Sesi 2FA tidak valid atau kedaluwarsa, silakan login ulang", "invalid_mfa_token", nil)
+		return
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.TwoFactorEnabled {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Sesi 2FA tidak valid atau kedaluwarsa, silakan login ulang", "invalid_mfa_token", nil)
+		return
+	}
+	if !user.IsActive {
+		s.recordLoginEvent(ctx, &user.ID, user.Username, false, model.LoginFailureInactiveAccount)
+		utils.RespondError(ctx, http.StatusForbidden,
+			"Akun dinonaktifkan", "inactive account", nil)
+		return
+	}
+
+	valid, err := s.checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memverifikasi kode 2FA", err.Error(), nil)
+		return
+	}
+	if !valid {
+		s.recordLoginEvent(ctx, &user.ID, user.Username, false, model.LoginFailureInvalid2FA)
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"Kode 2FA salah", "invalid_2fa_code", nil)
+		return
+	}
+
+	expiresAt := time.Now().Add(utils.MFATokenTTL())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.revokedRepo.Revoke(claims.ID, user.ID, expiresAt); err != nil {
+		utils.RespondError(ctx, http.StatusInternalServerError,
+			"Gagal memverifikasi kode 2FA", err.Error(), nil)
+		return
+	}
+
+	s.completeLogin(ctx, user, user.Username, claims.RememberMe)
+}
+
+// checkTwoFactorCode menerima kode TOTP 6 digit (tiap langkah hanya sekali pakai)
+// atau kode pemulihan (dihapus setelah dipakai).
+func (s *authService) checkTwoFactorCode(user *model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		secret, err := utils.DecryptSecret(user.TwoFactorSecret)
+		if err != nil {
+			return false, err
+		}
+		step, valid := utils.ValidateTOTP(secret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+		return s.userRepo.UseTwoFactorStep(user.ID, step)
+	}
+	return s.userRepo.ConsumeRecoveryCode(user.ID, utils.HashRecoveryCode(code))
+}
+
+// twoFactorUser memuat user yang sedang login; setup 2FA saat ini hanya untuk admin.
+func (s *authService) twoFactorUser(ctx *gin.Context) (*model.User, bool) {
+	if ctx.GetString("role") != "admin" {
+		utils.RespondError(ctx, http.StatusForbidden,
+			"2FA saat ini hanya tersedia untuk akun admin", "forbidden", nil)
+		return nil, false
+	}
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusUnauthorized,
+			"User belum terautentikasi", "no_user_id", nil)
+		return nil, false
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		utils.RespondError(ctx, http.StatusNotFound,
+			"User tidak ditemukan", err.Error(), nil)
+		return nil, false
+	}
+	return user, true
+}
+
+// ===============================================================
+//  POST /api/v1/admin/users/:id/2fa/reset
+//  Admin: reset 2FA user lain (mis. perangkat authenticator hilang). 2FA
+//  nonaktif, secret & kode pemulihan dihapus, sesi user harus login ulang;
+//  user bisa menjalankan setup 2FA lagi setelahnya.
+// ===============================================================
+func (s *adminService) ResetUserTwoFactor(ctx *gin.Context) {
+
+	if !ensureAdmin(ctx) {
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest,
+			utils.BuildResponseFailed("ID user tidak valid", err.Error(), nil))
+		return
+	}
+	actorID, _ := getUserIDFromContext(ctx)
+	if actorID == userID {
+		ctx.JSON(http.StatusForbidden,
+			utils.BuildResponseFailed("2FA akun sendiri harus direset oleh admin lain", "cannot_reset_self", nil))
+		return
+	}
+
+	reset, err := s.userRepo.ResetTwoFactor(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError,
+			utils.BuildResponseFailed("Gagal mereset 2FA", err.Error(), nil))
+		return
+	}
+	if !reset {
+		ctx.JSON(http.StatusNotFound,
+			utils.BuildResponseFailed("User tidak ditemukan atau 2FA belum aktif", "not_found", nil))
+		return
+	}
+	middleware.ForgetTokenVersion(userID)
+
+	_ = s.auditRepo.Record(ctx, &actorID, "user.2fa_reset", "user", userID.String(), nil)
+
+	ctx.JSON(http.StatusOK,
+		utils.BuildResponseSuccess("2FA user berhasil direset", nil))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMFATokenIsSingleUse(t *testing.T) {
+	user := newTestUser(t, "admin2fa", "Rahasia#2026", "admin")
+	user.TwoFactorEnabled = true
+	user.TwoFactorRecoveryCodes = []string{utils.HashRecoveryCode("pulih-satu"), utils.HashRecoveryCode("pulih-dua")}
+	f := newAuthFixture(t, user)
+
+	r := gin.New()
+	r.POST("/login", f.svc.Login)
+	r.POST("/2fa/challenge", f.svc.TwoFactorChallenge)
+
+	w, data := doJSON(t, r, http.MethodPost, "/login", "", map[string]any{"username": "admin2fa", "password": "Rahasia#2026"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", w.Code, w.Body)
+	}
+	mfaToken, _ := data["mfaToken"].(string)
+
+	tests := []struct {
+		name string
+		code string
+		want int
+	}{
+		{name: "pemakaian pertama", code: "pulih-satu", want: http.StatusOK},
+		{name: "mfaToken dipakai ulang dengan kode valid lain", code: "pulih-dua", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w, _ := doJSON(t, r, http.MethodPost, "/2fa/challenge", "", map[string]any{"mfaToken": mfaToken, "code": tt.code})
+		if w.Code != tt.want {
+			t.Fatalf("%s: status %d, want %d (body %s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestResetUserTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := uuid.New()
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		self       bool
+		wantStatus int
+	}{
+		{name: "reset 2FA user lain", enabled: true, wantStatus: http.StatusOK},
+		{name: "2FA belum aktif", wantStatus: http.StatusNotFound},
+		{name: "akun sendiri ditolak", enabled: true, self: true, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newTestUser(t, "target", "Rahasia#2026", "admin")
+			if tt.self {
+				target.ID = adminID
+			}
+			target.TwoFactorEnabled, target.TwoFactorSecret = tt.enabled, "rahasia-terenkripsi"
+			users := newFakeUserRepo(target)
+			audit := &fakeAuditRepo{}
+			svc := NewAdminService(nil, nil, audit, nil, nil, nil, nil, users, nil, nil)
+
+			r := gin.New()
+			r.POST("/users/:id/2fa/reset", func(c *gin.Context) {
+				c.Set("role", "admin")
+				c.Set("userID", adminID)
+			}, svc.ResetUserTwoFactor)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/"+target.ID.String()+"/2fa/reset", nil))
+
+			checkEnvelope(t, w)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			got, _ := users.FindByID(target.ID)
+			reset := tt.wantStatus == http.StatusOK
+			if reset != (!got.TwoFactorEnabled && got.TwoFactorSecret == "" && got.TokenVersion == 1) {
+				t.Fatalf("state 2FA setelah request: enabled=%v secret=%q tv=%d", got.TwoFactorEnabled, got.TwoFactorSecret, got.TokenVersion)
+			}
+			if reset != (len(audit.actions) == 1 && audit.actions[0] == "user.2fa_reset") {
+				t.Fatalf("audit %v", audit.actions)
+			}
+		})
+	}
+}
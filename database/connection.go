@@ -45,6 +45,11 @@ func InitDB() (*Database, error) {
 		return nil, fmt.Errorf("gagal koneksi ke postgres: %v", err)
 	}
 
+	// Child span per query GORM (no-op jika tracing tidak aktif)
+	if err := pgDB.Use(gormTracing{}); err != nil {
+		return nil, fmt.Errorf("gagal memasang tracing gorm: %v", err)
+	}
+
 	// 2. ENABLE EXTENSION PGCRYPTO — diperlukan untuk gen_random_uuid()
 	if err := pgDB.Exec(`CREATE EXTENSION IF NOT EXISTS "pgcrypto";`).Error; err != nil {
 		return nil, fmt.Errorf("gagal enable pgcrypto: %v", err)
@@ -61,11 +66,31 @@ func InitDB() (*Database, error) {
 		&model.Student{},
 		&model.Lecturer{},
 		&model.AchievementReference{},
+		&model.AchievementStatusLog{},
+		&model.AuditLog{},
+		&model.VerificationDelegation{},
+		&model.AchievementTarget{},
+		&model.Holiday{},
+		&model.PointRule{},
+		&model.AchievementType{},
+		&model.PendingNotification{},
+		&model.OutboxEvent{},
+		&model.OutboxDelivery{},
+		&model.RetentionPolicy{},
+		&model.APIKey{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.LoginEvent{},
+		&model.PasswordHistory{},
 	)
 	if err != nil {
 		log.Fatalf("❌ Migration error: %v", err)
 	}
 
+	if err := syncAchievementStatusConstraint(pgDB); err != nil {
+		log.Fatalf("❌ Migration error (status constraint): %v", err)
+	}
+
 	log.Println("✅ Migration complete")
 
 	// 4. KONEKSI MONGODB
@@ -86,6 +111,9 @@ func InitDB() (*Database, error) {
 	// 5. OPSIONAL: BUAT INDEX UNTUK COLLECTION achievements
 	//    - studentId: untuk query list prestasi per mahasiswa
 	//    - details.customFields.isDeleted: untuk filter soft-delete
+	//    - text title + description: untuk pencarian ?q= (SearchDetailIDs)
+	//    - studentId + title: untuk deteksi duplikat (FindDuplicateCandidates)
+	//    - tags: untuk autocomplete tag (FindTagCounts)
 	achievementsCol := mongoDB.Collection("achievements")
 	indexView := achievementsCol.Indexes()
 	_, err = indexView.CreateMany(ctx, []mongo.IndexModel{
@@ -95,6 +123,16 @@ func InitDB() (*Database, error) {
 		{
 			Keys: bson.D{{Key: "details.customFields.isDeleted", Value: 1}},
 		},
+		{
+			Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("achievements_text"),
+		},
+		{
+			Keys: bson.D{{Key: "studentId", Value: 1}, {Key: "title", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}},
+		},
 	})
 	if err != nil {
 		log.Printf("[MONGO] Gagal membuat index achievements: %v", err)
@@ -102,6 +140,20 @@ func InitDB() (*Database, error) {
 		log.Println("[MONGO] Index achievements siap ✔")
 	}
 
+	// Komentar prestasi dibaca per prestasi, urut waktu
+	if _, err := mongoDB.Collection("achievement_comments").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "achievementId", Value: 1}, {Key: "createdAt", Value: 1}},
+	}); err != nil {
+		log.Printf("[MONGO] Gagal membuat index achievement_comments: %v", err)
+	}
+
+	// Revisi isi prestasi dibaca per dokumen prestasi, terbaru dulu
+	if _, err := mongoDB.Collection("achievement_revisions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "achievementId", Value: 1}, {Key: "createdAt", Value: -1}},
+	}); err != nil {
+		log.Printf("[MONGO] Gagal membuat index achievement_revisions: %v", err)
+	}
+
 	log.Println("Berhasil terhubung ke PostgreSQL & MongoDB! ✔")
 
 	return &Database{
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"fmt"
+
+	"student-achievement-backend/app/model"
+
+	"gorm.io/gorm"
+)
+
+// syncAchievementStatusConstraint membuat ulang check constraint status prestasi
+// dari model.AchievementStatuses(), sehingga constraint DB selalu sama dengan daftar di Go.
+func syncAchievementStatusConstraint(db *gorm.DB) error {
+	name := model.AchievementStatusConstraintName
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf(
+			`ALTER TABLE achievement_references DROP CONSTRAINT IF EXISTS %s`, name,
+		)).Error; err != nil {
+			return err
+		}
+		return tx.Exec(achievementStatusConstraintSQL()).Error
+	})
+}
+
+// achievementStatusConstraintSQL adalah statement pembuat check constraint status prestasi.
+func achievementStatusConstraintSQL() string {
+	return fmt.Sprintf(
+		`ALTER TABLE achievement_references ADD CONSTRAINT %s CHECK (%s)`,
+		model.AchievementStatusConstraintName, model.AchievementStatusCheckSQL(),
+	)
+}
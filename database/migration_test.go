@@ -0,0 +1,45 @@
+package database
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"student-achievement-backend/app/model"
+)
+
+// TestAchievementStatusConstraintMatchesModel mem-parse SQL migrasi check constraint dan
+// membandingkannya dengan model.AchievementStatuses(): keduanya tidak boleh berbeda.
+func TestAchievementStatusConstraintMatchesModel(t *testing.T) {
+	sql := achievementStatusConstraintSQL()
+	re := regexp.MustCompile(`(?i)^ALTER TABLE achievement_references ADD CONSTRAINT (\w+) CHECK \(\s*status IN \(([^)]*)\)\s*\)$`)
+	m := re.FindStringSubmatch(sql)
+	if m == nil {
+		t.Fatalf("SQL constraint tidak dikenali: %s", sql)
+	}
+	if m[1] != model.AchievementStatusConstraintName {
+		t.Fatalf("nama constraint %q, want %q", m[1], model.AchievementStatusConstraintName)
+	}
+
+	var inDB []string
+	for _, v := range strings.Split(m[2], ",") {
+		v = strings.TrimSpace(v)
+		if len(v) < 2 || v[0] != '\'' || v[len(v)-1] != '\'' {
+			t.Fatalf("nilai %q di constraint bukan string literal", v)
+		}
+		inDB = append(inDB, v[1:len(v)-1])
+	}
+
+	inGo := model.AchievementStatuses()
+	slices.Sort(inDB)
+	slices.Sort(inGo)
+	if !slices.Equal(inDB, inGo) {
+		t.Fatalf("status di constraint DB %v, di Go %v", inDB, inGo)
+	}
+	for _, s := range inGo {
+		if !model.IsValidAchievementStatus(s) {
+			t.Fatalf("status %q tidak lolos IsValidAchievementStatus", s)
+		}
+	}
+}
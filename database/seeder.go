@@ -2,15 +2,23 @@ package database
 
 import (
 	"log"
+	"os"
 	"time"
 
 	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// DefaultSeedPassword adalah password yang dipakai seluruh akun hasil seeder.
+const DefaultSeedPassword = "123123"
+
+// seededUsernames adalah daftar username yang dibuat oleh seeder.
+var seededUsernames = []string{"admin", "doswal", "mahasiswa1", "mahasiswa2"}
+
 // ===============================
 //  SEED ROLES (admin, dosen_wali, mahasiswa)
 // ===============================
@@ -53,8 +61,7 @@ func SeedUsers(db *gorm.DB) {
 	db.Where("name = ?", "dosen_wali").First(&doswalRole)
 	db.Where("name = ?", "mahasiswa").First(&mhsRole)
 
-	password := "123123"
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
+	hash, _ := utils.HashPassword(DefaultSeedPassword)
 
 	users := []model.User{
 		{
@@ -118,8 +125,7 @@ func SeedMahasiswaKedua(db *gorm.DB) {
 	}
 
 	// Hash password (pakai password yang sama: 123123)
-	password := "123123"
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
+	hash, _ := utils.HashPassword(DefaultSeedPassword)
 
 	// Buat user baru untuk mahasiswa2
 	newUser := model.User{
@@ -173,3 +179,117 @@ func RunSeeders(db *gorm.DB) {
 	SeedUsers(db)
 	SeedMahasiswaKedua(db)
 }
+
+// ===============================
+//  CEK KREDENSIAL DEFAULT (APP_ENV=production)
+//  - Log peringatan jika akun seed masih memakai password default
+//  - Jika FORCE_DEFAULT_PASSWORD_CHANGE=true, akun tsb ditandai
+//    must_change_password sehingga wajib ganti password saat login
+// ===============================
+func CheckDefaultCredentials(db *gorm.DB) {
+	if os.Getenv("APP_ENV") != "production" {
+		return
+	}
+
+	var users []model.User
+	if err := db.Where("username IN ?", seededUsernames).Find(&users).Error; err != nil {
+		log.Printf("[SECURITY] Gagal memeriksa kredensial default: %v", err)
+		return
+	}
+
+	force := os.Getenv("FORCE_DEFAULT_PASSWORD_CHANGE") == "true"
+
+	for _, u := range users {
+		if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(DefaultSeedPassword)) != nil {
+			continue
+		}
+
+		log.Printf("⚠️  [SECURITY] Akun '%s' masih memakai password default hasil seeder! Segera ganti password.", u.Username)
+
+		if force && !u.MustChangePassword {
+			if err := db.Model(&model.User{}).
+				Where("id = ?", u.ID).
+				Update("must_change_password", true).Error; err != nil {
+				log.Printf("[SECURITY] Gagal menandai must_change_password untuk '%s': %v", u.Username, err)
+			}
+		}
+	}
+}
+
+// ===============================
+//  SEED RUBRIK POIN (point_rules)
+//   - Hanya jalan kalau tabel point_rules masih kosong
+//   - Rubrik kampus: juara 1-3 per tingkat lomba, peserta per tingkat,
+//     lalu nilai dasar untuk publikasi / organisasi / sertifikasi
+// ===============================
+func SeedPointRules(db *gorm.DB) {
+	var count int64
+	db.Model(&model.PointRule{}).Count(&count)
+	if count > 0 {
+		log.Println("[SEEDER] Rubrik poin sudah ada, skip seeding.")
+		return
+	}
+
+	// poin per tingkat: juara 1, 2, 3, peserta
+	levels := []struct {
+		level  string
+		points [4]float64
+	}{
+		{"international", [4]float64{50, 45, 40, 20}},
+		{"national", [4]float64{35, 30, 25, 10}},
+		{"regional", [4]float64{20, 15, 10, 5}},
+		{"local", [4]float64{10, 8, 6, 2}},
+	}
+
+	var rules []model.PointRule
+	for _, l := range levels {
+		level := l.level
+		for i := 0; i < 3; i++ {
+			rank := i + 1
+			rules = append(rules, model.PointRule{
+				AchievementType: "competition", CompetitionLevel: &level, Rank: &rank, Points: l.points[i],
+			})
+		}
+		rules = append(rules, model.PointRule{
+			AchievementType: "competition", CompetitionLevel: &level, Points: l.points[3],
+		})
+	}
+	rules = append(rules,
+		model.PointRule{AchievementType: "publication", Points: 25},
+		model.PointRule{AchievementType: "organization", Points: 10},
+		model.PointRule{AchievementType: "certification", Points: 10},
+	)
+
+	if err := db.Create(&rules).Error; err != nil {
+		log.Fatalf("[SEEDER] Gagal seed rubrik poin: %v", err)
+	}
+
+	log.Printf("[SEEDER] Berhasil seed %d aturan poin", len(rules))
+}
+
+// ===============================
+//  SEED KATALOG TIPE PRESTASI (achievement_types)
+//   - Hanya jalan kalau tabel achievement_types masih kosong
+//   - Tipe bawaan SRS; defaultPoints dipakai jika tidak ada point_rules yang cocok
+// ===============================
+func SeedAchievementTypes(db *gorm.DB) {
+	var count int64
+	db.Model(&model.AchievementType{}).Count(&count)
+	if count > 0 {
+		log.Println("[SEEDER] Katalog tipe prestasi sudah ada, skip seeding.")
+		return
+	}
+
+	types := []model.AchievementType{
+		{Code: model.AchievementTypeCompetition, Label: "Kompetisi", Active: true, DefaultPoints: 2},
+		{Code: model.AchievementTypePublication, Label: "Publikasi", Active: true, DefaultPoints: 25},
+		{Code: model.AchievementTypeOrganization, Label: "Organisasi", Active: true, DefaultPoints: 10},
+		{Code: model.AchievementTypeCertification, Label: "Sertifikasi", Active: true, DefaultPoints: 10},
+	}
+
+	if err := db.Create(&types).Error; err != nil {
+		log.Fatalf("[SEEDER] Gagal seed katalog tipe prestasi: %v", err)
+	}
+
+	log.Printf("[SEEDER] Berhasil seed %d tipe prestasi", len(types))
+}
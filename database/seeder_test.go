@@ -0,0 +1,164 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// seedTestUser memastikan akun seed username ada dengan password tertentu & flag
+// must_change_password=false. Akun yang sudah ada dikembalikan ke kondisi semula setelah test.
+func seedTestUser(t *testing.T, db *gorm.DB, username, password string) model.User {
+	t.Helper()
+	role := model.Role{Name: "mahasiswa"}
+	if err := db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+		t.Fatal(err)
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Now()
+
+	var user model.User
+	err = db.Where("username = ?", username).First(&user).Error
+	switch {
+	case err == nil:
+		original := user
+		t.Cleanup(func() {
+			db.Model(&model.User{}).Where("id = ?", original.ID).Updates(map[string]any{
+				"password_hash":        original.PasswordHash,
+				"must_change_password": original.MustChangePassword,
+				"is_active":            original.IsActive,
+			})
+		})
+	case err == gorm.ErrRecordNotFound:
+		user = model.User{Username: username, Email: username + "@kampus.ac.id", FullName: username, RoleID: role.ID}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Delete(&model.User{}, "id = ?", user.ID) })
+	default:
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&model.RefreshToken{}, "user_id = ? AND created_at >= ?", user.ID, started)
+		db.Delete(&model.LoginEvent{}, "user_id = ? AND created_at >= ?", user.ID, started)
+	})
+
+	if err := db.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+		"password_hash":        string(hash),
+		"must_change_password": false,
+		"is_active":            true,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// mustChangePassword membaca flag must_change_password user dari database.
+func mustChangePassword(t *testing.T, db *gorm.DB, user model.User) bool {
+	t.Helper()
+	var got model.User
+	if err := db.Select("must_change_password").Where("id = ?", user.ID).First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	return got.MustChangePassword
+}
+
+// TestCheckDefaultCredentialsForcesPasswordChange: di production dengan
+// FORCE_DEFAULT_PASSWORD_CHANGE=true, akun seed yang masih memakai password default ditandai
+// must_change_password, lalu token hasil login-nya ditolak 403 password_change_required.
+func TestCheckDefaultCredentialsForcesPasswordChange(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN tidak di-set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("koneksi postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	seeded := seedTestUser(t, db, "mahasiswa1", DefaultSeedPassword)
+	changed := seedTestUser(t, db, "mahasiswa2", "Sudah#Diganti2026")
+
+	// Selain production, atau tanpa FORCE_DEFAULT_PASSWORD_CHANGE: hanya peringatan.
+	for _, env := range []struct{ appEnv, force string }{
+		{appEnv: "development", force: "true"},
+		{appEnv: "production", force: ""},
+	} {
+		t.Setenv("APP_ENV", env.appEnv)
+		t.Setenv("FORCE_DEFAULT_PASSWORD_CHANGE", env.force)
+		CheckDefaultCredentials(db)
+		if mustChangePassword(t, db, seeded) {
+			t.Fatalf("APP_ENV=%s FORCE=%q: akun seed ditandai", env.appEnv, env.force)
+		}
+	}
+
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("FORCE_DEFAULT_PASSWORD_CHANGE", "true")
+	CheckDefaultCredentials(db)
+	if !mustChangePassword(t, db, seeded) {
+		t.Fatal("akun seed dengan password default tidak ditandai must_change_password")
+	}
+	if mustChangePassword(t, db, changed) {
+		t.Fatal("akun seed yang passwordnya sudah diganti ikut ditandai")
+	}
+
+	// Login tetap berhasil, tapi endpoint lain menolak token sebelum password diganti.
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	auth := service.NewAuthService(repository.NewUserRepository(db), repository.NewRefreshTokenRepository(db),
+		repository.NewRevokedTokenRepository(db), repository.NewLoginEventRepository(db), nil)
+	r := gin.New()
+	r.POST("/api/v1/auth/login", auth.Login)
+	r.GET("/api/v1/achievements", middleware.AuthMiddleware(), func(c *gin.Context) {
+		utils.RespondOK(c, "ok", nil)
+	})
+
+	body, _ := json.Marshal(map[string]string{"username": "mahasiswa1", "password": DefaultSeedPassword})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", w.Code, w.Body)
+	}
+	var login struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &login); err != nil || login.Data.Token == "" {
+		t.Fatalf("token login tidak ada: %s", w.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/achievements", nil)
+	req.Header.Set("Authorization", "Bearer "+login.Data.Token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "password_change_required") {
+		t.Fatalf("endpoint lain: status %d, body %s, want 403 password_change_required", w.Code, w.Body)
+	}
+}
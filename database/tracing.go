@@ -0,0 +1,77 @@
+package database
+
+import (
+	"student-achievement-backend/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey menyimpan span aktif di gorm.Statement selama 1 operasi.
+const gormSpanKey = "otel:span"
+
+// gormTracing adalah plugin GORM yang membuat child span untuk setiap query.
+// Span menjadi anak dari span request hanya jika query dijalankan dengan
+// db.WithContext(ctx); tanpa itu span berdiri sendiri (root span).
+type gormTracing struct{}
+
+// Name memenuhi interface gorm.Plugin.
+func (gormTracing) Name() string { return "otel-tracing" }
+
+// Initialize mendaftarkan callback before/after di semua jenis operasi GORM.
+func (p gormTracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	type hooks struct {
+		before, after func(name string, fn func(*gorm.DB)) error
+	}
+	ops := map[string]hooks{
+		"create": {cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		"query":  {cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		"update": {cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		"delete": {cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		"row":    {cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		"raw":    {cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for op, h := range ops {
+		if err := h.before("otel:before_"+op, p.before(op)); err != nil {
+			return err
+		}
+		if err := h.after("otel:after_"+op, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gormTracing) before(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := utils.StartSpan(db.Statement.Context, "postgres."+op,
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", op),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (gormTracing) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.collection.name", db.Statement.Table))
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", db.RowsAffected))
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/utils"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordSpans memasang TracerProvider global dengan SpanRecorder selama 1 test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+// openDryRunDB membuka GORM postgres mode DryRun (SQL dibangun tanpa koneksi) dengan plugin tracing.
+func openDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 sslmode=disable"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(gormTracing{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// TestGormTracingChildSpans: query lewat db.WithContext(ctx) menjadi child span request dengan
+// atribut db.*, query tanpa context menjadi root span, dan error query menandai span Error.
+func TestGormTracingChildSpans(t *testing.T) {
+	rec := recordSpans(t)
+	db := openDryRunDB(t)
+
+	ctx, parent := utils.StartSpan(context.Background(), "request")
+	var user model.User
+	db.WithContext(ctx).Where("username = ?", "budi").First(&user)
+	db.WithContext(ctx).Create(&model.User{Username: "budi"})
+	parent.End()
+	db.Where("username = ?", "siti").First(&user)
+
+	spans := rec.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("%d span, want 4 (2 query + request + query tanpa context)", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() || s.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Fatalf("span %s bukan child span request", s.Name())
+		}
+		if v, _ := spanAttr(s, "db.system"); v.AsString() != "postgresql" {
+			t.Fatalf("span %s db.system %q", s.Name(), v.AsString())
+		}
+		if v, _ := spanAttr(s, "db.collection.name"); v.AsString() != "users" {
+			t.Fatalf("span %s db.collection.name %q, want users", s.Name(), v.AsString())
+		}
+		if s.Status().Code == codes.Error {
+			t.Fatalf("span %s berstatus error: %s", s.Name(), s.Status().Description)
+		}
+	}
+	if spans[0].Name() != "postgres.query" || spans[1].Name() != "postgres.create" {
+		t.Fatalf("nama span %q, %q", spans[0].Name(), spans[1].Name())
+	}
+	if root := spans[3]; root.Name() != "postgres.query" || root.Parent().IsValid() {
+		t.Fatalf("query tanpa context: span %s parent %v, want root span", root.Name(), root.Parent())
+	}
+
+	t.Run("query gagal", func(t *testing.T) {
+		rec := recordSpans(t)
+		db := openDryRunDB(t)
+		if err := db.Callback().Query().After("gorm:query").Before("otel:after_query").
+			Register("test:fail", func(tx *gorm.DB) { _ = tx.AddError(errors.New("connection reset")) }); err != nil {
+			t.Fatal(err)
+		}
+		db.Where("username = ?", "budi").Find(&[]model.User{})
+
+		spans := rec.Ended()
+		if len(spans) != 1 || spans[0].Status().Code != codes.Error || spans[0].Status().Description != "connection reset" {
+			t.Fatalf("span %+v, want 1 span berstatus error", spans)
+		}
+		if len(spans[0].Events()) != 1 || spans[0].Events()[0].Name != "exception" {
+			t.Fatalf("event %+v, want error tercatat", spans[0].Events())
+		}
+	})
+}
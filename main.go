@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"student-achievement-backend/app/repository"
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/database"
+	"student-achievement-backend/middleware"
 	"student-achievement-backend/routes"
+	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -18,7 +27,7 @@ import (
 // - init PostgreSQL + MongoDB
 // - seed roles & users default
 // - inisialisasi repository, service, dan routes
-// - menjalankan HTTP server Gin
+// - menjalankan HTTP server Gin sampai SIGINT/SIGTERM (lihat run)
 func main() {
 
 	// =================================================================
@@ -28,54 +37,203 @@ func main() {
 		log.Println("⚠️  .env tidak ditemukan, menggunakan environment default")
 	}
 
+	// Setting runtime non-rahasia (timeout, batas, retry); bisa di-reload via SIGHUP / endpoint admin
+	if err := utils.InitRuntimeConfig(); err != nil {
+		log.Fatalf("❌ Konfigurasi runtime tidak valid: %v", err)
+	}
+	if err := utils.ValidateJWTConfig(); err != nil {
+		log.Fatalf("❌ Konfigurasi JWT tidak valid: %v", err)
+	}
+	if err := utils.ValidateTokenTTLConfig(); err != nil {
+		log.Fatalf("❌ Konfigurasi masa berlaku token tidak valid: %v", err)
+	}
+	if err := utils.ValidateBcryptCostConfig(); err != nil {
+		log.Fatalf("❌ Konfigurasi bcrypt tidak valid: %v", err)
+	}
+
+	if err := run(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// run merangkai dependency lalu menjalankan HTTP server. Error dikembalikan (bukan log.Fatalf)
+// agar defer tetap jalan: saat SIGINT/SIGTERM server berhenti menerima request dan menunggu
+// request berjalan (SHUTDOWN_TIMEOUT), lalu worker latar dihentikan dan span tracing di-flush.
+func run() error {
+
+	// =================================================================
+	// TRACING (OpenTelemetry, dikonfigurasi lewat env OTEL_*; default no-op)
+	// =================================================================
+	shutdownTracing, err := utils.InitTracing(context.Background())
+	if err != nil {
+		log.Printf("⚠️  Tracing tidak aktif: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("⚠️  Gagal menutup tracing: %v", err)
+		}
+	}()
+
+	// Worker latar dihentikan setelah HTTP server selesai shutdown (defer dijalankan lebih dulu
+	// daripada penutupan tracing di atas).
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
 	// =================================================================
 	// INIT DB (POSTGRES + MONGODB)
 	// =================================================================
 	dbConn, err := database.InitDB()
 	if err != nil {
-		log.Fatalf("❌ Gagal koneksi database: %v", err)
+		return fmt.Errorf("gagal koneksi database: %w", err)
 	}
 
 	// =================================================================
-	// SEED DATA (ROLES + USERS)
+	// SEED DATA (ROLES + USERS + TIPE PRESTASI + RUBRIK POIN)
 	// =================================================================
 	database.SeedRoles(dbConn.Postgres)
 	database.SeedUsers(dbConn.Postgres)
+	database.SeedAchievementTypes(dbConn.Postgres)
+	database.SeedPointRules(dbConn.Postgres)
+
+	// Peringatan akun seed dengan password default (hanya APP_ENV=production)
+	database.CheckDefaultCredentials(dbConn.Postgres)
 
 	// =================================================================
 	// REPOSITORIES (akses data ke DB)
 	// =================================================================
 	userRepo := repository.NewUserRepository(dbConn.Postgres)
 	achievementRepo := repository.NewAchievementRepository(dbConn.Postgres, dbConn.Mongo)
-	studentRepo := repository.NewStudentRepository(dbConn.Postgres)
-	lecturerRepo := repository.NewLecturerRepository(dbConn.Postgres)
-	adminRepo := repository.NewUserAdminRepository(dbConn.Postgres)
-	reportRepo := repository.NewReportRepository(dbConn.Mongo)
+	// Cek dosen wali → mahasiswa di-cache (ADVISOR_CACHE_TTL, 0 = nonaktif; bisa di-reload); repository
+	// yang mengubah relasi tsb dibungkus decorator agar cache langsung di-invalidasi.
+	advisorCache := repository.NewAdvisorCache(func() time.Duration { return utils.Runtime().AdvisorCacheTTL })
+	studentRepo := repository.NewCachedStudentRepository(repository.NewStudentRepository(dbConn.Postgres), advisorCache)
+	lecturerRepo := repository.NewCachedLecturerRepository(repository.NewLecturerRepository(dbConn.Postgres), advisorCache)
+	adminRepo := repository.NewCachedUserAdminRepository(repository.NewUserAdminRepository(dbConn.Postgres, dbConn.Mongo), advisorCache)
+	// Hasil statistik di-cache per filter (STATS_CACHE_TTL, 0 = nonaktif); di bawahnya, query
+	// identik yang bersamaan digabung menjadi 1 eksekusi (singleflight)
+	reportRepo := repository.NewCachingReportRepository(
+		repository.NewCoalescingReportRepository(repository.NewReportRepository(dbConn.Mongo)),
+		func() time.Duration { return utils.Runtime().StatsCacheTTL },
+	)
+	auditRepo := repository.NewAuditRepository(dbConn.Postgres)
+	delegationRepo := repository.NewCachedDelegationRepository(repository.NewDelegationRepository(dbConn.Postgres), advisorCache)
+	targetRepo := repository.NewTargetRepository(dbConn.Postgres)
+	rbacRepo := repository.NewRBACRepository(dbConn.Postgres)
+	holidayRepo := repository.NewCachedHolidayRepository(repository.NewHolidayRepository(dbConn.Postgres))
+	pointRuleRepo := repository.NewPointRuleRepository(dbConn.Postgres)
+	achievementTypeRepo := repository.NewAchievementTypeRepository(dbConn.Postgres)
+	notificationRepo := repository.NewNotificationRepository(dbConn.Postgres)
+	outboxRepo := repository.NewOutboxRepository(dbConn.Postgres)
+	retentionRepo := repository.NewRetentionRepository(dbConn.Postgres, dbConn.Mongo)
+	apiKeyRepo := repository.NewAPIKeyRepository(dbConn.Postgres)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(dbConn.Postgres)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(dbConn.Postgres)
+	loginEventRepo := repository.NewLoginEventRepository(dbConn.Postgres)
+	registrationRepo := repository.NewRegistrationRepository(dbConn.Postgres)
+
+	// Access token yang dicabut (logout) ditolak AuthMiddleware walau belum kedaluwarsa
+	middleware.SetTokenRevocationChecker(revokedTokenRepo)
+	// Token dengan token_version lama (role/status/password berubah) ditolak; versi di-cache singkat
+	middleware.SetTokenVersionChecker(userRepo, utils.GetEnvDuration("TOKEN_VERSION_CACHE_TTL", 5*time.Second))
+
+	// =================================================================
+	// NOTIFIKASI (worker pool terbatas + limpahan ke pending_notifications)
+	// =================================================================
+	notificationDispatcher := service.NewNotificationDispatcher(notificationRepo, service.LogNotifier{})
+	notificationDispatcher.Start(workerCtx)
+
+	// =================================================================
+	// OUTBOX (event perubahan status → notifikasi & webhook OUTBOX_WEBHOOK_URL)
+	// =================================================================
+	outboxPublishers := []service.OutboxPublisher{service.NotificationPublisher{Queue: notificationDispatcher}}
+	if url := os.Getenv("OUTBOX_WEBHOOK_URL"); url != "" {
+		outboxPublishers = append(outboxPublishers, service.WebhookPublisher{URL: url})
+	}
+	outboxDispatcher := service.NewOutboxDispatcher(outboxRepo, auditRepo, outboxPublishers...)
+	outboxDispatcher.Start(workerCtx)
+
+	// =================================================================
+	// RETENSI DATA (anonimisasi terjadwal mahasiswa lulus, RETENTION_RUN_INTERVAL)
+	// =================================================================
+	retentionJob := service.NewRetentionJob(retentionRepo, auditRepo)
+	retentionJob.Start(workerCtx)
+
+	// Bersihkan denylist token yang sudah kedaluwarsa (TOKEN_CLEANUP_INTERVAL)
+	service.NewTokenCleanupJob(revokedTokenRepo).Start(workerCtx)
+
+	// Pengajuan yang tidak diverifikasi dalam SUBMISSION_EXPIRY_DAYS → expired/draft (SUBMISSION_EXPIRY_INTERVAL)
+	service.NewSubmissionExpiryJob(achievementRepo).Start(workerCtx)
+
+	// =================================================================
+	// RELOAD CONFIG (SIGHUP → baca ulang .env untuk setting non-rahasia)
+	// =================================================================
+	configReloader := service.NewConfigReloader(auditRepo)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := configReloader.Reload(context.Background(), nil, "sighup"); err != nil {
+				log.Printf("⚠️  reload config ditolak, konfigurasi lama tetap berlaku: %v", err)
+			}
+		}
+	}()
 
 	// =================================================================
 	// SERVICES (logic & handler HTTP)
 	// =================================================================
-	authService := service.NewAuthService(userRepo)
-	adminService := service.NewAdminService(adminRepo)
+	commentRepo := repository.NewAchievementCommentRepository(dbConn.Mongo)
+	revisionRepo := repository.NewAchievementRevisionRepository(dbConn.Mongo)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, revokedTokenRepo, loginEventRepo, lecturerRepo)
+	adminService := service.NewAdminService(adminRepo, achievementRepo, auditRepo, rbacRepo, holidayRepo, loginEventRepo, refreshTokenRepo, userRepo, pointRuleRepo, achievementTypeRepo)
 	achievementService := service.NewAchievementService(
 		achievementRepo,
 		userRepo,
+		studentRepo,
 		lecturerRepo,
+		auditRepo,
+		delegationRepo,
+		commentRepo,
+		pointRuleRepo,
+		achievementTypeRepo,
+		revisionRepo,
+		utils.NewLocalStorage(),
+		utils.NewScannerFromEnv(),
 	)
-	reportService := service.NewReportService(reportRepo, lecturerRepo)
-	// StudentService butuh studentRepo + achievementRepo
-	studentService := service.NewStudentService(studentRepo, achievementRepo)
-	// LecturerService versi kamu saat ini hanya butuh lecturerRepo
-	lecturerService := service.NewLecturerService(lecturerRepo)
+	reportService := service.NewReportService(reportRepo, lecturerRepo, studentRepo, targetRepo, achievementRepo, holidayRepo)
+	// StudentService butuh studentRepo + achievementRepo + reportRepo (portofolio)
+	// + auditRepo, commentRepo & revisionRepo (feed aktivitas)
+	studentService := service.NewStudentService(studentRepo, achievementRepo, reportRepo, auditRepo, commentRepo, revisionRepo)
+	// LecturerService butuh lecturerRepo + delegationRepo (delegasi verifikasi) + auditRepo + targetRepo
+	lecturerService := service.NewLecturerService(lecturerRepo, delegationRepo, auditRepo, targetRepo)
+	metaService := service.NewMetaService(achievementTypeRepo)
+	// API key integrasi (portal fakultas): akses read-only tanpa login user
+	apiKeyManager := service.NewAPIKeyManager(apiKeyRepo, auditRepo)
+	// Registrasi mandiri mahasiswa (akun aktif setelah disetujui admin)
+	registrationService := service.NewRegistrationService(registrationRepo, auditRepo)
 
 	// =================================================================
 	// ROUTER (registrasi endpoint sesuai SRS)
 	// =================================================================
 	r := gin.Default()
 
+	// gin.Context meneruskan Done/Err/Deadline dari ctx.Request.Context(),
+	// sehingga query yang menerima gin.Context ikut dibatalkan oleh middleware Timeout.
+	r.ContextWithFallback = true
+
+	// 1 span per request; span repository/DB menjadi child dari span ini
+	r.Use(middleware.Tracing())
+
+	// X-API-Key → principal integrasi (tanpa role); route terbuka lewat middleware.RequirePermission
+	r.Use(middleware.APIKeyAuth(apiKeyManager.Resolve))
+
 	// 5.1 Authentication
 	routes.AuthRoutes(r, authService)
 
+	// Registrasi mandiri mahasiswa + antrean persetujuan (admin)
+	routes.RegistrationRoutes(r, registrationService)
+
 	// 5.2 Users (Admin)
 	routes.AdminRoutes(r, adminService)
 
@@ -89,6 +247,24 @@ func main() {
 	routes.StudentRoutes(r, studentService)
 	routes.LecturerRoutes(r, lecturerService)
 
+	// Data referensi (enum status, dll) untuk frontend
+	routes.MetaRoutes(r, metaService)
+
+	// Monitoring antrean notifikasi (admin)
+	routes.NotificationRoutes(r, notificationDispatcher)
+
+	// Event outbox yang belum terkirim + retry manual (admin)
+	routes.OutboxRoutes(r, outboxDispatcher)
+
+	// Kebijakan retensi & dry-run anonimisasi (admin)
+	routes.RetentionRoutes(r, retentionJob)
+
+	// Lihat & reload setting runtime (admin)
+	routes.ConfigRoutes(r, configReloader)
+
+	// API key integrasi machine-to-machine (admin)
+	routes.APIKeyRoutes(r, apiKeyManager)
+
 	// Root endpoint (optional health check)
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -105,9 +281,36 @@ func main() {
 		port = "8080"
 	}
 
-	log.Println("🚀 Server running at http://localhost:" + port)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Println("🚀 Server running at http://localhost:" + port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
 
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("❌ Gagal menjalankan server: %v", err)
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("gagal menjalankan server: %w", err)
+		}
+		return nil
+	case sig := <-stop:
+		log.Printf("⏳ Menerima %s, menghentikan server...", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.GetEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second))
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return fmt.Errorf("gagal menghentikan server dengan rapi: %w", err)
+	}
+	// Request sudah selesai: kirim sisa antrean notifikasi sebelum worker dihentikan.
+	if err := notificationDispatcher.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  %v", err)
 	}
+	log.Println("✅ Server berhenti")
+	return nil
 }
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeyRole adalah role sintetis di context untuk request ber-API key. Bukan role di tabel
+// roles: tidak pernah lolos ensureAdmin / cek role manusia, hanya route dengan RequirePermission.
+const APIKeyRole = "service"
+
+// apiKeyIDKey adalah key gin.Context untuk ID API key pemanggil (principal machine-to-machine).
+const apiKeyIDKey = "apiKeyID"
+
+// APIKeyResolver mencari API key aktif dari key mentah dan mengembalikan ID & permission-nya.
+// Error berarti key tidak valid, dicabut, atau kedaluwarsa.
+type APIKeyResolver func(rawKey, clientIP string) (keyID uuid.UUID, permissions []string, err error)
+
+// APIKeyAuth menerima header X-API-Key (integrasi machine-to-machine, mis. portal fakultas).
+// Jika header ada dan valid, context diisi principal sintetis: apiKeyID + permissions +
+// role APIKeyRole ("service"), TANPA userID, sehingga endpoint yang dijaga role (khusus manusia) menolaknya.
+// Endpoint yang boleh diakses API key memasang RequirePermission.
+// Request tanpa header diteruskan apa adanya ke AuthMiddleware (JWT).
+func APIKeyAuth(resolve APIKeyResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader("X-API-Key"))
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		keyID, perms, err := resolve(raw, c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized,
+				utils.BuildResponseFailed("API key tidak valid, dicabut, atau kedaluwarsa", "invalid_api_key", nil))
+			c.Abort()
+			return
+		}
+
+		c.Set(apiKeyIDKey, keyID)
+		c.Set("role", APIKeyRole)
+		c.Set("permissions", perms)
+		c.Next()
+	}
+}
+
+// IsAPIKeyPrincipal true jika request diautentikasi dengan API key (bukan JWT user).
+func IsAPIKeyPrincipal(c *gin.Context) bool {
+	_, ok := c.Get(apiKeyIDKey)
+	return ok
+}
+
+// HasPermission mengecek permission principal dari context (JWT atau API key).
+func HasPermission(c *gin.Context, perm string) bool {
+	v, ok := c.Get("permissions")
+	if !ok {
+		return false
+	}
+	perms, _ := v.([]string)
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission membuka route untuk API key yang memiliki permission perm. Route tanpa
+// RequirePermission ditolak untuk API key oleh AuthMiddleware (lihat routeAllowsAPIKey).
+// Request user (JWT) diteruskan tanpa cek di sini: otorisasi user tetap berbasis role di handler.
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAPIKeyPrincipal(c) && !HasPermission(c, perm) {
+			c.JSON(http.StatusForbidden,
+				utils.BuildResponseFailed("API key tidak memiliki permission "+perm, "missing_permission", nil))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// permissionGateName adalah nama handler RequirePermission di handler chain gin
+// (semua closure RequirePermission berbagi nama fungsi yang sama).
+var permissionGateName = runtime.FuncForPC(reflect.ValueOf(RequirePermission("")).Pointer()).Name()
+
+// routeAllowsAPIKey true jika route yang sedang diproses memasang RequirePermission (opt-in API key).
+// AuthMiddleware berjalan sebelum RequirePermission, sehingga opt-in dibaca dari handler chain route.
+func routeAllowsAPIKey(c *gin.Context) bool {
+	return slices.Contains(c.HandlerNames(), permissionGateName)
+}
@@ -1,18 +1,137 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// passwordChangeAllowedPaths adalah route yang tetap boleh diakses
+// selama user berstatus must_change_password.
+var passwordChangeAllowedPaths = map[string]bool{
+	"/api/v1/auth/change-password": true,
+	"/api/v1/auth/profile":         true,
+	"/api/v1/auth/logout":          true,
+}
+
+// impersonationAllowedWrites adalah satu-satunya request non-GET yang boleh memakai token
+// impersonasi: token impersonasi hanya untuk melihat apa yang dilihat user (view-only),
+// bukan mengubah data atau mengambil alih akunnya. Logout boleh agar admin bisa mengakhiri sesi.
+var impersonationAllowedWrites = map[string]bool{
+	"POST /api/v1/auth/logout": true,
+}
+
+// impersonationAllowed: GET/HEAD/OPTIONS selalu boleh, method lain hanya yang di-whitelist.
+func impersonationAllowed(method, route string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return impersonationAllowedWrites[method+" "+route]
+}
+
+// TokenRevocationChecker mengecek denylist access token berdasarkan jti dan sesi (sid).
+type TokenRevocationChecker interface {
+	AnyRevoked(keys ...string) (bool, error)
+}
+
+// revocationChecker dipasang sekali di main (SetTokenRevocationChecker); nil = tanpa denylist.
+var revocationChecker TokenRevocationChecker
+
+// SetTokenRevocationChecker memasang denylist yang dicek AuthMiddleware setelah ValidateToken.
+func SetTokenRevocationChecker(checker TokenRevocationChecker) {
+	revocationChecker = checker
+}
+
+// TokenVersionChecker membaca users.token_version untuk dibandingkan dengan klaim "tv".
+type TokenVersionChecker interface {
+	FindTokenVersion(userID uuid.UUID) (int, error)
+}
+
+type tokenVersionEntry struct {
+	version  int
+	cachedAt time.Time
+}
+
+// tokenVersionCache menyimpan token_version per user selama ttl agar tidak 1 query per request.
+// token_version hanya naik, sehingga token dengan versi lebih baru dari cache berarti cache basi
+// (mis. token hasil ganti password): versi dibaca ulang dari DB. Perubahan di instance ini
+// menghapus entri lewat ForgetTokenVersion; di instance lain berlaku paling lambat ttl kemudian.
+type tokenVersionCache struct {
+	checker TokenVersionChecker
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[uuid.UUID]tokenVersionEntry
+}
+
+// versionCache dipasang sekali di main (SetTokenVersionChecker); nil = versi token tidak dicek.
+var versionCache *tokenVersionCache
+
+// SetTokenVersionChecker memasang pengecekan token_version di AuthMiddleware dengan cache ttl
+// (0 = tanpa cache).
+func SetTokenVersionChecker(checker TokenVersionChecker, ttl time.Duration) {
+	versionCache = &tokenVersionCache{checker: checker, ttl: ttl, entries: map[uuid.UUID]tokenVersionEntry{}}
+}
+
+// ForgetTokenVersion menghapus token_version user dari cache, dipanggil setelah versinya
+// dinaikkan (ganti role/password, nonaktifkan akun) agar token lama langsung ditolak.
+func ForgetTokenVersion(userID uuid.UUID) {
+	if versionCache == nil {
+		return
+	}
+	versionCache.mu.Lock()
+	delete(versionCache.entries, userID)
+	versionCache.mu.Unlock()
+}
+
+// version mengembalikan token_version user; claimed adalah versi di token yang sedang dicek.
+func (c *tokenVersionCache) version(userID uuid.UUID, claimed int) (int, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Sub(entry.cachedAt) < c.ttl && claimed <= entry.version {
+		return entry.version, nil
+	}
+
+	v, err := c.checker.FindTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	if len(c.entries) > 10000 { // batas kasar memori; cache dibangun ulang
+		c.entries = map[uuid.UUID]tokenVersionEntry{}
+	}
+	c.entries[userID] = tokenVersionEntry{version: v, cachedAt: now}
+	c.mu.Unlock()
+	return v, nil
+}
+
 // AuthMiddleware memvalidasi JWT dari header Authorization (Bearer token)
-// dan menyimpan informasi user (userID, studentID, role, permissions) ke dalam context.
+// dan menyimpan informasi user (userID, studentID, lecturerID, role, permissions) ke dalam context.
+// Request yang sudah diautentikasi APIKeyAuth hanya diteruskan (tanpa JWT) ke route yang
+// memasang RequirePermission; route lain menolak API key (default deny).
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Sudah diautentikasi APIKeyAuth (X-API-Key): principal tanpa role, tidak perlu JWT.
+		if IsAPIKeyPrincipal(c) {
+			if !routeAllowsAPIKey(c) {
+				c.JSON(http.StatusForbidden,
+					utils.BuildResponseFailed("Endpoint ini tidak dapat diakses dengan API key", "api_key_not_allowed", nil))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
 		// Ambil header Authorization
 		auth := c.GetHeader("Authorization")
 		if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
@@ -33,6 +152,13 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// Validasi token menggunakan utils (JWT parsing & verifikasi signature/expired)
 		claims, err := utils.ValidateToken(tokenString)
+		if errors.Is(err, utils.ErrWrongTokenType) {
+			// refresh token / token 2FA hanya untuk endpoint auth masing-masing
+			c.JSON(http.StatusUnauthorized,
+				utils.BuildResponseFailed("Token ini bukan access token", "invalid_token_type", nil))
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized,
 				utils.BuildResponseFailed("Invalid or expired token", err.Error(), nil))
@@ -40,11 +166,76 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Token yang sudah dicabut (logout) atau sesinya dicabut ditolak walau belum kedaluwarsa.
+		// Gagal cek denylist → tolak (fail closed), bukan meloloskan token yang mungkin dicabut.
+		var revocationKeys []string
+		if claims.ID != "" {
+			revocationKeys = append(revocationKeys, claims.ID)
+		}
+		if claims.SessionID != uuid.Nil {
+			revocationKeys = append(revocationKeys, utils.SessionRevocationKey(claims.SessionID))
+		}
+		if revocationChecker != nil && len(revocationKeys) > 0 {
+			revoked, err := revocationChecker.AnyRevoked(revocationKeys...)
+			if err != nil {
+				c.JSON(http.StatusServiceUnavailable,
+					utils.BuildResponseFailed("Gagal memverifikasi status token", "token_check_failed", nil))
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized,
+					utils.BuildResponseFailed("Token sudah tidak berlaku, silakan login ulang", "token_revoked", nil))
+				c.Abort()
+				return
+			}
+		}
+
+		// Role / status / password user berubah sejak token dibuat → wajib login ulang.
+		if versionCache != nil {
+			version, err := versionCache.version(claims.UserID, claims.TokenVersion)
+			if err != nil {
+				// sama seperti denylist: gangguan DB bukan alasan memaksa login ulang
+				c.JSON(http.StatusServiceUnavailable,
+					utils.BuildResponseFailed("Gagal memverifikasi status token", "token_check_failed", nil))
+				c.Abort()
+				return
+			}
+			if version != claims.TokenVersion {
+				c.JSON(http.StatusUnauthorized,
+					utils.BuildResponseFailed("Data akun berubah, silakan login ulang", "token_version_mismatch", nil))
+				c.Abort()
+				return
+			}
+		}
+
 		// Inject nilai-nilai penting ke context untuk dipakai di handler/service
-		c.Set("userID", claims.UserID)       // UUID user (tabel users)
-		c.Set("studentID", claims.StudentID) // UUID student (tabel students) - bisa uuid.Nil jika bukan mahasiswa
+		c.Set("userID", claims.UserID)         // UUID user (tabel users)
+		c.Set("studentID", claims.StudentID)   // UUID student (tabel students) - bisa uuid.Nil jika bukan mahasiswa
+		c.Set("lecturerID", claims.LecturerID) // UUID lecturer (tabel lecturers) - bisa uuid.Nil jika bukan dosen
 		c.Set("role", claims.Role)
 		c.Set("permissions", claims.Permissions)
+		c.Set("tokenID", claims.ID)          // jti access token (untuk logout)
+		c.Set("sessionID", claims.SessionID) // family refresh token (uuid.Nil untuk token lama)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
+		c.Set(utils.ImpersonatedByKey, claims.ImpersonatedBy) // admin yang meng-impersonate (uuid.Nil = token biasa)
+
+		if claims.ImpersonatedBy != uuid.Nil && !impersonationAllowed(c.Request.Method, c.FullPath()) {
+			c.JSON(http.StatusForbidden,
+				utils.BuildResponseFailed("Token impersonasi hanya untuk melihat data", "impersonation_read_only", nil))
+			c.Abort()
+			return
+		}
+
+		// Akun yang wajib ganti password hanya boleh mengakses alur ganti password.
+		if claims.MustChangePassword && !passwordChangeAllowedPaths[c.FullPath()] {
+			c.JSON(http.StatusForbidden,
+				utils.BuildResponseFailed("Anda wajib mengganti password terlebih dahulu", "password_change_required", nil))
+			c.Abort()
+			return
+		}
 
 		// lanjut ke handler berikutnya
 		c.Next()
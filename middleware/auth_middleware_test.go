@@ -0,0 +1,273 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// fakeVersions adalah TokenVersionChecker di memori yang menghitung jumlah query.
+type fakeVersions struct {
+	mu       sync.Mutex
+	versions map[uuid.UUID]int
+	err      error
+	queries  int
+}
+
+func (f *fakeVersions) FindTokenVersion(userID uuid.UUID) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries++
+	return f.versions[userID], f.err
+}
+
+func (f *fakeVersions) set(userID uuid.UUID, v int) {
+	f.mu.Lock()
+	f.versions[userID] = v
+	f.mu.Unlock()
+}
+
+// authRequest menjalankan AuthMiddleware dengan access token versi tv untuk userID.
+func authRequest(t *testing.T, userID uuid.UUID, tv int) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := utils.GenerateToken(userID, uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/api/v1/ping", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func setupVersionCheck(t *testing.T, checker *fakeVersions) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	revocationChecker = nil
+	SetTokenVersionChecker(checker, time.Minute)
+	t.Cleanup(func() { versionCache = nil })
+}
+
+func TestTokenVersionNewerTokenBypassesStaleCache(t *testing.T) {
+	userID := uuid.New()
+	checker := &fakeVersions{versions: map[uuid.UUID]int{userID: 0}}
+	setupVersionCheck(t, checker)
+
+	if w := authRequest(t, userID, 0); w.Code != http.StatusOK {
+		t.Fatalf("token versi 0: status %d, want 200", w.Code)
+	}
+	// ganti password di instance lain / tanpa eviction: token baru membawa versi 1
+	checker.set(userID, 1)
+	if w := authRequest(t, userID, 1); w.Code != http.StatusOK {
+		t.Fatalf("token versi baru ditolak cache lama: status %d, body %s", w.Code, w.Body)
+	}
+	if w := authRequest(t, userID, 0); w.Code != http.StatusUnauthorized {
+		t.Fatalf("token versi lama: status %d, want 401", w.Code)
+	}
+}
+
+func TestForgetTokenVersionRejectsOldTokenImmediately(t *testing.T) {
+	userID := uuid.New()
+	checker := &fakeVersions{versions: map[uuid.UUID]int{userID: 0}}
+	setupVersionCheck(t, checker)
+
+	if w := authRequest(t, userID, 0); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	checker.set(userID, 1) // UpdateUserRole
+	if w := authRequest(t, userID, 0); w.Code != http.StatusOK {
+		t.Fatalf("tanpa eviction token lama masih dari cache: status %d, want 200", w.Code)
+	}
+	ForgetTokenVersion(userID)
+	if w := authRequest(t, userID, 0); w.Code != http.StatusUnauthorized {
+		t.Fatalf("setelah ForgetTokenVersion: status %d, want 401", w.Code)
+	}
+}
+
+func TestTokenVersionLookupFailureIsUnavailable(t *testing.T) {
+	checker := &fakeVersions{versions: map[uuid.UUID]int{}, err: errors.New("db down")}
+	setupVersionCheck(t, checker)
+
+	if w := authRequest(t, uuid.New(), 0); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", w.Code)
+	}
+}
+
+func TestImpersonationTokenIsViewOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	revocationChecker, versionCache = nil, nil
+
+	adminID := uuid.New()
+	token, _, _, err := utils.GenerateImpersonationToken(uuid.New(), uuid.New(), uuid.Nil, "mahasiswa", nil, 0, adminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, route string
+		want          int
+	}{
+		{http.MethodGet, "/api/v1/achievements", http.StatusOK},
+		{http.MethodGet, "/api/v1/auth/profile", http.StatusOK},
+		{http.MethodPost, "/api/v1/auth/logout", http.StatusOK},
+		{http.MethodPost, "/api/v1/achievements", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/achievements/:id/submit", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/achievements/:id", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/achievements/:id", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/auth/change-password", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			var gotAdmin uuid.UUID
+			r := gin.New()
+			r.Handle(tt.method, tt.route, AuthMiddleware(), func(c *gin.Context) {
+				gotAdmin, _ = utils.ImpersonatorID(c)
+				c.Status(http.StatusOK)
+			})
+			path := strings.ReplaceAll(tt.route, ":id", uuid.NewString())
+			req := httptest.NewRequest(tt.method, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusOK && gotAdmin != adminID {
+				t.Fatalf("ImpersonatorID = %s, want %s", gotAdmin, adminID)
+			}
+		})
+	}
+}
+
+// fakeDenylist adalah TokenRevocationChecker di memori.
+type fakeDenylist map[string]bool
+
+func (d fakeDenylist) AnyRevoked(keys ...string) (bool, error) {
+	for _, k := range keys {
+		if d[k] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRevokedSessionRejectsAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	versionCache = nil
+	t.Cleanup(func() { revocationChecker = nil })
+
+	sessionID := uuid.New()
+	token, err := utils.GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "mahasiswa", nil, false, sessionID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		denylist fakeDenylist
+		want     int
+	}{
+		{name: "tidak dicabut", denylist: fakeDenylist{}, want: http.StatusOK},
+		{name: "jti dicabut (logout)", denylist: fakeDenylist{claims.ID: true}, want: http.StatusUnauthorized},
+		{name: "sesi dicabut", denylist: fakeDenylist{utils.SessionRevocationKey(sessionID): true}, want: http.StatusUnauthorized},
+		{name: "sesi lain dicabut", denylist: fakeDenylist{utils.SessionRevocationKey(uuid.New()): true}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTokenRevocationChecker(tt.denylist)
+			r := gin.New()
+			r.GET("/api/v1/ping", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d (body %s)", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+// TestAuthMiddlewareRejectsNonAccessTokens: refresh token & token 2FA ditolak di route API
+// dengan invalid_token_type; token lama tanpa klaim tokenType tetap dianggap access token.
+func TestAuthMiddlewareRejectsNonAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	revocationChecker, versionCache = nil, nil
+	userID := uuid.New()
+
+	access, err := utils.GenerateToken(userID, uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, _, err := utils.GenerateRefreshToken(userID, uuid.Nil, uuid.Nil, "mahasiswa", nil, false, false, uuid.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfa, _, err := utils.GenerateMFAToken(userID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.JWTCustomClaims{
+		UserID: userID,
+		Role:   "mahasiswa",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    utils.JWTIssuer(),
+			Audience:  jwt.ClaimStrings{utils.JWTAudience()},
+			ID:        uuid.NewString(),
+		},
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "access token", token: access, wantCode: http.StatusOK},
+		{name: "token lama tanpa tokenType", token: legacy, wantCode: http.StatusOK},
+		{name: "refresh token", token: refresh, wantCode: http.StatusUnauthorized, wantErr: "invalid_token_type"},
+		{name: "token 2FA", token: mfa, wantCode: http.StatusUnauthorized, wantErr: "invalid_token_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/api/v1/achievements", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/achievements", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantErr != "" && !strings.Contains(w.Body.String(), `"errors":"`+tt.wantErr+`"`) {
+				t.Fatalf("body %s, want errors %q", w.Body, tt.wantErr)
+			}
+		})
+	}
+}
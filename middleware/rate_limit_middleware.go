@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitBodyPeekLimit membatasi body yang dibaca RateLimitByBodyField (login hanya berisi 2 field).
+const rateLimitBodyPeekLimit = 64 * 1024
+
+// RateLimitStore menyimpan kuota per key. Implementasi bawaan in-memory (per instance);
+// untuk beberapa instance aplikasi bisa diganti store bersama (mis. Redis) lewat SetRateLimitStore.
+type RateLimitStore interface {
+	// Take memakai 1 kuota key. allowed=false berarti kuota habis; retryAfter = waktu
+	// sampai 1 kuota tersedia lagi.
+	Take(key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration)
+}
+
+// RateLimitKeyFunc mengembalikan identitas pemanggil untuk rate limit; "" = tidak dibatasi.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// rateLimitStore dipakai semua RateLimit; diganti sekali di main sebelum route didaftarkan.
+var rateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+// SetRateLimitStore mengganti store rate limit (mis. ke implementasi Redis).
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+// RateLimit membatasi request per IP klien: limit request per window (token bucket,
+// burst maksimal limit). Melebihi kuota → 429 dengan header Retry-After.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	return RateLimitBy("ip", limit, window, func(c *gin.Context) string {
+		return c.ClientIP()
+	})
+}
+
+// RateLimitByUsername membatasi request per field "username" di body JSON,
+// sehingga percobaan login ke 1 akun dari banyak IP tetap tertahan.
+func RateLimitByUsername(limit int, window time.Duration) gin.HandlerFunc {
+	return RateLimitByBodyField("username", limit, window)
+}
+
+// RateLimitByBodyField membatasi request per nilai field string di body JSON
+// (case-insensitive). Body dikembalikan utuh untuk handler.
+func RateLimitByBodyField(field string, limit int, window time.Duration) gin.HandlerFunc {
+	return RateLimitBy(field, limit, window, func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, rateLimitBodyPeekLimit))
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		if err != nil {
+			return ""
+		}
+		var input map[string]any
+		if json.Unmarshal(body, &input) != nil {
+			return ""
+		}
+		v, _ := input[field].(string)
+		return strings.ToLower(strings.TrimSpace(v))
+	})
+}
+
+// RateLimitBy adalah bentuk umum RateLimit dengan identitas dari keyFn. Kuota dipisah per
+// route dan per scope, sehingga limit login tidak memakan kuota endpoint lain.
+func RateLimitBy(scope string, limit int, window time.Duration, keyFn RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 || window <= 0 {
+			c.Next()
+			return
+		}
+		id := keyFn(c)
+		if id == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter := rateLimitStore.Take(c.FullPath()+"|"+scope+"|"+id, limit, window)
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(http.StatusTooManyRequests,
+				utils.BuildResponseFailed("Terlalu banyak percobaan, coba lagi nanti", "rate_limited",
+					map[string]any{"retryAfterSeconds": seconds}))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// tokenBucket: tokens terisi ulang limit/window per detik, maksimal limit.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+// MemoryRateLimitStore adalah RateLimitStore in-memory berbasis token bucket.
+// Bucket yang sudah penuh kembali (idle >= window) dibuang secara berkala.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore membuat store in-memory kosong.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}, now: time.Now}
+}
+
+// Take lihat dokumentasi di RateLimitStore.
+func (s *MemoryRateLimitStore) Take(key string, limit int, window time.Duration) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	rate := float64(limit) / window.Seconds() // token per detik
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now, window: window}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+		b.window = window
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// sweep membuang bucket idle paling sering 1x per menit (dipanggil dengan mu terkunci).
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutWriterKey adalah key gin.Context untuk menyimpan timeoutWriter aktif,
+// supaya Timeout yang dipasang di level route bisa menimpa budget level group.
+const timeoutWriterKey = "timeoutWriter"
+
+// DefaultRequestTimeout membaca REQUEST_TIMEOUT (default 10s) untuk endpoint biasa.
+func DefaultRequestTimeout() time.Duration {
+	return utils.Runtime().RequestTimeout
+}
+
+// LongRequestTimeout membaca REQUEST_TIMEOUT_LONG (default 60s) untuk upload/export.
+func LongRequestTimeout() time.Duration {
+	return utils.Runtime().RequestTimeoutLong
+}
+
+// timeoutWriter membungkus gin.ResponseWriter supaya response dari handler
+// yang baru selesai setelah deadline lewat tidak ikut tertulis (digantikan 504).
+type timeoutWriter struct {
+	gin.ResponseWriter
+	base     context.Context // context asli request (tanpa deadline)
+	ctx      context.Context // context dengan deadline yang sedang berlaku
+	timedOut bool
+}
+
+// expired mengecek apakah budget waktu request sudah habis.
+func (w *timeoutWriter) expired() bool {
+	if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.expired() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if w.expired() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Timeout membungkus ctx.Request dengan context.WithTimeout sehingga query
+// yang memakai ctx.Request.Context() ikut dibatalkan saat budget habis.
+// Jika deadline terlewati sebelum response ditulis, client menerima 504
+// dengan format standar. Timeout yang dipasang di route menimpa budget group
+// (misal: group 10s, route upload 60s).
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return TimeoutFunc(func() time.Duration { return d })
+}
+
+// TimeoutFunc sama dengan Timeout, tetapi budget dibaca ulang di setiap request
+// (misal middleware.DefaultRequestTimeout), sehingga perubahan config lewat reload langsung berlaku.
+func TimeoutFunc(budget func() time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := budget()
+		// Sudah ada Timeout di level group → ganti budget, tetap pakai writer yang sama.
+		if v, ok := c.Get(timeoutWriterKey); ok {
+			w := v.(*timeoutWriter)
+			ctx, cancel := context.WithTimeout(w.base, d)
+			defer cancel()
+
+			w.ctx = ctx
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		base := c.Request.Context()
+		ctx, cancel := context.WithTimeout(base, d)
+		defer cancel()
+
+		w := &timeoutWriter{ResponseWriter: c.Writer, base: base, ctx: ctx}
+		c.Writer = w
+		c.Set(timeoutWriterKey, w)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if w.expired() && !w.ResponseWriter.Written() {
+			// Tulis langsung ke writer asli (writer pembungkus menolak semua write setelah timeout).
+			c.Writer = w.ResponseWriter
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout,
+				utils.BuildResponseFailed("Request melebihi batas waktu pemrosesan", "request_timeout", nil))
+		}
+	}
+}
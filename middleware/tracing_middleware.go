@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing memulai 1 span server per request. Nama span memakai route template
+// (mis. "POST /api/v1/achievements/:id/submit") agar kardinalitas tetap rendah.
+// Role user baru diketahui setelah AuthMiddleware (yang dipasang per group) jalan,
+// sehingga atribut role ditambahkan setelah handler selesai.
+//
+// Context request diganti dengan context ber-span, sehingga repository yang menerima
+// ctx (atau gin.Context, karena ContextWithFallback) otomatis membuat child span.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := utils.Tracer().Start(parent, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if role := c.GetString("role"); role != "" {
+			span.SetAttributes(attribute.String("user.role", role))
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
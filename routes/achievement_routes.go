@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/middleware"
 
@@ -13,6 +14,7 @@ func AchievementRoutes(r *gin.Engine, s service.AchievementService) {
 	// Semua endpoint di bawah ini butuh JWT
 	g := r.Group("/api/v1/achievements")
 	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
 
 	{
 		// -----------------------------------------------------------
@@ -27,22 +29,55 @@ func AchievementRoutes(r *gin.Engine, s service.AchievementService) {
 		// - Mahasiswa: hanya miliknya
 		// - Dosen wali: mahasiswa bimbingan
 		// - Admin: semua
+		// - API key (achievement:read): hanya prestasi verified
 		// -----------------------------------------------------------
-		g.GET("/:id", s.DetailAchievement)
+		g.GET("/:id", middleware.RequirePermission(model.PermissionAchievementRead), s.DetailAchievement)
 
 		// -----------------------------------------------------------
 		// UPDATE: SRS 5.4
 		// PUT /api/v1/achievements/:id
-		// - Mahasiswa pemilik, biasanya hanya saat status 'draft'
+		// - Mahasiswa pemilik, hanya saat status 'draft' atau 'rejected'
 		// -----------------------------------------------------------
 		g.PUT("/:id", s.UpdateAchievement)
 
+		// -----------------------------------------------------------
+		// AUTO-SAVE: PATCH /api/v1/achievements/:id
+		// - Aturan sama dengan PUT; hanya field yang dikirim yang diubah
+		// -----------------------------------------------------------
+		g.PATCH("/:id", s.PatchAchievement)
+
+		// -----------------------------------------------------------
+		// REVISIONS: GET /api/v1/achievements/:id/revisions?diff=true
+		// - Isi prestasi sebelum tiap PUT/PATCH (akses sama dengan detail)
+		// -----------------------------------------------------------
+		g.GET("/:id/revisions", middleware.RequirePermission(model.PermissionAchievementRead), s.GetAchievementRevisions)
+
+		// -----------------------------------------------------------
+		// CERTIFICATE: GET /api/v1/achievements/:id/certificate
+		// - PDF bukti verifikasi (akses sama dengan detail), hanya status verified
+		// -----------------------------------------------------------
+		g.GET("/:id/certificate", middleware.RequirePermission(model.PermissionAchievementRead), s.GetAchievementCertificate)
+
 		// -----------------------------------------------------------
 		// FR-004: Mahasiswa submit prestasi draft
 		// POST /api/v1/achievements/:id/submit
 		// -----------------------------------------------------------
 		g.POST("/:id/submit", s.SubmitForVerification)
 
+		// -----------------------------------------------------------
+		// Mahasiswa mengajukan ulang prestasi yang ditolak
+		// POST /api/v1/achievements/:id/resubmit
+		// - Hanya status 'rejected'; keputusan lama tetap tampil di history
+		// -----------------------------------------------------------
+		g.POST("/:id/resubmit", s.ResubmitAchievement)
+
+		// -----------------------------------------------------------
+		// Mahasiswa menarik prestasi yang sudah disubmit (kembali ke draft)
+		// POST /api/v1/achievements/:id/withdraw
+		// - Hanya jika belum diverifikasi/ditolak (409 jika sudah)
+		// -----------------------------------------------------------
+		g.POST("/:id/withdraw", s.WithdrawAchievement)
+
 		// -----------------------------------------------------------
 		// FR-005: Mahasiswa menghapus draft prestasi
 		// DELETE /api/v1/achievements/:id
@@ -57,8 +92,43 @@ func AchievementRoutes(r *gin.Engine, s service.AchievementService) {
 		// - Mahasiswa → list prestasi miliknya
 		// - Dosen wali → list prestasi semua mahasiswa bimbingan
 		// - Admin      → list semua prestasi (with status filter + pagination)
+		// - API key    → list prestasi verified (achievement:read)
+		// - Filter isi: ?type=competition&tags=robotics&from=2024-01-01&to=2024-06-30
+		// - Pencarian teks judul/deskripsi: ?q=robotika
+		// - Admin: ?search= NIM / nama mahasiswa / judul prestasi
+		// - Admin: ?cursor=&limit=50 keyset pagination (meta.nextCursor), alternatif page/limit
+		// - Urutan: ?sortBy=createdAt|submittedAt|status|points&sortDir=asc|desc
+		// -----------------------------------------------------------
+		g.GET("/", middleware.RequirePermission(model.PermissionAchievementRead), s.GetAchievements)
+
 		// -----------------------------------------------------------
-		g.GET("/", s.GetAchievements)
+		// Antrean verifikasi dosen wali
+		// GET /api/v1/achievements/pending?page=1&limit=10
+		// - Hanya submitted milik bimbingan, terlama dulu, + daysPending
+		// - meta.totalPending untuk badge menu
+		// -----------------------------------------------------------
+		g.GET("/pending", s.GetPendingAchievements)
+
+		// -----------------------------------------------------------
+		// Riwayat keputusan dosen wali
+		// GET /api/v1/achievements/my-decisions?decision=verified|rejected&from=&to=&page=1&limit=10
+		// - Hanya keputusan oleh user yang login, terbaru dulu
+		// -----------------------------------------------------------
+		g.GET("/my-decisions", s.GetMyDecisions)
+
+		// -----------------------------------------------------------
+		// Autocomplete tag
+		// GET /api/v1/achievements/tags?q=rob&limit=10
+		// - Tag unik berawalan q + jumlah pemakaian, scope sesuai role
+		// -----------------------------------------------------------
+		g.GET("/tags", middleware.RequirePermission(model.PermissionAchievementRead), s.GetTagSuggestions)
+
+		// -----------------------------------------------------------
+		// Dashboard per tipe
+		// GET /api/v1/achievements/grouped?studentId= (studentId khusus admin)
+		// - { achievementType: {count, totalPoints, items: 5 terbaru} }
+		// -----------------------------------------------------------
+		g.GET("/grouped", middleware.RequirePermission(model.PermissionAchievementRead), s.GetGroupedAchievements)
 
 		// -----------------------------------------------------------
 		// FR-007: Dosen wali memverifikasi prestasi mahasiswa
@@ -69,9 +139,17 @@ func AchievementRoutes(r *gin.Engine, s service.AchievementService) {
 		// -----------------------------------------------------------
 		// FR-008: Dosen wali menolak prestasi mahasiswa
 		// POST /api/v1/achievements/:id/reject
+		// - rejectionCategory wajib; kategori 'other' butuh catatan minimal 20 karakter
 		// -----------------------------------------------------------
 		g.POST("/:id/reject", s.RejectAchievement)
 
+		// -----------------------------------------------------------
+		// Dosen wali menolak banyak prestasi dengan 1 catatan
+		// POST /api/v1/achievements/bulk-reject
+		// - Hasil per item; item gagal tidak membatalkan item lain
+		// -----------------------------------------------------------
+		g.POST("/bulk-reject", s.BulkRejectAchievements)
+
 		// -----------------------------------------------------------
 		// HISTORY: SRS 5.4
 		// GET /api/v1/achievements/:id/history
@@ -79,11 +157,76 @@ func AchievementRoutes(r *gin.Engine, s service.AchievementService) {
 		// -----------------------------------------------------------
 		g.GET("/:id/history", s.GetAchievementHistory)
 
+		// -----------------------------------------------------------
+		// Diskusi prestasi (mahasiswa pemilik, dosen wali, admin)
+		// GET    /api/v1/achievements/:id/comments
+		// POST   /api/v1/achievements/:id/comments
+		// DELETE /api/v1/achievements/:id/comments/:commentId (penulis, <= 15 menit)
+		// -----------------------------------------------------------
+		g.GET("/:id/comments", s.GetComments)
+		g.POST("/:id/comments", s.AddComment)
+		g.DELETE("/:id/comments/:commentId", s.DeleteComment)
+
 		// -----------------------------------------------------------
 		// Upload attachments bukti prestasi (Mahasiswa)
 		// POST /api/v1/achievements/:id/attachments
 		// Body: multipart/form-data (file di field "file")
+		// Upload memakai budget waktu panjang (REQUEST_TIMEOUT_LONG).
+		// -----------------------------------------------------------
+		g.POST("/:id/attachments", middleware.TimeoutFunc(middleware.LongRequestTimeout), s.UploadAttachment)
+
+		// -----------------------------------------------------------
+		// Download lampiran (pemilik, dosen wali, admin)
+		// GET /api/v1/achievements/:id/attachments/:fileName
+		// 410 jika prestasi/lampiran sudah dihapus
+		// -----------------------------------------------------------
+		g.GET("/:id/attachments/:fileName", middleware.TimeoutFunc(middleware.LongRequestTimeout), s.DownloadAttachment)
+
+		// -----------------------------------------------------------
+		// Hapus lampiran (mahasiswa pemilik, status draft/rejected)
+		// DELETE /api/v1/achievements/:id/attachments/:attachmentId
+		// 409 jika status tidak mengizinkan, 404 jika lampiran tidak ada
+		// -----------------------------------------------------------
+		g.DELETE("/:id/attachments/:attachmentId", s.DeleteAttachment)
+	}
+
+	// Endpoint khusus admin untuk pengelolaan prestasi
+	admin := r.Group("/api/v1/admin/achievements")
+	admin.Use(middleware.AuthMiddleware())
+	admin.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+
+	{
+		// -----------------------------------------------------------
+		// Override poin manual (dibatasi MAX_POINTS_OVERRIDE, alasan wajib)
+		// PUT    /api/v1/admin/achievements/:id/points-override
+		// DELETE /api/v1/admin/achievements/:id/points-override
+		// -----------------------------------------------------------
+		admin.PUT("/:id/points-override", s.OverridePoints)
+		admin.DELETE("/:id/points-override", s.RevertPointsOverride)
+
+		// -----------------------------------------------------------
+		// Pulihkan / hapus permanen prestasi
+		// POST   /api/v1/admin/achievements/:id/restore
+		// DELETE /api/v1/admin/achievements/:id   (hanya yang sudah di-soft-delete; file lampiran ikut dihapus)
+		// DELETE /api/v1/admin/achievements/:id?hard=true[&force=true]   (status apa pun; verified wajib force)
+		// -----------------------------------------------------------
+		admin.POST("/:id/restore", s.RestoreAchievement)
+		admin.DELETE("/:id", s.PurgeAchievement)
+
+		// -----------------------------------------------------------
+		// Impor keputusan verified/rejected dari sistem lama (CSV)
+		// POST /api/v1/admin/achievements/import-decisions?overwrite=true
+		// Upload memakai budget waktu panjang (REQUEST_TIMEOUT_LONG).
+		// -----------------------------------------------------------
+		admin.POST("/import-decisions", middleware.TimeoutFunc(middleware.LongRequestTimeout), s.ImportDecisions)
+
+		// -----------------------------------------------------------
+		// Konsistensi data Postgres / Mongo (pemindaian penuh per batch)
+		// GET  /api/v1/admin/achievements/consistency?kind=&page=1&limit=10
+		// POST /api/v1/admin/achievements/consistency/repair?dryRun=false
+		// Memakai budget waktu panjang (REQUEST_TIMEOUT_LONG).
 		// -----------------------------------------------------------
-		g.POST("/:id/attachments", s.UploadAttachment)
+		admin.GET("/consistency", middleware.TimeoutFunc(middleware.LongRequestTimeout), s.CheckConsistency)
+		admin.POST("/consistency/repair", middleware.TimeoutFunc(middleware.LongRequestTimeout), s.RepairConsistency)
 	}
 }
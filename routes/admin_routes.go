@@ -11,13 +11,52 @@ func AdminRoutes(r *gin.Engine, s service.AdminService) {
 
 	admin := r.Group("/api/v1/admin")
 	admin.Use(middleware.AuthMiddleware()) // wajib JWT
+	admin.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
 	{
 		admin.GET("/users", s.GetAllUsers)
+		admin.GET("/users/possible-duplicates", s.GetPossibleDuplicates)
+		admin.POST("/users/merge", s.MergeUsers)
 		admin.GET("/users/:id", s.GetUserDetail)
 		admin.POST("/users", s.CreateUser)
 		admin.PUT("/users/:id", s.UpdateUser)
 		admin.DELETE("/users/:id", s.DeleteUser)
 		admin.PUT("/users/:id/role", s.UpdateUserRole)
+		admin.POST("/users/:id/link-lecturer", s.LinkLecturerProfile)
+		admin.GET("/users/:id/login-history", s.GetUserLoginHistory)
+		admin.GET("/users/:id/sessions", s.GetUserSessions)
+		admin.DELETE("/users/:id/sessions/:sessionId", s.RevokeUserSession)
+		admin.POST("/users/:id/impersonate", s.ImpersonateUser)
+		admin.POST("/users/:id/2fa/reset", s.ResetUserTwoFactor)
+
+		// Export / import matriks role-permission (audit akreditasi)
+		admin.GET("/rbac/export", s.ExportRBAC)
+		admin.POST("/rbac/import", s.ImportRBAC)
+
+		// Kalender hari libur (hitungan hari kerja untuk laporan turnaround)
+		admin.GET("/holidays", s.GetHolidays)
+		admin.POST("/holidays", s.CreateHoliday)
+		admin.POST("/holidays/import", s.ImportHolidays)
+		admin.PUT("/holidays/:id", s.UpdateHoliday)
+		admin.DELETE("/holidays/:id", s.DeleteHoliday)
+
+		// Rubrik poin prestasi (dipakai menghitung points saat create/update prestasi)
+		admin.GET("/point-rules", s.GetPointRules)
+		admin.POST("/point-rules", s.CreatePointRule)
+		admin.PUT("/point-rules/:id", s.UpdatePointRule)
+		admin.DELETE("/point-rules/:id", s.DeletePointRule)
+
+		// Katalog tipe prestasi (DELETE hanya menonaktifkan)
+		admin.GET("/achievement-types", s.GetAchievementTypes)
+		admin.POST("/achievement-types", s.CreateAchievementType)
+		admin.PUT("/achievement-types/:code", s.UpdateAchievementType)
+		admin.DELETE("/achievement-types/:code", s.DeleteAchievementType)
+
+		// Maintenance: mahasiswa dengan draft mendekati batas MAX_DRAFTS_PER_STUDENT
+		admin.GET("/maintenance/drafts", s.GetDraftUsage)
+
+		// Maintenance: versi schema dokumen prestasi di Mongo + upgrade bulk
+		admin.GET("/maintenance/schema", s.GetSchemaStatus)
+		admin.POST("/maintenance/schema/upgrade", s.UpgradeDocumentSchema)
 
 	}
 }
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"student-achievement-backend/app/model"
+	"student-achievement-backend/app/repository"
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeAPIKeyRepo menyimpan API key di memori (lookup berdasarkan prefix).
+type fakeAPIKeyRepo struct {
+	repository.APIKeyRepository
+	mu   sync.Mutex
+	keys map[string]*model.APIKey
+}
+
+func (r *fakeAPIKeyRepo) add(t *testing.T, expiresAt *time.Time, perms ...string) (string, *model.APIKey) {
+	t.Helper()
+	raw, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &model.APIKey{ID: uuid.New(), Name: "Portal Fakultas", Prefix: prefix, KeyHash: hash, Permissions: perms, ExpiresAt: expiresAt}
+	r.mu.Lock()
+	r.keys[prefix] = key
+	r.mu.Unlock()
+	return raw, key
+}
+
+func (r *fakeAPIKeyRepo) FindByPrefix(prefix string) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if k, ok := r.keys[prefix]; ok {
+		cp := *k
+		return &cp, nil
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (r *fakeAPIKeyRepo) Revoke(id uuid.UUID, at time.Time) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.ID == id && k.RevokedAt == nil {
+			k.RevokedAt = &at
+			return k, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(uuid.UUID, time.Time, string, time.Duration) error { return nil }
+
+type nopAuditRepo struct {
+	repository.AuditRepository
+}
+
+func (nopAuditRepo) Record(context.Context, *uuid.UUID, string, string, string, any) error {
+	return nil
+}
+
+// stubAchievements: handler prestasi yang hanya mencatat bahwa request sampai ke handler.
+type stubAchievements struct {
+	service.AchievementService
+	mu      sync.Mutex
+	reached []string
+}
+
+func (s *stubAchievements) reach(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.mu.Lock()
+		s.reached = append(s.reached, name)
+		s.mu.Unlock()
+		c.JSON(http.StatusOK, utils.BuildResponseSuccess(name, nil))
+	}
+}
+
+func (s *stubAchievements) GetAchievements(c *gin.Context)        { s.reach("list")(c) }
+func (s *stubAchievements) DetailAchievement(c *gin.Context)      { s.reach("detail")(c) }
+func (s *stubAchievements) VerifyAchievement(c *gin.Context)      { s.reach("verify")(c) }
+func (s *stubAchievements) GetPendingAchievements(c *gin.Context) { s.reach("pending")(c) }
+
+// TestAPIKeyAccessIsOptIn: API key hanya diterima di route yang memasang RequirePermission;
+// route lain (verify, admin) menolak dengan 403, key kedaluwarsa/dicabut ditolak dengan 401.
+func TestAPIKeyAccessIsOptIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+	keys := &fakeAPIKeyRepo{keys: map[string]*model.APIKey{}}
+	manager := service.NewAPIKeyManager(keys, nopAuditRepo{})
+	achievements := &stubAchievements{}
+
+	r := gin.New()
+	r.Use(middleware.APIKeyAuth(manager.Resolve))
+	AchievementRoutes(r, achievements)
+	APIKeyRoutes(r, manager)
+
+	do := func(method, path, apiKey, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	expect := func(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		if w.Code != status || (code != "" && !strings.Contains(w.Body.String(), `"`+code+`"`)) {
+			t.Fatalf("status %d, body %s, want %d %s", w.Code, w.Body, status, code)
+		}
+	}
+	achievementID := "/api/v1/achievements/" + uuid.NewString()
+	reader, readerKey := keys.add(t, nil, model.PermissionAchievementRead)
+
+	t.Run("key membaca prestasi", func(t *testing.T) {
+		expect(t, do(http.MethodGet, "/api/v1/achievements/", reader, ""), http.StatusOK, "")
+		expect(t, do(http.MethodGet, achievementID, reader, ""), http.StatusOK, "")
+	})
+
+	t.Run("key tanpa permission ditolak RequirePermission", func(t *testing.T) {
+		other, _ := keys.add(t, nil, model.PermissionReportRead)
+		expect(t, do(http.MethodGet, achievementID, other, ""), http.StatusForbidden, "missing_permission")
+	})
+
+	t.Run("route tanpa RequirePermission menolak key", func(t *testing.T) {
+		achievements.reached = nil
+		expect(t, do(http.MethodPost, achievementID+"/verify", reader, ""), http.StatusForbidden, "api_key_not_allowed")
+		expect(t, do(http.MethodGet, "/api/v1/achievements/pending", reader, ""), http.StatusForbidden, "api_key_not_allowed")
+		expect(t, do(http.MethodDelete, "/api/v1/admin/api-keys/"+readerKey.ID.String(), reader, ""), http.StatusForbidden, "api_key_not_allowed")
+		if len(achievements.reached) != 0 {
+			t.Fatalf("handler %v tetap dijalankan untuk API key", achievements.reached)
+		}
+	})
+
+	t.Run("key kedaluwarsa", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		expired, _ := keys.add(t, &past, model.PermissionAchievementRead)
+		expect(t, do(http.MethodGet, achievementID, expired, ""), http.StatusUnauthorized, "invalid_api_key")
+	})
+
+	t.Run("key dicabut admin", func(t *testing.T) {
+		adminToken, err := utils.GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "admin", nil, false, uuid.New(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, do(http.MethodDelete, "/api/v1/admin/api-keys/"+readerKey.ID.String(), "", adminToken), http.StatusOK, "")
+		expect(t, do(http.MethodGet, achievementID, reader, ""), http.StatusUnauthorized, "invalid_api_key")
+	})
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyRoutes mendaftarkan endpoint admin pengelolaan API key integrasi:
+// POST   /api/v1/admin/api-keys
+// GET    /api/v1/admin/api-keys
+// DELETE /api/v1/admin/api-keys/:id
+func APIKeyRoutes(r *gin.Engine, s service.APIKeyService) {
+	g := r.Group("/api/v1/admin/api-keys")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.POST("", s.CreateAPIKey)
+		g.GET("", s.ListAPIKeys)
+		g.DELETE("/:id", s.RevokeAPIKey)
+	}
+}
@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"time"
+
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/middleware"
+	"student-achievement-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,12 +13,38 @@ import (
 // AuthRoutes mendaftarkan seluruh endpoint /api/v1/auth sesuai SRS.
 func AuthRoutes(r *gin.Engine, s service.AuthService) {
 	g := r.Group("/api/v1/auth")
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+
+	// Rate limit login per IP & per username (LOGIN_RATE_LIMIT request per LOGIN_RATE_WINDOW).
+	// Endpoint lupa password (jika ditambahkan) memakai loginLimits yang sama.
+	limit, window := loginRateLimit()
+	loginLimits := []gin.HandlerFunc{
+		middleware.RateLimit(limit, window),
+		middleware.RateLimitByUsername(limit, window),
+	}
 
 	// Endpoint yang tidak membutuhkan JWT.
-	g.POST("/login", s.Login)
+	g.POST("/login", append(loginLimits, s.Login)...)
 	g.POST("/refresh", s.RefreshToken)
-	g.POST("/logout", s.Logout)
+	g.POST("/2fa/challenge", // tahap kedua login (mfaToken + kode), dibatasi seperti login
+		middleware.RateLimit(limit, window),
+		middleware.RateLimitByBodyField("mfaToken", limit, window),
+		s.TwoFactorChallenge)
 
 	// Endpoint yang membutuhkan JWT.
+	g.POST("/logout", middleware.AuthMiddleware(), s.Logout) // mencabut token yang sedang dipakai
 	g.GET("/profile", middleware.AuthMiddleware(), s.GetProfile)
+	g.PUT("/profile", middleware.AuthMiddleware(), s.UpdateProfile)
+	g.POST("/change-password", middleware.AuthMiddleware(), s.ChangePassword)
+	g.GET("/login-history", middleware.AuthMiddleware(), s.GetLoginHistory)
+	g.POST("/2fa/setup", middleware.AuthMiddleware(), s.SetupTwoFactor)
+	g.POST("/2fa/verify", middleware.AuthMiddleware(), s.VerifyTwoFactor)
+	g.GET("/sessions", middleware.AuthMiddleware(), s.GetSessions)
+	g.DELETE("/sessions", middleware.AuthMiddleware(), s.RevokeOtherSessions)
+	g.DELETE("/sessions/:id", middleware.AuthMiddleware(), s.RevokeSession)
+}
+
+// loginRateLimit membaca LOGIN_RATE_LIMIT (default 10, 0 = nonaktif) dan LOGIN_RATE_WINDOW (default 1m).
+func loginRateLimit() (int, time.Duration) {
+	return utils.GetEnvInt("LOGIN_RATE_LIMIT", 10), utils.GetEnvDuration("LOGIN_RATE_WINDOW", time.Minute)
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigRoutes mendaftarkan endpoint admin setting runtime:
+// GET  /api/v1/admin/config
+// POST /api/v1/admin/config/reload
+func ConfigRoutes(r *gin.Engine, s service.ConfigService) {
+	g := r.Group("/api/v1/admin/config")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("", s.GetRuntimeConfig)
+		g.POST("/reload", s.ReloadRuntimeConfig)
+	}
+}
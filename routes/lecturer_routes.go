@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/middleware"
 
@@ -10,11 +11,23 @@ import (
 // LecturerRoutes mendaftarkan endpoint SRS 5.5 Lecturers:
 // GET /api/v1/lecturers
 // GET /api/v1/lecturers/:id/advisees
+// GET /api/v1/lecturers/:id/advisees/export (CSV, data pribadi dimasking)
+// + delegasi verifikasi dosen wali (me = dosen login, :id = override admin)
 func LecturerRoutes(r *gin.Engine, s service.LecturerService) {
 	g := r.Group("/api/v1/lecturers")
 	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
 	{
-		g.GET("/", s.GetLecturers)
+		g.GET("/", middleware.RequirePermission(model.PermissionLecturerRead), s.GetLecturers)
 		g.GET("/:id/advisees", s.GetLecturerAdvisees)
+		g.GET("/:id/advisees/export", s.ExportLecturerAdvisees)
+
+		g.GET("/me/delegations", s.GetMyDelegations)
+		g.POST("/me/delegations", s.CreateMyDelegation)
+		g.DELETE("/me/delegations/:delegationId", s.RevokeMyDelegation)
+
+		g.GET("/:id/delegations", s.GetLecturerDelegations)
+		g.POST("/:id/delegations", s.CreateLecturerDelegation)
+		g.DELETE("/:id/delegations/:delegationId", s.RevokeLecturerDelegation)
 	}
 }
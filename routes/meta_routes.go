@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetaRoutes mendaftarkan endpoint data referensi:
+// GET /api/v1/meta/achievement-statuses
+// GET /api/v1/meta/rejection-categories
+// GET /api/v1/achievement-types (katalog tipe aktif untuk dropdown)
+func MetaRoutes(r *gin.Engine, s service.MetaService) {
+	g := r.Group("/api/v1/meta")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("/achievement-statuses", s.GetAchievementStatuses)
+		g.GET("/rejection-categories", s.GetRejectionCategories)
+	}
+
+	types := r.Group("/api/v1/achievement-types")
+	types.Use(middleware.AuthMiddleware())
+	types.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		types.GET("", s.GetAchievementTypes)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationRoutes mendaftarkan endpoint monitoring antrean notifikasi:
+// GET /api/v1/admin/notifications/stats
+func NotificationRoutes(r *gin.Engine, s service.NotificationService) {
+	g := r.Group("/api/v1/admin/notifications")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("/stats", s.GetQueueStats)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxRoutes mendaftarkan endpoint admin untuk event outbox:
+// GET  /api/v1/admin/outbox
+// POST /api/v1/admin/outbox/:id/retry
+func OutboxRoutes(r *gin.Engine, s service.OutboxService) {
+	g := r.Group("/api/v1/admin/outbox")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("", s.ListOutboxEvents)
+		g.POST("/:id/retry", s.RetryOutboxEvent)
+	}
+}
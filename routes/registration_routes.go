@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationRoutes mendaftarkan registrasi mandiri mahasiswa dan antrean persetujuannya:
+// POST /api/v1/auth/register                      (tanpa JWT, dibatasi seperti login)
+// GET  /api/v1/admin/registrations
+// POST /api/v1/admin/registrations/:id/approve
+// POST /api/v1/admin/registrations/:id/reject
+func RegistrationRoutes(r *gin.Engine, s service.RegistrationService) {
+	limit, window := loginRateLimit()
+	r.POST("/api/v1/auth/register",
+		middleware.TimeoutFunc(middleware.DefaultRequestTimeout),
+		middleware.RateLimit(limit, window),
+		s.Register)
+
+	g := r.Group("/api/v1/admin/registrations")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("", s.GetRegistrations)
+		g.POST("/:id/approve", s.ApproveRegistration)
+		g.POST("/:id/reject", s.RejectRegistration)
+	}
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/middleware"
 
@@ -12,20 +13,34 @@ func ReportRoutes(r *gin.Engine, s service.ReportService) {
 
 	g := r.Group("/api/v1/reports")
 	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
 
 	{
 		// FR-011 - Global statistics (scope tergantung role)
 		// Admin      → semua prestasi
 		// Dosen Wali → semua mahasiswa bimbingan
 		// Mahasiswa  → prestasi sendiri
+		// API key    → semua prestasi (report:read)
 		// GET /api/v1/reports/statistics
-		g.GET("/statistics", s.GetGlobalStatistics)
+		g.GET("/statistics", middleware.RequirePermission(model.PermissionReportRead), s.GetGlobalStatistics)
 
 		// FR-011 - Student statistics (1 mahasiswa)
 		// Admin      → boleh siapa saja
 		// Dosen Wali → hanya advisee
 		// Mahasiswa  → hanya dirinya sendiri
+		// API key    → siapa saja (report:read)
 		// GET /api/v1/reports/student/:id
-		g.GET("/student/:id", s.GetStudentStatistics)
+		g.GET("/student/:id", middleware.RequirePermission(model.PermissionReportRead), s.GetStudentStatistics)
+
+		// Target prestasi tahunan (admin)
+		// GET /api/v1/reports/target-progress?programStudy=&year=
+		g.GET("/target-progress", s.GetTargetProgress)
+		// GET/PUT /api/v1/reports/targets (default global & override per program studi)
+		g.GET("/targets", s.GetTargets)
+		g.PUT("/targets", s.UpdateTarget)
+
+		// Turnaround verifikasi (admin): hari kalender & hari kerja
+		// GET /api/v1/reports/turnaround?from=YYYY-MM-DD&to=YYYY-MM-DD
+		g.GET("/turnaround", s.GetTurnaround)
 	}
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"student-achievement-backend/app/service"
+	"student-achievement-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionRoutes mendaftarkan endpoint admin kebijakan retensi data:
+// GET /api/v1/admin/retention/policies
+// PUT /api/v1/admin/retention/policies/:class
+// GET /api/v1/admin/retention/preview
+func RetentionRoutes(r *gin.Engine, s service.RetentionService) {
+	g := r.Group("/api/v1/admin/retention")
+	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
+	{
+		g.GET("/policies", s.GetRetentionPolicies)
+		g.PUT("/policies/:class", s.UpdateRetentionPolicy)
+		g.GET("/preview", s.PreviewAnonymization)
+	}
+}
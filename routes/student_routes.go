@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"student-achievement-backend/app/model"
 	"student-achievement-backend/app/service"
 	"student-achievement-backend/middleware"
 
@@ -12,13 +13,22 @@ import (
 // GET /api/v1/students/:id
 // GET /api/v1/students/:id/achievements
 // PUT /api/v1/students/:id/advisor
+// PUT /api/v1/students/:id/graduation
+// POST /api/v1/students/graduation/bulk
+// GET /api/v1/students/me/portfolio
+// GET /api/v1/students/me/feed
 func StudentRoutes(r *gin.Engine, s service.StudentService) {
 	g := r.Group("/api/v1/students")
 	g.Use(middleware.AuthMiddleware())
+	g.Use(middleware.TimeoutFunc(middleware.DefaultRequestTimeout))
 	{
-		g.GET("/", s.GetStudents)
+		g.GET("/", middleware.RequirePermission(model.PermissionStudentRead), s.GetStudents)
+		g.GET("/me/portfolio", s.GetMyPortfolio)
+		g.GET("/me/feed", s.GetMyFeed)
+		g.POST("/graduation/bulk", s.BulkSetGraduation)
 		g.GET("/:id", s.GetStudentDetail)
 		g.GET("/:id/achievements", s.GetStudentAchievements)
 		g.PUT("/:id/advisor", s.UpdateAdvisor)
+		g.PUT("/:id/graduation", s.SetGraduation)
 	}
 }
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// AcademicYearStartMonth: bulan awal tahun akademik (default Agustus),
+// bisa diubah via ACADEMIC_YEAR_START_MONTH (1-12).
+func AcademicYearStartMonth() time.Month {
+	m := GetEnvInt("ACADEMIC_YEAR_START_MONTH", int(time.August))
+	if m < 1 || m > 12 {
+		m = int(time.August)
+	}
+	return time.Month(m)
+}
+
+// AcademicYear adalah rentang 1 tahun akademik [Start, End).
+type AcademicYear struct {
+	StartYear int       `json:"startYear"` // 2024 untuk tahun akademik 2024/2025
+	Label     string    `json:"label"`     // "2024/2025"
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// AcademicYearStarting membentuk tahun akademik yang dimulai pada startYear.
+func AcademicYearStarting(startYear int, loc *time.Location) AcademicYear {
+	start := time.Date(startYear, AcademicYearStartMonth(), 1, 0, 0, 0, 0, loc)
+	return AcademicYear{
+		StartYear: startYear,
+		Label:     fmt.Sprintf("%d/%d", startYear, startYear+1),
+		Start:     start,
+		End:       start.AddDate(1, 0, 0),
+	}
+}
+
+// AcademicYearOf mengembalikan tahun akademik yang memuat waktu t.
+// Contoh (awal Agustus): 31 Juli 2025 → 2024/2025, 1 Agustus 2025 → 2025/2026.
+func AcademicYearOf(t time.Time) AcademicYear {
+	y := t.Year()
+	if t.Month() < AcademicYearStartMonth() {
+		y--
+	}
+	return AcademicYearStarting(y, t.Location())
+}
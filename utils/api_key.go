@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// apiKeyScheme adalah awalan semua API key, memudahkan secret scanning.
+const apiKeyScheme = "sak"
+
+// ErrMalformedAPIKey dikembalikan jika format key bukan sak_<prefix>_<secret>.
+var ErrMalformedAPIKey = errors.New("malformed api key")
+
+// GenerateAPIKey membuat key baru berformat sak_<prefix>_<secret>.
+// Mengembalikan key mentah (ditampilkan sekali), prefix (disimpan untuk lookup) dan hash-nya.
+func GenerateAPIKey() (raw, prefix, hash string, err error) {
+	p := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err = rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(p)
+	raw = apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return raw, prefix, HashAPIKey(raw), nil
+}
+
+// ParseAPIKeyPrefix mengambil prefix dari key mentah.
+func ParseAPIKeyPrefix(raw string) (string, error) {
+	parts := strings.SplitN(raw, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", ErrMalformedAPIKey
+	}
+	return parts[1], nil
+}
+
+// HashAPIKey menghitung hash SHA-256 (hex) key mentah. Key berentropi tinggi,
+// sehingga hash cepat tanpa salt cukup (berbeda dengan password).
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// UnknownFieldsError dikembalikan BindStrictJSON jika payload berisi field yang tidak dikenal.
+type UnknownFieldsError struct {
+	Fields []string // path field, contoh: "achievmentType", "details.competitonName"
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// StrictJSONEnabled: STRICT_JSON=true/false; jika tidak diset, aktif di luar production (APP_ENV).
+// Dibaca dari setting runtime sehingga ikut berubah saat config di-reload.
+func StrictJSONEnabled() bool {
+	return Runtime().StrictJSON
+}
+
+// BindStrictJSON adalah pengganti ctx.ShouldBindJSON untuk endpoint tulis.
+// Saat strict mode aktif, field yang tidak dikenal (termasuk di objek nested) ditolak dengan
+// *UnknownFieldsError yang berisi semua field tersebut; saat nonaktif perilakunya sama
+// dengan ShouldBindJSON (field asing diabaikan). Validasi tag `binding` tetap berjalan.
+func BindStrictJSON(ctx *gin.Context, obj any) error {
+	if !StrictJSONEnabled() {
+		return ctx.ShouldBindJSON(obj)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := CheckUnknownJSONFields(body, obj); err != nil {
+		return err
+	}
+	return binding.JSON.BindBody(body, obj)
+}
+
+// MultipartJSONField adalah nama bagian form berisi JSON pada payload multipart/form-data
+// (mis. POST /achievements yang sekaligus membawa lampiran).
+const MultipartJSONField = "data"
+
+// BindStrictMultipartJSON adalah BindStrictJSON untuk payload multipart/form-data: JSON diambil
+// dari bagian MultipartJSONField dan diperiksa dengan aturan strict mode yang sama.
+func BindStrictMultipartJSON(ctx *gin.Context, obj any) error {
+	data, ok := ctx.GetPostForm(MultipartJSONField)
+	if !ok {
+		return fmt.Errorf("multipart payload requires a JSON %q part", MultipartJSONField)
+	}
+	if StrictJSONEnabled() {
+		if err := CheckUnknownJSONFields([]byte(data), obj); err != nil {
+			return err
+		}
+	}
+	return binding.JSON.BindBody([]byte(data), obj)
+}
+
+// CheckUnknownJSONFields memeriksa data JSON terhadap tipe obj dan mengembalikan
+// *UnknownFieldsError jika ada field yang tidak dipetakan. JSON tidak valid diabaikan
+// di sini (error-nya dilaporkan oleh proses binding). Dipakai juga untuk bagian JSON
+// pada payload multipart.
+func CheckUnknownJSONFields(data []byte, obj any) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	var unknown []string
+	collectUnknownFields(raw, reflect.TypeOf(obj), "", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return &UnknownFieldsError{Fields: unknown}
+}
+
+// collectUnknownFields menelusuri nilai JSON bersamaan dengan tipe Go tujuan.
+func collectUnknownFields(raw any, t reflect.Type, prefix string, out *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := raw.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return // map / interface{} menerima key apa pun
+		}
+		fields := jsonFieldsOf(t)
+		for key, val := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			ft, ok := fields[key]
+			if !ok {
+				// encoding/json mencocokkan nama field secara case-insensitive
+				ft, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				*out = append(*out, path)
+				continue
+			}
+			collectUnknownFields(val, ft, path, out)
+		}
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for i, item := range v {
+			collectUnknownFields(item, t.Elem(), prefix+"["+strconv.Itoa(i)+"]", out)
+		}
+	}
+}
+
+// jsonFieldsOf memetakan nama JSON (dan versi lowercase-nya) ke tipe field struct,
+// termasuk field dari struct yang di-embed.
+func jsonFieldsOf(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFieldsOf(ft) {
+					fields[k] = v
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
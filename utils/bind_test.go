@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setStrictJSON mengganti setting STRICT_JSON untuk 1 test.
+func setStrictJSON(t *testing.T, strict bool) {
+	t.Helper()
+	prev := Runtime()
+	cfg := *prev
+	cfg.StrictJSON = strict
+	runtimeConfig.Store(&cfg)
+	t.Cleanup(func() { runtimeConfig.Store(prev) })
+}
+
+type bindTestInput struct {
+	AchievementType string `json:"achievementType" binding:"required"`
+	Details         struct {
+		CompetitionName string `json:"competitionName"`
+	} `json:"details"`
+}
+
+// multipartRequest membuat request multipart/form-data dengan bagian JSON "data" (jika data != "").
+func multipartRequest(t *testing.T, data string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if data != "" {
+		if err := mw.WriteField(MultipartJSONField, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fw, err := mw.CreateFormFile("files", "sertifikat.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fw.Write([]byte("%PDF-1.4"))
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestBindStrictJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		strict      bool
+		multipart   bool
+		body        string
+		wantUnknown []string // nil = tidak ada UnknownFieldsError
+		wantErr     bool
+	}{
+		{name: "json valid", strict: true, body: `{"achievementType":"competition","details":{"competitionName":"KRI"}}`},
+		{name: "json field asing top-level", strict: true, body: `{"achievmentType":"competition","achievementType":"competition"}`,
+			wantUnknown: []string{"achievmentType"}, wantErr: true},
+		{name: "json field asing nested", strict: true, body: `{"achievementType":"competition","details":{"competitonName":"KRI"}}`,
+			wantUnknown: []string{"details.competitonName"}, wantErr: true},
+		{name: "json field asing diabaikan saat strict nonaktif", body: `{"achievementType":"competition","point":5}`},
+		{name: "multipart valid", strict: true, multipart: true, body: `{"achievementType":"competition"}`},
+		{name: "multipart field asing nested", strict: true, multipart: true, body: `{"achievementType":"competition","details":{"competitonName":"KRI"}}`,
+			wantUnknown: []string{"details.competitonName"}, wantErr: true},
+		{name: "multipart field asing diabaikan saat strict nonaktif", multipart: true, body: `{"achievementType":"competition","point":5}`},
+		{name: "multipart tanpa bagian data", strict: true, multipart: true, wantErr: true},
+		{name: "multipart field wajib kosong", multipart: true, body: `{"details":{}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setStrictJSON(t, tt.strict)
+			var req *http.Request
+			if tt.multipart {
+				req = multipartRequest(t, tt.body)
+			} else {
+				req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+				req.Header.Set("Content-Type", "application/json")
+			}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = req
+
+			var input bindTestInput
+			var err error
+			if tt.multipart {
+				err = BindStrictMultipartJSON(ctx, &input)
+			} else {
+				err = BindStrictJSON(ctx, &input)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var unknown *UnknownFieldsError
+			if errors.As(err, &unknown) != (tt.wantUnknown != nil) {
+				t.Fatalf("err = %v, want UnknownFieldsError %v", err, tt.wantUnknown)
+			}
+			if unknown != nil && !reflect.DeepEqual(unknown.Fields, tt.wantUnknown) {
+				t.Fatalf("field asing %v, want %v", unknown.Fields, tt.wantUnknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CertificateCode membuat kode verifikasi sertifikat prestasi (format XXXX-XXXX-XXXX-XXXX)
+// dari id prestasi dan waktu verifikasi, ditandatangani HMAC-SHA256 dengan CERTIFICATE_SECRET
+// (fallback JWT_SECRET). Kode sama untuk verifikasi yang sama, sehingga bisa dicek ulang;
+// verifikasi ulang (waktu berbeda) menghasilkan kode baru.
+func CertificateCode(achievementID string, verifiedAt time.Time) string {
+	secret := os.Getenv("CERTIFICATE_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(achievementID + "|" + strconv.FormatInt(verifiedAt.Unix(), 10)))
+
+	raw := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mac.Sum(nil))[:16]
+	groups := make([]string, 0, 4)
+	for i := 0; i < len(raw); i += 4 {
+		groups = append(groups, raw[i:i+4])
+	}
+	return strings.Join(groups, "-")
+}
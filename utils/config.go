@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// RuntimeConfig berisi setting non-rahasia yang boleh diubah tanpa restart
+// (SIGHUP atau POST /api/v1/admin/config/reload). Secret (JWT_SECRET, kredensial DB,
+// MONGO_URI) dan setting yang hanya berlaku saat boot (port, ukuran antrean, interval
+// polling) sengaja tidak termasuk.
+// Konsumen wajib membaca lewat Runtime() setiap kali dipakai, jangan menyimpan salinannya.
+type RuntimeConfig struct {
+	RequestTimeout       time.Duration `json:"requestTimeout" env:"REQUEST_TIMEOUT"`
+	RequestTimeoutLong   time.Duration `json:"requestTimeoutLong" env:"REQUEST_TIMEOUT_LONG"`
+	AdvisorCacheTTL      time.Duration `json:"advisorCacheTtl" env:"ADVISOR_CACHE_TTL"` // 0 = cache nonaktif
+	StatsCacheTTL        time.Duration `json:"statsCacheTtl" env:"STATS_CACHE_TTL"`     // 0 = cache nonaktif
+	MaxDraftsPerStudent  int           `json:"maxDraftsPerStudent" env:"MAX_DRAFTS_PER_STUDENT"`
+	MaxPointsOverride    int           `json:"maxPointsOverride" env:"MAX_POINTS_OVERRIDE"`
+	MaxDocumentBytes     int           `json:"maxDocumentBytes" env:"ACHIEVEMENT_MAX_DOCUMENT_BYTES"`
+	MaxAttachments       int           `json:"maxAttachments" env:"ACHIEVEMENT_MAX_ATTACHMENTS"`
+	TargetPerYear        int           `json:"targetPerYear" env:"ACHIEVEMENT_TARGET_PER_YEAR"`
+	ReviewSLADays        int           `json:"reviewSlaDays" env:"REVIEW_SLA_DAYS"`                    // fallback perkiraan waktu review
+	SubmissionExpiryDays int           `json:"submissionExpiryDays" env:"SUBMISSION_EXPIRY_DAYS"`      // 0 = pengajuan tidak kedaluwarsa
+	EventHorizonYears    int           `json:"eventHorizonYears" env:"ACHIEVEMENT_DATE_HORIZON_YEARS"` // batas usia eventDate, 0 = tanpa batas
+	NotifyWorkers        int           `json:"notifyWorkers" env:"NOTIFY_WORKERS"`
+	NotifyMaxAttempts    int           `json:"notifyMaxAttempts" env:"NOTIFY_MAX_ATTEMPTS"`
+	NotifyRetryDelay     time.Duration `json:"notifyRetryDelay" env:"NOTIFY_RETRY_DELAY"`
+	OutboxMaxAttempts    int           `json:"outboxMaxAttempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxRetryDelay     time.Duration `json:"outboxRetryDelay" env:"OUTBOX_RETRY_DELAY"`
+	StrictJSON           bool          `json:"strictJson" env:"STRICT_JSON"`
+}
+
+// Nilai default setting runtime (dipakai jika env kosong / tidak valid).
+const (
+	DefaultMaxDraftsPerStudent = 50
+	DefaultMaxDocumentBytes    = 12 * 1024 * 1024 // disisakan ruang dari batas keras Mongo 16MB
+	DefaultMaxAttachments      = 200
+)
+
+// ConfigChange adalah 1 field yang berubah saat reload.
+type ConfigChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+var (
+	runtimeConfig atomic.Pointer[RuntimeConfig]
+	runtimeInit   sync.Once
+	reloadMu      sync.Mutex // reload berurutan: SIGHUP & endpoint admin bisa bersamaan
+)
+
+// Runtime mengembalikan setting runtime yang sedang berlaku (snapshot immutable).
+func Runtime() *RuntimeConfig {
+	runtimeInit.Do(func() {
+		if runtimeConfig.Load() == nil {
+			cfg := loadRuntimeConfig()
+			runtimeConfig.Store(&cfg)
+		}
+	})
+	return runtimeConfig.Load()
+}
+
+// loadRuntimeConfig membaca setting runtime dari env. Nilai yang tidak valid
+// (bukan angka / <= 0) jatuh ke default, sama seperti pembacaan env sebelumnya.
+func loadRuntimeConfig() RuntimeConfig {
+	positive := func(key string, def int) int {
+		if n := GetEnvInt(key, def); n > 0 {
+			return n
+		}
+		return def
+	}
+
+	strict := os.Getenv("APP_ENV") != "production"
+	if os.Getenv("STRICT_JSON") != "" {
+		strict = GetEnvBool("STRICT_JSON", false)
+	}
+
+	return RuntimeConfig{
+		RequestTimeout:       GetEnvDuration("REQUEST_TIMEOUT", 10*time.Second),
+		RequestTimeoutLong:   GetEnvDuration("REQUEST_TIMEOUT_LONG", 60*time.Second),
+		AdvisorCacheTTL:      GetEnvDuration("ADVISOR_CACHE_TTL", 30*time.Second),
+		StatsCacheTTL:        GetEnvDuration("STATS_CACHE_TTL", 30*time.Second),
+		MaxDraftsPerStudent:  positive("MAX_DRAFTS_PER_STUDENT", DefaultMaxDraftsPerStudent),
+		MaxPointsOverride:    GetEnvInt("MAX_POINTS_OVERRIDE", 100),
+		MaxDocumentBytes:     positive("ACHIEVEMENT_MAX_DOCUMENT_BYTES", DefaultMaxDocumentBytes),
+		MaxAttachments:       positive("ACHIEVEMENT_MAX_ATTACHMENTS", DefaultMaxAttachments),
+		TargetPerYear:        GetEnvInt("ACHIEVEMENT_TARGET_PER_YEAR", 2),
+		ReviewSLADays:        positive("REVIEW_SLA_DAYS", 7),
+		SubmissionExpiryDays: GetEnvInt("SUBMISSION_EXPIRY_DAYS", 30),
+		EventHorizonYears:    GetEnvInt("ACHIEVEMENT_DATE_HORIZON_YEARS", 6),
+		NotifyWorkers:        max(GetEnvInt("NOTIFY_WORKERS", 4), 1),
+		NotifyMaxAttempts:    max(GetEnvInt("NOTIFY_MAX_ATTEMPTS", 5), 1),
+		NotifyRetryDelay:     GetEnvDuration("NOTIFY_RETRY_DELAY", 30*time.Second),
+		OutboxMaxAttempts:    max(GetEnvInt("OUTBOX_MAX_ATTEMPTS", 10), 1),
+		OutboxRetryDelay:     GetEnvDuration("OUTBOX_RETRY_DELAY", 30*time.Second),
+		StrictJSON:           strict,
+	}
+}
+
+// Validate menolak konfigurasi yang akan membuat server tidak berfungsi.
+func (c RuntimeConfig) Validate() error {
+	var errs []error
+	if c.RequestTimeout <= 0 {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT harus > 0"))
+	}
+	if c.RequestTimeoutLong < c.RequestTimeout {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT_LONG tidak boleh lebih kecil dari REQUEST_TIMEOUT"))
+	}
+	if c.AdvisorCacheTTL < 0 {
+		errs = append(errs, errors.New("ADVISOR_CACHE_TTL tidak boleh negatif"))
+	}
+	if c.StatsCacheTTL < 0 {
+		errs = append(errs, errors.New("STATS_CACHE_TTL tidak boleh negatif"))
+	}
+	if c.MaxPointsOverride < 0 {
+		errs = append(errs, errors.New("MAX_POINTS_OVERRIDE tidak boleh negatif"))
+	}
+	if c.MaxDocumentBytes > 16*1024*1024 {
+		errs = append(errs, errors.New("ACHIEVEMENT_MAX_DOCUMENT_BYTES melebihi batas dokumen Mongo (16MB)"))
+	}
+	if c.SubmissionExpiryDays < 0 {
+		errs = append(errs, errors.New("SUBMISSION_EXPIRY_DAYS tidak boleh negatif"))
+	}
+	if c.EventHorizonYears < 0 {
+		errs = append(errs, errors.New("ACHIEVEMENT_DATE_HORIZON_YEARS tidak boleh negatif"))
+	}
+	if c.TargetPerYear < 0 {
+		errs = append(errs, errors.New("ACHIEVEMENT_TARGET_PER_YEAR tidak boleh negatif"))
+	}
+	if c.NotifyWorkers > 256 {
+		errs = append(errs, errors.New("NOTIFY_WORKERS maksimal 256"))
+	}
+	if c.NotifyRetryDelay < 0 || c.OutboxRetryDelay < 0 {
+		errs = append(errs, errors.New("NOTIFY_RETRY_DELAY / OUTBOX_RETRY_DELAY tidak boleh negatif"))
+	}
+	return errors.Join(errs...)
+}
+
+// RuntimeConfigKeys mengembalikan nama env yang ikut di-reload.
+func RuntimeConfigKeys() []string {
+	t := reflect.TypeOf(RuntimeConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("env"))
+	}
+	return keys
+}
+
+// InitRuntimeConfig memvalidasi & memasang setting runtime saat boot (dipanggil setelah load .env).
+func InitRuntimeConfig() error {
+	cfg := loadRuntimeConfig()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	runtimeConfig.Store(&cfg)
+	return nil
+}
+
+// ReloadRuntimeConfig membaca ulang file .env (hanya key RuntimeConfigKeys yang diterapkan
+// ke environment proses), membangun konfigurasi baru, memvalidasi, lalu menukar pointer.
+// Jika validasi gagal, konfigurasi lama tetap berlaku. Mengembalikan field yang berubah.
+func ReloadRuntimeConfig() (map[string]ConfigChange, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	fileEnv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("gagal membaca .env: %w", err)
+	}
+
+	// Simpan nilai lama agar environment bisa dikembalikan jika validasi gagal.
+	previous := map[string]*string{}
+	for _, key := range RuntimeConfigKeys() {
+		v, ok := fileEnv[key]
+		if !ok {
+			continue
+		}
+		if old, had := os.LookupEnv(key); had {
+			previous[key] = &old
+		} else {
+			previous[key] = nil
+		}
+		_ = os.Setenv(key, v)
+	}
+
+	next := loadRuntimeConfig()
+	if err := next.Validate(); err != nil {
+		for key, old := range previous {
+			if old == nil {
+				_ = os.Unsetenv(key)
+			} else {
+				_ = os.Setenv(key, *old)
+			}
+		}
+		return nil, err
+	}
+
+	current := Runtime()
+	runtimeConfig.Store(&next)
+	return diffRuntimeConfig(*current, next), nil
+}
+
+// diffRuntimeConfig membandingkan 2 konfigurasi per field (key = nama JSON).
+func diffRuntimeConfig(old, next RuntimeConfig) map[string]ConfigChange {
+	diff := map[string]ConfigChange{}
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(next)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		a, b := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if a == b {
+			continue
+		}
+		// durasi ditulis sebagai string ("30s") agar mudah dibaca di audit log
+		if d, ok := a.(time.Duration); ok {
+			a, b = d.String(), b.(time.Duration).String()
+		}
+		diff[t.Field(i).Tag.Get("json")] = ConfigChange{Old: a, New: b}
+	}
+	return diff
+}
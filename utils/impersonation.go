@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonatedByKey adalah key context (gin.Context.Set) berisi ID admin yang
+// meng-impersonate request ini; diisi AuthMiddleware dari klaim impersonatedBy.
+const ImpersonatedByKey = "impersonatedBy"
+
+// ImpersonatorID mengembalikan ID admin yang meng-impersonate request ini (ok=false untuk
+// token biasa). ctx boleh *gin.Context atau context turunannya, sehingga service dan
+// repository bisa membacanya tanpa bergantung pada package middleware.
+func ImpersonatorID(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	id, _ := ctx.Value(ImpersonatedByKey).(uuid.UUID)
+	return id, id != uuid.Nil
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,73 +17,363 @@ import (
  Sesuai kebutuhan sistem (dan SRS), token harus menyimpan:
  - UserID     (uuid)  : identitas user
  - StudentID  (uuid)  : identitas mahasiswa untuk fitur prestasi
-                       (bisa uuid.Nil apabila user bukan mahasiswa)
+                       (bisa uuid.Nil apabila user tidak punya profil mahasiswa)
+ - LecturerID (uuid)  : identitas dosen (lecturers.id), uuid.Nil jika tidak punya profil dosen.
+                       Akun tertaut (mantan mahasiswa yang menjadi dosen) membawa keduanya.
  - Role       (string): nama role (admin / dosen_wali / mahasiswa)
  - Permissions([]string): daftar permission yang dimiliki user
+ - MustChangePassword (bool): user wajib ganti password sebelum akses endpoint lain
+ - TokenType  (string): "refresh" untuk refresh token, "mfa" untuk token tahap 2FA;
+                       kosong / "access" untuk access token
+ - RememberMe (bool): refresh token / token 2FA dari login "ingat saya" (TTL refresh lebih panjang)
+ - SessionID  (uuid, "sid"): sesi login = family refresh token; sama untuk access & refresh token
+                       hasil 1 login beserta rotasinya (dipakai daftar / pencabutan sesi)
+ - TokenVersion (int, "tv"): users.token_version saat token dibuat; token dengan versi lama
+                       ditolak (role/status/password berubah sejak token diterbitkan)
+ - ImpersonatedBy (uuid): admin yang meminjam identitas user (token impersonasi, 15 menit,
+                       tanpa refresh token); uuid.Nil untuk token biasa
+ - ID (jti, di RegisteredClaims): ID unik token, dipakai untuk denylist logout & rotasi refresh token
 */
 type JWTCustomClaims struct {
 	UserID      uuid.UUID `json:"userId"`
 	StudentID   uuid.UUID `json:"studentId"`
+	LecturerID  uuid.UUID `json:"lecturerId"`
 	Role        string    `json:"role"`
 	Permissions []string  `json:"permissions"`
+
+	MustChangePassword bool      `json:"mustChangePassword,omitempty"`
+	TokenType          string    `json:"tokenType,omitempty"`
+	RememberMe         bool      `json:"rememberMe,omitempty"`
+	SessionID          uuid.UUID `json:"sid,omitempty"`
+	TokenVersion       int       `json:"tv,omitempty"`
+	ImpersonatedBy     uuid.UUID `json:"impersonatedBy,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// getJWTSecret membaca JWT_SECRET dari environment setiap kali dipanggil.
+// Nilai klaim tokenType.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa" // bukti password benar, menunggu kode 2FA (POST /auth/2fa/challenge)
+)
+
+// ErrWrongTokenType dikembalikan jika jenis token tidak sesuai pemakaiannya
+// (refresh token dipakai sebagai access token, atau sebaliknya).
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// getJWTSecrets membaca secret HS256 dari environment setiap kali dipanggil.
 // Ini menghindari masalah ketika .env baru di-load setelah package di-import.
-func getJWTSecret() ([]byte, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
+//
+// Rotasi secret: JWT_SECRETS berisi daftar dipisah koma, TERBARU DULU. Token baru selalu
+// ditandatangani secret pertama; validasi menerima semua secret di daftar, sehingga token
+// lama tetap berlaku sampai kedaluwarsa. Langkah rotasi:
+//  1. JWT_SECRETS=baru,lama  (deploy; token lama & baru sama-sama valid)
+//  2. tunggu >= JWT_REFRESH_TTL / JWT_REMEMBER_ME_REFRESH_TTL
+//  3. JWT_SECRETS=baru       (secret lama berhenti diterima)
+//
+// Jika JWT_SECRETS kosong, JWT_SECRET (1 secret) dipakai seperti sebelumnya.
+func getJWTSecrets() ([][]byte, error) {
+	var secrets [][]byte
+	for _, s := range strings.Split(os.Getenv("JWT_SECRETS"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			secrets = append(secrets, []byte(s))
+		}
+	}
+	if len(secrets) == 0 {
+		if secret := os.Getenv("JWT_SECRET"); secret != "" {
+			secrets = append(secrets, []byte(secret))
+		}
+	}
+	if len(secrets) == 0 {
 		return nil, errors.New("JWT_SECRET is not configured")
 	}
-	return []byte(secret), nil
+	return secrets, nil
+}
+
+// Default klaim iss & aud. Token dari layanan kampus lain yang kebetulan memakai secret
+// yang sama ditolak karena iss/aud-nya berbeda.
+const (
+	defaultJWTIssuer   = "student-achievement-backend"
+	defaultJWTAudience = "student-achievement-api"
+)
+
+// JWTIssuer membaca JWT_ISSUER (default "student-achievement-backend").
+func JWTIssuer() string {
+	if v := strings.TrimSpace(os.Getenv("JWT_ISSUER")); v != "" {
+		return v
+	}
+	return defaultJWTIssuer
 }
 
-// GenerateToken membuat JWT access token yang menyimpan userID, studentID, role, dan permissions.
-// Expired time saat ini diset 24 jam (access token).
-func GenerateToken(userID uuid.UUID, studentID uuid.UUID, role string, permissions []string) (string, error) {
-	secret, err := getJWTSecret()
+// JWTAudience membaca JWT_AUDIENCE (default "student-achievement-api").
+func JWTAudience() string {
+	if v := strings.TrimSpace(os.Getenv("JWT_AUDIENCE")); v != "" {
+		return v
+	}
+	return defaultJWTAudience
+}
+
+// legacyTokenGraceUntil membaca JWT_LEGACY_TOKEN_GRACE_UNTIL (RFC3339): sampai waktu ini token
+// lama tanpa klaim iss & aud masih diterima. Kosong = token lama langsung ditolak.
+func legacyTokenGraceUntil() (time.Time, error) {
+	v := strings.TrimSpace(os.Getenv("JWT_LEGACY_TOKEN_GRACE_UNTIL"))
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
 	if err != nil {
-		return "", err
+		return time.Time{}, fmt.Errorf("JWT_LEGACY_TOKEN_GRACE_UNTIL harus RFC3339: %w", err)
+	}
+	return t, nil
+}
+
+// Default masa berlaku token (bisa diganti lewat env, lihat ValidateTokenTTLConfig).
+const (
+	defaultAccessTTL            = 24 * time.Hour
+	defaultRefreshTTL           = 7 * 24 * time.Hour
+	defaultRememberMeRefreshTTL = 30 * 24 * time.Hour
+	defaultMFATokenTTL          = 5 * time.Minute
+	defaultImpersonationTTL     = 15 * time.Minute
+)
+
+// tokenTTLEnv adalah env durasi token beserta default-nya.
+var tokenTTLEnv = []struct {
+	key string
+	def time.Duration
+}{
+	{"JWT_ACCESS_TTL", defaultAccessTTL},
+	{"JWT_REFRESH_TTL", defaultRefreshTTL},
+	{"JWT_REMEMBER_ME_REFRESH_TTL", defaultRememberMeRefreshTTL},
+	{"MFA_TOKEN_TTL", defaultMFATokenTTL},
+	{"IMPERSONATION_TOKEN_TTL", defaultImpersonationTTL},
+}
+
+// ValidateTokenTTLConfig memastikan env durasi token (JWT_ACCESS_TTL, JWT_REFRESH_TTL,
+// JWT_REMEMBER_ME_REFRESH_TTL, MFA_TOKEN_TTL, IMPERSONATION_TOKEN_TTL) bisa di-parse time.ParseDuration dan > 0.
+// Dipanggil saat boot agar salah konfigurasi tidak diam-diam jatuh ke default.
+func ValidateTokenTTLConfig() error {
+	var errs []error
+	for _, e := range tokenTTLEnv {
+		v := os.Getenv(e.key)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s tidak valid: %w", e.key, err))
+			continue
+		}
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s harus > 0", e.key))
+		}
+	}
+	if len(errs) == 0 && AccessTokenTTL() > RefreshTokenTTL(false) {
+		errs = append(errs, errors.New("JWT_ACCESS_TTL tidak boleh lebih panjang dari JWT_REFRESH_TTL"))
 	}
+	if len(errs) == 0 && RefreshTokenTTL(false) > RefreshTokenTTL(true) {
+		errs = append(errs, errors.New("JWT_REMEMBER_ME_REFRESH_TTL tidak boleh lebih pendek dari JWT_REFRESH_TTL"))
+	}
+	return errors.Join(errs...)
+}
 
-	claims := JWTCustomClaims{
+// AccessTokenTTL membaca JWT_ACCESS_TTL (default 24 jam).
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("JWT_ACCESS_TTL", defaultAccessTTL)
+}
+
+// MFATokenTTL membaca MFA_TOKEN_TTL (default 5 menit).
+func MFATokenTTL() time.Duration {
+	return GetEnvDuration("MFA_TOKEN_TTL", defaultMFATokenTTL)
+}
+
+// RefreshTokenTTL membaca JWT_REFRESH_TTL (default 7 hari), atau
+// JWT_REMEMBER_ME_REFRESH_TTL (default 30 hari) untuk login "ingat saya".
+func RefreshTokenTTL(rememberMe bool) time.Duration {
+	if rememberMe {
+		return GetEnvDuration("JWT_REMEMBER_ME_REFRESH_TTL", defaultRememberMeRefreshTTL)
+	}
+	return GetEnvDuration("JWT_REFRESH_TTL", defaultRefreshTTL)
+}
+
+// GenerateToken membuat JWT access token yang menyimpan userID, studentID, lecturerID, role, dan permissions.
+// Masa berlaku: JWT_ACCESS_TTL (default 24 jam).
+// mustChangePassword=true membuat token hanya bisa dipakai untuk alur ganti password.
+// sessionID adalah family refresh token pasangannya (uuid.Nil jika tanpa sesi);
+// tokenVersion adalah users.token_version saat ini.
+func GenerateToken(userID uuid.UUID, studentID uuid.UUID, lecturerID uuid.UUID, role string, permissions []string, mustChangePassword bool, sessionID uuid.UUID, tokenVersion int) (string, error) {
+	return signClaims(JWTCustomClaims{
 		UserID:      userID,
-		StudentID:   studentID, // bisa uuid.Nil kalau bukan mahasiswa
+		StudentID:   studentID,  // bisa uuid.Nil kalau tidak punya profil mahasiswa
+		LecturerID:  lecturerID, // bisa uuid.Nil kalau tidak punya profil dosen
 		Role:        role,
 		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // masa berlaku token
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID.String(),
-		},
+
+		MustChangePassword: mustChangePassword,
+		TokenType:          TokenTypeAccess,
+		SessionID:          sessionID,
+		TokenVersion:       tokenVersion,
+	}, uuid.NewString(), time.Now().Add(AccessTokenTTL()))
+}
+
+// SessionRevocationKey adalah kunci denylist untuk seluruh access token 1 sesi (klaim "sid"),
+// disimpan di tabel yang sama dengan jti access token yang dicabut.
+func SessionRevocationKey(sessionID uuid.UUID) string {
+	return "sid:" + sessionID.String()
+}
+
+// RefreshTokenInfo adalah metadata refresh token yang disimpan di tabel refresh_tokens.
+type RefreshTokenInfo struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// GenerateRefreshToken membuat refresh token (tokenType "refresh") dengan klaim yang sama
+// dan masa berlaku lebih panjang: RefreshTokenTTL(rememberMe). Refresh token hanya
+// diterima oleh POST /api/v1/auth/refresh, tidak oleh endpoint lain. Setiap token punya
+// jti unik agar bisa dicatat & dicabut. Klaim rememberMe ikut dibawa saat rotasi.
+func GenerateRefreshToken(userID uuid.UUID, studentID uuid.UUID, lecturerID uuid.UUID, role string, permissions []string, mustChangePassword bool, rememberMe bool, sessionID uuid.UUID, tokenVersion int) (string, RefreshTokenInfo, error) {
+	info := RefreshTokenInfo{
+		JTI:       uuid.NewString(),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL(rememberMe)),
+	}
+	token, err := signClaims(JWTCustomClaims{
+		UserID:             userID,
+		StudentID:          studentID,
+		LecturerID:         lecturerID,
+		Role:               role,
+		Permissions:        permissions,
+		MustChangePassword: mustChangePassword,
+		TokenType:          TokenTypeRefresh,
+		RememberMe:         rememberMe,
+		SessionID:          sessionID,
+		TokenVersion:       tokenVersion,
+	}, info.JTI, info.ExpiresAt)
+	return token, info, err
+}
+
+// GenerateMFAToken membuat token tahap 2FA (tokenType "mfa") yang hanya berisi userID
+// (dan pilihan rememberMe dari login), berlaku MFA_TOKEN_TTL (default 5 menit).
+// Tidak diterima sebagai access token.
+func GenerateMFAToken(userID uuid.UUID, rememberMe bool) (string, time.Duration, error) {
+	ttl := MFATokenTTL()
+	token, err := signClaims(JWTCustomClaims{
+		UserID:     userID,
+		TokenType:  TokenTypeMFA,
+		RememberMe: rememberMe,
+	}, uuid.NewString(), time.Now().Add(ttl))
+	return token, ttl, err
+}
+
+// GenerateImpersonationToken membuat access token atas nama user target untuk admin
+// impersonatorID (klaim impersonatedBy), berlaku IMPERSONATION_TOKEN_TTL (default 15 menit).
+// Tidak ada refresh token maupun sesi: setelah kedaluwarsa admin harus meminta token baru.
+func GenerateImpersonationToken(userID uuid.UUID, studentID uuid.UUID, lecturerID uuid.UUID, role string, permissions []string, tokenVersion int, impersonatorID uuid.UUID) (token, jti string, expiresAt time.Time, err error) {
+	jti = uuid.NewString()
+	expiresAt = time.Now().Add(GetEnvDuration("IMPERSONATION_TOKEN_TTL", defaultImpersonationTTL))
+	token, err = signClaims(JWTCustomClaims{
+		UserID:         userID,
+		StudentID:      studentID,
+		LecturerID:     lecturerID,
+		Role:           role,
+		Permissions:    permissions,
+		TokenType:      TokenTypeAccess,
+		TokenVersion:   tokenVersion,
+		ImpersonatedBy: impersonatorID,
+	}, jti, expiresAt)
+	return token, jti, expiresAt, err
+}
+
+// signClaims melengkapi RegisteredClaims (exp, iat, sub, jti) lalu menandatangani token.
+// Algoritma mengikuti JWT_ALG (HS256 default, RS256 opsional; lihat loadJWTKeys).
+func signClaims(claims JWTCustomClaims, jti string, expiresAt time.Time) (string, error) {
+	keys, err := loadJWTKeys()
+	if err != nil {
+		return "", err
+	}
+	if keys.signKey == nil {
+		return "", errors.New("JWT_PRIVATE_KEY_PATH is not configured")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt), // masa berlaku token
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   claims.UserID.String(),
+		Issuer:    JWTIssuer(),
+		Audience:  jwt.ClaimStrings{JWTAudience()},
+		ID:        jti, // jti: dasar pencabutan token (logout, rotasi refresh token)
+	}
+
+	token := jwt.NewWithClaims(keys.method, claims)
+	token.Header["kid"] = keys.signKID
+	return token.SignedString(keys.signKey)
 }
 
-// ValidateToken mem-validasi JWT dan mengembalikan *JWTCustomClaims jika valid.
-// - Mengecek signing method (HMAC).
-// - Menggunakan JWT_SECRET dari environment.
-// - Mengecek expiration dan validitas klaim.
+// ValidateToken mem-validasi JWT access token dan mengembalikan *JWTCustomClaims jika valid.
+// - Mengecek signing method (harus sama dengan JWT_ALG).
+// - Menggunakan JWT_SECRET (HS256) atau public key RSA (RS256) dari environment.
+// - Mengecek expiration dan validitas klaim, termasuk iss (JWT_ISSUER) & aud (JWT_AUDIENCE);
+//   token lama tanpa iss/aud hanya diterima sampai JWT_LEGACY_TOKEN_GRACE_UNTIL.
+// - Menolak refresh token (ErrWrongTokenType); token lama tanpa tokenType dianggap access token.
 func ValidateToken(tokenString string) (*JWTCustomClaims, error) {
-	secret, err := getJWTSecret()
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != "" && claims.TokenType != TokenTypeAccess {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken sama dengan ValidateToken tetapi hanya menerima tokenType "refresh".
+func ValidateRefreshToken(tokenString string) (*JWTCustomClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// ValidateMFAToken hanya menerima token tahap 2FA (tokenType "mfa").
+func ValidateMFAToken(tokenString string) (*JWTCustomClaims, error) {
+	claims, err := parseToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
+	if claims.TokenType != TokenTypeMFA {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&JWTCustomClaims{},
-		func(t *jwt.Token) (interface{}, error) {
-			// verifikasi signing method HMAC
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return secret, nil
-		},
+func parseToken(tokenString string) (*JWTCustomClaims, error) {
+	keys, err := loadJWTKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		// hanya algoritma JWT_ALG yang diterima (mencegah token HS256 yang
+		// ditandatangani dengan public key RSA, atau alg "none")
+		if t.Method.Alg() != keys.method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return keys.verificationKeys(t), nil
+	}
+	methods := jwt.WithValidMethods([]string{keys.method.Alg()})
+
+	token, err := jwt.ParseWithClaims(tokenString, &JWTCustomClaims{}, keyFunc,
+		methods,
+		jwt.WithIssuer(JWTIssuer()),
+		jwt.WithAudience(JWTAudience()),
 	)
+	if errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+		// Token lama (sebelum iss/aud ditambahkan) diterima selama masa transisi saja.
+		token, err = parseLegacyToken(tokenString, keyFunc, methods, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -93,3 +385,21 @@ func ValidateToken(tokenString string) (*JWTCustomClaims, error) {
 
 	return claims, nil
 }
+
+// parseLegacyToken menerima token tanpa klaim iss DAN aud sampai JWT_LEGACY_TOKEN_GRACE_UNTIL.
+// Token yang hanya punya salah satunya, atau di luar masa transisi, ditolak dengan cause.
+func parseLegacyToken(tokenString string, keyFunc jwt.Keyfunc, methods jwt.ParserOption, cause error) (*jwt.Token, error) {
+	until, err := legacyTokenGraceUntil()
+	if err != nil || !time.Now().Before(until) {
+		return nil, cause
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &JWTCustomClaims{}, keyFunc, methods)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*JWTCustomClaims)
+	if !ok || claims.Issuer != "" || len(claims.Audience) > 0 {
+		return nil, cause
+	}
+	return token, nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma JWT yang didukung (env JWT_ALG).
+const (
+	JWTAlgHS256 = "HS256" // default: shared secret JWT_SECRET
+	JWTAlgRS256 = "RS256" // key pair JWT_PRIVATE_KEY_PATH / JWT_PUBLIC_KEY_PATH
+)
+
+// jwtKeys adalah konfigurasi penandatanganan yang sedang berlaku.
+type jwtKeys struct {
+	method     jwt.SigningMethod
+	signKey    any    // []byte (HS256) atau *rsa.PrivateKey (RS256); nil = hanya verifikasi
+	signKID    string // header "kid" token baru
+	verifyKeys []verifyKey
+}
+
+// verifyKey adalah 1 key yang diterima saat validasi, urut terbaru dulu.
+type verifyKey struct {
+	kid string
+	key any // []byte (HS256) atau *rsa.PublicKey (RS256)
+}
+
+// verificationKeys memilih key untuk token t: jika header kid dikenal langsung ke key tsb,
+// selain itu (token lama tanpa kid / kid tidak dikenal) semua key dicoba berurutan.
+func (k *jwtKeys) verificationKeys(t *jwt.Token) any {
+	if kid, _ := t.Header["kid"].(string); kid != "" {
+		for _, vk := range k.verifyKeys {
+			if vk.kid == kid {
+				return vk.key
+			}
+		}
+	}
+	set := jwt.VerificationKeySet{}
+	for _, vk := range k.verifyKeys {
+		set.Keys = append(set.Keys, vk.key)
+	}
+	return set
+}
+
+// keyID menurunkan kid dari materi key (secret HMAC atau public key DER): 8 byte awal
+// SHA-256 dalam hex. Secret tidak bisa ditebak dari kid.
+func keyID(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+// rsaKeyID menurunkan kid dari public key RSA.
+func rsaKeyID(pub *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	return keyID(der)
+}
+
+// File key RSA di-cache per path agar tidak dibaca ulang setiap request.
+var (
+	rsaKeyMu      sync.Mutex
+	rsaPrivateKey = map[string]*rsa.PrivateKey{}
+	rsaPublicKey  = map[string]*rsa.PublicKey{}
+)
+
+// jwtAlg membaca JWT_ALG (default HS256).
+func jwtAlg() string {
+	alg := strings.ToUpper(strings.TrimSpace(os.Getenv("JWT_ALG")))
+	if alg == "" {
+		return JWTAlgHS256
+	}
+	return alg
+}
+
+// loadJWTKeys menyiapkan key sesuai JWT_ALG. Seperti JWT_SECRET, env dibaca setiap kali
+// dipanggil (hanya isi file key RSA yang di-cache).
+// RS256 tanpa JWT_PRIVATE_KEY_PATH tetap bisa memverifikasi (signKey nil), dan tanpa
+// JWT_PUBLIC_KEY_PATH public key diambil dari private key.
+func loadJWTKeys() (*jwtKeys, error) {
+	switch alg := jwtAlg(); alg {
+	case JWTAlgHS256:
+		secrets, err := getJWTSecrets()
+		if err != nil {
+			return nil, err
+		}
+		keys := &jwtKeys{method: jwt.SigningMethodHS256, signKey: secrets[0], signKID: keyID(secrets[0])}
+		for _, secret := range secrets {
+			keys.verifyKeys = append(keys.verifyKeys, verifyKey{kid: keyID(secret), key: secret})
+		}
+		return keys, nil
+
+	case JWTAlgRS256:
+		keys := &jwtKeys{method: jwt.SigningMethodRS256}
+		if path := os.Getenv("JWT_PRIVATE_KEY_PATH"); path != "" {
+			priv, err := loadRSAPrivateKey(path)
+			if err != nil {
+				return nil, err
+			}
+			keys.signKey = priv
+			keys.signKID = rsaKeyID(&priv.PublicKey)
+			keys.verifyKeys = []verifyKey{{kid: keys.signKID, key: &priv.PublicKey}}
+		}
+		if path := os.Getenv("JWT_PUBLIC_KEY_PATH"); path != "" {
+			pub, err := loadRSAPublicKey(path)
+			if err != nil {
+				return nil, err
+			}
+			keys.verifyKeys = []verifyKey{{kid: rsaKeyID(pub), key: pub}}
+		}
+		if len(keys.verifyKeys) == 0 {
+			return nil, errors.New("JWT_ALG=RS256 membutuhkan JWT_PRIVATE_KEY_PATH dan/atau JWT_PUBLIC_KEY_PATH")
+		}
+		return keys, nil
+
+	default:
+		return nil, fmt.Errorf("JWT_ALG %q tidak didukung (HS256 atau RS256)", alg)
+	}
+}
+
+// ValidateJWTConfig memastikan JWT_ALG dan key-nya bisa dipakai (serta format
+// JWT_LEGACY_TOKEN_GRACE_UNTIL); dipanggil saat boot.
+func ValidateJWTConfig() error {
+	if _, err := legacyTokenGraceUntil(); err != nil {
+		return err
+	}
+	keys, err := loadJWTKeys()
+	if err != nil {
+		return err
+	}
+	if keys.signKey == nil {
+		return errors.New("JWT_PRIVATE_KEY_PATH wajib diisi untuk menerbitkan token RS256")
+	}
+	if priv, ok := keys.signKey.(*rsa.PrivateKey); ok {
+		if pub := keys.verifyKeys[0].key.(*rsa.PublicKey); !priv.PublicKey.Equal(pub) {
+			return errors.New("JWT_PUBLIC_KEY_PATH bukan pasangan JWT_PRIVATE_KEY_PATH")
+		}
+	}
+	return nil
+}
+
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	rsaKeyMu.Lock()
+	defer rsaKeyMu.Unlock()
+	if key, ok := rsaPrivateKey[path]; ok {
+		return key, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca JWT_PRIVATE_KEY_PATH: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_PATH bukan RSA private key PEM: %w", err)
+	}
+	rsaPrivateKey[path] = key
+	return key, nil
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	rsaKeyMu.Lock()
+	defer rsaKeyMu.Unlock()
+	if key, ok := rsaPublicKey[path]; ok {
+		return key, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca JWT_PUBLIC_KEY_PATH: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_PUBLIC_KEY_PATH bukan RSA public key PEM: %w", err)
+	}
+	rsaPublicKey[path] = key
+	return key, nil
+}
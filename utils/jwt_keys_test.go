@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// signWith membuat access token dengan JWT_SECRETS=secrets (secret pertama yang menandatangani).
+func signWith(t *testing.T, secrets string) string {
+	t.Helper()
+	t.Setenv("JWT_SECRETS", secrets)
+	token, err := GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// withKID menandatangani ulang klaim token dengan secret & header kid tertentu (kid "" = tanpa kid).
+func withKID(t *testing.T, token, secret, kid string) string {
+	t.Helper()
+	claims := &JWTCustomClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		t.Fatal(err)
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	signed, err := tok.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJWTSecretRotation(t *testing.T) {
+	t.Setenv("JWT_ALG", "")
+	t.Setenv("JWT_SECRET", "")
+
+	oldToken := signWith(t, "secret-lama")
+	newToken := signWith(t, "secret-baru,secret-lama")
+	strangerToken := signWith(t, "secret-lain")
+
+	if kid := headerKID(t, newToken); kid != keyID([]byte("secret-baru")) {
+		t.Fatalf("kid token baru = %q, want kid secret pertama", kid)
+	}
+
+	tests := []struct {
+		name    string
+		secrets string
+		token   string
+		wantOK  bool
+	}{
+		{name: "masa rotasi: token lama valid", secrets: "secret-baru,secret-lama", token: oldToken, wantOK: true},
+		{name: "masa rotasi: token baru valid", secrets: "secret-baru,secret-lama", token: newToken, wantOK: true},
+		{name: "masa rotasi: token lama tanpa kid valid", secrets: "secret-baru,secret-lama",
+			token: withKID(t, oldToken, "secret-lama", ""), wantOK: true},
+		{name: "masa rotasi: kid tidak dikenal mencoba semua secret", secrets: "secret-baru,secret-lama",
+			token: withKID(t, oldToken, "secret-lama", "kid-lain"), wantOK: true},
+		{name: "kid menunjuk secret lain ditolak", secrets: "secret-baru,secret-lama",
+			token: withKID(t, oldToken, "secret-lama", keyID([]byte("secret-baru"))), wantOK: false},
+		{name: "secret asing ditolak", secrets: "secret-baru,secret-lama", token: strangerToken, wantOK: false},
+		{name: "sampah ditolak", secrets: "secret-baru,secret-lama", token: "bukan.sebuah.jwt", wantOK: false},
+		{name: "string kosong ditolak", secrets: "secret-baru,secret-lama", token: "", wantOK: false},
+		{name: "setelah rotasi selesai token lama ditolak", secrets: "secret-baru", token: oldToken, wantOK: false},
+		{name: "setelah rotasi selesai token baru valid", secrets: "secret-baru", token: newToken, wantOK: true},
+		{name: "spasi di daftar diabaikan", secrets: " secret-baru , , secret-lama ", token: oldToken, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRETS", tt.secrets)
+			_, err := ValidateToken(tt.token)
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("ValidateToken err = %v, want ok=%v", err, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestJWTSecretFallback: tanpa JWT_SECRETS, JWT_SECRET tunggal tetap dipakai.
+func TestJWTSecretFallback(t *testing.T) {
+	t.Setenv("JWT_ALG", "")
+	t.Setenv("JWT_SECRETS", "")
+	t.Setenv("JWT_SECRET", "secret-lama")
+	token, err := GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JWT_SECRETS", "secret-baru,secret-lama")
+	if _, err := ValidateToken(token); err != nil {
+		t.Fatalf("token dari JWT_SECRET ditolak setelah pindah ke JWT_SECRETS: %v", err)
+	}
+
+	t.Setenv("JWT_SECRETS", "")
+	t.Setenv("JWT_SECRET", "")
+	if _, err := GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), 0); err == nil {
+		t.Fatal("token dibuat tanpa secret")
+	}
+}
+
+func headerKID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTCustomClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// lifetime mengembalikan exp - iat token.
+func lifetime(t *testing.T, claims *JWTCustomClaims) time.Duration {
+	t.Helper()
+	if claims.ExpiresAt == nil || claims.IssuedAt == nil {
+		t.Fatal("token tanpa exp/iat")
+	}
+	return claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+}
+
+func TestTokenTTLs(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		wantAccess     time.Duration
+		wantRefresh    time.Duration
+		wantRememberMe time.Duration
+	}{
+		{name: "default", wantAccess: 24 * time.Hour, wantRefresh: 7 * 24 * time.Hour, wantRememberMe: 30 * 24 * time.Hour},
+		{name: "lab bersama: token pendek",
+			env:        map[string]string{"JWT_ACCESS_TTL": "15m", "JWT_REFRESH_TTL": "8h", "JWT_REMEMBER_ME_REFRESH_TTL": "720h"},
+			wantAccess: 15 * time.Minute, wantRefresh: 8 * time.Hour, wantRememberMe: 720 * time.Hour},
+		{name: "hanya remember-me diubah",
+			env:        map[string]string{"JWT_REMEMBER_ME_REFRESH_TTL": "2160h"},
+			wantAccess: 24 * time.Hour, wantRefresh: 7 * 24 * time.Hour, wantRememberMe: 2160 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_ALG", "")
+			t.Setenv("JWT_SECRETS", "")
+			t.Setenv("JWT_SECRET", "test-secret")
+			for _, k := range []string{"JWT_ACCESS_TTL", "JWT_REFRESH_TTL", "JWT_REMEMBER_ME_REFRESH_TTL"} {
+				t.Setenv(k, tt.env[k])
+			}
+			if err := ValidateTokenTTLConfig(); err != nil {
+				t.Fatalf("ValidateTokenTTLConfig: %v", err)
+			}
+			userID, sessionID := uuid.New(), uuid.New()
+
+			access, err := GenerateToken(userID, uuid.Nil, uuid.Nil, "mahasiswa", nil, false, sessionID, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := ValidateToken(access)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lifetime(t, claims); got != tt.wantAccess {
+				t.Fatalf("access token berlaku %s, want %s", got, tt.wantAccess)
+			}
+
+			for _, rememberMe := range []bool{false, true} {
+				want := tt.wantRefresh
+				if rememberMe {
+					want = tt.wantRememberMe
+				}
+				refresh, info, err := GenerateRefreshToken(userID, uuid.Nil, uuid.Nil, "mahasiswa", nil, false, rememberMe, sessionID, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				claims, err := ValidateRefreshToken(refresh)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := lifetime(t, claims); got != want {
+					t.Fatalf("rememberMe=%v: refresh token berlaku %s, want %s", rememberMe, got, want)
+				}
+				if claims.RememberMe != rememberMe {
+					t.Fatalf("klaim rememberMe = %v, want %v", claims.RememberMe, rememberMe)
+				}
+				if !info.ExpiresAt.Truncate(time.Second).Equal(claims.ExpiresAt.Time) {
+					t.Fatalf("ExpiresAt tersimpan %s, exp token %s", info.ExpiresAt, claims.ExpiresAt.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTokenTTLConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string // "" = valid
+	}{
+		{name: "kosong memakai default"},
+		{name: "durasi valid", env: map[string]string{"JWT_ACCESS_TTL": "30m", "JWT_REFRESH_TTL": "12h"}},
+		{name: "bukan durasi", env: map[string]string{"JWT_ACCESS_TTL": "24"}, wantErr: "JWT_ACCESS_TTL tidak valid"},
+		{name: "nol", env: map[string]string{"JWT_REFRESH_TTL": "0s"}, wantErr: "JWT_REFRESH_TTL harus > 0"},
+		{name: "negatif", env: map[string]string{"MFA_TOKEN_TTL": "-5m"}, wantErr: "MFA_TOKEN_TTL harus > 0"},
+		{name: "access lebih panjang dari refresh",
+			env:     map[string]string{"JWT_ACCESS_TTL": "48h", "JWT_REFRESH_TTL": "24h"},
+			wantErr: "JWT_ACCESS_TTL tidak boleh lebih panjang"},
+		{name: "remember-me lebih pendek dari refresh",
+			env:     map[string]string{"JWT_REMEMBER_ME_REFRESH_TTL": "24h"},
+			wantErr: "JWT_REMEMBER_ME_REFRESH_TTL tidak boleh lebih pendek"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, e := range tokenTTLEnv {
+				t.Setenv(e.key, tt.env[e.key])
+			}
+			err := ValidateTokenTTLConfig()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want mengandung %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// forgeToken menandatangani access token dengan secret yang sama tapi iss/aud sesuai argumen
+// (nil = klaim tidak ada), seperti token dari layanan kampus lain.
+func forgeToken(t *testing.T, secret string, iss *string, aud []string) string {
+	t.Helper()
+	now := time.Now()
+	claims := JWTCustomClaims{
+		UserID:    uuid.New(),
+		Role:      "admin",
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	if iss != nil {
+		claims.Issuer = *iss
+	}
+	if aud != nil {
+		claims.Audience = aud
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestValidateTokenIssuerAudience(t *testing.T) {
+	str := func(s string) *string { return &s }
+	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		iss    *string
+		aud    []string
+		wantOK bool
+	}{
+		{name: "iss & aud default", iss: str(defaultJWTIssuer), aud: []string{defaultJWTAudience}, wantOK: true},
+		{name: "issuer layanan lain", iss: str("sistem-krs"), aud: []string{defaultJWTAudience}},
+		{name: "audience layanan lain", iss: str(defaultJWTIssuer), aud: []string{"krs-api"}},
+		{name: "audience berisi beberapa termasuk milik kita", iss: str(defaultJWTIssuer),
+			aud: []string{"krs-api", defaultJWTAudience}, wantOK: true},
+		{name: "iss & aud dari env", env: map[string]string{"JWT_ISSUER": "sia-prod", "JWT_AUDIENCE": "sia-api"},
+			iss: str("sia-prod"), aud: []string{"sia-api"}, wantOK: true},
+		{name: "default ditolak saat env diubah", env: map[string]string{"JWT_ISSUER": "sia-prod", "JWT_AUDIENCE": "sia-api"},
+			iss: str(defaultJWTIssuer), aud: []string{defaultJWTAudience}},
+		{name: "token lama tanpa iss/aud, tanpa masa transisi"},
+		{name: "token lama tanpa iss/aud, dalam masa transisi",
+			env: map[string]string{"JWT_LEGACY_TOKEN_GRACE_UNTIL": future}, wantOK: true},
+		{name: "token lama tanpa iss/aud, masa transisi lewat",
+			env: map[string]string{"JWT_LEGACY_TOKEN_GRACE_UNTIL": past}},
+		{name: "hanya iss tanpa aud tetap ditolak dalam masa transisi",
+			env: map[string]string{"JWT_LEGACY_TOKEN_GRACE_UNTIL": future}, iss: str(defaultJWTIssuer)},
+		{name: "iss salah tanpa aud ditolak dalam masa transisi",
+			env: map[string]string{"JWT_LEGACY_TOKEN_GRACE_UNTIL": future}, iss: str("sistem-krs")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_ALG", "")
+			t.Setenv("JWT_SECRETS", "")
+			t.Setenv("JWT_SECRET", "secret-bersama")
+			for _, k := range []string{"JWT_ISSUER", "JWT_AUDIENCE", "JWT_LEGACY_TOKEN_GRACE_UNTIL"} {
+				t.Setenv(k, tt.env[k])
+			}
+			_, err := ValidateToken(forgeToken(t, "secret-bersama", tt.iss, tt.aud))
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("ValidateToken err = %v, want ok=%v", err, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestGenerateTokenSetsIssuerAudience: token yang diterbitkan membawa iss & aud dari env.
+func TestGenerateTokenSetsIssuerAudience(t *testing.T) {
+	t.Setenv("JWT_ALG", "")
+	t.Setenv("JWT_SECRETS", "")
+	t.Setenv("JWT_SECRET", "secret-bersama")
+	t.Setenv("JWT_ISSUER", "sia-prod")
+	t.Setenv("JWT_AUDIENCE", "sia-api")
+
+	token, err := GenerateToken(uuid.New(), uuid.Nil, uuid.Nil, "mahasiswa", nil, false, uuid.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != "sia-prod" || len(claims.Audience) != 1 || claims.Audience[0] != "sia-api" {
+		t.Fatalf("iss %q aud %v, want sia-prod [sia-api]", claims.Issuer, claims.Audience)
+	}
+}
+
+func TestValidateJWTConfigRejectsBadGraceDate(t *testing.T) {
+	t.Setenv("JWT_ALG", "")
+	t.Setenv("JWT_SECRET", "secret-bersama")
+	t.Setenv("JWT_LEGACY_TOKEN_GRACE_UNTIL", "2026-12-31")
+	if err := ValidateJWTConfig(); err == nil || !strings.Contains(err.Error(), "RFC3339") {
+		t.Fatalf("err = %v, want error format RFC3339", err)
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Aturan kebijakan password yang dikembalikan ValidatePassword (dipakai frontend untuk pesan).
+const (
+	PasswordRuleMinLength   = "min_length"   // kurang dari PASSWORD_MIN_LENGTH karakter
+	PasswordRuleLetter      = "letter"       // tidak ada huruf
+	PasswordRuleDigit       = "digit"        // tidak ada angka
+	PasswordRuleNotIdentity = "not_identity" // sama dengan username / email
+)
+
+// DefaultPasswordMinLength dipakai jika PASSWORD_MIN_LENGTH kosong / tidak valid.
+const DefaultPasswordMinLength = 8
+
+// PasswordMinLength membaca PASSWORD_MIN_LENGTH (default 8).
+func PasswordMinLength() int {
+	if n := GetEnvInt("PASSWORD_MIN_LENGTH", DefaultPasswordMinLength); n > 0 {
+		return n
+	}
+	return DefaultPasswordMinLength
+}
+
+// ValidatePassword memeriksa pw terhadap kebijakan password dan mengembalikan aturan
+// yang dilanggar (kosong = valid). identities berisi username/email pemilik akun:
+// password yang sama dengan salah satunya (atau bagian lokal email) ditolak.
+func ValidatePassword(pw string, identities ...string) []string {
+	var failed []string
+
+	if utf8.RuneCountInString(pw) < PasswordMinLength() {
+		failed = append(failed, PasswordRuleMinLength)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter {
+		failed = append(failed, PasswordRuleLetter)
+	}
+	if !hasDigit {
+		failed = append(failed, PasswordRuleDigit)
+	}
+
+	for _, id := range identities {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		local, _, _ := strings.Cut(id, "@")
+		if strings.EqualFold(pw, id) || strings.EqualFold(pw, local) {
+			failed = append(failed, PasswordRuleNotIdentity)
+			break
+		}
+	}
+	return failed
+}
+
+// PasswordPolicyDetails adalah details response 400 untuk password yang ditolak.
+func PasswordPolicyDetails(failed []string) map[string]any {
+	return map[string]any{
+		"failedRules": failed,
+		"minLength":   PasswordMinLength(),
+	}
+}
+
+// Batas BCRYPT_COST: di bawah 10 terlalu lemah, di atas 15 login terlalu lambat (> 1 detik).
+const (
+	DefaultBcryptCost = 10
+	MinBcryptCost     = 10
+	MaxBcryptCost     = 15
+)
+
+// BcryptCost membaca BCRYPT_COST (default 10). Nilai di luar 10–15 ditolak saat boot
+// (ValidateBcryptCostConfig); di sini jatuh ke default agar hash tetap bisa dibuat.
+func BcryptCost() int {
+	cost := GetEnvInt("BCRYPT_COST", DefaultBcryptCost)
+	if cost < MinBcryptCost || cost > MaxBcryptCost {
+		return DefaultBcryptCost
+	}
+	return cost
+}
+
+// ValidateBcryptCostConfig memastikan BCRYPT_COST (jika diisi) berupa angka 10–15.
+func ValidateBcryptCostConfig() error {
+	v := os.Getenv("BCRYPT_COST")
+	if v == "" {
+		return nil
+	}
+	if cost, err := strconv.Atoi(v); err != nil || cost < MinBcryptCost || cost > MaxBcryptCost {
+		return fmt.Errorf("BCRYPT_COST harus angka %d-%d, didapat %q", MinBcryptCost, MaxBcryptCost, v)
+	}
+	return nil
+}
+
+// HashPassword membuat hash bcrypt dengan cost BcryptCost().
+func HashPassword(pw string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pw), BcryptCost())
+	return string(hash), err
+}
+
+// PasswordNeedsRehash true jika hash dibuat dengan cost lebih rendah dari BcryptCost()
+// (di-upgrade saat login berhasil, karena hanya saat itu password plaintext tersedia).
+func PasswordNeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost < BcryptCost()
+}
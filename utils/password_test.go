@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		minLength  string
+		password   string
+		identities []string
+		want       []string
+	}{
+		{name: "valid", password: "rahasia123"},
+		{name: "terlalu pendek", password: "abc12", want: []string{PasswordRuleMinLength}},
+		{name: "tanpa huruf", password: "12345678", want: []string{PasswordRuleLetter}},
+		{name: "tanpa angka", password: "rahasiaku", want: []string{PasswordRuleDigit}},
+		{name: "seed lama melanggar beberapa aturan", password: "123123", want: []string{PasswordRuleMinLength, PasswordRuleLetter}},
+		{name: "sama dengan username", password: "Budi2024x", identities: []string{"budi2024x", "budi@kampus.ac.id"},
+			want: []string{PasswordRuleNotIdentity}},
+		{name: "sama dengan bagian lokal email", password: "budi.s1234", identities: []string{"budi", "budi.s1234@kampus.ac.id"},
+			want: []string{PasswordRuleNotIdentity}},
+		{name: "PASSWORD_MIN_LENGTH dikonfigurasi", minLength: "12", password: "rahasia123", want: []string{PasswordRuleMinLength}},
+		{name: "PASSWORD_MIN_LENGTH tidak valid memakai default", minLength: "-1", password: "rahasia1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PASSWORD_MIN_LENGTH", tt.minLength)
+			if got := ValidatePassword(tt.password, tt.identities...); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ValidatePassword(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "")
+	hash, err := HashPassword("rahasia123")
+	if err != nil || hash == "" {
+		t.Fatalf("HashPassword = %q, %v", hash, err)
+	}
+	// bcrypt membatasi input 72 byte: error harus diteruskan, bukan hash kosong.
+	if hash, err := HashPassword(string(make([]byte, 73))); err == nil {
+		t.Fatalf("HashPassword 73 byte = %q, want error", hash)
+	}
+}
+
+func TestBcryptCost(t *testing.T) {
+	tests := []struct {
+		env         string
+		wantCost    int
+		wantInvalid bool // ditolak ValidateBcryptCostConfig saat boot
+	}{
+		{env: "", wantCost: DefaultBcryptCost},
+		{env: "10", wantCost: 10},
+		{env: "12", wantCost: 12},
+		{env: "15", wantCost: 15},
+		{env: "9", wantCost: DefaultBcryptCost, wantInvalid: true},
+		{env: "16", wantCost: DefaultBcryptCost, wantInvalid: true},
+		{env: "dua belas", wantCost: DefaultBcryptCost, wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run("BCRYPT_COST="+tt.env, func(t *testing.T) {
+			t.Setenv("BCRYPT_COST", tt.env)
+			if got := BcryptCost(); got != tt.wantCost {
+				t.Fatalf("BcryptCost() = %d, want %d", got, tt.wantCost)
+			}
+			if err := ValidateBcryptCostConfig(); (err != nil) != tt.wantInvalid {
+				t.Fatalf("ValidateBcryptCostConfig() = %v, wantInvalid %v", err, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	hashWithCost := func(cost int) string {
+		h, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(h)
+	}
+	cost10, cost11 := hashWithCost(10), hashWithCost(11)
+
+	tests := []struct {
+		name string
+		env  string
+		hash string
+		want bool
+	}{
+		{name: "cost sama", env: "10", hash: cost10, want: false},
+		{name: "cost lebih rendah", env: "11", hash: cost10, want: true},
+		{name: "cost lebih tinggi tidak diturunkan", env: "10", hash: cost11, want: false},
+		{name: "bukan hash bcrypt", env: "11", hash: "plaintext", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BCRYPT_COST", tt.env)
+			if got := PasswordNeedsRehash(tt.hash); got != tt.want {
+				t.Fatalf("PasswordNeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// pdfLinesPerPage adalah jumlah baris teks per halaman A4 (font 11pt, spasi 14pt).
+const pdfLinesPerPage = 52
+
+// RenderTextPDF membuat dokumen PDF sederhana (A4, font Helvetica) dari judul dan baris-baris teks.
+// Dipakai untuk dokumen ringkas seperti transkrip/portofolio prestasi tanpa dependency eksternal.
+// Karakter non-ASCII diganti '?' karena font standar PDF hanya mendukung WinAnsi.
+func RenderTextPDF(title string, lines []string) []byte {
+	all := append([]string{title, ""}, lines...)
+
+	// Pecah baris menjadi beberapa halaman.
+	var pages [][]string
+	for len(all) > 0 {
+		n := pdfLinesPerPage
+		if len(all) < n {
+			n = len(all)
+		}
+		pages = append(pages, all[:n])
+		all = all[n:]
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objek 1: catalog, 2: pages, 3: font, lalu pasangan (page, content) per halaman.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for pi, page := range pages {
+		var content bytes.Buffer
+		content.WriteString("BT\n/F1 11 Tf\n14 TL\n50 800 Td\n")
+		for li, line := range page {
+			if pi == 0 && li == 0 {
+				// Judul sedikit lebih besar.
+				fmt.Fprintf(&content, "/F1 14 Tf\n(%s) Tj\n/F1 11 Tf\nT*\n", pdfEscape(line))
+				continue
+			}
+			fmt.Fprintf(&content, "(%s) Tj\nT*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		writeObj(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			len(offsets)+2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfEscape meng-escape karakter khusus string PDF dan mengganti karakter non-ASCII.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
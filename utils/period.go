@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale yang didukung untuk label periode laporan.
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+)
+
+// PeriodKeyFormat adalah format key periode bulanan di laporan ("2024-03").
+const PeriodKeyFormat = "2006-01"
+
+var monthNames = map[string][12]string{
+	LocaleID: {"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+		"Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+	LocaleEN: {"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+}
+
+var semesterNames = map[string][2]string{
+	LocaleID: {"Ganjil", "Genap"},
+	LocaleEN: {"Odd", "Even"},
+}
+
+// PeriodLabel adalah label tampilan untuk 1 key periode bulanan.
+type PeriodLabel struct {
+	Month    string `json:"month"`    // "Maret 2024"
+	Semester string `json:"semester"` // "Genap 2023/2024"
+}
+
+// LocaleFromAcceptLanguage memilih locale yang didukung dari header Accept-Language
+// (memperhatikan q-value). Default LocaleID.
+func LocaleFromAcceptLanguage(header string) string {
+	best, bestQ := LocaleID, -1.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := monthNames[lang]; !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// MonthLabel memformat bulan & tahun sesuai locale: "Maret 2024" / "March 2024".
+func MonthLabel(t time.Time, locale string) string {
+	names, ok := monthNames[locale]
+	if !ok {
+		names = monthNames[LocaleID]
+	}
+	return fmt.Sprintf("%s %d", names[t.Month()-1], t.Year())
+}
+
+// SemesterLabel memformat semester dari waktu t: "Ganjil 2024/2025".
+// Semester ganjil = 6 bulan pertama sejak AcademicYearStartMonth, sisanya genap.
+func SemesterLabel(t time.Time, locale string) string {
+	names, ok := semesterNames[locale]
+	if !ok {
+		names = semesterNames[LocaleID]
+	}
+	ay := AcademicYearOf(t)
+	monthsIn := (int(t.Month()) - int(AcademicYearStartMonth()) + 12) % 12
+	if monthsIn < 6 {
+		return names[0] + " " + ay.Label
+	}
+	return names[1] + " " + ay.Label
+}
+
+// PeriodLabels membuat label untuk setiap key periode "YYYY-MM".
+// Key yang formatnya tidak dikenali dilewati (tetap ada di data, hanya tanpa label).
+func PeriodLabels(keys []string, locale string) map[string]PeriodLabel {
+	out := make(map[string]PeriodLabel, len(keys))
+	for _, k := range keys {
+		t, err := time.Parse(PeriodKeyFormat, k)
+		if err != nil {
+			continue
+		}
+		out[k] = PeriodLabel{Month: MonthLabel(t, locale), Semester: SemesterLabel(t, locale)}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthLabel(t *testing.T) {
+	tests := []struct {
+		month  time.Month
+		locale string
+		want   string
+	}{
+		{month: time.January, locale: LocaleID, want: "Januari 2024"},
+		{month: time.March, locale: LocaleID, want: "Maret 2024"},
+		{month: time.May, locale: LocaleID, want: "Mei 2024"},
+		{month: time.August, locale: LocaleID, want: "Agustus 2024"},
+		{month: time.December, locale: LocaleID, want: "Desember 2024"},
+		{month: time.January, locale: LocaleEN, want: "January 2024"},
+		{month: time.March, locale: LocaleEN, want: "March 2024"},
+		{month: time.May, locale: LocaleEN, want: "May 2024"},
+		{month: time.August, locale: LocaleEN, want: "August 2024"},
+		{month: time.December, locale: LocaleEN, want: "December 2024"},
+		{month: time.October, locale: "fr", want: "Oktober 2024"}, // locale tidak dikenal → id
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.month.String(), func(t *testing.T) {
+			if got := MonthLabel(time.Date(2024, tt.month, 15, 0, 0, 0, 0, time.UTC), tt.locale); got != tt.want {
+				t.Fatalf("MonthLabel = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemesterLabelBoundaries(t *testing.T) {
+	tests := []struct {
+		name       string
+		startMonth string
+		at         time.Time
+		locale     string
+		want       string
+	}{
+		// Default tahun akademik mulai Agustus: Ganjil Agustus–Januari, Genap Februari–Juli.
+		{name: "Juli akhir genap", at: time.Date(2024, time.July, 31, 23, 0, 0, 0, time.UTC), locale: LocaleID, want: "Genap 2023/2024"},
+		{name: "Agustus awal ganjil", at: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Ganjil 2024/2025"},
+		{name: "Januari akhir ganjil", at: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Ganjil 2024/2025"},
+		{name: "Februari awal genap", at: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Genap 2024/2025"},
+		{name: "label en", at: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), locale: LocaleEN, want: "Even 2024/2025"},
+		{name: "label en ganjil", at: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), locale: LocaleEN, want: "Odd 2024/2025"},
+		// ACADEMIC_YEAR_START_MONTH=9: batas bergeser 1 bulan.
+		{name: "mulai September: Agustus masih genap", startMonth: "9", at: time.Date(2024, time.August, 31, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Genap 2023/2024"},
+		{name: "mulai September: September ganjil", startMonth: "9", at: time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Ganjil 2024/2025"},
+		{name: "mulai September: Februari masih ganjil", startMonth: "9", at: time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Ganjil 2024/2025"},
+		{name: "mulai September: Maret genap", startMonth: "9", at: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Genap 2024/2025"},
+		{name: "bulan mulai tidak valid → Agustus", startMonth: "13", at: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), locale: LocaleID, want: "Ganjil 2024/2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ACADEMIC_YEAR_START_MONTH", tt.startMonth)
+			if got := SemesterLabel(tt.at, tt.locale); got != tt.want {
+				t.Fatalf("SemesterLabel(%s) = %q, want %q", tt.at.Format(time.DateOnly), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodLabels(t *testing.T) {
+	t.Setenv("ACADEMIC_YEAR_START_MONTH", "")
+	got := PeriodLabels([]string{"2024-07", "2024-08", "bukan-periode"}, LocaleEN)
+	want := map[string]PeriodLabel{
+		"2024-07": {Month: "July 2024", Semester: "Even 2023/2024"},
+		"2024-08": {Month: "August 2024", Semester: "Odd 2024/2025"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("PeriodLabels = %v, want %v", got, want)
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Fatalf("label %s = %+v, want %+v", k, got[k], w)
+		}
+	}
+}
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: LocaleID},
+		{header: "en-US,en;q=0.9", want: LocaleEN},
+		{header: "id-ID,en;q=0.8", want: LocaleID},
+		{header: "fr-FR, en;q=0.5, id;q=0.7", want: LocaleID},
+		{header: "fr-FR, de;q=0.9", want: LocaleID},
+		{header: "EN", want: LocaleEN},
+	}
+	for _, tt := range tests {
+		if got := LocaleFromAcceptLanguage(tt.header); got != tt.want {
+			t.Errorf("LocaleFromAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// nimVisibleDigits adalah jumlah karakter awal NIM yang tetap ditampilkan saat dimasking
+// (cukup untuk mengenali angkatan/prodi, tidak cukup untuk mengidentifikasi individu).
+const nimVisibleDigits = 4
+
+// PII adalah data pribadi mahasiswa yang ikut keluar sistem lewat file export.
+type PII struct {
+	Email string
+	NIM   string
+}
+
+// MaskPII mengembalikan salinan data pribadi yang sudah dimasking jika mask = true.
+// Semua serializer export (CSV/PDF/dll) wajib melewati helper ini agar aturan masking seragam.
+func MaskPII(mask bool, p PII) PII {
+	if !mask {
+		return p
+	}
+	return PII{Email: MaskEmail(p.Email), NIM: MaskNIM(p.NIM)}
+}
+
+// MaskEmail menyisakan huruf pertama local-part dan domain: budi@kampus.ac.id → b***@kampus.ac.id.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok || local == "" {
+		if email == "" {
+			return ""
+		}
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
+
+// MaskNIM menyisakan beberapa karakter awal NIM: 2109106001 → 2109******.
+func MaskNIM(nim string) string {
+	nim = strings.TrimSpace(nim)
+	if utf8.RuneCountInString(nim) <= nimVisibleDigits {
+		return strings.Repeat("*", utf8.RuneCountInString(nim))
+	}
+	runes := []rune(nim)
+	return string(runes[:nimVisibleDigits]) + strings.Repeat("*", len(runes)-nimVisibleDigits)
+}
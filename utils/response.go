@@ -1,5 +1,16 @@
 package utils
 
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
 // APIResponse adalah format standar JSON yang akan diterima Frontend.
 // Contoh sukses  : { "status": true,  "message": "Login berhasil", "data": { ... } }
 // Contoh gagal   : { "status": false, "message": "Gagal login",     "errors": "invalid credentials" }
@@ -13,14 +24,40 @@ type APIResponse struct {
 // BuildResponseSuccess digunakan saat request berhasil (HTTP 200/201).
 // - message: deskripsi singkat keberhasilan (misal: "Login berhasil").
 // - data   : payload utama yang ingin dikirim ke frontend.
+//
+// Slice nil di data (langsung, atau sebagai value map seperti {"items": ...}) dikirim
+// sebagai [] bukan null, agar frontend bisa langsung memakai .map().
 func BuildResponseSuccess(message string, data interface{}) APIResponse {
 	return APIResponse{
 		Status:  true,
 		Message: message,
-		Data:    data,
+		Data:    emptySliceIfNil(data),
 	}
 }
 
+// emptySliceIfNil mengganti slice nil dengan slice kosong bertipe sama; untuk map
+// (mis. {"items": list, "meta": ...}) diterapkan pada tiap value (1 tingkat).
+func emptySliceIfNil(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	switch {
+	case !v.IsValid():
+		return data
+	case v.Kind() == reflect.Slice && v.IsNil():
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			item := v.MapIndex(key)
+			if item.Kind() == reflect.Interface {
+				item = item.Elem()
+			}
+			if item.Kind() == reflect.Slice && item.IsNil() {
+				v.SetMapIndex(key, reflect.MakeSlice(item.Type(), 0, 0))
+			}
+		}
+	}
+	return data
+}
+
 // BuildResponseFailed digunakan saat terjadi error (HTTP 400, 401, 500, dll).
 // - message: pesan utama untuk user (misal: "Input tidak valid").
 // - err    : detail error teknis (biasanya string, tapi bisa juga map jika mau lebih detail).
@@ -33,3 +70,68 @@ func BuildResponseFailed(message string, err interface{}, data interface{}) APIR
 		Data:    data,
 	}
 }
+
+// RespondSuccess mengirim respons sukses (status:true) dengan HTTP status 2xx.
+// Memasangkan status code & body di satu tempat mencegah kombinasi keliru
+// seperti HTTP 200 dengan body gagal.
+func RespondSuccess(ctx *gin.Context, status int, message string, data interface{}) {
+	checkResponseStatus(true, status, message)
+	ctx.JSON(status, BuildResponseSuccess(message, data))
+}
+
+// RespondOK adalah RespondSuccess dengan HTTP 200.
+func RespondOK(ctx *gin.Context, message string, data interface{}) {
+	RespondSuccess(ctx, http.StatusOK, message, data)
+}
+
+// RespondCreated adalah RespondSuccess dengan HTTP 201.
+func RespondCreated(ctx *gin.Context, message string, data interface{}) {
+	RespondSuccess(ctx, http.StatusCreated, message, data)
+}
+
+// RespondError mengirim respons gagal (status:false) dengan HTTP status 4xx/5xx.
+// - code   : kode error singkat (mis. "forbidden") atau detail teknis (err.Error()).
+// - details: data tambahan (biasanya nil).
+func RespondError(ctx *gin.Context, status int, message string, code interface{}, details interface{}) {
+	checkResponseStatus(false, status, message)
+	ctx.JSON(status, BuildResponseFailed(message, code, details))
+}
+
+// checkResponseStatus memastikan status code cocok dengan jenis respons:
+// sukses harus 2xx, gagal harus 4xx/5xx. Ketidakcocokan hanya dicatat di log (tidak pernah
+// panic, karena mode debug adalah default Gin); test menangkapnya lewat CheckResponseEnvelope.
+func checkResponseStatus(success bool, status int, message string) {
+	if err := checkStatusMatches(success, status); err != nil {
+		log.Printf("⚠️  %v (%q)", err, message)
+	}
+}
+
+func checkStatusMatches(success bool, status int) error {
+	ok := status >= 200 && status < 300
+	if !success {
+		ok = status >= 400 && status < 600
+	}
+	if !ok {
+		return fmt.Errorf("status HTTP %d tidak cocok dengan respons status=%t", status, success)
+	}
+	return nil
+}
+
+// CheckResponseEnvelope adalah helper test: memastikan body respons JSON memakai format
+// APIResponse dan field status cocok dengan HTTP status (true ↔ 2xx, false ↔ 4xx/5xx).
+// Respons non-JSON (file, CSV, PDF) dan tanpa body (204) dilewati.
+func CheckResponseEnvelope(status int, contentType string, body []byte) error {
+	if status == http.StatusNoContent || len(body) == 0 || !strings.HasPrefix(contentType, "application/json") {
+		return nil
+	}
+	var envelope struct {
+		Status *bool `json:"status"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("body respons bukan JSON: %w", err)
+	}
+	if envelope.Status == nil {
+		return fmt.Errorf("body respons tanpa field status: %s", body)
+	}
+	return checkStatusMatches(*envelope.Status, status)
+}
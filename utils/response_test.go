@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondMismatchDoesNotPanicInDebugMode(t *testing.T) {
+	gin.SetMode(gin.DebugMode)
+	t.Cleanup(func() { gin.SetMode(gin.TestMode) })
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	RespondError(ctx, http.StatusOK, "draft tidak ditemukan", "not_found", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200 (respons tetap dikirim apa adanya)", w.Code)
+	}
+	if err := CheckResponseEnvelope(w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()); err == nil {
+		t.Fatal("CheckResponseEnvelope tidak menangkap 200 dengan body gagal")
+	}
+}
+
+func TestCheckResponseEnvelope(t *testing.T) {
+	const jsonType = "application/json; charset=utf-8"
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{name: "sukses 200", status: 200, contentType: jsonType, body: `{"status":true,"message":"ok"}`},
+		{name: "sukses 201", status: 201, contentType: jsonType, body: `{"status":true,"message":"ok"}`},
+		{name: "gagal 404", status: 404, contentType: jsonType, body: `{"status":false,"message":"x"}`},
+		{name: "gagal 503", status: 503, contentType: jsonType, body: `{"status":false,"message":"x"}`},
+		{name: "200 dengan body gagal", status: 200, contentType: jsonType, body: `{"status":false}`, wantErr: true},
+		{name: "400 dengan body sukses", status: 400, contentType: jsonType, body: `{"status":true}`, wantErr: true},
+		{name: "tanpa field status", status: 200, contentType: jsonType, body: `{"message":"ok"}`, wantErr: true},
+		{name: "CSV dilewati", status: 200, contentType: "text/csv", body: "nim,nama"},
+		{name: "204 dilewati", status: 204, contentType: jsonType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckResponseEnvelope(tt.status, tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildResponseSuccessEmptySlices(t *testing.T) {
+	var nilMaps []map[string]any
+	var nilStrings []string
+	tests := []struct {
+		name string
+		data any
+		want string // JSON field data ("" = tidak ada)
+	}{
+		{name: "slice nil", data: nilMaps, want: `[]`},
+		{name: "slice string nil", data: nilStrings, want: `[]`},
+		{name: "slice berisi tetap", data: []string{"a"}, want: `["a"]`},
+		{name: "data nil tidak dikirim", data: nil, want: ``},
+		{name: "items nil di map", data: map[string]any{"items": nilMaps, "meta": map[string]any{"page": 1}},
+			want: `{"items":[],"meta":{"page":1}}`},
+		{name: "map bertipe slice", data: map[string][]string{"tags": nil}, want: `{"tags":[]}`},
+		{name: "slice bersarang 2 tingkat tidak diubah", data: map[string]any{"meta": map[string]any{"ids": nilStrings}},
+			want: `{"meta":{"ids":null}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := json.Marshal(BuildResponseSuccess("ok", tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var resp struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(raw, &resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(resp.Data); got != tt.want {
+				t.Fatalf("data %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
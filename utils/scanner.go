@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrScanTimeout dikembalikan jika scanner tidak merespons dalam batas waktu (mode fail-closed).
+var ErrScanTimeout = errors.New("malware scan timed out")
+
+// Scanner memeriksa isi file upload dari malware.
+// clean=false berarti file terinfeksi; signature berisi nama signature yang terdeteksi.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (clean bool, signature string, err error)
+}
+
+// NoopScanner selalu menganggap file bersih (default jika scanning tidak diaktifkan).
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(context.Context, io.Reader) (bool, string, error) {
+	return true, "", nil
+}
+
+// ClamAVScanner memindai file via clamd (perintah INSTREAM over TCP).
+type ClamAVScanner struct {
+	Addr string // host:port clamd, misal "localhost:3310"
+}
+
+// clamChunkSize: ukuran chunk INSTREAM (harus <= StreamMaxLength clamd).
+const clamChunkSize = 32 * 1024
+
+func (s ClamAVScanner) Scan(ctx context.Context, r io.Reader) (bool, string, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return false, "", fmt.Errorf("clamd dial: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return false, "", fmt.Errorf("clamd write: %w", err)
+	}
+
+	buf := make([]byte, clamChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return false, "", fmt.Errorf("clamd write: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return false, "", fmt.Errorf("clamd write: %w", err)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return false, "", rerr
+		}
+	}
+	// chunk panjang 0 menandai akhir stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return false, "", fmt.Errorf("clamd write: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && reply == "" {
+		return false, "", fmt.Errorf("clamd read: %w", err)
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+
+	// Format balasan: "stream: OK" atau "stream: <Signature> FOUND"
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return true, "", nil
+	case strings.HasSuffix(reply, " FOUND"):
+		sig := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return false, sig, nil
+	default:
+		return false, "", fmt.Errorf("clamd: %s", reply)
+	}
+}
+
+// boundedScanner membatasi waktu scan. Jika scanner hang / error:
+// - failOpen=true  → file dianggap bersih (upload tetap jalan, kejadian di-log)
+// - failOpen=false → error dikembalikan (upload ditolak)
+type boundedScanner struct {
+	inner    Scanner
+	timeout  time.Duration
+	failOpen bool
+}
+
+// NewBoundedScanner membungkus scanner dengan batas waktu & kebijakan fail open/closed.
+func NewBoundedScanner(inner Scanner, timeout time.Duration, failOpen bool) Scanner {
+	return &boundedScanner{inner: inner, timeout: timeout, failOpen: failOpen}
+}
+
+type scanResult struct {
+	clean     bool
+	signature string
+	err       error
+}
+
+// gatedReader meneruskan Read ke reader upload sampai close dipanggil. close menunggu Read
+// yang sedang berjalan selesai, sehingga setelah Scan kembali tidak ada lagi goroutine scanner
+// yang membaca file (handler aman melakukan Seek & menyimpan file).
+type gatedReader struct {
+	mu     sync.Mutex
+	r      io.Reader
+	closed bool
+}
+
+func (g *gatedReader) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return 0, ErrScanTimeout
+	}
+	return g.r.Read(p)
+}
+
+func (g *gatedReader) close() {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+}
+
+func (b *boundedScanner) Scan(ctx context.Context, r io.Reader) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	// Scanner yang hang boleh tetap berjalan di goroutine-nya, tapi aksesnya ke file diputus.
+	gate := &gatedReader{r: r}
+	defer gate.close()
+
+	done := make(chan scanResult, 1)
+	go func() {
+		clean, sig, err := b.inner.Scan(ctx, gate)
+		done <- scanResult{clean, sig, err}
+	}()
+
+	var res scanResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res = scanResult{err: ErrScanTimeout}
+	}
+
+	if res.err != nil {
+		if b.failOpen {
+			log.Printf("[SCAN] Scanner gagal (%v), file diloloskan (fail-open)", res.err)
+			return true, "", nil
+		}
+		return false, "", res.err
+	}
+	return res.clean, res.signature, nil
+}
+
+// NewScannerFromEnv membuat Scanner sesuai konfigurasi deployment:
+//   - UPLOAD_SCANNER       : "" / "none" (default, tanpa scan) atau "clamav"
+//   - CLAMAV_ADDR          : alamat clamd (default "localhost:3310")
+//   - UPLOAD_SCAN_TIMEOUT  : batas waktu scan (default 10s)
+//   - UPLOAD_SCAN_FAIL_OPEN: true = loloskan file jika scanner hang/error (default false)
+func NewScannerFromEnv() Scanner {
+	switch strings.ToLower(os.Getenv("UPLOAD_SCANNER")) {
+	case "clamav":
+		addr := os.Getenv("CLAMAV_ADDR")
+		if addr == "" {
+			addr = "localhost:3310"
+		}
+		return NewBoundedScanner(
+			ClamAVScanner{Addr: addr},
+			GetEnvDuration("UPLOAD_SCAN_TIMEOUT", 10*time.Second),
+			GetEnvBool("UPLOAD_SCAN_FAIL_OPEN", false),
+		)
+	default:
+		return NoopScanner{}
+	}
+}
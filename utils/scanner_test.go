@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeScanner membaca seluruh isi reader lalu mengembalikan hasil yang sudah ditentukan.
+// delay mensimulasikan scanner yang lambat/hang (ctx sengaja diabaikan).
+type fakeScanner struct {
+	clean     bool
+	signature string
+	delay     time.Duration
+}
+
+func (f fakeScanner) Scan(_ context.Context, r io.Reader) (bool, string, error) {
+	time.Sleep(f.delay)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return false, "", err
+	}
+	return f.clean, f.signature, nil
+}
+
+func TestBoundedScanner(t *testing.T) {
+	tests := []struct {
+		name      string
+		inner     fakeScanner
+		failOpen  bool
+		wantClean bool
+		wantSig   string
+		wantErr   error
+	}{
+		{name: "clean", inner: fakeScanner{clean: true}, wantClean: true},
+		{name: "infected", inner: fakeScanner{signature: "Eicar-Test-Signature"}, wantSig: "Eicar-Test-Signature"},
+		{name: "timeout fail-open", inner: fakeScanner{clean: false, delay: time.Second}, failOpen: true, wantClean: true},
+		{name: "timeout fail-closed", inner: fakeScanner{clean: true, delay: time.Second}, wantErr: ErrScanTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewBoundedScanner(tt.inner, 50*time.Millisecond, tt.failOpen)
+			clean, sig, err := s.Scan(context.Background(), strings.NewReader("isi file"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if clean != tt.wantClean || sig != tt.wantSig {
+				t.Fatalf("got (%v, %q), want (%v, %q)", clean, sig, tt.wantClean, tt.wantSig)
+			}
+		})
+	}
+}
+
+// slowReader mensimulasikan file upload yang dibaca lambat. Field pos sengaja tidak
+// dilindungi: jika goroutine scanner masih membaca setelah Scan kembali, `go test -race`
+// mendeteksi akses bersamaan dengan Seek di test (seperti UploadAttachment).
+type slowReader struct {
+	data []byte
+	pos  int
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	if s.pos >= len(s.data) {
+		return 0, io.EOF
+	}
+	n := copy(p[:1], s.data[s.pos:])
+	s.pos += n
+	return n, nil
+}
+
+func (s *slowReader) Seek(offset int64, _ int) (int64, error) {
+	s.pos = int(offset)
+	return offset, nil
+}
+
+func TestBoundedScannerStopsReadingAfterTimeout(t *testing.T) {
+	r := &slowReader{data: []byte(strings.Repeat("x", 1000))}
+	s := NewBoundedScanner(fakeScanner{clean: true}, 30*time.Millisecond, true)
+
+	if clean, _, err := s.Scan(context.Background(), r); err != nil || !clean {
+		t.Fatalf("fail-open timeout: got (%v, %v), want (true, nil)", clean, err)
+	}
+
+	// Setelah Scan kembali, file dipakai ulang oleh handler (Seek + simpan).
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if r.pos != 0 {
+		t.Fatalf("scanner masih membaca file setelah timeout (pos = %d)", r.pos)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+)
+
+// ErrSecretKeyMissing dikembalikan jika TWO_FACTOR_ENCRYPTION_KEY belum diset.
+var ErrSecretKeyMissing = errors.New("TWO_FACTOR_ENCRYPTION_KEY is not configured")
+
+// secretBoxKey menurunkan key AES-256 dari TWO_FACTOR_ENCRYPTION_KEY (dibaca setiap kali
+// dipakai, sama seperti JWT_SECRET). Key ini terpisah dari JWT_SECRET agar rotasi
+// secret JWT tidak membuat secret 2FA tersimpan tidak bisa dibaca.
+func secretBoxKey() ([]byte, error) {
+	v := os.Getenv("TWO_FACTOR_ENCRYPTION_KEY")
+	if v == "" {
+		return nil, ErrSecretKeyMissing
+	}
+	sum := sha256.Sum256([]byte(v))
+	return sum[:], nil
+}
+
+// EncryptSecret mengenkripsi plaintext dengan AES-256-GCM (nonce acak di depan ciphertext,
+// hasil base64) untuk disimpan di database.
+func EncryptSecret(plaintext string) (string, error) {
+	gcm, err := secretBoxCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret membalik EncryptSecret.
+func DecryptSecret(encoded string) (string, error) {
+	gcm, err := secretBoxCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func secretBoxCipher() (cipher.AEAD, error) {
+	key, err := secretBoxKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStorage adalah abstraksi penyimpanan file upload (saat ini disk lokal).
+type FileStorage interface {
+	// SaveAtomic menulis src ke file sementara (fsync), memindahkannya ke relPath
+	// secara atomik, lalu menjalankan commit (misal: simpan metadata ke Mongo).
+	// Metadata hanya ditulis setelah file ada di lokasi final, sehingga tidak ada
+	// metadata yang menunjuk file hilang; jika commit gagal, file final dihapus lagi.
+	SaveAtomic(src io.Reader, relPath string, commit func() error) error
+	// URL mengembalikan path URL relatif (diawali "/") untuk file di relPath.
+	URL(relPath string) string
+	// Remove menghapus 1 file berdasarkan path relatif terhadap direktori upload.
+	Remove(relPath string) error
+	// RemoveDir menghapus 1 direktori beserta isinya (dipakai saat purge prestasi).
+	RemoveDir(relDir string) error
+	// Open membuka file untuk dibaca (download).
+	Open(relPath string) (io.ReadSeekCloser, error)
+}
+
+// LocalStorage menyimpan file di disk lokal.
+// TempDir sebaiknya berada di filesystem yang sama dengan BaseDir agar rename bersifat atomik.
+type LocalStorage struct {
+	BaseDir string
+	TempDir string
+}
+
+// NewLocalStorage membaca UPLOAD_DIR (default "uploads") dan UPLOAD_TMP_DIR (default "<UPLOAD_DIR>/.tmp").
+func NewLocalStorage() *LocalStorage {
+	base := os.Getenv("UPLOAD_DIR")
+	if base == "" {
+		base = "uploads"
+	}
+	tmp := os.Getenv("UPLOAD_TMP_DIR")
+	if tmp == "" {
+		tmp = filepath.Join(base, ".tmp")
+	}
+	return &LocalStorage{BaseDir: base, TempDir: tmp}
+}
+
+// SaveAtomic mengimplementasikan FileStorage.SaveAtomic untuk disk lokal.
+func (s *LocalStorage) SaveAtomic(src io.Reader, relPath string, commit func() error) error {
+	if err := os.MkdirAll(s.TempDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(s.TempDir, hex.EncodeToString(suffix)+".part")
+
+	// 1. Tulis ke file sementara + fsync agar isi benar-benar ada di disk.
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 2. Pindahkan ke lokasi final secara atomik (sebelum metadata ditulis).
+	finalPath := filepath.Join(s.BaseDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(finalPath), os.ModePerm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 3. Commit metadata; gagal → hapus lagi file final agar tidak yatim.
+	if commit != nil {
+		if err := commit(); err != nil {
+			os.Remove(finalPath)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// URL mengimplementasikan FileStorage.URL: /<UPLOAD_DIR>/<relPath>.
+func (s *LocalStorage) URL(relPath string) string {
+	return "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Join(s.BaseDir, relPath)), "/")
+}
+
+// Remove menghapus 1 file; file yang sudah tidak ada tidak dianggap error.
+func (s *LocalStorage) Remove(relPath string) error {
+	err := os.Remove(filepath.Join(s.BaseDir, relPath))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveDir menghapus direktori beserta isinya; direktori yang tidak ada tidak dianggap error.
+func (s *LocalStorage) RemoveDir(relDir string) error {
+	return os.RemoveAll(filepath.Join(s.BaseDir, relDir))
+}
+
+// Open membuka file di direktori upload.
+func (s *LocalStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	return os.Open(filepath.Join(s.BaseDir, relPath))
+}
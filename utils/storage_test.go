@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageSaveAtomic(t *testing.T) {
+	tests := []struct {
+		name      string
+		commitErr error
+		wantFile  bool
+	}{
+		{name: "commit berhasil, file tetap ada", wantFile: true},
+		{name: "commit gagal, file final dihapus", commitErr: errors.New("mongo down")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			s := &LocalStorage{BaseDir: base, TempDir: filepath.Join(base, ".tmp")}
+			rel := filepath.Join("achievements", "a1", "bukti.pdf")
+			final := filepath.Join(base, rel)
+
+			err := s.SaveAtomic(strings.NewReader("isi"), rel, func() error {
+				// Metadata baru ditulis saat file sudah ada di lokasi final.
+				if _, err := os.Stat(final); err != nil {
+					t.Errorf("file final belum ada saat commit: %v", err)
+				}
+				return tt.commitErr
+			})
+			if !errors.Is(err, tt.commitErr) {
+				t.Fatalf("err = %v, want %v", err, tt.commitErr)
+			}
+			if _, statErr := os.Stat(final); (statErr == nil) != tt.wantFile {
+				t.Fatalf("file final ada = %v, want %v", statErr == nil, tt.wantFile)
+			}
+			if parts, _ := os.ReadDir(s.TempDir); len(parts) != 0 {
+				t.Fatalf("file sementara tertinggal: %d", len(parts))
+			}
+		})
+	}
+}
+
+func TestLocalStorageURL(t *testing.T) {
+	tests := []struct {
+		base, want string
+	}{
+		{"uploads", "/uploads/achievements/a1/bukti.pdf"},
+		{"data/files", "/data/files/achievements/a1/bukti.pdf"},
+		{"/srv/uploads", "/srv/uploads/achievements/a1/bukti.pdf"},
+	}
+	for _, tt := range tests {
+		s := &LocalStorage{BaseDir: tt.base}
+		if got := s.URL(filepath.Join("achievements", "a1", "bukti.pdf")); got != tt.want {
+			t.Errorf("URL dengan BaseDir %q = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName adalah nama instrumentation scope untuk semua span aplikasi.
+const TracerName = "student-achievement-backend"
+
+// InitTracing memasang TracerProvider global berdasarkan env OTEL standar:
+//   - OTEL_SDK_DISABLED=true            → no-op
+//   - OTEL_TRACES_EXPORTER=otlp         → ekspor OTLP/HTTP (OTEL_EXPORTER_OTLP_* dibaca exporter)
+//   - OTEL_TRACES_EXPORTER tidak diset  → otlp jika OTEL_EXPORTER_OTLP_ENDPOINT diset, selain itu no-op
+//
+// Nama service diambil dari OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES (default TracerName).
+// Fungsi shutdown yang dikembalikan wajib dipanggil saat aplikasi berhenti agar span ter-flush.
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	if GetEnvBool("OTEL_SDK_DISABLED", false) {
+		return noop, nil
+	}
+	exporterName := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER")))
+	if exporterName == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" &&
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		exporterName = "none"
+	}
+	if exporterName != "otlp" && exporterName != "" {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(TracerName)),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES menimpa default
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return noop, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	return tp.Shutdown, nil
+}
+
+// Tracer mengembalikan tracer aplikasi dari TracerProvider global (no-op jika tracing tidak aktif).
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// StartSpan membuat child span dari span yang ada di ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan menutup span dan menandai error bila ada.
+// Pola pakai: `defer func() { utils.EndSpan(span, err) }()`.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// AbsoluteURL mengubah path relatif (misal: /uploads/achievements/...) menjadi URL lengkap
+// berdasarkan APP_BASE_URL (misal: https://api.kampus.ac.id/api).
+// - Jika APP_BASE_URL kosong, path dikembalikan apa adanya (kompatibel dengan client lama).
+// - Jika path sudah berupa URL lengkap (http/https), tidak diubah.
+// - APP_BASE_URL tanpa scheme dianggap https.
+// - Slash ganda di sambungan base + path dirapikan.
+func AbsoluteURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	base := strings.TrimSpace(os.Getenv("APP_BASE_URL"))
+	if base == "" {
+		return path
+	}
+
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "https://" + strings.TrimLeft(base, "/")
+	}
+
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,31 @@
+package utils
+
+import "testing"
+
+func TestAbsoluteURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		path string
+		want string
+	}{
+		{name: "tanpa APP_BASE_URL tetap relatif", path: "/uploads/achievements/a.pdf", want: "/uploads/achievements/a.pdf"},
+		{name: "base dengan prefix", base: "https://api.kampus.ac.id/api", path: "/uploads/achievements/a.pdf",
+			want: "https://api.kampus.ac.id/api/uploads/achievements/a.pdf"},
+		{name: "slash ganda dirapikan", base: "https://api.kampus.ac.id/api/", path: "//uploads/a.pdf",
+			want: "https://api.kampus.ac.id/api/uploads/a.pdf"},
+		{name: "base tanpa scheme dianggap https", base: "api.kampus.ac.id", path: "uploads/a.pdf",
+			want: "https://api.kampus.ac.id/uploads/a.pdf"},
+		{name: "URL lengkap tidak diubah", base: "https://api.kampus.ac.id", path: "http://cdn.kampus.ac.id/a.pdf",
+			want: "http://cdn.kampus.ac.id/a.pdf"},
+		{name: "path kosong", base: "https://api.kampus.ac.id", path: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_BASE_URL", tt.base)
+			if got := AbsoluteURL(tt.path); got != tt.want {
+				t.Fatalf("AbsoluteURL(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
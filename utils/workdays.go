@@ -0,0 +1,41 @@
+package utils
+
+import "time"
+
+// HolidayDateFormat adalah format kunci tanggal libur (YYYY-MM-DD).
+const HolidayDateFormat = "2006-01-02"
+
+// IsWorkingDay: bukan Sabtu/Minggu dan bukan tanggal libur.
+func IsWorkingDay(t time.Time, holidays map[string]bool) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	return !holidays[t.Format(HolidayDateFormat)]
+}
+
+// CalendarDaysBetween menghitung selisih tanggal kalender end - start (mengabaikan jam).
+func CalendarDaysBetween(start, end time.Time) int {
+	s := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	e := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(e.Sub(s).Hours() / 24)
+}
+
+// WorkingDaysBetween menghitung jumlah hari kerja setelah tanggal start sampai dengan tanggal end
+// (hari start tidak dihitung, sama seperti CalendarDaysBetween). Sabtu, Minggu, dan tanggal
+// di holidays (kunci HolidayDateFormat) dilewati. end sebelum start → 0.
+// Contoh: submit Jumat, verifikasi Senin → kalender 3 hari, hari kerja 1.
+func WorkingDaysBetween(start, end time.Time, holidays map[string]bool) int {
+	days := CalendarDaysBetween(start, end)
+	if days <= 0 {
+		return 0
+	}
+	d := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	count := 0
+	for i := 0; i < days; i++ {
+		d = d.AddDate(0, 0, 1)
+		if IsWorkingDay(d, holidays) {
+			count++
+		}
+	}
+	return count
+}
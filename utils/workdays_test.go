@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkingDaysBetween(t *testing.T) {
+	// Agustus 2026: Jumat 14, Sabtu 15, Minggu 16, Senin 17 (HUT RI, libur), Selasa 18.
+	date := func(day, hour int) time.Time { return time.Date(2026, time.August, day, hour, 0, 0, 0, time.UTC) }
+	holidays := map[string]bool{"2026-08-17": true}
+	tests := []struct {
+		name         string
+		start, end   time.Time
+		holidays     map[string]bool
+		wantCalendar int
+		wantWorking  int
+	}{
+		{name: "hari yang sama", start: date(14, 8), end: date(14, 17), holidays: holidays},
+		{name: "Jumat ke Senin libur", start: date(14, 9), end: date(17, 9), holidays: holidays, wantCalendar: 3},
+		{name: "Jumat ke Selasa melewati akhir pekan & libur", start: date(14, 9), end: date(18, 9), holidays: holidays, wantCalendar: 4, wantWorking: 1},
+		{name: "Kamis ke Rabu", start: date(13, 9), end: date(19, 9), holidays: holidays, wantCalendar: 6, wantWorking: 3},
+		{name: "Jumat ke Selasa tanpa kalender libur", start: date(14, 9), end: date(18, 9), wantCalendar: 4, wantWorking: 2},
+		{name: "libur di hari Sabtu tidak dihitung ganda", start: date(14, 9), end: date(18, 9),
+			holidays: map[string]bool{"2026-08-15": true, "2026-08-17": true}, wantCalendar: 4, wantWorking: 1},
+		{name: "jam diabaikan", start: date(14, 23), end: date(18, 1), holidays: holidays, wantCalendar: 4, wantWorking: 1},
+		{name: "end sebelum start", start: date(18, 9), end: date(14, 9), holidays: holidays, wantCalendar: -4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalendarDaysBetween(tt.start, tt.end); got != tt.wantCalendar {
+				t.Fatalf("CalendarDaysBetween = %d, want %d", got, tt.wantCalendar)
+			}
+			if got := WorkingDaysBetween(tt.start, tt.end, tt.holidays); got != tt.wantWorking {
+				t.Fatalf("WorkingDaysBetween = %d, want %d", got, tt.wantWorking)
+			}
+		})
+	}
+}